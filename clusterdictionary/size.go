@@ -20,6 +20,12 @@ const (
 	SizeAlef5000 = "SizeAlef5000"
 	// SizeAlef10000 is the key representing a cluster supporting 10000 users.
 	SizeAlef10000 = "SizeAlef10000"
+	// SizeAlefDevSpot is the definition of a cluster supporting dev purposes
+	// on spot instances.
+	SizeAlefDevSpot = "SizeAlefDevSpot"
+	// SizeAlef500Spot is the key representing a cluster supporting 500 users
+	// on spot instances.
+	SizeAlef500Spot = "SizeAlef500Spot"
 )
 
 type size struct {
@@ -28,6 +34,11 @@ type size struct {
 	NodeInstanceType   string
 	NodeMinCount       int64
 	NodeMaxCount       int64
+
+	// NodeSpotMaxPrice is only set for sizes running worker nodes on spot
+	// instances.
+	NodeSpotMaxPrice       string
+	NodeMixedInstanceTypes []string
 }
 
 // ValidSizes is a mapping of a size keyword to kops cluster configuration.
@@ -37,6 +48,9 @@ var ValidSizes = map[string]size{
 	SizeAlef1000:  sizeAlef1000,
 	SizeAlef5000:  sizeAlef5000,
 	SizeAlef10000: sizeAlef10000,
+
+	SizeAlefDevSpot: sizeAlefDevSpot,
+	SizeAlef500Spot: sizeAlef500Spot,
 }
 
 // sizeAlefDev is a cluster sized for development and testing.
@@ -84,6 +98,30 @@ var sizeAlef10000 = size{
 	NodeMaxCount:       10,
 }
 
+// sizeAlefDevSpot is a cluster sized for development and testing, with worker
+// nodes on spot instances bid at up to the on-demand price.
+var sizeAlefDevSpot = size{
+	MasterInstanceType:     "t3.medium",
+	MasterCount:            1,
+	NodeInstanceType:       "t3.medium",
+	NodeMinCount:           2,
+	NodeMaxCount:           2,
+	NodeSpotMaxPrice:       "0.0416",
+	NodeMixedInstanceTypes: []string{"t3.medium", "t3a.medium"},
+}
+
+// sizeAlef500Spot is a cluster sized for 500 users, with worker nodes on spot
+// instances bid at up to the on-demand price.
+var sizeAlef500Spot = size{
+	MasterInstanceType:     "t3.medium",
+	MasterCount:            1,
+	NodeInstanceType:       "m5.large",
+	NodeMinCount:           2,
+	NodeMaxCount:           2,
+	NodeSpotMaxPrice:       "0.096",
+	NodeMixedInstanceTypes: []string{"m5.large", "m5a.large", "m5d.large"},
+}
+
 // IsValidClusterSize returns true if the given size string is supported.
 func IsValidClusterSize(size string) bool {
 	_, ok := ValidSizes[size]
@@ -107,6 +145,8 @@ func ApplyToCreateClusterRequest(size string, request *model.CreateClusterReques
 	request.NodeInstanceType = values.NodeInstanceType
 	request.NodeMinCount = values.NodeMinCount
 	request.NodeMaxCount = values.NodeMaxCount
+	request.NodeSpotMaxPrice = values.NodeSpotMaxPrice
+	request.NodeMixedInstanceTypes = values.NodeMixedInstanceTypes
 
 	return nil
 }
@@ -126,6 +166,10 @@ func ApplyToPatchClusterSizeRequest(size string, request *model.PatchClusterSize
 	request.NodeInstanceType = &values.NodeInstanceType
 	request.NodeMinCount = &values.NodeMinCount
 	request.NodeMaxCount = &values.NodeMaxCount
+	if len(values.NodeSpotMaxPrice) != 0 {
+		request.NodeSpotMaxPrice = &values.NodeSpotMaxPrice
+		request.NodeMixedInstanceTypes = values.NodeMixedInstanceTypes
+	}
 
 	return nil
 }
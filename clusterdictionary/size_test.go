@@ -20,6 +20,7 @@ func TestCheckSize(t *testing.T) {
 		{"unknown", false},
 		{SizeAlef500, true},
 		{SizeAlef1000, true},
+		{SizeAlefDevSpot, true},
 	}
 
 	for _, tc := range testCases {
@@ -93,6 +94,18 @@ func TestApplyToCreateClusterRequest(t *testing.T) {
 				NodeMaxCount:       10,
 			},
 			false,
+		}, {
+			SizeAlefDevSpot,
+			&model.CreateClusterRequest{
+				MasterInstanceType:     "t3.medium",
+				MasterCount:            1,
+				NodeInstanceType:       "t3.medium",
+				NodeMinCount:           2,
+				NodeMaxCount:           2,
+				NodeSpotMaxPrice:       "0.0416",
+				NodeMixedInstanceTypes: []string{"t3.medium", "t3a.medium"},
+			},
+			false,
 		},
 	}
 
@@ -164,6 +177,16 @@ func TestApplyToPatchClusterSizeRequest(t *testing.T) {
 				NodeMaxCount:     int64ToPointer(10),
 			},
 			false,
+		}, {
+			SizeAlefDevSpot,
+			&model.PatchClusterSizeRequest{
+				NodeInstanceType:       stringToPointer("t3.medium"),
+				NodeMinCount:           int64ToPointer(2),
+				NodeMaxCount:           int64ToPointer(2),
+				NodeSpotMaxPrice:       stringToPointer("0.0416"),
+				NodeMixedInstanceTypes: []string{"t3.medium", "t3a.medium"},
+			},
+			false,
 		},
 	}
 
@@ -6,6 +6,7 @@ package main
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -21,6 +22,7 @@ func init() {
 	clusterCmd.PersistentFlags().Bool("dry-run", false, "When set to true, only print the API request without sending it.")
 
 	clusterCreateCmd.Flags().String("provider", "aws", "Cloud provider hosting the cluster.")
+	clusterCreateCmd.Flags().String("provisioner", model.ProvisionerKops, "The provisioner creating the cluster. Accepts 'kops', 'eks' or 'local'.")
 	clusterCreateCmd.Flags().String("version", "latest", "The Kubernetes version to target. Use 'latest' or versions such as '1.16.10'.")
 	clusterCreateCmd.Flags().String("kops-ami", "", "The AMI to use for the cluster hosts. Leave empty for the default kops image.")
 	clusterCreateCmd.Flags().String("size", "SizeAlef500", "The size constant describing the cluster")
@@ -28,13 +30,29 @@ func init() {
 	clusterCreateCmd.Flags().Int64("size-master-count", 0, "The number of k8s master nodes. Overwrites value from 'size'.")
 	clusterCreateCmd.Flags().String("size-node-instance-type", "", "The instance type describing the k8s worker nodes. Overwrites value from 'size'.")
 	clusterCreateCmd.Flags().Int64("size-node-count", 0, "The number of k8s worker nodes. Overwrites value from 'size'.")
+	clusterCreateCmd.Flags().String("size-node-spot-max-price", "", "The maximum hourly price to bid for k8s worker nodes on spot instances. Overwrites value from 'size'.")
+	clusterCreateCmd.Flags().StringSlice("size-node-mixed-instance-types", nil, "The instance types k8s worker nodes on spot instances may run on. Overwrites value from 'size'.")
 	clusterCreateCmd.Flags().String("zones", "us-east-1a", "The zones where the cluster will be deployed. Use commas to separate multiple zones.")
 	clusterCreateCmd.Flags().Bool("allow-installations", true, "Whether the cluster will allow for new installations to be scheduled.")
+	clusterCreateCmd.Flags().StringToString("label", nil, "Labels describing the cluster which installation placement constraints can match, such as zone=us-east-1b. Accepts multiple values.")
+	clusterCreateCmd.Flags().Int("resource-threshold", 0, "The percent threshold where new installations won't be scheduled on the cluster. Uses the server default if omitted.")
+	clusterCreateCmd.Flags().Int("resource-threshold-scale-value", 0, "The number of worker nodes to scale up by when the threshold is passed. Set to 0 for no scaling. Uses the server default if omitted.")
+	clusterCreateCmd.Flags().String("scale-down-policy", model.ClusterScaleDownPolicyNone, "The policy used to scale down the worker nodes of the cluster. Accepts 'none' or 'utilization'.")
 	clusterCreateCmd.Flags().String("prometheus-version", model.PrometheusDefaultVersion, "The version of Prometheus to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("fluentbit-version", model.FluentbitDefaultVersion, "The version of Fluentbit to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("nginx-version", model.NginxDefaultVersion, "The version of Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("teleport-version", model.TeleportDefaultVersion, "The version of Teleport to provision. Use 'stable' to provision the latest stable version published upstream.")
 
+	clusterImportCmd.Flags().String("kubeconfig", "", "The path to the kubeconfig of the cluster to import.")
+	clusterImportCmd.Flags().String("name", "", "The name of the imported cluster. Generated from the cluster ID if omitted.")
+	clusterImportCmd.Flags().Bool("allow-installations", true, "Whether the cluster will allow for new installations to be scheduled.")
+	clusterImportCmd.Flags().StringToString("label", nil, "Labels describing the cluster which installation placement constraints can match, such as zone=us-east-1b. Accepts multiple values.")
+	clusterImportCmd.Flags().Int("resource-threshold", 0, "The percent threshold where new installations won't be scheduled on the cluster. Uses the server default if omitted.")
+	clusterImportCmd.Flags().String("prometheus-version", model.PrometheusDefaultVersion, "The version of Prometheus to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("fluentbit-version", model.FluentbitDefaultVersion, "The version of Fluentbit to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("nginx-version", model.NginxDefaultVersion, "The version of Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("teleport-version", model.TeleportDefaultVersion, "The version of Teleport to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.MarkFlagRequired("kubeconfig")
 	clusterProvisionCmd.Flags().String("cluster", "", "The id of the cluster to be provisioned.")
 	clusterProvisionCmd.Flags().String("prometheus-version", "", "The version of Prometheus to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.Flags().String("fluentbit-version", "", "The version of Fluentbit to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
@@ -44,6 +62,11 @@ func init() {
 
 	clusterUpdateCmd.Flags().String("cluster", "", "The id of the cluster to be updated.")
 	clusterUpdateCmd.Flags().Bool("allow-installations", true, "Whether the cluster will allow for new installations to be scheduled.")
+	clusterUpdateCmd.Flags().StringToString("label", nil, "Labels replacing those of the cluster, such as zone=us-east-1b. Accepts multiple values. No change if omitted.")
+	clusterUpdateCmd.Flags().Bool("clear-labels", false, "Whether to remove all labels of the cluster.")
+	clusterUpdateCmd.Flags().Int("resource-threshold", 0, "The percent threshold where new installations won't be scheduled on the cluster. No change if omitted.")
+	clusterUpdateCmd.Flags().Int("resource-threshold-scale-value", 0, "The number of worker nodes to scale up by when the threshold is passed. Set to 0 for no scaling. No change if omitted.")
+	clusterUpdateCmd.Flags().String("scale-down-policy", "", "The policy used to scale down the worker nodes of the cluster. Accepts 'none' or 'utilization'. No change if omitted.")
 	clusterUpdateCmd.MarkFlagRequired("cluster")
 
 	clusterUpgradeCmd.Flags().String("cluster", "", "The id of the cluster to be upgraded.")
@@ -56,8 +79,20 @@ func init() {
 	clusterResizeCmd.Flags().String("size-node-instance-type", "", "The instance type describing the k8s worker nodes. Overwrites value from 'size'.")
 	clusterResizeCmd.Flags().Int64("size-node-min-count", 0, "The minimum number of k8s worker nodes. Overwrites value from 'size'.")
 	clusterResizeCmd.Flags().Int64("size-node-max-count", 0, "The maximum number of k8s worker nodes. Overwrites value from 'size'.")
+	clusterResizeCmd.Flags().String("instance-group", "", "The worker node instance group to resize. It is created if it doesn't exist yet. Defaults to the 'nodes' instance group.")
+	clusterResizeCmd.Flags().StringToString("node-label", nil, "Labels of the nodes in a new instance group. Accepts multiple values, for example: '... --node-label pool=memory'")
+	clusterResizeCmd.Flags().Bool("delete-instance-group", false, "Delete the instance group instead of resizing it.")
+	clusterResizeCmd.Flags().String("size-node-spot-max-price", "", "The maximum hourly price to bid for k8s worker nodes on spot instances. Set to an empty value to move back to on-demand instances. Overwrites value from 'size'.")
+	clusterResizeCmd.Flags().StringSlice("size-node-mixed-instance-types", nil, "The instance types k8s worker nodes on spot instances may run on. Overwrites value from 'size'.")
 	clusterResizeCmd.MarkFlagRequired("cluster")
 
+	clusterDrainCmd.Flags().String("cluster", "", "The id of the cluster to be drained.")
+	clusterDrainCmd.Flags().Int64("max-rolling", 1, "The maximum number of installations moved off the cluster at the same time.")
+	clusterDrainCmd.MarkFlagRequired("cluster")
+
+	clusterDrainStatusCmd.Flags().String("cluster", "", "The id of the cluster whose drain progress is to be fetched.")
+	clusterDrainStatusCmd.MarkFlagRequired("cluster")
+
 	clusterDeleteCmd.Flags().String("cluster", "", "The id of the cluster to be deleted.")
 	clusterDeleteCmd.MarkFlagRequired("cluster")
 
@@ -71,14 +106,23 @@ func init() {
 	clusterUtilitiesCmd.Flags().String("cluster", "", "The id of the cluster whose utilities are to be fetched.")
 	clusterUtilitiesCmd.MarkFlagRequired("cluster")
 
+	clusterEventsCmd.Flags().String("cluster", "", "The id of the cluster whose events are to be fetched.")
+	clusterEventsCmd.Flags().Int("page", 0, "The page of events to fetch, starting at 0.")
+	clusterEventsCmd.Flags().Int("per-page", 100, "The number of events to fetch per page.")
+	clusterEventsCmd.MarkFlagRequired("cluster")
+
 	clusterCmd.AddCommand(clusterCreateCmd)
+	clusterCmd.AddCommand(clusterImportCmd)
 	clusterCmd.AddCommand(clusterProvisionCmd)
 	clusterCmd.AddCommand(clusterUpdateCmd)
 	clusterCmd.AddCommand(clusterUpgradeCmd)
 	clusterCmd.AddCommand(clusterResizeCmd)
+	clusterCmd.AddCommand(clusterDrainCmd)
+	clusterCmd.AddCommand(clusterDrainStatusCmd)
 	clusterCmd.AddCommand(clusterDeleteCmd)
 	clusterCmd.AddCommand(clusterGetCmd)
 	clusterCmd.AddCommand(clusterListCmd)
+	clusterCmd.AddCommand(clusterEventsCmd)
 	clusterCmd.AddCommand(clusterInstallationCmd)
 	clusterCmd.AddCommand(clusterShowStateReport)
 	clusterCmd.AddCommand(clusterUtilitiesCmd)
@@ -102,22 +146,35 @@ var clusterCreateCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		provider, _ := command.Flags().GetString("provider")
+		provisioner, _ := command.Flags().GetString("provisioner")
 		version, _ := command.Flags().GetString("version")
 		kopsAMI, _ := command.Flags().GetString("kops-ami")
 		zones, _ := command.Flags().GetString("zones")
 		allowInstallations, _ := command.Flags().GetBool("allow-installations")
+		labels, _ := command.Flags().GetStringToString("label")
+		scaleDownPolicy, _ := command.Flags().GetString("scale-down-policy")
 
 		request := &model.CreateClusterRequest{
 			Provider:               provider,
+			Provisioner:            provisioner,
 			Version:                version,
 			KopsAMI:                kopsAMI,
 			Zones:                  strings.Split(zones, ","),
 			AllowInstallations:     allowInstallations,
 			DesiredUtilityVersions: processUtilityFlags(command),
+			Labels:                 labels,
+			ScaleDownPolicy:        scaleDownPolicy,
+		}
+		if command.Flags().Changed("resource-threshold") {
+			resourceThreshold, _ := command.Flags().GetInt("resource-threshold")
+			request.ResourceThreshold = &resourceThreshold
+		}
+		if command.Flags().Changed("resource-threshold-scale-value") {
+			resourceThresholdScaleValue, _ := command.Flags().GetInt("resource-threshold-scale-value")
+			request.ResourceThresholdScaleValue = &resourceThresholdScaleValue
 		}
 
 		size, _ := command.Flags().GetString("size")
@@ -144,6 +201,12 @@ var clusterCreateCmd = &cobra.Command{
 			request.NodeMinCount = nodeCount
 			request.NodeMaxCount = nodeCount
 		}
+		if command.Flags().Changed("size-node-spot-max-price") {
+			request.NodeSpotMaxPrice, _ = command.Flags().GetString("size-node-spot-max-price")
+		}
+		if command.Flags().Changed("size-node-mixed-instance-types") {
+			request.NodeMixedInstanceTypes, _ = command.Flags().GetStringSlice("size-node-mixed-instance-types")
+		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
@@ -169,14 +232,69 @@ var clusterCreateCmd = &cobra.Command{
 	},
 }
 
+var clusterImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a cluster built outside of the provisioning server.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		kubeconfigPath, _ := command.Flags().GetString("kubeconfig")
+		kubeconfig, err := ioutil.ReadFile(kubeconfigPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to read kubeconfig")
+		}
+
+		name, _ := command.Flags().GetString("name")
+		allowInstallations, _ := command.Flags().GetBool("allow-installations")
+		labels, _ := command.Flags().GetStringToString("label")
+
+		request := &model.ImportClusterRequest{
+			Kubeconfig:             string(kubeconfig),
+			Name:                   name,
+			AllowInstallations:     allowInstallations,
+			DesiredUtilityVersions: processUtilityFlags(command),
+			Labels:                 labels,
+		}
+		if command.Flags().Changed("resource-threshold") {
+			resourceThreshold, _ := command.Flags().GetInt("resource-threshold")
+			request.ResourceThreshold = &resourceThreshold
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			// Never print the credentials held by the kubeconfig.
+			request.Kubeconfig = "<redacted>"
+			err = printJSON(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to print API request")
+			}
+
+			return nil
+		}
+
+		cluster, err := client.ImportCluster(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to import cluster")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster response")
+		}
+
+		return nil
+	},
+}
+
 var clusterProvisionCmd = &cobra.Command{
 	Use:   "provision",
 	Short: "Provision/Reprovision a cluster's k8s resources.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 		clusterID, _ := command.Flags().GetString("cluster")
 
 		var request *model.ProvisionClusterRequest = nil
@@ -216,8 +334,7 @@ var clusterUpdateCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		allowInstallations, _ := command.Flags().GetBool("allow-installations")
@@ -225,6 +342,25 @@ var clusterUpdateCmd = &cobra.Command{
 		request := &model.UpdateClusterRequest{
 			AllowInstallations: allowInstallations,
 		}
+		if command.Flags().Changed("label") {
+			request.Labels, _ = command.Flags().GetStringToString("label")
+		}
+		clearLabels, _ := command.Flags().GetBool("clear-labels")
+		if clearLabels {
+			request.Labels = map[string]string{}
+		}
+		if command.Flags().Changed("resource-threshold") {
+			resourceThreshold, _ := command.Flags().GetInt("resource-threshold")
+			request.ResourceThreshold = &resourceThreshold
+		}
+		if command.Flags().Changed("resource-threshold-scale-value") {
+			resourceThresholdScaleValue, _ := command.Flags().GetInt("resource-threshold-scale-value")
+			request.ResourceThresholdScaleValue = &resourceThresholdScaleValue
+		}
+		if command.Flags().Changed("scale-down-policy") {
+			scaleDownPolicy, _ := command.Flags().GetString("scale-down-policy")
+			request.ScaleDownPolicy = &scaleDownPolicy
+		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
@@ -256,8 +392,7 @@ var clusterUpgradeCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 
@@ -296,8 +431,7 @@ var clusterResizeCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 
@@ -320,6 +454,21 @@ var clusterResizeCmd = &cobra.Command{
 		if nodeMaxCount != 0 {
 			request.NodeMaxCount = &nodeMaxCount
 		}
+		if command.Flags().Changed("instance-group") {
+			instanceGroup, _ := command.Flags().GetString("instance-group")
+			request.InstanceGroup = &instanceGroup
+		}
+		if command.Flags().Changed("node-label") {
+			request.NodeLabels, _ = command.Flags().GetStringToString("node-label")
+		}
+		request.Delete, _ = command.Flags().GetBool("delete-instance-group")
+		if command.Flags().Changed("size-node-spot-max-price") {
+			nodeSpotMaxPrice, _ := command.Flags().GetString("size-node-spot-max-price")
+			request.NodeSpotMaxPrice = &nodeSpotMaxPrice
+		}
+		if command.Flags().Changed("size-node-mixed-instance-types") {
+			request.NodeMixedInstanceTypes, _ = command.Flags().GetStringSlice("size-node-mixed-instance-types")
+		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
@@ -345,14 +494,78 @@ var clusterResizeCmd = &cobra.Command{
 	},
 }
 
+var clusterDrainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Move all installations off a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		maxRolling, _ := command.Flags().GetInt64("max-rolling")
+
+		request := &model.DrainClusterRequest{
+			MaxRolling: maxRolling,
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			err := printJSON(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to print API request")
+			}
+
+			return nil
+		}
+
+		drainStatus, err := client.DrainCluster(clusterID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to drain cluster")
+		}
+
+		err = printJSON(drainStatus)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster drain status")
+		}
+
+		return nil
+	},
+}
+
+var clusterDrainStatusCmd = &cobra.Command{
+	Use:   "drain-status",
+	Short: "Get the progress of draining a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		drainStatus, err := client.GetClusterDrainStatus(clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query cluster drain status")
+		}
+		if drainStatus == nil {
+			return nil
+		}
+
+		err = printJSON(drainStatus)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster drain status")
+		}
+
+		return nil
+	},
+}
+
 var clusterDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a cluster.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 
@@ -371,8 +584,7 @@ var clusterGetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		cluster, err := client.GetCluster(clusterID)
@@ -398,8 +610,7 @@ var clusterListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		page, _ := command.Flags().GetInt("page")
 		perPage, _ := command.Flags().GetInt("per-page")
@@ -422,14 +633,41 @@ var clusterListCmd = &cobra.Command{
 	},
 }
 
+var clusterEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the state change history of a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		events, err := client.GetClusterEvents(clusterID, &model.GetEventsRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query cluster events")
+		}
+
+		err = printJSON(events)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var clusterUtilitiesCmd = &cobra.Command{
 	Use:   "utilities",
 	Short: "Show metadata regarding utility services running in a cluster.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 		clusterID, err := command.Flags().GetString("cluster")
 		if err != nil {
 			return err
@@ -43,8 +43,14 @@ func init() {
 	clusterInstallationMattermostCLICmd.MarkFlagRequired("cluster-installation")
 	clusterInstallationMattermostCLICmd.MarkFlagRequired("command")
 
+	clusterInstallationEventsCmd.Flags().String("cluster-installation", "", "The id of the cluster installation whose events are to be fetched.")
+	clusterInstallationEventsCmd.Flags().Int("page", 0, "The page of events to fetch, starting at 0.")
+	clusterInstallationEventsCmd.Flags().Int("per-page", 100, "The number of events to fetch per page.")
+	clusterInstallationEventsCmd.MarkFlagRequired("cluster-installation")
+
 	clusterInstallationCmd.AddCommand(clusterInstallationGetCmd)
 	clusterInstallationCmd.AddCommand(clusterInstallationListCmd)
+	clusterInstallationCmd.AddCommand(clusterInstallationEventsCmd)
 	clusterInstallationCmd.AddCommand(clusterInstallationConfigCmd)
 	clusterInstallationCmd.AddCommand(clusterInstallationMMCTL)
 	clusterInstallationCmd.AddCommand(clusterInstallationMattermostCLICmd)
@@ -64,8 +70,7 @@ var clusterInstallationGetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		clusterInstallation, err := client.GetClusterInstallation(clusterInstallationID)
@@ -91,8 +96,7 @@ var clusterInstallationListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		cluster, _ := command.Flags().GetString("cluster")
 		installation, _ := command.Flags().GetString("installation")
@@ -120,6 +124,34 @@ var clusterInstallationListCmd = &cobra.Command{
 	},
 }
 
+var clusterInstallationEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the state change history of a cluster installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		events, err := client.GetClusterInstallationEvents(clusterInstallationID, &model.GetEventsRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query cluster installation events")
+		}
+
+		err = printJSON(events)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var clusterInstallationConfigCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manipulate a particular cluster installation's config.",
@@ -131,8 +163,7 @@ var clusterInstallationConfigGetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		clusterInstallationConfig, err := client.GetClusterInstallationConfig(clusterInstallationID)
@@ -158,8 +189,7 @@ var clusterInstallationConfigSetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		key, _ := command.Flags().GetString("key")
@@ -193,8 +223,7 @@ var clusterInstallationMMCTL = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		subcommand, _ := command.Flags().GetString("command")
@@ -217,8 +246,7 @@ var clusterInstallationMattermostCLICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		subcommand, _ := command.Flags().GetString("command")
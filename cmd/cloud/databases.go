@@ -18,12 +18,32 @@ func init() {
 	databaseListCmd.Flags().Int("page", 0, "The page of databases to fetch, starting at 0.")
 	databaseListCmd.Flags().Int("per-page", 100, "The number of databases to fetch per page.")
 
+	databaseGetCmd.Flags().String("database", "", "The id of the multitenant database to be fetched.")
+	databaseGetCmd.MarkFlagRequired("database")
+
+	databaseUpdateCmd.Flags().String("database", "", "The id of the multitenant database to be updated.")
+	databaseUpdateCmd.Flags().Bool("allow-installations", true, "Whether new installations can be assigned to the database.")
+	databaseUpdateCmd.Flags().Bool("draining", false, "Whether the database is being drained. Draining databases never get new installations.")
+	databaseUpdateCmd.Flags().Int("max-installations", 0, "The maximum number of installations on the database. Set to 0 to use the default of the database type.")
+	databaseUpdateCmd.MarkFlagRequired("database")
+
+	databaseUnlockCmd.Flags().String("database", "", "The id of the multitenant database to be forcefully unlocked.")
+	databaseUnlockCmd.MarkFlagRequired("database")
+
+	databaseRebalanceCmd.Flags().String("vpc-id", "", "The VPC ID by which to filter databases.")
+	databaseRebalanceCmd.Flags().String("database-type", "", "The database type by which to filter databases.")
+	databaseRebalanceCmd.Flags().Bool("apply", false, "Request the proposed installation database migrations instead of only printing them.")
+
 	databaseCmd.AddCommand(databaseListCmd)
+	databaseCmd.AddCommand(databaseGetCmd)
+	databaseCmd.AddCommand(databaseUpdateCmd)
+	databaseCmd.AddCommand(databaseUnlockCmd)
+	databaseCmd.AddCommand(databaseRebalanceCmd)
 }
 
 var databaseCmd = &cobra.Command{
 	Use:   "database",
-	Short: "View information on known external multitenant databases",
+	Short: "View and manage known external multitenant databases",
 }
 
 var databaseListCmd = &cobra.Command{
@@ -32,8 +52,7 @@ var databaseListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		vpcID, _ := command.Flags().GetString("vpc-id")
 		databaseType, _ := command.Flags().GetString("database-type")
@@ -57,3 +76,125 @@ var databaseListCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var databaseGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular multitenant database and its installations.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		databaseID, _ := command.Flags().GetString("database")
+		database, err := client.GetMultitenantDatabase(databaseID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query database")
+		}
+		if database == nil {
+			return nil
+		}
+
+		err = printJSON(database)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var databaseUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the installation settings of a multitenant database.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		request := &model.UpdateMultitenantDatabaseRequest{}
+		if command.Flags().Changed("allow-installations") {
+			allowInstallations, _ := command.Flags().GetBool("allow-installations")
+			request.AllowInstallations = &allowInstallations
+		}
+		if command.Flags().Changed("draining") {
+			draining, _ := command.Flags().GetBool("draining")
+			request.Draining = &draining
+		}
+		if command.Flags().Changed("max-installations") {
+			maxInstallations, _ := command.Flags().GetInt("max-installations")
+			request.MaxInstallations = &maxInstallations
+		}
+
+		databaseID, _ := command.Flags().GetString("database")
+		database, err := client.UpdateMultitenantDatabase(databaseID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to update database")
+		}
+
+		err = printJSON(database)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var databaseUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Forcefully release the lock held on a multitenant database.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		databaseID, _ := command.Flags().GetString("database")
+		err := client.UnlockMultitenantDatabase(databaseID)
+		if err != nil {
+			return errors.Wrap(err, "failed to unlock database")
+		}
+
+		return nil
+	},
+}
+
+var databaseRebalanceCmd = &cobra.Command{
+	Use:   "rebalance",
+	Short: "Propose moving installation databases to even out the load between multitenant databases.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		vpcID, _ := command.Flags().GetString("vpc-id")
+		databaseType, _ := command.Flags().GetString("database-type")
+		moves, err := client.GetMultitenantDatabaseRebalancePlan(&model.GetDatabasesRequest{
+			VpcID:        vpcID,
+			DatabaseType: databaseType,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query rebalance plan")
+		}
+
+		err = printJSON(moves)
+		if err != nil {
+			return err
+		}
+
+		apply, _ := command.Flags().GetBool("apply")
+		if !apply {
+			return nil
+		}
+
+		for _, move := range moves {
+			_, err = client.MigrateInstallationDatabase(move.InstallationID, &model.MigrateInstallationDatabaseRequest{
+				TargetDatabaseID: move.TargetDatabaseID,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to migrate database of installation %s", move.InstallationID)
+			}
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// eventsWatchRetryInterval is how long to wait before reconnecting to the
+// event stream after a failure.
+const eventsWatchRetryInterval = 5 * time.Second
+
+func init() {
+	eventsCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The provisioning server whose API will be queried.")
+
+	eventsWatchCmd.Flags().String("type", "", "The resource type by which to filter events (cluster, installation, cluster_installation).")
+	eventsWatchCmd.Flags().String("owner", "", "The installation owner by which to filter events.")
+	eventsWatchCmd.Flags().String("group", "", "The installation group by which to filter events.")
+	eventsWatchCmd.Flags().String("last-event-id", "", "Resume watching after the event with this ID instead of only showing new events. Use 0 to show all recorded events.")
+
+	eventsCmd.AddCommand(eventsWatchCmd)
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Follow events of resources managed by the provisioning server.",
+}
+
+var eventsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch events as they happen.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		eventType, _ := command.Flags().GetString("type")
+		owner, _ := command.Flags().GetString("owner")
+		group, _ := command.Flags().GetString("group")
+		lastEventID, _ := command.Flags().GetString("last-event-id")
+
+		request := &model.StreamEventsRequest{
+			Type:        eventType,
+			OwnerID:     owner,
+			GroupID:     group,
+			LastEventID: lastEventID,
+		}
+
+		// The request keeps track of the last received event, so reconnecting
+		// with it resumes where the previous stream left off.
+		connected := false
+		for {
+			err := client.StreamEvents(request, func(event *model.Event) error {
+				return printJSON(event)
+			})
+			if err != nil {
+				// Fail fast if the first connection is rejected, as retrying
+				// won't fix invalid parameters.
+				if !connected && request.LastEventID == lastEventID {
+					return errors.Wrap(err, "failed to watch events")
+				}
+				logger.WithError(err).Warnf("Event stream interrupted; reconnecting in %s", eventsWatchRetryInterval)
+				time.Sleep(eventsWatchRetryInterval)
+				continue
+			}
+
+			// The server closes streams periodically.
+			connected = true
+			logger.Debug("Event stream closed by server; reconnecting")
+		}
+	},
+}
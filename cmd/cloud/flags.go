@@ -4,7 +4,10 @@
 
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/spf13/cobra"
+)
 
 func getStringFlagPointer(command *cobra.Command, s string) *string {
 	if command.Flags().Changed(s) {
@@ -15,6 +18,15 @@ func getStringFlagPointer(command *cobra.Command, s string) *string {
 	return nil
 }
 
+func getBoolFlagPointer(command *cobra.Command, s string) *bool {
+	if command.Flags().Changed(s) {
+		val, _ := command.Flags().GetBool(s)
+		return &val
+	}
+
+	return nil
+}
+
 func getInt64FlagPointer(command *cobra.Command, s string) *int64 {
 	if command.Flags().Changed(s) {
 		val, _ := command.Flags().GetInt64(s)
@@ -23,3 +35,80 @@ func getInt64FlagPointer(command *cobra.Command, s string) *int64 {
 
 	return nil
 }
+
+// getHibernationScheduleFlags returns the hibernation schedule described by the
+// schedule flags, or nil if no schedule was given. Clearing the schedule
+// returns an empty schedule, which removes the schedule when patching.
+func getHibernationScheduleFlags(command *cobra.Command) *model.HibernationSchedule {
+	clearSchedule, _ := command.Flags().GetBool("hibernation-schedule-clear")
+	if clearSchedule {
+		return &model.HibernationSchedule{}
+	}
+	if !command.Flags().Changed("hibernate-schedule") && !command.Flags().Changed("wake-up-schedule") {
+		return nil
+	}
+
+	hibernateCron, _ := command.Flags().GetString("hibernate-schedule")
+	wakeUpCron, _ := command.Flags().GetString("wake-up-schedule")
+	timeZone, _ := command.Flags().GetString("schedule-time-zone")
+
+	return &model.HibernationSchedule{
+		HibernateCron: hibernateCron,
+		WakeUpCron:    wakeUpCron,
+		TimeZone:      timeZone,
+	}
+}
+
+func getPlacementConstraintsFlags(command *cobra.Command) *model.PlacementConstraints {
+	requiredLabels, _ := command.Flags().GetStringToString("placement-required-label")
+	preferredLabels, _ := command.Flags().GetStringToString("placement-preferred-label")
+	avoidClusterIDs, _ := command.Flags().GetStringSlice("placement-avoid-cluster")
+	nodeSelector, _ := command.Flags().GetStringToString("node-selector")
+	if len(requiredLabels) == 0 && len(preferredLabels) == 0 && len(avoidClusterIDs) == 0 && len(nodeSelector) == 0 {
+		return nil
+	}
+
+	return &model.PlacementConstraints{
+		RequiredLabels:  requiredLabels,
+		PreferredLabels: preferredLabels,
+		AvoidClusterIDs: avoidClusterIDs,
+		NodeSelector:    nodeSelector,
+	}
+}
+
+func getExternalDatabaseConfigFlags(command *cobra.Command) *model.ExternalDatabaseConfig {
+	dataSource, _ := command.Flags().GetString("external-database")
+	secretName, _ := command.Flags().GetString("external-database-secret")
+	if dataSource == "" && secretName == "" {
+		return nil
+	}
+
+	return &model.ExternalDatabaseConfig{
+		DataSource: dataSource,
+		SecretName: secretName,
+	}
+}
+
+func getExternalFilestoreConfigFlags(command *cobra.Command) *model.ExternalFilestoreConfig {
+	endpoint, _ := command.Flags().GetString("external-filestore-endpoint")
+	bucket, _ := command.Flags().GetString("external-filestore-bucket")
+	if endpoint == "" && bucket == "" {
+		return nil
+	}
+
+	prefix, _ := command.Flags().GetString("external-filestore-prefix")
+	accessKeyID, _ := command.Flags().GetString("external-filestore-access-key-id")
+	secretAccessKey, _ := command.Flags().GetString("external-filestore-secret-access-key")
+	insecure, _ := command.Flags().GetBool("external-filestore-insecure")
+	deleteData, _ := command.Flags().GetBool("external-filestore-delete-data")
+
+	return &model.ExternalFilestoreConfig{
+		Endpoint:             endpoint,
+		Bucket:               bucket,
+		Prefix:               prefix,
+		AccessKeyID:          accessKeyID,
+		SecretAccessKey:      secretAccessKey,
+		Insecure:             insecure,
+		DeleteDataOnTeardown: deleteData,
+	}
+}
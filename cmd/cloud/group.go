@@ -20,6 +20,11 @@ func init() {
 	groupCreateCmd.Flags().String("image", "", "The Mattermost container image to use.")
 	groupCreateCmd.Flags().Int64("max-rolling", 1, "The maximum number of installations that can be updated at one time when a group is updated")
 	groupCreateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
+	groupCreateCmd.Flags().String("hibernate-schedule", "", "A cron expression of when to put the group's installations into hibernation, e.g. \"0 20 * * 1-5\".")
+	groupCreateCmd.Flags().String("wake-up-schedule", "", "A cron expression of when to wake the group's installations up from hibernation, e.g. \"0 7 * * 1-5\".")
+	groupCreateCmd.Flags().String("schedule-time-zone", "", "The time zone in which the hibernation schedule is evaluated, e.g. America/Toronto. Defaults to UTC.")
+	groupCreateCmd.Flags().Bool("idle-hibernation-opt-out", false, "Whether the group's installations are exempt from being hibernated when idle.")
+	groupCreateCmd.Flags().Int64("idle-hibernation-grace-period-hours", 0, "The number of hours after an installation of the group becomes stable during which it is not hibernated for being idle. Defaults to the server setting.")
 	groupCreateCmd.MarkFlagRequired("name")
 
 	groupUpdateCmd.Flags().String("group", "", "The id of the group to be updated.")
@@ -30,6 +35,12 @@ func init() {
 	groupUpdateCmd.Flags().Int64("max-rolling", 0, "The maximum number of installations that can be updated at one time when a group is updated")
 	groupUpdateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
 	groupUpdateCmd.Flags().Bool("mattermost-env-clear", false, "Clears all env var data.")
+	groupUpdateCmd.Flags().String("hibernate-schedule", "", "A cron expression of when to put the group's installations into hibernation, e.g. \"0 20 * * 1-5\".")
+	groupUpdateCmd.Flags().String("wake-up-schedule", "", "A cron expression of when to wake the group's installations up from hibernation, e.g. \"0 7 * * 1-5\".")
+	groupUpdateCmd.Flags().String("schedule-time-zone", "", "The time zone in which the hibernation schedule is evaluated, e.g. America/Toronto. Defaults to UTC.")
+	groupUpdateCmd.Flags().Bool("hibernation-schedule-clear", false, "Clears the hibernation schedule.")
+	groupUpdateCmd.Flags().Bool("idle-hibernation-opt-out", false, "Whether the group's installations are exempt from being hibernated when idle.")
+	groupUpdateCmd.Flags().Int64("idle-hibernation-grace-period-hours", 0, "The number of hours after an installation of the group becomes stable during which it is not hibernated for being idle.")
 	groupUpdateCmd.MarkFlagRequired("group")
 
 	groupDeleteCmd.Flags().String("group", "", "The id of the group to be deleted.")
@@ -51,11 +62,17 @@ func init() {
 	groupLeaveCmd.Flags().Bool("retain-config", true, "Whether to retain the group configuration values or not.")
 	groupLeaveCmd.MarkFlagRequired("installation")
 
+	groupEventsCmd.Flags().String("group", "", "The id of the group whose events are to be fetched.")
+	groupEventsCmd.Flags().Int("page", 0, "The page of events to fetch, starting at 0.")
+	groupEventsCmd.Flags().Int("per-page", 100, "The number of events to fetch per page.")
+	groupEventsCmd.MarkFlagRequired("group")
+
 	groupCmd.AddCommand(groupCreateCmd)
 	groupCmd.AddCommand(groupUpdateCmd)
 	groupCmd.AddCommand(groupDeleteCmd)
 	groupCmd.AddCommand(groupGetCmd)
 	groupCmd.AddCommand(groupListCmd)
+	groupCmd.AddCommand(groupEventsCmd)
 	groupCmd.AddCommand(groupJoinCmd)
 	groupCmd.AddCommand(groupLeaveCmd)
 }
@@ -71,8 +88,7 @@ var groupCreateCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		name, _ := command.Flags().GetString("name")
 		image, _ := command.Flags().GetString("image")
@@ -80,6 +96,7 @@ var groupCreateCmd = &cobra.Command{
 		version, _ := command.Flags().GetString("version")
 		maxRolling, _ := command.Flags().GetInt64("max-rolling")
 		mattermostEnv, _ := command.Flags().GetStringArray("mattermost-env")
+		idleHibernationOptOut, _ := command.Flags().GetBool("idle-hibernation-opt-out")
 
 		envVarMap, err := parseEnvVarInput(mattermostEnv, false)
 		if err != nil {
@@ -87,12 +104,15 @@ var groupCreateCmd = &cobra.Command{
 		}
 
 		request := &model.CreateGroupRequest{
-			Name:          name,
-			MaxRolling:    maxRolling,
-			Description:   description,
-			Version:       version,
-			Image:         image,
-			MattermostEnv: envVarMap,
+			Name:                            name,
+			MaxRolling:                      maxRolling,
+			Description:                     description,
+			Version:                         version,
+			Image:                           image,
+			MattermostEnv:                   envVarMap,
+			HibernationSchedule:             getHibernationScheduleFlags(command),
+			IdleHibernationOptOut:           idleHibernationOptOut,
+			IdleHibernationGracePeriodHours: getInt64FlagPointer(command, "idle-hibernation-grace-period-hours"),
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -120,8 +140,7 @@ var groupUpdateCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 		mattermostEnv, _ := command.Flags().GetStringArray("mattermost-env")
@@ -133,13 +152,16 @@ var groupUpdateCmd = &cobra.Command{
 		}
 
 		request := &model.PatchGroupRequest{
-			ID:            groupID,
-			Name:          getStringFlagPointer(command, "name"),
-			Description:   getStringFlagPointer(command, "description"),
-			Version:       getStringFlagPointer(command, "version"),
-			Image:         getStringFlagPointer(command, "image"),
-			MaxRolling:    getInt64FlagPointer(command, "max-rolling"),
-			MattermostEnv: envVarMap,
+			ID:                              groupID,
+			Name:                            getStringFlagPointer(command, "name"),
+			Description:                     getStringFlagPointer(command, "description"),
+			Version:                         getStringFlagPointer(command, "version"),
+			Image:                           getStringFlagPointer(command, "image"),
+			MaxRolling:                      getInt64FlagPointer(command, "max-rolling"),
+			MattermostEnv:                   envVarMap,
+			HibernationSchedule:             getHibernationScheduleFlags(command),
+			IdleHibernationOptOut:           getBoolFlagPointer(command, "idle-hibernation-opt-out"),
+			IdleHibernationGracePeriodHours: getInt64FlagPointer(command, "idle-hibernation-grace-period-hours"),
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -167,8 +189,7 @@ var groupDeleteCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 
@@ -187,8 +208,7 @@ var groupGetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 		group, err := client.GetGroup(groupID)
@@ -214,8 +234,7 @@ var groupListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		page, _ := command.Flags().GetInt("page")
 		perPage, _ := command.Flags().GetInt("per-page")
@@ -238,14 +257,41 @@ var groupListCmd = &cobra.Command{
 	},
 }
 
+var groupEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the state change history of the installations in a group.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		groupID, _ := command.Flags().GetString("group")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		events, err := client.GetGroupEvents(groupID, &model.GetEventsRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query group events")
+		}
+
+		err = printJSON(events)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var groupJoinCmd = &cobra.Command{
 	Use:   "join",
 	Short: "Join an installation to the given group, leaving any existing group.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 		installationID, _ := command.Flags().GetString("installation")
@@ -265,9 +311,8 @@ var groupLeaveCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
 		retainConfig, _ := command.Flags().GetBool("retain-config")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		installationID, _ := command.Flags().GetString("installation")
 		request := &model.LeaveGroupRequest{RetainConfig: retainConfig}
@@ -0,0 +1,171 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	installationBackupCreateCmd.Flags().String("installation", "", "The id of the installation to be backed up.")
+	installationBackupCreateCmd.MarkFlagRequired("installation")
+
+	installationBackupGetCmd.Flags().String("backup", "", "The id of the installation backup to be fetched.")
+	installationBackupGetCmd.MarkFlagRequired("backup")
+
+	installationBackupListCmd.Flags().String("installation", "", "The installation by which to filter installation backups.")
+	installationBackupListCmd.Flags().String("state", "", "The state by which to filter installation backups.")
+	installationBackupListCmd.Flags().Int("page", 0, "The page of installation backups to fetch, starting at 0.")
+	installationBackupListCmd.Flags().Int("per-page", 100, "The number of installation backups to fetch per page.")
+
+	installationBackupRestoreCmd.Flags().String("backup", "", "The id of the installation backup to be restored.")
+	installationBackupRestoreCmd.Flags().String("installation", "", "The id of the installation to restore the backup to. Defaults to the installation the backup was taken from.")
+	installationBackupRestoreCmd.Flags().String("new-installation-dns", "", "When set, create a new installation at this URL and restore the backup to it. Cannot be combined with --installation.")
+	installationBackupRestoreCmd.Flags().String("new-installation-owner", "", "An opaque identifier describing the owner of the new installation.")
+	installationBackupRestoreCmd.Flags().String("new-installation-version", "stable", "The Mattermost version of the new installation.")
+	installationBackupRestoreCmd.Flags().String("new-installation-size", model.InstallationDefaultSize, "The size of the new installation.")
+	installationBackupRestoreCmd.Flags().String("new-installation-database", model.InstallationDatabaseMysqlOperator, "The database type of the new installation. Must match the installation the backup was taken from.")
+	installationBackupRestoreCmd.Flags().String("new-installation-filestore", model.InstallationFilestoreMinioOperator, "The filestore type of the new installation.")
+	installationBackupRestoreCmd.MarkFlagRequired("backup")
+
+	installationBackupCmd.AddCommand(installationBackupCreateCmd)
+	installationBackupCmd.AddCommand(installationBackupGetCmd)
+	installationBackupCmd.AddCommand(installationBackupListCmd)
+	installationBackupCmd.AddCommand(installationBackupRestoreCmd)
+}
+
+var installationBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manipulate installation backups managed by the provisioning server.",
+}
+
+var installationBackupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Request a backup of the database and filestore contents of an installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		backup, err := client.CreateInstallationBackup(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to request installation backup")
+		}
+
+		err = printJSON(backup)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var installationBackupGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular installation backup.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		backupID, _ := command.Flags().GetString("backup")
+		backup, err := client.GetInstallationBackup(backupID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation backup")
+		}
+		if backup == nil {
+			return nil
+		}
+
+		err = printJSON(backup)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var installationBackupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installation backups.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		installationID, _ := command.Flags().GetString("installation")
+		state, _ := command.Flags().GetString("state")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		backups, err := client.GetInstallationBackups(&model.GetInstallationBackupsRequest{
+			InstallationID: installationID,
+			State:          state,
+			Page:           page,
+			PerPage:        perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation backups")
+		}
+
+		err = printJSON(backups)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var installationBackupRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore an installation backup to an installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		backupID, _ := command.Flags().GetString("backup")
+		installationID, _ := command.Flags().GetString("installation")
+		newInstallationDNS, _ := command.Flags().GetString("new-installation-dns")
+
+		request := &model.RestoreInstallationBackupRequest{
+			InstallationID: installationID,
+		}
+		if len(newInstallationDNS) != 0 {
+			ownerID, _ := command.Flags().GetString("new-installation-owner")
+			version, _ := command.Flags().GetString("new-installation-version")
+			size, _ := command.Flags().GetString("new-installation-size")
+			database, _ := command.Flags().GetString("new-installation-database")
+			filestore, _ := command.Flags().GetString("new-installation-filestore")
+
+			request.CreateInstallation = &model.CreateInstallationRequest{
+				OwnerID:   ownerID,
+				DNS:       newInstallationDNS,
+				Version:   version,
+				Size:      size,
+				Database:  database,
+				Filestore: filestore,
+			}
+		}
+
+		backup, err := client.RestoreInstallationBackup(backupID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to request installation backup restore")
+		}
+
+		err = printJSON(backup)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
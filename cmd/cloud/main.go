@@ -23,6 +23,7 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.MarkFlagRequired("database")
+	rootCmd.PersistentFlags().String("token", "", "The API token used to authenticate with the provisioning server. Defaults to the CLOUD_API_TOKEN environment variable.")
 
 	rootCmd.AddCommand(serverCmd)
 	rootCmd.AddCommand(clusterCmd)
@@ -31,7 +32,9 @@ func init() {
 	rootCmd.AddCommand(databaseCmd)
 	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(webhookCmd)
+	rootCmd.AddCommand(eventsCmd)
 	rootCmd.AddCommand(securityCmd)
+	rootCmd.AddCommand(tokenCmd)
 	rootCmd.AddCommand(workbenchCmd)
 	rootCmd.AddCommand(completionCmd)
 }
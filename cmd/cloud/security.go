@@ -5,7 +5,6 @@
 package main
 
 import (
-	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +24,9 @@ func init() {
 	securityGroupCmd.PersistentFlags().String("group", "", "The id of the group.")
 	securityGroupCmd.MarkPersistentFlagRequired("group")
 
+	securityDatabaseCmd.PersistentFlags().String("database", "", "The id of the multitenant database.")
+	securityDatabaseCmd.MarkPersistentFlagRequired("database")
+
 	securityCmd.AddCommand(securityClusterCmd)
 	securityClusterCmd.AddCommand(securityClusterLockAPICmd)
 	securityClusterCmd.AddCommand(securityClusterUnlockAPICmd)
@@ -40,6 +42,10 @@ func init() {
 	securityCmd.AddCommand(securityGroupCmd)
 	securityGroupCmd.AddCommand(securityGroupLockAPICmd)
 	securityGroupCmd.AddCommand(securityGroupUnlockAPICmd)
+
+	securityCmd.AddCommand(securityDatabaseCmd)
+	securityDatabaseCmd.AddCommand(securityDatabaseLockAPICmd)
+	securityDatabaseCmd.AddCommand(securityDatabaseUnlockAPICmd)
 }
 
 var securityCmd = &cobra.Command{
@@ -58,8 +64,7 @@ var securityClusterLockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		err := client.LockAPIForCluster(clusterID)
@@ -77,8 +82,7 @@ var securityClusterUnlockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		err := client.UnlockAPIForCluster(clusterID)
@@ -101,8 +105,7 @@ var securityInstallationLockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		installationID, _ := command.Flags().GetString("installation")
 		err := client.LockAPIForInstallation(installationID)
@@ -120,8 +123,7 @@ var securityInstallationUnlockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		installationID, _ := command.Flags().GetString("installation")
 		err := client.UnlockAPIForInstallation(installationID)
@@ -144,8 +146,7 @@ var securityClusterInstallationLockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		err := client.LockAPIForClusterInstallation(clusterInstallationID)
@@ -163,8 +164,7 @@ var securityClusterInstallationUnlockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		err := client.UnlockAPIForClusterInstallation(clusterInstallationID)
@@ -187,8 +187,7 @@ var securityGroupLockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 		err := client.LockAPIForGroup(groupID)
@@ -206,8 +205,7 @@ var securityGroupUnlockAPICmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		groupID, _ := command.Flags().GetString("group")
 		err := client.UnlockAPIForGroup(groupID)
@@ -218,3 +216,44 @@ var securityGroupUnlockAPICmd = &cobra.Command{
 		return nil
 	},
 }
+
+var securityDatabaseCmd = &cobra.Command{
+	Use:   "database",
+	Short: "Manage security locks for multitenant database resources.",
+}
+
+var securityDatabaseLockAPICmd = &cobra.Command{
+	Use:   "api-lock",
+	Short: "Lock API changes on a given multitenant database",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		databaseID, _ := command.Flags().GetString("database")
+		err := client.LockAPIForMultitenantDatabase(databaseID)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock database API")
+		}
+
+		return nil
+	},
+}
+
+var securityDatabaseUnlockAPICmd = &cobra.Command{
+	Use:   "api-unlock",
+	Short: "Unlock API changes on a given multitenant database",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		databaseID, _ := command.Flags().GetString("database")
+		err := client.UnlockAPIForMultitenantDatabase(databaseID)
+		if err != nil {
+			return errors.Wrap(err, "failed to unlock database API")
+		}
+
+		return nil
+	},
+}
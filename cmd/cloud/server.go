@@ -18,6 +18,9 @@ import (
 	sdkAWS "github.com/aws/aws-sdk-go/aws"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/placement"
 	"github.com/mattermost/mattermost-cloud/internal/provisioner"
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
@@ -25,6 +28,8 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	logrus "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -41,16 +46,46 @@ func init() {
 
 	serverCmd.PersistentFlags().String("database", "sqlite://cloud.db", "The database backing the provisioning server.")
 	serverCmd.PersistentFlags().String("listen", ":8075", "The interface and port on which to listen.")
+	serverCmd.PersistentFlags().Bool("require-auth", false, "Whether API requests must be authenticated with an API token or, if configured, an OIDC-issued JWT.")
+	serverCmd.PersistentFlags().String("oidc-issuer", "", "The URL of the OpenID Connect provider whose JWTs are accepted when authentication is required. Leave empty to only accept API tokens.")
+	serverCmd.PersistentFlags().String("oidc-audience", "", "The audience JWTs must be issued for. Leave empty to accept any audience.")
+	serverCmd.PersistentFlags().String("oidc-scope-claim", "cloud_scope", "The JWT claim holding the API scope of the caller.")
+	serverCmd.PersistentFlags().String("oidc-owner-claim", "cloud_owner", "The JWT claim holding the owner the caller is restricted to.")
+	serverCmd.PersistentFlags().String("metrics-listen", ":8076", "The interface and port on which to expose Prometheus metrics. Set to an empty string to disable metrics.")
 	serverCmd.PersistentFlags().Bool("cluster-supervisor", true, "Whether this server will run a cluster supervisor or not.")
 	serverCmd.PersistentFlags().Bool("group-supervisor", false, "Whether this server will run an installation group supervisor or not.")
 	serverCmd.PersistentFlags().Bool("installation-supervisor", true, "Whether this server will run an installation supervisor or not.")
 	serverCmd.PersistentFlags().Bool("cluster-installation-supervisor", true, "Whether this server will run a cluster installation supervisor or not.")
+	serverCmd.PersistentFlags().Bool("webhook-delivery-supervisor", true, "Whether this server will run a webhook delivery supervisor or not.")
+	serverCmd.PersistentFlags().Bool("installation-backup-supervisor", true, "Whether this server will run an installation backup supervisor or not.")
+	serverCmd.PersistentFlags().Bool("hibernation-schedule-supervisor", true, "Whether this server will run a hibernation schedule supervisor or not.")
+	serverCmd.PersistentFlags().Bool("idle-hibernation-supervisor", false, "Whether this server will run an idle hibernation supervisor or not.")
+	serverCmd.PersistentFlags().Bool("cluster-drain-supervisor", true, "Whether this server will run a cluster drain supervisor or not.")
+	serverCmd.PersistentFlags().Bool("cluster-capacity-supervisor", false, "Whether this server will run a cluster capacity supervisor or not.")
+	serverCmd.PersistentFlags().Bool("multitenant-database-retirement-supervisor", true, "Whether this server will run a multitenant database retirement supervisor or not.")
 	serverCmd.PersistentFlags().String("state-store", "dev.cloud.mattermost.com", "The S3 bucket used to store cluster state.")
 	serverCmd.PersistentFlags().StringSlice("allow-list-cidr-range", []string{"0.0.0.0/0"}, "The list of CIDRs to allow communication with the private ingress.")
+	serverCmd.PersistentFlags().String("eks-cluster-role-arn", "", "The ARN of the IAM role assumed by the control plane of EKS clusters.")
+	serverCmd.PersistentFlags().String("eks-node-role-arn", "", "The ARN of the IAM role assumed by the worker nodes of EKS clusters.")
+	serverCmd.PersistentFlags().String("encryption-key", "", "The base64-encoded 32 byte key encrypting the kubeconfig of imported clusters and the data source of external databases. Defaults to the CLOUD_ENCRYPTION_KEY environment variable. Neither can be stored without it.")
+	serverCmd.PersistentFlags().String("external-database-secret-prefix", "", "The name prefix the AWS Secrets Manager secrets holding the connection string of external databases must have. Leave empty to only accept encrypted connection strings.")
+	serverCmd.PersistentFlags().String("multitenant-database-config", "", "The path to a JSON file mapping VPC IDs to the engine version, instance class and KMS key of the multitenant RDS clusters created when no multitenant database has capacity left. Leave empty to never create them.")
+	serverCmd.PersistentFlags().String("local-cluster-engine", model.LocalClusterEngineKind, "The tool running clusters created with the local provisioner. Accepts 'kind' or 'k3d'.")
 
 	serverCmd.PersistentFlags().Int("poll", 30, "The interval in seconds to poll for background work.")
-	serverCmd.PersistentFlags().Int("cluster-resource-threshold", 80, "The percent threshold where new installations won't be scheduled on a multi-tenant cluster.")
-	serverCmd.PersistentFlags().Int("cluster-resource-threshold-scale-value", 0, "The number of worker nodes to scale up by when the threshold is passed. Set to 0 for no scaling. Scaling will never exceed the cluster max worker configuration value.")
+	serverCmd.PersistentFlags().Int("webhook-delivery-poll", 5, "The interval in seconds to poll for queued webhook deliveries.")
+	serverCmd.PersistentFlags().Int("webhook-delivery-max-attempts", 10, "The number of attempts to deliver a webhook before it is moved to the dead letter state.")
+	serverCmd.PersistentFlags().Int("event-retention-days", 90, "The number of days events are kept in the event log for. The latest event of each resource is always kept. Set to 0 to keep them forever.")
+	serverCmd.PersistentFlags().Int("webhook-delivery-retention-days", 30, "The number of days delivered and dead letter webhook deliveries are kept for. Set to 0 to keep them forever.")
+	serverCmd.PersistentFlags().Int("cluster-resource-threshold", 80, "The default percent threshold where new installations won't be scheduled on a multi-tenant cluster. Clusters may override it.")
+	serverCmd.PersistentFlags().Int("cluster-resource-threshold-scale-value", 0, "The number of worker nodes to scale up by when the threshold is passed. Set to 0 for no scaling. Scaling will never exceed the cluster max worker configuration value. Clusters may override it.")
+	serverCmd.PersistentFlags().String("scheduling-policy", model.SchedulingPolicyFirstFit, "The policy used to pick the cluster new installations are placed on. Accepts first-fit, bin-pack, spread or label.")
+	serverCmd.PersistentFlags().Int("cluster-scale-down-floor", 30, "The percent utilization under which clusters using the utilization scale down policy are scaled down by the cluster capacity supervisor.")
+	serverCmd.PersistentFlags().Int("cluster-scale-down-period-hours", 6, "The number of hours the utilization of a cluster must stay under the scale down floor before it is scaled down.")
+	serverCmd.PersistentFlags().Int("idle-hibernation-threshold-days", 14, "The number of days without user activity after which an installation is hibernated by the idle hibernation supervisor.")
+	serverCmd.PersistentFlags().Int("idle-hibernation-grace-period-hours", 24, "The number of hours after an installation becomes stable during which it is not hibernated for being idle. Groups may override this value.")
+	serverCmd.PersistentFlags().Int("cluster-drain-migration-timeout-minutes", 60, "The number of minutes after which an installation drained off a cluster is moved to migration-failed if no other cluster can host it.")
+	serverCmd.PersistentFlags().Int("multitenant-database-retirement-grace-period-hours", 24, "The number of hours a multitenant database created by the provisioner must stay empty before it is retired.")
 	serverCmd.PersistentFlags().Bool("use-existing-aws-resources", true, "Whether to use existing AWS resources (VPCs, subnets, etc.) or not.")
 	serverCmd.PersistentFlags().Bool("keep-database-data", true, "Whether to preserve database data after installation deletion or not.")
 	serverCmd.PersistentFlags().Bool("keep-filestore-data", true, "Whether to preserve filestore data after installation deletion or not.")
@@ -102,7 +137,6 @@ var serverCmd = &cobra.Command{
 			return errors.Errorf("server requires at least schema %s, current is %s", serverVersion, currentVersion)
 		}
 
-		// TODO: move these cluster threshold values to cluster configuration.
 		clusterResourceThreshold, _ := command.Flags().GetInt("cluster-resource-threshold")
 		if clusterResourceThreshold < 10 || clusterResourceThreshold > 100 {
 			return errors.Errorf("cluster-resource-threshold (%d) must be set between 10 and 100", clusterResourceThreshold)
@@ -111,19 +145,104 @@ var serverCmd = &cobra.Command{
 		if clusterResourceThresholdScaleValue < 0 || clusterResourceThresholdScaleValue > 10 {
 			return errors.Errorf("cluster-resource-threshold-scale-value (%d) must be set between 0 and 10", clusterResourceThresholdScaleValue)
 		}
+		schedulingPolicyName, _ := command.Flags().GetString("scheduling-policy")
+		schedulingPolicy, err := placement.NewPolicy(schedulingPolicyName)
+		if err != nil {
+			return errors.Wrap(err, "invalid scheduling-policy")
+		}
 
 		clusterSupervisor, _ := command.Flags().GetBool("cluster-supervisor")
 		groupSupervisor, _ := command.Flags().GetBool("group-supervisor")
 		installationSupervisor, _ := command.Flags().GetBool("installation-supervisor")
 		clusterInstallationSupervisor, _ := command.Flags().GetBool("cluster-installation-supervisor")
-		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor {
+		webhookDeliverySupervisor, _ := command.Flags().GetBool("webhook-delivery-supervisor")
+		installationBackupSupervisor, _ := command.Flags().GetBool("installation-backup-supervisor")
+		hibernationScheduleSupervisor, _ := command.Flags().GetBool("hibernation-schedule-supervisor")
+		idleHibernationSupervisor, _ := command.Flags().GetBool("idle-hibernation-supervisor")
+		clusterDrainSupervisor, _ := command.Flags().GetBool("cluster-drain-supervisor")
+		clusterCapacitySupervisor, _ := command.Flags().GetBool("cluster-capacity-supervisor")
+		multitenantDatabaseRetirementSupervisor, _ := command.Flags().GetBool("multitenant-database-retirement-supervisor")
+		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor && !webhookDeliverySupervisor && !installationBackupSupervisor && !hibernationScheduleSupervisor && !idleHibernationSupervisor && !clusterDrainSupervisor && !clusterCapacitySupervisor && !multitenantDatabaseRetirementSupervisor {
 			logger.Warn("Server will be running with no supervisors. Only API functionality will work.")
 		}
 
+		webhookDeliveryMaxAttempts, _ := command.Flags().GetInt("webhook-delivery-max-attempts")
+		if webhookDeliveryMaxAttempts < 1 {
+			return errors.Errorf("webhook-delivery-max-attempts (%d) must be at least 1", webhookDeliveryMaxAttempts)
+		}
+		webhookDeliveryRetentionDays, _ := command.Flags().GetInt("webhook-delivery-retention-days")
+		if webhookDeliveryRetentionDays < 0 {
+			return errors.Errorf("webhook-delivery-retention-days (%d) must not be negative", webhookDeliveryRetentionDays)
+		}
+		webhookDeliveryRetention := time.Duration(webhookDeliveryRetentionDays) * 24 * time.Hour
+
+		eventRetentionDays, _ := command.Flags().GetInt("event-retention-days")
+		if eventRetentionDays < 0 {
+			return errors.Errorf("event-retention-days (%d) must not be negative", eventRetentionDays)
+		}
+
+		idleHibernationThresholdDays, _ := command.Flags().GetInt("idle-hibernation-threshold-days")
+		if idleHibernationThresholdDays < 1 {
+			return errors.Errorf("idle-hibernation-threshold-days (%d) must be at least 1", idleHibernationThresholdDays)
+		}
+		idleHibernationGracePeriodHours, _ := command.Flags().GetInt("idle-hibernation-grace-period-hours")
+		if idleHibernationGracePeriodHours < 0 {
+			return errors.Errorf("idle-hibernation-grace-period-hours (%d) must not be negative", idleHibernationGracePeriodHours)
+		}
+
+		clusterDrainMigrationTimeoutMinutes, _ := command.Flags().GetInt("cluster-drain-migration-timeout-minutes")
+		if clusterDrainMigrationTimeoutMinutes < 1 {
+			return errors.Errorf("cluster-drain-migration-timeout-minutes (%d) must be at least 1", clusterDrainMigrationTimeoutMinutes)
+		}
+
+		multitenantDatabaseRetirementGracePeriodHours, _ := command.Flags().GetInt("multitenant-database-retirement-grace-period-hours")
+		if multitenantDatabaseRetirementGracePeriodHours < 0 {
+			return errors.Errorf("multitenant-database-retirement-grace-period-hours (%d) must not be negative", multitenantDatabaseRetirementGracePeriodHours)
+		}
+
+		clusterScaleDownFloor, _ := command.Flags().GetInt("cluster-scale-down-floor")
+		if clusterScaleDownFloor < 1 || clusterScaleDownFloor >= clusterResourceThreshold {
+			return errors.Errorf("cluster-scale-down-floor (%d) must be at least 1 and lower than cluster-resource-threshold", clusterScaleDownFloor)
+		}
+		clusterScaleDownPeriodHours, _ := command.Flags().GetInt("cluster-scale-down-period-hours")
+		if clusterScaleDownPeriodHours < 1 {
+			return errors.Errorf("cluster-scale-down-period-hours (%d) must be at least 1", clusterScaleDownPeriodHours)
+		}
+
 		s3StateStore, _ := command.Flags().GetString("state-store")
 		keepDatabaseData, _ := command.Flags().GetBool("keep-database-data")
 		keepFilestoreData, _ := command.Flags().GetBool("keep-filestore-data")
 		useExistingResources, _ := command.Flags().GetBool("use-existing-aws-resources")
+		eksClusterRoleARN, _ := command.Flags().GetString("eks-cluster-role-arn")
+		eksNodeRoleARN, _ := command.Flags().GetString("eks-node-role-arn")
+
+		localClusterEngine, _ := command.Flags().GetString("local-cluster-engine")
+		if !model.IsSupportedLocalClusterEngine(localClusterEngine) {
+			return errors.Errorf("unsupported local-cluster-engine %s", localClusterEngine)
+		}
+
+		var multitenantDatabaseConfig model.MultitenantDatabaseConfig
+		multitenantDatabaseConfigPath, _ := command.Flags().GetString("multitenant-database-config")
+		if multitenantDatabaseConfigPath != "" {
+			multitenantDatabaseConfig, err = model.MultitenantDatabaseConfigFromFile(multitenantDatabaseConfigPath)
+			if err != nil {
+				return errors.Wrap(err, "invalid multitenant-database-config")
+			}
+		}
+
+		externalDatabaseSecretPrefix, _ := command.Flags().GetString("external-database-secret-prefix")
+
+		encodedEncryptionKey, _ := command.Flags().GetString("encryption-key")
+		if encodedEncryptionKey == "" {
+			encodedEncryptionKey = os.Getenv("CLOUD_ENCRYPTION_KEY")
+		}
+		var encryptionKey []byte
+		if encodedEncryptionKey != "" {
+			encryptionKey, err = model.NewEncryptionKey(encodedEncryptionKey)
+			if err != nil {
+				return errors.Wrap(err, "invalid encryption key")
+			}
+		}
 
 		wd, err := os.Getwd()
 		if err != nil {
@@ -141,21 +260,44 @@ var serverCmd = &cobra.Command{
 		}
 
 		logger.WithFields(logrus.Fields{
-			"build-hash":                             model.BuildHash,
-			"cluster-supervisor":                     clusterSupervisor,
-			"group-supervisor":                       groupSupervisor,
-			"installation-supervisor":                installationSupervisor,
-			"cluster-installation-supervisor":        clusterInstallationSupervisor,
-			"store-version":                          currentVersion,
-			"state-store":                            s3StateStore,
-			"working-directory":                      wd,
-			"cluster-resource-threshold":             clusterResourceThreshold,
-			"cluster-resource-threshold-scale-value": clusterResourceThresholdScaleValue,
-			"use-existing-aws-resources":             useExistingResources,
-			"keep-database-data":                     keepDatabaseData,
-			"keep-filestore-data":                    keepFilestoreData,
-			"debug":                                  debugMode,
-			"dev-mode":                               devMode,
+			"build-hash":                                         model.BuildHash,
+			"cluster-supervisor":                                 clusterSupervisor,
+			"group-supervisor":                                   groupSupervisor,
+			"installation-supervisor":                            installationSupervisor,
+			"cluster-installation-supervisor":                    clusterInstallationSupervisor,
+			"webhook-delivery-supervisor":                        webhookDeliverySupervisor,
+			"installation-backup-supervisor":                     installationBackupSupervisor,
+			"hibernation-schedule-supervisor":                    hibernationScheduleSupervisor,
+			"idle-hibernation-supervisor":                        idleHibernationSupervisor,
+			"idle-hibernation-threshold-days":                    idleHibernationThresholdDays,
+			"idle-hibernation-grace-period-hours":                idleHibernationGracePeriodHours,
+			"cluster-drain-supervisor":                           clusterDrainSupervisor,
+			"cluster-drain-migration-timeout-minutes":            clusterDrainMigrationTimeoutMinutes,
+			"cluster-capacity-supervisor":                        clusterCapacitySupervisor,
+			"cluster-scale-down-floor":                           clusterScaleDownFloor,
+			"cluster-scale-down-period-hours":                    clusterScaleDownPeriodHours,
+			"multitenant-database-retirement-supervisor":         multitenantDatabaseRetirementSupervisor,
+			"multitenant-database-retirement-grace-period-hours": multitenantDatabaseRetirementGracePeriodHours,
+			"webhook-delivery-max-attempts":                      webhookDeliveryMaxAttempts,
+			"webhook-delivery-retention-days":                    webhookDeliveryRetentionDays,
+			"event-retention-days":                               eventRetentionDays,
+			"store-version":                                      currentVersion,
+			"state-store":                                        s3StateStore,
+			"eks-cluster-role-arn":                               eksClusterRoleARN,
+			"eks-node-role-arn":                                  eksNodeRoleARN,
+			"encryption-key-configured":                          len(encryptionKey) != 0,
+			"external-database-secret-prefix":                    externalDatabaseSecretPrefix,
+			"local-cluster-engine":                               localClusterEngine,
+			"multitenant-database-vpcs":                          len(multitenantDatabaseConfig),
+			"working-directory":                                  wd,
+			"cluster-resource-threshold":                         clusterResourceThreshold,
+			"cluster-resource-threshold-scale-value":             clusterResourceThresholdScaleValue,
+			"scheduling-policy":                                  schedulingPolicy.Name(),
+			"use-existing-aws-resources":                         useExistingResources,
+			"keep-database-data":                                 keepDatabaseData,
+			"keep-filestore-data":                                keepFilestoreData,
+			"debug":                                              debugMode,
+			"dev-mode":                                           devMode,
 		}).Info("Starting Mattermost Provisioning Server")
 
 		deprecationWarnings(logger, command)
@@ -181,8 +323,10 @@ var serverCmd = &cobra.Command{
 			},
 			logger,
 		)
+		awsClient.SetMultitenantDatabaseConfig(multitenantDatabaseConfig)
+		awsClient.SetExternalDatabaseSecretPrefix(externalDatabaseSecretPrefix)
 
-		resourceUtil := utils.NewResourceUtil(instanceID, awsClient)
+		resourceUtil := utils.NewResourceUtil(instanceID, awsClient, encryptionKey)
 
 		// Setup the provisioner for actually effecting changes to clusters.
 		kopsProvisioner := provisioner.NewKopsProvisioner(
@@ -193,20 +337,64 @@ var serverCmd = &cobra.Command{
 			resourceUtil,
 			logger,
 			sqlStore,
+			encryptionKey,
+		)
+		eksProvisioner := provisioner.NewEKSProvisioner(
+			kopsProvisioner,
+			awsClient,
+			eksClusterRoleARN,
+			eksNodeRoleARN,
+			logger,
 		)
+		externalProvisioner := provisioner.NewExternalProvisioner(kopsProvisioner, logger)
+		localProvisioner := provisioner.NewLocalProvisioner(kopsProvisioner, localClusterEngine, logger)
+
+		scheduler := placement.NewScheduler(sqlStore, kopsProvisioner, schedulingPolicy, clusterResourceThreshold, clusterResourceThresholdScaleValue)
 
 		var multiDoer supervisor.MultiDoer
 		if clusterSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("cluster", supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{
+				model.ProvisionerKops:     kopsProvisioner,
+				model.ProvisionerEKS:      eksProvisioner,
+				model.ProvisionerExternal: externalProvisioner,
+				model.ProvisionerLocal:    localProvisioner,
+			}, awsClient, instanceID, logger)))
 		}
 		if groupSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewGroupSupervisor(sqlStore, instanceID, logger))
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("group", supervisor.NewGroupSupervisor(sqlStore, instanceID, logger)))
 		}
 		if installationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, clusterResourceThreshold, clusterResourceThresholdScaleValue, keepDatabaseData, keepFilestoreData, resourceUtil, logger))
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("installation", supervisor.NewInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, scheduler, keepDatabaseData, keepFilestoreData, resourceUtil, logger)))
 		}
 		if clusterInstallationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("cluster_installation", supervisor.NewClusterInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger)))
+		}
+		if installationBackupSupervisor {
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("installation_backup", supervisor.NewInstallationBackupSupervisor(sqlStore, kopsProvisioner, instanceID, logger)))
+		}
+		if hibernationScheduleSupervisor {
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("hibernation_schedule", supervisor.NewHibernationScheduleSupervisor(sqlStore, instanceID, logger)))
+		}
+		if idleHibernationSupervisor {
+			idleHibernationThreshold := time.Duration(idleHibernationThresholdDays) * 24 * time.Hour
+			idleHibernationGracePeriod := time.Duration(idleHibernationGracePeriodHours) * time.Hour
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("idle_hibernation", supervisor.NewIdleHibernationSupervisor(sqlStore, kopsProvisioner, idleHibernationThreshold, idleHibernationGracePeriod, instanceID, logger)))
+		}
+		if clusterDrainSupervisor {
+			clusterDrainMigrationTimeout := time.Duration(clusterDrainMigrationTimeoutMinutes) * time.Minute
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("cluster_drain", supervisor.NewClusterDrainSupervisor(sqlStore, clusterDrainMigrationTimeout, instanceID, logger)))
+		}
+		if clusterCapacitySupervisor {
+			clusterScaleDownPeriod := time.Duration(clusterScaleDownPeriodHours) * time.Hour
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("cluster_capacity", supervisor.NewClusterCapacitySupervisor(sqlStore, kopsProvisioner, clusterScaleDownFloor, clusterScaleDownPeriod, clusterResourceThreshold, instanceID, logger)))
+		}
+		if multitenantDatabaseRetirementSupervisor {
+			multitenantDatabaseRetirementGracePeriod := time.Duration(multitenantDatabaseRetirementGracePeriodHours) * time.Hour
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("multitenant_database_retirement", supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, awsClient, multitenantDatabaseRetirementGracePeriod, instanceID, logger)))
+		}
+		if eventRetentionDays > 0 {
+			eventRetention := time.Duration(eventRetentionDays) * 24 * time.Hour
+			multiDoer = append(multiDoer, supervisor.NewInstrumentedDoer("event_retention", supervisor.NewEventRetentionSupervisor(sqlStore, eventRetention, logger)))
 		}
 
 		// Setup the supervisor to effect any requested changes. It is wrapped in a
@@ -217,16 +405,59 @@ var serverCmd = &cobra.Command{
 			logger.WithField("poll", poll).Info("Scheduler is disabled")
 		}
 
+		// Webhook deliveries are processed by their own scheduler so that
+		// long-running provisioning work doesn't delay them.
+		if webhookDeliverySupervisor {
+			webhookDeliveryPoll, _ := command.Flags().GetInt("webhook-delivery-poll")
+			if webhookDeliveryPoll == 0 {
+				logger.WithField("webhook-delivery-poll", webhookDeliveryPoll).Info("Webhook delivery scheduler is disabled")
+			}
+
+			webhookDeliveryScheduler := supervisor.NewScheduler(
+				supervisor.NewInstrumentedDoer("webhook_delivery", supervisor.NewWebhookDeliverySupervisor(sqlStore, webhookDeliveryMaxAttempts, webhookDeliveryRetention, instanceID, logger)),
+				time.Duration(webhookDeliveryPoll)*time.Second,
+			)
+			defer webhookDeliveryScheduler.Close()
+		}
+
 		supervisor := supervisor.NewScheduler(multiDoer, time.Duration(poll)*time.Second)
 		defer supervisor.Close()
 
 		router := mux.NewRouter()
 
+		var authenticator auth.Authenticator
+		requireAuth, _ := command.Flags().GetBool("require-auth")
+		if requireAuth {
+			authenticators := auth.Chain{auth.NewTokenAuthenticator(sqlStore)}
+
+			oidcIssuer, _ := command.Flags().GetString("oidc-issuer")
+			if oidcIssuer != "" {
+				oidcAudience, _ := command.Flags().GetString("oidc-audience")
+				oidcScopeClaim, _ := command.Flags().GetString("oidc-scope-claim")
+				oidcOwnerClaim, _ := command.Flags().GetString("oidc-owner-claim")
+				authenticators = append(authenticators, auth.NewJWTAuthenticator(auth.OIDCConfig{
+					Issuer:     oidcIssuer,
+					Audience:   oidcAudience,
+					ScopeClaim: oidcScopeClaim,
+					OwnerClaim: oidcOwnerClaim,
+				}, logger))
+			}
+
+			authenticator = authenticators
+		} else {
+			logger.Warn("API authentication is disabled; the API must not be exposed to untrusted networks.")
+		}
+
 		api.Register(router, &api.Context{
-			Store:       sqlStore,
-			Supervisor:  supervisor,
-			Provisioner: kopsProvisioner,
-			Logger:      logger,
+			Store:                        sqlStore,
+			Supervisor:                   supervisor,
+			Provisioner:                  kopsProvisioner,
+			Scheduler:                    scheduler,
+			Authenticator:                authenticator,
+			EncryptionKey:                encryptionKey,
+			ExternalDatabaseSecretPrefix: externalDatabaseSecretPrefix,
+			InstanceID:                   instanceID,
+			Logger:                       logger,
 		})
 
 		listen, _ := command.Flags().GetString("listen")
@@ -248,6 +479,35 @@ var serverCmd = &cobra.Command{
 			}
 		}()
 
+		// Metrics are served on a separate listener so that they can be
+		// scraped without exposing the API.
+		var metricsSrv *http.Server
+		metricsListen, _ := command.Flags().GetString("metrics-listen")
+		if metricsListen != "" {
+			prometheus.MustRegister(metrics.NewStateCollector(sqlStore, logger))
+
+			metricsRouter := mux.NewRouter()
+			metricsRouter.Handle("/metrics", promhttp.Handler())
+
+			metricsSrv = &http.Server{
+				Addr:           metricsListen,
+				Handler:        metricsRouter,
+				ReadTimeout:    30 * time.Second,
+				WriteTimeout:   30 * time.Second,
+				IdleTimeout:    time.Second * 180,
+				MaxHeaderBytes: 1 << 20,
+				ErrorLog:       log.New(&logrusWriter{logger}, "", 0),
+			}
+
+			go func() {
+				logger.WithField("addr", metricsSrv.Addr).Info("Serving metrics")
+				err := metricsSrv.ListenAndServe()
+				if err != nil && err != http.ErrServerClosed {
+					logger.WithError(err).Error("Failed to serve metrics")
+				}
+			}()
+		}
+
 		c := make(chan os.Signal, 1)
 		// We'll accept graceful shutdowns when quit via:
 		//  - SIGINT (Ctrl+C)
@@ -267,6 +527,9 @@ var serverCmd = &cobra.Command{
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 		srv.Shutdown(ctx)
+		if metricsSrv != nil {
+			metricsSrv.Shutdown(ctx)
+		}
 
 		return nil
 	},
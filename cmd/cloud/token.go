@@ -0,0 +1,193 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	tokenCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The provisioning server whose API will be queried.")
+
+	tokenCreateCmd.Flags().String("name", "", "A name describing the use of the API token.")
+	tokenCreateCmd.Flags().String("owner", "", "An opaque identifier of the owner the API token is restricted to. Leave empty to allow access to all owners.")
+	tokenCreateCmd.Flags().String("scope", model.APITokenScopeReadOnly, "The scope of the API token: read-only, installation-operator or cluster-admin.")
+	tokenCreateCmd.MarkFlagRequired("name")
+
+	tokenGetCmd.Flags().String("api-token", "", "The id of the API token to be fetched.")
+	tokenGetCmd.MarkFlagRequired("api-token")
+
+	tokenListCmd.Flags().String("owner", "", "The owner by which to filter API tokens.")
+	tokenListCmd.Flags().Int("page", 0, "The page of API tokens to fetch, starting at 0.")
+	tokenListCmd.Flags().Int("per-page", 100, "The number of API tokens to fetch per page.")
+	tokenListCmd.Flags().Bool("include-deleted", false, "Whether to include revoked API tokens.")
+
+	tokenDeleteCmd.Flags().String("api-token", "", "The id of the API token to be revoked.")
+	tokenDeleteCmd.MarkFlagRequired("api-token")
+
+	tokenBootstrapCmd.Flags().String("database", "sqlite://cloud.db", "The database backing the provisioning server.")
+	tokenBootstrapCmd.Flags().String("name", "bootstrap", "A name describing the use of the API token.")
+
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenGetCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenDeleteCmd)
+	tokenCmd.AddCommand(tokenBootstrapCmd)
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manipulate API tokens used to authenticate with the provisioning server.",
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API token. The token secret is only shown once.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		name, _ := command.Flags().GetString("name")
+		ownerID, _ := command.Flags().GetString("owner")
+		scope, _ := command.Flags().GetString("scope")
+
+		token, err := client.CreateAPIToken(&model.CreateAPITokenRequest{
+			Name:    name,
+			OwnerID: ownerID,
+			Scope:   scope,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create API token")
+		}
+
+		err = printJSON(token)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular API token.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		tokenID, _ := command.Flags().GetString("api-token")
+		token, err := client.GetAPIToken(tokenID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query API token")
+		}
+		if token == nil {
+			return nil
+		}
+
+		err = printJSON(token)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List created API tokens.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		owner, _ := command.Flags().GetString("owner")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		tokens, err := client.GetAPITokens(&model.GetAPITokensRequest{
+			OwnerID:        owner,
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query API tokens")
+		}
+
+		err = printJSON(tokens)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Revoke an API token.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		tokenID, _ := command.Flags().GetString("api-token")
+
+		err := client.DeleteAPIToken(tokenID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete API token")
+		}
+
+		return nil
+	},
+}
+
+var tokenBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Create a cluster-admin API token directly in the database.",
+	Long:  "Create a cluster-admin API token directly in the database, bypassing the API. Use this to create the first token once authentication is required. The token secret is only shown once.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		name, _ := command.Flags().GetString("name")
+		request := &model.CreateAPITokenRequest{
+			Name:  name,
+			Scope: model.APITokenScopeClusterAdmin,
+		}
+		err = request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid API token")
+		}
+
+		token, err := auth.NewAPIToken(request)
+		if err != nil {
+			return err
+		}
+
+		err = sqlStore.CreateAPIToken(token)
+		if err != nil {
+			return errors.Wrap(err, "failed to create API token")
+		}
+
+		err = printJSON(token)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
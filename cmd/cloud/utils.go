@@ -5,12 +5,33 @@
 package main
 
 import (
+	"os"
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 )
 
+// createClient creates a client to the provisioning server configured by the
+// flags of the given command, authenticating with the API token given by the
+// token flag or the CLOUD_API_TOKEN environment variable, if any.
+func createClient(command *cobra.Command) *model.Client {
+	serverAddress, _ := command.Flags().GetString("server")
+
+	token, _ := command.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("CLOUD_API_TOKEN")
+	}
+	if token == "" {
+		return model.NewClient(serverAddress)
+	}
+
+	return model.NewClientWithHeaders(serverAddress, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+}
+
 func parseEnvVarInput(rawInput []string, clear bool) (model.EnvVarMap, error) {
 	if len(rawInput) != 0 && clear {
 		return nil, errors.New("both mattermost-env and mattermost-env-clear were set; use one or the other")
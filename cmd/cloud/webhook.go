@@ -15,6 +15,8 @@ func init() {
 
 	webhookCreateCmd.Flags().String("owner", "", "An opaque identifier describing the owner of the webhook.")
 	webhookCreateCmd.Flags().String("url", "", "The callback URL of the webhook.")
+	webhookCreateCmd.Flags().String("secret", "", "An optional secret used to sign the payloads sent to the webhook.")
+	registerWebhookEventFilterFlags(webhookCreateCmd)
 	webhookCreateCmd.MarkFlagRequired("owner")
 	webhookCreateCmd.MarkFlagRequired("url")
 
@@ -26,13 +28,34 @@ func init() {
 	webhookListCmd.Flags().Int("per-page", 100, "The number of webhooks to fetch per page.")
 	webhookListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted webhooks.")
 
+	webhookUpdateCmd.Flags().String("webhook", "", "The id of the webhook to be updated.")
+	webhookUpdateCmd.MarkFlagRequired("webhook")
+	registerWebhookEventFilterFlags(webhookUpdateCmd)
+
 	webhookDeleteCmd.Flags().String("webhook", "", "The id of the webhook to be deleted.")
 	webhookDeleteCmd.MarkFlagRequired("webhook")
 
+	webhookDeliveryGetCmd.Flags().String("delivery", "", "The id of the webhook delivery to be fetched.")
+	webhookDeliveryGetCmd.MarkFlagRequired("delivery")
+
+	webhookDeliveryListCmd.Flags().String("webhook", "", "The webhook by which to filter webhook deliveries.")
+	webhookDeliveryListCmd.Flags().String("state", "", "The state by which to filter webhook deliveries.")
+	webhookDeliveryListCmd.Flags().Int("page", 0, "The page of webhook deliveries to fetch, starting at 0.")
+	webhookDeliveryListCmd.Flags().Int("per-page", 100, "The number of webhook deliveries to fetch per page.")
+
+	webhookDeliveryRedeliverCmd.Flags().String("delivery", "", "The id of the webhook delivery to be redelivered.")
+	webhookDeliveryRedeliverCmd.MarkFlagRequired("delivery")
+
 	webhookCmd.AddCommand(webhookCreateCmd)
 	webhookCmd.AddCommand(webhookGetCmd)
 	webhookCmd.AddCommand(webhookListCmd)
+	webhookCmd.AddCommand(webhookUpdateCmd)
 	webhookCmd.AddCommand(webhookDeleteCmd)
+	webhookCmd.AddCommand(webhookDeliveryCmd)
+
+	webhookDeliveryCmd.AddCommand(webhookDeliveryGetCmd)
+	webhookDeliveryCmd.AddCommand(webhookDeliveryListCmd)
+	webhookDeliveryCmd.AddCommand(webhookDeliveryRedeliverCmd)
 }
 
 var webhookCmd = &cobra.Command{
@@ -46,15 +69,17 @@ var webhookCreateCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		ownerID, _ := command.Flags().GetString("owner")
 		url, _ := command.Flags().GetString("url")
+		secret, _ := command.Flags().GetString("secret")
 
 		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
-			OwnerID: ownerID,
-			URL:     url,
+			OwnerID:     ownerID,
+			URL:         url,
+			Secret:      secret,
+			EventFilter: getWebhookEventFilterFromFlags(command),
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create webhook")
@@ -75,8 +100,7 @@ var webhookGetCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		webhookID, _ := command.Flags().GetString("webhook")
 		webhook, err := client.GetWebhook(webhookID)
@@ -102,8 +126,7 @@ var webhookListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		owner, _ := command.Flags().GetString("owner")
 		page, _ := command.Flags().GetInt("page")
@@ -128,14 +151,40 @@ var webhookListCmd = &cobra.Command{
 	},
 }
 
+var webhookUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the event filter of a webhook.",
+	Long:  "Update the event filter of a webhook. The existing filter is replaced; run without filter flags to send all events to the webhook.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+
+		webhook, err := client.UpdateWebhook(webhookID, &model.UpdateWebhookRequest{
+			EventFilter: getWebhookEventFilterFromFlags(command),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to update webhook")
+		}
+
+		err = printJSON(webhook)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var webhookDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a webhook.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := createClient(command)
 
 		webhookID, _ := command.Flags().GetString("webhook")
 
@@ -147,3 +196,114 @@ var webhookDeleteCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var webhookDeliveryCmd = &cobra.Command{
+	Use:   "delivery",
+	Short: "Inspect and redeliver queued webhook payloads.",
+}
+
+var webhookDeliveryGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular webhook delivery.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		deliveryID, _ := command.Flags().GetString("delivery")
+		delivery, err := client.GetWebhookDelivery(deliveryID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query webhook delivery")
+		}
+		if delivery == nil {
+			return nil
+		}
+
+		err = printJSON(delivery)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var webhookDeliveryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhook deliveries.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		state, _ := command.Flags().GetString("state")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		deliveries, err := client.GetWebhookDeliveries(&model.GetWebhookDeliveriesRequest{
+			WebhookID: webhookID,
+			State:     state,
+			Page:      page,
+			PerPage:   perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query webhook deliveries")
+		}
+
+		err = printJSON(deliveries)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var webhookDeliveryRedeliverCmd = &cobra.Command{
+	Use:   "redeliver",
+	Short: "Queue a failed or delivered webhook payload for delivery again.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := createClient(command)
+
+		deliveryID, _ := command.Flags().GetString("delivery")
+		delivery, err := client.RedeliverWebhookDelivery(deliveryID)
+		if err != nil {
+			return errors.Wrap(err, "failed to redeliver webhook delivery")
+		}
+
+		err = printJSON(delivery)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func registerWebhookEventFilterFlags(command *cobra.Command) {
+	command.Flags().StringSlice("filter-type", []string{}, "Only send events for these resource types (cluster, installation, cluster_installaton).")
+	command.Flags().StringSlice("filter-new-state", []string{}, "Only send events for transitions to these states.")
+	command.Flags().String("filter-owner", "", "Only send events for installations with this owner.")
+	command.Flags().String("filter-group", "", "Only send events for installations in this group.")
+}
+
+func getWebhookEventFilterFromFlags(command *cobra.Command) *model.WebhookEventFilter {
+	types, _ := command.Flags().GetStringSlice("filter-type")
+	newStates, _ := command.Flags().GetStringSlice("filter-new-state")
+	ownerID, _ := command.Flags().GetString("filter-owner")
+	groupID, _ := command.Flags().GetString("filter-group")
+
+	eventFilter := &model.WebhookEventFilter{
+		Types:     types,
+		NewStates: newStates,
+		OwnerID:   ownerID,
+		GroupID:   groupID,
+	}
+	if eventFilter.IsEmpty() {
+		return nil
+	}
+
+	return eventFilter
+}
@@ -5,9 +5,12 @@
 package main
 
 import (
+	"bytes"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	cloud "github.com/mattermost/mattermost-cloud/model"
 )
@@ -17,10 +20,32 @@ const (
 	DefaultPort = "8065"
 	// ListenPortEnv is the env var name for overriding the default listen port.
 	ListenPortEnv = "CWL_PORT"
+	// SecretEnv is the env var name for the webhook secret used to verify
+	// incoming webhook signatures. Signatures are not checked if it is unset.
+	SecretEnv = "CWL_SECRET"
+	// SignatureTolerance is the maximum age of a signed webhook.
+	SignatureTolerance = 5 * time.Minute
 )
 
+var secret string
+
 func handler(w http.ResponseWriter, r *http.Request) {
-	webhook, err := cloud.WebhookPayloadFromReader(r.Body)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error: failed to read webhook: %s", err)
+		return
+	}
+
+	if len(secret) != 0 {
+		err = cloud.VerifyWebhookSignature(secret, r.Header, body, SignatureTolerance)
+		if err != nil {
+			log.Printf("Error: rejecting webhook %s: %s", r.Header.Get(cloud.WebhookHeaderDeliveryID), err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	webhook, err := cloud.WebhookPayloadFromReader(bytes.NewReader(body))
 	if err != nil {
 		log.Printf("Error: failed to parse webhook: %s", err)
 		return
@@ -37,6 +62,8 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		wType = "INST"
 	case cloud.TypeClusterInstallation:
 		wType = "CLIN"
+	case cloud.TypeInstallationBackup:
+		wType = "BKUP"
 	}
 
 	log.Printf("[ %s | %s ] %s -> %s", wType, webhook.ID[0:4], webhook.OldState, webhook.NewState)
@@ -49,6 +76,7 @@ func main() {
 	if len(os.Getenv(ListenPortEnv)) != 0 {
 		port = os.Getenv(ListenPortEnv)
 	}
+	secret = os.Getenv(SecretEnv)
 
 	log.Printf("Starting cloud webhook listener on port %s", port)
 
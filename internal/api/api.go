@@ -9,12 +9,20 @@ import "github.com/gorilla/mux"
 // Register registers the API endpoints on the given router.
 func Register(rootRouter *mux.Router, context *Context) {
 	apiRouter := rootRouter.PathPrefix("/api").Subrouter()
+	apiRouter.Use(instrumentRequests)
+	if context.Authenticator != nil {
+		apiRouter.Use(authenticate(context))
+	}
 
 	initCluster(apiRouter, context)
 	initInstallation(apiRouter, context)
+	initInstallationBackup(apiRouter, context)
 	initClusterInstallation(apiRouter, context)
 	initGroup(apiRouter, context)
 	initWebhook(apiRouter, context)
+	initWebhookDelivery(apiRouter, context)
+	initEvents(apiRouter, context)
 	initDatabases(apiRouter, context)
 	initSecurity(apiRouter, context)
+	initAPIToken(apiRouter, context)
 }
@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initAPIToken registers API token endpoints on the given router.
+func initAPIToken(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	tokensRouter := apiRouter.PathPrefix("/tokens").Subrouter()
+	tokensRouter.Handle("", addContext(handleGetAPITokens)).Methods("GET")
+	tokensRouter.Handle("", addContext(handleCreateAPIToken)).Methods("POST")
+
+	tokenRouter := apiRouter.PathPrefix("/token/{token:[A-Za-z0-9]{26}}").Subrouter()
+	tokenRouter.Handle("", addContext(handleGetAPIToken)).Methods("GET")
+	tokenRouter.Handle("", addContext(handleDeleteAPIToken)).Methods("DELETE")
+}
+
+// handleCreateAPIToken responds to POST /api/tokens, creating a new API token.
+// The token secret is only included in this response.
+func handleCreateAPIToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	createAPITokenRequest, err := model.NewCreateAPITokenRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := auth.NewAPIToken(createAPITokenRequest)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to generate API token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = c.Store.CreateAPIToken(token)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create API token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.Logger.WithField("token", token.ID).Infof("Created API token %s with scope %s", token.Name, token.Scope)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, token)
+}
+
+// handleGetAPIToken responds to GET /api/token/{token}, returning the API
+// token in question without its secret.
+func handleGetAPIToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenID := vars["token"]
+	c.Logger = c.Logger.WithField("token", tokenID)
+
+	token, err := c.Store.GetAPIToken(tokenID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query API token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if token == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, token)
+}
+
+// handleGetAPITokens responds to GET /api/tokens, returning the specified page
+// of API tokens.
+func handleGetAPITokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, includeDeleted, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.APITokenFilter{
+		OwnerID:        parseString(r.URL, "owner", ""),
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	}
+
+	tokens, err := c.Store.GetAPITokens(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query API tokens")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.APIToken{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, tokens)
+}
+
+// handleDeleteAPIToken responds to DELETE /api/token/{token}, revoking the API
+// token.
+func handleDeleteAPIToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenID := vars["token"]
+	c.Logger = c.Logger.WithField("token", tokenID)
+
+	token, err := c.Store.GetAPIToken(tokenID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query API token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if token == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !token.IsDeleted() {
+		err = c.Store.DeleteAPIToken(token.ID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to delete API token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
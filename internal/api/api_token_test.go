@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/api/tokens", ts.URL), "application/json", bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := client.CreateAPIToken(&model.CreateAPITokenRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		_, err := client.CreateAPIToken(&model.CreateAPITokenRequest{Name: "token", Scope: "root"})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("owner restricted cluster admin", func(t *testing.T) {
+		_, err := client.CreateAPIToken(&model.CreateAPITokenRequest{Name: "token", OwnerID: "owner", Scope: model.APITokenScopeClusterAdmin})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("get unknown token", func(t *testing.T) {
+		token, err := client.GetAPIToken(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+
+	t.Run("create, get, list and delete", func(t *testing.T) {
+		token, err := client.CreateAPIToken(&model.CreateAPITokenRequest{Name: "token", OwnerID: "owner"})
+		require.NoError(t, err)
+		require.Equal(t, "token", token.Name)
+		require.Equal(t, "owner", token.OwnerID)
+		require.Equal(t, model.APITokenScopeReadOnly, token.Scope)
+		require.NotEmpty(t, token.Token)
+
+		storedToken, err := sqlStore.GetAPIToken(token.ID)
+		require.NoError(t, err)
+		require.Equal(t, auth.HashToken(token.Token), storedToken.TokenHash)
+
+		fetchedToken, err := client.GetAPIToken(token.ID)
+		require.NoError(t, err)
+		require.Equal(t, token.ID, fetchedToken.ID)
+		require.Empty(t, fetchedToken.Token)
+		require.Empty(t, fetchedToken.TokenHash)
+
+		tokens, err := client.GetAPITokens(&model.GetAPITokensRequest{OwnerID: "owner", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+
+		err = client.DeleteAPIToken(token.ID)
+		require.NoError(t, err)
+
+		tokens, err = client.GetAPITokens(&model.GetAPITokensRequest{OwnerID: "owner", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+
+		fetchedToken, err = client.GetAPIToken(token.ID)
+		require.NoError(t, err)
+		require.True(t, fetchedToken.IsDeleted())
+	})
+}
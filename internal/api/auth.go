@@ -0,0 +1,287 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+type principalContextKey struct{}
+
+// resourcePolicy describes the authorization requirements of the routes
+// serving a given type of resource.
+type resourcePolicy struct {
+	// readScope is the scope required to read the resource.
+	readScope string
+	// writeScope is the scope required to modify the resource.
+	writeScope string
+	// ownerScoped is true if the resource belongs to an owner, allowing
+	// principals restricted to an owner to access it.
+	ownerScoped bool
+}
+
+var (
+	clusterAdminPolicy = resourcePolicy{
+		readScope:  model.APITokenScopeReadOnly,
+		writeScope: model.APITokenScopeClusterAdmin,
+	}
+	installationOperatorPolicy = resourcePolicy{
+		readScope:   model.APITokenScopeReadOnly,
+		writeScope:  model.APITokenScopeInstallationOperator,
+		ownerScoped: true,
+	}
+	tokenPolicy = resourcePolicy{
+		readScope:  model.APITokenScopeClusterAdmin,
+		writeScope: model.APITokenScopeClusterAdmin,
+	}
+)
+
+// resourcePolicies maps the first segment of an API route, or the second one
+// for security routes, to its authorization requirements. Routes missing from
+// this map require the cluster-admin scope.
+var resourcePolicies = map[string]resourcePolicy{
+	"cluster":               clusterAdminPolicy,
+	"clusters":              clusterAdminPolicy,
+	"group":                 clusterAdminPolicy,
+	"groups":                clusterAdminPolicy,
+	"database":              clusterAdminPolicy,
+	"databases":             clusterAdminPolicy,
+	"installation":          installationOperatorPolicy,
+	"installations":         installationOperatorPolicy,
+	"installation_backup":   installationOperatorPolicy,
+	"installation_backups":  installationOperatorPolicy,
+	"cluster_installation":  installationOperatorPolicy,
+	"cluster_installations": installationOperatorPolicy,
+	"webhook":               installationOperatorPolicy,
+	"webhooks":              installationOperatorPolicy,
+	"webhook_delivery":      installationOperatorPolicy,
+	"webhook_deliveries":    installationOperatorPolicy,
+	"events":                installationOperatorPolicy,
+	"token":                 tokenPolicy,
+	"tokens":                tokenPolicy,
+}
+
+// actionPolicies maps the last segment of an API route to authorization
+// requirements overriding those of the resource, for actions that affect
+// more than the resource itself.
+var actionPolicies = map[string]resourcePolicy{
+	"migrate":  clusterAdminPolicy,
+	"schedule": clusterAdminPolicy,
+}
+
+// authenticate is a middleware rejecting requests that are not authenticated
+// by the authenticator of the given context, or that the authenticated
+// principal is not authorized to make.
+func authenticate(context *Context) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := context.Clone()
+			c.Logger = c.Logger.WithField("path", r.URL.Path)
+
+			principal, err := c.Authenticator.Authenticate(r)
+			if err != nil {
+				c.Logger.WithError(err).Warn("failed to authenticate request")
+			}
+			if principal == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			c.Principal = principal
+			c.Logger = c.Logger.WithField("principal", principal.Subject)
+
+			status := authorize(c, r)
+			if status != 0 {
+				w.WriteHeader(status)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// authorize checks that the principal of the given context may make the
+// given request, returning the status code to respond with if not.
+//
+// Collection requests made by principals restricted to an owner are rewritten
+// to only return resources belonging to that owner.
+func authorize(c *Context, r *http.Request) int {
+	segments := strings.Split(strings.TrimPrefix(currentRouteTemplate(r), "/api/"), "/")
+	resource := segments[0]
+	if resource == "security" && len(segments) > 1 {
+		resource = segments[1]
+	}
+
+	policy, ok := resourcePolicies[resource]
+	if !ok {
+		policy = resourcePolicy{
+			readScope:  model.APITokenScopeClusterAdmin,
+			writeScope: model.APITokenScopeClusterAdmin,
+		}
+	}
+	if actionPolicy, ok := actionPolicies[segments[len(segments)-1]]; ok && len(segments) > 1 {
+		policy = actionPolicy
+	}
+
+	requiredScope := policy.writeScope
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		requiredScope = policy.readScope
+	}
+	if !c.Principal.HasScope(requiredScope) {
+		c.Logger.Warnf("principal with scope %s is not allowed to make a request requiring scope %s", c.Principal.Scope, requiredScope)
+		return http.StatusForbidden
+	}
+
+	if !c.Principal.IsOwnerRestricted() {
+		return 0
+	}
+	if !policy.ownerScoped {
+		c.Logger.Warnf("principal restricted to owner %s is not allowed to access %s", c.Principal.OwnerID, resource)
+		return http.StatusForbidden
+	}
+
+	return authorizeOwner(c, r, resource, segments)
+}
+
+// authorizeOwner checks that the resources targeted by the given request
+// belong to the owner the principal of the given context is restricted to.
+func authorizeOwner(c *Context, r *http.Request, resource string, segments []string) int {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	switch {
+	case vars["installation"] != "":
+		return authorizeInstallationOwner(c, vars["installation"], http.StatusNotFound)
+	case vars["cluster_installation"] != "":
+		return authorizeClusterInstallationOwner(c, vars["cluster_installation"])
+	case vars["webhook"] != "":
+		return authorizeWebhookOwner(c, vars["webhook"], http.StatusNotFound)
+	case vars["delivery"] != "":
+		return authorizeWebhookDeliveryOwner(c, vars["delivery"])
+	case vars["backup"] != "":
+		return authorizeInstallationBackupOwner(c, vars["backup"])
+
+	case resource == "installations" && len(segments) > 1:
+		// Installation counts span all owners.
+		return http.StatusForbidden
+	case resource == "installations", resource == "webhooks", resource == "events":
+		owner := query.Get("owner")
+		if owner != "" && owner != c.Principal.OwnerID {
+			c.Logger.Warnf("principal restricted to owner %s is not allowed to query owner %s", c.Principal.OwnerID, owner)
+			return http.StatusForbidden
+		}
+		query.Set("owner", c.Principal.OwnerID)
+		r.URL.RawQuery = query.Encode()
+		return 0
+	case resource == "cluster_installations", resource == "installation_backups":
+		return authorizeInstallationOwner(c, query.Get("installation"), http.StatusForbidden)
+	case resource == "webhook_deliveries":
+		return authorizeWebhookOwner(c, query.Get("webhook"), http.StatusForbidden)
+	}
+
+	// Creation requests are checked against the owner in the request body by
+	// the handlers themselves.
+	return 0
+}
+
+func authorizeInstallationOwner(c *Context, installationID string, deniedStatus int) int {
+	if installationID == "" {
+		return http.StatusForbidden
+	}
+
+	installation, err := c.Store.GetInstallation(installationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		return http.StatusInternalServerError
+	}
+	if installation == nil || !c.Principal.CanAccessOwner(installation.OwnerID) {
+		return deniedStatus
+	}
+
+	return 0
+}
+
+func authorizeClusterInstallationOwner(c *Context, clusterInstallationID string) int {
+	clusterInstallation, err := c.Store.GetClusterInstallation(clusterInstallationID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster installation")
+		return http.StatusInternalServerError
+	}
+	if clusterInstallation == nil {
+		return http.StatusNotFound
+	}
+
+	return authorizeInstallationOwner(c, clusterInstallation.InstallationID, http.StatusNotFound)
+}
+
+func authorizeInstallationBackupOwner(c *Context, backupID string) int {
+	backup, err := c.Store.GetInstallationBackup(backupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backup")
+		return http.StatusInternalServerError
+	}
+	if backup == nil {
+		return http.StatusNotFound
+	}
+
+	return authorizeInstallationOwner(c, backup.InstallationID, http.StatusNotFound)
+}
+
+func authorizeWebhookOwner(c *Context, webhookID string, deniedStatus int) int {
+	if webhookID == "" {
+		return http.StatusForbidden
+	}
+
+	webhook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		return http.StatusInternalServerError
+	}
+	if webhook == nil || !c.Principal.CanAccessOwner(webhook.OwnerID) {
+		return deniedStatus
+	}
+
+	return 0
+}
+
+func authorizeWebhookDeliveryOwner(c *Context, deliveryID string) int {
+	delivery, err := c.Store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook delivery")
+		return http.StatusInternalServerError
+	}
+	if delivery == nil {
+		return http.StatusNotFound
+	}
+
+	return authorizeWebhookOwner(c, delivery.WebhookID, http.StatusNotFound)
+}
+
+// withPrincipal returns a copy of the given context carrying the given
+// principal.
+func withPrincipal(ctx context.Context, principal *auth.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// principalFromRequest returns the principal that authenticated the given
+// request, if any.
+func principalFromRequest(r *http.Request) *auth.Principal {
+	principal, _ := r.Context().Value(principalContextKey{}).(*auth.Principal)
+	return principal
+}
+
+// canAccessOwner returns true if the request may act on resources belonging
+// to the given owner. Unauthenticated requests are only possible when
+// authentication is disabled, in which case every owner is accessible.
+func (c *Context) canAccessOwner(ownerID string) bool {
+	return c.Principal == nil || c.Principal.CanAccessOwner(ownerID)
+}
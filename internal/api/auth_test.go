@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthentication(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		Authenticator: auth.NewTokenAuthenticator(sqlStore),
+		Logger:        logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newClient := func(t *testing.T, request *model.CreateAPITokenRequest) *model.Client {
+		request.SetDefaults()
+		token, err := auth.NewAPIToken(request)
+		require.NoError(t, err)
+		err = sqlStore.CreateAPIToken(token)
+		require.NoError(t, err)
+
+		return model.NewClientWithHeaders(ts.URL, map[string]string{
+			"Authorization": "Bearer " + token.Token,
+		})
+	}
+
+	adminClient := newClient(t, &model.CreateAPITokenRequest{Name: "admin", Scope: model.APITokenScopeClusterAdmin})
+	readOnlyClient := newClient(t, &model.CreateAPITokenRequest{Name: "read-only"})
+	operatorClient := newClient(t, &model.CreateAPITokenRequest{Name: "operator", OwnerID: "owner1", Scope: model.APITokenScopeInstallationOperator})
+
+	installation1 := &model.Installation{OwnerID: "owner1", DNS: "owner1.example.com", State: model.InstallationStateStable}
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+	installation2 := &model.Installation{OwnerID: "owner2", DNS: "owner2.example.com", State: model.InstallationStateStable}
+	err = sqlStore.CreateInstallation(installation2)
+	require.NoError(t, err)
+
+	t.Run("no token", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/clusters")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		client := model.NewClientWithHeaders(ts.URL, map[string]string{"Authorization": "Bearer unknown"})
+		_, err := client.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 401")
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		clusters, err := readOnlyClient.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, clusters)
+
+		installations, err := readOnlyClient.GetInstallations(&model.GetInstallationsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, installations, 2)
+
+		_, err = readOnlyClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner1", URL: "https://example.com/read-only"})
+		require.EqualError(t, err, "failed with status code 403")
+
+		_, err = readOnlyClient.GetAPITokens(&model.GetAPITokensRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("installation operator restricted to an owner", func(t *testing.T) {
+		installations, err := operatorClient.GetInstallations(&model.GetInstallationsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, installations, 1)
+		require.Equal(t, installation1.ID, installations[0].ID)
+
+		_, err = operatorClient.GetInstallations(&model.GetInstallationsRequest{OwnerID: "owner2", PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 403")
+
+		installation, err := operatorClient.GetInstallation(installation1.ID, &model.GetInstallationRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, installation)
+
+		installation, err = operatorClient.GetInstallation(installation2.ID, &model.GetInstallationRequest{})
+		require.NoError(t, err)
+		require.Nil(t, installation)
+
+		_, err = operatorClient.CreateInstallation(&model.CreateInstallationRequest{OwnerID: "owner2", DNS: "new.example.com"})
+		require.EqualError(t, err, "failed with status code 403")
+
+		_, err = operatorClient.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 403")
+
+		_, err = operatorClient.GetClusterInstallations(&model.GetClusterInstallationsRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 403")
+
+		clusterInstallations, err := operatorClient.GetClusterInstallations(&model.GetClusterInstallationsRequest{InstallationID: installation1.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, clusterInstallations)
+
+		_, err = operatorClient.GetInstallationBackups(&model.GetInstallationBackupsRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 403")
+
+		_, err = operatorClient.CreateInstallationBackup(installation2.ID)
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = operatorClient.MigrateInstallation(installation1.ID, &model.MigrateInstallationRequest{TargetClusterID: model.NewID()})
+		require.EqualError(t, err, "failed with status code 403")
+
+		webhook, err := operatorClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner1", URL: "https://example.com/operator"})
+		require.NoError(t, err)
+
+		_, err = operatorClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner2", URL: "https://example.com/operator"})
+		require.EqualError(t, err, "failed with status code 403")
+
+		err = operatorClient.DeleteWebhook(webhook.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("cluster admin", func(t *testing.T) {
+		group, err := adminClient.CreateGroup(&model.CreateGroupRequest{Name: "group", Version: "version"})
+		require.NoError(t, err)
+		require.NotNil(t, group)
+
+		token, err := adminClient.CreateAPIToken(&model.CreateAPITokenRequest{Name: "new"})
+		require.NoError(t, err)
+		require.NotEmpty(t, token.Token)
+
+		client := model.NewClientWithHeaders(ts.URL, map[string]string{"Authorization": "Bearer " + token.Token})
+		_, err = client.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+
+		err = adminClient.DeleteAPIToken(token.ID)
+		require.NoError(t, err)
+
+		_, err = client.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.EqualError(t, err, "failed with status code 401")
+	})
+}
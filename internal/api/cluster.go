@@ -22,6 +22,7 @@ func initCluster(apiRouter *mux.Router, context *Context) {
 	clustersRouter := apiRouter.PathPrefix("/clusters").Subrouter()
 	clustersRouter.Handle("", addContext(handleGetClusters)).Methods("GET")
 	clustersRouter.Handle("", addContext(handleCreateCluster)).Methods("POST")
+	clustersRouter.Handle("/import", addContext(handleImportCluster)).Methods("POST")
 
 	clusterRouter := apiRouter.PathPrefix("/cluster/{cluster:[A-Za-z0-9]{26}}").Subrouter()
 	clusterRouter.Handle("", addContext(handleGetCluster)).Methods("GET")
@@ -30,8 +31,11 @@ func initCluster(apiRouter *mux.Router, context *Context) {
 	clusterRouter.Handle("/provision", addContext(handleProvisionCluster)).Methods("POST")
 	clusterRouter.Handle("/kubernetes", addContext(handleUpgradeKubernetes)).Methods("PUT")
 	clusterRouter.Handle("/size", addContext(handleResizeCluster)).Methods("PUT")
+	clusterRouter.Handle("/drain", addContext(handleGetClusterDrainStatus)).Methods("GET")
+	clusterRouter.Handle("/drain", addContext(handleDrainCluster)).Methods("POST")
 	clusterRouter.Handle("/utilities", addContext(handleGetAllUtilityMetadata)).Methods("GET")
 	clusterRouter.Handle("", addContext(handleDeleteCluster)).Methods("DELETE")
+	clusterRouter.Handle("/events", addContext(handleGetClusterEvents)).Methods("GET")
 }
 
 // handleGetCluster responds to GET /api/cluster/{cluster}, returning the cluster in question.
@@ -56,6 +60,27 @@ func handleGetCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, cluster)
 }
 
+// handleGetClusterEvents responds to GET /api/cluster/{cluster}/events,
+// returning the state change history of the cluster in question.
+func handleGetClusterEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	cluster, err := c.Store.GetCluster(clusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if cluster == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	outputEvents(c, w, r, &model.EventFilter{ResourceID: cluster.ID})
+}
+
 // handleGetClusters responds to GET /api/clusters, returning the specified page of clusters.
 func handleGetClusters(c *Context, w http.ResponseWriter, r *http.Request) {
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
@@ -110,8 +135,38 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		ProviderMetadataAWS: &model.AWSMetadata{
 			Zones: createClusterRequest.Zones,
 		},
-		Provisioner: "kops",
-		ProvisionerMetadataKops: &model.KopsMetadata{
+		Provisioner:        createClusterRequest.Provisioner,
+		AllowInstallations: createClusterRequest.AllowInstallations,
+		Labels:             createClusterRequest.Labels,
+		APISecurityLock:    createClusterRequest.APISecurityLock,
+		State:              model.ClusterStateCreationRequested,
+
+		ResourceThreshold:           createClusterRequest.ResourceThreshold,
+		ResourceThresholdScaleValue: createClusterRequest.ResourceThresholdScaleValue,
+		ScaleDownPolicy:             createClusterRequest.ScaleDownPolicy,
+	}
+
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		cluster.ProvisionerMetadataEKS = &model.EKSMetadata{
+			ChangeRequest: &model.EKSMetadataRequestedState{
+				Version: createClusterRequest.Version,
+				NodeGroups: []*model.EKSNodeGroup{{
+					Name:         model.EKSNodeGroupNodes,
+					InstanceType: createClusterRequest.NodeInstanceType,
+					MinCount:     createClusterRequest.NodeMinCount,
+					MaxCount:     createClusterRequest.NodeMaxCount,
+				}},
+			},
+		}
+	case model.ProvisionerLocal:
+		cluster.ProvisionerMetadataLocal = &model.LocalMetadata{
+			ChangeRequest: &model.LocalMetadataRequestedState{
+				Version: createClusterRequest.Version,
+			},
+		}
+	default:
+		cluster.ProvisionerMetadataKops = &model.KopsMetadata{
 			ChangeRequest: &model.KopsMetadataRequestedState{
 				Version:            createClusterRequest.Version,
 				AMI:                createClusterRequest.KopsAMI,
@@ -121,10 +176,12 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 				NodeMinCount:       createClusterRequest.NodeMinCount,
 				NodeMaxCount:       createClusterRequest.NodeMaxCount,
 			},
-		},
-		AllowInstallations: createClusterRequest.AllowInstallations,
-		APISecurityLock:    createClusterRequest.APISecurityLock,
-		State:              model.ClusterStateCreationRequested,
+		}
+
+		if len(createClusterRequest.NodeSpotMaxPrice) != 0 {
+			cluster.ProvisionerMetadataKops.ChangeRequest.NodeSpotMaxPrice = &createClusterRequest.NodeSpotMaxPrice
+			cluster.ProvisionerMetadataKops.ChangeRequest.NodeMixedInstanceTypes = createClusterRequest.NodeMixedInstanceTypes
+		}
 	}
 
 	err = cluster.SetUtilityDesiredVersions(createClusterRequest.DesiredUtilityVersions)
@@ -148,7 +205,83 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		OldState:  "n/a",
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, cluster)
+}
+
+// handleImportCluster responds to POST /api/clusters/import, importing a
+// cluster built outside of the provisioner. The kubeconfig of the cluster is
+// encrypted before being stored.
+func handleImportCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	importClusterRequest, err := model.NewImportClusterRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if len(c.EncryptionKey) == 0 {
+		c.Logger.Error("unable to import cluster without an encryption key configured")
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	// The kubeconfig is bound to the ID of the cluster so that it can't be
+	// used with any other cluster.
+	clusterID := model.NewID()
+	encryptedKubeconfig, err := model.EncryptSecret(c.EncryptionKey, []byte(importClusterRequest.Kubeconfig), []byte(clusterID))
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to encrypt kubeconfig")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	cluster := model.Cluster{
+		ID:          clusterID,
+		Provider:    model.ProviderAWS,
+		Provisioner: model.ProvisionerExternal,
+		ProvisionerMetadataExternal: &model.ExternalMetadata{
+			Name: importClusterRequest.Name,
+		},
+		EncryptedKubeconfig: encryptedKubeconfig,
+		AllowInstallations:  importClusterRequest.AllowInstallations,
+		Labels:              importClusterRequest.Labels,
+		APISecurityLock:     importClusterRequest.APISecurityLock,
+		ResourceThreshold:   importClusterRequest.ResourceThreshold,
+		ScaleDownPolicy:     model.ClusterScaleDownPolicyNone,
+		State:               model.ClusterStateCreationRequested,
+	}
+
+	err = cluster.SetUtilityDesiredVersions(importClusterRequest.DesiredUtilityVersions)
+	if err != nil {
+		c.Logger.WithError(err).Error("provided utility metadata could not be applied without error")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = c.Store.CreateCluster(&cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to import cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateCreationRequested,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -202,7 +335,7 @@ func handleRetryCreateCluster(c *Context, w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -275,7 +408,7 @@ func handleProvisionCluster(c *Context, w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -317,8 +450,38 @@ func handleUpdateClusterConfiguration(c *Context, w http.ResponseWriter, r *http
 		return
 	}
 
+	if cluster.Draining && updateClusterRequest.AllowInstallations {
+		c.Logger.Warn("unable to allow installations while the cluster is being drained")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	changed := false
 	if cluster.AllowInstallations != updateClusterRequest.AllowInstallations {
 		cluster.AllowInstallations = updateClusterRequest.AllowInstallations
+		changed = true
+	}
+	if updateClusterRequest.Labels != nil {
+		cluster.Labels = updateClusterRequest.Labels
+		if len(cluster.Labels) == 0 {
+			cluster.Labels = nil
+		}
+		changed = true
+	}
+	if updateClusterRequest.ResourceThreshold != nil {
+		cluster.ResourceThreshold = updateClusterRequest.ResourceThreshold
+		changed = true
+	}
+	if updateClusterRequest.ResourceThresholdScaleValue != nil {
+		cluster.ResourceThresholdScaleValue = updateClusterRequest.ResourceThresholdScaleValue
+		changed = true
+	}
+	if updateClusterRequest.ScaleDownPolicy != nil {
+		cluster.ScaleDownPolicy = *updateClusterRequest.ScaleDownPolicy
+		changed = true
+	}
+
+	if changed {
 		err := c.Store.UpdateCluster(cluster)
 		if err != nil {
 			c.Logger.WithError(err).Error("failed to update cluster")
@@ -361,6 +524,13 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS, model.ProvisionerExternal, model.ProvisionerLocal:
+		c.Logger.Warnf("unable to upgrade cluster provisioned with %s", cluster.Provisioner)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	oldState := cluster.State
 	newState := model.ClusterStateUpgradeRequested
 
@@ -388,7 +558,7 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 				Timestamp: time.Now().UnixNano(),
 			}
 
-			err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+			err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 			if err != nil {
 				c.Logger.WithError(err).Error("Unable to process and send webhooks")
 			}
@@ -430,11 +600,17 @@ func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// One more check that can't be done without both the request and the cluster.
-	if resizeClusterRequest.NodeMinCount == nil &&
-		resizeClusterRequest.NodeMaxCount != nil &&
-		*resizeClusterRequest.NodeMaxCount < cluster.ProvisionerMetadataKops.NodeMinCount {
-		c.Logger.Error("resize patch would set max node count lower than min node count")
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS, model.ProvisionerExternal, model.ProvisionerLocal:
+		c.Logger.Warnf("unable to resize cluster provisioned with %s", cluster.Provisioner)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// More checks that can't be done without both the request and the cluster.
+	err = resizeClusterRequest.ValidateForMetadata(cluster.ProvisionerMetadataKops)
+	if err != nil {
+		c.Logger.WithError(err).Error("invalid resize patch for cluster")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -466,7 +642,7 @@ func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 				Timestamp: time.Now().UnixNano(),
 			}
 
-			err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+			err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 			if err != nil {
 				c.Logger.WithError(err).Error("Unable to process and send webhooks")
 			}
@@ -481,6 +657,117 @@ func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, cluster)
 }
 
+// handleDrainCluster responds to POST /api/cluster/{cluster}/drain, stopping
+// the scheduling of installations on the cluster and moving all of its
+// installations to other clusters.
+func handleDrainCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	drainClusterRequest, err := model.NewDrainClusterRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cluster, status, unlockOnce := lockCluster(c, clusterID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if cluster.APISecurityLock {
+		logSecurityLockConflict("cluster", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if cluster.DeleteAt != 0 ||
+		cluster.State == model.ClusterStateDeletionRequested ||
+		cluster.State == model.ClusterStateDeletionFailed {
+		c.Logger.Warnf("unable to drain cluster while in state %s", cluster.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cluster.AllowInstallations = false
+	cluster.Draining = true
+	cluster.DrainMaxRolling = drainClusterRequest.MaxRolling
+	err = c.Store.UpdateCluster(cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to mark cluster as draining")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	drainStatus, err := getClusterDrainStatus(c, cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get cluster drain status")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, drainStatus)
+}
+
+// handleGetClusterDrainStatus responds to GET /api/cluster/{cluster}/drain,
+// returning the progress of draining the cluster in question.
+func handleGetClusterDrainStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	cluster, err := c.Store.GetCluster(clusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if cluster == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	drainStatus, err := getClusterDrainStatus(c, cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get cluster drain status")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, drainStatus)
+}
+
+func getClusterDrainStatus(c *Context, cluster *model.Cluster) (*model.ClusterDrainStatus, error) {
+	drainMetadata, err := c.Store.GetClusterDrainMetadata(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ClusterDrainStatus{
+		ClusterID:                  cluster.ID,
+		Draining:                   cluster.Draining,
+		MaxRolling:                 cluster.DrainMaxRolling,
+		InstallationsRemaining:     drainMetadata.InstallationTotalCount,
+		InstallationsMigrating:     drainMetadata.InstallationMigratingCount,
+		InstallationsUpdating:      drainMetadata.InstallationUpdatingCount,
+		InstallationsHibernating:   drainMetadata.InstallationHibernatingCount,
+		InstallationsFailed:        drainMetadata.InstallationFailedCount,
+		InstallationsNotMigratable: drainMetadata.InstallationNotMigratableCount,
+		BlockedInstallationIDs:     drainMetadata.InstallationIDsBlocked,
+	}, nil
+}
+
 // handleDeleteCluster responds to DELETE /api/cluster/{cluster}, beginning the process of
 // deleting the cluster.
 func handleDeleteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -543,7 +830,7 @@ func handleDeleteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
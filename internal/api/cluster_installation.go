@@ -27,6 +27,7 @@ func initClusterInstallation(apiRouter *mux.Router, context *Context) {
 	clusterInstallationRouter.Handle("/config", addContext(handleSetClusterInstallationConfig)).Methods("PUT")
 	clusterInstallationRouter.Handle("/exec/{command}", addContext(handleRunClusterInstallationExecCommand)).Methods("POST")
 	clusterInstallationRouter.Handle("/mattermost_cli", addContext(handleRunClusterInstallationMattermostCLI)).Methods("POST")
+	clusterInstallationRouter.Handle("/events", addContext(handleGetClusterInstallationEvents)).Methods("GET")
 }
 
 // handleGetClusterInstallations responds to GET /api/cluster_installations, returning the specified page of cluster installations.
@@ -86,6 +87,28 @@ func handleGetClusterInstallation(c *Context, w http.ResponseWriter, r *http.Req
 	outputJSON(c, w, clusterInstallation)
 }
 
+// handleGetClusterInstallationEvents responds to GET /api/cluster_installation/{cluster_installation}/events,
+// returning the state change history of the cluster installation in
+// question.
+func handleGetClusterInstallationEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterInstallationID := vars["cluster_installation"]
+	c.Logger = c.Logger.WithField("cluster_installation", clusterInstallationID)
+
+	clusterInstallation, err := c.Store.GetClusterInstallation(clusterInstallationID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if clusterInstallation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	outputEvents(c, w, r, &model.EventFilter{ResourceID: clusterInstallation.ID})
+}
+
 // handleGetClusterInstallationConfig responds to GET /api/cluster_installation/{cluster_installation}/config, returning the config for the cluster installation in question.
 func handleGetClusterInstallationConfig(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -308,6 +309,191 @@ func TestCreateCluster(t *testing.T) {
 		require.Equal(t, model.ClusterStateCreationRequested, cluster.State)
 		// TODO: more fields...
 	})
+
+	t.Run("spot instances", func(t *testing.T) {
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:               model.ProviderAWS,
+			Zones:                  []string{"zone"},
+			NodeSpotMaxPrice:       "0.05",
+			NodeMixedInstanceTypes: []string{"m5.large", "m5a.large"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, sToP("0.05"), cluster.ProvisionerMetadataKops.ChangeRequest.NodeSpotMaxPrice)
+		require.Equal(t, []string{"m5.large", "m5a.large"}, cluster.ProvisionerMetadataKops.ChangeRequest.NodeMixedInstanceTypes)
+	})
+
+	t.Run("invalid spot max price", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:         model.ProviderAWS,
+			Zones:            []string{"zone"},
+			NodeSpotMaxPrice: "cheap",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("eks provisioner", func(t *testing.T) {
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:         model.ProviderAWS,
+			Provisioner:      model.ProvisionerEKS,
+			Zones:            []string{"zone"},
+			NodeInstanceType: "m5.xlarge",
+			NodeMinCount:     3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.ProvisionerEKS, cluster.Provisioner)
+		require.Nil(t, cluster.ProvisionerMetadataKops)
+		require.NotNil(t, cluster.ProvisionerMetadataEKS)
+		require.Equal(t, "latest", cluster.ProvisionerMetadataEKS.ChangeRequest.Version)
+		require.Equal(t, []*model.EKSNodeGroup{{
+			Name:         model.EKSNodeGroupNodes,
+			InstanceType: "m5.xlarge",
+			MinCount:     3,
+			MaxCount:     3,
+		}}, cluster.ProvisionerMetadataEKS.ChangeRequest.NodeGroups)
+
+		cluster, err = client.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ProvisionerEKS, cluster.Provisioner)
+		require.Nil(t, cluster.ProvisionerMetadataKops)
+		require.NotNil(t, cluster.ProvisionerMetadataEKS)
+
+		_, err = client.UpgradeCluster(cluster.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.16.0")})
+		require.EqualError(t, err, "failed with status code 400")
+
+		_, err = client.ResizeCluster(cluster.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.2xlarge")})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("local provisioner", func(t *testing.T) {
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:    model.ProviderAWS,
+			Provisioner: model.ProvisionerLocal,
+			Version:     "1.18.2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.ProvisionerLocal, cluster.Provisioner)
+		require.Nil(t, cluster.ProvisionerMetadataKops)
+		require.NotNil(t, cluster.ProvisionerMetadataLocal)
+		require.Equal(t, "1.18.2", cluster.ProvisionerMetadataLocal.ChangeRequest.Version)
+
+		cluster, err = client.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ProvisionerLocal, cluster.Provisioner)
+		require.NotNil(t, cluster.ProvisionerMetadataLocal)
+
+		_, err = client.UpgradeCluster(cluster.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.19.1")})
+		require.EqualError(t, err, "failed with status code 400")
+
+		_, err = client.ResizeCluster(cluster.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.2xlarge")})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("invalid provisioner", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:    model.ProviderAWS,
+			Provisioner: "gke",
+			Zones:       []string{"zone"},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: platform
+clusters:
+- name: platform
+  cluster:
+    server: https://platform.example.com
+    certificate-authority-data: Y2VydGlmaWNhdGU=
+contexts:
+- name: platform
+  context:
+    cluster: platform
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret-token
+`
+
+func TestImportCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	encryptionKey := bytes.Repeat([]byte{1}, model.EncryptionKeySize)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		EncryptionKey: encryptionKey,
+		Logger:        logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("missing kubeconfig", func(t *testing.T) {
+		_, err := client.ImportCluster(&model.ImportClusterRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("kubeconfig with exec plugin", func(t *testing.T) {
+		kubeconfig := strings.Replace(testKubeconfig, "token: secret-token", "exec:\n      apiVersion: client.authentication.k8s.io/v1alpha1\n      command: /bin/sh", 1)
+
+		_, err := client.ImportCluster(&model.ImportClusterRequest{Kubeconfig: kubeconfig})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("without encryption key", func(t *testing.T) {
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{
+			Store:      sqlStore,
+			Supervisor: &mockSupervisor{},
+			Logger:     logger,
+		})
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		_, err := model.NewClient(ts.URL).ImportCluster(&model.ImportClusterRequest{Kubeconfig: testKubeconfig})
+		require.EqualError(t, err, "failed with status code 501")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		cluster, err := client.ImportCluster(&model.ImportClusterRequest{
+			Kubeconfig:         testKubeconfig,
+			Name:               "platform",
+			AllowInstallations: true,
+			Labels:             map[string]string{"team": "platform"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.ProvisionerExternal, cluster.Provisioner)
+		require.Equal(t, model.ClusterStateCreationRequested, cluster.State)
+		require.Equal(t, "platform", cluster.ProvisionerMetadataExternal.Name)
+		require.True(t, cluster.AllowInstallations)
+		require.Equal(t, map[string]string{"team": "platform"}, cluster.Labels)
+		require.Empty(t, cluster.EncryptedKubeconfig)
+
+		storedCluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.NotContains(t, string(storedCluster.EncryptedKubeconfig), "apiVersion")
+		kubeconfig, err := model.DecryptSecret(encryptionKey, storedCluster.EncryptedKubeconfig, []byte(cluster.ID))
+		require.NoError(t, err)
+		require.Equal(t, testKubeconfig, string(kubeconfig))
+
+		t.Run("upgrade and resize are rejected", func(t *testing.T) {
+			storedCluster.State = model.ClusterStateStable
+			err = sqlStore.UpdateCluster(storedCluster)
+			require.NoError(t, err)
+
+			_, err = client.UpgradeCluster(cluster.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.16.0")})
+			require.EqualError(t, err, "failed with status code 400")
+
+			_, err = client.ResizeCluster(cluster.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.2xlarge")})
+			require.EqualError(t, err, "failed with status code 400")
+		})
+	})
 }
 
 func TestRetryCreateCluster(t *testing.T) {
@@ -783,6 +969,46 @@ func TestUpdateClusterConfiguration(t *testing.T) {
 		assert.Equal(t, model.ClusterStateStable, cluster1.State)
 		assert.False(t, cluster1.AllowInstallations)
 	})
+
+	t.Run("labels", func(t *testing.T) {
+		labels := map[string]string{"zone": "us-east-1b", "tier": "enterprise"}
+		clusterResp, err := client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{Labels: labels})
+		require.NoError(t, err)
+		assert.Equal(t, labels, clusterResp.Labels)
+
+		clusterResp, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, labels, clusterResp.Labels)
+
+		_, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{Labels: map[string]string{"zone": "us east"}})
+		require.EqualError(t, err, "failed with status code 400")
+
+		clusterResp, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{Labels: map[string]string{}})
+		require.NoError(t, err)
+		assert.Empty(t, clusterResp.Labels)
+	})
+
+	t.Run("scheduling settings", func(t *testing.T) {
+		clusterResp, err := client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{
+			ResourceThreshold:           iToP(60),
+			ResourceThresholdScaleValue: iToP(2),
+			ScaleDownPolicy:             sToP(model.ClusterScaleDownPolicyUtilization),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, iToP(60), clusterResp.ResourceThreshold)
+		assert.Equal(t, iToP(2), clusterResp.ResourceThresholdScaleValue)
+		assert.Equal(t, model.ClusterScaleDownPolicyUtilization, clusterResp.ScaleDownPolicy)
+
+		clusterResp, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, iToP(60), clusterResp.ResourceThreshold)
+
+		_, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{ResourceThreshold: iToP(101)})
+		require.EqualError(t, err, "failed with status code 400")
+
+		_, err = client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{ScaleDownPolicy: sToP("unknown")})
+		require.EqualError(t, err, "failed with status code 400")
+	})
 }
 
 func TestResizeCluster(t *testing.T) {
@@ -917,6 +1143,79 @@ func TestResizeCluster(t *testing.T) {
 		assert.Nil(t, clusterResp)
 	})
 
+	t.Run("while stable, create instance group", func(t *testing.T) {
+		cluster1.State = model.ClusterStateStable
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		min := int64(2)
+		clusterResp, err := client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{
+			InstanceGroup:    sToP("memory"),
+			NodeInstanceType: sToP("r5.xlarge"),
+			NodeMinCount:     &min,
+			NodeLabels:       map[string]string{"pool": "memory"},
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, clusterResp)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster1.State)
+		assert.Equal(t, &model.KopsMetadataRequestedState{
+			InstanceGroup:    "memory",
+			NodeInstanceType: "r5.xlarge",
+			NodeMinCount:     2,
+			NodeMaxCount:     2,
+			NodeLabels:       map[string]string{"pool": "memory"},
+		}, cluster1.ProvisionerMetadataKops.ChangeRequest)
+	})
+
+	t.Run("while stable, create instance group without instance type", func(t *testing.T) {
+		cluster1.State = model.ClusterStateStable
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		min := int64(2)
+		clusterResp, err := client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{
+			InstanceGroup: sToP("memory"),
+			NodeMinCount:  &min,
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+	})
+
+	t.Run("while stable, delete instance group", func(t *testing.T) {
+		cluster1.State = model.ClusterStateStable
+		cluster1.ProvisionerMetadataKops.InstanceGroups = []*model.KopsInstanceGroup{
+			{Name: model.KopsInstanceGroupNodes, InstanceType: "m5.large", MinCount: 5, MaxCount: 5},
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2},
+		}
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{
+			InstanceGroup: sToP("spot"),
+			Delete:        true,
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+
+		clusterResp, err = client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{
+			InstanceGroup: sToP("memory"),
+			Delete:        true,
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, clusterResp)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster1.State)
+		assert.Equal(t, &model.KopsMetadataRequestedState{
+			InstanceGroup:       "memory",
+			DeleteInstanceGroup: true,
+		}, cluster1.ProvisionerMetadataKops.ChangeRequest)
+	})
+
 	t.Run("while upgrading", func(t *testing.T) {
 		cluster1.State = model.ClusterStateUpgradeRequested
 		err = sqlStore.UpdateCluster(cluster1)
@@ -938,6 +1237,131 @@ func TestResizeCluster(t *testing.T) {
 	})
 }
 
+func TestDrainCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider:           model.ProviderAWS,
+		Zones:              []string{"zone"},
+		AllowInstallations: true,
+	})
+	require.NoError(t, err)
+
+	installation1 := &model.Installation{
+		OwnerID:   model.NewID(),
+		DNS:       "dns1.example.com",
+		Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+		Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+		State:     model.InstallationStateStable,
+	}
+	err = sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+		ClusterID:      cluster1.ID,
+		InstallationID: installation1.ID,
+		State:          model.ClusterInstallationStateStable,
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		drainStatus, err := client.DrainCluster(model.NewID(), &model.DrainClusterRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, drainStatus)
+
+		drainStatus, err = client.GetClusterDrainStatus(model.NewID())
+		require.NoError(t, err)
+		assert.Nil(t, drainStatus)
+	})
+
+	t.Run("invalid max rolling", func(t *testing.T) {
+		drainStatus, err := client.DrainCluster(cluster1.ID, &model.DrainClusterRequest{MaxRolling: -1})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, drainStatus)
+	})
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		err = sqlStore.LockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+
+		drainStatus, err := client.DrainCluster(cluster1.ID, &model.DrainClusterRequest{})
+		require.EqualError(t, err, "failed with status code 403")
+		assert.Nil(t, drainStatus)
+
+		err = sqlStore.UnlockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("not draining", func(t *testing.T) {
+		drainStatus, err := client.GetClusterDrainStatus(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &model.ClusterDrainStatus{
+			ClusterID:              cluster1.ID,
+			InstallationsRemaining: 1,
+		}, drainStatus)
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		drainStatus, err := client.DrainCluster(cluster1.ID, &model.DrainClusterRequest{MaxRolling: 5})
+		require.NoError(t, err)
+		assert.Equal(t, &model.ClusterDrainStatus{
+			ClusterID:              cluster1.ID,
+			Draining:               true,
+			MaxRolling:             5,
+			InstallationsRemaining: 1,
+		}, drainStatus)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.True(t, cluster1.Draining)
+		assert.False(t, cluster1.AllowInstallations)
+	})
+
+	t.Run("allow installations while draining", func(t *testing.T) {
+		clusterResp, err := client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{AllowInstallations: true})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+	})
+
+	t.Run("migrate installation to draining cluster", func(t *testing.T) {
+		installation2 := &model.Installation{
+			OwnerID:   model.NewID(),
+			DNS:       "dns2.example.com",
+			Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			State:     model.InstallationStateStable,
+		}
+		err = sqlStore.CreateInstallation(installation2)
+		require.NoError(t, err)
+
+		installationResp, err := client.MigrateInstallation(installation2.ID, &model.MigrateInstallationRequest{TargetClusterID: cluster1.ID})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, installationResp)
+	})
+
+	t.Run("while deleting", func(t *testing.T) {
+		cluster1.State = model.ClusterStateDeletionRequested
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		drainStatus, err := client.DrainCluster(cluster1.ID, &model.DrainClusterRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, drainStatus)
+	})
+}
+
 func TestDeleteCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -41,6 +41,30 @@ func (s *mockProvisioner) GetClusterResources(*model.Cluster, bool) (*k8s.Cluste
 	return nil, nil
 }
 
+type mockScheduler struct {
+}
+
+func (s *mockScheduler) Schedule(installation *model.Installation, clusters []*model.Cluster) []*model.ClusterPlacement {
+	var placements []*model.ClusterPlacement
+	for _, cluster := range clusters {
+		placement := &model.ClusterPlacement{ClusterID: cluster.ID, Schedulable: cluster.AllowInstallations}
+		if !placement.Schedulable {
+			placement.Reason = "cluster does not allow installations"
+		}
+		placements = append(placements, placement)
+	}
+
+	return placements
+}
+
+func (s *mockScheduler) PolicyName() string {
+	return model.SchedulingPolicyFirstFit
+}
+
 func sToP(s string) *string {
 	return &s
 }
+
+func iToP(i int) *int {
+	return &i
+}
@@ -5,6 +5,8 @@
 package api
 
 import (
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/sirupsen/logrus"
@@ -26,6 +28,7 @@ type Store interface {
 	LockClusterAPI(clusterID string) error
 	UnlockClusterAPI(clusterID string) error
 	DeleteCluster(clusterID string) error
+	GetClusterDrainMetadata(clusterID string) (*store.ClusterDrainMetadata, error)
 
 	CreateInstallation(installation *model.Installation) error
 	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
@@ -56,9 +59,41 @@ type Store interface {
 	CreateWebhook(webhook *model.Webhook) error
 	GetWebhook(webhookID string) (*model.Webhook, error)
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	UpdateWebhook(webhook *model.Webhook) error
 	DeleteWebhook(webhookID string) error
 
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetWebhookDelivery(deliveryID string) (*model.WebhookDelivery, error)
+	GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
+	LockWebhookDelivery(deliveryID, lockerID string) (bool, error)
+	UnlockWebhookDelivery(deliveryID, lockerID string, force bool) (bool, error)
+
+	CreateInstallationBackup(backup *model.InstallationBackup) error
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+	GetInstallationBackups(filter *model.InstallationBackupFilter) ([]*model.InstallationBackup, error)
+	UpdateInstallationBackup(backup *model.InstallationBackup) error
+	LockInstallationBackup(backupID, lockerID string) (bool, error)
+	UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error)
+
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+	GetEvents(filter *model.EventFilter) ([]*model.Event, error)
+	GetEventSequences(afterSequence int64, limit int) ([]int64, error)
+	GetLatestEventSequence() (int64, error)
+
+	GetMultitenantDatabase(multitenantDatabaseID string) (*model.MultitenantDatabase, error)
 	GetMultitenantDatabases(filter *model.MultitenantDatabaseFilter) ([]*model.MultitenantDatabase, error)
+	UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	LockMultitenantDatabase(multitenantDatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantDatabaseID, lockerID string, force bool) (bool, error)
+	LockMultitenantDatabaseAPI(multitenantDatabaseID string) error
+	UnlockMultitenantDatabaseAPI(multitenantDatabaseID string) error
+
+	CreateAPIToken(token *model.APIToken) error
+	GetAPIToken(tokenID string) (*model.APIToken, error)
+	GetAPITokens(filter *model.APITokenFilter) ([]*model.APIToken, error)
+	DeleteAPIToken(tokenID string) error
 }
 
 // Provisioner describes the interface required to communicate with the Kubernetes cluster.
@@ -68,6 +103,13 @@ type Provisioner interface {
 	GetClusterResources(*model.Cluster, bool) (*k8s.ClusterResources, error)
 }
 
+// Scheduler describes the interface required to preview the placement of
+// installations on clusters.
+type Scheduler interface {
+	Schedule(installation *model.Installation, clusters []*model.Cluster) []*model.ClusterPlacement
+	PolicyName() string
+}
+
 // Context provides the API with all necessary data and interfaces for responding to requests.
 //
 // It is cloned before each request, allowing per-request changes such as logger annotations.
@@ -75,16 +117,45 @@ type Context struct {
 	Store       Store
 	Supervisor  Supervisor
 	Provisioner Provisioner
-	RequestID   string
-	Logger      logrus.FieldLogger
+	Scheduler   Scheduler
+	// Authenticator, if set, is required to authenticate every API request.
+	Authenticator auth.Authenticator
+	// EncryptionKey encrypts the kubeconfig of imported clusters and the
+	// credentials of external databases and filestores. None of them can be
+	// stored when it isn't set.
+	EncryptionKey []byte
+	// ExternalDatabaseSecretPrefix is the required name prefix of the AWS
+	// Secrets Manager secrets external databases may reference. No secret can
+	// be referenced when it isn't set.
+	ExternalDatabaseSecretPrefix string
+	// Principal is the authenticated caller of the current request, if any.
+	Principal  *auth.Principal
+	InstanceID string
+	RequestID  string
+	Logger     logrus.FieldLogger
 }
 
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
 func (c *Context) Clone() *Context {
 	return &Context{
-		Store:       c.Store,
-		Supervisor:  c.Supervisor,
-		Provisioner: c.Provisioner,
-		Logger:      c.Logger,
+		Store:                        c.Store,
+		Supervisor:                   c.Supervisor,
+		Provisioner:                  c.Provisioner,
+		Scheduler:                    c.Scheduler,
+		Authenticator:                c.Authenticator,
+		EncryptionKey:                c.EncryptionKey,
+		ExternalDatabaseSecretPrefix: c.ExternalDatabaseSecretPrefix,
+		InstanceID:                   c.InstanceID,
+		Logger:                       c.Logger,
+	}
+}
+
+// newEvent creates an event for the given webhook payload, attributed to the
+// current request.
+func (c *Context) newEvent(payload *model.WebhookPayload) *model.Event {
+	return &model.Event{
+		Payload:    payload,
+		InstanceID: c.InstanceID,
+		RequestID:  c.RequestID,
 	}
 }
@@ -17,8 +17,14 @@ func initDatabases(apiRouter *mux.Router, context *Context) {
 		return newContextHandler(context, handler)
 	}
 
-	databaseRouter := apiRouter.PathPrefix("/databases").Subrouter()
-	databaseRouter.Handle("", addContext(handleGetDatabases)).Methods("GET")
+	databasesRouter := apiRouter.PathPrefix("/databases").Subrouter()
+	databasesRouter.Handle("", addContext(handleGetDatabases)).Methods("GET")
+	databasesRouter.Handle("/rebalance", addContext(handleGetDatabasesRebalancePlan)).Methods("GET")
+
+	databaseRouter := apiRouter.PathPrefix("/database/{database:[A-Za-z0-9_-]+}").Subrouter()
+	databaseRouter.Handle("", addContext(handleGetDatabase)).Methods("GET")
+	databaseRouter.Handle("", addContext(handleUpdateDatabase)).Methods("PUT")
+	databaseRouter.Handle("/unlock", addContext(handleUnlockDatabase)).Methods("POST")
 }
 
 // handleGetDatabases responds to GET /api/databases, returning a list of
@@ -56,3 +62,138 @@ func handleGetDatabases(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	outputJSON(c, w, databases)
 }
+
+// handleGetDatabasesRebalancePlan responds to GET /api/databases/rebalance,
+// returning the installation database moves that would even out the load
+// between multitenant databases.
+func handleGetDatabasesRebalancePlan(c *Context, w http.ResponseWriter, r *http.Request) {
+	vpcID, databaseType := parseDatabaseListRequest(r.URL)
+
+	databases, err := c.Store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		VpcID:                 vpcID,
+		DatabaseType:          databaseType,
+		PerPage:               model.AllPerPage,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant databases")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	moves := model.PlanMultitenantDatabaseRebalance(databases)
+	if moves == nil {
+		moves = []*model.MultitenantDatabaseMove{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, moves)
+}
+
+// handleGetDatabase responds to GET /api/database/{database}, returning the
+// multitenant database in question along with its installations.
+func handleGetDatabase(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseID := vars["database"]
+	c.Logger = c.Logger.WithField("database", databaseID)
+
+	database, err := c.Store.GetMultitenantDatabase(databaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if database == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, database)
+}
+
+// handleUpdateDatabase responds to PUT /api/database/{database}, updating the
+// installation settings of the multitenant database.
+func handleUpdateDatabase(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseID := vars["database"]
+	c.Logger = c.Logger.WithField("database", databaseID)
+
+	updateDatabaseRequest, err := model.NewUpdateMultitenantDatabaseRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	database, status, unlockOnce := lockMultitenantDatabase(c, databaseID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if database.APISecurityLock {
+		logSecurityLockConflict("database", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if database.State == model.MultitenantDatabaseStateDeleted {
+		c.Logger.Warn("unable to update a deleted multitenant database")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	updateDatabaseRequest.Apply(database)
+	err = c.Store.UpdateMultitenantDatabase(database)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, database)
+}
+
+// handleUnlockDatabase responds to POST /api/database/{database}/unlock,
+// forcefully releasing a lock held on the multitenant database.
+func handleUnlockDatabase(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseID := vars["database"]
+	c.Logger = c.Logger.WithField("database", databaseID)
+
+	database, err := c.Store.GetMultitenantDatabase(databaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if database == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if database.APISecurityLock {
+		logSecurityLockConflict("database", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if database.LockAcquiredBy != nil {
+		c.Logger.Warnf("Forcefully releasing multitenant database lock held by %s", *database.LockAcquiredBy)
+
+		_, err = c.Store.UnlockMultitenantDatabase(database.ID, "", true)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to unlock multitenant database")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDatabase(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	database1 := &model.MultitenantDatabase{
+		ID:                 "rds-cluster-multitenant-1234-a",
+		VpcID:              "vpc-1",
+		DatabaseType:       model.DatabaseEngineTypeMySQL,
+		AllowInstallations: true,
+		Installations:      model.MultitenantDatabaseInstallations{"installation1", "installation2"},
+	}
+	err := sqlStore.CreateMultitenantDatabase(database1)
+	require.NoError(t, err)
+
+	t.Run("unknown database", func(t *testing.T) {
+		database, err := client.GetMultitenantDatabase("unknown")
+		require.NoError(t, err)
+		require.Nil(t, database)
+	})
+
+	t.Run("get database", func(t *testing.T) {
+		database, err := client.GetMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+		require.NotNil(t, database)
+		assert.Equal(t, database1.ID, database.ID)
+		assert.True(t, database.AllowInstallations)
+		assert.Equal(t, database1.Installations, database.Installations)
+	})
+}
+
+func TestGetDatabasesRebalancePlan(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("no databases", func(t *testing.T) {
+		moves, err := client.GetMultitenantDatabaseRebalancePlan(&model.GetDatabasesRequest{})
+		require.NoError(t, err)
+		require.Empty(t, moves)
+	})
+
+	for _, database := range []*model.MultitenantDatabase{
+		{
+			ID:                 "rds-cluster-multitenant-1234-a",
+			VpcID:              "vpc-1",
+			DatabaseType:       model.DatabaseEngineTypeMySQL,
+			State:              model.MultitenantDatabaseStateStable,
+			AllowInstallations: true,
+			Installations:      model.MultitenantDatabaseInstallations{"installation1", "installation2", "installation3"},
+		},
+		{
+			ID:                 "rds-cluster-multitenant-1234-b",
+			VpcID:              "vpc-1",
+			DatabaseType:       model.DatabaseEngineTypeMySQL,
+			State:              model.MultitenantDatabaseStateStable,
+			AllowInstallations: true,
+		},
+		{
+			ID:                 "rds-cluster-multitenant-1234-c",
+			VpcID:              "vpc-2",
+			DatabaseType:       model.DatabaseEngineTypeMySQL,
+			State:              model.MultitenantDatabaseStateStable,
+			AllowInstallations: true,
+		},
+	} {
+		err := sqlStore.CreateMultitenantDatabase(database)
+		require.NoError(t, err)
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		moves, err := client.GetMultitenantDatabaseRebalancePlan(&model.GetDatabasesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.MultitenantDatabaseMove{
+			{InstallationID: "installation3", SourceDatabaseID: "rds-cluster-multitenant-1234-a", TargetDatabaseID: "rds-cluster-multitenant-1234-b"},
+		}, moves)
+	})
+
+	t.Run("other vpc", func(t *testing.T) {
+		moves, err := client.GetMultitenantDatabaseRebalancePlan(&model.GetDatabasesRequest{VpcID: "vpc-2"})
+		require.NoError(t, err)
+		require.Empty(t, moves)
+	})
+}
+
+func TestUpdateDatabase(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	database1 := &model.MultitenantDatabase{
+		ID:                 "rds-cluster-multitenant-1234-a",
+		VpcID:              "vpc-1",
+		DatabaseType:       model.DatabaseEngineTypeMySQL,
+		AllowInstallations: true,
+	}
+	err := sqlStore.CreateMultitenantDatabase(database1)
+	require.NoError(t, err)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		httpRequest, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/database/%s", ts.URL, database1.ID), bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(httpRequest)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid max installations", func(t *testing.T) {
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			MaxInstallations: iToP(-1),
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, database)
+	})
+
+	t.Run("unknown database", func(t *testing.T) {
+		database, err := client.UpdateMultitenantDatabase("unknown", &model.UpdateMultitenantDatabaseRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, database)
+	})
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		err = sqlStore.LockMultitenantDatabaseAPI(database1.ID)
+		require.NoError(t, err)
+
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			MaxInstallations: iToP(5),
+		})
+		require.EqualError(t, err, "failed with status code 403")
+		assert.Nil(t, database)
+
+		err = sqlStore.UnlockMultitenantDatabaseAPI(database1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("while locked", func(t *testing.T) {
+		locked, err := sqlStore.LockMultitenantDatabase(database1.ID, model.NewID())
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			MaxInstallations: iToP(5),
+		})
+		require.EqualError(t, err, "failed with status code 409")
+		assert.Nil(t, database)
+
+		err = client.UnlockMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+
+		database, err = client.GetMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+		assert.Nil(t, database.LockAcquiredBy)
+	})
+
+	t.Run("set installation limit", func(t *testing.T) {
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			MaxInstallations: iToP(5),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5, database.MaxInstallations)
+		assert.True(t, database.AllowInstallations)
+
+		database, err = client.GetMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, database.MaxInstallations)
+	})
+
+	t.Run("stop new installations", func(t *testing.T) {
+		allowInstallations := false
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			AllowInstallations: &allowInstallations,
+		})
+		require.NoError(t, err)
+		assert.False(t, database.AllowInstallations)
+		assert.False(t, database.Draining)
+		assert.Equal(t, 5, database.MaxInstallations)
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		allowInstallations := true
+		draining := true
+		_, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			AllowInstallations: &allowInstallations,
+			Draining:           &draining,
+		})
+		require.EqualError(t, err, "failed with status code 400")
+
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			Draining: &draining,
+		})
+		require.NoError(t, err)
+		assert.True(t, database.Draining)
+		assert.False(t, database.AllowInstallations)
+	})
+
+	t.Run("deleted database", func(t *testing.T) {
+		err = sqlStore.DeleteMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+
+		database, err := client.UpdateMultitenantDatabase(database1.ID, &model.UpdateMultitenantDatabaseRequest{
+			MaxInstallations: iToP(10),
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, database)
+	})
+}
+
+func TestDatabaseAPISecurityLock(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	database1 := &model.MultitenantDatabase{
+		ID:                 "rds-cluster-multitenant-1234-a",
+		VpcID:              "vpc-1",
+		DatabaseType:       model.DatabaseEngineTypeMySQL,
+		AllowInstallations: true,
+	}
+	err := sqlStore.CreateMultitenantDatabase(database1)
+	require.NoError(t, err)
+
+	t.Run("unknown database", func(t *testing.T) {
+		err := client.LockAPIForMultitenantDatabase("unknown")
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		err := client.LockAPIForMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+
+		database, err := client.GetMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+		assert.True(t, database.APISecurityLock)
+
+		err = client.UnlockMultitenantDatabase(database1.ID)
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+		err := client.UnlockAPIForMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+
+		database, err := client.GetMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+		assert.False(t, database.APISecurityLock)
+
+		err = client.UnlockMultitenantDatabase(database1.ID)
+		require.NoError(t, err)
+	})
+}
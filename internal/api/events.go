@@ -0,0 +1,237 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+const (
+	// eventStreamPollInterval is how often the event log is checked for new
+	// events while streaming.
+	eventStreamPollInterval = 1 * time.Second
+	// eventStreamKeepAliveInterval is how often a comment is sent to keep
+	// idle streams from being closed by proxies.
+	eventStreamKeepAliveInterval = 15 * time.Second
+	// eventStreamMaxDuration is how long a stream is kept open before the
+	// server closes it. It must be lower than the server write timeout;
+	// clients are expected to reconnect using the Last-Event-ID header.
+	eventStreamMaxDuration = 150 * time.Second
+	// eventStreamBatchSize is the maximum number of events read from the
+	// event log at once.
+	eventStreamBatchSize = 100
+	// eventStreamGapTimeout is how long the stream waits for a missing
+	// sequence number to be committed before considering that the transaction
+	// recording it was rolled back.
+	eventStreamGapTimeout = 5 * time.Second
+)
+
+// initEvents registers event endpoints on the given router.
+func initEvents(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	eventsRouter := apiRouter.PathPrefix("/events").Subrouter()
+	eventsRouter.Handle("/stream", addContext(handleStreamEvents)).Methods("GET")
+}
+
+// handleStreamEvents responds to GET /api/events/stream, streaming events as
+// server-sent events as they are recorded in the event log.
+func handleStreamEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.Logger.Error("response writer does not support streaming")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	filter := &model.EventFilter{
+		Type:    parseString(r.URL, "type", ""),
+		OwnerID: parseString(r.URL, "owner", ""),
+		GroupID: parseString(r.URL, "group", ""),
+		PerPage: eventStreamBatchSize,
+	}
+	switch filter.Type {
+	case "", model.TypeCluster, model.TypeInstallation, model.TypeClusterInstallation, model.TypeInstallationBackup:
+	default:
+		c.Logger.Errorf("invalid event type %s", filter.Type)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID != "" {
+		afterSequence, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			c.Logger.WithError(err).Errorf("invalid last event ID %s", lastEventID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		filter.AfterSequence = afterSequence
+	} else {
+		latestSequence, err := c.Store.GetLatestEventSequence()
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query latest event")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		filter.AfterSequence = latestSequence
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Send the position the stream starts at, so that clients that connected
+	// without a Last-Event-ID can resume without missing events.
+	_, err := fmt.Fprintf(w, "id: %d\n\n", filter.AfterSequence)
+	if err != nil {
+		return
+	}
+	flusher.Flush()
+
+	poll := time.NewTicker(eventStreamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	closeStream := time.After(eventStreamMaxDuration)
+
+	// Sequence numbers are allocated when events are inserted, but the
+	// transactions recording them may commit out of order. Events are only
+	// streamed up to the first missing sequence number so that clients
+	// resuming from the ID of the last event they received don't skip events
+	// committed later.
+	gap := &eventStreamGap{}
+	for {
+		sequences, err := c.Store.GetEventSequences(filter.AfterSequence, eventStreamBatchSize)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query event sequences")
+			return
+		}
+
+		horizon := gap.horizon(filter.AfterSequence, sequences, time.Now())
+		if horizon > filter.AfterSequence {
+			filter.MaxSequence = horizon
+			events, err := c.Store.GetEvents(filter)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query events")
+				return
+			}
+
+			for _, event := range events {
+				err = writeServerSentEvent(w, event)
+				if err != nil {
+					c.Logger.WithError(err).Debug("failed to write event; closing stream")
+					return
+				}
+			}
+
+			// Move the position of the client past the events that were
+			// filtered out.
+			if len(events) == 0 || events[len(events)-1].Sequence != horizon {
+				_, err = fmt.Fprintf(w, "id: %d\n\n", horizon)
+				if err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+			filter.AfterSequence = horizon
+		}
+		if len(sequences) == eventStreamBatchSize && horizon == sequences[len(sequences)-1] {
+			// Catch up on the remaining events without waiting.
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-closeStream:
+			return
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
+
+// eventStreamGap tracks the first missing sequence number of an event stream.
+type eventStreamGap struct {
+	sequence int64
+	seenAt   time.Time
+}
+
+// horizon returns the highest of the given ordered sequence numbers up to which
+// no sequence number is missing after the given position. Sequence numbers
+// missing for longer than eventStreamGapTimeout are skipped.
+func (g *eventStreamGap) horizon(position int64, sequences []int64, now time.Time) int64 {
+	horizon := position
+	for _, sequence := range sequences {
+		if sequence != horizon+1 {
+			if g.sequence != horizon+1 {
+				g.sequence = horizon + 1
+				g.seenAt = now
+			}
+			if now.Sub(g.seenAt) < eventStreamGapTimeout {
+				return horizon
+			}
+		}
+		horizon = sequence
+	}
+
+	return horizon
+}
+
+// writeServerSentEvent writes the given event in the server-sent events
+// format, using the sequence number of the event as its ID.
+func writeServerSentEvent(w http.ResponseWriter, event *model.Event) error {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.Sequence, data)
+
+	return err
+}
+
+// outputEvents responds with the requested page of events from the event log
+// matching the given filter.
+func outputEvents(c *Context, w http.ResponseWriter, r *http.Request, filter *model.EventFilter) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	filter.Page = page
+	filter.PerPage = perPage
+
+	events, err := c.Store.GetEvents(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query events")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []*model.Event{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, events)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStreamGapHorizon(t *testing.T) {
+	now := time.Now()
+
+	t.Run("no events", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 5, gap.horizon(5, nil, now))
+	})
+
+	t.Run("contiguous sequences", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 8, gap.horizon(5, []int64{6, 7, 8}, now))
+	})
+
+	t.Run("stops at a missing sequence", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 6, gap.horizon(5, []int64{6, 8, 9}, now))
+		require.EqualValues(t, 6, gap.horizon(6, []int64{8, 9}, now.Add(eventStreamGapTimeout/2)))
+	})
+
+	t.Run("missing sequence committed later", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 6, gap.horizon(5, []int64{6, 8, 9}, now))
+		require.EqualValues(t, 9, gap.horizon(6, []int64{7, 8, 9}, now.Add(time.Second)))
+	})
+
+	t.Run("skips a sequence missing for longer than the timeout", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 6, gap.horizon(5, []int64{6, 8, 9}, now))
+		require.EqualValues(t, 9, gap.horizon(6, []int64{8, 9}, now.Add(eventStreamGapTimeout)))
+	})
+
+	t.Run("timeout restarts for a new gap", func(t *testing.T) {
+		gap := &eventStreamGap{}
+		require.EqualValues(t, 6, gap.horizon(5, []int64{6, 8, 10}, now))
+		require.EqualValues(t, 8, gap.horizon(6, []int64{8, 10}, now.Add(eventStreamGapTimeout)))
+		require.EqualValues(t, 8, gap.horizon(8, []int64{10}, now.Add(eventStreamGapTimeout+time.Second)))
+	})
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStopStream = errors.New("stop stream")
+
+func TestStreamEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	clusterEvent := &model.Event{
+		Payload: &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeCluster,
+			NewState: model.ClusterStateStable,
+			OldState: model.ClusterStateCreationRequested,
+		},
+	}
+	err := sqlStore.CreateEvent(clusterEvent)
+	require.NoError(t, err)
+
+	installationEvent := &model.Event{
+		Payload: &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeInstallation,
+			NewState: model.InstallationStateStable,
+			OldState: model.InstallationStateCreationInProgress,
+			OwnerID:  "owner",
+		},
+	}
+	err = sqlStore.CreateEvent(installationEvent)
+	require.NoError(t, err)
+
+	// collect streams until the expected number of events is received.
+	collect := func(request *model.StreamEventsRequest, count int) ([]*model.Event, error) {
+		var events []*model.Event
+		err := client.StreamEvents(request, func(event *model.Event) error {
+			events = append(events, event)
+			if len(events) == count {
+				return errStopStream
+			}
+			return nil
+		})
+		if err == errStopStream {
+			err = nil
+		}
+
+		return events, err
+	}
+
+	t.Run("invalid type", func(t *testing.T) {
+		err := client.StreamEvents(&model.StreamEventsRequest{Type: "invalid"}, nil)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("invalid last event ID", func(t *testing.T) {
+		err := client.StreamEvents(&model.StreamEventsRequest{LastEventID: "invalid"}, nil)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("resume from the start", func(t *testing.T) {
+		request := &model.StreamEventsRequest{LastEventID: "0"}
+		events, err := collect(request, 2)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, clusterEvent.Sequence, events[0].Sequence)
+		require.Equal(t, clusterEvent.Payload, events[0].Payload)
+		require.Equal(t, installationEvent.Sequence, events[1].Sequence)
+		require.Equal(t, installationEvent.Payload, events[1].Payload)
+	})
+
+	t.Run("resume after an event", func(t *testing.T) {
+		request := &model.StreamEventsRequest{LastEventID: "0"}
+		events, err := collect(request, 1)
+		require.NoError(t, err)
+		require.Equal(t, clusterEvent.Sequence, events[0].Sequence)
+
+		events, err = collect(request, 1)
+		require.NoError(t, err)
+		require.Equal(t, installationEvent.Sequence, events[0].Sequence)
+	})
+
+	t.Run("filter by type", func(t *testing.T) {
+		request := &model.StreamEventsRequest{Type: model.TypeInstallation, LastEventID: "0"}
+		events, err := collect(request, 1)
+		require.NoError(t, err)
+		require.Equal(t, installationEvent.Sequence, events[0].Sequence)
+	})
+
+	t.Run("filter by owner", func(t *testing.T) {
+		request := &model.StreamEventsRequest{OwnerID: "owner", LastEventID: "0"}
+		events, err := collect(request, 1)
+		require.NoError(t, err)
+		require.Equal(t, installationEvent.Sequence, events[0].Sequence)
+	})
+
+	t.Run("live events", func(t *testing.T) {
+		liveEvent := &model.Event{
+			Payload: &model.WebhookPayload{
+				ID:       model.NewID(),
+				Type:     model.TypeInstallation,
+				NewState: model.InstallationStateDeletionRequested,
+				OldState: model.InstallationStateStable,
+			},
+		}
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			err := sqlStore.CreateEvent(liveEvent)
+			assert.NoError(t, err)
+		}()
+
+		request := &model.StreamEventsRequest{}
+		events, err := collect(request, 1)
+		require.NoError(t, err)
+		require.Equal(t, liveEvent.Payload, events[0].Payload)
+		require.Equal(t, liveEvent.Sequence, events[0].Sequence)
+	})
+}
+
+func TestGetEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		InstanceID: "instance",
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	group, err := client.CreateGroup(&model.CreateGroupRequest{
+		Name:    "name",
+		Version: "version",
+	})
+	require.NoError(t, err)
+
+	installation, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID:  "owner",
+		GroupID:  group.ID,
+		Version:  "version",
+		DNS:      "dns.example.com",
+		Affinity: model.InstallationAffinityIsolated,
+	})
+	require.NoError(t, err)
+
+	cluster := &model.Cluster{}
+	err = sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	clusterInstallation := &model.ClusterInstallation{
+		ClusterID:      cluster.ID,
+		InstallationID: installation.ID,
+		State:          model.ClusterInstallationStateCreationRequested,
+	}
+	err = sqlStore.CreateClusterInstallation(clusterInstallation)
+	require.NoError(t, err)
+
+	for _, payload := range []*model.WebhookPayload{
+		{Type: model.TypeCluster, ID: cluster.ID, OldState: model.ClusterStateCreationRequested, NewState: model.ClusterStateStable},
+		{Type: model.TypeClusterInstallation, ID: clusterInstallation.ID, OldState: model.ClusterInstallationStateCreationRequested, NewState: model.ClusterInstallationStateReconciling},
+		{Type: model.TypeClusterInstallation, ID: clusterInstallation.ID, OldState: model.ClusterInstallationStateReconciling, NewState: model.ClusterInstallationStateStable},
+	} {
+		err = sqlStore.CreateEvent(&model.Event{Payload: payload, InstanceID: "supervisor"})
+		require.NoError(t, err)
+	}
+
+	t.Run("installation", func(t *testing.T) {
+		events, err := client.GetInstallationEvents(installation.ID, &model.GetEventsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, installation.ID, events[0].Payload.ID)
+		require.Equal(t, model.InstallationStateCreationRequested, events[0].Payload.NewState)
+		require.Equal(t, "instance", events[0].InstanceID)
+		require.NotEmpty(t, events[0].RequestID)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		events, err := client.GetClusterEvents(cluster.ID, &model.GetEventsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.ClusterStateStable, events[0].Payload.NewState)
+		require.Equal(t, "supervisor", events[0].InstanceID)
+		require.Empty(t, events[0].RequestID)
+	})
+
+	t.Run("cluster installation", func(t *testing.T) {
+		events, err := client.GetClusterInstallationEvents(clusterInstallation.ID, &model.GetEventsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, model.ClusterInstallationStateReconciling, events[0].Payload.NewState)
+		require.Equal(t, model.ClusterInstallationStateStable, events[1].Payload.NewState)
+	})
+
+	t.Run("cluster installation, paged", func(t *testing.T) {
+		events, err := client.GetClusterInstallationEvents(clusterInstallation.ID, &model.GetEventsRequest{Page: 1, PerPage: 1})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.ClusterInstallationStateStable, events[0].Payload.NewState)
+
+		events, err = client.GetClusterInstallationEvents(clusterInstallation.ID, &model.GetEventsRequest{Page: 2, PerPage: 1})
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		events, err := client.GetGroupEvents(group.ID, &model.GetEventsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, installation.ID, events[0].Payload.ID)
+	})
+
+	t.Run("unknown resources", func(t *testing.T) {
+		_, err := client.GetInstallationEvents(model.NewID(), &model.GetEventsRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = client.GetClusterEvents(model.NewID(), &model.GetEventsRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = client.GetClusterInstallationEvents(model.NewID(), &model.GetEventsRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = client.GetGroupEvents(model.NewID(), &model.GetEventsRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+	})
+}
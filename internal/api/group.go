@@ -25,6 +25,7 @@ func initGroup(apiRouter *mux.Router, context *Context) {
 	groupRouter.Handle("", addContext(handleGetGroup)).Methods("GET")
 	groupRouter.Handle("", addContext(handleUpdateGroup)).Methods("PUT")
 	groupRouter.Handle("", addContext(handleDeleteGroup)).Methods("DELETE")
+	groupRouter.Handle("/events", addContext(handleGetGroupEvents)).Methods("GET")
 }
 
 // handleGetGroup responds to GET /api/group/{group}, returning the group in question.
@@ -49,6 +50,28 @@ func handleGetGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, group)
 }
 
+// handleGetGroupEvents responds to GET /api/group/{group}/events,
+// returning the state change history of the installations belonging to
+// the group in question.
+func handleGetGroupEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupID := vars["group"]
+	c.Logger = c.Logger.WithField("group", groupID)
+
+	group, err := c.Store.GetGroup(groupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query group")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	outputEvents(c, w, r, &model.EventFilter{GroupID: group.ID})
+}
+
 // handleGetGroups responds to GET /api/groups, returning the specified page of groups.
 func handleGetGroups(c *Context, w http.ResponseWriter, r *http.Request) {
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
@@ -89,13 +112,16 @@ func handleCreateGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	group := model.Group{
-		Name:            createGroupRequest.Name,
-		Description:     createGroupRequest.Description,
-		Version:         createGroupRequest.Version,
-		Image:           createGroupRequest.Image,
-		MaxRolling:      createGroupRequest.MaxRolling,
-		APISecurityLock: createGroupRequest.APISecurityLock,
-		MattermostEnv:   createGroupRequest.MattermostEnv,
+		Name:                            createGroupRequest.Name,
+		Description:                     createGroupRequest.Description,
+		Version:                         createGroupRequest.Version,
+		Image:                           createGroupRequest.Image,
+		MaxRolling:                      createGroupRequest.MaxRolling,
+		APISecurityLock:                 createGroupRequest.APISecurityLock,
+		MattermostEnv:                   createGroupRequest.MattermostEnv,
+		HibernationSchedule:             createGroupRequest.HibernationSchedule,
+		IdleHibernationOptOut:           createGroupRequest.IdleHibernationOptOut,
+		IdleHibernationGracePeriodHours: createGroupRequest.IdleHibernationGracePeriodHours,
 	}
 
 	err = c.Store.CreateGroup(&group)
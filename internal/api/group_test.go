@@ -255,6 +255,18 @@ func TestCreateGroup(t *testing.T) {
 		require.NotEqual(t, 0, group.CreateAt)
 		require.EqualValues(t, 0, group.DeleteAt)
 	})
+
+	t.Run("idle hibernation settings", func(t *testing.T) {
+		gracePeriodHours := int64(48)
+		group, err := client.CreateGroup(&model.CreateGroupRequest{
+			Name:                            "idle",
+			IdleHibernationOptOut:           true,
+			IdleHibernationGracePeriodHours: &gracePeriodHours,
+		})
+		require.NoError(t, err)
+		require.True(t, group.IdleHibernationOptOut)
+		require.Equal(t, &gracePeriodHours, group.IdleHibernationGracePeriodHours)
+	})
 }
 
 func TestUpdateGroup(t *testing.T) {
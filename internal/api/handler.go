@@ -26,6 +26,11 @@ func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"request": context.RequestID,
 	})
 
+	context.Principal = principalFromRequest(r)
+	if context.Principal != nil {
+		context.Logger = context.Logger.WithField("principal", context.Principal.Subject)
+	}
+
 	h.handler(context, w, r)
 }
 
@@ -23,6 +23,7 @@ func initInstallation(apiRouter *mux.Router, context *Context) {
 	installationsRouter.Handle("", addContext(handleGetInstallations)).Methods("GET")
 	installationsRouter.Handle("/count", addContext(handleGetNumberOfInstallations)).Methods("GET")
 	installationsRouter.Handle("", addContext(handleCreateInstallation)).Methods("POST")
+	installationsRouter.Handle("/schedule", addContext(handleScheduleInstallation)).Methods("POST")
 
 	installationRouter := apiRouter.PathPrefix("/installation/{installation:[A-Za-z0-9]{26}}").Subrouter()
 	installationRouter.Handle("", addContext(handleGetInstallation)).Methods("GET")
@@ -32,7 +33,11 @@ func initInstallation(apiRouter *mux.Router, context *Context) {
 	installationRouter.Handle("/group", addContext(handleLeaveGroup)).Methods("DELETE")
 	installationRouter.Handle("/hibernate", addContext(handleHibernateInstallation)).Methods("POST")
 	installationRouter.Handle("/wakeup", addContext(handleWakeupInstallation)).Methods("POST")
+	installationRouter.Handle("/migrate", addContext(handleMigrateInstallation)).Methods("POST")
+	installationRouter.Handle("/database/migrate", addContext(handleMigrateInstallationDatabase)).Methods("POST")
+	installationRouter.Handle("/backup", addContext(handleCreateInstallationBackup)).Methods("POST")
 	installationRouter.Handle("", addContext(handleDeleteInstallation)).Methods("DELETE")
+	installationRouter.Handle("/events", addContext(handleGetInstallationEvents)).Methods("GET")
 }
 
 // handleGetInstallation responds to GET /api/installation/{installation}, returning the installation in question.
@@ -64,6 +69,27 @@ func handleGetInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, installation)
 }
 
+// handleGetInstallationEvents responds to GET /api/installation/{installation}/events,
+// returning the state change history of the installation in question.
+func handleGetInstallationEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, err := c.Store.GetInstallation(installationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	outputEvents(c, w, r, &model.EventFilter{ResourceID: installation.ID})
+}
+
 // handleGetInstallations responds to GET /api/installations, returning the specified page of installations.
 func handleGetInstallations(c *Context, w http.ResponseWriter, r *http.Request) {
 	var err error
@@ -139,64 +165,185 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var group *model.Group
-	var status int
-	groupUnlockOnce := func() {}
+	installation, status := createInstallation(c, createInstallationRequest)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// createInstallation stores a new installation from the given request and
+// sends the corresponding webhook. On failure, the returned status is the
+// HTTP status to respond with.
+func createInstallation(c *Context, createInstallationRequest *model.CreateInstallationRequest) (*model.Installation, int) {
+	if !c.canAccessOwner(createInstallationRequest.OwnerID) {
+		c.Logger.Warnf("not allowed to create installations for owner %s", createInstallationRequest.OwnerID)
+		return nil, http.StatusForbidden
+	}
+
+	var err error
+	var externalDatabaseSecretName string
+	var encryptedExternalDatabase []byte
+	if createInstallationRequest.ExternalDatabaseConfig != nil {
+		externalDatabaseSecretName = createInstallationRequest.ExternalDatabaseConfig.SecretName
+		if len(externalDatabaseSecretName) != 0 {
+			if len(c.ExternalDatabaseSecretPrefix) == 0 {
+				c.Logger.Error("unable to reference external database secrets without a secret prefix configured")
+				return nil, http.StatusNotImplemented
+			}
+
+			err = model.CheckExternalDatabaseSecretName(externalDatabaseSecretName, c.ExternalDatabaseSecretPrefix)
+			if err != nil {
+				c.Logger.WithError(err).Error("invalid external database secret name")
+				return nil, http.StatusBadRequest
+			}
+		}
+		if len(createInstallationRequest.ExternalDatabaseConfig.DataSource) != 0 {
+			if len(c.EncryptionKey) == 0 {
+				c.Logger.Error("unable to store external database data source without an encryption key configured")
+				return nil, http.StatusNotImplemented
+			}
+
+			encryptedExternalDatabase, err = model.EncryptSecret(c.EncryptionKey, []byte(createInstallationRequest.ExternalDatabaseConfig.DataSource), nil)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to encrypt external database data source")
+				return nil, http.StatusInternalServerError
+			}
+		}
+	}
+
+	// The secret access key of the external filestore is bound to the ID of
+	// the installation so that it can't be used with any other installation.
+	installationID := model.NewID()
+	var externalFilestoreConfig *model.ExternalFilestoreConfig
+	var encryptedExternalFilestoreSecret []byte
+	if createInstallationRequest.ExternalFilestoreConfig != nil {
+		if len(c.EncryptionKey) == 0 {
+			c.Logger.Error("unable to store external filestore secret access key without an encryption key configured")
+			return nil, http.StatusNotImplemented
+		}
+
+		encryptedExternalFilestoreSecret, err = model.EncryptSecret(c.EncryptionKey, []byte(createInstallationRequest.ExternalFilestoreConfig.SecretAccessKey), []byte(installationID))
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to encrypt external filestore secret access key")
+			return nil, http.StatusInternalServerError
+		}
+
+		// The secret access key is only stored encrypted.
+		config := *createInstallationRequest.ExternalFilestoreConfig
+		config.SecretAccessKey = ""
+		externalFilestoreConfig = &config
+	}
+
 	if len(createInstallationRequest.GroupID) != 0 {
-		group, status, groupUnlockOnce = lockGroup(c, createInstallationRequest.GroupID)
+		group, status, groupUnlockOnce := lockGroup(c, createInstallationRequest.GroupID)
 		if status != 0 {
-			w.WriteHeader(status)
-			return
+			return nil, status
 		}
 		defer groupUnlockOnce()
 		if group.IsDeleted() {
 			c.Logger.Errorf("cannot join installation to deleted group %s", createInstallationRequest.GroupID)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+			return nil, http.StatusBadRequest
 		}
 	}
 
 	installation := model.Installation{
-		OwnerID:         createInstallationRequest.OwnerID,
-		GroupID:         &createInstallationRequest.GroupID,
-		Version:         createInstallationRequest.Version,
-		Image:           createInstallationRequest.Image,
-		DNS:             createInstallationRequest.DNS,
-		Database:        createInstallationRequest.Database,
-		Filestore:       createInstallationRequest.Filestore,
-		License:         createInstallationRequest.License,
-		Size:            createInstallationRequest.Size,
-		Affinity:        createInstallationRequest.Affinity,
-		APISecurityLock: createInstallationRequest.APISecurityLock,
-		MattermostEnv:   createInstallationRequest.MattermostEnv,
-		State:           model.InstallationStateCreationRequested,
+		ID:                   installationID,
+		OwnerID:              createInstallationRequest.OwnerID,
+		GroupID:              &createInstallationRequest.GroupID,
+		Version:              createInstallationRequest.Version,
+		Image:                createInstallationRequest.Image,
+		DNS:                  createInstallationRequest.DNS,
+		Database:             createInstallationRequest.Database,
+		Filestore:            createInstallationRequest.Filestore,
+		License:              createInstallationRequest.License,
+		Size:                 createInstallationRequest.Size,
+		Affinity:             createInstallationRequest.Affinity,
+		APISecurityLock:      createInstallationRequest.APISecurityLock,
+		MattermostEnv:        createInstallationRequest.MattermostEnv,
+		State:                model.InstallationStateCreationRequested,
+		HibernationSchedule:  createInstallationRequest.HibernationSchedule,
+		PlacementConstraints: createInstallationRequest.PlacementConstraints,
+
+		ExternalDatabaseSecretName:       externalDatabaseSecretName,
+		EncryptedExternalDatabase:        encryptedExternalDatabase,
+		ExternalFilestoreConfig:          externalFilestoreConfig,
+		EncryptedExternalFilestoreSecret: encryptedExternalFilestoreSecret,
 	}
 
 	err = c.Store.CreateInstallation(&installation)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to create installation")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return nil, http.StatusInternalServerError
 	}
 
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeInstallation,
 		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
 		NewState:  model.InstallationStateCreationRequested,
 		OldState:  "n/a",
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
-	groupUnlockOnce()
-	c.Supervisor.Do()
+	return &installation, 0
+}
+
+// handleScheduleInstallation responds to POST /api/installations/schedule,
+// reporting which cluster the requested installation would be placed on
+// without creating it.
+func handleScheduleInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	createInstallationRequest, err := model.NewCreateInstallationRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation := &model.Installation{
+		OwnerID:              createInstallationRequest.OwnerID,
+		DNS:                  createInstallationRequest.DNS,
+		Database:             createInstallationRequest.Database,
+		Filestore:            createInstallationRequest.Filestore,
+		Size:                 createInstallationRequest.Size,
+		Affinity:             createInstallationRequest.Affinity,
+		PlacementConstraints: createInstallationRequest.PlacementConstraints,
+	}
+
+	clusters, err := c.Store.GetClusters(&model.ClusterFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: false,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query clusters")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	result := &model.InstallationSchedulingResult{
+		Policy:     c.Scheduler.PolicyName(),
+		Placements: c.Scheduler.Schedule(installation, clusters),
+	}
+	for _, placement := range result.Placements {
+		if placement.Schedulable {
+			result.ClusterID = placement.ClusterID
+			break
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	outputJSON(c, w, installation)
+	outputJSON(c, w, result)
 }
 
 // handleRetryCreateInstallation responds to POST /api/installation/{installation}, retrying a
@@ -228,6 +375,8 @@ func handleRetryCreateInstallation(c *Context, w http.ResponseWriter, r *http.Re
 		webhookPayload := &model.WebhookPayload{
 			Type:      model.TypeInstallation,
 			ID:        installation.ID,
+			OwnerID:   installation.OwnerID,
+			GroupID:   installation.GroupID,
 			NewState:  newState,
 			OldState:  installation.State,
 			Timestamp: time.Now().UnixNano(),
@@ -241,7 +390,7 @@ func handleRetryCreateInstallation(c *Context, w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -292,6 +441,7 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	scheduleApplied := patchInstallationRequest.ApplyHibernationSchedule(installation)
 	if patchInstallationRequest.Apply(installation) {
 		installation.State = newState
 
@@ -305,14 +455,25 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		webhookPayload := &model.WebhookPayload{
 			Type:      model.TypeInstallation,
 			ID:        installation.ID,
+			OwnerID:   installation.OwnerID,
+			GroupID:   installation.GroupID,
 			NewState:  newState,
 			OldState:  oldState,
 			Timestamp: time.Now().UnixNano(),
 		}
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
+	} else if scheduleApplied {
+		// A hibernation schedule change alone is picked up by the hibernation
+		// schedule supervisor and doesn't require updating the installation.
+		err = c.Store.UpdateInstallation(installation)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to update installation")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 	}
 
 	unlockOnce()
@@ -488,11 +649,13 @@ func handleHibernateInstallation(c *Context, w http.ResponseWriter, r *http.Requ
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeInstallation,
 		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -546,11 +709,271 @@ func handleWakeupInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeInstallation,
 		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// handleMigrateInstallation responds to POST /api/installation/{installation}/migrate,
+// beginning the process of moving the installation to another cluster.
+func handleMigrateInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	migrateInstallationRequest, err := model.NewMigrateInstallationRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installation.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	oldState := installation.State
+	newState := model.InstallationStateMigrationRequested
+
+	if !installation.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to migrate installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Data kept by operators inside the source cluster would be lost.
+	if !installation.SupportsMigration() {
+		c.Logger.Warn("unable to migrate installation with a database or filestore running in its cluster")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	targetCluster, err := c.Store.GetCluster(migrateInstallationRequest.TargetClusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query target cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if targetCluster == nil || targetCluster.DeleteAt != 0 {
+		c.Logger.Warnf("target cluster %s not found", migrateInstallationRequest.TargetClusterID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetCluster.Draining {
+		c.Logger.Warnf("target cluster %s is being drained", targetCluster.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterInstallations, err := c.Store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster installations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// A failed migration may be retried to the same target cluster.
+	retrying := installation.State == model.InstallationStateMigrationFailed &&
+		installation.MigrationTargetClusterID == targetCluster.ID
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.ClusterID == targetCluster.ID && !retrying {
+			c.Logger.Warnf("installation is already on cluster %s", targetCluster.ID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Databases hosted in the VPC of the cluster are not moved along with
+	// the installation. The VPC of clusters created before it was kept in
+	// their metadata is unknown here; the supervisor looks it up and fails the
+	// migration if needed.
+	if installation.VPCDatabase() {
+		for _, clusterInstallation := range clusterInstallations {
+			if clusterInstallation.ClusterID == targetCluster.ID {
+				continue
+			}
+			sourceCluster, err := c.Store.GetCluster(clusterInstallation.ClusterID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query source cluster")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			knownVPCs := sourceCluster != nil && sourceCluster.VPC() != "" && targetCluster.VPC() != ""
+			if sourceCluster == nil || knownVPCs && !sourceCluster.InSameVPC(targetCluster) {
+				c.Logger.Warnf("installations using an RDS database can only be migrated to a cluster in the same VPC; target cluster %s is not in the VPC of the installation database", targetCluster.ID)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	installation.State = newState
+	installation.MigrationTargetClusterID = targetCluster.ID
+
+	err = c.Store.UpdateInstallation(installation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"TargetClusterID": targetCluster.ID},
+	}
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// handleMigrateInstallationDatabase responds to POST /api/installation/{installation}/database/migrate,
+// beginning the process of moving the installation database to another
+// multitenant database.
+func handleMigrateInstallationDatabase(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	migrateDatabaseRequest, err := model.NewMigrateInstallationDatabaseRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installation.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	oldState := installation.State
+	newState := model.InstallationStateDBMigrationRequested
+
+	if !installation.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to migrate installation database while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if installation.Database != model.InstallationDatabaseMultiTenantRDSMySQL &&
+		installation.Database != model.InstallationDatabaseMultiTenantRDSPostgres {
+		c.Logger.Warnf("unable to migrate installation database of type %s", installation.Database)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	sourceDatabases, err := c.Store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		InstallationID:        installation.ID,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query source multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(sourceDatabases) != 1 {
+		c.Logger.Errorf("expected exactly one multitenant database for the installation, but found %d", len(sourceDatabases))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	sourceDatabase := sourceDatabases[0]
+
+	targetDatabase, err := c.Store.GetMultitenantDatabase(migrateDatabaseRequest.TargetDatabaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query target multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if targetDatabase == nil || targetDatabase.State == model.MultitenantDatabaseStateDeleted {
+		c.Logger.Warnf("target multitenant database %s not found", migrateDatabaseRequest.TargetDatabaseID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetDatabase.ID == sourceDatabase.ID {
+		c.Logger.Warnf("installation database is already on multitenant database %s", targetDatabase.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetDatabase.State != model.MultitenantDatabaseStateStable || !targetDatabase.AllowInstallations {
+		c.Logger.Warnf("target multitenant database %s does not allow installations", targetDatabase.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetDatabase.VpcID != sourceDatabase.VpcID || targetDatabase.DatabaseType != sourceDatabase.DatabaseType {
+		c.Logger.Warnf("target multitenant database %s is not in the same VPC or of the same type", targetDatabase.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation.State = newState
+	installation.MigrationTargetDatabaseID = targetDatabase.ID
+
+	err = c.Store.UpdateInstallation(installation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"TargetDatabaseID": targetDatabase.ID},
 	}
-	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -595,6 +1018,8 @@ func handleDeleteInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		webhookPayload := &model.WebhookPayload{
 			Type:      model.TypeInstallation,
 			ID:        installation.ID,
+			OwnerID:   installation.OwnerID,
+			GroupID:   installation.GroupID,
 			NewState:  newState,
 			OldState:  installation.State,
 			Timestamp: time.Now().UnixNano(),
@@ -608,7 +1033,7 @@ func handleDeleteInstallation(c *Context, w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
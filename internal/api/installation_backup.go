@@ -0,0 +1,332 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initInstallationBackup registers installation backup endpoints on the given router.
+func initInstallationBackup(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	backupsRouter := apiRouter.PathPrefix("/installation_backups").Subrouter()
+	backupsRouter.Handle("", addContext(handleGetInstallationBackups)).Methods("GET")
+
+	backupRouter := apiRouter.PathPrefix("/installation_backup/{backup:[A-Za-z0-9]{26}}").Subrouter()
+	backupRouter.Handle("", addContext(handleGetInstallationBackup)).Methods("GET")
+	backupRouter.Handle("/restore", addContext(handleRestoreInstallationBackup)).Methods("POST")
+}
+
+// handleCreateInstallationBackup responds to POST /api/installation/{installation}/backup,
+// requesting a backup of the database and filestore contents of the installation.
+func handleCreateInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installation.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if installation.State != model.InstallationStateStable &&
+		installation.State != model.InstallationStateHibernating {
+		c.Logger.Warnf("unable to back up installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	backups, err := c.Store.GetInstallationBackups(&model.InstallationBackupFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backups")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	for _, backup := range backups {
+		if backup.State == model.InstallationBackupStateBackupRequested ||
+			backup.State == model.InstallationBackupStateBackupInProgress {
+			c.Logger.Warnf("installation backup %s is already in progress", backup.ID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	backup := &model.InstallationBackup{
+		InstallationID: installation.ID,
+		State:          model.InstallationBackupStateBackupRequested,
+	}
+
+	err = c.Store.CreateInstallationBackup(backup)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendInstallationBackupWebhook(c, installation, backup, "n/a")
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, backup)
+}
+
+// handleGetInstallationBackup responds to GET /api/installation_backup/{backup},
+// returning the installation backup in question.
+func handleGetInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backupID := vars["backup"]
+	c.Logger = c.Logger.WithField("installation_backup", backupID)
+
+	backup, err := c.Store.GetInstallationBackup(backupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if backup == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, backup)
+}
+
+// handleGetInstallationBackups responds to GET /api/installation_backups,
+// returning the specified page of installation backups.
+func handleGetInstallationBackups(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	state := parseString(r.URL, "state", "")
+	if state != "" && !model.IsValidInstallationBackupState(state) {
+		c.Logger.Errorf("invalid installation backup state %s", state)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.InstallationBackupFilter{
+		InstallationID: parseString(r.URL, "installation", ""),
+		State:          state,
+		Page:           page,
+		PerPage:        perPage,
+	}
+
+	backups, err := c.Store.GetInstallationBackups(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backups")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if backups == nil {
+		backups = []*model.InstallationBackup{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, backups)
+}
+
+// handleRestoreInstallationBackup responds to POST
+// /api/installation_backup/{backup}/restore, restoring the installation
+// backup to the installation it was taken from, to another installation or to
+// a newly created installation. Backups of deleted installations can only be
+// restored to another installation.
+func handleRestoreInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backupID := vars["backup"]
+	c.Logger = c.Logger.WithField("installation_backup", backupID)
+
+	restoreRequest, err := model.NewRestoreInstallationBackupRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	backup, status, unlockBackupOnce := lockInstallationBackup(c, backupID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockBackupOnce()
+
+	if !backup.CanRestore() {
+		c.Logger.Warnf("unable to restore installation backup while in state %s", backup.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	sourceInstallation, err := c.Store.GetInstallation(backup.InstallationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query backed up installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if sourceInstallation == nil {
+		c.Logger.Error("backed up installation not found")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if restoreRequest.CreateInstallation != nil {
+		if restoreRequest.CreateInstallation.Database != sourceInstallation.Database {
+			c.Logger.Warnf("unable to restore a backup of a %s database to a %s database", sourceInstallation.Database, restoreRequest.CreateInstallation.Database)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if sourceInstallation.InternalFilestore() {
+			c.Logger.Warn("backups of installations with an in-cluster filestore can only be restored to the same installation")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// The new installation is claimed for the restore by the supervisor
+		// once it is stable.
+		targetInstallation, status := createInstallation(c, restoreRequest.CreateInstallation)
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		c.Logger = c.Logger.WithField("installation", targetInstallation.ID)
+
+		requestRestore(c, w, sourceInstallation, targetInstallation, backup, unlockBackupOnce)
+		return
+	}
+
+	targetInstallationID := restoreRequest.InstallationID
+	if targetInstallationID == "" {
+		targetInstallationID = backup.InstallationID
+	}
+	c.Logger = c.Logger.WithField("installation", targetInstallationID)
+
+	targetInstallation, status, unlockInstallationOnce := lockInstallation(c, targetInstallationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockInstallationOnce()
+
+	if !c.canAccessOwner(targetInstallation.OwnerID) {
+		c.Logger.Warnf("principal is not allowed to restore to installations of owner %s", targetInstallation.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if targetInstallation.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if targetInstallation.State != model.InstallationStateStable {
+		c.Logger.Warnf("unable to restore to installation while in state %s", targetInstallation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetInstallation.Database != sourceInstallation.Database {
+		c.Logger.Warnf("unable to restore a backup of a %s database to a %s database", sourceInstallation.Database, targetInstallation.Database)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetInstallation.ID != sourceInstallation.ID && sourceInstallation.InternalFilestore() {
+		c.Logger.Warn("backups of installations with an in-cluster filestore can only be restored to the same installation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	oldInstallationState := targetInstallation.State
+	targetInstallation.State = model.InstallationStateRestorationInProgress
+	err = c.Store.UpdateInstallation(targetInstallation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        targetInstallation.ID,
+		OwnerID:   targetInstallation.OwnerID,
+		GroupID:   targetInstallation.GroupID,
+		NewState:  targetInstallation.State,
+		OldState:  oldInstallationState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockInstallationOnce()
+	requestRestore(c, w, sourceInstallation, targetInstallation, backup, unlockBackupOnce)
+}
+
+// requestRestore moves the locked backup to the restore-requested state for
+// the given target installation and responds with the updated backup.
+func requestRestore(c *Context, w http.ResponseWriter, sourceInstallation, targetInstallation *model.Installation, backup *model.InstallationBackup, unlockBackupOnce func()) {
+	oldBackupState := backup.State
+	backup.State = model.InstallationBackupStateRestoreRequested
+	backup.RestoreInstallationID = targetInstallation.ID
+	err := c.Store.UpdateInstallationBackup(backup)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendInstallationBackupWebhook(c, sourceInstallation, backup, oldBackupState)
+
+	unlockBackupOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, backup)
+}
+
+func sendInstallationBackupWebhook(c *Context, installation *model.Installation, backup *model.InstallationBackup, oldState string) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationBackup,
+		ID:        backup.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  backup.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Installation": installation.ID},
+	}
+	err := webhook.SendToAllWebhooks(c.Store, c.newEvent(webhookPayload), c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationBackups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	installation1 := &model.Installation{
+		OwnerID:   "owner",
+		DNS:       "backup1.example.com",
+		Database:  model.InstallationDatabaseMultiTenantRDSMySQL,
+		Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+		State:     model.InstallationStateStable,
+	}
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installation2 := &model.Installation{
+		OwnerID:   "owner",
+		DNS:       "backup2.example.com",
+		Database:  model.InstallationDatabaseMultiTenantRDSMySQL,
+		Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+		State:     model.InstallationStateStable,
+	}
+	err = sqlStore.CreateInstallation(installation2)
+	require.NoError(t, err)
+
+	installation3 := &model.Installation{
+		OwnerID:   "owner",
+		DNS:       "backup3.example.com",
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreMinioOperator,
+		State:     model.InstallationStateStable,
+	}
+	err = sqlStore.CreateInstallation(installation3)
+	require.NoError(t, err)
+
+	t.Run("get unknown backup", func(t *testing.T) {
+		backup, err := client.GetInstallationBackup(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, backup)
+	})
+
+	t.Run("back up unknown installation", func(t *testing.T) {
+		_, err := client.CreateInstallationBackup(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	var backup *model.InstallationBackup
+
+	t.Run("back up installation", func(t *testing.T) {
+		backup, err = client.CreateInstallationBackup(installation1.ID)
+		require.NoError(t, err)
+		require.Equal(t, installation1.ID, backup.InstallationID)
+		require.Equal(t, model.InstallationBackupStateBackupRequested, backup.State)
+
+		_, err = client.CreateInstallationBackup(installation1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("back up installation with API security lock", func(t *testing.T) {
+		err = sqlStore.LockInstallationAPI(installation2.ID)
+		require.NoError(t, err)
+		defer sqlStore.UnlockInstallationAPI(installation2.ID)
+
+		_, err = client.CreateInstallationBackup(installation2.ID)
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("get and list backups", func(t *testing.T) {
+		fetchedBackup, err := client.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup, fetchedBackup)
+
+		backups, err := client.GetInstallationBackups(&model.GetInstallationBackupsRequest{
+			InstallationID: installation1.ID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup}, backups)
+
+		backups, err = client.GetInstallationBackups(&model.GetInstallationBackupsRequest{
+			InstallationID: installation2.ID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Empty(t, backups)
+	})
+
+	t.Run("restore unfinished backup", func(t *testing.T) {
+		_, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	backup.State = model.InstallationBackupStateBackupSucceeded
+	err = sqlStore.UpdateInstallationBackup(backup)
+	require.NoError(t, err)
+
+	t.Run("restore to installation with another database type", func(t *testing.T) {
+		_, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{InstallationID: installation3.ID})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("restore to another installation", func(t *testing.T) {
+		restoredBackup, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{InstallationID: installation2.ID})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationBackupStateRestoreRequested, restoredBackup.State)
+		require.Equal(t, installation2.ID, restoredBackup.RestoreInstallationID)
+
+		installation, err := client.GetInstallation(installation2.ID, &model.GetInstallationRequest{})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateRestorationInProgress, installation.State)
+
+		_, err = client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	backup.State = model.InstallationBackupStateBackupSucceeded
+	err = sqlStore.UpdateInstallationBackup(backup)
+	require.NoError(t, err)
+
+	t.Run("restore to an installation and a new installation", func(t *testing.T) {
+		_, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{
+			InstallationID: installation2.ID,
+			CreateInstallation: &model.CreateInstallationRequest{
+				OwnerID:   "owner",
+				DNS:       "backup4.example.com",
+				Database:  model.InstallationDatabaseMultiTenantRDSMySQL,
+				Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("restore to a new installation with another database type", func(t *testing.T) {
+		_, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{
+			CreateInstallation: &model.CreateInstallationRequest{
+				OwnerID: "owner",
+				DNS:     "backup4.example.com",
+			},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("restore to a new installation", func(t *testing.T) {
+		restoredBackup, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{
+			CreateInstallation: &model.CreateInstallationRequest{
+				OwnerID:   "owner",
+				DNS:       "backup4.example.com",
+				Database:  model.InstallationDatabaseMultiTenantRDSMySQL,
+				Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationBackupStateRestoreRequested, restoredBackup.State)
+		require.NotEmpty(t, restoredBackup.RestoreInstallationID)
+		require.NotContains(t, []string{installation1.ID, installation2.ID, installation3.ID}, restoredBackup.RestoreInstallationID)
+
+		installation, err := client.GetInstallation(restoredBackup.RestoreInstallationID, &model.GetInstallationRequest{})
+		require.NoError(t, err)
+		require.Equal(t, "backup4.example.com", installation.DNS)
+		require.Equal(t, model.InstallationStateCreationRequested, installation.State)
+	})
+
+	t.Run("restore backup of deleted installation", func(t *testing.T) {
+		backup.State = model.InstallationBackupStateBackupSucceeded
+		err = sqlStore.UpdateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		installation1.State = model.InstallationStateDeleted
+		err = sqlStore.UpdateInstallationState(installation1)
+		require.NoError(t, err)
+		err = sqlStore.DeleteInstallation(installation1.ID)
+		require.NoError(t, err)
+
+		installation5 := &model.Installation{
+			OwnerID:   "owner",
+			DNS:       "backup5.example.com",
+			Database:  model.InstallationDatabaseMultiTenantRDSMySQL,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			State:     model.InstallationStateStable,
+		}
+		err = sqlStore.CreateInstallation(installation5)
+		require.NoError(t, err)
+
+		_, err = client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+
+		restoredBackup, err := client.RestoreInstallationBackup(backup.ID, &model.RestoreInstallationBackupRequest{InstallationID: installation5.ID})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationBackupStateRestoreRequested, restoredBackup.State)
+		require.Equal(t, installation5.ID, restoredBackup.RestoreInstallationID)
+	})
+}
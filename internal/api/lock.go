@@ -115,3 +115,108 @@ func lockInstallation(c *Context, installationID string) (*model.Installation, i
 		})
 	}
 }
+
+// lockWebhookDelivery synchronizes access to the given webhook delivery across
+// potentially multiple provisioning servers.
+func lockWebhookDelivery(c *Context, deliveryID string) (*model.WebhookDelivery, int, func()) {
+	delivery, err := c.Store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook delivery")
+		return nil, http.StatusInternalServerError, nil
+	}
+	if delivery == nil {
+		return nil, http.StatusNotFound, nil
+	}
+
+	locked, err := c.Store.LockWebhookDelivery(deliveryID, c.RequestID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to lock webhook delivery")
+		return nil, http.StatusInternalServerError, nil
+	} else if !locked {
+		c.Logger.Error("failed to acquire lock for webhook delivery")
+		return nil, http.StatusConflict, nil
+	}
+
+	unlockOnce := sync.Once{}
+
+	return delivery, 0, func() {
+		unlockOnce.Do(func() {
+			unlocked, err := c.Store.UnlockWebhookDelivery(delivery.ID, c.RequestID, false)
+			if err != nil {
+				c.Logger.WithError(err).Errorf("failed to unlock webhook delivery")
+			} else if unlocked != true {
+				c.Logger.Warn("failed to release lock for webhook delivery")
+			}
+		})
+	}
+}
+
+// lockInstallationBackup synchronizes access to the given installation backup
+// across potentially multiple provisioning servers.
+func lockInstallationBackup(c *Context, backupID string) (*model.InstallationBackup, int, func()) {
+	backup, err := c.Store.GetInstallationBackup(backupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backup")
+		return nil, http.StatusInternalServerError, nil
+	}
+	if backup == nil {
+		return nil, http.StatusNotFound, nil
+	}
+
+	locked, err := c.Store.LockInstallationBackup(backupID, c.RequestID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to lock installation backup")
+		return nil, http.StatusInternalServerError, nil
+	} else if !locked {
+		c.Logger.Error("failed to acquire lock for installation backup")
+		return nil, http.StatusConflict, nil
+	}
+
+	unlockOnce := sync.Once{}
+
+	return backup, 0, func() {
+		unlockOnce.Do(func() {
+			unlocked, err := c.Store.UnlockInstallationBackup(backup.ID, c.RequestID, false)
+			if err != nil {
+				c.Logger.WithError(err).Errorf("failed to unlock installation backup")
+			} else if unlocked != true {
+				c.Logger.Warn("failed to release lock for installation backup")
+			}
+		})
+	}
+}
+
+// lockMultitenantDatabase synchronizes access to the given multitenant
+// database across potentially multiple provisioning servers.
+func lockMultitenantDatabase(c *Context, databaseID string) (*model.MultitenantDatabase, int, func()) {
+	database, err := c.Store.GetMultitenantDatabase(databaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant database")
+		return nil, http.StatusInternalServerError, nil
+	}
+	if database == nil {
+		return nil, http.StatusNotFound, nil
+	}
+
+	locked, err := c.Store.LockMultitenantDatabase(databaseID, c.RequestID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to lock multitenant database")
+		return nil, http.StatusInternalServerError, nil
+	} else if !locked {
+		c.Logger.Error("failed to acquire lock for multitenant database")
+		return nil, http.StatusConflict, nil
+	}
+
+	unlockOnce := sync.Once{}
+
+	return database, 0, func() {
+		unlockOnce.Do(func() {
+			unlocked, err := c.Store.UnlockMultitenantDatabase(database.ID, c.RequestID, false)
+			if err != nil {
+				c.Logger.WithError(err).Errorf("failed to unlock multitenant database")
+			} else if unlocked != true {
+				c.Logger.Warn("failed to release lock for multitenant database")
+			}
+		})
+	}
+}
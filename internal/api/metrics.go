@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+)
+
+// statusRecorder records the status code written to the wrapped
+// http.ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush implements http.Flusher, as required for streaming responses.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// instrumentRequests is a middleware recording the latency of each request
+// under the path template of the matched route.
+func instrumentRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := currentRouteTemplate(r)
+		if route == "" {
+			route = "unknown"
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		metrics.APIRequestDuration.
+			WithLabelValues(route, r.Method, strconv.Itoa(recorder.statusCode)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// currentRouteTemplate returns the path template of the route matched by the
+// given request, or an empty string if no route matched.
+func currentRouteTemplate(r *http.Request) string {
+	currentRoute := mux.CurrentRoute(r)
+	if currentRoute == nil {
+		return ""
+	}
+
+	template, err := currentRoute.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return template
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	// requestCount returns the number of requests recorded for the given
+	// route and status code.
+	requestCount := func(route, statusCode string) uint64 {
+		families, err := prometheus.DefaultGatherer.Gather()
+		require.NoError(t, err)
+
+		for _, family := range families {
+			if family.GetName() != "cloud_api_request_duration_seconds" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["route"] == route && labels["method"] == "GET" && labels["status_code"] == statusCode {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+
+		return 0
+	}
+
+	before := requestCount("/api/cluster/{cluster:[A-Za-z0-9]{26}}", "404")
+
+	_, err := client.GetCluster(model.NewID())
+	require.NoError(t, err)
+	_, err = client.GetCluster(model.NewID())
+	require.NoError(t, err)
+
+	require.Equal(t, before+2, requestCount("/api/cluster/{cluster:[A-Za-z0-9]{26}}", "404"))
+}
@@ -25,6 +25,8 @@ func TestNewCreateClusterRequestFromReader(t *testing.T) {
 			NodeMaxCount:           2,
 			Zones:                  []string{"us-east-1a"},
 			DesiredUtilityVersions: map[string]string{"fluentbit": "2.8.7", "nginx": "2.15.0", "prometheus": "10.4.0", "teleport": "0.3.0"},
+			ScaleDownPolicy:        model.ClusterScaleDownPolicyNone,
+			Provisioner:            model.ProvisionerKops,
 		}
 	}
 
@@ -82,6 +84,8 @@ func TestNewCreateClusterRequestFromReader(t *testing.T) {
 				"nginx":      "2.15.0",
 				"prometheus": "10.4.0",
 				"teleport":   "0.3.0"},
+			ScaleDownPolicy: model.ClusterScaleDownPolicyNone,
+			Provisioner:     model.ProvisionerKops,
 		}, clusterRequest)
 	})
 }
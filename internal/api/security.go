@@ -33,6 +33,10 @@ func initSecurity(apiRouter *mux.Router, context *Context) {
 	securityGroupRouter := securityRouter.PathPrefix("/group/{group:[A-Za-z0-9]{26}}").Subrouter()
 	securityGroupRouter.Handle("/api/lock", addContext(handleGroupLockAPI)).Methods("POST")
 	securityGroupRouter.Handle("/api/unlock", addContext(handleGroupUnlockAPI)).Methods("POST")
+
+	securityDatabaseRouter := securityRouter.PathPrefix("/database/{database:[A-Za-z0-9_-]+}").Subrouter()
+	securityDatabaseRouter.Handle("/api/lock", addContext(handleDatabaseLockAPI)).Methods("POST")
+	securityDatabaseRouter.Handle("/api/unlock", addContext(handleDatabaseUnlockAPI)).Methods("POST")
 }
 
 // handleClusterLockAPI responds to POST /api/cluster/{cluster}/api/lock,
@@ -274,3 +278,63 @@ func handleGroupUnlockAPI(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleDatabaseLockAPI responds to POST /api/database/{database}/api/lock,
+// locking API changes for this multitenant database.
+func handleDatabaseLockAPI(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseID := vars["database"]
+	c.Logger = c.Logger.WithField("database", databaseID)
+
+	database, err := c.Store.GetMultitenantDatabase(databaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if database == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !database.APISecurityLock {
+		err = c.Store.LockMultitenantDatabaseAPI(database.ID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to lock multitenant database API")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDatabaseUnlockAPI responds to POST /api/database/{database}/api/unlock,
+// unlocking API changes for this multitenant database.
+func handleDatabaseUnlockAPI(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseID := vars["database"]
+	c.Logger = c.Logger.WithField("database", databaseID)
+
+	database, err := c.Store.GetMultitenantDatabase(databaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query multitenant database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if database == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if database.APISecurityLock {
+		err = c.Store.UnlockMultitenantDatabaseAPI(database.ID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to unlock multitenant database API")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
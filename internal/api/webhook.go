@@ -23,6 +23,7 @@ func initWebhook(apiRouter *mux.Router, context *Context) {
 
 	webhookRouter := apiRouter.PathPrefix("/webhook/{webhook:[A-Za-z0-9]{26}}").Subrouter()
 	webhookRouter.Handle("", addContext(handleGetWebhook)).Methods("GET")
+	webhookRouter.Handle("", addContext(handleUpdateWebhook)).Methods("PUT")
 	webhookRouter.Handle("", addContext(handleDeleteWebhook)).Methods("DELETE")
 }
 
@@ -34,10 +35,23 @@ func handleCreateWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !c.canAccessOwner(createWebhookRequest.OwnerID) {
+		c.Logger.Warnf("not allowed to create webhooks for owner %s", createWebhookRequest.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	eventFilter, ok := c.ownerEventFilter(createWebhookRequest.EventFilter)
+	if !ok {
+		c.Logger.Warnf("not allowed to receive events of owner %s", createWebhookRequest.EventFilter.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
 
 	webhook := model.Webhook{
-		OwnerID: createWebhookRequest.OwnerID,
-		URL:     createWebhookRequest.URL,
+		OwnerID:     createWebhookRequest.OwnerID,
+		URL:         createWebhookRequest.URL,
+		Secret:      createWebhookRequest.Secret,
+		EventFilter: eventFilter,
 	}
 
 	err = c.Store.CreateWebhook(&webhook)
@@ -108,6 +122,57 @@ func handleGetWebhooks(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, webhooks)
 }
 
+// handleUpdateWebhook responds to PUT /api/webhook/{webhook}, updating the
+// event filter of the webhook.
+func handleUpdateWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	c.Logger = c.Logger.WithField("webhook", webhookID)
+
+	updateWebhookRequest, err := model.NewUpdateWebhookRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if webhook.IsDeleted() {
+		c.Logger.Warn("unable to update webhook that is deleted")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	eventFilter, ok := c.ownerEventFilter(updateWebhookRequest.EventFilter)
+	if !ok {
+		c.Logger.Warnf("not allowed to receive events of owner %s", updateWebhookRequest.EventFilter.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	webhook.EventFilter = eventFilter
+
+	err = c.Store.UpdateWebhook(webhook)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, webhook)
+}
+
 // handleDeleteWebhook responds to DELETE /api/webhook/{webhook}, deleting the webhook.
 func handleDeleteWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -139,3 +204,24 @@ func handleDeleteWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// ownerEventFilter returns the given event filter restricted to the events of
+// the owner the principal of the request is restricted to, if any, so that
+// its webhooks never receive the events of other owners. False is returned if
+// the filter explicitly requests the events of another owner.
+func (c *Context) ownerEventFilter(filter *model.WebhookEventFilter) (*model.WebhookEventFilter, bool) {
+	if c.Principal == nil || !c.Principal.IsOwnerRestricted() {
+		return filter, true
+	}
+	if filter == nil {
+		return &model.WebhookEventFilter{OwnerID: c.Principal.OwnerID}, true
+	}
+	if filter.OwnerID != "" && filter.OwnerID != c.Principal.OwnerID {
+		return nil, false
+	}
+
+	ownerFilter := *filter
+	ownerFilter.OwnerID = c.Principal.OwnerID
+
+	return &ownerFilter, true
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initWebhookDelivery registers webhook delivery endpoints on the given router.
+func initWebhookDelivery(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	webhookDeliveriesRouter := apiRouter.PathPrefix("/webhook_deliveries").Subrouter()
+	webhookDeliveriesRouter.Handle("", addContext(handleGetWebhookDeliveries)).Methods("GET")
+
+	webhookDeliveryRouter := apiRouter.PathPrefix("/webhook_delivery/{delivery:[A-Za-z0-9]{26}}").Subrouter()
+	webhookDeliveryRouter.Handle("", addContext(handleGetWebhookDelivery)).Methods("GET")
+	webhookDeliveryRouter.Handle("/redeliver", addContext(handleRedeliverWebhookDelivery)).Methods("POST")
+}
+
+// handleGetWebhookDelivery responds to GET /api/webhook_delivery/{delivery},
+// returning the webhook delivery in question.
+func handleGetWebhookDelivery(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliveryID := vars["delivery"]
+	c.Logger = c.Logger.WithField("webhook_delivery", deliveryID)
+
+	delivery, err := c.Store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook delivery")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if delivery == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, delivery)
+}
+
+// handleGetWebhookDeliveries responds to GET /api/webhook_deliveries,
+// returning the specified page of webhook deliveries.
+func handleGetWebhookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	state := parseString(r.URL, "state", "")
+	if state != "" && !model.IsValidWebhookDeliveryState(state) {
+		c.Logger.Errorf("invalid webhook delivery state %s", state)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.WebhookDeliveryFilter{
+		WebhookID: parseString(r.URL, "webhook", ""),
+		State:     state,
+		Page:      page,
+		PerPage:   perPage,
+	}
+
+	deliveries, err := c.Store.GetWebhookDeliveries(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook deliveries")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*model.WebhookDelivery{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, deliveries)
+}
+
+// handleRedeliverWebhookDelivery responds to POST
+// /api/webhook_delivery/{delivery}/redeliver, queueing a failed or already
+// delivered webhook delivery for delivery again.
+func handleRedeliverWebhookDelivery(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliveryID := vars["delivery"]
+	c.Logger = c.Logger.WithField("webhook_delivery", deliveryID)
+
+	delivery, status, unlockOnce := lockWebhookDelivery(c, deliveryID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if !delivery.CanRedeliver() {
+		c.Logger.Warnf("unable to redeliver webhook delivery while in state %s", delivery.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	delivery.Redeliver()
+
+	err := c.Store.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to queue webhook delivery for redelivery")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, delivery)
+}
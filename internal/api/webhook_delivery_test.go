@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	hook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+		OwnerID: "owner",
+		URL:     "https://validurl.com",
+	})
+	require.NoError(t, err)
+
+	delivery1 := &model.WebhookDelivery{
+		WebhookID: hook.ID,
+		Payload:   &model.WebhookPayload{ID: model.NewID(), Type: model.TypeCluster},
+	}
+	err = sqlStore.CreateWebhookDelivery(delivery1)
+	require.NoError(t, err)
+
+	delivery2 := &model.WebhookDelivery{
+		WebhookID: hook.ID,
+		Payload:   &model.WebhookPayload{ID: model.NewID(), Type: model.TypeInstallation},
+	}
+	err = sqlStore.CreateWebhookDelivery(delivery2)
+	require.NoError(t, err)
+
+	delivery2.State = model.WebhookDeliveryStateDeadLetter
+	delivery2.Attempts = 10
+	delivery2.LastError = "unable to send webhook"
+	err = sqlStore.UpdateWebhookDelivery(delivery2)
+	require.NoError(t, err)
+
+	t.Run("get unknown delivery", func(t *testing.T) {
+		delivery, err := client.GetWebhookDelivery(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, delivery)
+	})
+
+	t.Run("get delivery", func(t *testing.T) {
+		delivery, err := client.GetWebhookDelivery(delivery1.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery1.ID, delivery.ID)
+		require.Equal(t, delivery1.Payload, delivery.Payload)
+	})
+
+	t.Run("list deliveries", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(&model.GetWebhookDeliveriesRequest{
+			WebhookID: hook.ID,
+			Page:      0,
+			PerPage:   10,
+		})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+	})
+
+	t.Run("list dead letter deliveries", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(&model.GetWebhookDeliveriesRequest{
+			State:   model.WebhookDeliveryStateDeadLetter,
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, delivery2.ID, deliveries[0].ID)
+	})
+
+	t.Run("list with invalid state", func(t *testing.T) {
+		_, err := client.GetWebhookDeliveries(&model.GetWebhookDeliveriesRequest{
+			State:   "invalid",
+			Page:    0,
+			PerPage: 10,
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("redeliver pending delivery", func(t *testing.T) {
+		_, err := client.RedeliverWebhookDelivery(delivery1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("redeliver while locked", func(t *testing.T) {
+		lockerID := model.NewID()
+		locked, err := sqlStore.LockWebhookDelivery(delivery2.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+		defer func() {
+			unlocked, err := sqlStore.UnlockWebhookDelivery(delivery2.ID, lockerID, false)
+			require.NoError(t, err)
+			require.True(t, unlocked)
+		}()
+
+		_, err = client.RedeliverWebhookDelivery(delivery2.ID)
+		require.EqualError(t, err, "failed with status code 409")
+	})
+
+	t.Run("redeliver dead letter delivery", func(t *testing.T) {
+		delivery, err := client.RedeliverWebhookDelivery(delivery2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+		require.Equal(t, 0, delivery.Attempts)
+		require.Empty(t, delivery.LastError)
+
+		delivery, err = sqlStore.GetWebhookDelivery(delivery2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+	})
+}
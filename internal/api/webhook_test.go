@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/auth"
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -81,6 +82,21 @@ func TestCreateWebhook(t *testing.T) {
 		require.NotEqual(t, 0, webhook.CreateAt)
 		require.EqualValues(t, 0, webhook.DeleteAt)
 	})
+
+	t.Run("valid with secret", func(t *testing.T) {
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner",
+			URL:     "https://signedurl.com",
+			Secret:  "secret",
+		})
+		require.NoError(t, err)
+		require.Empty(t, webhook.Secret)
+
+		storedWebhook, err := sqlStore.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, "secret", storedWebhook.Secret)
+		require.True(t, storedWebhook.IsSigned())
+	})
 }
 
 func TestGetWebhooks(t *testing.T) {
@@ -265,6 +281,86 @@ func TestGetWebhooks(t *testing.T) {
 	})
 }
 
+func TestUpdateWebhook(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+		OwnerID: "owner",
+		URL:     "https://validurl.com",
+		EventFilter: &model.WebhookEventFilter{
+			Types: []string{model.TypeCluster},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{model.TypeCluster}, webhook.EventFilter.Types)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		httpRequest, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/webhook/%s", ts.URL, webhook.ID), bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(httpRequest)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		_, err := client.UpdateWebhook(model.NewID(), &model.UpdateWebhookRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("invalid event filter", func(t *testing.T) {
+		_, err := client.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{
+			EventFilter: &model.WebhookEventFilter{
+				NewStates: []string{"unknown"},
+			},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		eventFilter := &model.WebhookEventFilter{
+			Types:     []string{model.TypeInstallation},
+			NewStates: []string{model.InstallationStateStable, model.InstallationStateHibernating},
+			OwnerID:   "billing",
+		}
+		updatedWebhook, err := client.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{
+			EventFilter: eventFilter,
+		})
+		require.NoError(t, err)
+		require.Equal(t, eventFilter, updatedWebhook.EventFilter)
+
+		updatedWebhook, err = client.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, eventFilter, updatedWebhook.EventFilter)
+	})
+
+	t.Run("remove event filter", func(t *testing.T) {
+		updatedWebhook, err := client.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{})
+		require.NoError(t, err)
+		require.Nil(t, updatedWebhook.EventFilter)
+	})
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		err := client.DeleteWebhook(webhook.ID)
+		require.NoError(t, err)
+
+		_, err = client.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+}
+
 func TestDeleteWebhook(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -307,3 +403,96 @@ func TestDeleteWebhook(t *testing.T) {
 		require.True(t, webhook.IsDeleted())
 	})
 }
+
+func TestWebhookOwnerRestriction(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		Authenticator: auth.NewTokenAuthenticator(sqlStore),
+		Logger:        logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newClient := func(t *testing.T, request *model.CreateAPITokenRequest) *model.Client {
+		request.SetDefaults()
+		token, err := auth.NewAPIToken(request)
+		require.NoError(t, err)
+		err = sqlStore.CreateAPIToken(token)
+		require.NoError(t, err)
+
+		return model.NewClientWithHeaders(ts.URL, map[string]string{
+			"Authorization": "Bearer " + token.Token,
+		})
+	}
+
+	adminClient := newClient(t, &model.CreateAPITokenRequest{Name: "admin", Scope: model.APITokenScopeClusterAdmin})
+	operatorClient := newClient(t, &model.CreateAPITokenRequest{Name: "operator", OwnerID: "owner1", Scope: model.APITokenScopeInstallationOperator})
+
+	t.Run("create without an event filter", func(t *testing.T) {
+		webhook, err := operatorClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner1", URL: "https://example.com/no-filter"})
+		require.NoError(t, err)
+		require.Equal(t, &model.WebhookEventFilter{OwnerID: "owner1"}, webhook.EventFilter)
+	})
+
+	t.Run("create with an event filter of another owner", func(t *testing.T) {
+		_, err := operatorClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:     "owner1",
+			URL:         "https://example.com/other-owner",
+			EventFilter: &model.WebhookEventFilter{OwnerID: "owner2"},
+		})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		webhook, err := operatorClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:     "owner1",
+			URL:         "https://example.com/update",
+			EventFilter: &model.WebhookEventFilter{Types: []string{model.TypeInstallation}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &model.WebhookEventFilter{Types: []string{model.TypeInstallation}, OwnerID: "owner1"}, webhook.EventFilter)
+
+		webhook, err = operatorClient.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{})
+		require.NoError(t, err)
+		require.Equal(t, &model.WebhookEventFilter{OwnerID: "owner1"}, webhook.EventFilter)
+
+		_, err = operatorClient.UpdateWebhook(webhook.ID, &model.UpdateWebhookRequest{
+			EventFilter: &model.WebhookEventFilter{OwnerID: "owner2"},
+		})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("unrestricted principals may receive every event", func(t *testing.T) {
+		webhook, err := adminClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner1", URL: "https://example.com/admin"})
+		require.NoError(t, err)
+		require.Nil(t, webhook.EventFilter)
+
+		err = adminClient.DeleteWebhook(webhook.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("no events of other owners are delivered", func(t *testing.T) {
+		webhook, err := operatorClient.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner1", URL: "https://example.com/deliveries"})
+		require.NoError(t, err)
+
+		_, err = adminClient.CreateInstallation(&model.CreateInstallationRequest{OwnerID: "owner2", DNS: "owner2.example.com"})
+		require.NoError(t, err)
+
+		deliveries, err := sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{WebhookID: webhook.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		installation, err := operatorClient.CreateInstallation(&model.CreateInstallationRequest{OwnerID: "owner1", DNS: "owner1.example.com"})
+		require.NoError(t, err)
+
+		deliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{WebhookID: webhook.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, installation.ID, deliveries[0].Payload.ID)
+	})
+}
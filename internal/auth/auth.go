@@ -0,0 +1,76 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package auth authenticates requests made to the provisioning server API.
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// Principal is the authenticated caller of an API request.
+type Principal struct {
+	// Subject identifies the caller, e.g. the ID of a static API token or the
+	// subject of a JWT.
+	Subject string
+	// OwnerID, if set, restricts the caller to resources with the same owner.
+	OwnerID string
+	Scope   string
+}
+
+// HasScope returns true if the principal was granted at least the given scope.
+func (p *Principal) HasScope(scope string) bool {
+	return model.APITokenScopeAllows(p.Scope, scope)
+}
+
+// IsOwnerRestricted returns true if the principal may only access resources
+// belonging to a single owner.
+func (p *Principal) IsOwnerRestricted() bool {
+	return p.OwnerID != ""
+}
+
+// CanAccessOwner returns true if the principal may access resources belonging
+// to the given owner.
+func (p *Principal) CanAccessOwner(ownerID string) bool {
+	return !p.IsOwnerRestricted() || p.OwnerID == ownerID
+}
+
+// Authenticator authenticates API requests.
+type Authenticator interface {
+	// Authenticate returns the principal making the given request. A nil
+	// principal and error is returned if the request does not carry any
+	// credentials recognized by the authenticator, whereas an error is
+	// returned if the credentials are recognized but invalid.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain is an Authenticator trying each of its authenticators in turn until
+// one of them recognizes the credentials of the request.
+type Chain []Authenticator
+
+// Authenticate implements Authenticator.
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+
+	return nil, nil
+}
+
+// bearerToken returns the bearer token from the Authorization header of the
+// given request, if any.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len("Bearer "):])
+}
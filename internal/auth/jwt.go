@@ -0,0 +1,297 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// jwtClockSkew is the tolerated clock difference when validating the
+	// expiry and not-before claims of a JWT.
+	jwtClockSkew = time.Minute
+	// jwksMinRefreshInterval limits how often the signing keys of the issuer
+	// are fetched again when a JWT is signed by an unknown key.
+	jwksMinRefreshInterval = time.Minute
+)
+
+// OIDCConfig configures the verification of JWTs issued by an OpenID Connect
+// provider.
+type OIDCConfig struct {
+	// Issuer is the URL of the provider, used both to discover its signing
+	// keys and to validate the iss claim.
+	Issuer string
+	// Audience, if set, must be one of the values of the aud claim.
+	Audience string
+	// ScopeClaim is the claim holding the API scope granted to the caller.
+	ScopeClaim string
+	// OwnerClaim is the claim holding the owner the caller is restricted to.
+	// Callers without this claim are not restricted to an owner.
+	OwnerClaim string
+}
+
+// JWTAuthenticator authenticates requests bearing a JWT signed by an OpenID
+// Connect provider.
+type JWTAuthenticator struct {
+	config     OIDCConfig
+	httpClient *http.Client
+	logger     log.FieldLogger
+
+	keysLock        sync.Mutex
+	keys            map[string]*rsa.PublicKey
+	keysRefreshedAt time.Time
+}
+
+// NewJWTAuthenticator creates a new JWTAuthenticator. The signing keys of the
+// provider are fetched lazily, on the first authenticated request.
+func NewJWTAuthenticator(config OIDCConfig, logger log.FieldLogger) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+	}
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+
+	var header jwtHeader
+	err := decodeJWTSegment(parts[0], &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode JWT header")
+	}
+	if header.Algorithm != "RS256" {
+		return nil, errors.Errorf("unsupported JWT signing algorithm %s", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode JWT signature")
+	}
+
+	key, err := a.getKey(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT signature")
+	}
+
+	var claims map[string]interface{}
+	err = decodeJWTSegment(parts[1], &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode JWT claims")
+	}
+
+	return a.principalFromClaims(claims, time.Now())
+}
+
+// principalFromClaims validates the given claims and returns the principal
+// they describe.
+func (a *JWTAuthenticator) principalFromClaims(claims map[string]interface{}, now time.Time) (*Principal, error) {
+	if issuer, _ := claims["iss"].(string); issuer != a.config.Issuer {
+		return nil, errors.Errorf("unexpected JWT issuer %q", issuer)
+	}
+	if a.config.Audience != "" && !claimContains(claims["aud"], a.config.Audience) {
+		return nil, errors.New("JWT was not issued for this audience")
+	}
+
+	expiresAt, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("JWT has no expiry")
+	}
+	if now.Add(-jwtClockSkew).After(time.Unix(int64(expiresAt), 0)) {
+		return nil, errors.New("JWT has expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(jwtClockSkew).Before(time.Unix(int64(notBefore), 0)) {
+		return nil, errors.New("JWT is not valid yet")
+	}
+
+	scope := highestScope(claims[a.config.ScopeClaim])
+	if scope == "" {
+		return nil, errors.Errorf("JWT does not grant any valid scope in claim %s", a.config.ScopeClaim)
+	}
+
+	subject, _ := claims["sub"].(string)
+	ownerID, _ := claims[a.config.OwnerClaim].(string)
+
+	return &Principal{
+		Subject: subject,
+		OwnerID: ownerID,
+		Scope:   scope,
+	}, nil
+}
+
+// getKey returns the signing key with the given ID, refreshing the keys of
+// the provider if the key is unknown.
+func (a *JWTAuthenticator) getKey(keyID string) (*rsa.PublicKey, error) {
+	a.keysLock.Lock()
+	defer a.keysLock.Unlock()
+
+	if key, ok := a.keys[keyID]; ok {
+		return key, nil
+	}
+	if time.Since(a.keysRefreshedAt) < jwksMinRefreshInterval {
+		return nil, errors.Errorf("unknown JWT signing key %q", keyID)
+	}
+
+	keys, err := a.fetchKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch JWT signing keys")
+	}
+	a.keys = keys
+	a.keysRefreshedAt = time.Now()
+	a.logger.Debugf("Fetched %d JWT signing keys from %s", len(keys), a.config.Issuer)
+
+	key, ok := a.keys[keyID]
+	if !ok {
+		return nil, errors.Errorf("unknown JWT signing key %q", keyID)
+	}
+
+	return key, nil
+}
+
+// fetchKeys discovers and fetches the RSA signing keys of the provider.
+func (a *JWTAuthenticator) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	err := a.getJSON(strings.TrimSuffix(a.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get OpenID configuration")
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("OpenID configuration has no jwks_uri")
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+	err = a.getJSON(discovery.JWKSURI, &jwks)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get JSON web key set")
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode modulus of key %s", jwk.KeyID)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode exponent of key %s", jwk.KeyID)
+		}
+
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+func (a *JWTAuthenticator) getJSON(url string, v interface{}) error {
+	resp, err := a.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// claimContains returns true if the given string or list claim contains the
+// given value.
+func claimContains(claim interface{}, value string) bool {
+	for _, v := range claimValues(claim) {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// highestScope returns the most privileged valid API scope in the given claim,
+// which is either a list or a space-separated string of scopes.
+func highestScope(claim interface{}) string {
+	var scope string
+	for _, value := range claimValues(claim) {
+		for _, candidate := range strings.Fields(value) {
+			if model.IsValidAPITokenScope(candidate) && (scope == "" || model.APITokenScopeAllows(candidate, scope)) {
+				scope = candidate
+			}
+		}
+	}
+
+	return scope
+}
+
+func claimValues(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []interface{}:
+		var values []string
+		for _, value := range claim {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package auth_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, keyID string, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signingInput := encode(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID}) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	issuer = ts.URL
+
+	authenticator := auth.NewJWTAuthenticator(auth.OIDCConfig{
+		Issuer:     issuer,
+		Audience:   "cloud",
+		ScopeClaim: "cloud_scope",
+		OwnerClaim: "cloud_owner",
+	}, logger)
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":         issuer,
+			"aud":         []string{"other", "cloud"},
+			"sub":         "user",
+			"exp":         time.Now().Add(time.Hour).Unix(),
+			"cloud_scope": "openid read-only installation-operator",
+			"cloud_owner": "owner",
+		}
+	}
+
+	t.Run("not a JWT", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest("static-token"))
+		require.NoError(t, err)
+		require.Nil(t, principal)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest(signJWT(t, key, "key1", validClaims())))
+		require.NoError(t, err)
+		require.Equal(t, &auth.Principal{
+			Subject: "user",
+			OwnerID: "owner",
+			Scope:   model.APITokenScopeInstallationOperator,
+		}, principal)
+	})
+
+	t.Run("scope list", func(t *testing.T) {
+		claims := validClaims()
+		claims["cloud_scope"] = []string{model.APITokenScopeReadOnly}
+		delete(claims, "cloud_owner")
+
+		principal, err := authenticator.Authenticate(newRequest(signJWT(t, key, "key1", claims)))
+		require.NoError(t, err)
+		require.Equal(t, &auth.Principal{
+			Subject: "user",
+			Scope:   model.APITokenScopeReadOnly,
+		}, principal)
+	})
+
+	invalidCases := []struct {
+		Description string
+		KeyID       string
+		Key         *rsa.PrivateKey
+		Modify      func(claims map[string]interface{})
+	}{
+		{"wrong signature", "key1", otherKey, func(claims map[string]interface{}) {}},
+		{"unknown key", "key2", key, func(claims map[string]interface{}) {}},
+		{"wrong issuer", "key1", key, func(claims map[string]interface{}) { claims["iss"] = "https://example.com" }},
+		{"wrong audience", "key1", key, func(claims map[string]interface{}) { claims["aud"] = "other" }},
+		{"expired", "key1", key, func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", "key1", key, func(claims map[string]interface{}) { delete(claims, "exp") }},
+		{"not valid yet", "key1", key, func(claims map[string]interface{}) { claims["nbf"] = time.Now().Add(time.Hour).Unix() }},
+		{"no scope", "key1", key, func(claims map[string]interface{}) { claims["cloud_scope"] = "openid" }},
+	}
+
+	for _, tc := range invalidCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			claims := validClaims()
+			tc.Modify(claims)
+
+			principal, err := authenticator.Authenticate(newRequest(signJWT(t, tc.Key, tc.KeyID, claims)))
+			require.Error(t, err)
+			require.Nil(t, principal)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// tokenBytes is the number of random bytes in a generated API token.
+const tokenBytes = 32
+
+// tokenStore abstracts the database operations required to authenticate
+// static API tokens.
+type tokenStore interface {
+	GetAPITokenByHash(tokenHash string) (*model.APIToken, error)
+}
+
+// GenerateToken returns a new random API token secret.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate random token")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hash of the given API token secret, as persisted in
+// the store.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// NewAPIToken generates a new API token for the given request. The returned
+// token carries both the secret and its hash.
+func NewAPIToken(request *model.CreateAPITokenRequest) (*model.APIToken, error) {
+	secret, err := GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.APIToken{
+		Name:      request.Name,
+		OwnerID:   request.OwnerID,
+		Scope:     request.Scope,
+		Token:     secret,
+		TokenHash: HashToken(secret),
+	}, nil
+}
+
+// TokenAuthenticator authenticates requests bearing a static API token.
+type TokenAuthenticator struct {
+	store tokenStore
+}
+
+// NewTokenAuthenticator creates a new TokenAuthenticator.
+func NewTokenAuthenticator(store tokenStore) *TokenAuthenticator {
+	return &TokenAuthenticator{
+		store: store,
+	}
+}
+
+// Authenticate implements Authenticator.
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+
+	apiToken, err := a.store.GetAPITokenByHash(HashToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up API token")
+	}
+	if apiToken == nil {
+		return nil, nil
+	}
+
+	return &Principal{
+		Subject: apiToken.ID,
+		OwnerID: apiToken.OwnerID,
+		Scope:   apiToken.Scope,
+	}, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/auth"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/clusters", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return r
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	token, err := auth.NewAPIToken(&model.CreateAPITokenRequest{
+		Name:    "operator",
+		OwnerID: "owner",
+		Scope:   model.APITokenScopeInstallationOperator,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, token.Token)
+	require.Equal(t, auth.HashToken(token.Token), token.TokenHash)
+
+	err = sqlStore.CreateAPIToken(token)
+	require.NoError(t, err)
+
+	authenticator := auth.NewTokenAuthenticator(sqlStore)
+
+	t.Run("no credentials", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest(""))
+		require.NoError(t, err)
+		require.Nil(t, principal)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest("unknown"))
+		require.NoError(t, err)
+		require.Nil(t, principal)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest(token.Token))
+		require.NoError(t, err)
+		require.Equal(t, &auth.Principal{
+			Subject: token.ID,
+			OwnerID: "owner",
+			Scope:   model.APITokenScopeInstallationOperator,
+		}, principal)
+		require.True(t, principal.HasScope(model.APITokenScopeReadOnly))
+		require.False(t, principal.HasScope(model.APITokenScopeClusterAdmin))
+		require.True(t, principal.CanAccessOwner("owner"))
+		require.False(t, principal.CanAccessOwner("other"))
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		err := sqlStore.DeleteAPIToken(token.ID)
+		require.NoError(t, err)
+
+		principal, err := authenticator.Authenticate(newRequest(token.Token))
+		require.NoError(t, err)
+		require.Nil(t, principal)
+	})
+}
+
+type staticAuthenticator struct {
+	principal *auth.Principal
+	err       error
+}
+
+func (a *staticAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	return a.principal, a.err
+}
+
+func TestChain(t *testing.T) {
+	principal := &auth.Principal{Subject: "subject", Scope: model.APITokenScopeReadOnly}
+
+	t.Run("empty", func(t *testing.T) {
+		actual, err := auth.Chain{}.Authenticate(newRequest("token"))
+		require.NoError(t, err)
+		require.Nil(t, actual)
+	})
+
+	t.Run("falls through unrecognized credentials", func(t *testing.T) {
+		actual, err := auth.Chain{
+			&staticAuthenticator{},
+			&staticAuthenticator{principal: principal},
+		}.Authenticate(newRequest("token"))
+		require.NoError(t, err)
+		require.Equal(t, principal, actual)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		actual, err := auth.Chain{
+			&staticAuthenticator{err: http.ErrNoCookie},
+			&staticAuthenticator{principal: principal},
+		}.Authenticate(newRequest("token"))
+		require.Error(t, err)
+		require.Nil(t, actual)
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package metrics defines the Prometheus metrics exposed by the provisioning
+// server.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "cloud"
+
+	// LockFailureContended is the reason for a lock failure when the rows
+	// were already locked by someone else.
+	LockFailureContended = "contended"
+	// LockFailureError is the reason for a lock failure when the lock query
+	// itself failed.
+	LockFailureError = "error"
+
+	// WebhookDeliveryDelivered is the outcome of a webhook delivery attempt
+	// that was accepted by the receiver.
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed is the outcome of a failed webhook delivery
+	// attempt that will be retried.
+	WebhookDeliveryFailed = "failed"
+	// WebhookDeliveryDeadLetter is the outcome of a failed webhook delivery
+	// attempt that will not be retried.
+	WebhookDeliveryDeadLetter = "dead-letter"
+)
+
+var (
+	// SupervisorDuration tracks how long each execution of a supervisor takes.
+	SupervisorDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "do_duration_seconds",
+			Help:      "The duration of each supervisor execution.",
+			Buckets:   []float64{0.01, 0.1, 1, 5, 15, 30, 60, 120, 300, 600, 1200},
+		},
+		[]string{"supervisor"},
+	)
+
+	// SupervisorErrors counts supervisor executions that returned an error.
+	SupervisorErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "do_errors_total",
+			Help:      "The number of supervisor executions that returned an error.",
+		},
+		[]string{"supervisor"},
+	)
+
+	// LockFailures counts failures to acquire a lock on rows of a table.
+	LockFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "lock_failures_total",
+			Help:      "The number of failures to acquire a lock on rows of a table.",
+		},
+		[]string{"table", "reason"},
+	)
+
+	// APIRequestDuration tracks the latency of API requests per route.
+	APIRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "The duration of API requests.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status_code"},
+	)
+
+	// WebhookDeliveries counts webhook delivery attempts by outcome.
+	WebhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhook",
+			Name:      "delivery_attempts_total",
+			Help:      "The number of webhook delivery attempts by outcome.",
+		},
+		[]string{"outcome"},
+	)
+
+	// StateDuration tracks how long resources spend in each state before
+	// transitioning to another one.
+	StateDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "state_duration_seconds",
+			Help:      "The time resources spent in a state before transitioning out of it.",
+			Buckets:   []float64{1, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400},
+		},
+		[]string{"type", "state"},
+	)
+)
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// stateStore abstracts the database operations required to collect the
+// state metrics.
+type stateStore interface {
+	GetClusterStateSummaries() ([]*model.ResourceStateSummary, error)
+	GetInstallationStateSummaries() ([]*model.ResourceStateSummary, error)
+}
+
+// StateCollector reports the number of clusters and installations in each
+// state, and how long the oldest of them has been in that state, whenever
+// metrics are scraped.
+type StateCollector struct {
+	store  stateStore
+	logger log.FieldLogger
+
+	clusters             *prometheus.Desc
+	clusterStateAge      *prometheus.Desc
+	installations        *prometheus.Desc
+	installationStateAge *prometheus.Desc
+}
+
+// NewStateCollector creates a new StateCollector.
+func NewStateCollector(store stateStore, logger log.FieldLogger) *StateCollector {
+	return &StateCollector{
+		store:  store,
+		logger: logger,
+		clusters: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "clusters"),
+			"The number of clusters in each state.",
+			[]string{"state"}, nil,
+		),
+		clusterStateAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "cluster_state_age_max_seconds"),
+			"The longest time any cluster has been in its current state.",
+			[]string{"state"}, nil,
+		),
+		installations: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "installations"),
+			"The number of installations in each state.",
+			[]string{"state"}, nil,
+		),
+		installationStateAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "installation_state_age_max_seconds"),
+			"The longest time any installation has been in its current state.",
+			[]string{"state"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.clusters
+	ch <- c.clusterStateAge
+	ch <- c.installations
+	ch <- c.installationStateAge
+}
+
+// Collect implements prometheus.Collector.
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+
+	clusters, err := c.store.GetClusterStateSummaries()
+	if err != nil {
+		c.logger.WithError(err).Error("Failed to query cluster states for metrics")
+	} else {
+		collectStateSummaries(ch, c.clusters, c.clusterStateAge, clusters, now)
+	}
+
+	installations, err := c.store.GetInstallationStateSummaries()
+	if err != nil {
+		c.logger.WithError(err).Error("Failed to query installation states for metrics")
+	} else {
+		collectStateSummaries(ch, c.installations, c.installationStateAge, installations, now)
+	}
+}
+
+func collectStateSummaries(ch chan<- prometheus.Metric, count, age *prometheus.Desc, summaries []*model.ResourceStateSummary, now time.Time) {
+	for _, summary := range summaries {
+		ch <- prometheus.MustNewConstMetric(count, prometheus.GaugeValue, float64(summary.Count), summary.State)
+
+		oldest := time.Unix(0, summary.OldestStateChangeAt*int64(time.Millisecond))
+		ch <- prometheus.MustNewConstMetric(age, prometheus.GaugeValue, now.Sub(oldest).Seconds(), summary.State)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStateStore struct {
+	ClusterStateSummaries      []*model.ResourceStateSummary
+	InstallationStateSummaries []*model.ResourceStateSummary
+	Err                        error
+}
+
+func (s *mockStateStore) GetClusterStateSummaries() ([]*model.ResourceStateSummary, error) {
+	return s.ClusterStateSummaries, s.Err
+}
+
+func (s *mockStateStore) GetInstallationStateSummaries() ([]*model.ResourceStateSummary, error) {
+	return s.InstallationStateSummaries, s.Err
+}
+
+func TestStateCollector(t *testing.T) {
+	// gather collects the metrics of the given store, keyed by metric name
+	// and state label.
+	gather := func(t *testing.T, store stateStore) map[string]map[string]float64 {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(NewStateCollector(store, testlib.MakeLogger(t)))
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		values := make(map[string]map[string]float64)
+		for _, family := range families {
+			values[family.GetName()] = make(map[string]float64)
+			for _, metric := range family.GetMetric() {
+				values[family.GetName()][metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+			}
+		}
+
+		return values
+	}
+
+	t.Run("store error", func(t *testing.T) {
+		values := gather(t, &mockStateStore{Err: errors.New("failure")})
+		require.Empty(t, values)
+	})
+
+	t.Run("states", func(t *testing.T) {
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+
+		values := gather(t, &mockStateStore{
+			ClusterStateSummaries: []*model.ResourceStateSummary{
+				{State: model.ClusterStateStable, Count: 3, OldestStateChangeAt: now - 60*1000},
+			},
+			InstallationStateSummaries: []*model.ResourceStateSummary{
+				{State: model.InstallationStateStable, Count: 10, OldestStateChangeAt: now - 3600*1000},
+				{State: model.InstallationStateUpdateInProgress, Count: 2, OldestStateChangeAt: now - 7200*1000},
+			},
+		})
+
+		require.Equal(t, map[string]float64{model.ClusterStateStable: 3}, values["cloud_clusters"])
+		require.Equal(t, map[string]float64{
+			model.InstallationStateStable:           10,
+			model.InstallationStateUpdateInProgress: 2,
+		}, values["cloud_installations"])
+
+		require.InDelta(t, 60, values["cloud_cluster_state_age_max_seconds"][model.ClusterStateStable], 5)
+		require.InDelta(t, 3600, values["cloud_installation_state_age_max_seconds"][model.InstallationStateStable], 5)
+		require.InDelta(t, 7200, values["cloud_installation_state_age_max_seconds"][model.InstallationStateUpdateInProgress], 5)
+	})
+}
@@ -14,6 +14,7 @@
 //go:generate ../../../bin/mockgen -package=mocks -destination ./resource_tagging.go github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface ResourceGroupsTaggingAPIAPI
 //go:generate ../../../bin/mockgen -package=mocks -destination ./sts.go github.com/aws/aws-sdk-go/service/sts/stsiface STSAPI
 //go:generate ../../../bin/mockgen -package=mocks -destination ./dynamodb.go github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface DynamoDBAPI
+//go:generate ../../../bin/mockgen -package=mocks -destination ./eks.go github.com/aws/aws-sdk-go/service/eks/eksiface EKSAPI
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt ec2.go > _ec2.go && mv _ec2.go ec2.go"
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt rds.go > _rds.go && mv _rds.go rds.go"
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt s3.go > _s3.go && mv _s3.go s3.go"
@@ -25,4 +26,5 @@
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt resource_tagging.go > _resource_tagging.go && mv _resource_tagging.go resource_tagging.go"
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt sts.go > _sts.go && mv _sts.go sts.go"
 //go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt dynamodb.go > _dynamodb.go && mv _dynamodb.go dynamodb.go"
+//go:generate /usr/bin/env bash -c "cat ../../../hack/boilerplate/boilerplate.generatego.txt eks.go > _eks.go && mv _eks.go eks.go"
 package mocks //nolint
@@ -9,9 +9,11 @@ package mocks
 
 import (
 	acm "github.com/aws/aws-sdk-go/service/acm"
+	eks "github.com/aws/aws-sdk-go/service/eks"
 	iam "github.com/aws/aws-sdk-go/service/iam"
 	gomock "github.com/golang/mock/gomock"
 	aws "github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	model "github.com/mattermost/mattermost-cloud/model"
 	logrus "github.com/sirupsen/logrus"
 	reflect "reflect"
 )
@@ -99,6 +101,21 @@ func (mr *MockAWSMockRecorder) GetAndClaimVpcResources(clusterID, owner, logger
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAndClaimVpcResources", reflect.TypeOf((*MockAWS)(nil).GetAndClaimVpcResources), clusterID, owner, logger)
 }
 
+// GetClaimedVpcID mocks base method
+func (m *MockAWS) GetClaimedVpcID(clusterID string, logger logrus.FieldLogger) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClaimedVpcID", clusterID, logger)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClaimedVpcID indicates an expected call of GetClaimedVpcID
+func (mr *MockAWSMockRecorder) GetClaimedVpcID(clusterID, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClaimedVpcID", reflect.TypeOf((*MockAWS)(nil).GetClaimedVpcID), clusterID, logger)
+}
+
 // ReleaseVpc mocks base method
 func (m *MockAWS) ReleaseVpc(clusterID string, logger logrus.FieldLogger) error {
 	m.ctrl.T.Helper()
@@ -299,6 +316,95 @@ func (mr *MockAWSMockRecorder) IsValidAMI(AMIImage, logger interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValidAMI", reflect.TypeOf((*MockAWS)(nil).IsValidAMI), AMIImage, logger)
 }
 
+// GetSpotInterruptionWarnings mocks base method
+func (m *MockAWS) GetSpotInterruptionWarnings(kopsClusterName string, logger logrus.FieldLogger) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpotInterruptionWarnings", kopsClusterName, logger)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpotInterruptionWarnings indicates an expected call of GetSpotInterruptionWarnings
+func (mr *MockAWSMockRecorder) GetSpotInterruptionWarnings(kopsClusterName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpotInterruptionWarnings", reflect.TypeOf((*MockAWS)(nil).GetSpotInterruptionWarnings), kopsClusterName, logger)
+}
+
+// EnsureEKSCluster mocks base method
+func (m *MockAWS) EnsureEKSCluster(cluster *model.Cluster, resources aws.ClusterResources, logger logrus.FieldLogger) (*eks.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEKSCluster", cluster, resources, logger)
+	ret0, _ := ret[0].(*eks.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEKSCluster indicates an expected call of EnsureEKSCluster
+func (mr *MockAWSMockRecorder) EnsureEKSCluster(cluster, resources, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEKSCluster", reflect.TypeOf((*MockAWS)(nil).EnsureEKSCluster), cluster, resources, logger)
+}
+
+// EnsureEKSNodeGroup mocks base method
+func (m *MockAWS) EnsureEKSNodeGroup(cluster *model.Cluster, nodeGroup *model.EKSNodeGroup, subnetIDs []string, logger logrus.FieldLogger) (*eks.Nodegroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEKSNodeGroup", cluster, nodeGroup, subnetIDs, logger)
+	ret0, _ := ret[0].(*eks.Nodegroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEKSNodeGroup indicates an expected call of EnsureEKSNodeGroup
+func (mr *MockAWSMockRecorder) EnsureEKSNodeGroup(cluster, nodeGroup, subnetIDs, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEKSNodeGroup", reflect.TypeOf((*MockAWS)(nil).EnsureEKSNodeGroup), cluster, nodeGroup, subnetIDs, logger)
+}
+
+// GetEKSCluster mocks base method
+func (m *MockAWS) GetEKSCluster(clusterName string) (*eks.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEKSCluster", clusterName)
+	ret0, _ := ret[0].(*eks.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEKSCluster indicates an expected call of GetEKSCluster
+func (mr *MockAWSMockRecorder) GetEKSCluster(clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEKSCluster", reflect.TypeOf((*MockAWS)(nil).GetEKSCluster), clusterName)
+}
+
+// GetEKSNodeGroups mocks base method
+func (m *MockAWS) GetEKSNodeGroups(clusterName string) ([]*eks.Nodegroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEKSNodeGroups", clusterName)
+	ret0, _ := ret[0].([]*eks.Nodegroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEKSNodeGroups indicates an expected call of GetEKSNodeGroups
+func (mr *MockAWSMockRecorder) GetEKSNodeGroups(clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEKSNodeGroups", reflect.TypeOf((*MockAWS)(nil).GetEKSNodeGroups), clusterName)
+}
+
+// EnsureEKSClusterDeleted mocks base method
+func (m *MockAWS) EnsureEKSClusterDeleted(clusterName string, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEKSClusterDeleted", clusterName, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureEKSClusterDeleted indicates an expected call of EnsureEKSClusterDeleted
+func (mr *MockAWSMockRecorder) EnsureEKSClusterDeleted(clusterName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEKSClusterDeleted", reflect.TypeOf((*MockAWS)(nil).EnsureEKSClusterDeleted), clusterName, logger)
+}
+
 // DynamoDBEnsureTableDeleted mocks base method
 func (m *MockAWS) DynamoDBEnsureTableDeleted(tableName string, logger logrus.FieldLogger) error {
 	m.ctrl.T.Helper()
@@ -208,6 +208,20 @@ func (mr *MockInstallationDatabaseStoreInterfaceMockRecorder) UpdateMultitenantD
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMultitenantDatabase", reflect.TypeOf((*MockInstallationDatabaseStoreInterface)(nil).UpdateMultitenantDatabase), multitenantDatabase)
 }
 
+// DeleteMultitenantDatabase mocks base method
+func (m *MockInstallationDatabaseStoreInterface) DeleteMultitenantDatabase(multitenantDatabaseID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMultitenantDatabase", multitenantDatabaseID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMultitenantDatabase indicates an expected call of DeleteMultitenantDatabase
+func (mr *MockInstallationDatabaseStoreInterfaceMockRecorder) DeleteMultitenantDatabase(multitenantDatabaseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMultitenantDatabase", reflect.TypeOf((*MockInstallationDatabaseStoreInterface)(nil).DeleteMultitenantDatabase), multitenantDatabaseID)
+}
+
 // LockMultitenantDatabase mocks base method
 func (m *MockInstallationDatabaseStoreInterface) LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -237,3 +251,33 @@ func (mr *MockInstallationDatabaseStoreInterfaceMockRecorder) UnlockMultitenantD
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockMultitenantDatabase", reflect.TypeOf((*MockInstallationDatabaseStoreInterface)(nil).UnlockMultitenantDatabase), multitenantdatabaseID, lockerID, force)
 }
+
+// LockMultitenantDatabaseVPC mocks base method
+func (m *MockInstallationDatabaseStoreInterface) LockMultitenantDatabaseVPC(vpcID, lockerID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockMultitenantDatabaseVPC", vpcID, lockerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockMultitenantDatabaseVPC indicates an expected call of LockMultitenantDatabaseVPC
+func (mr *MockInstallationDatabaseStoreInterfaceMockRecorder) LockMultitenantDatabaseVPC(vpcID, lockerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockMultitenantDatabaseVPC", reflect.TypeOf((*MockInstallationDatabaseStoreInterface)(nil).LockMultitenantDatabaseVPC), vpcID, lockerID)
+}
+
+// UnlockMultitenantDatabaseVPC mocks base method
+func (m *MockInstallationDatabaseStoreInterface) UnlockMultitenantDatabaseVPC(vpcID, lockerID string, force bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockMultitenantDatabaseVPC", vpcID, lockerID, force)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockMultitenantDatabaseVPC indicates an expected call of UnlockMultitenantDatabaseVPC
+func (mr *MockInstallationDatabaseStoreInterfaceMockRecorder) UnlockMultitenantDatabaseVPC(vpcID, lockerID, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockMultitenantDatabaseVPC", reflect.TypeOf((*MockInstallationDatabaseStoreInterface)(nil).UnlockMultitenantDatabaseVPC), vpcID, lockerID, force)
+}
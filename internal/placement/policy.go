@@ -0,0 +1,109 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package placement
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// Policy orders the clusters able to host an installation.
+type Policy interface {
+	// Name returns the name of the policy.
+	Name() string
+	// Less returns true if the installation should rather be placed on the
+	// cluster of placement a than on the cluster of placement b.
+	Less(a, b *model.ClusterPlacement) bool
+}
+
+// NewPolicy returns the scheduling policy with the given name.
+func NewPolicy(name string) (Policy, error) {
+	switch name {
+	case model.SchedulingPolicyFirstFit:
+		return FirstFitPolicy{}, nil
+	case model.SchedulingPolicyBinPack:
+		return BinPackPolicy{}, nil
+	case model.SchedulingPolicySpread:
+		return SpreadPolicy{}, nil
+	case model.SchedulingPolicyLabel:
+		return LabelPolicy{}, nil
+	}
+
+	return nil, errors.Errorf("unsupported scheduling policy %s", name)
+}
+
+// FirstFitPolicy keeps the clusters in the order they were created, placing
+// installations on the oldest cluster able to host them.
+type FirstFitPolicy struct{}
+
+// Name returns the name of the policy.
+func (FirstFitPolicy) Name() string {
+	return model.SchedulingPolicyFirstFit
+}
+
+// Less keeps the existing order of the clusters.
+func (FirstFitPolicy) Less(a, b *model.ClusterPlacement) bool {
+	return false
+}
+
+// BinPackPolicy places installations on the most loaded cluster, only scaling
+// up clusters when no other cluster is able to host them.
+type BinPackPolicy struct{}
+
+// Name returns the name of the policy.
+func (BinPackPolicy) Name() string {
+	return model.SchedulingPolicyBinPack
+}
+
+// Less prefers the most loaded cluster.
+func (BinPackPolicy) Less(a, b *model.ClusterPlacement) bool {
+	if a.RequiresScaleUp != b.RequiresScaleUp {
+		return !a.RequiresScaleUp
+	}
+
+	return load(a) > load(b)
+}
+
+// SpreadPolicy places installations on the least loaded cluster, only scaling
+// up clusters when no other cluster is able to host them.
+type SpreadPolicy struct{}
+
+// Name returns the name of the policy.
+func (SpreadPolicy) Name() string {
+	return model.SchedulingPolicySpread
+}
+
+// Less prefers the least loaded cluster.
+func (SpreadPolicy) Less(a, b *model.ClusterPlacement) bool {
+	if a.RequiresScaleUp != b.RequiresScaleUp {
+		return !a.RequiresScaleUp
+	}
+
+	return load(a) < load(b)
+}
+
+// LabelPolicy places installations on the cluster matching most of their
+// preferred labels, falling back to the order the clusters were created in.
+type LabelPolicy struct{}
+
+// Name returns the name of the policy.
+func (LabelPolicy) Name() string {
+	return model.SchedulingPolicyLabel
+}
+
+// Less prefers the cluster matching the most preferred labels.
+func (LabelPolicy) Less(a, b *model.ClusterPlacement) bool {
+	return a.MatchingPreferredLabels > b.MatchingPreferredLabels
+}
+
+// load returns the expected load of the most used resource of a cluster.
+func load(placement *model.ClusterPlacement) int {
+	if placement.CPUPercent > placement.MemoryPercent {
+		return placement.CPUPercent
+	}
+
+	return placement.MemoryPercent
+}
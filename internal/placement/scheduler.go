@@ -0,0 +1,262 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package placement decides which cluster an installation is placed on.
+package placement
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+)
+
+// Store abstracts the database operations required to place installations.
+type Store interface {
+	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+}
+
+// Provisioner abstracts the provisioning operations required to place
+// installations.
+type Provisioner interface {
+	GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error)
+}
+
+// Scheduler finds the clusters able to host an installation and orders them
+// following a scheduling policy.
+type Scheduler struct {
+	store               Store
+	provisioner         Provisioner
+	policy              Policy
+	threshold           int
+	thresholdScaleValue int
+}
+
+// NewScheduler creates a new Scheduler. Clusters whose CPU or memory usage
+// would exceed the threshold percentage are only picked when they can be
+// scaled up by the threshold scale value. Both values are defaults used for
+// clusters which don't set their own.
+func NewScheduler(store Store, provisioner Provisioner, policy Policy, threshold, thresholdScaleValue int) *Scheduler {
+	return &Scheduler{
+		store:               store,
+		provisioner:         provisioner,
+		policy:              policy,
+		threshold:           threshold,
+		thresholdScaleValue: thresholdScaleValue,
+	}
+}
+
+// PolicyName returns the name of the scheduling policy in use.
+func (s *Scheduler) PolicyName() string {
+	return s.policy.Name()
+}
+
+// Schedule evaluates every given cluster for the installation. The clusters
+// able to host it come first, in order of preference.
+func (s *Scheduler) Schedule(installation *model.Installation, clusters []*model.Cluster) []*model.ClusterPlacement {
+	var schedulable, unschedulable []*model.ClusterPlacement
+	for _, cluster := range clusters {
+		placement, err := s.Evaluate(cluster, installation)
+		if err != nil {
+			placement = &model.ClusterPlacement{
+				ClusterID: cluster.ID,
+				Reason:    err.Error(),
+			}
+		}
+
+		if placement.Schedulable {
+			schedulable = append(schedulable, placement)
+		} else {
+			unschedulable = append(unschedulable, placement)
+		}
+	}
+
+	sort.SliceStable(schedulable, func(i, j int) bool {
+		return s.policy.Less(schedulable[i], schedulable[j])
+	})
+
+	return append(schedulable, unschedulable...)
+}
+
+// Evaluate checks whether the installation can be placed on the given
+// cluster. An error is returned if the cluster could not be evaluated.
+func (s *Scheduler) Evaluate(cluster *model.Cluster, installation *model.Installation) (*model.ClusterPlacement, error) {
+	placement := &model.ClusterPlacement{ClusterID: cluster.ID}
+
+	if cluster.State != model.ClusterStateStable {
+		placement.Reason = fmt.Sprintf("cluster is not stable (currently %s)", cluster.State)
+		return placement, nil
+	}
+	if !cluster.AllowInstallations {
+		placement.Reason = "cluster is set to not allow for new installation scheduling"
+		return placement, nil
+	}
+
+	// Local clusters can't reach the AWS resources of installations keeping
+	// their data outside of the cluster.
+	if cluster.Provisioner == model.ProvisionerLocal && (!installation.InternalDatabase() || !installation.InternalFilestore()) {
+		placement.Reason = fmt.Sprintf("local clusters require the %s database and the %s filestore", model.InstallationDatabaseMysqlOperator, model.InstallationFilestoreMinioOperator)
+		return placement, nil
+	}
+
+	if installation.PlacementConstraints != nil {
+		reason := checkConstraints(cluster, installation.PlacementConstraints)
+		if reason != "" {
+			placement.Reason = reason
+			return placement, nil
+		}
+		placement.MatchingPreferredLabels = countMatchingLabels(cluster, installation.PlacementConstraints.PreferredLabels)
+	}
+
+	existingClusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:   model.AllPerPage,
+		ClusterID: cluster.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster installations")
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+	//                              MULTI-TENANCY                             //
+	////////////////////////////////////////////////////////////////////////////
+	// Current model:                                                         //
+	// - isolation=true  | 1 cluster installations                            //
+	// - isolation=false | X cluster installations, where "X" is as many as   //
+	//                     will fit with the given CPU and Memory threshold.  //
+	////////////////////////////////////////////////////////////////////////////
+	if installation.Affinity == model.InstallationAffinityIsolated {
+		if len(existingClusterInstallations) > 0 {
+			placement.Reason = fmt.Sprintf("cluster already has %d installations", len(existingClusterInstallations))
+			return placement, nil
+		}
+	} else {
+		if len(existingClusterInstallations) == 1 {
+			// This should be the only scenario where we need to check if the
+			// cluster installation running requires isolation or not.
+			existingInstallation, err := s.store.GetInstallation(existingClusterInstallations[0].InstallationID, true, false)
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to find installation")
+			}
+			if existingInstallation.Affinity == model.InstallationAffinityIsolated {
+				placement.Reason = fmt.Sprintf("cluster already has an isolated installation %s", existingInstallation.ID)
+				return placement, nil
+			}
+		}
+	}
+
+	// Begin final resource check.
+
+	size, err := mmv1alpha1.GetClusterSize(installation.Size)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cluster installation size")
+	}
+	clusterResources, err := s.provisioner.GetClusterResources(cluster, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster resources")
+	}
+
+	installationCPURequirement := size.CalculateCPUMilliRequirement(
+		installation.InternalDatabase(),
+		installation.InternalFilestore(),
+	)
+	installationMemRequirement := size.CalculateMemoryMilliRequirement(
+		installation.InternalDatabase(),
+		installation.InternalFilestore(),
+	)
+	placement.CPUPercent = clusterResources.CalculateCPUPercentUsed(installationCPURequirement)
+	placement.MemoryPercent = clusterResources.CalculateMemoryPercentUsed(installationMemRequirement)
+
+	threshold, _ := s.thresholds(cluster)
+	if placement.CPUPercent > threshold || placement.MemoryPercent > threshold {
+		if !s.canScaleUp(cluster) {
+			placement.Reason = fmt.Sprintf("cluster would exceed the cluster load threshold (%d%%): CPU=%d%% (+%dm), Memory=%d%% (+%dMi)",
+				threshold,
+				placement.CPUPercent, installationCPURequirement,
+				placement.MemoryPercent, installationMemRequirement/1048576000, // Have to convert to Mi
+			)
+			return placement, nil
+		}
+		placement.RequiresScaleUp = true
+	}
+
+	placement.Schedulable = true
+
+	return placement, nil
+}
+
+// ScaleUpNodeCount returns the worker node count the given cluster should be
+// scaled to when the placement of an installation requires a scale up.
+func (s *Scheduler) ScaleUpNodeCount(cluster *model.Cluster) int64 {
+	_, thresholdScaleValue := s.thresholds(cluster)
+	newWorkerCount := cluster.ProvisionerMetadataKops.NodeMinCount + int64(thresholdScaleValue)
+	if newWorkerCount > cluster.ProvisionerMetadataKops.NodeMaxCount {
+		newWorkerCount = cluster.ProvisionerMetadataKops.NodeMaxCount
+	}
+
+	return newWorkerCount
+}
+
+// thresholds returns the resource threshold and threshold scale value of the
+// given cluster, falling back to the scheduler defaults.
+func (s *Scheduler) thresholds(cluster *model.Cluster) (int, int) {
+	threshold := s.threshold
+	if cluster.ResourceThreshold != nil {
+		threshold = *cluster.ResourceThreshold
+	}
+	thresholdScaleValue := s.thresholdScaleValue
+	if cluster.ResourceThresholdScaleValue != nil {
+		thresholdScaleValue = *cluster.ResourceThresholdScaleValue
+	}
+
+	return threshold, thresholdScaleValue
+}
+
+func (s *Scheduler) canScaleUp(cluster *model.Cluster) bool {
+	_, thresholdScaleValue := s.thresholds(cluster)
+	if thresholdScaleValue == 0 || cluster.ProvisionerMetadataKops == nil {
+		return false
+	}
+
+	return cluster.ProvisionerMetadataKops.NodeMinCount != cluster.ProvisionerMetadataKops.NodeMaxCount
+}
+
+// checkConstraints returns the reason the cluster does not satisfy the
+// placement constraints, if any.
+func checkConstraints(cluster *model.Cluster, constraints *model.PlacementConstraints) string {
+	for _, clusterID := range constraints.AvoidClusterIDs {
+		if clusterID == cluster.ID {
+			return "cluster is avoided by the installation placement constraints"
+		}
+	}
+	for key, value := range constraints.RequiredLabels {
+		clusterValue, ok := cluster.Labels[key]
+		if !ok || clusterValue != value {
+			return fmt.Sprintf("cluster is missing required label %s=%s", key, value)
+		}
+	}
+	if len(constraints.NodeSelector) != 0 {
+		if cluster.ProvisionerMetadataKops == nil || !cluster.ProvisionerMetadataKops.MatchesNodeSelector(constraints.NodeSelector) {
+			return "cluster has no instance group matching the installation node selector"
+		}
+	}
+
+	return ""
+}
+
+func countMatchingLabels(cluster *model.Cluster, labels map[string]string) int {
+	var count int
+	for key, value := range labels {
+		clusterValue, ok := cluster.Labels[key]
+		if ok && clusterValue == value {
+			count++
+		}
+	}
+
+	return count
+}
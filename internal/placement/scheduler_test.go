@@ -0,0 +1,272 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package placement_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/placement"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProvisioner struct {
+	usedCPU map[string]int64
+}
+
+func (p *mockProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
+	return &k8s.ClusterResources{
+		MilliTotalCPU:    1000000,
+		MilliUsedCPU:     p.usedCPU[cluster.ID],
+		MilliTotalMemory: 100000000000000000,
+		MilliUsedMemory:  100,
+	}, nil
+}
+
+func TestScheduler(t *testing.T) {
+	setup := func(t *testing.T) (*store.SQLStore, *mockProvisioner, []*model.Cluster) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockProvisioner{usedCPU: make(map[string]int64)}
+
+		var clusters []*model.Cluster
+		for i, labels := range []map[string]string{
+			{"zone": "us-east-1a"},
+			{"zone": "us-east-1b"},
+			{"zone": "us-east-1b", "tier": "enterprise"},
+		} {
+			cluster := &model.Cluster{
+				State:                   model.ClusterStateStable,
+				AllowInstallations:      true,
+				Labels:                  labels,
+				ProvisionerMetadataKops: &model.KopsMetadata{NodeMinCount: 2, NodeMaxCount: 4},
+			}
+			err := sqlStore.CreateCluster(cluster)
+			require.NoError(t, err)
+			clusters = append(clusters, cluster)
+
+			provisioner.usedCPU[cluster.ID] = []int64{500000, 100000, 300000}[i]
+		}
+
+		return sqlStore, provisioner, clusters
+	}
+
+	newInstallation := func(constraints *model.PlacementConstraints) *model.Installation {
+		return &model.Installation{
+			Size:                 mmv1alpha1.Size100String,
+			Affinity:             model.InstallationAffinityMultiTenant,
+			Database:             model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore:            model.InstallationFilestoreMultiTenantAwsS3,
+			PlacementConstraints: constraints,
+		}
+	}
+
+	expectOrder := func(t *testing.T, placements []*model.ClusterPlacement, clusters ...*model.Cluster) {
+		t.Helper()
+
+		var scheduled []string
+		for _, placement := range placements {
+			if placement.Schedulable {
+				scheduled = append(scheduled, placement.ClusterID)
+			}
+		}
+
+		var expected []string
+		for _, cluster := range clusters {
+			expected = append(expected, cluster.ID)
+		}
+
+		require.Equal(t, expected, scheduled)
+	}
+
+	t.Run("first fit", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(nil), clusters)
+		expectOrder(t, placements, clusters[0], clusters[1], clusters[2])
+	})
+
+	t.Run("bin pack", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.BinPackPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(nil), clusters)
+		expectOrder(t, placements, clusters[0], clusters[2], clusters[1])
+	})
+
+	t.Run("spread", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.SpreadPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(nil), clusters)
+		expectOrder(t, placements, clusters[1], clusters[2], clusters[0])
+	})
+
+	t.Run("spread, prefers clusters not requiring scale up", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		provisioner.usedCPU[clusters[1].ID] = 900000
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.SpreadPolicy{}, 80, 1)
+
+		placements := scheduler.Schedule(newInstallation(nil), clusters)
+		expectOrder(t, placements, clusters[2], clusters[0], clusters[1])
+		require.True(t, placements[2].RequiresScaleUp)
+	})
+
+	t.Run("label", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.LabelPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(&model.PlacementConstraints{
+			PreferredLabels: map[string]string{"zone": "us-east-1b", "tier": "enterprise"},
+		}), clusters)
+		expectOrder(t, placements, clusters[2], clusters[1], clusters[0])
+	})
+
+	t.Run("required labels and avoided clusters", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(&model.PlacementConstraints{
+			RequiredLabels:  map[string]string{"zone": "us-east-1b"},
+			AvoidClusterIDs: []string{clusters[1].ID},
+		}), clusters)
+		expectOrder(t, placements, clusters[2])
+		require.Len(t, placements, 3)
+		require.NotEmpty(t, placements[1].Reason)
+		require.NotEmpty(t, placements[2].Reason)
+	})
+
+	t.Run("node selector", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		clusters[2].ProvisionerMetadataKops.InstanceGroups = []*model.KopsInstanceGroup{
+			{Name: model.KopsInstanceGroupNodes},
+			{Name: "memory", NodeLabels: map[string]string{"pool": "memory"}},
+		}
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		placements := scheduler.Schedule(newInstallation(&model.PlacementConstraints{
+			NodeSelector: map[string]string{"pool": "memory"},
+		}), clusters)
+		expectOrder(t, placements, clusters[2])
+
+		placements = scheduler.Schedule(newInstallation(&model.PlacementConstraints{
+			NodeSelector: map[string]string{model.KopsInstanceGroupLabel: model.KopsInstanceGroupNodes},
+		}), clusters)
+		expectOrder(t, placements, clusters[0], clusters[1], clusters[2])
+	})
+
+	t.Run("over threshold", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		provisioner.usedCPU[clusters[0].ID] = 900000
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		clusterPlacement, err := scheduler.Evaluate(clusters[0], newInstallation(nil))
+		require.NoError(t, err)
+		require.False(t, clusterPlacement.Schedulable)
+		require.Contains(t, clusterPlacement.Reason, "threshold")
+	})
+
+	t.Run("over cluster threshold", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		threshold := 40
+		clusters[0].ResourceThreshold = &threshold
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		clusterPlacement, err := scheduler.Evaluate(clusters[0], newInstallation(nil))
+		require.NoError(t, err)
+		require.False(t, clusterPlacement.Schedulable)
+		require.Contains(t, clusterPlacement.Reason, "(40%)")
+
+		clusterPlacement, err = scheduler.Evaluate(clusters[2], newInstallation(nil))
+		require.NoError(t, err)
+		require.True(t, clusterPlacement.Schedulable)
+	})
+
+	t.Run("over threshold, cluster scale value", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		provisioner.usedCPU[clusters[0].ID] = 900000
+		scaleValue := 1
+		clusters[0].ResourceThresholdScaleValue = &scaleValue
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		clusterPlacement, err := scheduler.Evaluate(clusters[0], newInstallation(nil))
+		require.NoError(t, err)
+		require.True(t, clusterPlacement.Schedulable)
+		require.True(t, clusterPlacement.RequiresScaleUp)
+		require.Equal(t, int64(3), scheduler.ScaleUpNodeCount(clusters[0]))
+	})
+
+	t.Run("unstable cluster", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		clusters[0].State = model.ClusterStateUpgradeRequested
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		clusterPlacement, err := scheduler.Evaluate(clusters[0], newInstallation(nil))
+		require.NoError(t, err)
+		require.False(t, clusterPlacement.Schedulable)
+	})
+
+	t.Run("local cluster", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		clusters[0].Provisioner = model.ProvisionerLocal
+		clusters[0].ProvisionerMetadataKops = nil
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		clusterPlacement, err := scheduler.Evaluate(clusters[0], newInstallation(nil))
+		require.NoError(t, err)
+		require.False(t, clusterPlacement.Schedulable)
+
+		installation := newInstallation(nil)
+		installation.Database = model.InstallationDatabaseMysqlOperator
+		installation.Filestore = model.InstallationFilestoreMinioOperator
+		clusterPlacement, err = scheduler.Evaluate(clusters[0], installation)
+		require.NoError(t, err)
+		require.True(t, clusterPlacement.Schedulable)
+	})
+
+	t.Run("isolated installation", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		err := sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      clusters[0].ID,
+			InstallationID: model.NewID(),
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0)
+
+		installation := newInstallation(nil)
+		installation.Affinity = model.InstallationAffinityIsolated
+		placements := scheduler.Schedule(installation, clusters)
+		expectOrder(t, placements, clusters[1], clusters[2])
+	})
+
+	t.Run("scale up node count", func(t *testing.T) {
+		sqlStore, provisioner, clusters := setup(t)
+		scheduler := placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 5)
+
+		require.Equal(t, int64(4), scheduler.ScaleUpNodeCount(clusters[0]))
+	})
+}
+
+func TestNewPolicy(t *testing.T) {
+	for _, name := range []string{
+		model.SchedulingPolicyFirstFit,
+		model.SchedulingPolicyBinPack,
+		model.SchedulingPolicySpread,
+		model.SchedulingPolicyLabel,
+	} {
+		policy, err := placement.NewPolicy(name)
+		require.NoError(t, err)
+		require.Equal(t, name, policy.Name())
+	}
+
+	_, err := placement.NewPolicy("unknown")
+	require.Error(t, err)
+}
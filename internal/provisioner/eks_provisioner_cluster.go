@@ -0,0 +1,297 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+
+	sdkAWS "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// EKSProvisioner provisions clusters using EKS.
+//
+// Everything happening inside of the kubernetes cluster is shared with the
+// embedded KopsProvisioner, which looks up the kubeconfig of each cluster
+// according to its provisioner. This is what lets EKSProvisioner handle
+// cluster installations.
+type EKSProvisioner struct {
+	*KopsProvisioner
+	awsClient      aws.AWS
+	clusterRoleARN string
+	nodeRoleARN    string
+	logger         log.FieldLogger
+}
+
+// NewEKSProvisioner creates a new EKSProvisioner. The control plane and the
+// worker nodes of the clusters assume the given IAM roles.
+func NewEKSProvisioner(kopsProvisioner *KopsProvisioner, awsClient aws.AWS, clusterRoleARN, nodeRoleARN string, logger log.FieldLogger) *EKSProvisioner {
+	return &EKSProvisioner{
+		KopsProvisioner: kopsProvisioner,
+		awsClient:       awsClient,
+		clusterRoleARN:  clusterRoleARN,
+		nodeRoleARN:     nodeRoleARN,
+		logger:          logger.WithField("provisioner", "eks"),
+	}
+}
+
+// PrepareCluster ensures a cluster object is ready for provisioning.
+func (provisioner *EKSProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	eksMetadata := cluster.ProvisionerMetadataEKS
+
+	// Don't regenerate the name if already set.
+	if eksMetadata.Name != "" {
+		return false
+	}
+
+	// Generate the EKS name using the cluster id.
+	eksMetadata.Name = fmt.Sprintf("%s-eks", cluster.ID)
+	eksMetadata.ClusterRoleARN = provisioner.clusterRoleARN
+	eksMetadata.NodeRoleARN = provisioner.nodeRoleARN
+
+	return true
+}
+
+// CreateCluster creates the EKS control plane and managed node groups of a
+// cluster in one of the VPCs available to the provisioner.
+func (provisioner *EKSProvisioner) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	if eksMetadata.ChangeRequest == nil {
+		return errors.New("EKS metadata change request is missing")
+	}
+
+	logger.WithField("name", eksMetadata.Name).Info("Creating cluster")
+
+	clusterResources, err := awsClient.GetAndClaimVpcResources(cluster.ID, provisioner.owner, logger)
+	if err != nil {
+		return err
+	}
+	eksMetadata.VPC = clusterResources.VpcID
+
+	eksCluster, err := awsClient.EnsureEKSCluster(cluster, clusterResources, logger)
+	if err != nil {
+		releaseErr := awsClient.ReleaseVpc(cluster.ID, logger)
+		if releaseErr != nil {
+			logger.WithError(releaseErr).Error("Unable to release VPC")
+		}
+
+		return errors.Wrap(err, "unable to create EKS cluster")
+	}
+	updateEKSClusterMetadata(eksMetadata, eksCluster)
+
+	// Worker nodes only run in the private subnets, behind the load balancers.
+	for _, nodeGroup := range eksMetadata.ChangeRequest.NodeGroups {
+		_, err = awsClient.EnsureEKSNodeGroup(cluster, nodeGroup, clusterResources.PrivateSubnetIDs, logger)
+		if err != nil {
+			// The VPC is only released once the control plane no longer uses
+			// it; deleting the cluster releases it otherwise.
+			deleteErr := awsClient.EnsureEKSClusterDeleted(eksMetadata.Name, logger)
+			if deleteErr != nil {
+				logger.WithError(deleteErr).Error("Unable to delete EKS cluster; keeping VPC claimed")
+			} else {
+				releaseErr := awsClient.ReleaseVpc(cluster.ID, logger)
+				if releaseErr != nil {
+					logger.WithError(releaseErr).Error("Unable to release VPC")
+				}
+			}
+
+			return errors.Wrapf(err, "unable to create EKS node group %s", nodeGroup.Name)
+		}
+	}
+	eksMetadata.NodeGroups = eksMetadata.ChangeRequest.NodeGroups
+
+	logger.WithField("name", eksMetadata.Name).Info("Successfully deployed kubernetes")
+
+	logger.WithField("name", eksMetadata.Name).Info("Updating VolumeBindingMode in default storage class")
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeClient()
+
+	_, err = k8sClient.UpdateStorageClassVolumeBindingMode("gp2")
+	if err != nil {
+		return err
+	}
+	logger.WithField("name", eksMetadata.Name).Info("Successfully updated storage class")
+
+	return nil
+}
+
+// ProvisionCluster installs all the baseline kubernetes resources needed for
+// managing installations. This can be called on an already-provisioned cluster
+// to reprovision with the newest version of the resources.
+func (provisioner *EKSProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+
+	// The endpoint is only persisted once the cluster is stable, so it is
+	// looked up again in case a previous provisioning attempt failed.
+	eksCluster, err := awsClient.GetEKSCluster(eksMetadata.Name)
+	if err != nil {
+		return err
+	}
+	if eksCluster == nil {
+		return errors.Errorf("EKS cluster %s not found", eksMetadata.Name)
+	}
+	updateEKSClusterMetadata(eksMetadata, eksCluster)
+
+	kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeconfig()
+
+	nodeIAMRole, err := iamRoleName(eksMetadata.NodeRoleARN)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Provisioning cluster")
+
+	err = provisioner.provisionCluster(cluster, kubeconfigPath, nodeIAMRole, awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("name", eksMetadata.Name).Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// UpgradeCluster is not supported for EKS clusters.
+func (provisioner *EKSProvisioner) UpgradeCluster(cluster *model.Cluster) error {
+	return errors.New("upgrading EKS clusters is not supported")
+}
+
+// ResizeCluster is not supported for EKS clusters.
+func (provisioner *EKSProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	return errors.New("resizing EKS clusters is not supported")
+}
+
+// DeleteCluster deletes the cluster utilities, the node groups and the
+// control plane of an EKS cluster, and releases its VPC.
+func (provisioner *EKSProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+
+	logger.Info("Deleting cluster")
+
+	eksCluster, err := awsClient.GetEKSCluster(eksMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	if eksCluster == nil {
+		logger.Info("EKS cluster not found; proceeding assuming the cluster utilities were never created")
+	} else {
+		updateEKSClusterMetadata(eksMetadata, eksCluster)
+
+		kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+		if err != nil {
+			return err
+		}
+		defer closeKubeconfig()
+
+		ugh, err := newUtilityGroupHandle(kubeconfigPath, provisioner.KopsProvisioner, cluster, awsClient, logger)
+		if err != nil {
+			return errors.Wrap(err, "couldn't create new utility group handle while deleting the cluster")
+		}
+
+		err = ugh.DestroyUtilityGroup()
+		if err != nil {
+			return errors.Wrap(err, "failed to destroy all services in the utility group")
+		}
+	}
+
+	nodeIAMRole, err := iamRoleName(eksMetadata.NodeRoleARN)
+	if err != nil {
+		return err
+	}
+	err = awsClient.DetachPolicyFromRole(nodeIAMRole, aws.CustomNodePolicyName, logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to detach custom node policy")
+	}
+
+	err = awsClient.EnsureEKSClusterDeleted(eksMetadata.Name, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete EKS cluster")
+	}
+
+	err = awsClient.ReleaseVpc(cluster.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to release VPC")
+	}
+
+	logger.Info("Successfully deleted cluster")
+
+	return nil
+}
+
+// RefreshClusterMetadata updates the EKS metadata of a cluster with the
+// current values of the running cluster, adding a warning for each health
+// issue of its node groups.
+func (provisioner *EKSProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Refreshing EKS metadata")
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+
+	eksCluster, err := provisioner.awsClient.GetEKSCluster(eksMetadata.Name)
+	if err != nil {
+		return err
+	}
+	if eksCluster == nil {
+		return errors.Errorf("EKS cluster %s not found", eksMetadata.Name)
+	}
+	updateEKSClusterMetadata(eksMetadata, eksCluster)
+
+	eksNodeGroups, err := provisioner.awsClient.GetEKSNodeGroups(eksMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	eksMetadata.NodeGroups = nil
+	for _, eksNodeGroup := range eksNodeGroups {
+		nodeGroup := &model.EKSNodeGroup{
+			Name: sdkAWS.StringValue(eksNodeGroup.NodegroupName),
+		}
+		if len(eksNodeGroup.InstanceTypes) != 0 {
+			nodeGroup.InstanceType = sdkAWS.StringValue(eksNodeGroup.InstanceTypes[0])
+		}
+		if eksNodeGroup.ScalingConfig != nil {
+			nodeGroup.MinCount = sdkAWS.Int64Value(eksNodeGroup.ScalingConfig.MinSize)
+			nodeGroup.MaxCount = sdkAWS.Int64Value(eksNodeGroup.ScalingConfig.MaxSize)
+		}
+		eksMetadata.NodeGroups = append(eksMetadata.NodeGroups, nodeGroup)
+
+		for _, issue := range aws.EKSNodeGroupIssues(eksNodeGroup) {
+			eksMetadata.AddWarning(issue)
+			logger.WithField("eks-metadata-error", issue).Warn("Encountered an EKS node group issue")
+		}
+	}
+
+	return nil
+}
+
+// updateEKSClusterMetadata records the version and the connection details of
+// the given EKS cluster.
+func updateEKSClusterMetadata(eksMetadata *model.EKSMetadata, eksCluster *eks.Cluster) {
+	eksMetadata.Version = sdkAWS.StringValue(eksCluster.Version)
+	eksMetadata.Endpoint = sdkAWS.StringValue(eksCluster.Endpoint)
+	if eksCluster.CertificateAuthority != nil {
+		eksMetadata.CertificateAuthority = sdkAWS.StringValue(eksCluster.CertificateAuthority.Data)
+	}
+}
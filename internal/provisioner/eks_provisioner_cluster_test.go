@@ -0,0 +1,194 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"errors"
+	"testing"
+
+	sdkAWS "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/mattermost/mattermost-cloud/internal/mocks/aws-tools"
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+func newTestEKSCluster() *model.Cluster {
+	return &model.Cluster{
+		ID:          model.NewID(),
+		Provisioner: model.ProvisionerEKS,
+		ProvisionerMetadataEKS: &model.EKSMetadata{
+			ChangeRequest: &model.EKSMetadataRequestedState{
+				Version: "1.17",
+				NodeGroups: []*model.EKSNodeGroup{
+					{Name: model.EKSNodeGroupNodes, InstanceType: "m5.large", MinCount: 2, MaxCount: 2},
+				},
+			},
+		},
+	}
+}
+
+func TestEKSProvisionerPrepareCluster(t *testing.T) {
+	provisioner := NewEKSProvisioner(&KopsProvisioner{}, nil, "arn:cluster-role", "arn:node-role", log.New())
+	cluster := newTestEKSCluster()
+
+	require.True(t, provisioner.PrepareCluster(cluster))
+	assert.Equal(t, cluster.ID+"-eks", cluster.ProvisionerMetadataEKS.Name)
+	assert.Equal(t, "arn:cluster-role", cluster.ProvisionerMetadataEKS.ClusterRoleARN)
+	assert.Equal(t, "arn:node-role", cluster.ProvisionerMetadataEKS.NodeRoleARN)
+
+	require.False(t, provisioner.PrepareCluster(cluster))
+}
+
+func TestEKSProvisionerCreateClusterFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := log.New()
+	awsClient := mocks.NewMockAWS(ctrl)
+	provisioner := NewEKSProvisioner(&KopsProvisioner{owner: "owner"}, awsClient, "arn:cluster-role", "arn:node-role", logger)
+	cluster := newTestEKSCluster()
+	provisioner.PrepareCluster(cluster)
+
+	resources := aws.ClusterResources{VpcID: "vpc-1", PrivateSubnetIDs: []string{"subnet-1"}}
+	gomock.InOrder(
+		awsClient.EXPECT().
+			GetAndClaimVpcResources(cluster.ID, "owner", gomock.Any()).
+			Return(resources, nil),
+		awsClient.EXPECT().
+			EnsureEKSCluster(cluster, resources, gomock.Any()).
+			Return(nil, errors.New("quota exceeded")),
+		awsClient.EXPECT().
+			ReleaseVpc(cluster.ID, gomock.Any()).
+			Return(nil),
+	)
+
+	err := provisioner.CreateCluster(cluster, awsClient)
+	require.Error(t, err)
+	assert.Equal(t, "vpc-1", cluster.ProvisionerMetadataEKS.VPC)
+}
+
+func TestEKSProvisionerCreateClusterNodeGroupFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := log.New()
+	awsClient := mocks.NewMockAWS(ctrl)
+	provisioner := NewEKSProvisioner(&KopsProvisioner{owner: "owner"}, awsClient, "arn:cluster-role", "arn:node-role", logger)
+	cluster := newTestEKSCluster()
+	provisioner.PrepareCluster(cluster)
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	nodeGroup := eksMetadata.ChangeRequest.NodeGroups[0]
+
+	resources := aws.ClusterResources{VpcID: "vpc-1", PrivateSubnetIDs: []string{"subnet-1"}}
+
+	t.Run("cluster deleted, VPC released", func(t *testing.T) {
+		gomock.InOrder(
+			awsClient.EXPECT().
+				GetAndClaimVpcResources(cluster.ID, "owner", gomock.Any()).
+				Return(resources, nil),
+			awsClient.EXPECT().
+				EnsureEKSCluster(cluster, resources, gomock.Any()).
+				Return(&eks.Cluster{}, nil),
+			awsClient.EXPECT().
+				EnsureEKSNodeGroup(cluster, nodeGroup, resources.PrivateSubnetIDs, gomock.Any()).
+				Return(nil, errors.New("instance type unavailable")),
+			awsClient.EXPECT().
+				EnsureEKSClusterDeleted(eksMetadata.Name, gomock.Any()).
+				Return(nil),
+			awsClient.EXPECT().
+				ReleaseVpc(cluster.ID, gomock.Any()).
+				Return(nil),
+		)
+
+		err := provisioner.CreateCluster(cluster, awsClient)
+		require.Error(t, err)
+		assert.Nil(t, eksMetadata.NodeGroups)
+	})
+
+	t.Run("cluster not deleted, VPC kept", func(t *testing.T) {
+		gomock.InOrder(
+			awsClient.EXPECT().
+				GetAndClaimVpcResources(cluster.ID, "owner", gomock.Any()).
+				Return(resources, nil),
+			awsClient.EXPECT().
+				EnsureEKSCluster(cluster, resources, gomock.Any()).
+				Return(&eks.Cluster{}, nil),
+			awsClient.EXPECT().
+				EnsureEKSNodeGroup(cluster, nodeGroup, resources.PrivateSubnetIDs, gomock.Any()).
+				Return(nil, errors.New("instance type unavailable")),
+			awsClient.EXPECT().
+				EnsureEKSClusterDeleted(eksMetadata.Name, gomock.Any()).
+				Return(errors.New("throttled")),
+		)
+
+		err := provisioner.CreateCluster(cluster, awsClient)
+		require.Error(t, err)
+	})
+}
+
+func TestEKSProvisionerRefreshClusterMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := log.New()
+	awsClient := mocks.NewMockAWS(ctrl)
+	provisioner := NewEKSProvisioner(&KopsProvisioner{}, awsClient, "arn:cluster-role", "arn:node-role", logger)
+	cluster := newTestEKSCluster()
+	provisioner.PrepareCluster(cluster)
+	eksMetadata := cluster.ProvisionerMetadataEKS
+
+	t.Run("cluster not found", func(t *testing.T) {
+		awsClient.EXPECT().GetEKSCluster(eksMetadata.Name).Return(nil, nil)
+
+		err := provisioner.RefreshClusterMetadata(cluster)
+		require.Error(t, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		awsClient.EXPECT().
+			GetEKSCluster(eksMetadata.Name).
+			Return(&eks.Cluster{
+				Version:              sdkAWS.String("1.17"),
+				Endpoint:             sdkAWS.String("https://cluster.eks.amazonaws.com"),
+				CertificateAuthority: &eks.Certificate{Data: sdkAWS.String("Y2VydGlmaWNhdGU=")},
+			}, nil)
+		awsClient.EXPECT().
+			GetEKSNodeGroups(eksMetadata.Name).
+			Return([]*eks.Nodegroup{
+				{
+					NodegroupName: sdkAWS.String(model.EKSNodeGroupNodes),
+					InstanceTypes: sdkAWS.StringSlice([]string{"m5.large"}),
+					ScalingConfig: &eks.NodegroupScalingConfig{MinSize: sdkAWS.Int64(2), MaxSize: sdkAWS.Int64(4)},
+					Health: &eks.NodegroupHealth{
+						Issues: []*eks.Issue{{Code: sdkAWS.String("AccessDenied"), Message: sdkAWS.String("denied")}},
+					},
+				},
+			}, nil)
+
+		err := provisioner.RefreshClusterMetadata(cluster)
+		require.NoError(t, err)
+		assert.Equal(t, "1.17", eksMetadata.Version)
+		assert.Equal(t, "https://cluster.eks.amazonaws.com", eksMetadata.Endpoint)
+		assert.Equal(t, "Y2VydGlmaWNhdGU=", eksMetadata.CertificateAuthority)
+		assert.Equal(t, []*model.EKSNodeGroup{
+			{Name: model.EKSNodeGroupNodes, InstanceType: "m5.large", MinCount: 2, MaxCount: 4},
+		}, eksMetadata.NodeGroups)
+		assert.Equal(t, []string{"EKS node group nodes has issue AccessDenied: denied"}, eksMetadata.Warnings)
+	})
+}
+
+func TestEKSProvisionerUnsupportedOperations(t *testing.T) {
+	provisioner := NewEKSProvisioner(&KopsProvisioner{}, nil, "arn:cluster-role", "arn:node-role", log.New())
+	cluster := newTestEKSCluster()
+
+	assert.Error(t, provisioner.UpgradeCluster(cluster))
+	assert.Error(t, provisioner.ResizeCluster(cluster))
+}
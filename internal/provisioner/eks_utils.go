@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// eksKubeconfigTemplate is the kubeconfig of an EKS cluster. Authentication
+// relies on the AWS CLI, which issues tokens for the provisioner credentials
+// in the region of the cluster.
+const eksKubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: %[2]s
+    certificate-authority-data: %[3]s
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+current-context: %[1]s
+users:
+- name: %[1]s
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1alpha1
+      command: aws
+      args:
+      - eks
+      - get-token
+      - --cluster-name
+      - %[1]s
+      - --region
+      - %[4]s
+`
+
+// writeEKSKubeconfig writes the kubeconfig of the given EKS cluster in the
+// given region to a temporary directory, and returns its path along with a
+// function removing it.
+func writeEKSKubeconfig(eksMetadata *model.EKSMetadata, region string) (string, func() error, error) {
+	if eksMetadata == nil || eksMetadata.Endpoint == "" || eksMetadata.CertificateAuthority == "" {
+		return "", nil, errors.New("EKS cluster endpoint is not known yet")
+	}
+
+	kubeconfig := fmt.Sprintf(eksKubeconfigTemplate, eksMetadata.Name, eksMetadata.Endpoint, eksMetadata.CertificateAuthority, region)
+
+	return writeKubeconfig([]byte(kubeconfig))
+}
+
+// eksRegion returns the region EKS clusters are created in, which is the one
+// the AWS client of the provisioner is configured with.
+func eksRegion() string {
+	awsRegion := os.Getenv("AWS_REGION")
+	if awsRegion == "" {
+		awsRegion = aws.DefaultAWSRegion
+	}
+
+	return awsRegion
+}
+
+// iamRoleName returns the name of the IAM role with the given ARN.
+func iamRoleName(roleARN string) (string, error) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse IAM role ARN %s", roleARN)
+	}
+	if !strings.HasPrefix(parsed.Resource, "role/") {
+		return "", errors.Errorf("%s is not an IAM role ARN", roleARN)
+	}
+
+	// Roles may have a path, which isn't part of their name.
+	return parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:], nil
+}
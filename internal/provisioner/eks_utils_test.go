@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEKSKubeconfig(t *testing.T) {
+	t.Run("unknown endpoint", func(t *testing.T) {
+		_, _, err := writeEKSKubeconfig(&model.EKSMetadata{Name: "cluster-eks"}, "us-east-2")
+		require.Error(t, err)
+
+		_, _, err = writeEKSKubeconfig(nil, "us-east-2")
+		require.Error(t, err)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		kubeconfigPath, closeKubeconfig, err := writeEKSKubeconfig(&model.EKSMetadata{
+			Name:                 "cluster-eks",
+			Endpoint:             "https://cluster.eks.amazonaws.com",
+			CertificateAuthority: "Y2VydGlmaWNhdGU=",
+		}, "us-east-2")
+		require.NoError(t, err)
+
+		kubeconfig, err := ioutil.ReadFile(kubeconfigPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(kubeconfig), "server: https://cluster.eks.amazonaws.com")
+		assert.Contains(t, string(kubeconfig), "certificate-authority-data: Y2VydGlmaWNhdGU=")
+		assert.Contains(t, string(kubeconfig), "current-context: cluster-eks")
+		assert.Contains(t, string(kubeconfig), "- --region\n      - us-east-2\n")
+
+		require.NoError(t, closeKubeconfig())
+		_, err = os.Stat(kubeconfigPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestIAMRoleName(t *testing.T) {
+	var testCases = []struct {
+		roleARN     string
+		expected    string
+		expectError bool
+	}{
+		{"arn:aws:iam::0123456789:role/eks-node", "eks-node", false},
+		{"arn:aws:iam::0123456789:role/cloud/eks-node", "eks-node", false},
+		{"arn:aws:iam::0123456789:user/eks-node", "", true},
+		{"eks-node", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.roleARN, func(t *testing.T) {
+			roleName, err := iamRoleName(tc.roleARN)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, roleName)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// ExternalProvisioner manages clusters imported with their kubeconfig. The
+// infrastructure of these clusters is never touched: only the cluster
+// utilities and the cluster installations are managed, through the embedded
+// KopsProvisioner.
+type ExternalProvisioner struct {
+	*KopsProvisioner
+	logger log.FieldLogger
+}
+
+// NewExternalProvisioner creates a new ExternalProvisioner.
+func NewExternalProvisioner(kopsProvisioner *KopsProvisioner, logger log.FieldLogger) *ExternalProvisioner {
+	return &ExternalProvisioner{
+		KopsProvisioner: kopsProvisioner,
+		logger:          logger.WithField("provisioner", "external"),
+	}
+}
+
+// PrepareCluster ensures a cluster object is ready for provisioning.
+func (provisioner *ExternalProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	if cluster.ProvisionerMetadataExternal == nil {
+		cluster.ProvisionerMetadataExternal = &model.ExternalMetadata{}
+	}
+
+	// Don't regenerate the name if already set.
+	if cluster.ProvisionerMetadataExternal.Name != "" {
+		return false
+	}
+
+	cluster.ProvisionerMetadataExternal.Name = fmt.Sprintf("%s-external", cluster.ID)
+
+	return true
+}
+
+// CreateCluster is not supported for external clusters.
+func (provisioner *ExternalProvisioner) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	return errors.New("creating external clusters is not supported")
+}
+
+// ProvisionCluster installs all the baseline kubernetes resources needed for
+// managing installations. This can be called on an already-provisioned cluster
+// to reprovision with the newest version of the resources.
+func (provisioner *ExternalProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeconfig()
+
+	logger.Info("Provisioning cluster")
+
+	err = provisioner.provisionCluster(cluster, kubeconfigPath, "", awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("name", cluster.ProvisionerMetadataExternal.Name).Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// UpgradeCluster is not supported for external clusters.
+func (provisioner *ExternalProvisioner) UpgradeCluster(cluster *model.Cluster) error {
+	return errors.New("upgrading external clusters is not supported")
+}
+
+// ResizeCluster is not supported for external clusters.
+func (provisioner *ExternalProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	return errors.New("resizing external clusters is not supported")
+}
+
+// DeleteCluster removes the cluster utilities from an external cluster. The
+// cluster itself keeps running.
+func (provisioner *ExternalProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Deleting cluster")
+
+	kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeconfig()
+
+	ugh, err := newUtilityGroupHandle(kubeconfigPath, provisioner.KopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "couldn't create new utility group handle while deleting the cluster")
+	}
+
+	err = ugh.DestroyUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to destroy all services in the utility group")
+	}
+
+	logger.Info("Successfully deleted cluster")
+
+	return nil
+}
+
+// RefreshClusterMetadata updates the external metadata of a cluster with the
+// kubernetes version it reports.
+func (provisioner *ExternalProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Refreshing external metadata")
+
+	version, err := provisioner.getKubernetesVersion(cluster, logger)
+	if err != nil {
+		return err
+	}
+	cluster.ProvisionerMetadataExternal.Version = version
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+func TestExternalProvisionerPrepareCluster(t *testing.T) {
+	provisioner := NewExternalProvisioner(&KopsProvisioner{}, log.New())
+
+	t.Run("generated name", func(t *testing.T) {
+		cluster := &model.Cluster{ID: model.NewID(), Provisioner: model.ProvisionerExternal}
+
+		require.True(t, provisioner.PrepareCluster(cluster))
+		assert.Equal(t, cluster.ID+"-external", cluster.ProvisionerMetadataExternal.Name)
+
+		require.False(t, provisioner.PrepareCluster(cluster))
+	})
+
+	t.Run("imported name", func(t *testing.T) {
+		cluster := &model.Cluster{
+			ID:                          model.NewID(),
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Name: "platform"},
+		}
+
+		require.False(t, provisioner.PrepareCluster(cluster))
+		assert.Equal(t, "platform", cluster.ProvisionerMetadataExternal.Name)
+	})
+}
+
+func TestExternalProvisionerUnsupportedOperations(t *testing.T) {
+	provisioner := NewExternalProvisioner(&KopsProvisioner{}, log.New())
+	cluster := &model.Cluster{ID: model.NewID(), Provisioner: model.ProvisionerExternal}
+
+	assert.Error(t, provisioner.CreateCluster(cluster, nil))
+	assert.Error(t, provisioner.UpgradeCluster(cluster))
+	assert.Error(t, provisioner.ResizeCluster(cluster))
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -18,13 +17,13 @@ import (
 type fluentbit struct {
 	provisioner    *KopsProvisioner
 	awsClient      aws.AWS
-	kops           *kops.Cmd
+	kubeconfigPath string
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newFluentbitHandle(version string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*fluentbit, error) {
+func newFluentbitHandle(version string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfigPath string, logger log.FieldLogger) (*fluentbit, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate Fluentbit handle with nil logger")
 	}
@@ -37,14 +36,14 @@ func newFluentbitHandle(version string, provisioner *KopsProvisioner, awsClient
 		return nil, errors.New("cannot create a connection to Fluentbit if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Fluentbit if the Kops command provided is nil")
+	if kubeconfigPath == "" {
+		return nil, errors.New("cannot create a connection to Fluentbit if the kubeconfig path provided is empty")
 	}
 
 	return &fluentbit{
 		provisioner:    provisioner,
 		awsClient:      awsClient,
-		kops:           kops,
+		kubeconfigPath: kubeconfigPath,
 		logger:         logger.WithField("cluster-utility", model.FluentbitCanonicalName),
 		desiredVersion: version,
 	}, nil
@@ -127,7 +126,7 @@ func (f *fluentbit) NewHelmDeployment(logger log.FieldLogger) *helmDeployment {
 `, elasticSearchDNS, auditLogsConf),
 		valuesPath:      "helm-charts/fluent-bit_values.yaml",
 		kopsProvisioner: f.provisioner,
-		kops:            f.kops,
+		kubeconfigPath:  f.kubeconfigPath,
 		logger:          f.logger,
 		desiredVersion:  f.desiredVersion,
 	}
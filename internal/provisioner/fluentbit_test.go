@@ -10,7 +10,6 @@ import (
 
 	mocks "github.com/mattermost/mattermost-cloud/internal/mocks/aws-tools"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -39,8 +38,7 @@ func TestNewHelmDeploymentWithAuditLogsConfiguration(t *testing.T) {
 		Return(expectedTag, nil).
 		AnyTimes()
 
-	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, "/tmp/kubeconfig", logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -70,8 +68,7 @@ func TestNewHelmDeploymentWithDefaultConfiguration(t *testing.T) {
 		Return(expectedTag, nil).
 		AnyTimes()
 
-	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, "/tmp/kubeconfig", logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -98,8 +95,7 @@ func TestNewHelmDeploymentWithZoneIDError(t *testing.T) {
 		Return("", err1).
 		AnyTimes()
 
-	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, "/tmp/kubeconfig", logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -130,8 +126,7 @@ func TestNewHelmDeploymentWithoutFindingAuditTag(t *testing.T) {
 		Return(expectedTag, err1).
 		AnyTimes()
 
-	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, "/tmp/kubeconfig", logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -161,8 +156,7 @@ func TestNewHelmDeploymentWithNillTag(t *testing.T) {
 		Return(nil, nil).
 		AnyTimes()
 
-	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, "/tmp/kubeconfig", logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -19,7 +19,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/helm"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 )
@@ -35,14 +34,14 @@ type helmDeployment struct {
 
 	cluster         *model.Cluster
 	kopsProvisioner *KopsProvisioner
-	kops            *kops.Cmd
+	kubeconfigPath  string
 	logger          log.FieldLogger
 }
 
-func installHelm(kops *kops.Cmd, repos map[string]string, logger log.FieldLogger) error {
+func installHelm(kubeconfigPath string, repos map[string]string, logger log.FieldLogger) error {
 	logger.Info("Installing Helm")
 
-	err := helmSetup(logger, kops)
+	err := helmSetup(logger, kubeconfigPath)
 	if err != nil {
 		return errors.Wrap(err, "unable to install helm")
 	}
@@ -51,7 +50,7 @@ func installHelm(kops *kops.Cmd, repos map[string]string, logger log.FieldLogger
 	logger.Infof("Waiting up to %d seconds for helm to become ready...", wait)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
 	defer cancel()
-	err = waitForHelmRunning(ctx, kops.GetKubeConfigPath())
+	err = waitForHelmRunning(ctx, kubeconfigPath)
 	if err != nil {
 		return errors.Wrap(err, "helm didn't start as expected, or we couldn't detect it")
 	}
@@ -63,7 +62,7 @@ func (d *helmDeployment) Update() error {
 	logger := d.logger.WithField("helm-update", d.chartName)
 
 	logger.Infof("Refreshing helm chart %s -- may trigger service upgrade", d.chartName)
-	err := upgradeHelmChart(*d, d.kops.GetKubeConfigPath(), logger)
+	err := upgradeHelmChart(*d, d.kubeconfigPath, logger)
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("got an error trying to upgrade the helm chart %s", d.chartName))
 	}
@@ -217,7 +216,7 @@ type HelmListOutput struct {
 func (d *helmDeployment) List() (*HelmListOutput, error) {
 	arguments := []string{
 		"list",
-		"--kubeconfig", d.kops.GetKubeConfigPath(),
+		"--kubeconfig", d.kubeconfigPath,
 		"--output", "json",
 	}
 
@@ -262,8 +261,8 @@ func (d *helmDeployment) Version() (string, error) {
 }
 
 // helmSetup is used for the initial setup of Helm in cluster.
-func helmSetup(logger log.FieldLogger, kops *kops.Cmd) error {
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+func helmSetup(logger log.FieldLogger, kubeconfigPath string) error {
+	k8sClient, err := k8s.NewFromFile(kubeconfigPath, logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to set up the k8s client")
 	}
@@ -298,7 +297,7 @@ func helmSetup(logger log.FieldLogger, kops *kops.Cmd) error {
 		}
 	}
 
-	err = helmInit(logger, kops)
+	err = helmInit(logger, kubeconfigPath)
 	if err != nil {
 		return err
 	}
@@ -307,7 +306,7 @@ func helmSetup(logger log.FieldLogger, kops *kops.Cmd) error {
 }
 
 // helmInit calls helm init and doesn't do anything fancy
-func helmInit(logger log.FieldLogger, kops *kops.Cmd) error {
+func helmInit(logger log.FieldLogger, kubeconfigPath string) error {
 	logger.Info("Upgrading Helm")
 	helmClient, err := helm.New(logger)
 	if err != nil {
@@ -315,7 +314,7 @@ func helmInit(logger log.FieldLogger, kops *kops.Cmd) error {
 	}
 	defer helmClient.Close()
 
-	err = helmClient.RunGenericCommand("--debug", "--kubeconfig", kops.GetKubeConfigPath(), "init", "--service-account", "tiller", "--upgrade")
+	err = helmClient.RunGenericCommand("--debug", "--kubeconfig", kubeconfigPath, "init", "--service-account", "tiller", "--upgrade")
 	if err != nil {
 		return errors.Wrap(err, "failed to upgrade helm")
 	}
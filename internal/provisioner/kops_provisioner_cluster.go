@@ -41,12 +41,13 @@ type KopsProvisioner struct {
 	resourceUtil            *utils.ResourceUtil
 	logger                  log.FieldLogger
 	store                   model.InstallationDatabaseStoreInterface
+	encryptionKey           []byte
 }
 
 // NewKopsProvisioner creates a new KopsProvisioner.
 // TODO(gsagula): Consider replacing all these paramaters with a struct for readability.
 func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources bool, allowCIDRRangeList []string,
-	resourceUtil *utils.ResourceUtil, logger log.FieldLogger, store model.InstallationDatabaseStoreInterface) *KopsProvisioner {
+	resourceUtil *utils.ResourceUtil, logger log.FieldLogger, store model.InstallationDatabaseStoreInterface, encryptionKey []byte) *KopsProvisioner {
 
 	logger = logger.WithField("provisioner", "kops")
 
@@ -58,6 +59,7 @@ func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources bool
 		resourceUtil:            resourceUtil,
 		owner:                   owner,
 		store:                   store,
+		encryptionKey:           encryptionKey,
 	}
 }
 
@@ -101,6 +103,7 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		if err != nil {
 			return err
 		}
+		kopsMetadata.VPC = clusterResources.VpcID
 	}
 
 	err = kops.CreateCluster(
@@ -122,6 +125,13 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		return errors.Wrap(err, "unable to create kops cluster")
 	}
 
+	// Spot instances can't be requested with kops create cluster flags, so
+	// the default instance group is edited before the cluster is applied.
+	err = updateKopsInstanceGroupSpot(kops, kopsMetadata, model.KopsInstanceGroupNodes, logger)
+	if err != nil {
+		return err
+	}
+
 	terraformClient, err := terraform.New(kops.GetOutputDirectory(), provisioner.s3StateStore, logger)
 	if err != nil {
 		return err
@@ -197,7 +207,7 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		return errors.Wrap(err, "unable to attach custom node policy")
 	}
 
-	ugh, err := newUtilityGroupHandle(kops, provisioner, cluster, awsClient, logger)
+	ugh, err := newUtilityGroupHandle(kops.GetKubeConfigPath(), provisioner, cluster, awsClient, logger)
 	if err != nil {
 		return err
 	}
@@ -222,12 +232,57 @@ func (provisioner *KopsProvisioner) ProvisionCluster(cluster *model.Cluster, aws
 		return errors.Wrap(err, "failed to export kubecfg")
 	}
 
-	kopsMetadata := cluster.ProvisionerMetadataKops
-
 	logger.Info("Provisioning cluster")
 
+	iamRole := fmt.Sprintf("nodes.%s", cluster.ProvisionerMetadataKops.Name)
+	err = provisioner.provisionCluster(cluster, kops.GetKubeConfigPath(), iamRole, awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("name", cluster.ProvisionerMetadataKops.Name).Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// provisionCluster installs the baseline kubernetes resources and the cluster
+// utilities through the given kubeconfig. It is shared by all provisioners, as
+// it only relies on kubernetes and on the IAM role used by the worker nodes,
+// if any.
+func (provisioner *KopsProvisioner) provisionCluster(cluster *model.Cluster, kubeconfigPath, nodeIAMRole string, awsClient aws.AWS, logger log.FieldLogger) error {
+	err := provisioner.deployOperators(kubeconfigPath, logger)
+	if err != nil {
+		return err
+	}
+
+	// The worker nodes of external clusters aren't managed by the provisioner.
+	if nodeIAMRole != "" {
+		err = awsClient.AttachPolicyToRole(nodeIAMRole, aws.CustomNodePolicyName, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to attach custom node policy")
+		}
+	}
+
+	ugh, err := newUtilityGroupHandle(kubeconfigPath, provisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new cluster utility group handle")
+	}
+
+	err = ugh.ProvisionUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade all services in utility group")
+	}
+
+	return nil
+}
+
+// deployOperators deploys the mysql, minio and mattermost operators through
+// the given kubeconfig, along with the calico network policies and the metrics
+// server they rely on. Unlike the cluster utilities, none of these depend on
+// AWS.
+func (provisioner *KopsProvisioner) deployOperators(kubeconfigPath string, logger log.FieldLogger) error {
 	// Begin deploying the mattermost operator.
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, err := k8s.NewFromFile(kubeconfigPath, logger)
 	if err != nil {
 		return err
 	}
@@ -414,24 +469,6 @@ func (provisioner *KopsProvisioner) ProvisionCluster(cluster *model.Cluster, aws
 		}
 	}
 
-	iamRole := fmt.Sprintf("nodes.%s", kopsMetadata.Name)
-	err = awsClient.AttachPolicyToRole(iamRole, aws.CustomNodePolicyName, logger)
-	if err != nil {
-		return errors.Wrap(err, "unable to attach custom node policy")
-	}
-
-	ugh, err := newUtilityGroupHandle(kops, provisioner, cluster, awsClient, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create new cluster utility group handle")
-	}
-
-	err = ugh.ProvisionUtilityGroup()
-	if err != nil {
-		return errors.Wrap(err, "failed to upgrade all services in utility group")
-	}
-
-	logger.WithField("name", cluster.ProvisionerMetadataKops.Name).Info("Successfully provisioned cluster")
-
 	return nil
 }
 
@@ -574,29 +611,92 @@ func (provisioner *KopsProvisioner) ResizeCluster(cluster *model.Cluster) error
 
 	logger.Info("Resizing cluster")
 
-	igManifest, err := kops.GetInstanceGroupYAML(kopsMetadata.Name, "nodes")
-	if err != nil {
-		return err
+	igName := kopsMetadata.ChangeRequest.InstanceGroup
+	if igName == "" {
+		igName = model.KopsInstanceGroupNodes
 	}
+	logger = logger.WithField("instance-group", igName)
 
-	igManifest, err = grossKopsReplaceSize(
-		igManifest,
-		kopsMetadata.ChangeRequest.NodeInstanceType,
-		fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMinCount),
-		fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMaxCount),
-	)
-	if err != nil {
-		return err
-	}
+	switch {
+	case kopsMetadata.ChangeRequest.DeleteInstanceGroup:
+		logger.Info("Deleting instance group")
+		err = kops.DeleteInstanceGroup(kopsMetadata.Name, igName)
+		if err != nil {
+			return err
+		}
+	case igName != model.KopsInstanceGroupNodes && kopsMetadata.GetInstanceGroup(igName) == nil:
+		logger.Info("Creating instance group")
 
-	igFilename := "ig-nodes.yaml"
-	err = ioutil.WriteFile(path.Join(kops.GetTempDir(), igFilename), []byte(igManifest), 0600)
-	if err != nil {
-		return err
-	}
-	_, err = kops.Replace(igFilename)
-	if err != nil {
-		return err
+		// New instance groups are based on the default one to share its
+		// image, subnets and security groups.
+		igManifest, err := kops.GetInstanceGroupYAML(kopsMetadata.Name, model.KopsInstanceGroupNodes)
+		if err != nil {
+			return err
+		}
+		igManifest, err = grossKopsNewInstanceGroup(igManifest, igName, kopsMetadata.ChangeRequest.NodeLabels)
+		if err != nil {
+			return err
+		}
+		igManifest, err = grossKopsReplaceSize(
+			igManifest,
+			kopsMetadata.ChangeRequest.NodeInstanceType,
+			fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMinCount),
+			fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMaxCount),
+		)
+		if err != nil {
+			return err
+		}
+
+		// Unlike the rest of the template, spot values are not inherited.
+		var spotMaxPrice string
+		if kopsMetadata.ChangeRequest.NodeSpotMaxPrice != nil {
+			spotMaxPrice = *kopsMetadata.ChangeRequest.NodeSpotMaxPrice
+		}
+		igManifest, err = grossKopsReplaceSpot(igManifest, spotMaxPrice, kopsMetadata.ChangeRequest.NodeMixedInstanceTypes)
+		if err != nil {
+			return err
+		}
+
+		igFilename := fmt.Sprintf("ig-%s.yaml", igName)
+		err = ioutil.WriteFile(path.Join(kops.GetTempDir(), igFilename), []byte(igManifest), 0600)
+		if err != nil {
+			return err
+		}
+		_, err = kops.Create(igFilename)
+		if err != nil {
+			return err
+		}
+	default:
+		igManifest, err := kops.GetInstanceGroupYAML(kopsMetadata.Name, igName)
+		if err != nil {
+			return err
+		}
+
+		igManifest, err = grossKopsReplaceSize(
+			igManifest,
+			kopsMetadata.ChangeRequest.NodeInstanceType,
+			fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMinCount),
+			fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMaxCount),
+		)
+		if err != nil {
+			return err
+		}
+		if kopsMetadata.ChangeRequest.NodeSpotMaxPrice != nil {
+			igManifest, err = grossKopsReplaceSpot(igManifest, *kopsMetadata.ChangeRequest.NodeSpotMaxPrice, kopsMetadata.ChangeRequest.NodeMixedInstanceTypes)
+			if err != nil {
+				return err
+			}
+		}
+
+		igFilename := fmt.Sprintf("ig-%s.yaml", igName)
+		err = ioutil.WriteFile(path.Join(kops.GetTempDir(), igFilename), []byte(igManifest), 0600)
+		if err != nil {
+			return err
+		}
+		_, err = kops.Replace(igFilename)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = kops.UpdateCluster(kopsMetadata.Name, kops.GetOutputDirectory())
@@ -653,7 +753,7 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 
 	logger.Info("Deleting cluster")
 
-	ugh, err := newUtilityGroupHandle(kops, provisioner, cluster, awsClient, logger)
+	ugh, err := newUtilityGroupHandle(kops.GetKubeConfigPath(), provisioner, cluster, awsClient, logger)
 	if err != nil {
 		return errors.Wrap(err, "couldn't greate new utility group handle while deleting the cluster")
 	}
@@ -728,21 +828,11 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to construct k8s client")
+		return nil, err
 	}
+	defer closeKubeClient()
 
 	ctx := context.TODO()
 	allPods, err := k8sClient.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
@@ -751,7 +841,7 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 	}
 	usedCPU, usedMemory := k8s.CalculateTotalPodMilliResourceRequests(allPods)
 
-	var totalCPU, totalMemory int64
+	var totalCPU, totalMemory, nodeCount int64
 	nodes, err := k8sClient.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -781,6 +871,7 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 		if !skipNode {
 			totalCPU += node.Status.Allocatable.Cpu().MilliValue()
 			totalMemory += node.Status.Allocatable.Memory().MilliValue()
+			nodeCount++
 		}
 	}
 
@@ -789,12 +880,13 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 		MilliUsedCPU:     usedCPU,
 		MilliTotalMemory: totalMemory,
 		MilliUsedMemory:  usedMemory,
+		NodeCount:        nodeCount,
 	}, nil
 }
 
-// RefreshKopsMetadata updates the kops metadata of a cluster with the current
-// values of the running cluster.
-func (provisioner *KopsProvisioner) RefreshKopsMetadata(cluster *model.Cluster) error {
+// RefreshClusterMetadata updates the kops metadata of a cluster with the
+// current values of the running cluster.
+func (provisioner *KopsProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
 	logger.Info("Refreshing kops metadata")
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
@@ -31,21 +30,11 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 	})
 	logger.Info("Creating cluster installation")
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
 		return err
 	}
+	defer closeKubeClient()
 
 	_, err = k8sClient.CreateOrUpdateNamespace(clusterInstallation.Namespace)
 	if err != nil {
@@ -84,6 +73,7 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 			Image:         installation.Image,
 			IngressName:   installation.DNS,
 			MattermostEnv: mattermostEnv.ToEnvList(),
+			NodeSelector:  installation.PlacementConstraints.GetNodeSelector(),
 			UseIngressTLS: false,
 			IngressAnnotations: map[string]string{
 				"kubernetes.io/ingress.class":                          "nginx-controller",
@@ -171,21 +161,11 @@ func (provisioner *KopsProvisioner) HibernateClusterInstallation(cluster *model.
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kubernetes client")
+		return err
 	}
+	defer closeKubeClient()
 
 	ctx := context.TODO()
 	name := makeClusterInstallationName(clusterInstallation)
@@ -219,26 +199,16 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	if cluster.ProvisionerMetadataKops.Name == "" {
+	if provisionedClusterName(cluster) == "" {
 		logger.Infof("Cluster %s has no name, assuming cluster installation never existed.", cluster.ID)
 		return nil
 	}
 
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
 		return err
 	}
+	defer closeKubeClient()
 
 	name := makeClusterInstallationName(clusterInstallation)
 
@@ -305,6 +275,7 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 	}
 	// Always ensure resources match
 	cr.Spec.Resources = sizeTemplate.App.Resources
+	cr.Spec.NodeSelector = installation.PlacementConstraints.GetNodeSelector()
 
 	cr.Spec.MattermostLicenseSecret = ""
 	secretName := fmt.Sprintf("%s-license", name)
@@ -350,26 +321,16 @@ func (provisioner *KopsProvisioner) DeleteClusterInstallation(cluster *model.Clu
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	if cluster.ProvisionerMetadataKops.Name == "" {
+	if provisionedClusterName(cluster) == "" {
 		logger.Infof("Cluster %s has no name, assuming cluster installation never existed.", cluster.ID)
 		return nil
 	}
 
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
 		return err
 	}
+	defer closeKubeClient()
 
 	name := makeClusterInstallationName(clusterInstallation)
 
@@ -411,26 +372,16 @@ func (provisioner *KopsProvisioner) GetClusterInstallationResource(cluster *mode
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	if cluster.ProvisionerMetadataKops.Name == "" {
+	if provisionedClusterName(cluster) == "" {
 		logger.Infof("Cluster %s has no name, assuming cluster installation never existed.", cluster.ID)
 		return nil, nil
 	}
 
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
 		return nil, err
 	}
+	defer closeKubeClient()
 
 	name := makeClusterInstallationName(clusterInstallation)
 
@@ -457,21 +408,11 @@ func (provisioner *KopsProvisioner) ExecClusterInstallationCLI(cluster *model.Cl
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to construct k8s client")
+		return nil, err
 	}
+	defer closeKubeClient()
 
 	ctx := context.TODO()
 	podList, err := k8sClient.Clientset.CoreV1().Pods(clusterInstallation.Namespace).List(ctx, metav1.ListOptions{
@@ -532,7 +473,9 @@ func getMattermostEnvWithOverrides(installation *model.Installation) model.EnvVa
 	mattermostEnv["MM_CLOUD_INSTALLATION_ID"] = model.EnvVar{Value: installation.ID}
 	mattermostEnv["MM_SERVICESETTINGS_ENABLELOCALMODE"] = model.EnvVar{Value: "true"}
 
-	if !installation.InternalFilestore() {
+	// S3-compatible stores don't all support server-side encryption, so it is
+	// only enabled for AWS S3 filestores.
+	if !installation.InternalFilestore() && installation.Filestore != model.InstallationFilestoreExternalS3 {
 		mattermostEnv["MM_FILESETTINGS_AMAZONS3SSE"] = model.EnvVar{Value: "true"}
 	}
 
@@ -540,5 +483,14 @@ func getMattermostEnvWithOverrides(installation *model.Installation) model.EnvVa
 		mattermostEnv["MM_FILESETTINGS_AMAZONS3PATHPREFIX"] = model.EnvVar{Value: installation.ID}
 	}
 
+	if installation.Filestore == model.InstallationFilestoreExternalS3 && installation.ExternalFilestoreConfig != nil {
+		if installation.ExternalFilestoreConfig.Prefix != "" {
+			mattermostEnv["MM_FILESETTINGS_AMAZONS3PATHPREFIX"] = model.EnvVar{Value: installation.ExternalFilestoreConfig.Prefix}
+		}
+		if !installation.ExternalFilestoreConfig.Insecure {
+			mattermostEnv["MM_FILESETTINGS_AMAZONS3SSL"] = model.EnvVar{Value: "true"}
+		}
+	}
+
 	return mattermostEnv
 }
@@ -0,0 +1,83 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// mmctlUser is the subset of the user fields printed by mmctl that is used to
+// determine the activity of an installation.
+type mmctlUser struct {
+	LastActivityAt int64 `json:"last_activity_at"`
+	UpdateAt       int64 `json:"update_at"`
+}
+
+// GetClusterInstallationLastActivity returns the most recent user activity
+// reported by the given cluster installation, in milliseconds since the epoch.
+// It relies on mmctl in local mode, which is available in the Mattermost
+// images.
+func (provisioner *KopsProvisioner) GetClusterInstallationLastActivity(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (int64, error) {
+	output, err := provisioner.ExecClusterInstallationCLI(cluster, clusterInstallation, "./bin/mmctl", "--local", "user", "list", "--all", "--json")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list installation users")
+	}
+
+	return parseMmctlUsersLastActivity(output)
+}
+
+// parseMmctlUsersLastActivity returns the latest activity of the users in the
+// given mmctl output. Depending on the version, mmctl prints the users either
+// as a single JSON array or as a stream of JSON objects.
+func parseMmctlUsersLastActivity(output []byte) (int64, error) {
+	var lastActivityAt int64
+	observe := func(user mmctlUser) {
+		if user.LastActivityAt > lastActivityAt {
+			lastActivityAt = user.LastActivityAt
+		}
+		if user.UpdateAt > lastActivityAt {
+			lastActivityAt = user.UpdateAt
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to decode mmctl output")
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			var users []mmctlUser
+			err = json.Unmarshal(raw, &users)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to decode mmctl user list")
+			}
+			for _, user := range users {
+				observe(user)
+			}
+			continue
+		}
+
+		var user mmctlUser
+		err = json.Unmarshal(raw, &user)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to decode mmctl user")
+		}
+		observe(user)
+	}
+
+	return lastActivityAt, nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMmctlUsersLastActivity(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		lastActivityAt, err := parseMmctlUsersLastActivity([]byte(""))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), lastActivityAt)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		lastActivityAt, err := parseMmctlUsersLastActivity([]byte(`[{"id":"a","last_activity_at":10,"update_at":5},{"id":"b","update_at":20}]`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), lastActivityAt)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		lastActivityAt, err := parseMmctlUsersLastActivity([]byte("{\"id\":\"a\",\"last_activity_at\":30}\n{\"id\":\"b\",\"update_at\":20}\n"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(30), lastActivityAt)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseMmctlUsersLastActivity([]byte("error: local mode is not enabled"))
+		require.Error(t, err)
+	})
+}
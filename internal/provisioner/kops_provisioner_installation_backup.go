@@ -0,0 +1,361 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// backupRestoreImage is the image used by the jobs that back up and
+	// restore installations. It is pinned so that backups are always taken
+	// and restored with the same, known version of the tool.
+	backupRestoreImage = "mattermost/backup-restore-tool:v0.4.0"
+	// backupRestoreJobBackoffLimit is the number of times a backup or restore
+	// job is retried before it is considered failed.
+	backupRestoreJobBackoffLimit = 2
+)
+
+// TriggerInstallationBackup starts a job backing up the database and the
+// filestore contents of the given cluster installation to the filestore of the
+// installation. Installations using a single tenant RDS database additionally
+// get an RDS snapshot of their database.
+func (provisioner *KopsProvisioner) TriggerInstallationBackup(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":            clusterInstallation.ClusterID,
+		"installation":       installation.ID,
+		"installationBackup": backup.ID,
+	})
+	logger.Info("Triggering installation backup")
+
+	switch installation.Database {
+	case model.InstallationDatabaseSingleTenantRDSMySQL, model.InstallationDatabaseSingleTenantRDSPostgres:
+		err := provisioner.resourceUtil.GetDatabase(installation).Snapshot(provisioner.store, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to snapshot installation database")
+		}
+	}
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeClient()
+
+	env, err := provisioner.backupJobEnv(k8sClient, installation, clusterInstallation, "BRT_STORAGE", "", logger)
+	if err != nil {
+		return err
+	}
+	databaseEnv, err := provisioner.backupJobDatabaseEnv(k8sClient, installation, clusterInstallation, logger)
+	if err != nil {
+		return err
+	}
+	env = append(env, databaseEnv...)
+
+	job := makeBackupRestoreJob(backupJobName(backup), clusterInstallation, backup, "backup", env)
+	err = createBackupRestoreJob(k8sClient, clusterInstallation.Namespace, job)
+	if err != nil {
+		return errors.Wrap(err, "failed to create backup job")
+	}
+
+	logger.Info("Installation backup job created")
+
+	return nil
+}
+
+// CheckInstallationBackupJob returns the state of the job backing up the given
+// cluster installation.
+func (provisioner *KopsProvisioner) CheckInstallationBackupJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":            clusterInstallation.ClusterID,
+		"installation":       clusterInstallation.InstallationID,
+		"installationBackup": backup.ID,
+	})
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return "", err
+	}
+	defer closeKubeClient()
+
+	return checkBackupRestoreJob(k8sClient, clusterInstallation.Namespace, backupJobName(backup), logger)
+}
+
+// TriggerInstallationRestore starts a job restoring the given backup of the
+// source installation to the target cluster installation.
+func (provisioner *KopsProvisioner) TriggerInstallationRestore(cluster *model.Cluster, sourceInstallation, targetInstallation *model.Installation, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":            targetClusterInstallation.ClusterID,
+		"installation":       targetInstallation.ID,
+		"installationBackup": backup.ID,
+	})
+	logger.Info("Triggering installation restore")
+
+	if sourceInstallation.ID != targetInstallation.ID && sourceInstallation.InternalFilestore() {
+		return errors.New("backups of installations with an in-cluster filestore can only be restored to the same installation")
+	}
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeClient()
+
+	env, err := provisioner.backupJobEnv(k8sClient, targetInstallation, targetClusterInstallation, "BRT_STORAGE", "", logger)
+	if err != nil {
+		return err
+	}
+	databaseEnv, err := provisioner.backupJobDatabaseEnv(k8sClient, targetInstallation, targetClusterInstallation, logger)
+	if err != nil {
+		return err
+	}
+	env = append(env, databaseEnv...)
+
+	// The backup is read from the filestore of the installation it was taken
+	// from, which is only different when restoring to another installation.
+	sourceSecretName := ""
+	if sourceInstallation.ID != targetInstallation.ID {
+		sourceSecretName = fmt.Sprintf("%s-backup-source", backup.ID)
+	}
+	sourceEnv, err := provisioner.backupJobEnv(k8sClient, sourceInstallation, targetClusterInstallation, "BRT_BACKUP_STORAGE", sourceSecretName, logger)
+	if err != nil {
+		return err
+	}
+	env = append(env, sourceEnv...)
+
+	job := makeBackupRestoreJob(restoreJobName(backup), targetClusterInstallation, backup, "restore", env)
+	err = createBackupRestoreJob(k8sClient, targetClusterInstallation.Namespace, job)
+	if err != nil {
+		return errors.Wrap(err, "failed to create restore job")
+	}
+
+	logger.Info("Installation restore job created")
+
+	return nil
+}
+
+// CheckInstallationRestoreJob returns the state of the job restoring the given
+// backup to the target cluster installation.
+func (provisioner *KopsProvisioner) CheckInstallationRestoreJob(cluster *model.Cluster, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":            targetClusterInstallation.ClusterID,
+		"installation":       targetClusterInstallation.InstallationID,
+		"installationBackup": backup.ID,
+	})
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return "", err
+	}
+	defer closeKubeClient()
+
+	return checkBackupRestoreJob(k8sClient, targetClusterInstallation.Namespace, restoreJobName(backup), logger)
+}
+
+// backupJobEnv returns the environment describing the filestore of the given
+// installation to a backup or restore job, prefixing each variable with the
+// given prefix. When secretName is set, the filestore credentials are copied
+// to a secret of that name in the namespace of the cluster installation.
+func (provisioner *KopsProvisioner) backupJobEnv(k8sClient *k8s.KubeClient, installation *model.Installation, clusterInstallation *model.ClusterInstallation, prefix, secretName string, logger log.FieldLogger) ([]corev1.EnvVar, error) {
+	filestoreSpec, filestoreSecret, err := provisioner.resourceUtil.GetFilestore(installation).GenerateFilestoreSpecAndSecret(provisioner.store, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate filestore configuration")
+	}
+
+	if filestoreSpec == nil {
+		// The filestore is managed by the Minio operator, which names the
+		// Minio instance and its credentials after the Mattermost installation.
+		installationName := makeClusterInstallationName(clusterInstallation)
+		filestoreSpec = &mmv1alpha1.Minio{
+			ExternalURL:    fmt.Sprintf("%s-minio.%s.svc.cluster.local:9000", installationName, clusterInstallation.Namespace),
+			ExternalBucket: installationName,
+			Secret:         fmt.Sprintf("%s-minio", installationName),
+		}
+	} else {
+		if secretName != "" {
+			filestoreSecret.Name = secretName
+		}
+		_, err = k8sClient.CreateOrUpdateSecret(clusterInstallation.Namespace, filestoreSecret)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the filestore secret %s/%s", clusterInstallation.Namespace, filestoreSecret.Name)
+		}
+		filestoreSpec.Secret = filestoreSecret.Name
+	}
+
+	storagePrefix := ""
+	switch installation.Filestore {
+	case model.InstallationFilestoreMultiTenantAwsS3:
+		storagePrefix = installation.ID
+	case model.InstallationFilestoreExternalS3:
+		storagePrefix = installation.ExternalFilestoreConfig.Prefix
+	}
+
+	return []corev1.EnvVar{
+		{Name: prefix + "_ENDPOINT", Value: filestoreSpec.ExternalURL},
+		{Name: prefix + "_BUCKET", Value: filestoreSpec.ExternalBucket},
+		{Name: prefix + "_PREFIX", Value: storagePrefix},
+		secretEnvVar(prefix+"_ACCESS_KEY", filestoreSpec.Secret, "accesskey"),
+		secretEnvVar(prefix+"_SECRET_KEY", filestoreSpec.Secret, "secretkey"),
+	}, nil
+}
+
+// backupJobDatabaseEnv returns the environment describing the database of the
+// given installation to a backup or restore job.
+func (provisioner *KopsProvisioner) backupJobDatabaseEnv(k8sClient *k8s.KubeClient, installation *model.Installation, clusterInstallation *model.ClusterInstallation, logger log.FieldLogger) ([]corev1.EnvVar, error) {
+	databaseSpec, databaseSecret, err := provisioner.resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate database configuration")
+	}
+
+	if databaseSpec == nil {
+		// The database is managed by the MySQL operator; the job resolves the
+		// connection details from the Mattermost installation.
+		return []corev1.EnvVar{
+			{Name: "BRT_DATABASE_TYPE", Value: model.DatabaseEngineTypeMySQL},
+			{Name: "BRT_MATTERMOST_INSTALLATION", Value: makeClusterInstallationName(clusterInstallation)},
+		}, nil
+	}
+
+	_, err = k8sClient.CreateOrUpdateSecret(clusterInstallation.Namespace, databaseSecret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the database secret %s/%s", clusterInstallation.Namespace, databaseSecret.Name)
+	}
+
+	databaseType := model.DatabaseEngineTypeMySQL
+	switch installation.Database {
+	case model.InstallationDatabaseSingleTenantRDSPostgres, model.InstallationDatabaseMultiTenantRDSPostgres:
+		databaseType = model.DatabaseEngineTypePostgres
+	case model.InstallationDatabaseExternal:
+		databaseType, err = model.ExternalDatabaseEngineType(databaseSecret.StringData["DB_CONNECTION_STRING"])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine external database type")
+		}
+	}
+
+	return []corev1.EnvVar{
+		{Name: "BRT_DATABASE_TYPE", Value: databaseType},
+		secretEnvVar("BRT_DATABASE", databaseSecret.Name, "DB_CONNECTION_STRING"),
+	}, nil
+}
+
+func makeBackupRestoreJob(name string, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup, action string, env []corev1.EnvVar) *batchv1.Job {
+	backoffLimit := int32(backupRestoreJobBackoffLimit)
+	labels := map[string]string{
+		"app":                  "backup-restore",
+		"installation-backup":  backup.ID,
+		"cluster-installation": clusterInstallation.ID,
+	}
+
+	env = append(env,
+		corev1.EnvVar{Name: "BRT_ACTION", Value: action},
+		corev1.EnvVar{Name: "BRT_BACKUP_KEY", Value: backup.StorageKey()},
+	)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: clusterInstallation.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:  "backup-restore",
+							Image: backupRestoreImage,
+							Args:  []string{action},
+							Env:   env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createBackupRestoreJob(k8sClient *k8s.KubeClient, namespace string, job *batchv1.Job) error {
+	ctx := context.TODO()
+	_, err := k8sClient.Clientset.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+	if k8sErrors.IsAlreadyExists(err) {
+		return nil
+	}
+
+	return err
+}
+
+// checkBackupRestoreJob returns the state of the given backup or restore job,
+// deleting the job once it has finished.
+func checkBackupRestoreJob(k8sClient *k8s.KubeClient, namespace, name string, logger log.FieldLogger) (string, error) {
+	ctx := context.TODO()
+	jobs := k8sClient.Clientset.BatchV1().Jobs(namespace)
+	job, err := jobs.Get(ctx, name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		logger.Warnf("Job %s/%s no longer exists", namespace, name)
+		return model.BackupJobStateFailed, nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get job %s/%s", namespace, name)
+	}
+
+	state := model.BackupJobStateRunning
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			state = model.BackupJobStateSucceeded
+		case batchv1.JobFailed:
+			state = model.BackupJobStateFailed
+		}
+	}
+
+	if state != model.BackupJobStateRunning {
+		propagation := metav1.DeletePropagationBackground
+		err = jobs.Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			logger.WithError(err).Warnf("Failed to clean up job %s/%s", namespace, name)
+		}
+	}
+
+	return state, nil
+}
+
+func secretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+func backupJobName(backup *model.InstallationBackup) string {
+	return fmt.Sprintf("backup-%s", backup.ID)
+}
+
+func restoreJobName(backup *model.InstallationBackup) string {
+	return fmt.Sprintf("restore-%s", backup.ID)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// databaseMigrationMySQLImage is the image of the job copying MySQL
+	// installation databases between multitenant databases.
+	databaseMigrationMySQLImage = "mysql:5.7"
+	// databaseMigrationPostgresImage is the image of the job copying Postgres
+	// installation databases between multitenant databases.
+	databaseMigrationPostgresImage = "postgres:11"
+
+	databaseMigrationMySQLScript = `set -euo pipefail
+mysqldump --single-transaction --set-gtid-purged=OFF --ssl-mode=REQUIRED -h "$SOURCE_HOST" -u "$DATABASE_USERNAME" "$DATABASE_NAME" |
+  mysql --ssl-mode=REQUIRED -h "$TARGET_HOST" -u "$DATABASE_USERNAME" "$DATABASE_NAME"`
+	databaseMigrationPostgresScript = `set -euo pipefail
+pg_dump --clean --if-exists --no-owner --no-acl -h "$SOURCE_HOST" -U "$DATABASE_USERNAME" "$DATABASE_NAME" |
+  psql -v ON_ERROR_STOP=1 -h "$TARGET_HOST" -U "$DATABASE_USERNAME" "$DATABASE_NAME"`
+)
+
+// TriggerInstallationDatabaseMigration starts a job copying the database of
+// the installation to its migration target database. Triggering a job that
+// already exists is a no-op.
+func (provisioner *KopsProvisioner) TriggerInstallationDatabaseMigration(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":        clusterInstallation.ClusterID,
+		"installation":   installation.ID,
+		"targetDatabase": installation.MigrationTargetDatabaseID,
+	})
+
+	databaseMigration := provisioner.resourceUtil.GetInstallationDatabaseMigration(installation)
+	if databaseMigration == nil {
+		return errors.Errorf("database type %s can't be migrated", installation.Database)
+	}
+
+	migrationSecret, err := databaseMigration.GenerateMigrationSecret(provisioner.store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database migration configuration")
+	}
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeClient()
+
+	_, err = k8sClient.CreateOrUpdateSecret(clusterInstallation.Namespace, migrationSecret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the database migration secret %s/%s", clusterInstallation.Namespace, migrationSecret.Name)
+	}
+
+	job := makeDatabaseMigrationJob(installation, clusterInstallation, migrationSecret.Name)
+	err = createBackupRestoreJob(k8sClient, clusterInstallation.Namespace, job)
+	if err != nil {
+		return errors.Wrap(err, "failed to create database migration job")
+	}
+
+	logger.Debug("Installation database migration job triggered")
+
+	return nil
+}
+
+// CheckInstallationDatabaseMigrationJob returns the state of the job copying
+// the database of the installation, cleaning up once it has finished.
+func (provisioner *KopsProvisioner) CheckInstallationDatabaseMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (string, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":        clusterInstallation.ClusterID,
+		"installation":   installation.ID,
+		"targetDatabase": installation.MigrationTargetDatabaseID,
+	})
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return "", err
+	}
+	defer closeKubeClient()
+
+	state, err := checkBackupRestoreJob(k8sClient, clusterInstallation.Namespace, databaseMigrationJobName(installation), logger)
+	if err != nil {
+		return "", err
+	}
+
+	if state != model.BackupJobStateRunning {
+		secretName := databaseMigrationSecretName(installation)
+		err = k8sClient.Clientset.CoreV1().Secrets(clusterInstallation.Namespace).Delete(context.TODO(), secretName, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			logger.WithError(err).Warnf("Failed to clean up secret %s/%s", clusterInstallation.Namespace, secretName)
+		}
+	}
+
+	return state, nil
+}
+
+// RefreshClusterInstallationDatabase updates the database secret of the
+// cluster installation to match the database the installation is assigned to.
+func (provisioner *KopsProvisioner) RefreshClusterInstallationDatabase(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": installation.ID,
+	})
+
+	_, databaseSecret, err := provisioner.resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database configuration")
+	}
+	if databaseSecret == nil {
+		return nil
+	}
+
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeClient()
+
+	_, err = k8sClient.CreateOrUpdateSecret(clusterInstallation.Namespace, databaseSecret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the database secret %s/%s", clusterInstallation.Namespace, databaseSecret.Name)
+	}
+
+	logger.Info("Cluster installation database secret refreshed")
+
+	return nil
+}
+
+func makeDatabaseMigrationJob(installation *model.Installation, clusterInstallation *model.ClusterInstallation, secretName string) *batchv1.Job {
+	backoffLimit := int32(backupRestoreJobBackoffLimit)
+	labels := map[string]string{
+		"app":                  "database-migration",
+		"installation":         installation.ID,
+		"cluster-installation": clusterInstallation.ID,
+	}
+
+	image := databaseMigrationMySQLImage
+	script := databaseMigrationMySQLScript
+	passwordEnv := "MYSQL_PWD"
+	if installation.Database == model.InstallationDatabaseMultiTenantRDSPostgres {
+		image = databaseMigrationPostgresImage
+		script = databaseMigrationPostgresScript
+		passwordEnv = "PGPASSWORD"
+	}
+
+	env := []corev1.EnvVar{
+		secretEnvVar("SOURCE_HOST", secretName, "SOURCE_HOST"),
+		secretEnvVar("TARGET_HOST", secretName, "TARGET_HOST"),
+		secretEnvVar("DATABASE_NAME", secretName, "DATABASE_NAME"),
+		secretEnvVar("DATABASE_USERNAME", secretName, "DATABASE_USERNAME"),
+		secretEnvVar(passwordEnv, secretName, "DATABASE_PASSWORD"),
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      databaseMigrationJobName(installation),
+			Namespace: clusterInstallation.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "database-migration",
+							Image:   image,
+							Command: []string{"bash", "-c", script},
+							Env:     env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func databaseMigrationJobName(installation *model.Installation) string {
+	return fmt.Sprintf("database-migration-%s", installation.ID)
+}
+
+func databaseMigrationSecretName(installation *model.Installation) string {
+	return aws.RDSMultitenantMigrationSecretName(installation.ID)
+}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -128,21 +129,11 @@ func (provisioner *KopsProvisioner) GetPublicLoadBalancerEndpoint(cluster *model
 		"cluster":         cluster.ID,
 		"nginx-namespace": namespace,
 	})
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
 	if err != nil {
 		return "", err
 	}
+	defer closeKubeClient()
 
 	ctx := context.TODO()
 	services, err := k8sClient.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
@@ -212,6 +203,38 @@ func updateKopsInstanceGroupAMIs(kops *kops.Cmd, kopsMetadata *model.KopsMetadat
 	return nil
 }
 
+// updateKopsInstanceGroupSpot updates the given instance group with the spot
+// instance values of the kops metadata change request, if any.
+func updateKopsInstanceGroupSpot(kops *kops.Cmd, kopsMetadata *model.KopsMetadata, igName string, logger log.FieldLogger) error {
+	if kopsMetadata.ChangeRequest.NodeSpotMaxPrice == nil {
+		logger.Info("Skipping instance group spot update")
+		return nil
+	}
+
+	logger.Infof("Updating instance group '%s' spot max price to '%s'", igName, *kopsMetadata.ChangeRequest.NodeSpotMaxPrice)
+
+	igManifest, err := kops.GetInstanceGroupYAML(kopsMetadata.Name, igName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get YAML output for instance group")
+	}
+	igManifest, err = grossKopsReplaceSpot(igManifest, *kopsMetadata.ChangeRequest.NodeSpotMaxPrice, kopsMetadata.ChangeRequest.NodeMixedInstanceTypes)
+	if err != nil {
+		return errors.Wrap(err, "failed to replace spot values in YAML")
+	}
+
+	igFilename := fmt.Sprintf("%s-ig.yaml", igName)
+	err = ioutil.WriteFile(path.Join(kops.GetTempDir(), igFilename), []byte(igManifest), 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write new YAML file")
+	}
+	_, err = kops.Replace(igFilename)
+	if err != nil {
+		return errors.Wrap(err, "failed to update instance group")
+	}
+
+	return nil
+}
+
 // grossKopsReplaceSize is a manual find-and-replace flow for updating a raw
 // kops instance group YAML manifest with new sizing values.
 // TODO: remove once new `kops set instancegroup` functionality is available.
@@ -275,3 +298,92 @@ func grossKopsReplaceImage(input, image string) (string, error) {
 
 	return input, nil
 }
+
+// grossKopsReplaceSpot is a manual find-and-replace flow for updating a raw
+// kops instance group YAML manifest with new spot instance values. The spot
+// values are removed when the max price is empty.
+// TODO: remove once new `kops set instancegroup` functionality is available.
+//
+// Example Manifest:
+//
+// apiVersion: kops.k8s.io/v1alpha2
+// kind: InstanceGroup
+// spec:
+//   maxPrice: "0.05"
+//   mixedInstancesPolicy:
+//     instances:
+//     - m5.large
+//     - m5a.large
+//     onDemandAboveBase: 0
+//     spotAllocationStrategy: capacity-optimized
+func grossKopsReplaceSpot(input, maxPrice string, mixedInstanceTypes []string) (string, error) {
+	input = regexp.MustCompile(`(?m)^  maxPrice: .*\n`).ReplaceAllString(input, "")
+	input = regexp.MustCompile(`(?m)^  mixedInstancesPolicy:\n(    .*\n)*`).ReplaceAllString(input, "")
+	if len(maxPrice) == 0 {
+		return input, nil
+	}
+
+	specRE := regexp.MustCompile(`(?m)^spec:\n`)
+	specMatches := len(specRE.FindAllStringIndex(input, -1))
+	if specMatches != 1 {
+		return "", errors.Errorf("expected to find one spec match, but found %d", specMatches)
+	}
+
+	spec := fmt.Sprintf("spec:\n  maxPrice: %q\n", maxPrice)
+	if len(mixedInstanceTypes) != 0 {
+		spec += "  mixedInstancesPolicy:\n    instances:\n"
+		for _, instanceType := range mixedInstanceTypes {
+			spec += fmt.Sprintf("    - %s\n", instanceType)
+		}
+		spec += "    onDemandAboveBase: 0\n    spotAllocationStrategy: capacity-optimized\n"
+	}
+	input = specRE.ReplaceAllLiteralString(input, spec)
+
+	return input, nil
+}
+
+// grossKopsNewInstanceGroup is a manual find-and-replace flow for creating a
+// raw kops instance group YAML manifest from the manifest of another instance
+// group, with a new name and additional node labels.
+// TODO: remove once new `kops create instancegroup` functionality is available.
+//
+// Example Manifest:
+//
+// apiVersion: kops.k8s.io/v1alpha2
+// kind: InstanceGroup
+// metadata:
+//   creationTimestamp: "2020-03-19T20:33:45Z"
+//   name: nodes
+// spec:
+//   nodeLabels:
+//     kops.k8s.io/instancegroup: nodes
+func grossKopsNewInstanceGroup(input, name string, nodeLabels map[string]string) (string, error) {
+	input = regexp.MustCompile(`  creationTimestamp: .*\n`).ReplaceAllString(input, "")
+
+	nameRE := regexp.MustCompile(`(?m)^  name: .*\n`)
+	nameMatches := len(nameRE.FindAllStringIndex(input, -1))
+	if nameMatches != 1 {
+		return "", errors.Errorf("expected to find one name match, but found %d", nameMatches)
+	}
+	input = nameRE.ReplaceAllString(input, fmt.Sprintf("  name: %s\n", name))
+
+	labelRE := regexp.MustCompile(fmt.Sprintf(`    %s: .*\n`, regexp.QuoteMeta(model.KopsInstanceGroupLabel)))
+	labelMatches := len(labelRE.FindAllStringIndex(input, -1))
+	if labelMatches != 1 {
+		return "", errors.Errorf("expected to find one instance group label match, but found %d", labelMatches)
+	}
+
+	var keys []string
+	for key := range nodeLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := fmt.Sprintf("    %s: %s\n", model.KopsInstanceGroupLabel, name)
+	for _, key := range keys {
+		labels += fmt.Sprintf("    %s: %q\n", key, nodeLabels[key])
+	}
+	input = labelRE.ReplaceAllLiteralString(input, labels)
+
+	return input, nil
+}
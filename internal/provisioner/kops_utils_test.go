@@ -371,3 +371,115 @@ spec:
 		assert.Empty(t, replaced)
 	})
 }
+
+func TestGrossNewInstanceGroup(t *testing.T) {
+	expectedManifest := `
+apiVersion: kops.k8s.io/v1alpha2
+kind: InstanceGroup
+metadata:
+  labels:
+    kops.k8s.io/cluster: 1nx98f8ykbbz9ern94reuodqpe-kops.k8s.local
+  name: memory
+spec:
+  additionalSecurityGroups:
+  - sg-08bc68b2c11d412fc
+  image: kope.io/k8s-1.15-debian-stretch-amd64-hvm-ebs-2020-01-17
+  machineType: m5.large
+  maxSize: 26
+  minSize: 26
+  nodeLabels:
+    kops.k8s.io/instancegroup: memory
+    pool: "memory"
+    tier: "enterprise"
+  role: Node
+  subnets:
+  - us-east-1a
+  - us-east-1b
+  - us-east-1c
+  - us-east-1d
+  - us-east-1e
+`
+	t.Run("valid", func(t *testing.T) {
+		replaced, err := grossKopsNewInstanceGroup(newDefaultTestManifest(), "memory", map[string]string{"tier": "enterprise", "pool": "memory"})
+		assert.NoError(t, err)
+		assert.Equal(t, expectedManifest, replaced)
+	})
+
+	t.Run("no instance group label", func(t *testing.T) {
+		testManifest := `
+apiVersion: kops.k8s.io/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: nodes
+spec:
+  machineType: m5.large
+`
+		replaced, err := grossKopsNewInstanceGroup(testManifest, "memory", nil)
+		assert.Error(t, err)
+		assert.Empty(t, replaced)
+	})
+}
+
+func TestGrossReplaceSpot(t *testing.T) {
+	spotManifest := `
+apiVersion: kops.k8s.io/v1alpha2
+kind: InstanceGroup
+metadata:
+  creationTimestamp: "2020-03-19T20:33:45Z"
+  labels:
+    kops.k8s.io/cluster: 1nx98f8ykbbz9ern94reuodqpe-kops.k8s.local
+  name: nodes
+spec:
+  maxPrice: "0.05"
+  mixedInstancesPolicy:
+    instances:
+    - m5.large
+    - m5a.large
+    onDemandAboveBase: 0
+    spotAllocationStrategy: capacity-optimized
+  additionalSecurityGroups:
+  - sg-08bc68b2c11d412fc
+  image: kope.io/k8s-1.15-debian-stretch-amd64-hvm-ebs-2020-01-17
+  machineType: m5.large
+  maxSize: 26
+  minSize: 26
+  nodeLabels:
+    kops.k8s.io/instancegroup: nodes
+  role: Node
+  subnets:
+  - us-east-1a
+  - us-east-1b
+  - us-east-1c
+  - us-east-1d
+  - us-east-1e
+`
+	t.Run("spot instances", func(t *testing.T) {
+		replaced, err := grossKopsReplaceSpot(newDefaultTestManifest(), "0.05", []string{"m5.large", "m5a.large"})
+		assert.NoError(t, err)
+		assert.Equal(t, spotManifest, replaced)
+	})
+
+	t.Run("spot instances, new values", func(t *testing.T) {
+		replaced, err := grossKopsReplaceSpot(spotManifest, "0.1", nil)
+		assert.NoError(t, err)
+		assert.Contains(t, replaced, "spec:\n  maxPrice: \"0.1\"\n  additionalSecurityGroups:\n")
+		assert.NotContains(t, replaced, "mixedInstancesPolicy")
+		assert.NotContains(t, replaced, "0.05")
+	})
+
+	t.Run("on-demand instances", func(t *testing.T) {
+		replaced, err := grossKopsReplaceSpot(spotManifest, "", []string{"m5.large"})
+		assert.NoError(t, err)
+		assert.Equal(t, newDefaultTestManifest(), replaced)
+	})
+
+	t.Run("no spec", func(t *testing.T) {
+		testManifest := `
+apiVersion: kops.k8s.io/v1alpha2
+kind: InstanceGroup
+`
+		replaced, err := grossKopsReplaceSpot(testManifest, "0.05", nil)
+		assert.Error(t, err)
+		assert.Empty(t, replaced)
+	})
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
+	"github.com/mattermost/mattermost-cloud/internal/tools/localcluster"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// provisionedClusterName returns the name given to the cluster by its
+// provisioner, or an empty string if the cluster was never named.
+func provisionedClusterName(cluster *model.Cluster) string {
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		if cluster.ProvisionerMetadataEKS == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataEKS.Name
+	case model.ProvisionerExternal:
+		if cluster.ProvisionerMetadataExternal == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataExternal.Name
+	case model.ProvisionerLocal:
+		if cluster.ProvisionerMetadataLocal == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataLocal.Name
+	default:
+		if cluster.ProvisionerMetadataKops == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataKops.Name
+	}
+}
+
+// getKubeconfig returns the path to a kubeconfig for the given cluster along
+// with a function removing it. Clusters created with kops get their kubeconfig
+// exported from the kops state, the kubeconfig of EKS clusters is built from
+// their metadata, the one of external clusters is decrypted and the one of
+// local clusters is requested from kind or k3d.
+func (provisioner *KopsProvisioner) getKubeconfig(cluster *model.Cluster, logger log.FieldLogger) (string, func() error, error) {
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		return writeEKSKubeconfig(cluster.ProvisionerMetadataEKS, eksRegion())
+	case model.ProvisionerExternal:
+		kubeconfig, err := provisioner.decryptKubeconfig(cluster)
+		if err != nil {
+			return "", nil, err
+		}
+		return writeKubeconfig(kubeconfig)
+	case model.ProvisionerLocal:
+		localCluster, err := localcluster.New(cluster.ProvisionerMetadataLocal.Engine, logger)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "failed to create local cluster wrapper")
+		}
+		defer localCluster.Close()
+
+		kubeconfig, err := localCluster.GetKubeconfig(cluster.ProvisionerMetadataLocal.Name)
+		if err != nil {
+			return "", nil, err
+		}
+		return writeKubeconfig(kubeconfig)
+	}
+
+	kops, err := kops.New(provisioner.s3StateStore, logger)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to create kops wrapper")
+	}
+
+	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		kops.Close()
+		return "", nil, errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	return kops.GetKubeConfigPath(), kops.Close, nil
+}
+
+// getKubeClient returns a kubernetes client for the given cluster along with a
+// function removing the underlying kubeconfig. Clients of external clusters
+// are created straight from their decrypted kubeconfig, without writing it
+// to disk.
+func (provisioner *KopsProvisioner) getKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, func() error, error) {
+	if cluster.Provisioner == model.ProvisionerExternal {
+		kubeconfig, err := provisioner.decryptKubeconfig(cluster)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse kubeconfig")
+		}
+
+		k8sClient, err := k8s.NewFromConfig(config, logger)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create kubernetes client")
+		}
+
+		return k8sClient, func() error { return nil }, nil
+	}
+
+	kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k8sClient, err := k8s.NewFromFile(kubeconfigPath, logger)
+	if err != nil {
+		closeKubeconfig()
+		return nil, nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	return k8sClient, closeKubeconfig, nil
+}
+
+// getKubernetesVersion returns the kubernetes version reported by the given
+// cluster, without its "v" prefix.
+func (provisioner *KopsProvisioner) getKubernetesVersion(cluster *model.Cluster, logger log.FieldLogger) (string, error) {
+	k8sClient, closeKubeClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return "", err
+	}
+	defer closeKubeClient()
+
+	versionInfo, err := k8sClient.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get kubernetes version")
+	}
+
+	return strings.TrimLeft(versionInfo.GitVersion, "v"), nil
+}
+
+// decryptKubeconfig returns the kubeconfig stored with an external cluster.
+func (provisioner *KopsProvisioner) decryptKubeconfig(cluster *model.Cluster) ([]byte, error) {
+	if len(cluster.EncryptedKubeconfig) == 0 {
+		return nil, errors.New("external cluster has no kubeconfig")
+	}
+	if len(provisioner.encryptionKey) == 0 {
+		return nil, errors.New("no encryption key configured to decrypt the kubeconfig of external clusters")
+	}
+
+	kubeconfig, err := model.DecryptSecret(provisioner.encryptionKey, cluster.EncryptedKubeconfig, []byte(cluster.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt kubeconfig")
+	}
+
+	return kubeconfig, nil
+}
+
+// writeKubeconfig writes the given kubeconfig to a temporary directory, and
+// returns its path along with a function removing it.
+func writeKubeconfig(kubeconfig []byte) (string, func() error, error) {
+	tempDir, err := ioutil.TempDir("", "kubeconfig-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to create temporary kubeconfig directory")
+	}
+	closeKubeconfig := func() error {
+		return os.RemoveAll(tempDir)
+	}
+
+	kubeconfigPath := path.Join(tempDir, "kubeconfig")
+	err = ioutil.WriteFile(kubeconfigPath, kubeconfig, 0600)
+	if err != nil {
+		closeKubeconfig()
+		return "", nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	return kubeconfigPath, closeKubeconfig, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptKubeconfig(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte{1}, model.EncryptionKeySize)
+	clusterID := model.NewID()
+	encryptedKubeconfig, err := model.EncryptSecret(encryptionKey, []byte("apiVersion: v1"), []byte(clusterID))
+	require.NoError(t, err)
+
+	cluster := &model.Cluster{
+		ID:                  clusterID,
+		Provisioner:         model.ProvisionerExternal,
+		EncryptedKubeconfig: encryptedKubeconfig,
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		provisioner := &KopsProvisioner{encryptionKey: encryptionKey}
+
+		kubeconfig, err := provisioner.decryptKubeconfig(cluster)
+		require.NoError(t, err)
+		assert.Equal(t, "apiVersion: v1", string(kubeconfig))
+	})
+
+	t.Run("no encryption key", func(t *testing.T) {
+		provisioner := &KopsProvisioner{}
+
+		_, err := provisioner.decryptKubeconfig(cluster)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong encryption key", func(t *testing.T) {
+		provisioner := &KopsProvisioner{encryptionKey: bytes.Repeat([]byte{2}, model.EncryptionKeySize)}
+
+		_, err := provisioner.decryptKubeconfig(cluster)
+		require.Error(t, err)
+	})
+
+	t.Run("kubeconfig of another cluster", func(t *testing.T) {
+		provisioner := &KopsProvisioner{encryptionKey: encryptionKey}
+
+		_, err := provisioner.decryptKubeconfig(&model.Cluster{
+			ID:                  model.NewID(),
+			Provisioner:         model.ProvisionerExternal,
+			EncryptedKubeconfig: encryptedKubeconfig,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("no kubeconfig", func(t *testing.T) {
+		provisioner := &KopsProvisioner{encryptionKey: encryptionKey}
+
+		_, err := provisioner.decryptKubeconfig(&model.Cluster{Provisioner: model.ProvisionerExternal})
+		require.Error(t, err)
+	})
+}
+
+func TestWriteKubeconfig(t *testing.T) {
+	kubeconfigPath, closeKubeconfig, err := writeKubeconfig([]byte("apiVersion: v1"))
+	require.NoError(t, err)
+
+	kubeconfig, err := ioutil.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1", string(kubeconfig))
+
+	info, err := os.Stat(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, closeKubeconfig())
+	_, err = os.Stat(kubeconfigPath)
+	assert.True(t, os.IsNotExist(err))
+}
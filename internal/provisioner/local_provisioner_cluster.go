@@ -0,0 +1,175 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/localcluster"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// LocalProvisioner provisions clusters on the machine running the provisioner
+// with kind or k3d, for development and end-to-end tests.
+//
+// Local clusters never depend on AWS: only the operators are deployed to them,
+// without the cluster utilities, and their installations are expected to keep
+// their data in the cluster.
+type LocalProvisioner struct {
+	*KopsProvisioner
+	engine string
+	logger log.FieldLogger
+}
+
+// NewLocalProvisioner creates a new LocalProvisioner running clusters with the
+// given engine, either kind or k3d.
+func NewLocalProvisioner(kopsProvisioner *KopsProvisioner, engine string, logger log.FieldLogger) *LocalProvisioner {
+	return &LocalProvisioner{
+		KopsProvisioner: kopsProvisioner,
+		engine:          engine,
+		logger:          logger.WithField("provisioner", "local"),
+	}
+}
+
+// PrepareCluster ensures a cluster object is ready for provisioning.
+func (provisioner *LocalProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	if cluster.ProvisionerMetadataLocal == nil {
+		cluster.ProvisionerMetadataLocal = &model.LocalMetadata{}
+	}
+
+	// Don't regenerate the name if already set.
+	if cluster.ProvisionerMetadataLocal.Name != "" {
+		return false
+	}
+
+	cluster.ProvisionerMetadataLocal.Name = fmt.Sprintf("%s-local", cluster.ID)
+	cluster.ProvisionerMetadataLocal.Engine = provisioner.engine
+
+	return true
+}
+
+// CreateCluster creates a local cluster, unless one with the same name is
+// already running.
+func (provisioner *LocalProvisioner) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	localMetadata := cluster.ProvisionerMetadataLocal
+	if localMetadata.ChangeRequest == nil {
+		return errors.New("local metadata change request is missing")
+	}
+
+	logger.WithField("name", localMetadata.Name).Infof("Creating cluster with %s", localMetadata.Engine)
+
+	localCluster, err := localcluster.New(localMetadata.Engine, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create local cluster wrapper")
+	}
+	defer localCluster.Close()
+
+	exists, err := localCluster.ClusterExists(localMetadata.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		logger.Info("Local cluster already exists; skipping creation")
+		return nil
+	}
+
+	err = localCluster.CreateCluster(localMetadata.Name, localMetadata.ChangeRequest.Version)
+	if err != nil {
+		return errors.Wrap(err, "unable to create local cluster")
+	}
+
+	logger.WithField("name", localMetadata.Name).Info("Successfully created cluster")
+
+	return nil
+}
+
+// ProvisionCluster deploys the operators needed for managing installations.
+// This can be called on an already-provisioned cluster to reprovision with
+// the newest version of the operators.
+func (provisioner *LocalProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kubeconfigPath, closeKubeconfig, err := provisioner.getKubeconfig(cluster, logger)
+	if err != nil {
+		return err
+	}
+	defer closeKubeconfig()
+
+	logger.Info("Provisioning cluster")
+
+	err = provisioner.deployOperators(kubeconfigPath, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("name", cluster.ProvisionerMetadataLocal.Name).Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// UpgradeCluster is not supported for local clusters.
+func (provisioner *LocalProvisioner) UpgradeCluster(cluster *model.Cluster) error {
+	return errors.New("upgrading local clusters is not supported")
+}
+
+// ResizeCluster is not supported for local clusters.
+func (provisioner *LocalProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	return errors.New("resizing local clusters is not supported")
+}
+
+// DeleteCluster deletes a local cluster.
+func (provisioner *LocalProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	localMetadata := cluster.ProvisionerMetadataLocal
+
+	logger.WithField("name", localMetadata.Name).Info("Deleting cluster")
+
+	localCluster, err := localcluster.New(localMetadata.Engine, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create local cluster wrapper")
+	}
+	defer localCluster.Close()
+
+	exists, err := localCluster.ClusterExists(localMetadata.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		logger.Info("Local cluster not found; skipping deletion")
+		return nil
+	}
+
+	err = localCluster.DeleteCluster(localMetadata.Name)
+	if err != nil {
+		return errors.Wrap(err, "unable to delete local cluster")
+	}
+
+	logger.Info("Successfully deleted cluster")
+
+	return nil
+}
+
+// RefreshClusterMetadata updates the local metadata of a cluster with the
+// kubernetes version it reports.
+func (provisioner *LocalProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Refreshing local metadata")
+
+	version, err := provisioner.getKubernetesVersion(cluster, logger)
+	if err != nil {
+		return err
+	}
+	cluster.ProvisionerMetadataLocal.Version = version
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+func TestLocalProvisionerPrepareCluster(t *testing.T) {
+	provisioner := NewLocalProvisioner(&KopsProvisioner{}, model.LocalClusterEngineK3d, log.New())
+	cluster := &model.Cluster{
+		ID:          model.NewID(),
+		Provisioner: model.ProvisionerLocal,
+		ProvisionerMetadataLocal: &model.LocalMetadata{
+			ChangeRequest: &model.LocalMetadataRequestedState{Version: "latest"},
+		},
+	}
+
+	require.True(t, provisioner.PrepareCluster(cluster))
+	assert.Equal(t, cluster.ID+"-local", cluster.ProvisionerMetadataLocal.Name)
+	assert.Equal(t, model.LocalClusterEngineK3d, cluster.ProvisionerMetadataLocal.Engine)
+
+	require.False(t, provisioner.PrepareCluster(cluster))
+}
+
+func TestLocalProvisionerCreateClusterWithoutChangeRequest(t *testing.T) {
+	provisioner := NewLocalProvisioner(&KopsProvisioner{}, model.LocalClusterEngineKind, log.New())
+	cluster := &model.Cluster{ID: model.NewID(), Provisioner: model.ProvisionerLocal}
+	provisioner.PrepareCluster(cluster)
+
+	assert.Error(t, provisioner.CreateCluster(cluster, nil))
+}
+
+func TestLocalProvisionerUnsupportedOperations(t *testing.T) {
+	provisioner := NewLocalProvisioner(&KopsProvisioner{}, model.LocalClusterEngineKind, log.New())
+	cluster := &model.Cluster{ID: model.NewID(), Provisioner: model.ProvisionerLocal}
+
+	assert.Error(t, provisioner.UpgradeCluster(cluster))
+	assert.Error(t, provisioner.ResizeCluster(cluster))
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -18,13 +17,13 @@ import (
 type nginx struct {
 	awsClient      aws.AWS
 	provisioner    *KopsProvisioner
-	kops           *kops.Cmd
+	kubeconfigPath string
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newNginxHandle(desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*nginx, error) {
+func newNginxHandle(desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfigPath string, logger log.FieldLogger) (*nginx, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate NGINX handle with nil logger")
 	}
@@ -37,14 +36,14 @@ func newNginxHandle(desiredVersion string, provisioner *KopsProvisioner, awsClie
 		return nil, errors.New("cannot create a connection to Nginx if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Nginx if the Kops command provided is nil")
+	if kubeconfigPath == "" {
+		return nil, errors.New("cannot create a connection to Nginx if the kubeconfig path provided is empty")
 	}
 
 	return &nginx{
 		awsClient:      awsClient,
 		provisioner:    provisioner,
-		kops:           kops,
+		kubeconfigPath: kubeconfigPath,
 		logger:         logger.WithField("cluster-utility", model.NginxCanonicalName),
 		desiredVersion: desiredVersion,
 	}, nil
@@ -105,7 +104,7 @@ func (n *nginx) NewHelmDeployment() (*helmDeployment, error) {
 		setArgument:         fmt.Sprintf("controller.service.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s,controller.service.internal.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s", *awsACMCert.CertificateArn, *awsACMPrivateCert.CertificateArn),
 		valuesPath:          "helm-charts/nginx_values.yaml",
 		kopsProvisioner:     n.provisioner,
-		kops:                n.kops,
+		kubeconfigPath:      n.kubeconfigPath,
 		logger:              n.logger,
 		desiredVersion:      n.desiredVersion,
 	}, nil
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -20,14 +19,14 @@ import (
 type prometheus struct {
 	awsClient      aws.AWS
 	cluster        *model.Cluster
-	kops           *kops.Cmd
+	kubeconfigPath string
 	logger         log.FieldLogger
 	provisioner    *KopsProvisioner
 	desiredVersion string
 	actualVersion  string
 }
 
-func newPrometheusHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*prometheus, error) {
+func newPrometheusHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfigPath string, logger log.FieldLogger) (*prometheus, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("cannot instantiate Prometheus handle with nil logger")
 	}
@@ -44,8 +43,8 @@ func newPrometheusHandle(cluster *model.Cluster, provisioner *KopsProvisioner, a
 		return nil, errors.New("cannot create a connection to Prometheus if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Prometheus if the Kops command provided is nil")
+	if kubeconfigPath == "" {
+		return nil, errors.New("cannot create a connection to Prometheus if the kubeconfig path provided is empty")
 	}
 
 	version, err := cluster.DesiredUtilityVersion(model.PrometheusCanonicalName)
@@ -56,7 +55,7 @@ func newPrometheusHandle(cluster *model.Cluster, provisioner *KopsProvisioner, a
 	return &prometheus{
 		awsClient:      awsClient,
 		cluster:        cluster,
-		kops:           kops,
+		kubeconfigPath: kubeconfigPath,
 		logger:         logger.WithField("cluster-utility", model.PrometheusCanonicalName),
 		provisioner:    provisioner,
 		desiredVersion: version,
@@ -92,7 +91,7 @@ func (p *prometheus) CreateOrUpgrade() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(120)*time.Second)
 	defer cancel()
 
-	endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("prometheus-action", "create"), p.kops.GetKubeConfigPath())
+	endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("prometheus-action", "create"), p.kubeconfigPath)
 	if err != nil {
 		return errors.Wrap(err, "couldn't get the load balancer endpoint (nginx) for Prometheus")
 	}
@@ -138,7 +137,7 @@ func (p *prometheus) NewHelmDeployment() *helmDeployment {
 	return &helmDeployment{
 		chartDeploymentName: "prometheus",
 		chartName:           "stable/prometheus",
-		kops:                p.kops,
+		kubeconfigPath:      p.kubeconfigPath,
 		kopsProvisioner:     p.provisioner,
 		logger:              p.logger,
 		namespace:           "prometheus",
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -20,14 +19,14 @@ type teleport struct {
 	awsClient      aws.AWS
 	environment    string
 	provisioner    *KopsProvisioner
-	kops           *kops.Cmd
+	kubeconfigPath string
 	cluster        *model.Cluster
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*teleport, error) {
+func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfigPath string, logger log.FieldLogger) (*teleport, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate Teleport handle with nil logger")
 	}
@@ -36,8 +35,8 @@ func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisione
 		return nil, errors.New("cannot create a connection to Teleport if the provisioner provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Teleport if the Kops command provided is nil")
+	if kubeconfigPath == "" {
+		return nil, errors.New("cannot create a connection to Teleport if the kubeconfig path provided is empty")
 	}
 
 	environment, err := awsClient.GetCloudEnvironmentName()
@@ -53,7 +52,7 @@ func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisione
 		awsClient:      awsClient,
 		environment:    environment,
 		provisioner:    provisioner,
-		kops:           kops,
+		kubeconfigPath: kubeconfigPath,
 		cluster:        cluster,
 		logger:         logger.WithField("cluster-utility", model.TeleportCanonicalName),
 		desiredVersion: desiredVersion,
@@ -122,7 +121,7 @@ func (n *teleport) NewHelmDeployment() *helmDeployment {
 		setArgument:         fmt.Sprintf("config.auth_service.cluster_name=%[1]s,config.teleport.storage.region=%[2]s,config.teleport.storage.table_name=%[1]s,config.teleport.storage.audit_events_uri=dynamodb://%[1]s-events,config.teleport.storage.audit_sessions_uri=s3://%[1]s/records?region=%[2]s", teleportClusterName, awsRegion),
 		valuesPath:          "helm-charts/teleport_values.yaml",
 		kopsProvisioner:     n.provisioner,
-		kops:                n.kops,
+		kubeconfigPath:      n.kubeconfigPath,
 		logger:              n.logger,
 		desiredVersion:      n.desiredVersion,
 	}
@@ -6,7 +6,6 @@ package provisioner
 
 import (
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -44,10 +43,10 @@ type Utility interface {
 // thought  of as  a handle  to the  real group  of utilities  running
 // inside of the cluster
 type utilityGroup struct {
-	utilities   []Utility
-	kops        *kops.Cmd
-	provisioner *KopsProvisioner
-	cluster     *model.Cluster
+	utilities      []Utility
+	kubeconfigPath string
+	provisioner    *KopsProvisioner
+	cluster        *model.Cluster
 }
 
 // List of repos to add during helm setup
@@ -57,7 +56,7 @@ var helmRepos = map[string]string{
 	"ingress-nginx": "https://kubernetes.github.io/ingress-nginx",
 }
 
-func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster *model.Cluster, awsClient aws.AWS, parentLogger log.FieldLogger) (*utilityGroup, error) {
+func newUtilityGroupHandle(kubeconfigPath string, provisioner *KopsProvisioner, cluster *model.Cluster, awsClient aws.AWS, parentLogger log.FieldLogger) (*utilityGroup, error) {
 	logger := parentLogger.WithField("utility-group", "create-handle")
 
 	desiredVersion, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
@@ -65,12 +64,12 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	nginx, err := newNginxHandle(desiredVersion, provisioner, awsClient, kops, logger)
+	nginx, err := newNginxHandle(desiredVersion, provisioner, awsClient, kubeconfigPath, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for NGINX")
 	}
 
-	prometheus, err := newPrometheusHandle(cluster, provisioner, awsClient, kops, logger)
+	prometheus, err := newPrometheusHandle(cluster, provisioner, awsClient, kubeconfigPath, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Prometheus")
 	}
@@ -80,7 +79,7 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	fluentbit, err := newFluentbitHandle(desiredVersion, provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(desiredVersion, provisioner, awsClient, kubeconfigPath, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Fluentbit")
 	}
@@ -90,7 +89,7 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	teleport, err := newTeleportHandle(cluster, desiredVersion, provisioner, awsClient, kops, logger)
+	teleport, err := newTeleportHandle(cluster, desiredVersion, provisioner, awsClient, kubeconfigPath, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Teleport")
 	}
@@ -98,10 +97,10 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 	// the order of utilities here matters; the utilities are deployed
 	// in order to resolve dependencies between them
 	return &utilityGroup{
-		utilities:   []Utility{nginx, prometheus, fluentbit, teleport},
-		kops:        kops,
-		provisioner: provisioner,
-		cluster:     cluster,
+		utilities:      []Utility{nginx, prometheus, fluentbit, teleport},
+		kubeconfigPath: kubeconfigPath,
+		provisioner:    provisioner,
+		cluster:        cluster,
 	}, nil
 
 }
@@ -134,7 +133,7 @@ func (group utilityGroup) DestroyUtilityGroup() error {
 func (group utilityGroup) ProvisionUtilityGroup() error {
 	logger := group.provisioner.logger.WithField("utility-group", "UpgradeManifests")
 
-	err := installHelm(group.kops, helmRepos, group.provisioner.logger.WithField("helm-install", "ProvisionUtilityGroup"))
+	err := installHelm(group.kubeconfigPath, helmRepos, group.provisioner.logger.WithField("helm-install", "ProvisionUtilityGroup"))
 	if err != nil {
 		return errors.Wrap(err, "failed to set up Helm as a prerequisite to installing the cluster utilities")
 	}
@@ -0,0 +1,122 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var apiTokenSelect sq.SelectBuilder
+
+func init() {
+	apiTokenSelect = sq.
+		Select("ID", "Name", "OwnerID", "Scope", "TokenHash", "CreateAt", "DeleteAt").
+		From("APIToken")
+}
+
+// GetAPIToken fetches the given API token by id.
+func (sqlStore *SQLStore) GetAPIToken(id string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := sqlStore.getBuilder(sqlStore.db, &token,
+		apiTokenSelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get API token by id")
+	}
+
+	return &token, nil
+}
+
+// GetAPITokenByHash fetches the API token with the given token hash. Deleted
+// tokens are never returned.
+func (sqlStore *SQLStore) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := sqlStore.getBuilder(sqlStore.db, &token,
+		apiTokenSelect.
+			Where("TokenHash = ?", tokenHash).
+			Where("DeleteAt = 0"),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get API token by hash")
+	}
+
+	return &token, nil
+}
+
+// GetAPITokens fetches the given page of created API tokens. The first page is 0.
+func (sqlStore *SQLStore) GetAPITokens(filter *model.APITokenFilter) ([]*model.APIToken, error) {
+	builder := apiTokenSelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.OwnerID != "" {
+		builder = builder.Where("OwnerID = ?", filter.OwnerID)
+	}
+	if !filter.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
+
+	var tokens []*model.APIToken
+	err := sqlStore.selectBuilder(sqlStore.db, &tokens, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for API tokens")
+	}
+
+	return tokens, nil
+}
+
+// CreateAPIToken records the given API token to the database, assigning it a
+// unique ID. Only the token hash is persisted.
+func (sqlStore *SQLStore) CreateAPIToken(token *model.APIToken) error {
+	token.ID = model.NewID()
+	token.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("APIToken").
+		SetMap(map[string]interface{}{
+			"ID":        token.ID,
+			"Name":      token.Name,
+			"OwnerID":   token.OwnerID,
+			"Scope":     token.Scope,
+			"TokenHash": token.TokenHash,
+			"CreateAt":  token.CreateAt,
+			"DeleteAt":  0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create API token")
+	}
+
+	return nil
+}
+
+// DeleteAPIToken marks the given API token as deleted, revoking it without
+// removing the record from the database.
+func (sqlStore *SQLStore) DeleteAPIToken(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("APIToken").
+		Set("DeleteAt", GetMillis()).
+		Where("ID = ?", id).
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark API token as deleted")
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	t.Run("get unknown API token", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token, err := sqlStore.GetAPIToken("unknown")
+		require.NoError(t, err)
+		require.Nil(t, token)
+
+		token, err = sqlStore.GetAPITokenByHash("unknown")
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+
+	t.Run("get API tokens", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token1 := &model.APIToken{
+			Name:      "admin",
+			Scope:     model.APITokenScopeClusterAdmin,
+			TokenHash: "hash1",
+		}
+
+		token2 := &model.APIToken{
+			Name:      "operator",
+			OwnerID:   "owner2",
+			Scope:     model.APITokenScopeInstallationOperator,
+			TokenHash: "hash2",
+		}
+
+		err := sqlStore.CreateAPIToken(token1)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateAPIToken(token2)
+		require.NoError(t, err)
+
+		actualToken1, err := sqlStore.GetAPIToken(token1.ID)
+		require.NoError(t, err)
+		require.Equal(t, token1, actualToken1)
+
+		actualToken2, err := sqlStore.GetAPITokenByHash("hash2")
+		require.NoError(t, err)
+		require.Equal(t, token2, actualToken2)
+
+		testCases := []struct {
+			Description string
+			Filter      *model.APITokenFilter
+			Expected    []*model.APIToken
+		}{
+			{
+				"page 0, perPage 0",
+				&model.APITokenFilter{Page: 0, PerPage: 0},
+				nil,
+			},
+			{
+				"page 0, perPage 1",
+				&model.APITokenFilter{Page: 0, PerPage: 1},
+				[]*model.APIToken{token1},
+			},
+			{
+				"all",
+				&model.APITokenFilter{PerPage: model.AllPerPage},
+				[]*model.APIToken{token1, token2},
+			},
+			{
+				"owner",
+				&model.APITokenFilter{OwnerID: "owner2", PerPage: model.AllPerPage},
+				[]*model.APIToken{token2},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.Description, func(t *testing.T) {
+				actual, err := sqlStore.GetAPITokens(testCase.Filter)
+				require.NoError(t, err)
+				require.Equal(t, testCase.Expected, actual)
+			})
+		}
+	})
+
+	t.Run("delete API token", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token := &model.APIToken{
+			Name:      "admin",
+			Scope:     model.APITokenScopeClusterAdmin,
+			TokenHash: "hash",
+		}
+
+		err := sqlStore.CreateAPIToken(token)
+		require.NoError(t, err)
+
+		err = sqlStore.DeleteAPIToken(token.ID)
+		require.NoError(t, err)
+
+		actualToken, err := sqlStore.GetAPIToken(token.ID)
+		require.NoError(t, err)
+		require.True(t, actualToken.IsDeleted())
+
+		actualToken, err = sqlStore.GetAPITokenByHash("hash")
+		require.NoError(t, err)
+		require.Nil(t, actualToken)
+
+		tokens, err := sqlStore.GetAPITokens(&model.APITokenFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+
+		tokens, err = sqlStore.GetAPITokens(&model.APITokenFilter{PerPage: model.AllPerPage, IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+	})
+}
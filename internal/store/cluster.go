@@ -19,8 +19,10 @@ func init() {
 	clusterSelect = sq.
 		Select(
 			"ID", "Provider", "Provisioner", "ProviderMetadataRaw", "ProvisionerMetadataRaw",
-			"UtilityMetadataRaw", "State", "AllowInstallations", "CreateAt", "DeleteAt",
-			"APISecurityLock", "LockAcquiredBy", "LockAcquiredAt",
+			"UtilityMetadataRaw", "State", "AllowInstallations", "LabelsRaw", "Draining", "DrainMaxRolling",
+			"CreateAt", "DeleteAt", "APISecurityLock", "LockAcquiredBy", "LockAcquiredAt",
+			"ResourceThreshold", "ResourceThresholdScaleValue", "ScaleDownPolicy",
+			"LowUtilizationSince", "CapacityCheckAt", "EncryptedKubeconfig",
 		).
 		From("Cluster")
 }
@@ -30,6 +32,7 @@ type RawClusterMetadata struct {
 	ProviderMetadataRaw    []byte
 	ProvisionerMetadataRaw []byte
 	UtilityMetadataRaw     []byte
+	LabelsRaw              []byte
 }
 
 type rawCluster struct {
@@ -44,19 +47,46 @@ func buildRawMetadata(cluster *model.Cluster) (*RawClusterMetadata, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to marshal ProviderMetadataAWS")
 	}
-	provisionerMetadataJSON, err := json.Marshal(cluster.ProvisionerMetadataKops)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataKops")
+	var provisionerMetadataJSON []byte
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataEKS)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataEKS")
+		}
+	case model.ProvisionerExternal:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataExternal)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataExternal")
+		}
+	case model.ProvisionerLocal:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataLocal)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataLocal")
+		}
+	default:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataKops)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataKops")
+		}
 	}
 	utilityMetadataJSON, err := json.Marshal(cluster.UtilityMetadata)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to marshal UtilityMetadata")
 	}
+	var labelsJSON []byte
+	if cluster.Labels != nil {
+		labelsJSON, err = json.Marshal(cluster.Labels)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal Labels")
+		}
+	}
 
 	return &RawClusterMetadata{
 		ProviderMetadataRaw:    providerMetadataJSON,
 		ProvisionerMetadataRaw: provisionerMetadataJSON,
 		UtilityMetadataRaw:     utilityMetadataJSON,
+		LabelsRaw:              labelsJSON,
 	}, nil
 }
 
@@ -66,7 +96,16 @@ func (r *rawCluster) toCluster() (*model.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	r.Cluster.ProvisionerMetadataKops, err = model.NewKopsMetadata(r.ProvisionerMetadataRaw)
+	switch r.Cluster.Provisioner {
+	case model.ProvisionerEKS:
+		r.Cluster.ProvisionerMetadataEKS, err = model.NewEKSMetadata(r.ProvisionerMetadataRaw)
+	case model.ProvisionerExternal:
+		r.Cluster.ProvisionerMetadataExternal, err = model.NewExternalMetadata(r.ProvisionerMetadataRaw)
+	case model.ProvisionerLocal:
+		r.Cluster.ProvisionerMetadataLocal, err = model.NewLocalMetadata(r.ProvisionerMetadataRaw)
+	default:
+		r.Cluster.ProvisionerMetadataKops, err = model.NewKopsMetadata(r.ProvisionerMetadataRaw)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +113,12 @@ func (r *rawCluster) toCluster() (*model.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
+	if r.LabelsRaw != nil {
+		err = json.Unmarshal(r.LabelsRaw, &r.Cluster.Labels)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal Labels")
+		}
+	}
 
 	return r.Cluster, nil
 }
@@ -146,9 +191,108 @@ func (sqlStore *SQLStore) GetUnlockedClustersPendingWork() ([]*model.Cluster, er
 	return rawClusters.toClusters()
 }
 
-// CreateCluster records the given cluster to the database, assigning it a unique ID.
+// GetUnlockedClustersDraining returns unlocked clusters whose installations
+// are being moved to other clusters.
+func (sqlStore *SQLStore) GetUnlockedClustersDraining() ([]*model.Cluster, error) {
+	builder := clusterSelect.
+		Where("Draining = ?", true).
+		Where("LockAcquiredAt = 0").
+		Where("DeleteAt = 0").
+		OrderBy("CreateAt ASC")
+
+	var rawClusters rawClusters
+	err := sqlStore.selectBuilder(sqlStore.db, &rawClusters, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for draining clusters")
+	}
+
+	return rawClusters.toClusters()
+}
+
+// ClusterDrainMetadata is a batch of information about the installations of a
+// cluster being drained.
+type ClusterDrainMetadata struct {
+	InstallationIDsToBeMigrated []string
+	InstallationIDsToBeWokenUp  []string
+	// InstallationIDsAwaitingTarget are the installations requested to be
+	// migrated for which no target cluster was found yet.
+	InstallationIDsAwaitingTarget []string
+	// InstallationIDsBlocked are the installations that keep the drain from
+	// finishing until they are dealt with by hand: ones that are not
+	// migratable and ones whose migration failed.
+	InstallationIDsBlocked         []string
+	InstallationTotalCount         int64
+	InstallationMigratingCount     int64
+	InstallationUpdatingCount      int64
+	InstallationHibernatingCount   int64
+	InstallationFailedCount        int64
+	InstallationNotMigratableCount int64
+}
+
+// GetClusterDrainMetadata returns installation IDs and metadata related to
+// moving the installations off the given cluster.
+//
+// Only stable installations are returned to be migrated. Hibernating
+// installations are returned to be woken up so that they can be migrated once
+// stable. Installations in other states are left alone until they become
+// stable again.
+func (sqlStore *SQLStore) GetClusterDrainMetadata(clusterID string) (*ClusterDrainMetadata, error) {
+	var installations []*model.Installation
+	builder := sq.
+		Select("Installation.ID", "Installation.State", "Installation.Database", "Installation.Filestore", "Installation.MigrationTargetClusterID").
+		From("Installation").
+		Join("ClusterInstallation ON ClusterInstallation.InstallationID = Installation.ID").
+		Where("ClusterInstallation.ClusterID = ?", clusterID).
+		Where("ClusterInstallation.DeleteAt = 0").
+		Where("Installation.DeleteAt = 0").
+		OrderBy("Installation.CreateAt ASC")
+	err := sqlStore.selectBuilder(sqlStore.db, &installations, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for cluster installations")
+	}
+
+	metadata := &ClusterDrainMetadata{
+		InstallationIDsToBeMigrated:   []string{},
+		InstallationIDsToBeWokenUp:    []string{},
+		InstallationIDsAwaitingTarget: []string{},
+	}
+	for _, installation := range installations {
+		metadata.InstallationTotalCount++
+
+		switch {
+		case !installation.SupportsMigration():
+			metadata.InstallationIDsBlocked = append(metadata.InstallationIDsBlocked, installation.ID)
+			metadata.InstallationNotMigratableCount++
+		case installation.State == model.InstallationStateStable:
+			metadata.InstallationIDsToBeMigrated = append(metadata.InstallationIDsToBeMigrated, installation.ID)
+		case installation.State == model.InstallationStateMigrationFailed:
+			metadata.InstallationIDsBlocked = append(metadata.InstallationIDsBlocked, installation.ID)
+			metadata.InstallationFailedCount++
+		case installation.State == model.InstallationStateHibernating:
+			metadata.InstallationIDsToBeWokenUp = append(metadata.InstallationIDsToBeWokenUp, installation.ID)
+			metadata.InstallationHibernatingCount++
+		case installation.State == model.InstallationStateUpdateRequested,
+			installation.State == model.InstallationStateUpdateInProgress:
+			metadata.InstallationUpdatingCount++
+		case installation.State == model.InstallationStateMigrationRequested &&
+			installation.MigrationTargetClusterID == "":
+			metadata.InstallationIDsAwaitingTarget = append(metadata.InstallationIDsAwaitingTarget, installation.ID)
+			metadata.InstallationMigratingCount++
+		case installation.IsMigrating():
+			metadata.InstallationMigratingCount++
+		}
+	}
+
+	return metadata, nil
+}
+
+// CreateCluster records the given cluster to the database, assigning it a
+// unique ID unless one was already generated, as done for imported clusters
+// whose kubeconfig is encrypted along with their ID.
 func (sqlStore *SQLStore) CreateCluster(cluster *model.Cluster) error {
-	cluster.ID = model.NewID()
+	if len(cluster.ID) == 0 {
+		cluster.ID = model.NewID()
+	}
 	cluster.CreateAt = GetMillis()
 
 	rawMetadata, err := buildRawMetadata(cluster)
@@ -167,11 +311,21 @@ func (sqlStore *SQLStore) CreateCluster(cluster *model.Cluster) error {
 			"ProvisionerMetadataRaw": rawMetadata.ProvisionerMetadataRaw,
 			"UtilityMetadataRaw":     rawMetadata.UtilityMetadataRaw,
 			"AllowInstallations":     cluster.AllowInstallations,
+			"LabelsRaw":              rawMetadata.LabelsRaw,
+			"Draining":               cluster.Draining,
+			"DrainMaxRolling":        cluster.DrainMaxRolling,
 			"CreateAt":               cluster.CreateAt,
 			"DeleteAt":               0,
 			"APISecurityLock":        cluster.APISecurityLock,
 			"LockAcquiredBy":         nil,
 			"LockAcquiredAt":         0,
+
+			"ResourceThreshold":           cluster.ResourceThreshold,
+			"ResourceThresholdScaleValue": cluster.ResourceThresholdScaleValue,
+			"ScaleDownPolicy":             cluster.ScaleDownPolicy,
+			"LowUtilizationSince":         0,
+			"CapacityCheckAt":             0,
+			"EncryptedKubeconfig":         cluster.EncryptedKubeconfig,
 		}),
 	)
 	if err != nil {
@@ -198,6 +352,13 @@ func (sqlStore *SQLStore) UpdateCluster(cluster *model.Cluster) error {
 			"ProvisionerMetadataRaw": rawMetadata.ProvisionerMetadataRaw,
 			"UtilityMetadataRaw":     rawMetadata.UtilityMetadataRaw,
 			"AllowInstallations":     cluster.AllowInstallations,
+			"LabelsRaw":              rawMetadata.LabelsRaw,
+			"Draining":               cluster.Draining,
+			"DrainMaxRolling":        cluster.DrainMaxRolling,
+
+			"ResourceThreshold":           cluster.ResourceThreshold,
+			"ResourceThresholdScaleValue": cluster.ResourceThresholdScaleValue,
+			"ScaleDownPolicy":             cluster.ScaleDownPolicy,
 		}).
 		Where("ID = ?", cluster.ID),
 	)
@@ -208,6 +369,24 @@ func (sqlStore *SQLStore) UpdateCluster(cluster *model.Cluster) error {
 	return nil
 }
 
+// UpdateClusterCapacityCheck updates the recorded utilization of the given
+// cluster.
+func (sqlStore *SQLStore) UpdateClusterCapacityCheck(cluster *model.Cluster) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Cluster").
+		SetMap(map[string]interface{}{
+			"LowUtilizationSince": cluster.LowUtilizationSince,
+			"CapacityCheckAt":     cluster.CapacityCheckAt,
+		}).
+		Where("ID = ?", cluster.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update cluster capacity check")
+	}
+
+	return nil
+}
+
 // DeleteCluster marks the given cluster as deleted, but does not remove the record from the
 // database.
 func (sqlStore *SQLStore) DeleteCluster(id string) error {
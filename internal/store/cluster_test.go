@@ -26,6 +26,7 @@ func TestClusters(t *testing.T) {
 	t.Run("get clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := MakeTestSQLStore(t, logger)
+		resourceThreshold := 70
 
 		cluster1 := &model.Cluster{
 			Provider:                "aws",
@@ -45,6 +46,9 @@ func TestClusters(t *testing.T) {
 			UtilityMetadata:         &model.UtilityMetadata{},
 			State:                   model.ClusterStateStable,
 			AllowInstallations:      true,
+			Labels:                  map[string]string{"zone": "us-east-1b"},
+			ResourceThreshold:       &resourceThreshold,
+			ScaleDownPolicy:         model.ClusterScaleDownPolicyUtilization,
 		}
 
 		err := sqlStore.CreateCluster(cluster1)
@@ -92,6 +96,9 @@ func TestClusters(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := MakeTestSQLStore(t, logger)
 
+		resourceThreshold := 60
+		resourceThresholdScaleValue := 2
+
 		cluster1 := &model.Cluster{
 			Provider:                "aws",
 			Provisioner:             "kops",
@@ -124,6 +131,10 @@ func TestClusters(t *testing.T) {
 		cluster1.ProvisionerMetadataKops = &model.KopsMetadata{Version: "version2"}
 		cluster1.State = model.ClusterStateDeletionRequested
 		cluster1.AllowInstallations = true
+		cluster1.Labels = map[string]string{"tier": "enterprise"}
+		cluster1.ResourceThreshold = &resourceThreshold
+		cluster1.ResourceThresholdScaleValue = &resourceThresholdScaleValue
+		cluster1.ScaleDownPolicy = model.ClusterScaleDownPolicyUtilization
 
 		err = sqlStore.UpdateCluster(cluster1)
 		require.NoError(t, err)
@@ -137,6 +148,107 @@ func TestClusters(t *testing.T) {
 		require.Equal(t, cluster2, actualCluster2)
 	})
 
+	t.Run("eks metadata", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{
+			Provider:            "aws",
+			Provisioner:         model.ProvisionerEKS,
+			ProviderMetadataAWS: &model.AWSMetadata{Zones: []string{"zone1"}},
+			ProvisionerMetadataEKS: &model.EKSMetadata{
+				Name:    "eks-cluster",
+				Version: "1.17",
+				NodeGroups: []*model.EKSNodeGroup{
+					{Name: model.EKSNodeGroupNodes, InstanceType: "m5.large", MinCount: 2, MaxCount: 2},
+				},
+			},
+			UtilityMetadata: &model.UtilityMetadata{},
+			State:           model.ClusterStateCreationRequested,
+		}
+
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster, actualCluster)
+		require.Nil(t, actualCluster.ProvisionerMetadataKops)
+
+		cluster.ProvisionerMetadataEKS.Version = "1.18"
+		err = sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, "1.18", actualCluster.ProvisionerMetadataEKS.Version)
+	})
+
+	t.Run("external cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{
+			Provider:                    "aws",
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Name: "platform"},
+			EncryptedKubeconfig:         []byte("encrypted"),
+			UtilityMetadata:             &model.UtilityMetadata{},
+			State:                       model.ClusterStateCreationRequested,
+		}
+
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster, actualCluster)
+		require.Nil(t, actualCluster.ProvisionerMetadataKops)
+
+		cluster.ProvisionerMetadataExternal.Version = "1.17.9"
+		err = sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, "1.17.9", actualCluster.ProvisionerMetadataExternal.Version)
+		require.Equal(t, []byte("encrypted"), actualCluster.EncryptedKubeconfig)
+	})
+
+	t.Run("local cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{
+			Provider:    "aws",
+			Provisioner: model.ProvisionerLocal,
+			ProvisionerMetadataLocal: &model.LocalMetadata{
+				Name:          "local",
+				Engine:        model.LocalClusterEngineKind,
+				ChangeRequest: &model.LocalMetadataRequestedState{Version: "latest"},
+			},
+			UtilityMetadata: &model.UtilityMetadata{},
+			State:           model.ClusterStateCreationRequested,
+		}
+
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster, actualCluster)
+		require.Nil(t, actualCluster.ProvisionerMetadataKops)
+
+		cluster.ProvisionerMetadataLocal.Version = "1.18.2"
+		cluster.ProvisionerMetadataLocal.ClearChangeRequest()
+		err = sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+
+		actualCluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster, actualCluster)
+	})
+
 	t.Run("delete cluster", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := MakeTestSQLStore(t, logger)
@@ -284,6 +396,133 @@ func TestGetUnlockedClustersPendingWork(t *testing.T) {
 	require.Empty(t, clusters)
 }
 
+func TestGetUnlockedClustersDraining(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	drainingCluster := &model.Cluster{
+		State:           model.ClusterStateStable,
+		Draining:        true,
+		DrainMaxRolling: 2,
+	}
+	err := sqlStore.CreateCluster(drainingCluster)
+	require.NoError(t, err)
+
+	err = sqlStore.CreateCluster(&model.Cluster{State: model.ClusterStateStable})
+	require.NoError(t, err)
+
+	clusters, err := sqlStore.GetUnlockedClustersDraining()
+	require.NoError(t, err)
+	require.Equal(t, []*model.Cluster{drainingCluster}, clusters)
+
+	locked, err := sqlStore.LockCluster(drainingCluster.ID, model.NewID())
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	clusters, err = sqlStore.GetUnlockedClustersDraining()
+	require.NoError(t, err)
+	require.Empty(t, clusters)
+}
+
+func TestGetClusterDrainMetadata(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	cluster := &model.Cluster{State: model.ClusterStateStable}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	otherCluster := &model.Cluster{State: model.ClusterStateStable}
+	err = sqlStore.CreateCluster(otherCluster)
+	require.NoError(t, err)
+
+	createInstallation := func(t *testing.T, clusterID, state, database string) *model.Installation {
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			DNS:       model.NewID() + ".example.com",
+			Database:  database,
+			Filestore: model.InstallationFilestoreAwsS3,
+			State:     state,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      clusterID,
+			InstallationID: installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		return installation
+	}
+
+	stable1 := createInstallation(t, cluster.ID, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+	stable2 := createInstallation(t, cluster.ID, model.InstallationStateStable, model.InstallationDatabaseSingleTenantRDSMySQL)
+	createInstallation(t, cluster.ID, model.InstallationStateMigrationInProgress, model.InstallationDatabaseMultiTenantRDSPostgres)
+	failed := createInstallation(t, cluster.ID, model.InstallationStateMigrationFailed, model.InstallationDatabaseMultiTenantRDSPostgres)
+	notMigratable1 := createInstallation(t, cluster.ID, model.InstallationStateStable, model.InstallationDatabaseMysqlOperator)
+	createInstallation(t, cluster.ID, model.InstallationStateUpdateInProgress, model.InstallationDatabaseMultiTenantRDSPostgres)
+	hibernating := createInstallation(t, cluster.ID, model.InstallationStateHibernating, model.InstallationDatabaseMultiTenantRDSPostgres)
+	notMigratable2 := createInstallation(t, cluster.ID, model.InstallationStateHibernating, model.InstallationDatabaseMysqlOperator)
+	awaitingTarget := createInstallation(t, cluster.ID, model.InstallationStateMigrationRequested, model.InstallationDatabaseMultiTenantRDSPostgres)
+	withTarget := createInstallation(t, cluster.ID, model.InstallationStateMigrationRequested, model.InstallationDatabaseMultiTenantRDSPostgres)
+	withTarget.MigrationTargetClusterID = otherCluster.ID
+	err = sqlStore.UpdateInstallationMigrationTarget(withTarget)
+	require.NoError(t, err)
+	createInstallation(t, otherCluster.ID, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+	metadata, err := sqlStore.GetClusterDrainMetadata(cluster.ID)
+	require.NoError(t, err)
+	require.Equal(t, &ClusterDrainMetadata{
+		InstallationIDsToBeMigrated:    []string{stable1.ID, stable2.ID},
+		InstallationIDsToBeWokenUp:     []string{hibernating.ID},
+		InstallationIDsAwaitingTarget:  []string{awaitingTarget.ID},
+		InstallationIDsBlocked:         []string{failed.ID, notMigratable1.ID, notMigratable2.ID},
+		InstallationTotalCount:         10,
+		InstallationMigratingCount:     3,
+		InstallationUpdatingCount:      1,
+		InstallationHibernatingCount:   1,
+		InstallationFailedCount:        1,
+		InstallationNotMigratableCount: 2,
+	}, metadata)
+
+	metadata, err = sqlStore.GetClusterDrainMetadata(model.NewID())
+	require.NoError(t, err)
+	require.Equal(t, &ClusterDrainMetadata{
+		InstallationIDsToBeMigrated:   []string{},
+		InstallationIDsToBeWokenUp:    []string{},
+		InstallationIDsAwaitingTarget: []string{},
+	}, metadata)
+}
+
+func TestUpdateClusterCapacityCheck(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	cluster := &model.Cluster{
+		State:              model.ClusterStateStable,
+		AllowInstallations: true,
+	}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	cluster.LowUtilizationSince = 1000
+	cluster.CapacityCheckAt = 2000
+	cluster.AllowInstallations = false
+
+	err = sqlStore.UpdateClusterCapacityCheck(cluster)
+	require.NoError(t, err)
+
+	storedCluster, err := sqlStore.GetCluster(cluster.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, storedCluster.LowUtilizationSince)
+	require.EqualValues(t, 2000, storedCluster.CapacityCheckAt)
+	require.True(t, storedCluster.AllowInstallations)
+}
+
 func TestLockCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
@@ -0,0 +1,214 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var eventSelect sq.SelectBuilder
+
+func init() {
+	eventSelect = sq.
+		Select("Sequence", "PayloadRaw", "InstanceID", "RequestID", "CreateAt").
+		From("Event")
+}
+
+type rawEvent struct {
+	*model.Event
+	PayloadRaw []byte
+}
+
+type rawEvents []*rawEvent
+
+func (r *rawEvent) toEvent() (*model.Event, error) {
+	// We only need to set values that are converted from a raw database format.
+	if r.PayloadRaw != nil {
+		err := json.Unmarshal(r.PayloadRaw, &r.Event.Payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.Event, nil
+}
+
+func (rs *rawEvents) toEvents() ([]*model.Event, error) {
+	var events []*model.Event
+	for _, rawEvent := range *rs {
+		event, err := rawEvent.toEvent()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetEvents fetches the given page of events from the event log in the order
+// in which they were recorded.
+func (sqlStore *SQLStore) GetEvents(filter *model.EventFilter) ([]*model.Event, error) {
+	builder := eventSelect.
+		Where("Sequence > ?", filter.AfterSequence).
+		OrderBy("Sequence ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+	if filter.MaxSequence != 0 {
+		builder = builder.Where("Sequence <= ?", filter.MaxSequence)
+	}
+	if filter.ResourceID != "" {
+		builder = builder.Where("ResourceID = ?", filter.ResourceID)
+	}
+	if filter.Type != "" {
+		builder = builder.Where("Type = ?", filter.Type)
+	}
+	if filter.OwnerID != "" {
+		builder = builder.Where("OwnerID = ?", filter.OwnerID)
+	}
+	if filter.GroupID != "" {
+		builder = builder.Where("GroupID = ?", filter.GroupID)
+	}
+
+	var rawEvents rawEvents
+	err := sqlStore.selectBuilder(sqlStore.db, &rawEvents, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for events")
+	}
+
+	return rawEvents.toEvents()
+}
+
+// GetLatestResourceEvent fetches the most recently recorded event of the given
+// resource, or nil if no events were recorded for it.
+func (sqlStore *SQLStore) GetLatestResourceEvent(resourceID string) (*model.Event, error) {
+	var rawEvent rawEvent
+	err := sqlStore.getBuilder(sqlStore.db, &rawEvent,
+		eventSelect.
+			Where("ResourceID = ?", resourceID).
+			OrderBy("Sequence DESC").
+			Limit(1),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest event of resource")
+	}
+
+	return rawEvent.toEvent()
+}
+
+// GetEventSequences returns up to the given number of sequence numbers of the
+// events recorded after the event with the given sequence number, in order.
+func (sqlStore *SQLStore) GetEventSequences(afterSequence int64, limit int) ([]int64, error) {
+	var sequences []int64
+	err := sqlStore.selectBuilder(sqlStore.db, &sequences,
+		sq.Select("Sequence").
+			From("Event").
+			Where("Sequence > ?", afterSequence).
+			OrderBy("Sequence ASC").
+			Limit(uint64(limit)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for event sequences")
+	}
+
+	return sequences, nil
+}
+
+// DeleteEventsBefore permanently removes the events recorded before the given
+// time, returning the number of events removed. The latest event of each
+// resource is kept to preserve the time of its last state change.
+func (sqlStore *SQLStore) DeleteEventsBefore(before int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("Event").
+		Where("CreateAt < ?", before).
+		Where("EXISTS (SELECT 1 FROM Event AS Later WHERE Later.ResourceID = Event.ResourceID AND Later.Sequence > Event.Sequence)"),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete events")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted events")
+	}
+
+	return count, nil
+}
+
+// GetLatestEventSequence returns the sequence number of the most recently
+// recorded event, or 0 if the event log is empty.
+func (sqlStore *SQLStore) GetLatestEventSequence() (int64, error) {
+	var sequence int64
+	err := sqlStore.getBuilder(sqlStore.db, &sequence,
+		sq.Select("COALESCE(MAX(Sequence), 0)").From("Event"),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query for latest event sequence")
+	}
+
+	return sequence, nil
+}
+
+// CreateEvent records the given event in the event log, assigning it the next
+// sequence number.
+func (sqlStore *SQLStore) CreateEvent(event *model.Event) error {
+	event.CreateAt = GetMillis()
+
+	payloadJSON, err := json.Marshal(event.Payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event payload")
+	}
+
+	var groupID *string
+	if event.Payload.GroupID != nil && *event.Payload.GroupID != "" {
+		groupID = event.Payload.GroupID
+	}
+
+	builder := sq.
+		Insert("Event").
+		SetMap(map[string]interface{}{
+			"Type":       event.Payload.Type,
+			"ResourceID": event.Payload.ID,
+			"OwnerID":    event.Payload.OwnerID,
+			"GroupID":    groupID,
+			"PayloadRaw": payloadJSON,
+			"InstanceID": event.InstanceID,
+			"RequestID":  event.RequestID,
+			"CreateAt":   event.CreateAt,
+		})
+
+	// Postgres doesn't support retrieving the ID of the inserted row from the
+	// result, so it has to be returned by the insert statement instead.
+	if sqlStore.db.DriverName() == driverPostgres {
+		err = sqlStore.getBuilder(sqlStore.db, &event.Sequence, builder.Suffix("RETURNING Sequence"))
+		if err != nil {
+			return errors.Wrap(err, "failed to create event")
+		}
+
+		return nil
+	}
+
+	result, err := sqlStore.execBuilder(sqlStore.db, builder)
+	if err != nil {
+		return errors.Wrap(err, "failed to create event")
+	}
+	event.Sequence, err = result.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "failed to get event sequence")
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	t.Run("empty event log", func(t *testing.T) {
+		sequence, err := sqlStore.GetLatestEventSequence()
+		require.NoError(t, err)
+		require.EqualValues(t, 0, sequence)
+
+		events, err := sqlStore.GetEvents(&model.EventFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	groupID := model.NewID()
+	event1 := &model.Event{
+		Payload: &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeCluster,
+			NewState: model.ClusterStateStable,
+			OldState: model.ClusterStateCreationRequested,
+		},
+	}
+	event2 := &model.Event{
+		Payload: &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeInstallation,
+			NewState: model.InstallationStateStable,
+			OldState: model.InstallationStateCreationInProgress,
+			OwnerID:  "owner1",
+			GroupID:  &groupID,
+		},
+		InstanceID: "instance1",
+		RequestID:  "request1",
+	}
+	event3 := &model.Event{
+		Payload: &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeInstallation,
+			NewState: model.InstallationStateDeleted,
+			OldState: model.InstallationStateDeletionInProgress,
+			OwnerID:  "owner2",
+		},
+	}
+
+	for _, event := range []*model.Event{event1, event2, event3} {
+		err := sqlStore.CreateEvent(event)
+		require.NoError(t, err)
+		require.NotEqual(t, 0, event.Sequence)
+		require.NotEqual(t, 0, event.CreateAt)
+	}
+	require.True(t, event1.Sequence < event2.Sequence)
+	require.True(t, event2.Sequence < event3.Sequence)
+
+	t.Run("latest sequence", func(t *testing.T) {
+		sequence, err := sqlStore.GetLatestEventSequence()
+		require.NoError(t, err)
+		require.Equal(t, event3.Sequence, sequence)
+	})
+
+	t.Run("sequences", func(t *testing.T) {
+		sequences, err := sqlStore.GetEventSequences(event1.Sequence, 1)
+		require.NoError(t, err)
+		require.Equal(t, []int64{event2.Sequence}, sequences)
+
+		sequences, err = sqlStore.GetEventSequences(0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int64{event1.Sequence, event2.Sequence, event3.Sequence}, sequences)
+
+		sequences, err = sqlStore.GetEventSequences(event3.Sequence, 10)
+		require.NoError(t, err)
+		require.Empty(t, sequences)
+	})
+
+	testCases := []struct {
+		Description string
+		Filter      *model.EventFilter
+		Expected    []*model.Event
+	}{
+		{
+			"all",
+			&model.EventFilter{PerPage: model.AllPerPage},
+			[]*model.Event{event1, event2, event3},
+		},
+		{
+			"first page",
+			&model.EventFilter{PerPage: 2},
+			[]*model.Event{event1, event2},
+		},
+		{
+			"second page",
+			&model.EventFilter{Page: 1, PerPage: 2},
+			[]*model.Event{event3},
+		},
+		{
+			"after sequence",
+			&model.EventFilter{AfterSequence: event1.Sequence, PerPage: model.AllPerPage},
+			[]*model.Event{event2, event3},
+		},
+		{
+			"max sequence",
+			&model.EventFilter{AfterSequence: event1.Sequence, MaxSequence: event2.Sequence, PerPage: model.AllPerPage},
+			[]*model.Event{event2},
+		},
+		{
+			"resource",
+			&model.EventFilter{ResourceID: event2.Payload.ID, PerPage: model.AllPerPage},
+			[]*model.Event{event2},
+		},
+		{
+			"type",
+			&model.EventFilter{Type: model.TypeInstallation, PerPage: model.AllPerPage},
+			[]*model.Event{event2, event3},
+		},
+		{
+			"owner",
+			&model.EventFilter{OwnerID: "owner2", PerPage: model.AllPerPage},
+			[]*model.Event{event3},
+		},
+		{
+			"group",
+			&model.EventFilter{GroupID: groupID, PerPage: model.AllPerPage},
+			[]*model.Event{event2},
+		},
+		{
+			"no matches",
+			&model.EventFilter{AfterSequence: event3.Sequence, PerPage: model.AllPerPage},
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			events, err := sqlStore.GetEvents(testCase.Filter)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, events)
+		})
+	}
+}
+
+func TestDeleteEventsBefore(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	createEvent := func(resourceID string) *model.Event {
+		event := &model.Event{
+			Payload: &model.WebhookPayload{
+				Type: model.TypeInstallation,
+				ID:   resourceID,
+			},
+		}
+		err := sqlStore.CreateEvent(event)
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+
+		return event
+	}
+
+	resourceID1 := model.NewID()
+	resourceID2 := model.NewID()
+	createEvent(resourceID1)
+	createEvent(resourceID1)
+	createEvent(resourceID1)
+	latest2 := createEvent(resourceID2)
+	recent1 := createEvent(resourceID1)
+
+	count, err := sqlStore.DeleteEventsBefore(recent1.CreateAt)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+
+	events, err := sqlStore.GetEvents(&model.EventFilter{PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.Event{latest2, recent1}, events)
+
+	count, err = sqlStore.DeleteEventsBefore(GetMillis() + 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, count)
+}
@@ -17,7 +17,8 @@ var groupSelect sq.SelectBuilder
 
 type rawGroup struct {
 	*model.Group
-	MattermostEnvRaw []byte
+	MattermostEnvRaw       []byte
+	HibernationScheduleRaw []byte
 }
 
 type rawGroups []*rawGroup
@@ -26,7 +27,9 @@ func init() {
 	groupSelect = sq.
 		Select("ID", "Name", "Description", "Version", "Image", "Sequence",
 			"CreateAt", "DeleteAt", "MattermostEnvRaw", "MaxRolling",
-			"APISecurityLock", "LockAcquiredBy", "LockAcquiredAt").
+			"APISecurityLock", "LockAcquiredBy", "LockAcquiredAt",
+			"HibernationScheduleRaw", "IdleHibernationOptOut",
+			"IdleHibernationGracePeriodHours").
 		From(`"Group"`)
 }
 
@@ -42,6 +45,14 @@ func (r *rawGroup) toGroup() (*model.Group, error) {
 	}
 
 	r.Group.MattermostEnv = *mattermostEnv
+
+	if r.HibernationScheduleRaw != nil {
+		r.Group.HibernationSchedule, err = model.HibernationScheduleFromJSON(r.HibernationScheduleRaw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return r.Group, nil
 }
 
@@ -229,23 +240,30 @@ func (sqlStore *SQLStore) CreateGroup(group *model.Group) error {
 	if err != nil {
 		return err
 	}
+	scheduleJSON, err := group.HibernationSchedule.ToJSON()
+	if err != nil {
+		return err
+	}
 
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Insert(`"Group"`).
 		SetMap(map[string]interface{}{
-			"ID":               group.ID,
-			"Sequence":         0,
-			"Name":             group.Name,
-			"Image":            group.Image,
-			"Description":      group.Description,
-			"Version":          group.Version,
-			"MattermostEnvRaw": envVarMap,
-			"MaxRolling":       group.MaxRolling,
-			"CreateAt":         group.CreateAt,
-			"DeleteAt":         0,
-			"APISecurityLock":  group.APISecurityLock,
-			"LockAcquiredBy":   nil,
-			"LockAcquiredAt":   0,
+			"ID":                              group.ID,
+			"Sequence":                        0,
+			"Name":                            group.Name,
+			"Image":                           group.Image,
+			"Description":                     group.Description,
+			"Version":                         group.Version,
+			"MattermostEnvRaw":                envVarMap,
+			"MaxRolling":                      group.MaxRolling,
+			"CreateAt":                        group.CreateAt,
+			"DeleteAt":                        0,
+			"APISecurityLock":                 group.APISecurityLock,
+			"LockAcquiredBy":                  nil,
+			"LockAcquiredAt":                  0,
+			"HibernationScheduleRaw":          scheduleJSON,
+			"IdleHibernationOptOut":           group.IdleHibernationOptOut,
+			"IdleHibernationGracePeriodHours": group.IdleHibernationGracePeriodHours,
 		}),
 	)
 	if err != nil {
@@ -274,16 +292,23 @@ func (sqlStore *SQLStore) UpdateGroup(group *model.Group) error {
 	if err != nil {
 		return err
 	}
+	scheduleJSON, err := group.HibernationSchedule.ToJSON()
+	if err != nil {
+		return err
+	}
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Update(`"Group"`).
 		SetMap(map[string]interface{}{
-			"Sequence":         group.Sequence,
-			"Name":             group.Name,
-			"Description":      group.Description,
-			"Version":          group.Version,
-			"Image":            group.Image,
-			"MattermostEnvRaw": envVarMap,
-			"MaxRolling":       group.MaxRolling,
+			"Sequence":                        group.Sequence,
+			"Name":                            group.Name,
+			"Description":                     group.Description,
+			"Version":                         group.Version,
+			"Image":                           group.Image,
+			"MattermostEnvRaw":                envVarMap,
+			"MaxRolling":                      group.MaxRolling,
+			"HibernationScheduleRaw":          scheduleJSON,
+			"IdleHibernationOptOut":           group.IdleHibernationOptOut,
+			"IdleHibernationGracePeriodHours": group.IdleHibernationGracePeriodHours,
 		}).
 		Where("ID = ?", group.ID),
 	)
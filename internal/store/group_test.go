@@ -511,6 +511,16 @@ func TestUpdateGroup(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, oldSequence+1, group1.Sequence)
 
+	oldSequence = group1.Sequence
+	gracePeriod := int64(12)
+	group1.HibernationSchedule = &model.HibernationSchedule{HibernateCron: "0 20 * * *"}
+	group1.IdleHibernationOptOut = true
+	group1.IdleHibernationGracePeriodHours = &gracePeriod
+
+	err = sqlStore.UpdateGroup(group1)
+	require.NoError(t, err)
+	assert.Equal(t, oldSequence, group1.Sequence)
+
 	actualGroup1, err := sqlStore.GetGroup(group1.ID)
 	require.NoError(t, err)
 	assert.Equal(t, group1, actualGroup1)
@@ -21,14 +21,22 @@ func init() {
 			"ID", "OwnerID", "Version", "Image", "DNS", "Database", "Filestore", "Size",
 			"Affinity", "GroupID", "GroupSequence", "State", "License",
 			"MattermostEnvRaw", "CreateAt", "DeleteAt", "APISecurityLock",
-			"LockAcquiredBy", "LockAcquiredAt",
+			"LockAcquiredBy", "LockAcquiredAt", "HibernationScheduleRaw",
+			"LastActivityAt", "LastActivityCheckAt", "MigrationTargetClusterID",
+			"PlacementConstraintsRaw", "MigrationTargetDatabaseID",
+			"ExternalDatabaseSecretName", "EncryptedExternalDatabase",
+			"ExternalFilestoreConfigRaw", "EncryptedExternalFilestoreSecret",
+			"HibernationScheduleEvaluatedAt",
 		).
 		From("Installation")
 }
 
 type rawInstallation struct {
 	*model.Installation
-	MattermostEnvRaw []byte
+	MattermostEnvRaw           []byte
+	HibernationScheduleRaw     []byte
+	PlacementConstraintsRaw    []byte
+	ExternalFilestoreConfigRaw []byte
 }
 
 type rawInstallations []*rawInstallation
@@ -45,6 +53,28 @@ func (r *rawInstallation) toInstallation() (*model.Installation, error) {
 	}
 
 	r.Installation.MattermostEnv = *mattermostEnv
+
+	if r.HibernationScheduleRaw != nil {
+		r.Installation.HibernationSchedule, err = model.HibernationScheduleFromJSON(r.HibernationScheduleRaw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.PlacementConstraintsRaw != nil {
+		r.Installation.PlacementConstraints, err = model.PlacementConstraintsFromJSON(r.PlacementConstraintsRaw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.ExternalFilestoreConfigRaw != nil {
+		r.Installation.ExternalFilestoreConfig, err = model.ExternalFilestoreConfigFromJSON(r.ExternalFilestoreConfigRaw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return r.Installation, nil
 }
 
@@ -115,6 +145,9 @@ func (sqlStore *SQLStore) GetInstallations(filter *model.InstallationFilter, inc
 	if filter.DNS != "" {
 		builder = builder.Where("DNS = ?", filter.DNS)
 	}
+	if filter.State != "" {
+		builder = builder.Where("State = ?", filter.State)
+	}
 
 	var rawInstallations rawInstallations
 	err := sqlStore.selectBuilder(sqlStore.db, &rawInstallations, builder)
@@ -198,38 +231,89 @@ func (sqlStore *SQLStore) GetUnlockedInstallationsPendingWork() ([]*model.Instal
 	return installations, nil
 }
 
-// CreateInstallation records the given installation to the database, assigning it a unique ID.
+// GetUnlockedInstallationsWithHibernationSchedule returns the unlocked stable
+// or hibernating installations that have a hibernation schedule of their own
+// or belong to a group that has one.
+func (sqlStore *SQLStore) GetUnlockedInstallationsWithHibernationSchedule() ([]*model.Installation, error) {
+	builder := installationSelect.
+		Where(sq.Eq{
+			"State": []string{model.InstallationStateStable, model.InstallationStateHibernating},
+		}).
+		Where("DeleteAt = 0").
+		Where("LockAcquiredAt = 0").
+		Where(sq.Or{
+			sq.Expr("HibernationScheduleRaw IS NOT NULL"),
+			sq.Expr(`GroupID IN (SELECT ID FROM "Group" WHERE HibernationScheduleRaw IS NOT NULL AND DeleteAt = 0)`),
+		}).
+		OrderBy("CreateAt ASC")
+
+	var rawInstallations rawInstallations
+	err := sqlStore.selectBuilder(sqlStore.db, &rawInstallations, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get installations with hibernation schedule")
+	}
+
+	return rawInstallations.toInstallations()
+}
+
+// CreateInstallation records the given installation to the database, assigning it a unique ID
+// unless one was already picked.
 func (sqlStore *SQLStore) CreateInstallation(installation *model.Installation) error {
-	installation.ID = model.NewID()
+	if len(installation.ID) == 0 {
+		installation.ID = model.NewID()
+	}
 	installation.CreateAt = GetMillis()
 
 	envJSON, err := json.Marshal(installation.MattermostEnv)
 	if err != nil {
 		return errors.Wrap(err, "unable to marshal MattermostEnv")
 	}
+	scheduleJSON, err := installation.HibernationSchedule.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal HibernationSchedule")
+	}
+	constraintsJSON, err := installation.PlacementConstraints.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal PlacementConstraints")
+	}
+	externalFilestoreJSON, err := installation.ExternalFilestoreConfig.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal ExternalFilestoreConfig")
+	}
 
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Insert("Installation").
 		SetMap(map[string]interface{}{
-			"ID":               installation.ID,
-			"OwnerID":          installation.OwnerID,
-			"GroupID":          installation.GroupID,
-			"GroupSequence":    nil,
-			"Version":          installation.Version,
-			"Image":            installation.Image,
-			"DNS":              installation.DNS,
-			"Database":         installation.Database,
-			"Filestore":        installation.Filestore,
-			"Size":             installation.Size,
-			"Affinity":         installation.Affinity,
-			"State":            installation.State,
-			"License":          installation.License,
-			"MattermostEnvRaw": []byte(envJSON),
-			"CreateAt":         installation.CreateAt,
-			"DeleteAt":         0,
-			"APISecurityLock":  installation.APISecurityLock,
-			"LockAcquiredBy":   nil,
-			"LockAcquiredAt":   0,
+			"ID":                               installation.ID,
+			"OwnerID":                          installation.OwnerID,
+			"GroupID":                          installation.GroupID,
+			"GroupSequence":                    nil,
+			"Version":                          installation.Version,
+			"Image":                            installation.Image,
+			"DNS":                              installation.DNS,
+			"Database":                         installation.Database,
+			"Filestore":                        installation.Filestore,
+			"Size":                             installation.Size,
+			"Affinity":                         installation.Affinity,
+			"State":                            installation.State,
+			"License":                          installation.License,
+			"MattermostEnvRaw":                 []byte(envJSON),
+			"CreateAt":                         installation.CreateAt,
+			"DeleteAt":                         0,
+			"APISecurityLock":                  installation.APISecurityLock,
+			"LockAcquiredBy":                   nil,
+			"LockAcquiredAt":                   0,
+			"HibernationScheduleRaw":           scheduleJSON,
+			"LastActivityAt":                   installation.LastActivityAt,
+			"LastActivityCheckAt":              installation.LastActivityCheckAt,
+			"HibernationScheduleEvaluatedAt":   installation.HibernationScheduleEvaluatedAt,
+			"MigrationTargetClusterID":         installation.MigrationTargetClusterID,
+			"PlacementConstraintsRaw":          constraintsJSON,
+			"MigrationTargetDatabaseID":        installation.MigrationTargetDatabaseID,
+			"ExternalDatabaseSecretName":       installation.ExternalDatabaseSecretName,
+			"EncryptedExternalDatabase":        installation.EncryptedExternalDatabase,
+			"ExternalFilestoreConfigRaw":       externalFilestoreJSON,
+			"EncryptedExternalFilestoreSecret": installation.EncryptedExternalFilestoreSecret,
 		}),
 	)
 	if err != nil {
@@ -248,23 +332,35 @@ func (sqlStore *SQLStore) UpdateInstallation(installation *model.Installation) e
 	if err != nil {
 		return errors.Wrap(err, "unable to marshal MattermostEnv")
 	}
+	scheduleJSON, err := installation.HibernationSchedule.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal HibernationSchedule")
+	}
+	constraintsJSON, err := installation.PlacementConstraints.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal PlacementConstraints")
+	}
 
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Update("Installation").
 		SetMap(map[string]interface{}{
-			"OwnerID":          installation.OwnerID,
-			"GroupID":          installation.GroupID,
-			"GroupSequence":    installation.GroupSequence,
-			"Version":          installation.Version,
-			"Image":            installation.Image,
-			"DNS":              installation.DNS,
-			"Database":         installation.Database,
-			"Filestore":        installation.Filestore,
-			"Size":             installation.Size,
-			"Affinity":         installation.Affinity,
-			"License":          installation.License,
-			"MattermostEnvRaw": []byte(envJSON),
-			"State":            installation.State,
+			"OwnerID":                   installation.OwnerID,
+			"GroupID":                   installation.GroupID,
+			"GroupSequence":             installation.GroupSequence,
+			"Version":                   installation.Version,
+			"Image":                     installation.Image,
+			"DNS":                       installation.DNS,
+			"Database":                  installation.Database,
+			"Filestore":                 installation.Filestore,
+			"Size":                      installation.Size,
+			"Affinity":                  installation.Affinity,
+			"License":                   installation.License,
+			"MattermostEnvRaw":          []byte(envJSON),
+			"State":                     installation.State,
+			"HibernationScheduleRaw":    scheduleJSON,
+			"MigrationTargetClusterID":  installation.MigrationTargetClusterID,
+			"PlacementConstraintsRaw":   constraintsJSON,
+			"MigrationTargetDatabaseID": installation.MigrationTargetDatabaseID,
 		}).
 		Where("ID = ?", installation.ID),
 	)
@@ -297,6 +393,59 @@ func (sqlStore *SQLStore) UpdateInstallationGroupSequence(installation *model.In
 	return nil
 }
 
+// UpdateInstallationActivity updates the recorded activity of the given
+// installation.
+func (sqlStore *SQLStore) UpdateInstallationActivity(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"LastActivityAt":      installation.LastActivityAt,
+			"LastActivityCheckAt": installation.LastActivityCheckAt,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation activity")
+	}
+
+	return nil
+}
+
+// UpdateInstallationHibernationScheduleEvaluatedAt updates the time up to
+// which the hibernation schedule of the given installation was acted upon.
+func (sqlStore *SQLStore) UpdateInstallationHibernationScheduleEvaluatedAt(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"HibernationScheduleEvaluatedAt": installation.HibernationScheduleEvaluatedAt,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation hibernation schedule evaluation")
+	}
+
+	return nil
+}
+
+// UpdateInstallationMigrationTarget updates the migration target cluster and
+// database of the given installation.
+func (sqlStore *SQLStore) UpdateInstallationMigrationTarget(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"MigrationTargetClusterID":  installation.MigrationTargetClusterID,
+			"MigrationTargetDatabaseID": installation.MigrationTargetDatabaseID,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation migration target")
+	}
+
+	return nil
+}
+
 // UpdateInstallationState updates the given installation to a new state.
 func (sqlStore *SQLStore) UpdateInstallationState(installation *model.Installation) error {
 	_, err := sqlStore.execBuilder(sqlStore.db, sq.
@@ -0,0 +1,143 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var installationBackupSelect sq.SelectBuilder
+
+func init() {
+	installationBackupSelect = sq.
+		Select(
+			"ID", "InstallationID", "ClusterInstallationID", "RestoreInstallationID",
+			"State", "RequestAt", "CompleteAt", "LockAcquiredBy", "LockAcquiredAt",
+		).
+		From("InstallationBackup")
+}
+
+// GetInstallationBackup fetches the given installation backup by id.
+func (sqlStore *SQLStore) GetInstallationBackup(id string) (*model.InstallationBackup, error) {
+	var backup model.InstallationBackup
+	err := sqlStore.getBuilder(sqlStore.db, &backup,
+		installationBackupSelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation backup by id")
+	}
+
+	return &backup, nil
+}
+
+// GetInstallationBackups fetches the given page of installation backups. The
+// first page is 0.
+func (sqlStore *SQLStore) GetInstallationBackups(filter *model.InstallationBackupFilter) ([]*model.InstallationBackup, error) {
+	builder := installationBackupSelect.
+		OrderBy("RequestAt DESC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.InstallationID != "" {
+		builder = builder.Where("InstallationID = ?", filter.InstallationID)
+	}
+	if filter.State != "" {
+		builder = builder.Where("State = ?", filter.State)
+	}
+
+	var backups []*model.InstallationBackup
+	err := sqlStore.selectBuilder(sqlStore.db, &backups, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for installation backups")
+	}
+
+	return backups, nil
+}
+
+// GetUnlockedInstallationBackupsPendingWork returns unlocked installation
+// backups in a pending state.
+func (sqlStore *SQLStore) GetUnlockedInstallationBackupsPendingWork() ([]*model.InstallationBackup, error) {
+	builder := installationBackupSelect.
+		Where(sq.Eq{
+			"State": model.AllInstallationBackupStatesPendingWork,
+		}).
+		Where("LockAcquiredAt = 0").
+		OrderBy("RequestAt ASC")
+
+	var backups []*model.InstallationBackup
+	err := sqlStore.selectBuilder(sqlStore.db, &backups, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation backups pending work")
+	}
+
+	return backups, nil
+}
+
+// CreateInstallationBackup records the given installation backup to the
+// database, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateInstallationBackup(backup *model.InstallationBackup) error {
+	backup.ID = model.NewID()
+	backup.RequestAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("InstallationBackup").
+		SetMap(map[string]interface{}{
+			"ID":                    backup.ID,
+			"InstallationID":        backup.InstallationID,
+			"ClusterInstallationID": backup.ClusterInstallationID,
+			"RestoreInstallationID": backup.RestoreInstallationID,
+			"State":                 backup.State,
+			"RequestAt":             backup.RequestAt,
+			"CompleteAt":            backup.CompleteAt,
+			"LockAcquiredBy":        nil,
+			"LockAcquiredAt":        0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation backup")
+	}
+
+	return nil
+}
+
+// UpdateInstallationBackup updates the given installation backup in the
+// database.
+func (sqlStore *SQLStore) UpdateInstallationBackup(backup *model.InstallationBackup) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationBackup").
+		SetMap(map[string]interface{}{
+			"ClusterInstallationID": backup.ClusterInstallationID,
+			"RestoreInstallationID": backup.RestoreInstallationID,
+			"State":                 backup.State,
+			"CompleteAt":            backup.CompleteAt,
+		}).
+		Where("ID = ?", backup.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation backup")
+	}
+
+	return nil
+}
+
+// LockInstallationBackup marks the installation backup as locked for exclusive use by the caller.
+func (sqlStore *SQLStore) LockInstallationBackup(backupID, lockerID string) (bool, error) {
+	return sqlStore.lockRows("InstallationBackup", []string{backupID}, lockerID)
+}
+
+// UnlockInstallationBackup releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("InstallationBackup", []string{backupID}, lockerID, force)
+}
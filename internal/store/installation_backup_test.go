@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationBackups(t *testing.T) {
+	t.Run("get unknown installation backup", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		backup, err := sqlStore.GetInstallationBackup("unknown")
+		require.NoError(t, err)
+		require.Nil(t, backup)
+	})
+
+	t.Run("create, update and filter installation backups", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		installationID1 := model.NewID()
+		installationID2 := model.NewID()
+
+		backup1 := &model.InstallationBackup{
+			InstallationID:        installationID1,
+			ClusterInstallationID: model.NewID(),
+			State:                 model.InstallationBackupStateBackupRequested,
+		}
+		backup2 := &model.InstallationBackup{
+			InstallationID:        installationID2,
+			ClusterInstallationID: model.NewID(),
+			State:                 model.InstallationBackupStateBackupSucceeded,
+		}
+
+		err := sqlStore.CreateInstallationBackup(backup1)
+		require.NoError(t, err)
+		require.NotEmpty(t, backup1.ID)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateInstallationBackup(backup2)
+		require.NoError(t, err)
+
+		actualBackup1, err := sqlStore.GetInstallationBackup(backup1.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup1, actualBackup1)
+
+		backups, err := sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup2, backup1}, backups)
+
+		backups, err = sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{InstallationID: installationID1, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup1}, backups)
+
+		backups, err = sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{State: model.InstallationBackupStateBackupSucceeded, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup2}, backups)
+
+		backups, err = sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup1}, backups)
+
+		backup2.State = model.InstallationBackupStateRestoreRequested
+		backup2.RestoreInstallationID = installationID1
+		err = sqlStore.UpdateInstallationBackup(backup2)
+		require.NoError(t, err)
+
+		actualBackup2, err := sqlStore.GetInstallationBackup(backup2.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup2, actualBackup2)
+
+		backups, err = sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup1, backup2}, backups)
+	})
+
+	t.Run("lock installation backup", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		backup := &model.InstallationBackup{
+			InstallationID: model.NewID(),
+			State:          model.InstallationBackupStateBackupRequested,
+		}
+		err := sqlStore.CreateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		lockerID := model.NewID()
+		locked, err := sqlStore.LockInstallationBackup(backup.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		backups, err := sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, backups)
+
+		unlocked, err := sqlStore.UnlockInstallationBackup(backup.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+	})
+}
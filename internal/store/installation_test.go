@@ -717,6 +717,117 @@ func TestUpdateInstallationState(t *testing.T) {
 	assert.NotEqual(t, storedInstallation.Version, installation1.Version)
 }
 
+func TestUpdateInstallationHibernationScheduleEvaluatedAt(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID: model.NewID(),
+		Version: "version",
+		DNS:     "dns-schedule.example.com",
+		State:   model.InstallationStateStable,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installation1.HibernationScheduleEvaluatedAt = 1000
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationHibernationScheduleEvaluatedAt(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000, storedInstallation.HibernationScheduleEvaluatedAt)
+	assert.Equal(t, "version", storedInstallation.Version)
+}
+
+func TestUpdateInstallationActivity(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID: model.NewID(),
+		Version: "version",
+		DNS:     "dns4.example.com",
+		State:   model.InstallationStateStable,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installation1.LastActivityAt = 1000
+	installation1.LastActivityCheckAt = 2000
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationActivity(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000, storedInstallation.LastActivityAt)
+	assert.EqualValues(t, 2000, storedInstallation.LastActivityCheckAt)
+	assert.Equal(t, "version", storedInstallation.Version)
+
+	installations, err := sqlStore.GetInstallations(&model.InstallationFilter{
+		State:   model.InstallationStateStable,
+		PerPage: model.AllPerPage,
+	}, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Installation{storedInstallation}, installations)
+
+	installations, err = sqlStore.GetInstallations(&model.InstallationFilter{
+		State:   model.InstallationStateHibernating,
+		PerPage: model.AllPerPage,
+	}, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, installations)
+}
+
+func TestUpdateInstallationMigrationTarget(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:                  model.NewID(),
+		Version:                  "version",
+		DNS:                      "dns5.example.com",
+		State:                    model.InstallationStateMigrationRequested,
+		MigrationTargetClusterID: model.NewID(),
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, installation1.MigrationTargetClusterID, storedInstallation.MigrationTargetClusterID)
+
+	installation1.MigrationTargetClusterID = ""
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationMigrationTarget(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, storedInstallation.MigrationTargetClusterID)
+	assert.Equal(t, "version", storedInstallation.Version)
+
+	t.Run("target database", func(t *testing.T) {
+		installation1.MigrationTargetDatabaseID = "rds-cluster-multitenant-1234"
+
+		err = sqlStore.UpdateInstallationMigrationTarget(installation1)
+		require.NoError(t, err)
+
+		storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, "rds-cluster-multitenant-1234", storedInstallation.MigrationTargetDatabaseID)
+		assert.Empty(t, storedInstallation.MigrationTargetClusterID)
+	})
+}
+
 func TestDeleteInstallation(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
@@ -779,3 +890,168 @@ func TestDeleteInstallation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, installation1, actualInstallation1)
 }
+
+func TestGetUnlockedInstallationsWithHibernationSchedule(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	schedule := &model.HibernationSchedule{
+		HibernateCron: "0 20 * * 1-5",
+		WakeUpCron:    "0 7 * * 1-5",
+		TimeZone:      "Europe/Berlin",
+	}
+
+	group := &model.Group{
+		Name:                "group",
+		HibernationSchedule: schedule,
+	}
+	err := sqlStore.CreateGroup(group)
+	require.NoError(t, err)
+
+	createInstallation := func(dns, state string, groupID *string, schedule *model.HibernationSchedule) *model.Installation {
+		installation := &model.Installation{
+			OwnerID:             model.NewID(),
+			DNS:                 dns,
+			GroupID:             groupID,
+			State:               state,
+			HibernationSchedule: schedule,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		return installation
+	}
+
+	scheduledInstallation := createInstallation("dns1.example.com", model.InstallationStateStable, nil, schedule)
+	groupInstallation := createInstallation("dns2.example.com", model.InstallationStateHibernating, &group.ID, nil)
+	createInstallation("dns3.example.com", model.InstallationStateStable, nil, nil)
+	createInstallation("dns4.example.com", model.InstallationStateUpdateInProgress, nil, schedule)
+	lockedInstallation := createInstallation("dns5.example.com", model.InstallationStateStable, nil, schedule)
+
+	locked, err := sqlStore.LockInstallation(lockedInstallation.ID, model.NewID())
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	storedInstallation, err := sqlStore.GetInstallation(scheduledInstallation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, schedule, storedInstallation.HibernationSchedule)
+
+	installations, err := sqlStore.GetUnlockedInstallationsWithHibernationSchedule()
+	require.NoError(t, err)
+	require.Equal(t, []*model.Installation{scheduledInstallation, groupInstallation}, installations)
+
+	scheduledInstallation.HibernationSchedule = nil
+	err = sqlStore.UpdateInstallation(scheduledInstallation)
+	require.NoError(t, err)
+
+	group.HibernationSchedule = nil
+	err = sqlStore.UpdateGroup(group)
+	require.NoError(t, err)
+
+	installations, err = sqlStore.GetUnlockedInstallationsWithHibernationSchedule()
+	require.NoError(t, err)
+	require.Empty(t, installations)
+}
+
+func TestInstallationPlacementConstraints(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	constraints := &model.PlacementConstraints{
+		RequiredLabels:  map[string]string{"zone": "us-east-1b"},
+		PreferredLabels: map[string]string{"tier": "enterprise"},
+		AvoidClusterIDs: []string{model.NewID()},
+	}
+
+	installation := &model.Installation{
+		OwnerID:              model.NewID(),
+		DNS:                  "dns.example.com",
+		State:                model.InstallationStateCreationRequested,
+		PlacementConstraints: constraints,
+	}
+	err := sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, constraints, storedInstallation.PlacementConstraints)
+
+	installation.PlacementConstraints = nil
+	err = sqlStore.UpdateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Nil(t, storedInstallation.PlacementConstraints)
+}
+
+func TestInstallationExternalDatabase(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation := &model.Installation{
+		OwnerID:                    model.NewID(),
+		DNS:                        "dns.example.com",
+		Database:                   model.InstallationDatabaseExternal,
+		State:                      model.InstallationStateCreationRequested,
+		ExternalDatabaseSecretName: "customer-database",
+		EncryptedExternalDatabase:  []byte("encrypted"),
+	}
+	err := sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, "customer-database", storedInstallation.ExternalDatabaseSecretName)
+	require.Equal(t, []byte("encrypted"), storedInstallation.EncryptedExternalDatabase)
+
+	// The external database connection can't be changed once created.
+	installation.ExternalDatabaseSecretName = ""
+	installation.EncryptedExternalDatabase = nil
+	err = sqlStore.UpdateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, "customer-database", storedInstallation.ExternalDatabaseSecretName)
+	require.Equal(t, []byte("encrypted"), storedInstallation.EncryptedExternalDatabase)
+}
+
+func TestInstallationExternalFilestore(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	config := &model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		Prefix:      "customer1",
+		AccessKeyID: "access",
+	}
+
+	installation := &model.Installation{
+		OwnerID:                          model.NewID(),
+		DNS:                              "dns.example.com",
+		Filestore:                        model.InstallationFilestoreExternalS3,
+		State:                            model.InstallationStateCreationRequested,
+		ExternalFilestoreConfig:          config,
+		EncryptedExternalFilestoreSecret: []byte("encrypted"),
+	}
+	err := sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, config, storedInstallation.ExternalFilestoreConfig)
+	require.Equal(t, []byte("encrypted"), storedInstallation.EncryptedExternalFilestoreSecret)
+
+	// The external filestore bucket can't be changed once created.
+	installation.ExternalFilestoreConfig = nil
+	installation.EncryptedExternalFilestoreSecret = nil
+	err = sqlStore.UpdateInstallation(installation)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, config, storedInstallation.ExternalFilestoreConfig)
+	require.Equal(t, []byte("encrypted"), storedInstallation.EncryptedExternalFilestoreSecret)
+}
@@ -6,6 +6,7 @@ package store
 
 import (
 	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -23,16 +24,20 @@ func (sqlStore *SQLStore) lockRows(table string, ids []string, lockerID string)
 		}),
 	)
 	if err != nil {
+		metrics.LockFailures.WithLabelValues(table, metrics.LockFailureError).Inc()
 		return false, errors.Wrapf(err, "failed to lock %d rows in %s", len(ids), table)
 	}
 	count, err := result.RowsAffected()
 	if err != nil {
+		metrics.LockFailures.WithLabelValues(table, metrics.LockFailureError).Inc()
 		return false, errors.Wrap(err, "failed to count rows affected")
 	}
 
 	locked := false
 	if count > 0 {
 		locked = true
+	} else {
+		metrics.LockFailures.WithLabelValues(table, metrics.LockFailureContended).Inc()
 	}
 
 	if count > 0 && int(count) < len(ids) {
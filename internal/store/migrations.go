@@ -1113,6 +1113,409 @@ var migrations = []migration{
 			return err
 		}
 
+		return nil
+	}},
+	{semver.MustParse("0.21.0"), semver.MustParse("0.22.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE WebhookDelivery (
+				ID TEXT PRIMARY KEY,
+				WebhookID TEXT NOT NULL,
+				PayloadRaw BYTEA NOT NULL,
+				State TEXT NOT NULL,
+				Attempts INT NOT NULL,
+				LastAttemptAt BIGINT NOT NULL,
+				NextAttemptAt BIGINT NOT NULL,
+				LastError TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				LockAcquiredBy TEXT NULL,
+				LockAcquiredAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX WebhookDelivery_State ON WebhookDelivery (State);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.22.0"), semver.MustParse("0.23.0"), func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE Webhooks ADD COLUMN Secret TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.23.0"), semver.MustParse("0.24.0"), func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE Webhooks ADD COLUMN EventFilterRaw BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.24.0"), semver.MustParse("0.25.0"), func(e execer) error {
+		// The event log is ordered by an auto-incrementing sequence, which
+		// is declared differently for each database.
+		if e.DriverName() == driverPostgres {
+			_, err := e.Exec(`
+				CREATE TABLE Event (
+					Sequence BIGSERIAL PRIMARY KEY,
+					Type TEXT NOT NULL,
+					ResourceID TEXT NOT NULL,
+					OwnerID TEXT NOT NULL,
+					GroupID TEXT NULL,
+					PayloadRaw BYTEA NOT NULL,
+					CreateAt BIGINT NOT NULL
+				);
+			`)
+			if err != nil {
+				return err
+			}
+		} else if e.DriverName() == driverSqlite {
+			_, err := e.Exec(`
+				CREATE TABLE Event (
+					Sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+					Type TEXT NOT NULL,
+					ResourceID TEXT NOT NULL,
+					OwnerID TEXT NOT NULL,
+					GroupID TEXT NULL,
+					PayloadRaw BYTEA NOT NULL,
+					CreateAt BIGINT NOT NULL
+				);
+			`)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := e.Exec(`CREATE INDEX Event_ResourceID ON Event (ResourceID);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE Event ADD COLUMN InstanceID TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Event ADD COLUMN RequestID TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.26.0"), semver.MustParse("0.27.0"), func(e execer) error {
+		// Add API token table.
+		_, err := e.Exec(`
+			CREATE TABLE APIToken (
+				ID TEXT PRIMARY KEY,
+				Name TEXT NOT NULL,
+				OwnerID TEXT NOT NULL,
+				Scope TEXT NOT NULL,
+				TokenHash TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				DeleteAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX APIToken_TokenHash ON APIToken (TokenHash);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.27.0"), semver.MustParse("0.28.0"), func(e execer) error {
+		// Add installation backup table.
+		_, err := e.Exec(`
+			CREATE TABLE InstallationBackup (
+				ID TEXT PRIMARY KEY,
+				InstallationID TEXT NOT NULL,
+				ClusterInstallationID TEXT NOT NULL,
+				RestoreInstallationID TEXT NOT NULL,
+				State TEXT NOT NULL,
+				RequestAt BIGINT NOT NULL,
+				CompleteAt BIGINT NOT NULL,
+				LockAcquiredBy TEXT NULL,
+				LockAcquiredAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE INDEX InstallationBackup_InstallationID ON InstallationBackup (InstallationID);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.28.0"), semver.MustParse("0.29.0"), func(e execer) error {
+		// Add hibernation schedule columns for installations and groups.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN HibernationScheduleRaw BYTEA NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE "Group"
+				ADD COLUMN HibernationScheduleRaw BYTEA NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.29.0"), semver.MustParse("0.30.0"), func(e execer) error {
+		// Add activity columns for installations.
+		// Add idle hibernation columns for groups.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN LastActivityAt BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Installation ADD COLUMN LastActivityCheckAt BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE "Group" ADD COLUMN IdleHibernationOptOut BOOLEAN NOT NULL DEFAULT 'false';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE "Group" ADD COLUMN IdleHibernationGracePeriodHours BIGINT NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.30.0"), semver.MustParse("0.31.0"), func(e execer) error {
+		// Add migration target column for installations.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN MigrationTargetClusterID TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.31.0"), semver.MustParse("0.32.0"), func(e execer) error {
+		// Add drain columns for clusters.
+		_, err := e.Exec(`ALTER TABLE Cluster ADD COLUMN Draining BOOLEAN NOT NULL DEFAULT 'false';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Cluster ADD COLUMN DrainMaxRolling BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.32.0"), semver.MustParse("0.33.0"), func(e execer) error {
+		// Add cluster labels and installation placement constraints.
+		_, err := e.Exec(`ALTER TABLE Cluster ADD COLUMN LabelsRaw BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Installation ADD COLUMN PlacementConstraintsRaw BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.33.0"), semver.MustParse("0.34.0"), func(e execer) error {
+		// Add scheduling settings for clusters.
+		_, err := e.Exec(`ALTER TABLE Cluster ADD COLUMN ResourceThreshold BIGINT NULL;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Cluster ADD COLUMN ResourceThresholdScaleValue BIGINT NULL;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Cluster ADD COLUMN ScaleDownPolicy TEXT NOT NULL DEFAULT 'none';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.34.0"), semver.MustParse("0.35.0"), func(e execer) error {
+		// Record the utilization of clusters for scaling them down.
+		_, err := e.Exec(`ALTER TABLE Cluster ADD COLUMN LowUtilizationSince BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Cluster ADD COLUMN CapacityCheckAt BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.35.0"), semver.MustParse("0.36.0"), func(e execer) error {
+		// Store the kubeconfig of imported clusters.
+		_, err := e.Exec(`ALTER TABLE Cluster ADD COLUMN EncryptedKubeconfig BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.36.0"), semver.MustParse("0.37.0"), func(e execer) error {
+		// Track the lifecycle of multitenant databases created by the provisioner.
+		_, err := e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN State TEXT NOT NULL DEFAULT 'stable';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN Managed BOOLEAN NOT NULL DEFAULT 'false';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.37.0"), semver.MustParse("0.38.0"), func(e execer) error {
+		// Manage multitenant databases from the API.
+		_, err := e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN AllowInstallations BOOLEAN NOT NULL DEFAULT 'true';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN Draining BOOLEAN NOT NULL DEFAULT 'false';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN MaxInstallations BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN APISecurityLock BOOLEAN NOT NULL DEFAULT 'false';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.38.0"), semver.MustParse("0.39.0"), func(e execer) error {
+		// Add migration target database column for installations.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN MigrationTargetDatabaseID TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.39.0"), semver.MustParse("0.40.0"), func(e execer) error {
+		// Store the connection of installations using an external database.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN ExternalDatabaseSecretName TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Installation ADD COLUMN EncryptedExternalDatabase BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.40.0"), semver.MustParse("0.41.0"), func(e execer) error {
+		// Store the bucket of installations using an external S3 filestore.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN ExternalFilestoreConfigRaw BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Installation ADD COLUMN EncryptedExternalFilestoreSecret BYTEA NULL;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.41.0"), semver.MustParse("0.42.0"), func(e execer) error {
+		// Index the creation time of events to find the latest event of each
+		// resource, and to remove old events, without reading the whole
+		// event log.
+		_, err := e.Exec(`CREATE INDEX Event_ResourceID_CreateAt ON Event (ResourceID, CreateAt);`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`DROP INDEX Event_ResourceID;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX Event_CreateAt ON Event (CreateAt);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.42.0"), semver.MustParse("0.43.0"), func(e execer) error {
+		// Record how far the hibernation schedule of installations was acted
+		// upon.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN HibernationScheduleEvaluatedAt BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.43.0"), semver.MustParse("0.44.0"), func(e execer) error {
+		// Retire empty managed multitenant databases after a grace period and
+		// serialize their creation per VPC.
+		_, err := e.Exec(`ALTER TABLE MultitenantDatabase ADD COLUMN EmptySince BIGINT NOT NULL DEFAULT '0';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE TABLE MultitenantDatabaseVPC (
+				ID TEXT PRIMARY KEY,
+				LockAcquiredBy TEXT NULL,
+				LockAcquiredAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}},
 }
@@ -19,8 +19,9 @@ var multitenantDatabaseSelect sq.SelectBuilder
 
 func init() {
 	multitenantDatabaseSelect = sq.
-		Select("ID", "VpcID", "DatabaseType", "InstallationsRaw",
-			"CreateAt", "DeleteAt", "LockAcquiredBy", "LockAcquiredAt").
+		Select("ID", "VpcID", "DatabaseType", "State", "InstallationsRaw",
+			"Managed", "EmptySince", "AllowInstallations", "Draining", "MaxInstallations",
+			"CreateAt", "DeleteAt", "APISecurityLock", "LockAcquiredBy", "LockAcquiredAt").
 		From("MultitenantDatabase")
 }
 
@@ -80,6 +81,9 @@ func (sqlStore *SQLStore) GetMultitenantDatabases(filter *model.MultitenantDatab
 			Offset(uint64(filter.Page * filter.PerPage))
 	}
 
+	if !filter.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
 	if len(filter.InstallationID) > 0 {
 		builder = builder.
 			Where(sq.Like{"InstallationsRaw": fmt.Sprint("%", filter.InstallationID, "%")})
@@ -93,6 +97,9 @@ func (sqlStore *SQLStore) GetMultitenantDatabases(filter *model.MultitenantDatab
 	if len(filter.DatabaseType) > 0 {
 		builder = builder.Where(sq.Eq{"DatabaseType": filter.DatabaseType})
 	}
+	if filter.AllowInstallations {
+		builder = builder.Where(sq.Eq{"AllowInstallations": true})
+	}
 
 	var rawDatabases rawMultitenantDatabases
 
@@ -109,7 +116,7 @@ func (sqlStore *SQLStore) GetMultitenantDatabases(filter *model.MultitenantDatab
 	if filter.MaxInstallationsLimit != model.NoInstallationsLimit {
 		var filteredDatabases []*model.MultitenantDatabase
 		for _, database := range databases {
-			if len(database.Installations) < int(filter.MaxInstallationsLimit) {
+			if len(database.Installations) < database.InstallationLimit(filter.MaxInstallationsLimit) {
 				filteredDatabases = append(filteredDatabases, database)
 			}
 		}
@@ -144,6 +151,9 @@ func (sqlStore *SQLStore) CreateMultitenantDatabase(multitenantDatabase *model.M
 	}
 
 	multitenantDatabase.CreateAt = GetMillis()
+	if multitenantDatabase.State == "" {
+		multitenantDatabase.State = model.MultitenantDatabaseStateStable
+	}
 
 	envJSON, err := json.Marshal(multitenantDatabase.Installations)
 	if err != nil {
@@ -153,14 +163,21 @@ func (sqlStore *SQLStore) CreateMultitenantDatabase(multitenantDatabase *model.M
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Insert("MultitenantDatabase").
 		SetMap(map[string]interface{}{
-			"ID":               multitenantDatabase.ID,
-			"VpcID":            multitenantDatabase.VpcID,
-			"DatabaseType":     multitenantDatabase.DatabaseType,
-			"InstallationsRaw": []byte(envJSON),
-			"LockAcquiredBy":   nil,
-			"LockAcquiredAt":   0,
-			"CreateAt":         multitenantDatabase.CreateAt,
-			"DeleteAt":         0,
+			"ID":                 multitenantDatabase.ID,
+			"VpcID":              multitenantDatabase.VpcID,
+			"DatabaseType":       multitenantDatabase.DatabaseType,
+			"State":              multitenantDatabase.State,
+			"InstallationsRaw":   []byte(envJSON),
+			"Managed":            multitenantDatabase.Managed,
+			"EmptySince":         multitenantDatabase.EmptySince,
+			"AllowInstallations": multitenantDatabase.AllowInstallations,
+			"Draining":           multitenantDatabase.Draining,
+			"MaxInstallations":   multitenantDatabase.MaxInstallations,
+			"APISecurityLock":    multitenantDatabase.APISecurityLock,
+			"LockAcquiredBy":     nil,
+			"LockAcquiredAt":     0,
+			"CreateAt":           multitenantDatabase.CreateAt,
+			"DeleteAt":           0,
 		}),
 	)
 	if err != nil {
@@ -180,7 +197,12 @@ func (sqlStore *SQLStore) UpdateMultitenantDatabase(multitenantDatabase *model.M
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Update("MultitenantDatabase").
 		SetMap(map[string]interface{}{
-			"InstallationsRaw": []byte(envJSON),
+			"State":              multitenantDatabase.State,
+			"InstallationsRaw":   []byte(envJSON),
+			"EmptySince":         multitenantDatabase.EmptySince,
+			"AllowInstallations": multitenantDatabase.AllowInstallations,
+			"Draining":           multitenantDatabase.Draining,
+			"MaxInstallations":   multitenantDatabase.MaxInstallations,
 		}).
 		Where(sq.Eq{"ID": multitenantDatabase.ID}),
 	)
@@ -191,6 +213,25 @@ func (sqlStore *SQLStore) UpdateMultitenantDatabase(multitenantDatabase *model.M
 	return nil
 }
 
+// DeleteMultitenantDatabase marks the given multitenant database as deleted,
+// but does not remove the record from the database.
+func (sqlStore *SQLStore) DeleteMultitenantDatabase(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("MultitenantDatabase").
+		SetMap(map[string]interface{}{
+			"State":    model.MultitenantDatabaseStateDeleted,
+			"DeleteAt": GetMillis(),
+		}).
+		Where("ID = ?", id).
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark multitenant database as deleted")
+	}
+
+	return nil
+}
+
 // LockMultitenantDatabase marks the database cluster as locked for exclusive use by the caller.
 func (sqlStore *SQLStore) LockMultitenantDatabase(multitenantDatabaseID, lockerID string) (bool, error) {
 	return sqlStore.lockRows("MultitenantDatabase", []string{multitenantDatabaseID}, lockerID)
@@ -200,3 +241,50 @@ func (sqlStore *SQLStore) LockMultitenantDatabase(multitenantDatabaseID, lockerI
 func (sqlStore *SQLStore) UnlockMultitenantDatabase(multitenantDatabaseID, lockerID string, force bool) (bool, error) {
 	return sqlStore.unlockRows("MultitenantDatabase", []string{multitenantDatabaseID}, lockerID, force)
 }
+
+// LockMultitenantDatabaseVPC marks the VPC as locked for creating a
+// multitenant database in it by the caller.
+func (sqlStore *SQLStore) LockMultitenantDatabaseVPC(vpcID, lockerID string) (bool, error) {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("MultitenantDatabaseVPC").
+		SetMap(map[string]interface{}{
+			"ID":             vpcID,
+			"LockAcquiredBy": nil,
+			"LockAcquiredAt": 0,
+		}).
+		Suffix("ON CONFLICT DO NOTHING"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to store multitenant database VPC")
+	}
+
+	return sqlStore.lockRows("MultitenantDatabaseVPC", []string{vpcID}, lockerID)
+}
+
+// UnlockMultitenantDatabaseVPC releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockMultitenantDatabaseVPC(vpcID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("MultitenantDatabaseVPC", []string{vpcID}, lockerID, force)
+}
+
+// LockMultitenantDatabaseAPI locks updates to the multitenant database from the API.
+func (sqlStore *SQLStore) LockMultitenantDatabaseAPI(multitenantDatabaseID string) error {
+	return sqlStore.setMultitenantDatabaseAPILock(multitenantDatabaseID, true)
+}
+
+// UnlockMultitenantDatabaseAPI unlocks updates to the multitenant database from the API.
+func (sqlStore *SQLStore) UnlockMultitenantDatabaseAPI(multitenantDatabaseID string) error {
+	return sqlStore.setMultitenantDatabaseAPILock(multitenantDatabaseID, false)
+}
+
+func (sqlStore *SQLStore) setMultitenantDatabaseAPILock(multitenantDatabaseID string, lock bool) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("MultitenantDatabase").
+		Set("APISecurityLock", lock).
+		Where("ID = ?", multitenantDatabaseID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store multitenant database API lock")
+	}
+
+	return nil
+}
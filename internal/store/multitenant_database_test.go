@@ -252,3 +252,157 @@ func (s *TestMultitenantDatabaseSuite) TestGetDatabasesWithVpcIDFilter() {
 	s.Assert().Nil(databases)
 	s.Assert().Equal(0, len(databases))
 }
+
+func (s *TestMultitenantDatabaseSuite) TestCreateManaged() {
+	db := model.MultitenantDatabase{
+		ID:      "database_managed_id",
+		VpcID:   "vpc_id0",
+		State:   model.MultitenantDatabaseStateCreating,
+		Managed: true,
+	}
+	err := s.sqlStore.CreateMultitenantDatabase(&db)
+	s.Assert().NoError(err)
+
+	database, err := s.sqlStore.GetMultitenantDatabase(db.ID)
+	s.Assert().NoError(err)
+	s.Assert().Equal(model.MultitenantDatabaseStateCreating, database.State)
+	s.Assert().True(database.Managed)
+
+	database.State = model.MultitenantDatabaseStateStable
+	database.EmptySince = 1234
+	err = s.sqlStore.UpdateMultitenantDatabase(database)
+	s.Assert().NoError(err)
+
+	database, err = s.sqlStore.GetMultitenantDatabase(db.ID)
+	s.Assert().NoError(err)
+	s.Assert().Equal(model.MultitenantDatabaseStateStable, database.State)
+	s.Assert().Equal(int64(1234), database.EmptySince)
+}
+
+func (s *TestMultitenantDatabaseSuite) TestDelete() {
+	s.Assert().Equal(model.MultitenantDatabaseStateStable, s.database1.State)
+
+	err := s.sqlStore.DeleteMultitenantDatabase(s.database1.ID)
+	s.Assert().NoError(err)
+
+	database, err := s.sqlStore.GetMultitenantDatabase(s.database1.ID)
+	s.Assert().NoError(err)
+	s.Assert().Equal(model.MultitenantDatabaseStateDeleted, database.State)
+	s.Assert().NotZero(database.DeleteAt)
+
+	databases, err := s.sqlStore.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	s.Assert().NoError(err)
+	s.Assert().Equal(1, len(databases))
+	s.Assert().Equal(s.database2.ID, databases[0].ID)
+
+	databases, err = s.sqlStore.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+		IncludeDeleted:        true,
+	})
+	s.Assert().NoError(err)
+	s.Assert().Equal(2, len(databases))
+}
+
+func (s *TestMultitenantDatabaseSuite) TestUpdateManagement() {
+	s.database1.AllowInstallations = true
+	s.database1.Draining = true
+	s.database1.MaxInstallations = 20
+
+	err := s.sqlStore.UpdateMultitenantDatabase(s.database1)
+	s.Assert().NoError(err)
+
+	database, err := s.sqlStore.GetMultitenantDatabase(s.database1.ID)
+	s.Assert().NoError(err)
+	s.Assert().True(database.AllowInstallations)
+	s.Assert().True(database.Draining)
+	s.Assert().Equal(20, database.MaxInstallations)
+}
+
+func (s *TestMultitenantDatabaseSuite) TestGetAllowInstallationsConstraint() {
+	s.database2.AllowInstallations = true
+	err := s.sqlStore.UpdateMultitenantDatabase(s.database2)
+	s.Assert().NoError(err)
+
+	databases, err := s.sqlStore.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		AllowInstallations:    true,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	s.Assert().NoError(err)
+	s.Assert().Equal(1, len(databases))
+	s.Assert().Equal(s.database2.ID, databases[0].ID)
+}
+
+func (s *TestMultitenantDatabaseSuite) TestGetDatabaseInstallationLimit() {
+	// database2 holds 3 installations and can take more with its own limit.
+	s.database2.MaxInstallations = 4
+	err := s.sqlStore.UpdateMultitenantDatabase(s.database2)
+	s.Assert().NoError(err)
+
+	databases, err := s.sqlStore.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: 2,
+		PerPage:               model.AllPerPage,
+	})
+	s.Assert().NoError(err)
+	s.Assert().Equal(1, len(databases))
+	s.Assert().Equal(s.database2.ID, databases[0].ID)
+
+	// database1 holds 2 installations and is full with its own limit.
+	s.database1.MaxInstallations = 2
+	err = s.sqlStore.UpdateMultitenantDatabase(s.database1)
+	s.Assert().NoError(err)
+
+	databases, err = s.sqlStore.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: 10,
+		PerPage:               model.AllPerPage,
+	})
+	s.Assert().NoError(err)
+	s.Assert().Equal(1, len(databases))
+	s.Assert().Equal(s.database2.ID, databases[0].ID)
+}
+
+func (s *TestMultitenantDatabaseSuite) TestAPISecurityLock() {
+	err := s.sqlStore.LockMultitenantDatabaseAPI(s.database1.ID)
+	s.Assert().NoError(err)
+
+	database, err := s.sqlStore.GetMultitenantDatabase(s.database1.ID)
+	s.Assert().NoError(err)
+	s.Assert().True(database.APISecurityLock)
+
+	err = s.sqlStore.UnlockMultitenantDatabaseAPI(s.database1.ID)
+	s.Assert().NoError(err)
+
+	database, err = s.sqlStore.GetMultitenantDatabase(s.database1.ID)
+	s.Assert().NoError(err)
+	s.Assert().False(database.APISecurityLock)
+}
+
+func (s *TestMultitenantDatabaseSuite) TestVPCLock() {
+	locked, err := s.sqlStore.LockMultitenantDatabaseVPC("vpc_id0", s.lockerID)
+	s.Assert().NoError(err)
+	s.Assert().True(locked)
+
+	locked, err = s.sqlStore.LockMultitenantDatabaseVPC("vpc_id0", "other_locker")
+	s.Assert().NoError(err)
+	s.Assert().False(locked)
+
+	locked, err = s.sqlStore.LockMultitenantDatabaseVPC("vpc_id1", "other_locker")
+	s.Assert().NoError(err)
+	s.Assert().True(locked)
+
+	unlocked, err := s.sqlStore.UnlockMultitenantDatabaseVPC("vpc_id0", "other_locker", false)
+	s.Assert().NoError(err)
+	s.Assert().False(unlocked)
+
+	unlocked, err = s.sqlStore.UnlockMultitenantDatabaseVPC("vpc_id0", s.lockerID, false)
+	s.Assert().NoError(err)
+	s.Assert().True(unlocked)
+
+	locked, err = s.sqlStore.LockMultitenantDatabaseVPC("vpc_id0", "other_locker")
+	s.Assert().NoError(err)
+	s.Assert().True(locked)
+}
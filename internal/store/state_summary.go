@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// GetClusterStateSummaries returns the number of clusters that are not
+// deleted in each state.
+func (sqlStore *SQLStore) GetClusterStateSummaries() ([]*model.ResourceStateSummary, error) {
+	return sqlStore.getStateSummaries("Cluster")
+}
+
+// GetInstallationStateSummaries returns the number of installations that are
+// not deleted in each state.
+func (sqlStore *SQLStore) GetInstallationStateSummaries() ([]*model.ResourceStateSummary, error) {
+	return sqlStore.getStateSummaries("Installation")
+}
+
+// getStateSummaries summarizes the rows of the given table by state. The time
+// of the last state change of each row is taken from the event log, falling
+// back to the creation time of rows without any recorded events. Only the
+// events of rows that are not deleted are read, using the index on the
+// resource ID and creation time of events.
+func (sqlStore *SQLStore) getStateSummaries(table string) ([]*model.ResourceStateSummary, error) {
+	current := sq.
+		Select(
+			"State",
+			fmt.Sprintf("COALESCE((SELECT MAX(Event.CreateAt) FROM Event WHERE Event.ResourceID = %s.ID), %s.CreateAt) AS StateChangeAt", table, table),
+		).
+		From(table).
+		Where("DeleteAt = 0")
+
+	builder := sq.
+		Select("State", "COUNT(*) AS Count", "MIN(StateChangeAt) AS OldestStateChangeAt").
+		FromSelect(current, "CurrentState").
+		GroupBy("State").
+		OrderBy("State")
+
+	var summaries []*model.ResourceStateSummary
+	err := sqlStore.selectBuilder(sqlStore.db, &summaries, builder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query %s states", table)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSummaries(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	t.Run("no resources", func(t *testing.T) {
+		summaries, err := sqlStore.GetInstallationStateSummaries()
+		require.NoError(t, err)
+		require.Empty(t, summaries)
+
+		summaries, err = sqlStore.GetClusterStateSummaries()
+		require.NoError(t, err)
+		require.Empty(t, summaries)
+	})
+
+	createInstallation := func(dns, state string) *model.Installation {
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      dns,
+			Affinity: model.InstallationAffinityIsolated,
+			State:    state,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+
+		return installation
+	}
+
+	installation1 := createInstallation("dns1.example.com", model.InstallationStateStable)
+	installation2 := createInstallation("dns2.example.com", model.InstallationStateStable)
+	installation3 := createInstallation("dns3.example.com", model.InstallationStateCreationRequested)
+	installation4 := createInstallation("dns4.example.com", model.InstallationStateStable)
+
+	err := sqlStore.DeleteInstallation(installation4.ID)
+	require.NoError(t, err)
+
+	// The first installation changed its state after the second one was
+	// created, so the second one has been stable for the longest time.
+	err = sqlStore.CreateEvent(&model.Event{
+		Payload: &model.WebhookPayload{
+			Type:     model.TypeInstallation,
+			ID:       installation1.ID,
+			OldState: model.InstallationStateUpdateInProgress,
+			NewState: model.InstallationStateStable,
+		},
+	})
+	require.NoError(t, err)
+
+	cluster := &model.Cluster{
+		ProviderMetadataAWS:     &model.AWSMetadata{},
+		ProvisionerMetadataKops: &model.KopsMetadata{},
+		UtilityMetadata:         &model.UtilityMetadata{},
+		State:                   model.ClusterStateStable,
+	}
+	err = sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	t.Run("installations", func(t *testing.T) {
+		summaries, err := sqlStore.GetInstallationStateSummaries()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ResourceStateSummary{
+			{
+				State:               model.InstallationStateCreationRequested,
+				Count:               1,
+				OldestStateChangeAt: installation3.CreateAt,
+			},
+			{
+				State:               model.InstallationStateStable,
+				Count:               2,
+				OldestStateChangeAt: installation2.CreateAt,
+			},
+		}, summaries)
+	})
+
+	t.Run("clusters", func(t *testing.T) {
+		summaries, err := sqlStore.GetClusterStateSummaries()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ResourceStateSummary{
+			{
+				State:               model.ClusterStateStable,
+				Count:               1,
+				OldestStateChangeAt: cluster.CreateAt,
+			},
+		}, summaries)
+	})
+}
@@ -6,6 +6,7 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -14,15 +15,60 @@ import (
 
 var webhookSelect sq.SelectBuilder
 
+type rawWebhook struct {
+	*model.Webhook
+	EventFilterRaw []byte
+}
+
+type rawWebhooks []*rawWebhook
+
 func init() {
 	webhookSelect = sq.
-		Select("ID", "OwnerID", "URL", "CreateAt", "DeleteAt").From("Webhooks")
+		Select("ID", "OwnerID", "URL", "Secret", "EventFilterRaw", "CreateAt", "DeleteAt").
+		From("Webhooks")
+}
+
+func (r *rawWebhook) toWebhook() (*model.Webhook, error) {
+	// We only need to set values that are converted from a raw database format.
+	if r.EventFilterRaw != nil {
+		eventFilter := &model.WebhookEventFilter{}
+		err := json.Unmarshal(r.EventFilterRaw, eventFilter)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal webhook event filter")
+		}
+		r.Webhook.EventFilter = eventFilter
+	}
+
+	return r.Webhook, nil
+}
+
+func (rs *rawWebhooks) toWebhooks() ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	for _, rawWebhook := range *rs {
+		webhook, err := rawWebhook.toWebhook()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// marshalWebhookEventFilter returns the database representation of the given
+// event filter. Empty filters are stored as NULL.
+func marshalWebhookEventFilter(eventFilter *model.WebhookEventFilter) ([]byte, error) {
+	if eventFilter.IsEmpty() {
+		return nil, nil
+	}
+
+	return json.Marshal(eventFilter)
 }
 
 // GetWebhook fetches the given webhook by id.
 func (sqlStore *SQLStore) GetWebhook(id string) (*model.Webhook, error) {
-	var webhook model.Webhook
-	err := sqlStore.getBuilder(sqlStore.db, &webhook,
+	var rawWebhook rawWebhook
+	err := sqlStore.getBuilder(sqlStore.db, &rawWebhook,
 		webhookSelect.Where("ID = ?", id),
 	)
 	if err == sql.ErrNoRows {
@@ -31,7 +77,7 @@ func (sqlStore *SQLStore) GetWebhook(id string) (*model.Webhook, error) {
 		return nil, errors.Wrap(err, "failed to get webhook by id")
 	}
 
-	return &webhook, nil
+	return rawWebhook.toWebhook()
 }
 
 // GetWebhooks fetches the given page of created webhooks. The first page is 0.
@@ -52,13 +98,13 @@ func (sqlStore *SQLStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Web
 		builder = builder.Where("DeleteAt = 0")
 	}
 
-	var webhooks []*model.Webhook
-	err := sqlStore.selectBuilder(sqlStore.db, &webhooks, builder)
+	var rawWebhooks rawWebhooks
+	err := sqlStore.selectBuilder(sqlStore.db, &rawWebhooks, builder)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for webhooks")
 	}
 
-	return webhooks, nil
+	return rawWebhooks.toWebhooks()
 }
 
 // CreateWebhook records the given webhook to the database, assigning it a unique ID.
@@ -66,14 +112,21 @@ func (sqlStore *SQLStore) CreateWebhook(webhook *model.Webhook) error {
 	webhook.ID = model.NewID()
 	webhook.CreateAt = GetMillis()
 
-	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+	eventFilterJSON, err := marshalWebhookEventFilter(webhook.EventFilter)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal webhook event filter")
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Insert("Webhooks").
 		SetMap(map[string]interface{}{
-			"ID":       webhook.ID,
-			"OwnerID":  webhook.OwnerID,
-			"URL":      webhook.URL,
-			"CreateAt": webhook.CreateAt,
-			"DeleteAt": 0,
+			"ID":             webhook.ID,
+			"OwnerID":        webhook.OwnerID,
+			"URL":            webhook.URL,
+			"Secret":         webhook.Secret,
+			"EventFilterRaw": eventFilterJSON,
+			"CreateAt":       webhook.CreateAt,
+			"DeleteAt":       0,
 		}),
 	)
 	if err != nil {
@@ -83,6 +136,27 @@ func (sqlStore *SQLStore) CreateWebhook(webhook *model.Webhook) error {
 	return nil
 }
 
+// UpdateWebhook updates the given webhook in the database.
+func (sqlStore *SQLStore) UpdateWebhook(webhook *model.Webhook) error {
+	eventFilterJSON, err := marshalWebhookEventFilter(webhook.EventFilter)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal webhook event filter")
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Webhooks").
+		SetMap(map[string]interface{}{
+			"EventFilterRaw": eventFilterJSON,
+		}).
+		Where("ID = ?", webhook.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update webhook")
+	}
+
+	return nil
+}
+
 // DeleteWebhook marks the given webhook as deleted, but does not remove the
 // record from the database.
 func (sqlStore *SQLStore) DeleteWebhook(id string) error {
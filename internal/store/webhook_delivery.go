@@ -0,0 +1,213 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var webhookDeliverySelect sq.SelectBuilder
+
+func init() {
+	webhookDeliverySelect = sq.
+		Select(
+			"ID", "WebhookID", "PayloadRaw", "State", "Attempts", "LastAttemptAt",
+			"NextAttemptAt", "LastError", "CreateAt", "LockAcquiredBy", "LockAcquiredAt",
+		).
+		From("WebhookDelivery")
+}
+
+type rawWebhookDelivery struct {
+	*model.WebhookDelivery
+	PayloadRaw []byte
+}
+
+type rawWebhookDeliveries []*rawWebhookDelivery
+
+func (r *rawWebhookDelivery) toWebhookDelivery() (*model.WebhookDelivery, error) {
+	// We only need to set values that are converted from a raw database format.
+	if r.PayloadRaw != nil {
+		err := json.Unmarshal(r.PayloadRaw, &r.WebhookDelivery.Payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.WebhookDelivery, nil
+}
+
+func (rs *rawWebhookDeliveries) toWebhookDeliveries() ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	for _, rawDelivery := range *rs {
+		delivery, err := rawDelivery.toWebhookDelivery()
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDelivery fetches the given webhook delivery by id.
+func (sqlStore *SQLStore) GetWebhookDelivery(id string) (*model.WebhookDelivery, error) {
+	var rawDelivery rawWebhookDelivery
+	err := sqlStore.getBuilder(sqlStore.db, &rawDelivery,
+		webhookDeliverySelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook delivery by id")
+	}
+
+	return rawDelivery.toWebhookDelivery()
+}
+
+// GetWebhookDeliveries fetches the given page of webhook deliveries. The first
+// page is 0.
+func (sqlStore *SQLStore) GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.WebhookID != "" {
+		builder = builder.Where("WebhookID = ?", filter.WebhookID)
+	}
+	if filter.State != "" {
+		builder = builder.Where("State = ?", filter.State)
+	}
+
+	var rawDeliveries rawWebhookDeliveries
+	err := sqlStore.selectBuilder(sqlStore.db, &rawDeliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for webhook deliveries")
+	}
+
+	return rawDeliveries.toWebhookDeliveries()
+}
+
+// GetUnlockedWebhookDeliveriesPendingWork returns unlocked webhook deliveries
+// that are due for a delivery attempt.
+func (sqlStore *SQLStore) GetUnlockedWebhookDeliveriesPendingWork() ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		Where("State = ?", model.WebhookDeliveryStatePending).
+		Where("NextAttemptAt <= ?", GetMillis()).
+		Where("LockAcquiredAt = 0").
+		OrderBy("CreateAt ASC")
+
+	var rawDeliveries rawWebhookDeliveries
+	err := sqlStore.selectBuilder(sqlStore.db, &rawDeliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook deliveries pending work")
+	}
+
+	return rawDeliveries.toWebhookDeliveries()
+}
+
+// CreateWebhookDelivery records the given webhook delivery to the database,
+// assigning it a unique ID. New deliveries are immediately eligible for a
+// delivery attempt.
+func (sqlStore *SQLStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	delivery.ID = model.NewID()
+	delivery.CreateAt = GetMillis()
+	delivery.NextAttemptAt = delivery.CreateAt
+	if delivery.State == "" {
+		delivery.State = model.WebhookDeliveryStatePending
+	}
+
+	payloadJSON, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal webhook payload")
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"ID":             delivery.ID,
+			"WebhookID":      delivery.WebhookID,
+			"PayloadRaw":     payloadJSON,
+			"State":          delivery.State,
+			"Attempts":       delivery.Attempts,
+			"LastAttemptAt":  delivery.LastAttemptAt,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"LastError":      delivery.LastError,
+			"CreateAt":       delivery.CreateAt,
+			"LockAcquiredBy": nil,
+			"LockAcquiredAt": 0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook delivery")
+	}
+
+	return nil
+}
+
+// UpdateWebhookDelivery updates the delivery status of the given webhook
+// delivery in the database.
+func (sqlStore *SQLStore) UpdateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"State":         delivery.State,
+			"Attempts":      delivery.Attempts,
+			"LastAttemptAt": delivery.LastAttemptAt,
+			"NextAttemptAt": delivery.NextAttemptAt,
+			"LastError":     delivery.LastError,
+		}).
+		Where("ID = ?", delivery.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update webhook delivery")
+	}
+
+	return nil
+}
+
+// DeleteFinishedWebhookDeliveries permanently removes the unlocked webhook
+// deliveries that were delivered or moved to the dead letter state before the
+// given time, returning the number of deliveries removed.
+func (sqlStore *SQLStore) DeleteFinishedWebhookDeliveries(before int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("WebhookDelivery").
+		Where(sq.Eq{"State": []string{
+			model.WebhookDeliveryStateDelivered,
+			model.WebhookDeliveryStateDeadLetter,
+		}}).
+		Where("LastAttemptAt < ?", before).
+		Where("LockAcquiredAt = 0"),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete finished webhook deliveries")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted webhook deliveries")
+	}
+
+	return count, nil
+}
+
+// LockWebhookDelivery marks the webhook delivery as locked for exclusive use by the caller.
+func (sqlStore *SQLStore) LockWebhookDelivery(deliveryID, lockerID string) (bool, error) {
+	return sqlStore.lockRows("WebhookDelivery", []string{deliveryID}, lockerID)
+}
+
+// UnlockWebhookDelivery releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockWebhookDelivery(deliveryID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("WebhookDelivery", []string{deliveryID}, lockerID, force)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	t.Run("get unknown webhook delivery", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		delivery, err := sqlStore.GetWebhookDelivery("unknown")
+		require.NoError(t, err)
+		require.Nil(t, delivery)
+	})
+
+	t.Run("create, update and filter webhook deliveries", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		webhookID1 := model.NewID()
+		webhookID2 := model.NewID()
+
+		delivery1 := &model.WebhookDelivery{
+			WebhookID: webhookID1,
+			Payload: &model.WebhookPayload{
+				Timestamp: 100,
+				ID:        model.NewID(),
+				Type:      model.TypeInstallation,
+				NewState:  model.InstallationStateStable,
+				OldState:  model.InstallationStateCreationRequested,
+			},
+		}
+		delivery2 := &model.WebhookDelivery{
+			WebhookID: webhookID2,
+			Payload: &model.WebhookPayload{
+				Timestamp: 200,
+				ID:        model.NewID(),
+				Type:      model.TypeCluster,
+				NewState:  model.ClusterStateStable,
+				OldState:  model.ClusterStateCreationRequested,
+			},
+		}
+
+		err := sqlStore.CreateWebhookDelivery(delivery1)
+		require.NoError(t, err)
+		require.NotEmpty(t, delivery1.ID)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery1.State)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateWebhookDelivery(delivery2)
+		require.NoError(t, err)
+
+		actualDelivery1, err := sqlStore.GetWebhookDelivery(delivery1.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery1, actualDelivery1)
+
+		deliveries, err := sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery1, delivery2}, deliveries)
+
+		deliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{WebhookID: webhookID2, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery2}, deliveries)
+
+		time.Sleep(1 * time.Millisecond)
+
+		deliveries, err = sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery1, delivery2}, deliveries)
+
+		delivery1.State = model.WebhookDeliveryStateDeadLetter
+		delivery1.Attempts = 5
+		delivery1.LastAttemptAt = GetMillis()
+		delivery1.LastError = "failed with status code 500"
+		err = sqlStore.UpdateWebhookDelivery(delivery1)
+		require.NoError(t, err)
+
+		deliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{State: model.WebhookDeliveryStateDeadLetter, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery1}, deliveries)
+
+		delivery2.NextAttemptAt = GetMillis() + 60*1000
+		err = sqlStore.UpdateWebhookDelivery(delivery2)
+		require.NoError(t, err)
+
+		deliveries, err = sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	})
+
+	t.Run("lock webhook delivery", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		delivery := &model.WebhookDelivery{
+			WebhookID: model.NewID(),
+			Payload:   &model.WebhookPayload{ID: model.NewID()},
+		}
+		err := sqlStore.CreateWebhookDelivery(delivery)
+		require.NoError(t, err)
+
+		lockerID := model.NewID()
+		locked, err := sqlStore.LockWebhookDelivery(delivery.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		time.Sleep(1 * time.Millisecond)
+
+		deliveries, err := sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		unlocked, err := sqlStore.UnlockWebhookDelivery(delivery.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+	})
+
+	t.Run("delete finished webhook deliveries", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		makeDelivery := func(state string) *model.WebhookDelivery {
+			delivery := &model.WebhookDelivery{
+				WebhookID: model.NewID(),
+				Payload:   &model.WebhookPayload{ID: model.NewID()},
+			}
+			err := sqlStore.CreateWebhookDelivery(delivery)
+			require.NoError(t, err)
+
+			delivery.State = state
+			delivery.Attempts = 1
+			delivery.LastAttemptAt = GetMillis()
+			err = sqlStore.UpdateWebhookDelivery(delivery)
+			require.NoError(t, err)
+
+			return delivery
+		}
+
+		delivered := makeDelivery(model.WebhookDeliveryStateDelivered)
+		deadLetter := makeDelivery(model.WebhookDeliveryStateDeadLetter)
+		pending := makeDelivery(model.WebhookDeliveryStatePending)
+		locked := makeDelivery(model.WebhookDeliveryStateDelivered)
+		_, err := sqlStore.LockWebhookDelivery(locked.ID, model.NewID())
+		require.NoError(t, err)
+
+		count, err := sqlStore.DeleteFinishedWebhookDeliveries(delivered.LastAttemptAt)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, count)
+
+		time.Sleep(1 * time.Millisecond)
+
+		count, err = sqlStore.DeleteFinishedWebhookDeliveries(GetMillis())
+		require.NoError(t, err)
+		require.EqualValues(t, 2, count)
+
+		for _, delivery := range []*model.WebhookDelivery{delivered, deadLetter} {
+			actualDelivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+			require.NoError(t, err)
+			require.Nil(t, actualDelivery)
+		}
+		for _, delivery := range []*model.WebhookDelivery{pending, locked} {
+			actualDelivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+			require.NoError(t, err)
+			require.NotNil(t, actualDelivery)
+		}
+	})
+}
@@ -35,6 +35,7 @@ func TestWebhooks(t *testing.T) {
 		webhook2 := &model.Webhook{
 			OwnerID: "owner2",
 			URL:     "https://url2.com",
+			Secret:  "secret",
 		}
 
 		err := sqlStore.CreateWebhook(webhook1)
@@ -143,4 +144,43 @@ func TestWebhooks(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, webhook1, actualWebhook1)
 	})
+
+	t.Run("update webhook event filter", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		webhook := &model.Webhook{
+			OwnerID: "owner1",
+			URL:     "https://url1.com",
+			EventFilter: &model.WebhookEventFilter{
+				Types: []string{model.TypeInstallation},
+			},
+		}
+
+		err := sqlStore.CreateWebhook(webhook)
+		require.NoError(t, err)
+
+		actualWebhook, err := sqlStore.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, webhook, actualWebhook)
+
+		webhook.EventFilter = &model.WebhookEventFilter{
+			NewStates: []string{model.InstallationStateStable, model.InstallationStateDeleted},
+			OwnerID:   "owner2",
+		}
+		err = sqlStore.UpdateWebhook(webhook)
+		require.NoError(t, err)
+
+		actualWebhook, err = sqlStore.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, webhook, actualWebhook)
+
+		webhook.EventFilter = nil
+		err = sqlStore.UpdateWebhook(webhook)
+		require.NoError(t, err)
+
+		actualWebhooks, err := sqlStore.GetWebhooks(&model.WebhookFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Webhook{webhook}, actualWebhooks)
+	})
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,6 +25,9 @@ type clusterStore interface {
 	DeleteCluster(clusterID string) error
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
 }
 
 // clusterProvisioner abstracts the provisioning operations required by the cluster supervisor.
@@ -34,29 +38,33 @@ type clusterProvisioner interface {
 	UpgradeCluster(cluster *model.Cluster) error
 	ResizeCluster(cluster *model.Cluster) error
 	DeleteCluster(cluster *model.Cluster, aws aws.AWS) error
-	RefreshKopsMetadata(cluster *model.Cluster) error
+	RefreshClusterMetadata(cluster *model.Cluster) error
 }
 
+// ClusterProvisioners maps each supported value of model.Cluster.Provisioner
+// to the provisioner responsible for clusters created with it.
+type ClusterProvisioners map[string]clusterProvisioner
+
 // ClusterSupervisor finds clusters pending work and effects the required changes.
 //
 // The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
 // other clients needing to coordinate background jobs.
 type ClusterSupervisor struct {
-	store       clusterStore
-	provisioner clusterProvisioner
-	aws         aws.AWS
-	instanceID  string
-	logger      log.FieldLogger
+	store        clusterStore
+	provisioners ClusterProvisioners
+	aws          aws.AWS
+	instanceID   string
+	logger       log.FieldLogger
 }
 
 // NewClusterSupervisor creates a new ClusterSupervisor.
-func NewClusterSupervisor(store clusterStore, clusterProvisioner clusterProvisioner, aws aws.AWS, instanceID string, logger log.FieldLogger) *ClusterSupervisor {
+func NewClusterSupervisor(store clusterStore, provisioners ClusterProvisioners, aws aws.AWS, instanceID string, logger log.FieldLogger) *ClusterSupervisor {
 	return &ClusterSupervisor{
-		store:       store,
-		provisioner: clusterProvisioner,
-		aws:         aws,
-		instanceID:  instanceID,
-		logger:      logger,
+		store:        store,
+		provisioners: provisioners,
+		aws:          aws,
+		instanceID:   instanceID,
+		logger:       logger,
 	}
 }
 
@@ -136,7 +144,7 @@ func (s *ClusterSupervisor) Supervise(cluster *model.Cluster) {
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -146,29 +154,51 @@ func (s *ClusterSupervisor) Supervise(cluster *model.Cluster) {
 
 // Do works with the given cluster to transition it to a final state.
 func (s *ClusterSupervisor) transitionCluster(cluster *model.Cluster, logger log.FieldLogger) string {
+	provisioner, err := s.getProvisioner(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster provisioner")
+		return cluster.State
+	}
+
 	switch cluster.State {
 	case model.ClusterStateCreationRequested:
-		return s.createCluster(cluster, logger)
+		return s.createCluster(cluster, provisioner, logger)
 	case model.ClusterStateProvisioningRequested:
-		return s.provisionCluster(cluster, logger)
+		return s.provisionCluster(cluster, provisioner, logger)
 	case model.ClusterStateUpgradeRequested:
-		return s.upgradeCluster(cluster, logger)
+		return s.upgradeCluster(cluster, provisioner, logger)
 	case model.ClusterStateResizeRequested:
-		return s.resizeCluster(cluster, logger)
+		return s.resizeCluster(cluster, provisioner, logger)
 	case model.ClusterStateRefreshMetadata:
-		return s.refreshClusterMetadata(cluster, logger)
+		return s.refreshClusterMetadata(cluster, provisioner, logger)
 	case model.ClusterStateDeletionRequested:
-		return s.deleteCluster(cluster, logger)
+		return s.deleteCluster(cluster, provisioner, logger)
 	default:
 		logger.Warnf("Found cluster pending work in unexpected state %s", cluster.State)
 		return cluster.State
 	}
 }
 
-func (s *ClusterSupervisor) createCluster(cluster *model.Cluster, logger log.FieldLogger) string {
+// getProvisioner returns the provisioner responsible for the given cluster.
+// Clusters created before the provisioner was recorded are managed by kops.
+func (s *ClusterSupervisor) getProvisioner(cluster *model.Cluster) (clusterProvisioner, error) {
+	name := cluster.Provisioner
+	if name == "" {
+		name = model.ProvisionerKops
+	}
+
+	provisioner, ok := s.provisioners[name]
+	if !ok {
+		return nil, errors.Errorf("no provisioner configured for %s clusters", name)
+	}
+
+	return provisioner, nil
+}
+
+func (s *ClusterSupervisor) createCluster(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
 	var err error
 
-	if s.provisioner.PrepareCluster(cluster) {
+	if provisioner.PrepareCluster(cluster) {
 		err = s.store.UpdateCluster(cluster)
 		if err != nil {
 			logger.WithError(err).Error("Failed to record updated cluster after creation")
@@ -176,60 +206,90 @@ func (s *ClusterSupervisor) createCluster(cluster *model.Cluster, logger log.Fie
 		}
 	}
 
-	err = s.provisioner.CreateCluster(cluster, s.aws)
+	// The infrastructure of external clusters is never managed here, so they
+	// only need to be provisioned.
+	if cluster.Provisioner == model.ProvisionerExternal {
+		logger.Info("Skipping creation of external cluster")
+		return s.provisionCluster(cluster, provisioner, logger)
+	}
+
+	err = provisioner.CreateCluster(cluster, s.aws)
 	if err != nil {
 		logger.WithError(err).Error("Failed to create cluster")
 		return model.ClusterStateCreationFailed
 	}
 
 	logger.Info("Finished creating cluster")
-	return s.provisionCluster(cluster, logger)
+	return s.provisionCluster(cluster, provisioner, logger)
 }
 
-func (s *ClusterSupervisor) provisionCluster(cluster *model.Cluster, logger log.FieldLogger) string {
-	err := s.provisioner.ProvisionCluster(cluster, s.aws)
+func (s *ClusterSupervisor) provisionCluster(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
+	err := provisioner.ProvisionCluster(cluster, s.aws)
 	if err != nil {
 		logger.WithError(err).Error("Failed to provision cluster")
 		return model.ClusterStateProvisioningFailed
 	}
 
 	logger.Info("Finished provisioning cluster")
-	return s.refreshClusterMetadata(cluster, logger)
+	return s.refreshClusterMetadata(cluster, provisioner, logger)
 }
 
-func (s *ClusterSupervisor) upgradeCluster(cluster *model.Cluster, logger log.FieldLogger) string {
-	err := s.provisioner.UpgradeCluster(cluster)
+func (s *ClusterSupervisor) upgradeCluster(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
+	if cluster.Provisioner == model.ProvisionerExternal {
+		logger.Info("Skipping upgrade of external cluster")
+		return s.refreshClusterMetadata(cluster, provisioner, logger)
+	}
+
+	err := provisioner.UpgradeCluster(cluster)
 	if err != nil {
 		logger.WithError(err).Error("Failed to upgrade cluster")
 		return model.ClusterStateUpgradeFailed
 	}
 
 	logger.Info("Finished upgrading cluster")
-	return s.refreshClusterMetadata(cluster, logger)
+	return s.refreshClusterMetadata(cluster, provisioner, logger)
 }
 
-func (s *ClusterSupervisor) resizeCluster(cluster *model.Cluster, logger log.FieldLogger) string {
-	err := s.provisioner.ResizeCluster(cluster)
+func (s *ClusterSupervisor) resizeCluster(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
+	if cluster.Provisioner == model.ProvisionerExternal {
+		logger.Info("Skipping resize of external cluster")
+		return s.refreshClusterMetadata(cluster, provisioner, logger)
+	}
+
+	err := provisioner.ResizeCluster(cluster)
 	if err != nil {
 		logger.WithError(err).Error("Failed to resize cluster")
 		return model.ClusterStateResizeFailed
 	}
 
 	logger.Info("Finished resizing cluster")
-	return s.refreshClusterMetadata(cluster, logger)
+	return s.refreshClusterMetadata(cluster, provisioner, logger)
 }
 
-func (s *ClusterSupervisor) refreshClusterMetadata(cluster *model.Cluster, logger log.FieldLogger) string {
+func (s *ClusterSupervisor) refreshClusterMetadata(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
 	if cluster.ProvisionerMetadataKops != nil {
 		cluster.ProvisionerMetadataKops.ClearChangeRequest()
 		cluster.ProvisionerMetadataKops.ClearWarnings()
 	}
+	if cluster.ProvisionerMetadataEKS != nil {
+		cluster.ProvisionerMetadataEKS.ClearChangeRequest()
+		cluster.ProvisionerMetadataEKS.ClearWarnings()
+	}
+	if cluster.ProvisionerMetadataLocal != nil {
+		cluster.ProvisionerMetadataLocal.ClearChangeRequest()
+	}
 
-	err := s.provisioner.RefreshKopsMetadata(cluster)
+	err := provisioner.RefreshClusterMetadata(cluster)
 	if err != nil {
 		logger.WithError(err).Error("Failed to refresh cluster")
 		return model.ClusterStateRefreshMetadata
 	}
+	if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.UsesSpotInstances() {
+		s.recordSpotInterruptions(cluster, logger)
+	}
+	if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.VPC == "" {
+		s.recordClaimedVPC(cluster, logger)
+	}
 	err = s.store.UpdateCluster(cluster)
 	if err != nil {
 		logger.WithError(err).Error("Failed to save updated cluster metadata")
@@ -239,8 +299,37 @@ func (s *ClusterSupervisor) refreshClusterMetadata(cluster *model.Cluster, logge
 	return model.ClusterStateStable
 }
 
-func (s *ClusterSupervisor) deleteCluster(cluster *model.Cluster, logger log.FieldLogger) string {
-	err := s.provisioner.DeleteCluster(cluster, s.aws)
+// recordSpotInterruptions adds a kops metadata warning for each interrupted
+// spot instance of the cluster.
+func (s *ClusterSupervisor) recordSpotInterruptions(cluster *model.Cluster, logger log.FieldLogger) {
+	warnings, err := s.aws.GetSpotInterruptionWarnings(cluster.ProvisionerMetadataKops.Name, logger)
+	if err != nil {
+		// Failing to check for interruptions shouldn't block the refresh.
+		logger.WithError(err).Warn("Failed to check for spot instance interruptions")
+		return
+	}
+
+	for _, warning := range warnings {
+		cluster.ProvisionerMetadataKops.AddWarning(warning)
+		logger.WithField("kops-metadata-error", warning).Warn("Encountered a spot instance interruption")
+	}
+}
+
+// recordClaimedVPC records the VPC claimed by a kops cluster created before
+// the VPC was kept in its metadata.
+func (s *ClusterSupervisor) recordClaimedVPC(cluster *model.Cluster, logger log.FieldLogger) {
+	vpcID, err := s.aws.GetClaimedVpcID(cluster.ID, logger)
+	if err != nil {
+		// Failing to look up the VPC shouldn't block the refresh.
+		logger.WithError(err).Warn("Failed to look up the VPC claimed by the cluster")
+		return
+	}
+
+	cluster.ProvisionerMetadataKops.VPC = vpcID
+}
+
+func (s *ClusterSupervisor) deleteCluster(cluster *model.Cluster, provisioner clusterProvisioner, logger log.FieldLogger) string {
+	err := provisioner.DeleteCluster(cluster, s.aws)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete cluster")
 		return model.ClusterStateDeletionFailed
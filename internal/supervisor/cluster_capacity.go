@@ -0,0 +1,315 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+)
+
+// clusterCapacityCheckInterval is the minimum time between two utilization
+// checks of the same cluster.
+const clusterCapacityCheckInterval = 10 * time.Minute
+
+// clusterCapacityStore abstracts the database operations required by the
+// cluster capacity supervisor.
+type clusterCapacityStore interface {
+	GetClusters(clusterFilter *model.ClusterFilter) ([]*model.Cluster, error)
+	GetCluster(id string) (*model.Cluster, error)
+	UpdateCluster(cluster *model.Cluster) error
+	UpdateClusterCapacityCheck(cluster *model.Cluster) error
+	LockCluster(clusterID, lockerID string) (bool, error)
+	UnlockCluster(clusterID, lockerID string, force bool) (bool, error)
+
+	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+}
+
+// clusterCapacityProvisioner abstracts the provisioning operations required
+// by the cluster capacity supervisor.
+type clusterCapacityProvisioner interface {
+	GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error)
+}
+
+// ClusterCapacitySupervisor records the utilization of stable clusters using
+// the utilization scale down policy, and requests a resize to fewer worker
+// nodes once the utilization has stayed below the floor for long enough.
+//
+// Clusters are never scaled below the resources requested by the
+// installations on them, and are kept under their resource threshold so that
+// the installation supervisor doesn't scale them right back up.
+type ClusterCapacitySupervisor struct {
+	store       clusterCapacityStore
+	provisioner clusterCapacityProvisioner
+	floor       int
+	period      time.Duration
+	threshold   int
+	instanceID  string
+	logger      log.FieldLogger
+}
+
+// NewClusterCapacitySupervisor creates a new ClusterCapacitySupervisor.
+// Clusters are scaled down once both their CPU and memory usage have stayed
+// below the floor percentage for the given period. The threshold is the
+// default resource threshold, which clusters may override.
+func NewClusterCapacitySupervisor(store clusterCapacityStore, provisioner clusterCapacityProvisioner, floor int, period time.Duration, threshold int, instanceID string, logger log.FieldLogger) *ClusterCapacitySupervisor {
+	return &ClusterCapacitySupervisor{
+		store:       store,
+		provisioner: provisioner,
+		floor:       floor,
+		period:      period,
+		threshold:   threshold,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the cluster capacity supervisor.
+func (s *ClusterCapacitySupervisor) Shutdown() {
+	s.logger.Debug("Shutting down cluster capacity supervisor")
+}
+
+// Do looks for clusters whose utilization is due to be checked and requests
+// the scale down of the underused ones.
+func (s *ClusterCapacitySupervisor) Do() error {
+	clusters, err := s.store.GetClusters(&model.ClusterFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: false,
+	})
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for clusters")
+		return nil
+	}
+
+	now := store.GetMillis()
+	for _, cluster := range clusters {
+		if cluster.State != model.ClusterStateStable ||
+			cluster.ScaleDownPolicy != model.ClusterScaleDownPolicyUtilization ||
+			cluster.LockAcquiredAt != 0 ||
+			now-cluster.CapacityCheckAt < clusterCapacityCheckInterval.Milliseconds() {
+			continue
+		}
+		s.Supervise(cluster)
+	}
+
+	return nil
+}
+
+// Supervise records the current utilization of the given cluster and requests
+// its scale down if it has been underused for long enough.
+func (s *ClusterCapacitySupervisor) Supervise(cluster *model.Cluster) {
+	logger := s.logger.WithFields(log.Fields{
+		"cluster": cluster.ID,
+	})
+
+	lock := newClusterLock(cluster.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	cluster, err := s.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed cluster")
+		return
+	}
+	if cluster == nil ||
+		cluster.State != model.ClusterStateStable ||
+		cluster.ScaleDownPolicy != model.ClusterScaleDownPolicyUtilization ||
+		cluster.Draining ||
+		cluster.ProvisionerMetadataKops == nil {
+		return
+	}
+	if len(cluster.ProvisionerMetadataKops.InstanceGroups) > 1 {
+		// The worker node count is only estimated for clusters with a single
+		// node pool.
+		logger.Debug("Skipping capacity check of cluster with several instance groups")
+		return
+	}
+
+	resources, err := s.provisioner.GetClusterResources(cluster, true)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster resources")
+		return
+	}
+
+	now := store.GetMillis()
+	cluster.CapacityCheckAt = now
+
+	cpuPercent := resources.CalculateCPUPercentUsed(0)
+	memoryPercent := resources.CalculateMemoryPercentUsed(0)
+	if cpuPercent >= s.floor || memoryPercent >= s.floor {
+		cluster.LowUtilizationSince = 0
+		s.updateCapacityCheck(cluster, logger)
+		return
+	}
+	if cluster.LowUtilizationSince == 0 {
+		cluster.LowUtilizationSince = now
+	}
+	if now-cluster.LowUtilizationSince < s.period.Milliseconds() {
+		s.updateCapacityCheck(cluster, logger)
+		return
+	}
+
+	nodeCount, err := s.getScaleDownNodeCount(cluster, resources)
+	if err != nil {
+		logger.WithError(err).Error("Failed to determine the worker node count to scale down to")
+		s.updateCapacityCheck(cluster, logger)
+		return
+	}
+	if nodeCount >= cluster.ProvisionerMetadataKops.NodeMinCount {
+		logger.Debugf("Cluster utilization is low (CPU=%d%%, Memory=%d%%), but no worker node can be removed", cpuPercent, memoryPercent)
+		s.updateCapacityCheck(cluster, logger)
+		return
+	}
+
+	// Restart the period so that the next scale down is only considered once
+	// the resized cluster has been underused for long enough.
+	cluster.LowUtilizationSince = 0
+	s.updateCapacityCheck(cluster, logger)
+
+	// Without an autoscaler the worker nodes above the minimum are only
+	// removed once the max count is lowered as well.
+	cluster.State = model.ClusterStateResizeRequested
+	cluster.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
+		NodeMinCount: nodeCount,
+		NodeMaxCount: nodeCount,
+	}
+
+	logger.Infof("Cluster utilization is low (CPU=%d%%, Memory=%d%%); scaling cluster worker nodes from %d to %d (max=%d to %d)",
+		cpuPercent, memoryPercent,
+		cluster.ProvisionerMetadataKops.NodeMinCount,
+		cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount,
+		cluster.ProvisionerMetadataKops.NodeMaxCount,
+		cluster.ProvisionerMetadataKops.ChangeRequest.NodeMaxCount,
+	)
+	err = s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update cluster")
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateResizeRequested,
+		OldState:  model.ClusterStateStable,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"ResizeReason": "low-utilization"},
+	}
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+func (s *ClusterCapacitySupervisor) updateCapacityCheck(cluster *model.Cluster, logger log.FieldLogger) {
+	err := s.store.UpdateClusterCapacityCheck(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record cluster capacity check")
+	}
+}
+
+// getScaleDownNodeCount returns the lowest worker node count able to host
+// both the resources currently in use and the resources requested by the
+// installations on the cluster while staying under the resource threshold.
+func (s *ClusterCapacitySupervisor) getScaleDownNodeCount(cluster *model.Cluster, resources *k8s.ClusterResources) (int64, error) {
+	cpuRequirement, memoryRequirement, err := s.getInstallationRequirements(cluster)
+	if err != nil {
+		return 0, err
+	}
+	if resources.MilliUsedCPU > cpuRequirement {
+		cpuRequirement = resources.MilliUsedCPU
+	}
+	if resources.MilliUsedMemory > memoryRequirement {
+		memoryRequirement = resources.MilliUsedMemory
+	}
+
+	threshold := s.threshold
+	if cluster.ResourceThreshold != nil {
+		threshold = *cluster.ResourceThreshold
+	}
+
+	// The totals are spread over the nodes currently running, which can be
+	// more than the minimum when the cluster was scaled up.
+	if resources.NodeCount < 1 {
+		return 0, errors.New("no schedulable worker nodes found")
+	}
+	cpuNodeCount := nodeCountFor(cpuRequirement, resources.MilliTotalCPU, resources.NodeCount, threshold)
+	memoryNodeCount := nodeCountFor(memoryRequirement, resources.MilliTotalMemory, resources.NodeCount, threshold)
+
+	if cpuNodeCount > memoryNodeCount {
+		return cpuNodeCount, nil
+	}
+
+	return memoryNodeCount, nil
+}
+
+// getInstallationRequirements returns the CPU and memory requested by the
+// installations on the cluster, including hibernated ones which may be woken
+// up at any time.
+func (s *ClusterCapacitySupervisor) getInstallationRequirements(cluster *model.Cluster) (int64, int64, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		ClusterID: cluster.ID,
+		PerPage:   model.AllPerPage,
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get cluster installations")
+	}
+
+	var cpuRequirement, memoryRequirement int64
+	for _, clusterInstallation := range clusterInstallations {
+		installation, err := s.store.GetInstallation(clusterInstallation.InstallationID, true, false)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to get installation")
+		}
+		if installation == nil {
+			return 0, 0, errors.Errorf("installation %s not found", clusterInstallation.InstallationID)
+		}
+
+		size, err := mmv1alpha1.GetClusterSize(installation.Size)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid size of installation %s", installation.ID)
+		}
+		cpuRequirement += size.CalculateCPUMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+		memoryRequirement += size.CalculateMemoryMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+	}
+
+	return cpuRequirement, memoryRequirement, nil
+}
+
+// nodeCountFor returns the number of worker nodes needed for the required
+// resources to stay under the threshold percentage of the total resources,
+// assuming the total is spread evenly over the current worker nodes.
+func nodeCountFor(required, total, currentNodeCount int64, threshold int) int64 {
+	if currentNodeCount < 1 || total < 1 {
+		return currentNodeCount
+	}
+
+	usablePerNode := total / currentNodeCount * int64(threshold) / 100
+	if usablePerNode < 1 {
+		return currentNodeCount
+	}
+
+	nodeCount := (required + usablePerNode - 1) / usablePerNode
+	if nodeCount < 1 {
+		nodeCount = 1
+	}
+
+	return nodeCount
+}
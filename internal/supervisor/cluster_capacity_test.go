@@ -0,0 +1,256 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClusterCapacityProvisioner struct {
+	Resources *k8s.ClusterResources
+}
+
+func (p *mockClusterCapacityProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
+	return p.Resources, nil
+}
+
+func TestClusterCapacitySupervisor(t *testing.T) {
+	setup := func(t *testing.T, scaleDownPolicy string) (*store.SQLStore, *model.Cluster) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{
+			State:                   model.ClusterStateStable,
+			ProvisionerMetadataKops: &model.KopsMetadata{NodeMinCount: 4, NodeMaxCount: 6},
+			ScaleDownPolicy:         scaleDownPolicy,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		return sqlStore, cluster
+	}
+
+	getCluster := func(t *testing.T, sqlStore *store.SQLStore, cluster *model.Cluster) *model.Cluster {
+		t.Helper()
+		cluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		return cluster
+	}
+
+	// Four nodes of 1000 cores each, with 10% in use.
+	lowResources := &k8s.ClusterResources{
+		MilliTotalCPU:    4000000,
+		MilliUsedCPU:     400000,
+		MilliTotalMemory: 4000000,
+		MilliUsedMemory:  400000,
+		NodeCount:        4,
+	}
+
+	t.Run("low utilization, first check", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, time.Hour, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.NotZero(t, cluster.LowUtilizationSince)
+		require.NotZero(t, cluster.CapacityCheckAt)
+	})
+
+	t.Run("low utilization, sustained", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, int64(1), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount)
+		require.Equal(t, int64(1), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMaxCount)
+		require.Zero(t, cluster.LowUtilizationSince)
+	})
+
+	t.Run("low utilization, cluster threshold", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		threshold := 20
+		cluster.ResourceThreshold = &threshold
+		err := sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, int64(2), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount)
+		require.Equal(t, int64(2), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMaxCount)
+	})
+
+	t.Run("low utilization, installation requirements", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			DNS:       "capacity.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			State:     model.InstallationStateHibernating,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+		err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		// Each node is exactly as large as the hibernated installation, which
+		// no longer uses any resources.
+		size, err := mmv1alpha1.GetClusterSize(installation.Size)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: &k8s.ClusterResources{
+			MilliTotalCPU:    4 * size.CalculateCPUMilliRequirement(false, false),
+			MilliTotalMemory: 4 * size.CalculateMemoryMilliRequirement(false, false),
+			NodeCount:        4,
+		}}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, int64(2), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount)
+		require.Equal(t, int64(2), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMaxCount)
+	})
+
+	t.Run("high utilization", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		cluster.LowUtilizationSince = 1000
+		err := sqlStore.UpdateClusterCapacityCheck(cluster)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: &k8s.ClusterResources{
+			MilliTotalCPU:    4000000,
+			MilliUsedCPU:     2000000,
+			MilliTotalMemory: 4000000,
+			MilliUsedMemory:  400000,
+			NodeCount:        4,
+		}}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Zero(t, cluster.LowUtilizationSince)
+		require.NotZero(t, cluster.CapacityCheckAt)
+	})
+
+	t.Run("low utilization, more nodes than the minimum", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+
+		// Eight nodes of 1000 cores each, with 25% in use, fit on three nodes.
+		provisioner := &mockClusterCapacityProvisioner{Resources: &k8s.ClusterResources{
+			MilliTotalCPU:    8000000,
+			MilliUsedCPU:     2000000,
+			MilliTotalMemory: 8000000,
+			MilliUsedMemory:  2000000,
+			NodeCount:        8,
+		}}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, int64(3), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount)
+		require.Equal(t, int64(3), cluster.ProvisionerMetadataKops.ChangeRequest.NodeMaxCount)
+	})
+
+	t.Run("no worker node to remove", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		cluster.ProvisionerMetadataKops.NodeMinCount = 1
+		err := sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.NotZero(t, cluster.LowUtilizationSince)
+	})
+
+	t.Run("scale down policy none", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyNone)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		err := capacitySupervisor.Do()
+		require.NoError(t, err)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Zero(t, cluster.CapacityCheckAt)
+	})
+
+	t.Run("several instance groups", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		cluster.ProvisionerMetadataKops.InstanceGroups = []*model.KopsInstanceGroup{
+			{Name: model.KopsInstanceGroupNodes, MinCount: 4, MaxCount: 6},
+			{Name: "memory", MinCount: 2, MaxCount: 2},
+		}
+		err := sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Zero(t, cluster.CapacityCheckAt)
+	})
+
+	t.Run("draining cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, cluster := setup(t, model.ClusterScaleDownPolicyUtilization)
+		cluster.Draining = true
+		err := sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+		provisioner := &mockClusterCapacityProvisioner{Resources: lowResources}
+
+		capacitySupervisor := supervisor.NewClusterCapacitySupervisor(sqlStore, provisioner, 30, 0, 80, model.NewID(), logger)
+		capacitySupervisor.Supervise(cluster)
+
+		cluster = getCluster(t, sqlStore, cluster)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+	})
+}
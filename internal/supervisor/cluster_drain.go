@@ -0,0 +1,327 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// clusterDrainStore abstracts the database operations required to drain
+// clusters.
+type clusterDrainStore interface {
+	GetUnlockedClustersDraining() ([]*model.Cluster, error)
+	GetCluster(clusterID string) (*model.Cluster, error)
+	UpdateCluster(cluster *model.Cluster) error
+	GetClusterDrainMetadata(clusterID string) (*store.ClusterDrainMetadata, error)
+	LockCluster(clusterID, lockerID string) (bool, error)
+	UnlockCluster(clusterID, lockerID string, force bool) (bool, error)
+
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	UpdateInstallation(installation *model.Installation) error
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+}
+
+// ClusterDrainSupervisor finds clusters that are being drained and moves their
+// installations to other clusters, no more than the max rolling count of the
+// cluster at a time.
+//
+// Hibernating installations are woken up first and moved once stable. The
+// installations themselves are moved by the installation supervisor.
+type ClusterDrainSupervisor struct {
+	store            clusterDrainStore
+	migrationTimeout time.Duration
+	instanceID       string
+	logger           log.FieldLogger
+}
+
+// NewClusterDrainSupervisor creates a new ClusterDrainSupervisor.
+// Installations for which no target cluster is found within the given
+// migration timeout are moved to migration-failed.
+func NewClusterDrainSupervisor(store clusterDrainStore, migrationTimeout time.Duration, instanceID string, logger log.FieldLogger) *ClusterDrainSupervisor {
+	return &ClusterDrainSupervisor{
+		store:            store,
+		migrationTimeout: migrationTimeout,
+		instanceID:       instanceID,
+		logger:           logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the cluster drain supervisor.
+func (s *ClusterDrainSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down cluster drain supervisor")
+}
+
+// Do looks for clusters being drained and attempts to schedule the required
+// work.
+func (s *ClusterDrainSupervisor) Do() error {
+	clusters, err := s.store.GetUnlockedClustersDraining()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for draining clusters")
+		return nil
+	}
+
+	for _, cluster := range clusters {
+		s.Supervise(cluster)
+	}
+
+	return nil
+}
+
+// Supervise schedules the required work on the given cluster.
+func (s *ClusterDrainSupervisor) Supervise(cluster *model.Cluster) {
+	logger := s.logger.WithFields(log.Fields{
+		"cluster": cluster.ID,
+	})
+
+	clusterLock := newClusterLock(cluster.ID, s.instanceID, s.store, logger)
+	if !clusterLock.TryLock() {
+		return
+	}
+	defer clusterLock.Unlock()
+
+	// Fetch the cluster again now that it is locked, in case the drain
+	// finished in the meantime.
+	cluster, err := s.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster")
+		return
+	}
+	if cluster == nil || !cluster.Draining {
+		return
+	}
+
+	logger.Debug("Supervising cluster drain")
+
+	drainMetadata, err := s.store.GetClusterDrainMetadata(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get installations on cluster")
+		return
+	}
+
+	// Installations that no cluster can host would otherwise keep counting
+	// towards the max rolling count forever.
+	for _, id := range drainMetadata.InstallationIDsAwaitingTarget {
+		if s.failStaleMigration(id, cluster, logger) {
+			drainMetadata.InstallationMigratingCount--
+			drainMetadata.InstallationFailedCount++
+			drainMetadata.InstallationIDsBlocked = append(drainMetadata.InstallationIDsBlocked, id)
+		}
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"maxRolling":                   cluster.DrainMaxRolling,
+		"installations-total":          drainMetadata.InstallationTotalCount,
+		"installations-migrating":      drainMetadata.InstallationMigratingCount,
+		"installations-updating":       drainMetadata.InstallationUpdatingCount,
+		"installations-hibernating":    drainMetadata.InstallationHibernatingCount,
+		"installations-failed":         drainMetadata.InstallationFailedCount,
+		"installations-not-migratable": drainMetadata.InstallationNotMigratableCount,
+	})
+
+	if drainMetadata.InstallationTotalCount == 0 {
+		cluster.Draining = false
+		err = s.store.UpdateCluster(cluster)
+		if err != nil {
+			logger.WithError(err).Error("Failed to mark cluster drain as finished")
+			return
+		}
+		logger.Info("Finished draining cluster")
+		return
+	}
+
+	if int64(len(drainMetadata.InstallationIDsBlocked)) == drainMetadata.InstallationTotalCount {
+		logger.Warnf("Cluster drain is blocked by installations to be moved or deleted by hand: %s", strings.Join(drainMetadata.InstallationIDsBlocked, ", "))
+		return
+	}
+
+	// Installations being updated are mostly ones woken up by the drain, which
+	// are migrated as soon as they are stable, so they count towards the max
+	// rolling count as well.
+	inFlight := drainMetadata.InstallationMigratingCount + drainMetadata.InstallationUpdatingCount
+	if inFlight >= cluster.DrainMaxRolling {
+		logger.Debugf("Cluster already has %d migrating or updating installations with a max of %d", inFlight, cluster.DrainMaxRolling)
+		return
+	}
+
+	var moved int64
+	for _, id := range drainMetadata.InstallationIDsToBeMigrated {
+		if inFlight+moved >= cluster.DrainMaxRolling {
+			break
+		}
+
+		if s.requestMigration(id, cluster, logger) {
+			moved++
+		}
+	}
+
+	var woken int64
+	for _, id := range drainMetadata.InstallationIDsToBeWokenUp {
+		if inFlight+moved+woken >= cluster.DrainMaxRolling {
+			break
+		}
+
+		if s.requestWakeUp(id, cluster, logger) {
+			woken++
+		}
+	}
+
+	logger.Infof("Moved %d installations to %s and woke up %d hibernating installations", moved, model.InstallationStateMigrationRequested, woken)
+}
+
+// requestMigration asks for the given installation to be moved off the
+// drained cluster to any cluster able to host it.
+func (s *ClusterDrainSupervisor) requestMigration(installationID string, cluster *model.Cluster, logger log.FieldLogger) bool {
+	logger = logger.WithField("installation", installationID)
+
+	installationLock := newInstallationLock(installationID, s.instanceID, s.store, logger)
+	if !installationLock.TryLock() {
+		return false
+	}
+	defer installationLock.Unlock()
+
+	installation, err := s.store.GetInstallation(installationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get installation to set new state")
+		return false
+	}
+	if installation == nil || !installation.ValidTransitionState(model.InstallationStateMigrationRequested) {
+		return false
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateMigrationRequested
+	installation.MigrationTargetClusterID = ""
+	err = s.store.UpdateInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Unable to set new installation state")
+		return false
+	}
+
+	s.sendInstallationWebhook(installation, oldState, cluster, logger)
+
+	return true
+}
+
+// failStaleMigration moves the given installation to migration-failed if no
+// target cluster was found for it within the migration timeout.
+func (s *ClusterDrainSupervisor) failStaleMigration(installationID string, cluster *model.Cluster, logger log.FieldLogger) bool {
+	logger = logger.WithField("installation", installationID)
+
+	installationLock := newInstallationLock(installationID, s.instanceID, s.store, logger)
+	if !installationLock.TryLock() {
+		return false
+	}
+	defer installationLock.Unlock()
+
+	installation, err := s.store.GetInstallation(installationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get installation to set new state")
+		return false
+	}
+	if installation == nil ||
+		installation.State != model.InstallationStateMigrationRequested ||
+		installation.MigrationTargetClusterID != "" {
+		return false
+	}
+
+	requestedAt, err := s.getMigrationRequestedAt(installation)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get the time the migration was requested")
+		return false
+	}
+	if store.GetMillis()-requestedAt < s.migrationTimeout.Milliseconds() {
+		return false
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateMigrationFailed
+	err = s.store.UpdateInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Unable to set new installation state")
+		return false
+	}
+
+	logger.Warnf("No cluster was found to host the installation within %s", s.migrationTimeout)
+	s.sendInstallationWebhook(installation, oldState, cluster, logger)
+
+	return true
+}
+
+// getMigrationRequestedAt returns the time at which the migration of the
+// installation was last requested.
+func (s *ClusterDrainSupervisor) getMigrationRequestedAt(installation *model.Installation) (int64, error) {
+	event, err := s.store.GetLatestResourceEvent(installation.ID)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil || event.Payload.NewState != model.InstallationStateMigrationRequested {
+		return installation.CreateAt, nil
+	}
+
+	return event.CreateAt, nil
+}
+
+// requestWakeUp wakes up the given hibernating installation so that it can be
+// moved off the drained cluster once stable.
+func (s *ClusterDrainSupervisor) requestWakeUp(installationID string, cluster *model.Cluster, logger log.FieldLogger) bool {
+	logger = logger.WithField("installation", installationID)
+
+	installationLock := newInstallationLock(installationID, s.instanceID, s.store, logger)
+	if !installationLock.TryLock() {
+		return false
+	}
+	defer installationLock.Unlock()
+
+	installation, err := s.store.GetInstallation(installationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get installation to set new state")
+		return false
+	}
+	if installation == nil || installation.State != model.InstallationStateHibernating {
+		return false
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateUpdateRequested
+	err = s.store.UpdateInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Unable to set new installation state")
+		return false
+	}
+
+	s.sendInstallationWebhook(installation, oldState, cluster, logger)
+
+	return true
+}
+
+func (s *ClusterDrainSupervisor) sendInstallationWebhook(installation *model.Installation, oldState string, cluster *model.Cluster, logger log.FieldLogger) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  installation.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"DrainedClusterID": cluster.ID},
+	}
+	err := webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
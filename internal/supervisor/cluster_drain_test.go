@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterDrainSupervisor(t *testing.T) {
+	setup := func(t *testing.T, maxRolling int64) (*store.SQLStore, *supervisor.ClusterDrainSupervisor, *model.Cluster) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		drainSupervisor := supervisor.NewClusterDrainSupervisor(sqlStore, time.Hour, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			State:           model.ClusterStateStable,
+			Draining:        true,
+			DrainMaxRolling: maxRolling,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		return sqlStore, drainSupervisor, cluster
+	}
+
+	createInstallation := func(t *testing.T, sqlStore *store.SQLStore, cluster *model.Cluster, state, database string) *model.Installation {
+		t.Helper()
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			DNS:       model.NewID() + ".example.com",
+			Database:  database,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			State:     state,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		return installation
+	}
+
+	expectInstallationStateCounts := func(t *testing.T, sqlStore *store.SQLStore, expectedStateCounts map[string]int) {
+		t.Helper()
+
+		installations, err := sqlStore.GetInstallations(&model.InstallationFilter{
+			PerPage: model.AllPerPage,
+		}, false, false)
+		require.NoError(t, err)
+
+		actualStateCounts := make(map[string]int)
+		for _, installation := range installations {
+			actualStateCounts[installation.State]++
+		}
+
+		require.Equal(t, expectedStateCounts, actualStateCounts)
+	}
+
+	expectDraining := func(t *testing.T, sqlStore *store.SQLStore, cluster *model.Cluster, draining bool) {
+		t.Helper()
+
+		cluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, draining, cluster.Draining)
+	}
+
+	t.Run("empty cluster", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+
+		drainSupervisor.Supervise(cluster)
+		expectDraining(t, sqlStore, cluster, false)
+	})
+
+	t.Run("stable installations, up to max rolling", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 2)
+		for i := 0; i < 3; i++ {
+			createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+		}
+
+		drainSupervisor.Supervise(cluster)
+		expectDraining(t, sqlStore, cluster, true)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationRequested: 2,
+			model.InstallationStateStable:             1,
+		})
+	})
+
+	t.Run("migrating installations at max rolling", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateMigrationInProgress, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		drainSupervisor.Supervise(cluster)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationInProgress: 1,
+			model.InstallationStateStable:              1,
+		})
+	})
+
+	t.Run("hibernating installations are woken up after stable ones are migrated", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 2)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateHibernating, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateHibernating, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		drainSupervisor.Supervise(cluster)
+		expectDraining(t, sqlStore, cluster, true)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationRequested: 1,
+			model.InstallationStateUpdateRequested:    1,
+			model.InstallationStateHibernating:        1,
+		})
+
+		metadata, err := sqlStore.GetClusterDrainMetadata(cluster.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, metadata.InstallationHibernatingCount)
+		require.EqualValues(t, 1, metadata.InstallationUpdatingCount)
+	})
+
+	t.Run("updating installations count towards max rolling", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateUpdateInProgress, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateHibernating, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		drainSupervisor.Supervise(cluster)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateUpdateInProgress: 1,
+			model.InstallationStateHibernating:      1,
+		})
+	})
+
+	t.Run("installation with data in the cluster", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+		installation := createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMysqlOperator)
+
+		drainSupervisor.Supervise(cluster)
+		expectDraining(t, sqlStore, cluster, true)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateStable: 1,
+		})
+
+		metadata, err := sqlStore.GetClusterDrainMetadata(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, []string{installation.ID}, metadata.InstallationIDsBlocked)
+	})
+
+	t.Run("installation without target cluster, within the migration timeout", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateMigrationRequested, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		drainSupervisor.Supervise(cluster)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationRequested: 1,
+			model.InstallationStateStable:             1,
+		})
+	})
+
+	t.Run("installation without target cluster, past the migration timeout", func(t *testing.T) {
+		sqlStore, _, cluster := setup(t, 1)
+		drainSupervisor := supervisor.NewClusterDrainSupervisor(sqlStore, 0, "instanceID", testlib.MakeLogger(t))
+		stuck := createInstallation(t, sqlStore, cluster, model.InstallationStateMigrationRequested, model.InstallationDatabaseMultiTenantRDSPostgres)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		drainSupervisor.Supervise(cluster)
+		expectDraining(t, sqlStore, cluster, true)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationFailed:    1,
+			model.InstallationStateMigrationRequested: 1,
+		})
+
+		stuck, err := sqlStore.GetInstallation(stuck.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateMigrationFailed, stuck.State)
+
+		metadata, err := sqlStore.GetClusterDrainMetadata(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, []string{stuck.ID}, metadata.InstallationIDsBlocked)
+	})
+
+	t.Run("installation with target cluster, past the migration timeout", func(t *testing.T) {
+		sqlStore, _, cluster := setup(t, 1)
+		drainSupervisor := supervisor.NewClusterDrainSupervisor(sqlStore, 0, "instanceID", testlib.MakeLogger(t))
+		installation := createInstallation(t, sqlStore, cluster, model.InstallationStateMigrationRequested, model.InstallationDatabaseMultiTenantRDSPostgres)
+		installation.MigrationTargetClusterID = model.NewID()
+		err := sqlStore.UpdateInstallationMigrationTarget(installation)
+		require.NoError(t, err)
+
+		drainSupervisor.Supervise(cluster)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateMigrationRequested: 1,
+		})
+	})
+
+	t.Run("cluster no longer draining", func(t *testing.T) {
+		sqlStore, drainSupervisor, cluster := setup(t, 1)
+		createInstallation(t, sqlStore, cluster, model.InstallationStateStable, model.InstallationDatabaseMultiTenantRDSPostgres)
+
+		cluster.Draining = false
+		err := sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+
+		drainSupervisor.Supervise(cluster)
+		expectInstallationStateCounts(t, sqlStore, map[string]int{
+			model.InstallationStateStable: 1,
+		})
+	})
+}
@@ -30,6 +30,9 @@ type clusterInstallationStore interface {
 	DeleteClusterInstallation(clusterInstallationID string) error
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
 }
 
 // provisioner abstracts the provisioning operations required by the cluster installation supervisor.
@@ -140,7 +143,7 @@ func (s *ClusterInstallationSupervisor) Supervise(clusterInstallation *model.Clu
 		Timestamp: time.Now().UnixNano(),
 		ExtraData: map[string]string{"ClusterID": clusterInstallation.ClusterID},
 	}
-	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
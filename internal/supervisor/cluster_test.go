@@ -60,25 +60,46 @@ func (s *mockClusterStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.We
 	return nil, nil
 }
 
-type mockClusterProvisioner struct{}
+func (s *mockClusterStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
+func (s *mockClusterStore) GetLatestResourceEvent(resourceID string) (*model.Event, error) {
+	return nil, nil
+}
+
+func (s *mockClusterStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
+type mockClusterProvisioner struct {
+	CreateClusterCalls    int
+	ProvisionClusterCalls int
+	UpgradeClusterCalls   int
+	ResizeClusterCalls    int
+}
 
 func (p *mockClusterProvisioner) PrepareCluster(cluster *model.Cluster) bool {
 	return true
 }
 
 func (p *mockClusterProvisioner) CreateCluster(cluster *model.Cluster, aws aws.AWS) error {
+	p.CreateClusterCalls++
 	return nil
 }
 
 func (p *mockClusterProvisioner) ProvisionCluster(cluster *model.Cluster, aws aws.AWS) error {
+	p.ProvisionClusterCalls++
 	return nil
 }
 
 func (p *mockClusterProvisioner) UpgradeCluster(cluster *model.Cluster) error {
+	p.UpgradeClusterCalls++
 	return nil
 }
 
 func (p *mockClusterProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	p.ResizeClusterCalls++
 	return nil
 }
 
@@ -86,7 +107,7 @@ func (p *mockClusterProvisioner) DeleteCluster(cluster *model.Cluster, aws aws.A
 	return nil
 }
 
-func (p *mockClusterProvisioner) RefreshKopsMetadata(cluster *model.Cluster) error {
+func (p *mockClusterProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
 	return nil
 }
 
@@ -95,7 +116,7 @@ func TestClusterSupervisorDo(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		mockStore := &mockClusterStore{}
 
-		supervisor := supervisor.NewClusterSupervisor(mockStore, &mockClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+		supervisor := supervisor.NewClusterSupervisor(mockStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, &mockAWS{}, "instanceID", logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -113,7 +134,7 @@ func TestClusterSupervisorDo(t *testing.T) {
 		mockStore.Cluster = mockStore.UnlockedClustersPendingWork[0]
 		mockStore.UnlockChan = make(chan interface{})
 
-		supervisor := supervisor.NewClusterSupervisor(mockStore, &mockClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+		supervisor := supervisor.NewClusterSupervisor(mockStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, &mockAWS{}, "instanceID", logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -141,7 +162,7 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		t.Run(tc.Description, func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewClusterSupervisor(sqlStore, &mockClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+			supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, &mockAWS{}, "instanceID", logger)
 
 			cluster := &model.Cluster{
 				Provider:                model.ProviderAWS,
@@ -159,10 +180,219 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		})
 	}
 
+	t.Run("refresh metadata, spot instance interruptions", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockAWS := &mockAWS{SpotInterruptionWarnings: []string{"Spot instance i-1 was interrupted"}}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, mockAWS, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{NodeSpotMaxPrice: "0.05"},
+			State:                   model.ClusterStateRefreshMetadata,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, []string{"Spot instance i-1 was interrupted"}, cluster.ProvisionerMetadataKops.Warnings)
+	})
+
+	t.Run("refresh metadata, on-demand instances", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockAWS := &mockAWS{SpotInterruptionWarnings: []string{"Spot instance i-1 was interrupted"}}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, mockAWS, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateRefreshMetadata,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Empty(t, cluster.ProvisionerMetadataKops.Warnings)
+	})
+
+	t.Run("refresh metadata, VPC not recorded", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockAWS := &mockAWS{ClaimedVPCs: make(map[string]string)}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, mockAWS, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateRefreshMetadata,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+		mockAWS.ClaimedVPCs[cluster.ID] = "vpc1"
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, "vpc1", cluster.VPC())
+	})
+
+	t.Run("creation requested, eks provisioner", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		kopsProvisioner := &mockClusterProvisioner{}
+		eksProvisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{
+			model.ProvisionerKops: kopsProvisioner,
+			model.ProvisionerEKS:  eksProvisioner,
+		}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:               model.ProviderAWS,
+			Provisioner:            model.ProvisionerEKS,
+			ProvisionerMetadataEKS: &model.EKSMetadata{},
+			State:                  model.ClusterStateCreationRequested,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, 1, eksProvisioner.CreateClusterCalls)
+		require.Equal(t, 0, kopsProvisioner.CreateClusterCalls)
+	})
+
+	t.Run("creation requested, external provisioner", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		externalProvisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{
+			model.ProvisionerKops:     &mockClusterProvisioner{},
+			model.ProvisionerExternal: externalProvisioner,
+		}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                    model.ProviderAWS,
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{},
+			State:                       model.ClusterStateCreationRequested,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, 0, externalProvisioner.CreateClusterCalls)
+		require.Equal(t, 1, externalProvisioner.ProvisionClusterCalls)
+	})
+
+	t.Run("creation requested, local provisioner", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		localProvisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{
+			model.ProvisionerLocal: localProvisioner,
+		}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:    model.ProviderAWS,
+			Provisioner: model.ProvisionerLocal,
+			ProvisionerMetadataLocal: &model.LocalMetadata{
+				ChangeRequest: &model.LocalMetadataRequestedState{Version: "latest"},
+			},
+			State: model.ClusterStateCreationRequested,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, 1, localProvisioner.CreateClusterCalls)
+		require.Equal(t, 1, localProvisioner.ProvisionClusterCalls)
+		require.Nil(t, cluster.ProvisionerMetadataLocal.ChangeRequest)
+	})
+
+	t.Run("upgrade and resize requested, external provisioner", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		externalProvisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{
+			model.ProvisionerExternal: externalProvisioner,
+		}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                    model.ProviderAWS,
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{},
+			State:                       model.ClusterStateUpgradeRequested,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+
+		cluster.State = model.ClusterStateResizeRequested
+		err = sqlStore.UpdateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, 0, externalProvisioner.UpgradeClusterCalls)
+		require.Equal(t, 0, externalProvisioner.ResizeClusterCalls)
+	})
+
+	t.Run("creation requested, provisioner not configured", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		kopsProvisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: kopsProvisioner}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:               model.ProviderAWS,
+			Provisioner:            model.ProvisionerEKS,
+			ProvisionerMetadataEKS: &model.EKSMetadata{},
+			State:                  model.ClusterStateCreationRequested,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateCreationRequested, cluster.State)
+		require.Equal(t, 0, kopsProvisioner.CreateClusterCalls)
+	})
+
 	t.Run("state has changed since cluster was selected to be worked on", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewClusterSupervisor(sqlStore, &mockClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, supervisor.ClusterProvisioners{model.ProvisionerKops: &mockClusterProvisioner{}}, &mockAWS{}, "instanceID", logger)
 
 		cluster := &model.Cluster{
 			Provider: model.ProviderAWS,
@@ -4,6 +4,12 @@
 
 package supervisor
 
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+)
+
 // Doer describes an action to be done.
 type Doer interface {
 	Do() error
@@ -31,3 +37,30 @@ func (md MultiDoer) Shutdown() {
 		doer.Shutdown()
 	}
 }
+
+// InstrumentedDoer wraps a doer, recording the duration and errors of each of
+// its executions under the given name.
+type InstrumentedDoer struct {
+	Doer
+	name string
+}
+
+// NewInstrumentedDoer creates a new InstrumentedDoer.
+func NewInstrumentedDoer(name string, doer Doer) *InstrumentedDoer {
+	return &InstrumentedDoer{
+		Doer: doer,
+		name: name,
+	}
+}
+
+// Do executes the wrapped doer and records the execution metrics.
+func (d *InstrumentedDoer) Do() error {
+	start := time.Now()
+	err := d.Doer.Do()
+	metrics.SupervisorDuration.WithLabelValues(d.name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.SupervisorErrors.WithLabelValues(d.name).Inc()
+	}
+
+	return err
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -86,3 +88,23 @@ func TestMultiDoer(t *testing.T) {
 		}
 	})
 }
+
+func TestInstrumentedDoer(t *testing.T) {
+	t.Run("failure", func(t *testing.T) {
+		doer := supervisor.NewInstrumentedDoer("test-failure", &failDoer{})
+
+		err := doer.Do()
+		require.EqualError(t, err, "failed")
+		require.Equal(t, float64(1), testutil.ToFloat64(metrics.SupervisorErrors.WithLabelValues("test-failure")))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		d := &testDoer{calls: make(chan bool, 1)}
+		doer := supervisor.NewInstrumentedDoer("test-success", d)
+
+		err := doer.Do()
+		require.NoError(t, err)
+		require.Len(t, d.calls, 1)
+		require.Equal(t, float64(0), testutil.ToFloat64(metrics.SupervisorErrors.WithLabelValues("test-success")))
+	})
+}
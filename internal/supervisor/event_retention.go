@@ -0,0 +1,66 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventRetentionCleanupInterval is the minimum delay between two removals of
+// the events past their retention period.
+const eventRetentionCleanupInterval = time.Hour
+
+// eventRetentionStore abstracts the database operations required by the event
+// retention supervisor.
+type eventRetentionStore interface {
+	DeleteEventsBefore(before int64) (int64, error)
+}
+
+// EventRetentionSupervisor removes the events of the event log that are older
+// than the retention period, keeping the latest event of each resource.
+type EventRetentionSupervisor struct {
+	store       eventRetentionStore
+	retention   time.Duration
+	lastCleanup time.Time
+	logger      log.FieldLogger
+}
+
+// NewEventRetentionSupervisor creates a new EventRetentionSupervisor.
+func NewEventRetentionSupervisor(store eventRetentionStore, retention time.Duration, logger log.FieldLogger) *EventRetentionSupervisor {
+	return &EventRetentionSupervisor{
+		store:     store,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the event retention supervisor.
+func (s *EventRetentionSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down event retention supervisor")
+}
+
+// Do removes the events past the retention period, at most once per cleanup
+// interval.
+func (s *EventRetentionSupervisor) Do() error {
+	if time.Since(s.lastCleanup) < eventRetentionCleanupInterval {
+		return nil
+	}
+	s.lastCleanup = time.Now()
+
+	before := store.GetMillis() - int64(s.retention/time.Millisecond)
+	count, err := s.store.DeleteEventsBefore(before)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to delete events past the retention period")
+		return nil
+	}
+	if count > 0 {
+		s.logger.Debugf("Deleted %d events past the retention period", count)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRetentionSupervisor(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	resourceID := model.NewID()
+	for i := 0; i < 3; i++ {
+		err := sqlStore.CreateEvent(&model.Event{
+			Payload: &model.WebhookPayload{
+				Type: model.TypeInstallation,
+				ID:   resourceID,
+			},
+		})
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	eventRetentionSupervisor := supervisor.NewEventRetentionSupervisor(sqlStore, time.Millisecond, logger)
+	err := eventRetentionSupervisor.Do()
+	require.NoError(t, err)
+
+	events, err := sqlStore.GetEvents(&model.EventFilter{ResourceID: resourceID, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// The cleanup interval has not elapsed, so newer events are kept.
+	err = sqlStore.CreateEvent(&model.Event{
+		Payload: &model.WebhookPayload{
+			Type: model.TypeInstallation,
+			ID:   resourceID,
+		},
+	})
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	err = eventRetentionSupervisor.Do()
+	require.NoError(t, err)
+
+	events, err = sqlStore.GetEvents(&model.EventFilter{ResourceID: resourceID, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+}
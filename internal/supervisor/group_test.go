@@ -71,6 +71,22 @@ func (s *mockGroupStore) UnlockInstallation(installationID, lockerID string, for
 	return true, nil
 }
 
+func (s *mockGroupStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error) {
+	return nil, nil
+}
+
+func (s *mockGroupStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
+func (s *mockGroupStore) GetLatestResourceEvent(resourceID string) (*model.Event, error) {
+	return nil, nil
+}
+
+func (s *mockGroupStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
 func TestGroupSupervisorDo(t *testing.T) {
 	t.Run("no groups pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
@@ -192,6 +208,15 @@ func TestGroupSupervisor(t *testing.T) {
 
 		supervisor.Supervise(group)
 		expectInstallations(t, sqlStore, 1, model.InstallationStateUpdateRequested)
+
+		events, err := sqlStore.GetEvents(&model.EventFilter{
+			ResourceID: installation.ID,
+			PerPage:    model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.InstallationStateUpdateRequested, events[0].Payload.NewState)
+		require.Equal(t, "instanceID", events[0].InstanceID)
 	})
 
 	t.Run("three installations, stable", func(t *testing.T) {
@@ -0,0 +1,223 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// hibernationScheduleStore abstracts the database operations required by the
+// hibernation schedule supervisor.
+type hibernationScheduleStore interface {
+	GetUnlockedInstallationsWithHibernationSchedule() ([]*model.Installation, error)
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	UpdateInstallationState(*model.Installation) error
+	UpdateInstallationHibernationScheduleEvaluatedAt(*model.Installation) error
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetGroup(groupID string) (*model.Group, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+}
+
+// HibernationScheduleSupervisor finds installations with a hibernation
+// schedule and requests hibernation or wake-up when a scheduled time passes.
+//
+// The time up to which the schedule of an installation was acted upon is
+// recorded on the installation, so that scheduled times passing while no
+// provisioner is running, or while the installation is busy, are acted upon
+// on a later run.
+type HibernationScheduleSupervisor struct {
+	store      hibernationScheduleStore
+	instanceID string
+	logger     log.FieldLogger
+}
+
+// NewHibernationScheduleSupervisor creates a new HibernationScheduleSupervisor.
+func NewHibernationScheduleSupervisor(store hibernationScheduleStore, instanceID string, logger log.FieldLogger) *HibernationScheduleSupervisor {
+	return &HibernationScheduleSupervisor{
+		store:      store,
+		instanceID: instanceID,
+		logger:     logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the hibernation schedule supervisor.
+func (s *HibernationScheduleSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down hibernation schedule supervisor")
+}
+
+// Do looks for installations with a scheduled hibernation or wake-up time
+// that passed since their schedule was last acted upon and requests the
+// scheduled state change.
+func (s *HibernationScheduleSupervisor) Do() error {
+	installations, err := s.store.GetUnlockedInstallationsWithHibernationSchedule()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installations with a hibernation schedule")
+		return nil
+	}
+
+	now := store.GetMillis()
+	for _, installation := range installations {
+		s.Supervise(installation, now)
+	}
+
+	return nil
+}
+
+// Supervise requests hibernation or wake-up of the given installation if its
+// hibernation schedule has a scheduled time between the time the schedule was
+// last evaluated and now, given in milliseconds. A scheduled action that
+// cannot be taken yet because the installation is locked or busy is retried
+// on the next run.
+func (s *HibernationScheduleSupervisor) Supervise(installation *model.Installation, now int64) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": installation.ID,
+	})
+
+	if installation.HibernationScheduleEvaluatedAt == 0 {
+		// The schedule is evaluated from the first time it is seen.
+		s.markEvaluated(installation, now, logger)
+		return
+	}
+
+	action, err := s.dueAction(installation, now)
+	if err != nil {
+		logger.WithError(err).Error("Failed to evaluate hibernation schedule")
+		return
+	}
+	if action == "" {
+		return
+	}
+
+	lock := newInstallationLock(installation.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	installation, err = s.store.GetInstallation(installation.ID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed installation")
+		return
+	}
+	if installation == nil {
+		return
+	}
+
+	// Another provisioner may have acted upon the schedule in the meantime.
+	action, err = s.dueAction(installation, now)
+	if err != nil {
+		logger.WithError(err).Error("Failed to evaluate hibernation schedule")
+		return
+	}
+	if action == "" {
+		return
+	}
+
+	if installation.APISecurityLock {
+		logger.Infof("Installation has an API security lock; skipping scheduled %s", action)
+		s.markEvaluated(installation, now, logger)
+		return
+	}
+
+	var newState string
+	switch action {
+	case model.HibernationScheduleActionHibernate:
+		switch installation.State {
+		case model.InstallationStateStable:
+			newState = model.InstallationStateHibernationRequested
+		case model.InstallationStateHibernationRequested,
+			model.InstallationStateHibernationInProgress,
+			model.InstallationStateHibernating:
+			s.markEvaluated(installation, now, logger)
+			return
+		default:
+			logger.Infof("Installation is in state %s; retrying scheduled hibernation later", installation.State)
+			return
+		}
+	case model.HibernationScheduleActionWakeUp:
+		switch installation.State {
+		case model.InstallationStateHibernating:
+			newState = model.InstallationStateUpdateRequested
+		case model.InstallationStateStable:
+			s.markEvaluated(installation, now, logger)
+			return
+		default:
+			logger.Infof("Installation is in state %s; retrying scheduled wake-up later", installation.State)
+			return
+		}
+	}
+
+	oldState := installation.State
+	installation.State = newState
+	err = s.store.UpdateInstallationState(installation)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to set installation state to %s", newState)
+		return
+	}
+	s.markEvaluated(installation, now, logger)
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  installation.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"HibernationScheduleAction": action},
+	}
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	logger.Infof("Scheduled %s requested; transitioned installation from %s to %s", action, oldState, newState)
+}
+
+// markEvaluated records that the schedule of the installation was acted upon
+// up to the given time.
+func (s *HibernationScheduleSupervisor) markEvaluated(installation *model.Installation, now int64, logger log.FieldLogger) {
+	installation.HibernationScheduleEvaluatedAt = now
+	err := s.store.UpdateInstallationHibernationScheduleEvaluatedAt(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record hibernation schedule evaluation")
+	}
+}
+
+// dueAction returns the most recent scheduled action of the effective
+// hibernation schedule of the installation since the schedule was last
+// evaluated, if any.
+func (s *HibernationScheduleSupervisor) dueAction(installation *model.Installation, now int64) (string, error) {
+	var group *model.Group
+	if installation.HibernationSchedule == nil && installation.IsInGroup() {
+		var err error
+		group, err = s.store.GetGroup(*installation.GroupID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	schedule := installation.EffectiveHibernationSchedule(group)
+	if schedule == nil {
+		return "", nil
+	}
+
+	return schedule.DueAction(millisToTime(installation.HibernationScheduleEvaluatedAt), millisToTime(now))
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHibernationScheduleSupervisor(t *testing.T) {
+	schedule := &model.HibernationSchedule{
+		HibernateCron: "0 20 * * *",
+		WakeUpCron:    "0 7 * * *",
+	}
+	date := func(hour, min int) int64 {
+		return time.Date(2020, time.October, 1, hour, min, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	}
+
+	setup := func(t *testing.T, state string, schedule *model.HibernationSchedule, evaluatedAt int64) (*store.SQLStore, *model.Installation) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		installation := &model.Installation{
+			OwnerID:                        model.NewID(),
+			DNS:                            "schedule.example.com",
+			State:                          state,
+			HibernationSchedule:            schedule,
+			HibernationScheduleEvaluatedAt: evaluatedAt,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		return sqlStore, installation
+	}
+
+	expectInstallationState := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation, expectedState string) {
+		t.Helper()
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, installation.State)
+	}
+
+	expectEvaluatedAt := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation, expectedEvaluatedAt int64) {
+		t.Helper()
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, expectedEvaluatedAt, installation.HibernationScheduleEvaluatedAt)
+	}
+
+	t.Run("hibernation due", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, date(19, 59))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationRequested)
+		expectEvaluatedAt(t, sqlStore, installation, date(20, 0))
+	})
+
+	t.Run("hibernation due since last evaluation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, date(19, 0))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(21, 30))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationRequested)
+	})
+
+	t.Run("schedule evaluated from the first time it is seen", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, 0)
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(21, 30))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectEvaluatedAt(t, sqlStore, installation, date(21, 30))
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, date(12, 0))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(12, 1))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+
+	t.Run("wake-up due", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateHibernating, schedule, date(6, 59))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(7, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateRequested)
+	})
+
+	t.Run("hibernation retried for unstable installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateUpdateInProgress, schedule, date(19, 59))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateInProgress)
+		expectEvaluatedAt(t, sqlStore, installation, date(19, 59))
+
+		installation.State = model.InstallationStateStable
+		err := sqlStore.UpdateInstallationState(installation)
+		require.NoError(t, err)
+
+		scheduleSupervisor.Supervise(installation, date(20, 5))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationRequested)
+		expectEvaluatedAt(t, sqlStore, installation, date(20, 5))
+	})
+
+	t.Run("hibernation not repeated for hibernating installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateHibernating, schedule, date(19, 59))
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernating)
+		expectEvaluatedAt(t, sqlStore, installation, date(20, 0))
+	})
+
+	t.Run("hibernation skipped for API security locked installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, date(19, 59))
+
+		err := sqlStore.LockInstallationAPI(installation.ID)
+		require.NoError(t, err)
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectEvaluatedAt(t, sqlStore, installation, date(20, 0))
+	})
+
+	t.Run("hibernation retried for locked installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, schedule, date(19, 59))
+
+		lockerID := model.NewID()
+		locked, err := sqlStore.LockInstallation(installation.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectEvaluatedAt(t, sqlStore, installation, date(19, 59))
+
+		unlocked, err := sqlStore.UnlockInstallation(installation.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		scheduleSupervisor.Supervise(installation, date(20, 5))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationRequested)
+	})
+
+	t.Run("group schedule", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, nil, date(19, 59))
+
+		group := &model.Group{Name: "group", HibernationSchedule: schedule}
+		err := sqlStore.CreateGroup(group)
+		require.NoError(t, err)
+
+		installation.GroupID = &group.ID
+		err = sqlStore.UpdateInstallation(installation)
+		require.NoError(t, err)
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationRequested)
+	})
+
+	t.Run("installation schedule overrides group schedule", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable, &model.HibernationSchedule{HibernateCron: "0 22 * * *"}, date(19, 59))
+
+		group := &model.Group{Name: "group", HibernationSchedule: schedule}
+		err := sqlStore.CreateGroup(group)
+		require.NoError(t, err)
+
+		installation.GroupID = &group.ID
+		err = sqlStore.UpdateInstallation(installation)
+		require.NoError(t, err)
+
+		scheduleSupervisor := supervisor.NewHibernationScheduleSupervisor(sqlStore, model.NewID(), logger)
+		scheduleSupervisor.Supervise(installation, date(20, 0))
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// idleHibernationActivityCheckInterval is the minimum time between two
+// activity checks of the same installation.
+const idleHibernationActivityCheckInterval = time.Hour
+
+// idleHibernationStore abstracts the database operations required by the
+// idle hibernation supervisor.
+type idleHibernationStore interface {
+	GetInstallations(filter *model.InstallationFilter, includeGroupConfig, includeGroupConfigOverrides bool) ([]*model.Installation, error)
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	UpdateInstallationState(*model.Installation) error
+	UpdateInstallationActivity(*model.Installation) error
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetGroup(groupID string) (*model.Group, error)
+
+	GetCluster(id string) (*model.Cluster, error)
+	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+}
+
+// idleHibernationProvisioner abstracts the provisioning operations required
+// by the idle hibernation supervisor.
+type idleHibernationProvisioner interface {
+	GetClusterInstallationLastActivity(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (int64, error)
+}
+
+// IdleHibernationSupervisor records the user activity of stable installations
+// and requests hibernation of installations that have been idle for longer
+// than the idle threshold.
+type IdleHibernationSupervisor struct {
+	store       idleHibernationStore
+	provisioner idleHibernationProvisioner
+	threshold   time.Duration
+	gracePeriod time.Duration
+	instanceID  string
+	logger      log.FieldLogger
+}
+
+// NewIdleHibernationSupervisor creates a new IdleHibernationSupervisor.
+// Installations are hibernated once they have had no activity for the given
+// threshold, but not before they have been stable for the given grace period,
+// which groups may override.
+func NewIdleHibernationSupervisor(store idleHibernationStore, provisioner idleHibernationProvisioner, threshold, gracePeriod time.Duration, instanceID string, logger log.FieldLogger) *IdleHibernationSupervisor {
+	return &IdleHibernationSupervisor{
+		store:       store,
+		provisioner: provisioner,
+		threshold:   threshold,
+		gracePeriod: gracePeriod,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the idle hibernation supervisor.
+func (s *IdleHibernationSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down idle hibernation supervisor")
+}
+
+// Do looks for stable installations whose activity is due to be checked and
+// requests hibernation of the idle ones.
+func (s *IdleHibernationSupervisor) Do() error {
+	installations, err := s.store.GetInstallations(&model.InstallationFilter{
+		State:   model.InstallationStateStable,
+		PerPage: model.AllPerPage,
+	}, false, false)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for stable installations")
+		return nil
+	}
+
+	now := store.GetMillis()
+	for _, installation := range installations {
+		if installation.LockAcquiredAt != 0 ||
+			now-installation.LastActivityCheckAt < idleHibernationActivityCheckInterval.Milliseconds() {
+			continue
+		}
+		s.Supervise(installation)
+	}
+
+	return nil
+}
+
+// Supervise records the current activity of the given installation and
+// requests its hibernation if it is idle.
+func (s *IdleHibernationSupervisor) Supervise(installation *model.Installation) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": installation.ID,
+	})
+
+	lock := newInstallationLock(installation.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	installation, err := s.store.GetInstallation(installation.ID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed installation")
+		return
+	}
+	if installation == nil || installation.State != model.InstallationStateStable {
+		return
+	}
+
+	gracePeriod := s.gracePeriod
+	if installation.IsInGroup() {
+		group, err := s.store.GetGroup(*installation.GroupID)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get installation group")
+			return
+		}
+		if group != nil && !group.IsDeleted() {
+			if group.IdleHibernationOptOut {
+				return
+			}
+			if group.IdleHibernationGracePeriodHours != nil {
+				gracePeriod = time.Duration(*group.IdleHibernationGracePeriodHours) * time.Hour
+			}
+		}
+	}
+
+	lastActivityAt, err := s.getLastActivity(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check installation activity")
+		return
+	}
+
+	now := store.GetMillis()
+	if lastActivityAt > installation.LastActivityAt {
+		installation.LastActivityAt = lastActivityAt
+	}
+	installation.LastActivityCheckAt = now
+	err = s.store.UpdateInstallationActivity(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record installation activity")
+		return
+	}
+
+	if installation.APISecurityLock {
+		return
+	}
+
+	stableSince, err := s.getStableSince(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to determine when the installation became stable")
+		return
+	}
+	if now-stableSince < gracePeriod.Milliseconds() {
+		return
+	}
+
+	idleSince := installation.LastActivityAt
+	if idleSince < installation.CreateAt {
+		idleSince = installation.CreateAt
+	}
+	if now-idleSince < s.threshold.Milliseconds() {
+		return
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateHibernationRequested
+	err = s.store.UpdateInstallationState(installation)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to set installation state to %s", installation.State)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  installation.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{
+			"HibernationReason": "idle",
+			"LastActivityAt":    strconv.FormatInt(installation.LastActivityAt, 10),
+		},
+	}
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	logger.Infof("Installation idle since %d; requested hibernation", idleSince)
+}
+
+// getLastActivity returns the time of the most recent user activity reported
+// by the installation.
+func (s *IdleHibernationSupervisor) getLastActivity(installation *model.Installation) (int64, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get cluster installations")
+	}
+	if len(clusterInstallations) == 0 {
+		return 0, errors.New("no cluster installations found")
+	}
+
+	var lastActivityAt int64
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get cluster")
+		}
+		if cluster == nil {
+			return 0, errors.Errorf("cluster %s not found", clusterInstallation.ClusterID)
+		}
+
+		clusterInstallationActivityAt, err := s.provisioner.GetClusterInstallationLastActivity(cluster, clusterInstallation)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get activity of cluster installation %s", clusterInstallation.ID)
+		}
+		if clusterInstallationActivityAt > lastActivityAt {
+			lastActivityAt = clusterInstallationActivityAt
+		}
+	}
+
+	return lastActivityAt, nil
+}
+
+// getStableSince returns the time at which the installation last became
+// stable, e.g. after being created or woken up.
+func (s *IdleHibernationSupervisor) getStableSince(installation *model.Installation) (int64, error) {
+	event, err := s.store.GetLatestResourceEvent(installation.ID)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil || event.Payload.NewState != model.InstallationStateStable {
+		return installation.CreateAt, nil
+	}
+
+	return event.CreateAt, nil
+}
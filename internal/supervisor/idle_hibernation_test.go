@@ -0,0 +1,181 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIdleHibernationProvisioner struct {
+	LastActivityAt int64
+	Error          error
+}
+
+func (p *mockIdleHibernationProvisioner) GetClusterInstallationLastActivity(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (int64, error) {
+	return p.LastActivityAt, p.Error
+}
+
+func TestIdleHibernationSupervisor(t *testing.T) {
+	setup := func(t *testing.T, state string) (*store.SQLStore, *model.Installation) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{State: model.ClusterStateStable}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "idle.example.com",
+			State:   state,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		return sqlStore, installation
+	}
+
+	getInstallation := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation) *model.Installation {
+		t.Helper()
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		return installation
+	}
+
+	t.Run("idle installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateHibernationRequested, installation.State)
+		require.NotZero(t, installation.LastActivityCheckAt)
+	})
+
+	t.Run("active installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+		lastActivityAt := store.GetMillis()
+		provisioner := &mockIdleHibernationProvisioner{LastActivityAt: lastActivityAt}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 24*time.Hour, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+		require.Equal(t, lastActivityAt, installation.LastActivityAt)
+	})
+
+	t.Run("within grace period", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, time.Hour, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+	})
+
+	t.Run("activity check fails", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+		provisioner := &mockIdleHibernationProvisioner{Error: errors.New("exec failed")}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+		require.Zero(t, installation.LastActivityCheckAt)
+	})
+
+	t.Run("API security locked installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		err := sqlStore.LockInstallationAPI(installation.ID)
+		require.NoError(t, err)
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+	})
+
+	t.Run("unstable installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateUpdateInProgress)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateUpdateInProgress, installation.State)
+	})
+
+	t.Run("group opt-out", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+
+		group := &model.Group{Name: "opt-out", IdleHibernationOptOut: true}
+		err := sqlStore.CreateGroup(group)
+		require.NoError(t, err)
+		installation.GroupID = &group.ID
+		err = sqlStore.UpdateInstallation(installation)
+		require.NoError(t, err)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+	})
+
+	t.Run("group grace period", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation := setup(t, model.InstallationStateStable)
+
+		gracePeriodHours := int64(1)
+		group := &model.Group{Name: "grace", IdleHibernationGracePeriodHours: &gracePeriodHours}
+		err := sqlStore.CreateGroup(group)
+		require.NoError(t, err)
+		installation.GroupID = &group.ID
+		err = sqlStore.UpdateInstallation(installation)
+		require.NoError(t, err)
+		provisioner := &mockIdleHibernationProvisioner{}
+
+		idleSupervisor := supervisor.NewIdleHibernationSupervisor(sqlStore, provisioner, 0, 0, model.NewID(), logger)
+		idleSupervisor.Supervise(installation)
+
+		installation = getInstallation(t, sqlStore, installation)
+		require.Equal(t, model.InstallationStateStable, installation.State)
+	})
+}
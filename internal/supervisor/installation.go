@@ -13,7 +13,6 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
-	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
 )
@@ -31,6 +30,7 @@ type installationStore interface {
 	UpdateInstallation(installation *model.Installation) error
 	UpdateInstallationGroupSequence(installation *model.Installation) error
 	UpdateInstallationState(*model.Installation) error
+	UpdateInstallationMigrationTarget(*model.Installation) error
 	LockInstallation(installationID, lockerID string) (bool, error)
 	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
 	DeleteInstallation(installationID string) error
@@ -47,10 +47,22 @@ type installationStore interface {
 	GetMultitenantDatabaseForInstallationID(installationID string) (*model.MultitenantDatabase, error)
 	CreateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
 	UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	DeleteMultitenantDatabase(multitenantDatabaseID string) error
 	LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error)
 	UnlockMultitenantDatabase(multitenantdatabaseID, lockerID string, force bool) (bool, error)
+	LockMultitenantDatabaseVPC(vpcID, lockerID string) (bool, error)
+	UnlockMultitenantDatabaseVPC(vpcID, lockerID string, force bool) (bool, error)
+
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+	GetInstallationBackups(filter *model.InstallationBackupFilter) ([]*model.InstallationBackup, error)
+	UpdateInstallationBackup(backup *model.InstallationBackup) error
+	LockInstallationBackup(backupID, lockerID string) (bool, error)
+	UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error)
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
 }
 
 // provisioner abstracts the provisioning operations required by the installation supervisor.
@@ -60,8 +72,17 @@ type installationProvisioner interface {
 	UpdateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
 	HibernateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
 	GetClusterInstallationResource(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*mmv1alpha1.ClusterInstallation, error)
-	GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error)
 	GetPublicLoadBalancerEndpoint(cluster *model.Cluster, namespace string) (string, error)
+	TriggerInstallationDatabaseMigration(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
+	CheckInstallationDatabaseMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (string, error)
+	RefreshClusterInstallationDatabase(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
+}
+
+// installationScheduler abstracts the placement of installations on clusters.
+type installationScheduler interface {
+	Schedule(installation *model.Installation, clusters []*model.Cluster) []*model.ClusterPlacement
+	Evaluate(cluster *model.Cluster, installation *model.Installation) (*model.ClusterPlacement, error)
+	ScaleUpNodeCount(cluster *model.Cluster) int64
 }
 
 // InstallationSupervisor finds installations pending work and effects the required changes.
@@ -69,31 +90,29 @@ type installationProvisioner interface {
 // The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
 // other clients needing to coordinate background jobs.
 type InstallationSupervisor struct {
-	store                              installationStore
-	provisioner                        installationProvisioner
-	aws                                aws.AWS
-	instanceID                         string
-	clusterResourceThreshold           int
-	clusterResourceThresholdScaleValue int
-	keepDatabaseData                   bool
-	keepFilestoreData                  bool
-	resourceUtil                       *utils.ResourceUtil
-	logger                             log.FieldLogger
+	store             installationStore
+	provisioner       installationProvisioner
+	aws               aws.AWS
+	instanceID        string
+	scheduler         installationScheduler
+	keepDatabaseData  bool
+	keepFilestoreData bool
+	resourceUtil      *utils.ResourceUtil
+	logger            log.FieldLogger
 }
 
 // NewInstallationSupervisor creates a new InstallationSupervisor.
-func NewInstallationSupervisor(store installationStore, installationProvisioner installationProvisioner, aws aws.AWS, instanceID string, threshold, thresholdScaleValue int, keepDatabaseData, keepFilestoreData bool, resourceUtil *utils.ResourceUtil, logger log.FieldLogger) *InstallationSupervisor {
+func NewInstallationSupervisor(store installationStore, installationProvisioner installationProvisioner, aws aws.AWS, instanceID string, scheduler installationScheduler, keepDatabaseData, keepFilestoreData bool, resourceUtil *utils.ResourceUtil, logger log.FieldLogger) *InstallationSupervisor {
 	return &InstallationSupervisor{
-		store:                              store,
-		provisioner:                        installationProvisioner,
-		aws:                                aws,
-		instanceID:                         instanceID,
-		clusterResourceThreshold:           threshold,
-		clusterResourceThresholdScaleValue: thresholdScaleValue,
-		keepDatabaseData:                   keepDatabaseData,
-		keepFilestoreData:                  keepFilestoreData,
-		resourceUtil:                       resourceUtil,
-		logger:                             logger,
+		store:             store,
+		provisioner:       installationProvisioner,
+		aws:               aws,
+		instanceID:        instanceID,
+		scheduler:         scheduler,
+		keepDatabaseData:  keepDatabaseData,
+		keepFilestoreData: keepFilestoreData,
+		resourceUtil:      resourceUtil,
+		logger:            logger,
 	}
 }
 
@@ -179,11 +198,13 @@ func (s *InstallationSupervisor) Supervise(installation *model.Installation) {
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeInstallation,
 		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -193,6 +214,23 @@ func (s *InstallationSupervisor) Supervise(installation *model.Installation) {
 
 // transitionInstallation works with the given installation to transition it to a final state.
 func (s *InstallationSupervisor) transitionInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	switch installation.State {
+	case model.InstallationStateUpdateRequested,
+		model.InstallationStateHibernationRequested,
+		model.InstallationStateDeletionRequested:
+		// Changes to the installation wait for a backup in progress to finish
+		// so that the backup is consistent.
+		backingUp, err := s.hasBackupInProgress(installation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to check for installation backups in progress")
+			return installation.State
+		}
+		if backingUp {
+			logger.Debug("Installation backup in progress; waiting for it to finish")
+			return installation.State
+		}
+	}
+
 	switch installation.State {
 	case model.InstallationStateCreationRequested,
 		model.InstallationStateCreationNoCompatibleClusters:
@@ -222,6 +260,30 @@ func (s *InstallationSupervisor) transitionInstallation(installation *model.Inst
 	case model.InstallationStateHibernationInProgress:
 		return s.waitForHibernationStable(installation, instanceID, logger)
 
+	case model.InstallationStateMigrationRequested:
+		return s.migrateInstallation(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationInProgress:
+		return s.waitForMigrationStable(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationDNS:
+		return s.configureInstallationDNS(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationCleanup:
+		return s.cleanupMigration(installation, instanceID, logger)
+
+	case model.InstallationStateDBMigrationRequested:
+		return s.migrateInstallationDatabase(installation, instanceID, logger)
+
+	case model.InstallationStateDBMigrationInProgress:
+		return s.waitForDatabaseMigration(installation, instanceID, logger)
+
+	case model.InstallationStateDBMigrationFinalizing:
+		return s.finalizeDatabaseMigration(installation, instanceID, logger)
+
+	case model.InstallationStateDBMigrationCleanup:
+		return s.cleanupDatabaseMigration(installation, instanceID, logger)
+
 	case model.InstallationStateDeletionRequested,
 		model.InstallationStateDeletionInProgress:
 		return s.deleteInstallation(installation, instanceID, logger)
@@ -260,7 +322,7 @@ func (s *InstallationSupervisor) createInstallation(installation *model.Installa
 		return model.InstallationStateCreationRequested
 	}
 
-	for _, cluster := range clusters {
+	for _, cluster := range s.scheduleClusters(installation, clusters, logger) {
 		clusterInstallation := s.createClusterInstallation(cluster, installation, instanceID, logger)
 		if clusterInstallation != nil {
 			return s.preProvisionInstallation(installation, instanceID, logger)
@@ -273,6 +335,26 @@ func (s *InstallationSupervisor) createInstallation(installation *model.Installa
 	return model.InstallationStateCreationNoCompatibleClusters
 }
 
+// scheduleClusters returns the clusters able to host the installation, in
+// the order of preference of the scheduler.
+func (s *InstallationSupervisor) scheduleClusters(installation *model.Installation, clusters []*model.Cluster, logger log.FieldLogger) []*model.Cluster {
+	clustersByID := make(map[string]*model.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clustersByID[cluster.ID] = cluster
+	}
+
+	var scheduled []*model.Cluster
+	for _, placement := range s.scheduler.Schedule(installation, clusters) {
+		if !placement.Schedulable {
+			logger.Debugf("Cluster %s cannot host the installation: %s", placement.ClusterID, placement.Reason)
+			continue
+		}
+		scheduled = append(scheduled, clustersByID[placement.ClusterID])
+	}
+
+	return scheduled
+}
+
 // createClusterInstallation attempts to schedule a cluster installation onto the given cluster.
 func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluster, installation *model.Installation, instanceID string, logger log.FieldLogger) *model.ClusterInstallation {
 	clusterLock := newClusterLock(cluster.ID, instanceID, s.store, logger)
@@ -282,99 +364,26 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 	}
 	defer clusterLock.Unlock()
 
-	if cluster.State != model.ClusterStateStable {
-		logger.Debugf("Cluster %s is not stable (currently %s)", cluster.ID, cluster.State)
-		return nil
-	}
-	if !cluster.AllowInstallations {
-		logger.Debugf("Cluster %s is set to not allow for new installation scheduling", cluster.ID)
-		return nil
-	}
-
-	existingClusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
-		PerPage:   model.AllPerPage,
-		ClusterID: cluster.ID,
-	})
-
-	////////////////////////////////////////////////////////////////////////////
-	//                              MULTI-TENANCY                             //
-	////////////////////////////////////////////////////////////////////////////
-	// Current model:                                                         //
-	// - isolation=true  | 1 cluster installations                            //
-	// - isolation=false | X cluster installations, where "X" is as many as   //
-	//                     will fit with the given CPU and Memory threshold.  //
-	////////////////////////////////////////////////////////////////////////////
-	if installation.Affinity == model.InstallationAffinityIsolated {
-		if len(existingClusterInstallations) > 0 {
-			logger.Debugf("Cluster %s already has %d installations", cluster.ID, len(existingClusterInstallations))
-			return nil
-		}
-	} else {
-		if len(existingClusterInstallations) == 1 {
-			// This should be the only scenario where we need to check if the
-			// cluster installation running requires isolation or not.
-			installation, err := s.store.GetInstallation(existingClusterInstallations[0].InstallationID, true, false)
-			if err != nil {
-				logger.WithError(err).Warn("Unable to find installation")
-				return nil
-			}
-			if installation.Affinity == model.InstallationAffinityIsolated {
-				logger.Debugf("Cluster %s already has an isolated installation %s", cluster.ID, installation.ID)
-				return nil
-			}
-		}
-	}
-
-	// Begin final resource check.
-
-	size, err := mmv1alpha1.GetClusterSize(installation.Size)
+	// Evaluate the cluster again now that it is locked.
+	placement, err := s.scheduler.Evaluate(cluster, installation)
 	if err != nil {
-		logger.WithError(err).Error("Invalid cluster installation size")
+		logger.WithError(err).Errorf("Failed to evaluate cluster %s", cluster.ID)
 		return nil
 	}
-	clusterResources, err := s.provisioner.GetClusterResources(cluster, true)
-	if err != nil {
-		logger.WithError(err).Error("Failed to get cluster resources")
+	if !placement.Schedulable {
+		logger.Debugf("Cluster %s cannot host the installation: %s", cluster.ID, placement.Reason)
 		return nil
 	}
 
-	installationCPURequirement := size.CalculateCPUMilliRequirement(
-		installation.InternalDatabase(),
-		installation.InternalFilestore(),
-	)
-	installationMemRequirement := size.CalculateMemoryMilliRequirement(
-		installation.InternalDatabase(),
-		installation.InternalFilestore(),
-	)
-	cpuPercent := clusterResources.CalculateCPUPercentUsed(installationCPURequirement)
-	memoryPercent := clusterResources.CalculateMemoryPercentUsed(installationMemRequirement)
-
-	if cpuPercent > s.clusterResourceThreshold || memoryPercent > s.clusterResourceThreshold {
-		if s.clusterResourceThresholdScaleValue == 0 ||
-			cluster.ProvisionerMetadataKops.NodeMinCount == cluster.ProvisionerMetadataKops.NodeMaxCount ||
-			cluster.State != model.ClusterStateStable {
-			logger.Debugf("Cluster %s would exceed the cluster load threshold (%d%%): CPU=%d%% (+%dm), Memory=%d%% (+%dMi)",
-				cluster.ID,
-				s.clusterResourceThreshold,
-				cpuPercent, installationCPURequirement,
-				memoryPercent, installationMemRequirement/1048576000, // Have to convert to Mi
-			)
-			return nil
-		}
-
+	if placement.RequiresScaleUp {
 		// This cluster is ready to scale to meet increased resource demand.
 		// TODO: if this ends up working well, build a safer interface for
 		// updating the cluster. We should try to reuse some of the API flow
 		// that already does this.
 
-		newWorkerCount := cluster.ProvisionerMetadataKops.NodeMinCount + int64(s.clusterResourceThresholdScaleValue)
-		if newWorkerCount > cluster.ProvisionerMetadataKops.NodeMaxCount {
-			newWorkerCount = cluster.ProvisionerMetadataKops.NodeMaxCount
-		}
-
 		cluster.State = model.ClusterStateResizeRequested
 		cluster.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
-			NodeMinCount: newWorkerCount,
+			NodeMinCount: s.scheduler.ScaleUpNodeCount(cluster),
 		}
 
 		logger.WithField("cluster", cluster.ID).Infof("Scaling cluster worker nodes from %d to %d (max=%d)",
@@ -396,7 +405,7 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 			Timestamp: time.Now().UnixNano(),
 		}
 
-		err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+		err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -424,12 +433,12 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		OldState:  "n/a",
 		Timestamp: time.Now().UnixNano(),
 	}
-	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
-	logger.Infof("Requested creation of cluster installation on cluster %s. Expected resource load: CPU=%d%%, Memory=%d%%", cluster.ID, cpuPercent, memoryPercent)
+	logger.Infof("Requested creation of cluster installation on cluster %s. Expected resource load: CPU=%d%%, Memory=%d%%", cluster.ID, placement.CPUPercent, placement.MemoryPercent)
 
 	return clusterInstallation
 }
@@ -468,43 +477,75 @@ func (s *InstallationSupervisor) waitForCreationStable(installation *model.Insta
 }
 
 func (s *InstallationSupervisor) configureInstallationDNS(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	// Installations being migrated have their DNS switched to the cluster
+	// installation on the migration target cluster only.
+	migrating := installation.MigrationTargetClusterID != ""
+	dnsState := model.InstallationStateCreationDNS
+	if migrating {
+		dnsState = model.InstallationStateMigrationDNS
+	}
+
 	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		InstallationID: installation.ID,
 		PerPage:        model.AllPerPage,
 	})
 	if err != nil {
 		logger.WithError(err).Warn("Failed to find cluster installations")
-		return model.InstallationStateCreationDNS
+		return dnsState
 	}
 
 	var endpoints []string
+	var localClusters int
 	for _, clusterInstallation := range clusterInstallations {
+		if migrating && clusterInstallation.ClusterID != installation.MigrationTargetClusterID {
+			continue
+		}
+
 		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
 		if err != nil {
 			logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
-			return model.InstallationStateCreationDNS
+			return dnsState
 		}
 		if cluster == nil {
 			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
 			return failedClusterInstallationState(clusterInstallation.State)
 		}
 
+		// Local clusters have no load balancer to point public DNS to.
+		if cluster.Provisioner == model.ProvisionerLocal {
+			localClusters++
+			continue
+		}
+
 		endpoint, err := s.provisioner.GetPublicLoadBalancerEndpoint(cluster, "nginx")
 		if err != nil {
 			logger.WithError(err).Error("Couldn't get the load balancer endpoint (nginx) for Cluster Installation")
-			return model.InstallationStateCreationDNS
+			return dnsState
 		}
 
 		endpoints = append(endpoints, endpoint)
 	}
 
-	err = s.aws.CreatePublicCNAME(installation.DNS, endpoints, logger)
-	if err != nil {
-		logger.WithError(err).Error("Failed to create DNS CNAME record")
-		return model.InstallationStateCreationDNS
+	if len(endpoints) == 0 && localClusters == 0 {
+		logger.Warn("Found no cluster installations to point DNS to")
+		return dnsState
+	}
+
+	if len(endpoints) > 0 {
+		err = s.aws.CreatePublicCNAME(installation.DNS, endpoints, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to create DNS CNAME record")
+			return dnsState
+		}
+
+		logger.Infof("Successfully configured DNS %s", installation.DNS)
+	} else {
+		logger.Infof("Skipping DNS %s for installation hosted on local clusters", installation.DNS)
 	}
 
-	logger.Infof("Successfully configured DNS %s", installation.DNS)
+	if migrating {
+		return s.cleanupMigration(installation, instanceID, logger)
+	}
 
 	return s.waitForCreationStable(installation, instanceID, logger)
 }
@@ -564,8 +605,7 @@ func (s *InstallationSupervisor) updateInstallation(installation *model.Installa
 			return installation.State
 		}
 
-		clusterInstallation.State = model.ClusterInstallationStateReconciling
-		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		err = s.updateClusterInstallationState(clusterInstallation, model.ClusterInstallationStateReconciling, logger)
 		if err != nil {
 			logger.Errorf("Failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
 			return installation.State
@@ -650,8 +690,7 @@ func (s *InstallationSupervisor) hibernateInstallation(installation *model.Insta
 			return installation.State
 		}
 
-		clusterInstallation.State = model.ClusterInstallationStateReconciling
-		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		err = s.updateClusterInstallationState(clusterInstallation, model.ClusterInstallationStateReconciling, logger)
 		if err != nil {
 			logger.Errorf("Failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
 			return installation.State
@@ -680,6 +719,509 @@ func (s *InstallationSupervisor) waitForHibernationStable(installation *model.In
 	return model.InstallationStateHibernating
 }
 
+func (s *InstallationSupervisor) migrateInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	logger = logger.WithField("targetCluster", installation.MigrationTargetClusterID)
+
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return installation.State
+	}
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.ClusterID == installation.MigrationTargetClusterID {
+			logger.Warnf("Found existing cluster installation %s on the target cluster", clusterInstallation.ID)
+			return s.waitForMigrationStable(installation, instanceID, logger)
+		}
+	}
+
+	var targetCluster *model.Cluster
+	if installation.MigrationTargetClusterID != "" {
+		targetCluster, err = s.store.GetCluster(installation.MigrationTargetClusterID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to query target cluster")
+			return installation.State
+		}
+		if targetCluster == nil || targetCluster.DeleteAt != 0 {
+			logger.Error("Migration target cluster not found")
+			return model.InstallationStateMigrationFailed
+		}
+	}
+
+	// Databases hosted in the VPC of the cluster are not moved; they remain
+	// reachable from other clusters in the same VPC only.
+	var sourceCluster *model.Cluster
+	if installation.VPCDatabase() {
+		sourceCluster, err = s.getSourceCluster(installation, clusterInstallations)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to query source cluster")
+			return installation.State
+		}
+		err = s.resolveClusterVPC(sourceCluster, logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to resolve the VPC of the source cluster")
+			return installation.State
+		}
+		err = s.resolveClusterVPC(targetCluster, logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to resolve the VPC of the target cluster")
+			return installation.State
+		}
+		if targetCluster != nil && !sourceCluster.InSameVPC(targetCluster) {
+			logger.Errorf("Installations using an RDS database can only be migrated to a cluster in the same VPC; the database is only reachable from the VPC of cluster %s", sourceCluster.ID)
+			return model.InstallationStateMigrationFailed
+		}
+	}
+
+	if targetCluster == nil {
+		return s.scheduleMigration(installation, clusterInstallations, sourceCluster, instanceID, logger)
+	}
+
+	clusterInstallation := s.createClusterInstallation(targetCluster, installation, instanceID, logger)
+	if clusterInstallation == nil {
+		logger.Warn("Unable to schedule the installation on the target cluster")
+		return installation.State
+	}
+
+	return s.waitForMigrationStable(installation, instanceID, logger)
+}
+
+// resolveClusterVPC fills in the VPC of kops clusters created before it was
+// kept in their metadata with the VPC claimed by them. The cluster supervisor
+// records it the next time the cluster metadata is refreshed.
+func (s *InstallationSupervisor) resolveClusterVPC(cluster *model.Cluster, logger log.FieldLogger) error {
+	if cluster == nil || cluster.ProvisionerMetadataKops == nil || cluster.VPC() != "" {
+		return nil
+	}
+
+	vpcID, err := s.aws.GetClaimedVpcID(cluster.ID, logger)
+	if err != nil {
+		return err
+	}
+	cluster.ProvisionerMetadataKops.VPC = vpcID
+
+	return nil
+}
+
+// scheduleMigration moves an installation without a migration target, such as
+// one drained off a cluster, to any other cluster able to host it. When a
+// source cluster is given, only clusters in its VPC are considered.
+func (s *InstallationSupervisor) scheduleMigration(installation *model.Installation, clusterInstallations []*model.ClusterInstallation, sourceCluster *model.Cluster, instanceID string, logger log.FieldLogger) string {
+	sourceClusterIDs := make(map[string]bool)
+	for _, clusterInstallation := range clusterInstallations {
+		sourceClusterIDs[clusterInstallation.ClusterID] = true
+	}
+
+	clusters, err := s.store.GetClusters(&model.ClusterFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: false,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to query clusters")
+		return installation.State
+	}
+
+	var candidates []*model.Cluster
+	for _, cluster := range clusters {
+		if sourceClusterIDs[cluster.ID] {
+			continue
+		}
+		if sourceCluster != nil {
+			err = s.resolveClusterVPC(cluster, logger)
+			if err != nil {
+				logger.WithError(err).Warnf("Failed to resolve the VPC of cluster %s", cluster.ID)
+				continue
+			}
+			if !sourceCluster.InSameVPC(cluster) {
+				continue
+			}
+		}
+		candidates = append(candidates, cluster)
+	}
+
+	for _, cluster := range s.scheduleClusters(installation, candidates, logger) {
+		clusterInstallation := s.createClusterInstallation(cluster, installation, instanceID, logger)
+		if clusterInstallation == nil {
+			continue
+		}
+
+		// Should recording the target fail, the new cluster installation is
+		// removed along with the source cluster installations once the
+		// installation was migrated elsewhere.
+		installation.MigrationTargetClusterID = cluster.ID
+		err = s.store.UpdateInstallationMigrationTarget(installation)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to record installation migration target")
+			installation.MigrationTargetClusterID = ""
+			return installation.State
+		}
+
+		logger.Infof("Scheduled installation migration to cluster %s", cluster.ID)
+
+		return s.waitForMigrationStable(installation, instanceID, logger)
+	}
+
+	logger.Warn("No compatible clusters available for installation migration")
+
+	return installation.State
+}
+
+func (s *InstallationSupervisor) waitForMigrationStable(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Installation migration failed")
+		return model.InstallationStateMigrationFailed
+	}
+	if !stable {
+		return model.InstallationStateMigrationInProgress
+	}
+
+	logger.Info("Cluster installation on the target cluster is now stable")
+
+	return s.configureInstallationDNS(installation, instanceID, logger)
+}
+
+// cleanupMigration deletes the cluster installations that are not on the
+// migration target cluster, once DNS points to the target cluster.
+func (s *InstallationSupervisor) cleanupMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateMigrationCleanup
+	}
+
+	var sourceClusterInstallationIDs []string
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.ClusterID != installation.MigrationTargetClusterID {
+			sourceClusterInstallationIDs = append(sourceClusterInstallationIDs, clusterInstallation.ID)
+		}
+	}
+
+	if len(sourceClusterInstallationIDs) > 0 {
+		clusterInstallationLocks := newClusterInstallationLocks(sourceClusterInstallationIDs, instanceID, s.store, logger)
+		if !clusterInstallationLocks.TryLock() {
+			logger.Debugf("Failed to lock %d cluster installations", len(sourceClusterInstallationIDs))
+			return model.InstallationStateMigrationCleanup
+		}
+		defer clusterInstallationLocks.Unlock()
+
+		// Fetch the same cluster installations again, now that we have the locks.
+		clusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+			PerPage: model.AllPerPage,
+			IDs:     sourceClusterInstallationIDs,
+		})
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to fetch %d cluster installations by ids", len(sourceClusterInstallationIDs))
+			return model.InstallationStateMigrationCleanup
+		}
+
+		deleting := 0
+		for _, clusterInstallation := range clusterInstallations {
+			if clusterInstallation.State == model.ClusterInstallationStateDeleted {
+				continue
+			}
+			deleting++
+			if clusterInstallation.State == model.ClusterInstallationStateDeletionRequested {
+				continue
+			}
+
+			// Only fail on cluster installations whose deletion was requested
+			// by a previous cleanup. Otherwise, e.g. when retrying a failed
+			// migration, the deletion is tried again.
+			if clusterInstallation.State == model.ClusterInstallationStateDeletionFailed &&
+				installation.State == model.InstallationStateMigrationCleanup {
+				logger.Errorf("Failed to delete cluster installation %s on the source cluster", clusterInstallation.ID)
+				return model.InstallationStateMigrationFailed
+			}
+
+			err = s.updateClusterInstallationState(clusterInstallation, model.ClusterInstallationStateDeletionRequested, logger)
+			if err != nil {
+				logger.WithError(err).Warnf("Failed to mark cluster installation %s for deletion", clusterInstallation.ID)
+				return model.InstallationStateMigrationCleanup
+			}
+		}
+
+		if deleting > 0 {
+			logger.Debugf("Waiting for %d cluster installations on the source clusters to be deleted", deleting)
+			return model.InstallationStateMigrationCleanup
+		}
+	}
+
+	targetClusterID := installation.MigrationTargetClusterID
+	installation.MigrationTargetClusterID = ""
+	err = s.store.UpdateInstallationMigrationTarget(installation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to clear installation migration target")
+		return model.InstallationStateMigrationCleanup
+	}
+
+	logger.Infof("Finished migrating installation to cluster %s", targetClusterID)
+
+	return model.InstallationStateStable
+}
+
+func (s *InstallationSupervisor) migrateInstallationDatabase(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	logger = logger.WithField("targetDatabase", installation.MigrationTargetDatabaseID)
+
+	databaseMigration := s.resourceUtil.GetInstallationDatabaseMigration(installation)
+	if databaseMigration == nil {
+		logger.Errorf("Database type %s can't be migrated", installation.Database)
+		return model.InstallationStateDBMigrationFailed
+	}
+
+	err := databaseMigration.Setup(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to set up database migration")
+		return model.InstallationStateDBMigrationFailed
+	}
+
+	// Mattermost is stopped so that the database doesn't change while it is
+	// being copied.
+	if !s.updateClusterInstallations(installation, instanceID, s.provisioner.HibernateClusterInstallation, logger) {
+		return installation.State
+	}
+
+	logger.Info("Installation database migration set up")
+
+	return s.waitForDatabaseMigration(installation, instanceID, logger)
+}
+
+func (s *InstallationSupervisor) waitForDatabaseMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	logger = logger.WithField("targetDatabase", installation.MigrationTargetDatabaseID)
+
+	stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to stop installation for the database migration")
+		return s.rollbackDatabaseMigration(installation, instanceID, logger)
+	}
+	if !stable {
+		return model.InstallationStateDBMigrationInProgress
+	}
+
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateDBMigrationInProgress
+	}
+	if len(clusterInstallations) == 0 {
+		logger.Warn("Cluster installation list contained no results")
+		return model.InstallationStateDBMigrationInProgress
+	}
+
+	// The database is copied by a job running next to the first cluster
+	// installation.
+	clusterInstallation := clusterInstallations[0]
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+		return model.InstallationStateDBMigrationInProgress
+	}
+	if cluster == nil {
+		logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+		return s.rollbackDatabaseMigration(installation, instanceID, logger)
+	}
+
+	err = s.provisioner.TriggerInstallationDatabaseMigration(cluster, installation, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to trigger database migration job")
+		return model.InstallationStateDBMigrationInProgress
+	}
+
+	jobState, err := s.provisioner.CheckInstallationDatabaseMigrationJob(cluster, installation, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check database migration job")
+		return model.InstallationStateDBMigrationInProgress
+	}
+
+	switch jobState {
+	case model.BackupJobStateSucceeded:
+		logger.Info("Installation database copied to the target database")
+		return s.finalizeDatabaseMigration(installation, instanceID, logger)
+	case model.BackupJobStateFailed:
+		logger.Error("Database migration job failed")
+		return s.rollbackDatabaseMigration(installation, instanceID, logger)
+	}
+
+	logger.Debug("Database migration job is still running")
+
+	return model.InstallationStateDBMigrationInProgress
+}
+
+// rollbackDatabaseMigration removes the copy of the installation database and
+// starts Mattermost again on the source database.
+func (s *InstallationSupervisor) rollbackDatabaseMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	databaseMigration := s.resourceUtil.GetInstallationDatabaseMigration(installation)
+	if databaseMigration != nil {
+		err := databaseMigration.Rollback(s.store, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to roll back database migration; the target database may need to be cleaned up manually")
+		}
+	}
+
+	installation.MigrationTargetDatabaseID = ""
+	err := s.store.UpdateInstallationMigrationTarget(installation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to clear installation migration target")
+	}
+
+	if !s.updateClusterInstallations(installation, instanceID, s.provisioner.UpdateClusterInstallation, logger) {
+		logger.Error("Failed to start cluster installations again; update the installation to retry")
+	}
+
+	return model.InstallationStateDBMigrationFailed
+}
+
+// finalizeDatabaseMigration switches the installation over to the target
+// database and restarts Mattermost with the updated database secret.
+func (s *InstallationSupervisor) finalizeDatabaseMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	// The migration target is only cleared once the source database was
+	// cleaned up, so the switch isn't repeated after that.
+	if installation.MigrationTargetDatabaseID != "" {
+		databaseMigration := s.resourceUtil.GetInstallationDatabaseMigration(installation)
+		if databaseMigration == nil {
+			logger.Errorf("Database type %s can't be migrated", installation.Database)
+			return model.InstallationStateDBMigrationFailed
+		}
+
+		err := databaseMigration.Switch(s.store, logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to switch installation to the target database")
+			return model.InstallationStateDBMigrationFinalizing
+		}
+	}
+
+	refreshClusterInstallation := func(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+		err := s.provisioner.RefreshClusterInstallationDatabase(cluster, installation, clusterInstallation)
+		if err != nil {
+			return err
+		}
+
+		return s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
+	}
+	if !s.updateClusterInstallations(installation, instanceID, refreshClusterInstallation, logger) {
+		return model.InstallationStateDBMigrationFinalizing
+	}
+
+	return s.cleanupDatabaseMigration(installation, instanceID, logger)
+}
+
+// cleanupDatabaseMigration removes the installation database from the source
+// database once Mattermost runs on the target database again.
+func (s *InstallationSupervisor) cleanupDatabaseMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Installation failed to start on the target database; keeping the source database")
+		return model.InstallationStateDBMigrationCleanup
+	}
+	if !stable {
+		return model.InstallationStateDBMigrationCleanup
+	}
+
+	if installation.MigrationTargetDatabaseID != "" {
+		databaseMigration := s.resourceUtil.GetInstallationDatabaseMigration(installation)
+		if databaseMigration == nil {
+			logger.Errorf("Database type %s can't be migrated", installation.Database)
+			return model.InstallationStateDBMigrationFailed
+		}
+
+		err = databaseMigration.Cleanup(s.store, logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to clean up the source database")
+			return model.InstallationStateDBMigrationCleanup
+		}
+
+		targetDatabaseID := installation.MigrationTargetDatabaseID
+		installation.MigrationTargetDatabaseID = ""
+		err = s.store.UpdateInstallationMigrationTarget(installation)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to clear installation migration target")
+			installation.MigrationTargetDatabaseID = targetDatabaseID
+			return model.InstallationStateDBMigrationCleanup
+		}
+
+		logger.Infof("Moved installation database to multitenant database %s", targetDatabaseID)
+	}
+
+	logger.Info("Finished migrating installation database")
+
+	return model.InstallationStateStable
+}
+
+// updateClusterInstallations applies the given provisioner operation to every
+// cluster installation of the installation and marks them as reconciling. It
+// returns false when the operation should be retried.
+func (s *InstallationSupervisor) updateClusterInstallations(installation *model.Installation, instanceID string, update func(*model.Cluster, *model.Installation, *model.ClusterInstallation) error, logger log.FieldLogger) bool {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return false
+	}
+
+	if len(clusterInstallations) == 0 {
+		logger.Warn("Cluster installation list contained no results")
+		return false
+	}
+
+	var clusterInstallationIDs []string
+	for _, clusterInstallation := range clusterInstallations {
+		clusterInstallationIDs = append(clusterInstallationIDs, clusterInstallation.ID)
+	}
+
+	clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+	if !clusterInstallationLocks.TryLock() {
+		logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallations))
+		return false
+	}
+	defer clusterInstallationLocks.Unlock()
+
+	// Fetch the same cluster installations again, now that we have the locks.
+	clusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage: model.AllPerPage,
+		IDs:     clusterInstallationIDs,
+	})
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to fetch %d cluster installations by ids", len(clusterInstallations))
+		return false
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+			return false
+		}
+		if cluster == nil {
+			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+			return false
+		}
+
+		err = update(cluster, installation, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to update cluster installation %s", clusterInstallation.ID)
+			return false
+		}
+
+		err = s.updateClusterInstallationState(clusterInstallation, model.ClusterInstallationStateReconciling, logger)
+		if err != nil {
+			logger.Errorf("Failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
+			return false
+		}
+	}
+
+	return true
+}
+
 func (s *InstallationSupervisor) deleteInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
 	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		PerPage:        model.AllPerPage,
@@ -755,8 +1297,7 @@ func (s *InstallationSupervisor) deleteInstallation(installation *model.Installa
 			return model.InstallationStateDeletionFailed
 		}
 
-		clusterInstallation.State = model.ClusterInstallationStateDeletionRequested
-		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		err = s.updateClusterInstallationState(clusterInstallation, model.ClusterInstallationStateDeletionRequested, logger)
 		if err != nil {
 			logger.WithError(err).Warnf("Failed to mark cluster installation %s for deletion", clusterInstallation.ID)
 			return installation.State
@@ -786,23 +1327,65 @@ func (s *InstallationSupervisor) deleteInstallation(installation *model.Installa
 }
 
 func (s *InstallationSupervisor) finalDeletionCleanup(installation *model.Installation, logger log.FieldLogger) string {
-	err := s.aws.DeletePublicCNAME(installation.DNS, logger)
+	localOnly, err := s.hostedOnLocalClusters(installation)
 	if err != nil {
-		logger.WithError(err).Error("Failed to delete installation DNS")
+		logger.WithError(err).Error("Failed to check the clusters hosting the installation")
 		return model.InstallationStateDeletionFinalCleanup
 	}
 
+	if localOnly {
+		logger.Infof("Skipping DNS %s deletion for installation hosted on local clusters", installation.DNS)
+	} else {
+		err = s.aws.DeletePublicCNAME(installation.DNS, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to delete installation DNS")
+			return model.InstallationStateDeletionFinalCleanup
+		}
+	}
+
 	err = s.resourceUtil.GetDatabase(installation).Teardown(s.store, s.keepDatabaseData, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete database")
 		return model.InstallationStateDeletionFinalCleanup
 	}
 
-	err = s.resourceUtil.GetFilestore(installation).Teardown(s.keepFilestoreData, s.store, logger)
+	backups, err := s.store.GetInstallationBackups(&model.InstallationBackupFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+	})
 	if err != nil {
-		logger.WithError(err).Error("Failed to delete filestore")
+		logger.WithError(err).Error("Failed to get installation backups")
 		return model.InstallationStateDeletionFinalCleanup
 	}
+	var restorableBackups []*model.InstallationBackup
+	for _, backup := range backups {
+		switch backup.State {
+		case model.InstallationBackupStateRestoreRequested,
+			model.InstallationBackupStateRestoreInProgress:
+			logger.Debugf("Installation backup %s is being restored; waiting for it to finish", backup.ID)
+			return model.InstallationStateDeletionFinalCleanup
+		case model.InstallationBackupStateBackupSucceeded:
+			restorableBackups = append(restorableBackups, backup)
+		}
+	}
+
+	// Backups are stored in the filestore of the installation and restored
+	// with its credentials, so both are kept while backups can be restored.
+	if len(restorableBackups) != 0 && s.keepFilestoreData && !installation.InternalFilestore() {
+		logger.Infof("Keeping filestore and its credentials for %d restorable installation backups", len(restorableBackups))
+	} else {
+		err = s.deleteInstallationBackups(restorableBackups, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to mark installation backups as deleted")
+			return model.InstallationStateDeletionFinalCleanup
+		}
+
+		err = s.resourceUtil.GetFilestore(installation).Teardown(s.keepFilestoreData, s.store, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to delete filestore")
+			return model.InstallationStateDeletionFinalCleanup
+		}
+	}
 
 	err = s.store.DeleteInstallation(installation.ID)
 	if err != nil {
@@ -822,6 +1405,108 @@ func (s *InstallationSupervisor) finalCreationTasks(installation *model.Installa
 
 // Helper funcs
 
+// getSourceCluster returns the cluster an installation is migrated from,
+// which is the first of its clusters that isn't the migration target.
+func (s *InstallationSupervisor) getSourceCluster(installation *model.Installation, clusterInstallations []*model.ClusterInstallation) (*model.Cluster, error) {
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.ClusterID == installation.MigrationTargetClusterID {
+			continue
+		}
+
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+		}
+		if cluster == nil {
+			return nil, errors.Errorf("cluster %s not found", clusterInstallation.ClusterID)
+		}
+
+		return cluster, nil
+	}
+
+	return nil, errors.New("no source cluster installation found")
+}
+
+// hasBackupInProgress returns true if a backup of the installation is being
+// taken.
+func (s *InstallationSupervisor) hasBackupInProgress(installation *model.Installation) (bool, error) {
+	backups, err := s.store.GetInstallationBackups(&model.InstallationBackupFilter{
+		InstallationID: installation.ID,
+		State:          model.InstallationBackupStateBackupInProgress,
+		PerPage:        1,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get installation backups")
+	}
+
+	return len(backups) != 0, nil
+}
+
+// deleteInstallationBackups marks the given backups as deleted ahead of the
+// removal of their stored data, so that they can no longer be restored.
+func (s *InstallationSupervisor) deleteInstallationBackups(backups []*model.InstallationBackup, logger log.FieldLogger) error {
+	for _, backup := range backups {
+		lock := newInstallationBackupLock(backup.ID, s.instanceID, s.store, logger)
+		if !lock.TryLock() {
+			return errors.Errorf("failed to lock installation backup %s", backup.ID)
+		}
+
+		err := s.deleteInstallationBackup(backup.ID)
+		lock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *InstallationSupervisor) deleteInstallationBackup(backupID string) error {
+	backup, err := s.store.GetInstallationBackup(backupID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get installation backup %s", backupID)
+	}
+	if backup == nil || backup.State != model.InstallationBackupStateBackupSucceeded {
+		return errors.Errorf("installation backup %s changed state", backupID)
+	}
+
+	backup.State = model.InstallationBackupStateDeleted
+	err = s.store.UpdateInstallationBackup(backup)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update installation backup %s", backupID)
+	}
+
+	return nil
+}
+
+// hostedOnLocalClusters returns true if all the clusters the installation was
+// ever scheduled on are local clusters, which have no public DNS records.
+func (s *InstallationSupervisor) hostedOnLocalClusters(installation *model.Installation) (bool, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to find cluster installations")
+	}
+	if len(clusterInstallations) == 0 {
+		return false, nil
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+		}
+		if cluster == nil || cluster.Provisioner != model.ProvisionerLocal {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 // checkIfClusterInstallationsAreStable returns if all cluster installations
 // belonging to an installation are stable or not. Any errors that will likely
 // not succeed on future retries will also be returned. Otherwise, the error will
@@ -862,3 +1547,32 @@ func (s *InstallationSupervisor) checkIfClusterInstallationsAreStable(installati
 
 	return false, nil
 }
+
+// updateClusterInstallationState sets the state of the given cluster
+// installation and notifies webhooks of the transition.
+func (s *InstallationSupervisor) updateClusterInstallationState(clusterInstallation *model.ClusterInstallation, newState string, logger log.FieldLogger) error {
+	oldState := clusterInstallation.State
+	clusterInstallation.State = newState
+	err := s.store.UpdateClusterInstallation(clusterInstallation)
+	if err != nil {
+		return err
+	}
+	if oldState == newState {
+		return nil
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeClusterInstallation,
+		ID:        clusterInstallation.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"ClusterID": clusterInstallation.ClusterID},
+	}
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	return nil
+}
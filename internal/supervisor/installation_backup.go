@@ -0,0 +1,469 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// installationBackupStore abstracts the database operations required by the
+// installation backup supervisor.
+type installationBackupStore interface {
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+	GetUnlockedInstallationBackupsPendingWork() ([]*model.InstallationBackup, error)
+	UpdateInstallationBackup(backup *model.InstallationBackup) error
+	LockInstallationBackup(backupID, lockerID string) (bool, error)
+	UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error)
+
+	GetCluster(id string) (*model.Cluster, error)
+
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	UpdateInstallationState(*model.Installation) error
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetClusterInstallation(clusterInstallationID string) (*model.ClusterInstallation, error)
+	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
+}
+
+// installationBackupProvisioner abstracts the provisioning operations required
+// by the installation backup supervisor.
+type installationBackupProvisioner interface {
+	TriggerInstallationBackup(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error
+	CheckInstallationBackupJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error)
+	TriggerInstallationRestore(cluster *model.Cluster, sourceInstallation, targetInstallation *model.Installation, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error
+	CheckInstallationRestoreJob(cluster *model.Cluster, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error)
+}
+
+// InstallationBackupSupervisor finds installation backups pending work and
+// effects the required changes.
+type InstallationBackupSupervisor struct {
+	store       installationBackupStore
+	provisioner installationBackupProvisioner
+	instanceID  string
+	logger      log.FieldLogger
+}
+
+// NewInstallationBackupSupervisor creates a new InstallationBackupSupervisor.
+func NewInstallationBackupSupervisor(store installationBackupStore, provisioner installationBackupProvisioner, instanceID string, logger log.FieldLogger) *InstallationBackupSupervisor {
+	return &InstallationBackupSupervisor{
+		store:       store,
+		provisioner: provisioner,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the installation backup supervisor.
+func (s *InstallationBackupSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down installation backup supervisor")
+}
+
+// Do looks for work to be done on any pending installation backups and
+// attempts to schedule the required work.
+func (s *InstallationBackupSupervisor) Do() error {
+	backups, err := s.store.GetUnlockedInstallationBackupsPendingWork()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installation backups pending work")
+		return nil
+	}
+
+	for _, backup := range backups {
+		s.Supervise(backup)
+	}
+
+	return nil
+}
+
+// Supervise schedules the required work on the given installation backup.
+func (s *InstallationBackupSupervisor) Supervise(backup *model.InstallationBackup) {
+	logger := s.logger.WithFields(log.Fields{
+		"installationBackup": backup.ID,
+		"installation":       backup.InstallationID,
+	})
+
+	lock := newInstallationBackupLock(backup.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	// Before working on the backup, it is crucial that we ensure that it was
+	// not updated to a new state by another provisioning server.
+	originalState := backup.State
+	backup, err := s.store.GetInstallationBackup(backup.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed installation backup")
+		return
+	}
+	if backup == nil || backup.State != originalState {
+		logger.Warn("Another provisioner has worked on this installation backup; skipping...")
+		return
+	}
+
+	logger.Debugf("Supervising installation backup in state %s", backup.State)
+
+	oldState := backup.State
+	newState := s.transitionInstallationBackup(backup, logger)
+	if newState == oldState {
+		return
+	}
+
+	backup.State = newState
+	err = s.store.UpdateInstallationBackup(backup)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to set installation backup state to %s", newState)
+		return
+	}
+
+	s.sendWebhook(backup, oldState, logger)
+
+	logger.Debugf("Transitioned installation backup from %s to %s", oldState, newState)
+}
+
+// transitionInstallationBackup works with the given installation backup to
+// transition it to a final state.
+func (s *InstallationBackupSupervisor) transitionInstallationBackup(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	switch backup.State {
+	case model.InstallationBackupStateBackupRequested:
+		return s.startBackup(backup, logger)
+	case model.InstallationBackupStateBackupInProgress:
+		return s.checkBackup(backup, logger)
+	case model.InstallationBackupStateRestoreRequested:
+		return s.startRestore(backup, logger)
+	case model.InstallationBackupStateRestoreInProgress:
+		return s.checkRestore(backup, logger)
+	default:
+		logger.Warnf("Found installation backup pending work in unexpected state %s", backup.State)
+		return backup.State
+	}
+}
+
+func (s *InstallationBackupSupervisor) startBackup(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	// The installation lock keeps the installation from being changed while
+	// the backup is started. Once the backup is in progress, the installation
+	// supervisor holds off on updates, hibernation and deletion until it
+	// finishes.
+	lock := newInstallationLock(backup.InstallationID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		logger.Debug("Installation is locked; retrying later")
+		return backup.State
+	}
+	defer lock.Unlock()
+
+	installation, err := s.store.GetInstallation(backup.InstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation")
+		return backup.State
+	}
+	if installation == nil || installation.State == model.InstallationStateDeleted {
+		logger.Error("Installation no longer exists; failing backup")
+		return model.InstallationBackupStateBackupFailed
+	}
+	if installation.State != model.InstallationStateStable &&
+		installation.State != model.InstallationStateHibernating {
+		logger.Debugf("Installation is in state %s; waiting for it to settle before taking a backup", installation.State)
+		return backup.State
+	}
+
+	cluster, clusterInstallation, err := s.getClusterInstallationForInstallation(installation.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation to back up")
+		return backup.State
+	}
+
+	err = s.provisioner.TriggerInstallationBackup(cluster, installation, clusterInstallation, backup)
+	if err != nil {
+		logger.WithError(err).Error("Failed to trigger installation backup")
+		return backup.State
+	}
+
+	backup.ClusterInstallationID = clusterInstallation.ID
+	logger.Info("Installation backup started")
+
+	return model.InstallationBackupStateBackupInProgress
+}
+
+func (s *InstallationBackupSupervisor) checkBackup(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	cluster, clusterInstallation, err := s.getClusterInstallation(backup.ClusterInstallationID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get backed up cluster installation")
+		return backup.State
+	}
+
+	jobState, err := s.provisioner.CheckInstallationBackupJob(cluster, clusterInstallation, backup)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check installation backup job")
+		return backup.State
+	}
+
+	switch jobState {
+	case model.BackupJobStateSucceeded:
+		backup.CompleteAt = store.GetMillis()
+		logger.Info("Installation backup completed")
+		return model.InstallationBackupStateBackupSucceeded
+	case model.BackupJobStateFailed:
+		logger.Error("Installation backup job failed")
+		return model.InstallationBackupStateBackupFailed
+	}
+
+	return backup.State
+}
+
+func (s *InstallationBackupSupervisor) startRestore(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	logger = logger.WithField("targetInstallation", backup.RestoreInstallationID)
+
+	sourceInstallation, err := s.store.GetInstallation(backup.InstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get backed up installation")
+		return backup.State
+	}
+	if sourceInstallation == nil {
+		logger.Error("Backed up installation no longer exists; cancelling restore")
+		return s.finishRestore(backup, model.InstallationStateRestorationFailed, logger)
+	}
+
+	targetInstallation, err := s.store.GetInstallation(backup.RestoreInstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get target installation")
+		return backup.State
+	}
+	if targetInstallation == nil {
+		logger.Error("Target installation no longer exists; cancelling restore")
+		return model.InstallationBackupStateBackupSucceeded
+	}
+
+	switch {
+	case targetInstallation.State == model.InstallationStateRestorationInProgress:
+	case targetInstallation.State == model.InstallationStateStable:
+		// Installations created for the restore are claimed once they are
+		// stable.
+		var claimed bool
+		targetInstallation, claimed = s.claimRestoreTarget(backup, logger)
+		if !claimed {
+			return backup.State
+		}
+	case isInstallationCreationState(targetInstallation.State):
+		logger.Debugf("Target installation is in state %s; waiting for it to be created", targetInstallation.State)
+		return backup.State
+	default:
+		logger.Errorf("Target installation is in state %s and no longer awaiting a restore; cancelling restore", targetInstallation.State)
+		return model.InstallationBackupStateBackupSucceeded
+	}
+
+	cluster, clusterInstallation, err := s.getClusterInstallationForInstallation(targetInstallation.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation to restore to")
+		return backup.State
+	}
+
+	err = s.provisioner.TriggerInstallationRestore(cluster, sourceInstallation, targetInstallation, clusterInstallation, backup)
+	if err != nil {
+		logger.WithError(err).Error("Failed to trigger installation restore")
+		return backup.State
+	}
+
+	logger.Info("Installation restore started")
+
+	return model.InstallationBackupStateRestoreInProgress
+}
+
+func (s *InstallationBackupSupervisor) checkRestore(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	logger = logger.WithField("targetInstallation", backup.RestoreInstallationID)
+
+	cluster, clusterInstallation, err := s.getClusterInstallationForInstallation(backup.RestoreInstallationID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get restored cluster installation")
+		return backup.State
+	}
+
+	jobState, err := s.provisioner.CheckInstallationRestoreJob(cluster, clusterInstallation, backup)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check installation restore job")
+		return backup.State
+	}
+
+	switch jobState {
+	case model.BackupJobStateSucceeded:
+		logger.Info("Installation restore completed")
+		return s.finishRestore(backup, model.InstallationStateStable, logger)
+	case model.BackupJobStateFailed:
+		logger.Error("Installation restore job failed")
+		return s.finishRestore(backup, model.InstallationStateRestorationFailed, logger)
+	}
+
+	return backup.State
+}
+
+// finishRestore moves the target installation of a restore to the given state
+// and returns the backup to a restorable state. The backup is left unchanged
+// if the target installation could not be updated, so that it is retried on
+// the next work cycle.
+func (s *InstallationBackupSupervisor) finishRestore(backup *model.InstallationBackup, installationState string, logger log.FieldLogger) string {
+	lock := newInstallationLock(backup.RestoreInstallationID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		logger.Debug("Target installation is locked; retrying later")
+		return backup.State
+	}
+	defer lock.Unlock()
+
+	installation, err := s.store.GetInstallation(backup.RestoreInstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get target installation")
+		return backup.State
+	}
+	if installation == nil || installation.State != model.InstallationStateRestorationInProgress {
+		return model.InstallationBackupStateBackupSucceeded
+	}
+
+	oldState := installation.State
+	installation.State = installationState
+	err = s.store.UpdateInstallationState(installation)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to set target installation state to %s", installationState)
+		return backup.State
+	}
+	s.sendInstallationWebhook(installation, oldState, logger)
+
+	return model.InstallationBackupStateBackupSucceeded
+}
+
+// claimRestoreTarget moves the stable target installation of a restore to
+// the restoration-in-progress state. It returns false if the installation was
+// locked or changed state in the meantime.
+func (s *InstallationBackupSupervisor) claimRestoreTarget(backup *model.InstallationBackup, logger log.FieldLogger) (*model.Installation, bool) {
+	lock := newInstallationLock(backup.RestoreInstallationID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		logger.Debug("Target installation is locked; retrying later")
+		return nil, false
+	}
+	defer lock.Unlock()
+
+	installation, err := s.store.GetInstallation(backup.RestoreInstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get target installation")
+		return nil, false
+	}
+	if installation == nil || installation.State != model.InstallationStateStable {
+		return nil, false
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateRestorationInProgress
+	err = s.store.UpdateInstallationState(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to claim target installation for the restore")
+		return nil, false
+	}
+	s.sendInstallationWebhook(installation, oldState, logger)
+
+	return installation, true
+}
+
+// isInstallationCreationState returns true if the installation is still being
+// created.
+func isInstallationCreationState(state string) bool {
+	switch state {
+	case model.InstallationStateCreationRequested,
+		model.InstallationStateCreationPreProvisioning,
+		model.InstallationStateCreationInProgress,
+		model.InstallationStateCreationDNS,
+		model.InstallationStateCreationNoCompatibleClusters,
+		model.InstallationStateCreationFinalTasks:
+		return true
+	}
+
+	return false
+}
+
+// getClusterInstallationForInstallation returns the cluster installation of
+// the given installation along with the cluster it runs on.
+func (s *InstallationBackupSupervisor) getClusterInstallationForInstallation(installationID string) (*model.Cluster, *model.ClusterInstallation, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installationID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get cluster installations")
+	}
+	if len(clusterInstallations) == 0 {
+		return nil, nil, errors.Errorf("no cluster installations found for installation %s", installationID)
+	}
+
+	return s.getClusterInstallation(clusterInstallations[0].ID)
+}
+
+// getClusterInstallation returns the given cluster installation along with
+// the cluster it runs on.
+func (s *InstallationBackupSupervisor) getClusterInstallation(clusterInstallationID string) (*model.Cluster, *model.ClusterInstallation, error) {
+	clusterInstallation, err := s.store.GetClusterInstallation(clusterInstallationID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get cluster installation")
+	}
+	if clusterInstallation == nil {
+		return nil, nil, errors.Errorf("cluster installation %s not found", clusterInstallationID)
+	}
+
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get cluster")
+	}
+	if cluster == nil {
+		return nil, nil, errors.Errorf("cluster %s not found", clusterInstallation.ClusterID)
+	}
+
+	return cluster, clusterInstallation, nil
+}
+
+func (s *InstallationBackupSupervisor) sendInstallationWebhook(installation *model.Installation, oldState string, logger log.FieldLogger) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		OwnerID:   installation.OwnerID,
+		GroupID:   installation.GroupID,
+		NewState:  installation.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	err := webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+func (s *InstallationBackupSupervisor) sendWebhook(backup *model.InstallationBackup, oldState string, logger log.FieldLogger) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationBackup,
+		ID:        backup.ID,
+		NewState:  backup.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Installation": backup.InstallationID},
+	}
+
+	installation, err := s.store.GetInstallation(backup.InstallationID, false, false)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get installation for webhook payload")
+	} else if installation != nil {
+		webhookPayload.OwnerID = installation.OwnerID
+		webhookPayload.GroupID = installation.GroupID
+	}
+
+	err = webhook.SendToAllWebhooks(s.store, &model.Event{Payload: webhookPayload, InstanceID: s.instanceID}, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
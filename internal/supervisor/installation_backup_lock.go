@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+type installationBackupLockStore interface {
+	LockInstallationBackup(backupID, lockerID string) (bool, error)
+	UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error)
+}
+
+type installationBackupLock struct {
+	backupID string
+	lockerID string
+	store    installationBackupLockStore
+	logger   log.FieldLogger
+}
+
+func newInstallationBackupLock(backupID, lockerID string, store installationBackupLockStore, logger log.FieldLogger) *installationBackupLock {
+	return &installationBackupLock{
+		backupID: backupID,
+		lockerID: lockerID,
+		store:    store,
+		logger:   logger,
+	}
+}
+
+func (l *installationBackupLock) TryLock() bool {
+	locked, err := l.store.LockInstallationBackup(l.backupID, l.lockerID)
+	if err != nil {
+		l.logger.WithError(err).Error("failed to lock installation backup")
+		return false
+	}
+
+	return locked
+}
+
+func (l *installationBackupLock) Unlock() {
+	unlocked, err := l.store.UnlockInstallationBackup(l.backupID, l.lockerID, false)
+	if err != nil {
+		l.logger.WithError(err).Error("failed to unlock installation backup")
+	} else if unlocked != true {
+		l.logger.Error("failed to release lock for installation backup")
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInstallationBackupProvisioner struct {
+	TriggerBackupError  error
+	TriggerRestoreError error
+	BackupJobState      string
+	RestoreJobState     string
+}
+
+func (p *mockInstallationBackupProvisioner) TriggerInstallationBackup(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error {
+	return p.TriggerBackupError
+}
+
+func (p *mockInstallationBackupProvisioner) CheckInstallationBackupJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error) {
+	return p.BackupJobState, nil
+}
+
+func (p *mockInstallationBackupProvisioner) TriggerInstallationRestore(cluster *model.Cluster, sourceInstallation, targetInstallation *model.Installation, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) error {
+	return p.TriggerRestoreError
+}
+
+func (p *mockInstallationBackupProvisioner) CheckInstallationRestoreJob(cluster *model.Cluster, targetClusterInstallation *model.ClusterInstallation, backup *model.InstallationBackup) (string, error) {
+	return p.RestoreJobState, nil
+}
+
+func TestInstallationBackupSupervisor(t *testing.T) {
+	setup := func(t *testing.T, installationState, backupState string) (*store.SQLStore, *model.Installation, *model.InstallationBackup) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		cluster := &model.Cluster{State: model.ClusterStateStable}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "backup.example.com",
+			State:   installationState,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		backup := &model.InstallationBackup{
+			InstallationID: installation.ID,
+			State:          backupState,
+		}
+		if backupState != model.InstallationBackupStateBackupRequested {
+			backup.ClusterInstallationID = clusterInstallation.ID
+		}
+		if backupState == model.InstallationBackupStateRestoreRequested ||
+			backupState == model.InstallationBackupStateRestoreInProgress {
+			backup.RestoreInstallationID = installation.ID
+		}
+		err = sqlStore.CreateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		return sqlStore, installation, backup
+	}
+
+	expectBackupState := func(t *testing.T, sqlStore *store.SQLStore, backup *model.InstallationBackup, expectedState string) *model.InstallationBackup {
+		t.Helper()
+		backup, err := sqlStore.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, backup.State)
+
+		return backup
+	}
+
+	expectInstallationState := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation, expectedState string) {
+		t.Helper()
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, installation.State)
+	}
+
+	t.Run("backup started", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		backup = expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupInProgress)
+		require.NotEmpty(t, backup.ClusterInstallationID)
+	})
+
+	t.Run("backup waits for unstable installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateUpdateInProgress, model.InstallationBackupStateBackupRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupRequested)
+	})
+
+	t.Run("backup waits for locked installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupRequested)
+
+		locked, err := sqlStore.LockInstallation(installation.ID, model.NewID())
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupRequested)
+	})
+
+	t.Run("backup trigger error is retried", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupRequested)
+
+		provisioner := &mockInstallationBackupProvisioner{TriggerBackupError: errors.New("failed")}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupRequested)
+	})
+
+	t.Run("backup job running", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupInProgress)
+
+		provisioner := &mockInstallationBackupProvisioner{BackupJobState: model.BackupJobStateRunning}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupInProgress)
+	})
+
+	t.Run("backup job succeeded", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupInProgress)
+
+		provisioner := &mockInstallationBackupProvisioner{BackupJobState: model.BackupJobStateSucceeded}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		backup = expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupSucceeded)
+		require.NotZero(t, backup.CompleteAt)
+	})
+
+	t.Run("backup job failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateBackupInProgress)
+
+		provisioner := &mockInstallationBackupProvisioner{BackupJobState: model.BackupJobStateFailed}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupFailed)
+	})
+
+	t.Run("restore started", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateRestorationInProgress, model.InstallationBackupStateRestoreRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateRestoreInProgress)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateRestorationInProgress)
+	})
+
+	t.Run("restore claims new installation once stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateStable, model.InstallationBackupStateRestoreRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateRestoreInProgress)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateRestorationInProgress)
+	})
+
+	t.Run("restore waits for new installation to be created", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateCreationInProgress, model.InstallationBackupStateRestoreRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateRestoreRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+	})
+
+	t.Run("restore cancelled when new installation fails creation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateCreationFailed, model.InstallationBackupStateRestoreRequested)
+
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupSucceeded)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationFailed)
+	})
+
+	t.Run("restore job succeeded", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateRestorationInProgress, model.InstallationBackupStateRestoreInProgress)
+
+		provisioner := &mockInstallationBackupProvisioner{RestoreJobState: model.BackupJobStateSucceeded}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupSucceeded)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+
+	t.Run("restore job failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateRestorationInProgress, model.InstallationBackupStateRestoreInProgress)
+
+		provisioner := &mockInstallationBackupProvisioner{RestoreJobState: model.BackupJobStateFailed}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupSucceeded)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateRestorationFailed)
+	})
+
+	t.Run("restore job waits for locked installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, installation, backup := setup(t, model.InstallationStateRestorationInProgress, model.InstallationBackupStateRestoreInProgress)
+
+		locked, err := sqlStore.LockInstallation(installation.ID, model.NewID())
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		provisioner := &mockInstallationBackupProvisioner{RestoreJobState: model.BackupJobStateSucceeded}
+		backupSupervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, model.NewID(), logger)
+		backupSupervisor.Supervise(backup)
+
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateRestoreInProgress)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateRestorationInProgress)
+	})
+}
@@ -9,7 +9,9 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/acm"
+	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/mattermost/mattermost-cloud/internal/placement"
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
@@ -72,6 +74,10 @@ func (s *mockInstallationStore) UpdateInstallationState(installation *model.Inst
 	return nil
 }
 
+func (s *mockInstallationStore) UpdateInstallationMigrationTarget(installation *model.Installation) error {
+	return nil
+}
+
 func (s *mockInstallationStore) LockInstallation(installationID, lockerID string) (bool, error) {
 	return true, nil
 }
@@ -115,6 +121,18 @@ func (s *mockInstallationStore) GetWebhooks(filter *model.WebhookFilter) ([]*mod
 	return nil, nil
 }
 
+func (s *mockInstallationStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
+func (s *mockInstallationStore) GetLatestResourceEvent(resourceID string) (*model.Event, error) {
+	return nil, nil
+}
+
+func (s *mockInstallationStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
 func (s *mockInstallationStore) GetMultitenantDatabase(multitenantdatabaseID string) (*model.MultitenantDatabase, error) {
 	return nil, nil
 }
@@ -135,10 +153,42 @@ func (s *mockInstallationStore) UnlockMultitenantDatabase(multitenantdatabaseID,
 	return true, nil
 }
 
+func (s *mockInstallationStore) LockMultitenantDatabaseVPC(vpcID, lockerID string) (bool, error) {
+	return true, nil
+}
+
+func (s *mockInstallationStore) UnlockMultitenantDatabaseVPC(vpcID, lockerID string, force bool) (bool, error) {
+	return true, nil
+}
+
 func (s *mockInstallationStore) UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error {
 	return nil
 }
 
+func (s *mockInstallationStore) DeleteMultitenantDatabase(multitenantDatabaseID string) error {
+	return nil
+}
+
+func (s *mockInstallationStore) GetInstallationBackup(backupID string) (*model.InstallationBackup, error) {
+	return nil, nil
+}
+
+func (s *mockInstallationStore) GetInstallationBackups(filter *model.InstallationBackupFilter) ([]*model.InstallationBackup, error) {
+	return nil, nil
+}
+
+func (s *mockInstallationStore) UpdateInstallationBackup(backup *model.InstallationBackup) error {
+	return nil
+}
+
+func (s *mockInstallationStore) LockInstallationBackup(backupID, lockerID string) (bool, error) {
+	return true, nil
+}
+
+func (s *mockInstallationStore) UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error) {
+	return true, nil
+}
+
 func (s *mockInstallationStore) GetMultitenantDatabaseForInstallationID(installationID string) (*model.MultitenantDatabase, error) {
 	return nil, nil
 }
@@ -146,6 +196,7 @@ func (s *mockInstallationStore) GetMultitenantDatabaseForInstallationID(installa
 type mockInstallationProvisioner struct {
 	UseCustomClusterResources bool
 	CustomClusterResources    *k8s.ClusterResources
+	DatabaseMigrationJobState string
 }
 
 func (p *mockInstallationProvisioner) CreateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, awsClient aws.AWS) error {
@@ -193,9 +244,29 @@ func (p *mockInstallationProvisioner) GetPublicLoadBalancerEndpoint(cluster *mod
 	return "example.elb.us-east-1.amazonaws.com", nil
 }
 
+func (p *mockInstallationProvisioner) TriggerInstallationDatabaseMigration(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+	return nil
+}
+
+func (p *mockInstallationProvisioner) CheckInstallationDatabaseMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (string, error) {
+	if p.DatabaseMigrationJobState != "" {
+		return p.DatabaseMigrationJobState, nil
+	}
+
+	return model.BackupJobStateRunning, nil
+}
+
+func (p *mockInstallationProvisioner) RefreshClusterInstallationDatabase(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+	return nil
+}
+
 // TODO(gsagula): this can be replaced with /internal/mocks/aws-tools/AWS.go so that inputs and other variants
 // can be tested.
-type mockAWS struct{}
+type mockAWS struct {
+	SpotInterruptionWarnings []string
+	PublicCNAMECalls         int
+	ClaimedVPCs              map[string]string
+}
 
 func (a *mockAWS) GetCertificateSummaryByTag(key, value string, logger log.FieldLogger) (*acm.CertificateSummary, error) {
 	return nil, nil
@@ -221,6 +292,10 @@ func (a *mockAWS) GetAndClaimVpcResources(clusterID, owner string, logger log.Fi
 	return aws.ClusterResources{}, nil
 }
 
+func (a *mockAWS) GetClaimedVpcID(clusterID string, logger log.FieldLogger) (string, error) {
+	return a.ClaimedVPCs[clusterID], nil
+}
+
 func (a *mockAWS) ReleaseVpc(clusterID string, logger log.FieldLogger) error {
 	return nil
 }
@@ -252,6 +327,7 @@ func (a *mockAWS) CreatePrivateCNAME(dnsName string, dnsEndpoints []string, logg
 }
 
 func (a *mockAWS) CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error {
+	a.PublicCNAMECalls++
 	return nil
 }
 
@@ -264,6 +340,7 @@ func (a *mockAWS) DeletePrivateCNAME(dnsName string, logger log.FieldLogger) err
 }
 
 func (a *mockAWS) DeletePublicCNAME(dnsName string, logger log.FieldLogger) error {
+	a.PublicCNAMECalls++
 	return nil
 }
 
@@ -279,6 +356,30 @@ func (a *mockAWS) IsValidAMI(AMIID string, logger log.FieldLogger) (bool, error)
 	return true, nil
 }
 
+func (a *mockAWS) GetSpotInterruptionWarnings(kopsClusterName string, logger log.FieldLogger) ([]string, error) {
+	return a.SpotInterruptionWarnings, nil
+}
+
+func (a *mockAWS) EnsureEKSCluster(cluster *model.Cluster, resources aws.ClusterResources, logger log.FieldLogger) (*eks.Cluster, error) {
+	return &eks.Cluster{}, nil
+}
+
+func (a *mockAWS) EnsureEKSNodeGroup(cluster *model.Cluster, nodeGroup *model.EKSNodeGroup, subnetIDs []string, logger log.FieldLogger) (*eks.Nodegroup, error) {
+	return &eks.Nodegroup{}, nil
+}
+
+func (a *mockAWS) GetEKSCluster(clusterName string) (*eks.Cluster, error) {
+	return nil, nil
+}
+
+func (a *mockAWS) GetEKSNodeGroups(clusterName string) ([]*eks.Nodegroup, error) {
+	return nil, nil
+}
+
+func (a *mockAWS) EnsureEKSClusterDeleted(clusterName string, logger log.FieldLogger) error {
+	return nil
+}
+
 func (a *mockAWS) S3FilestoreProvision(installationID string, logger log.FieldLogger) error {
 	return nil
 }
@@ -296,7 +397,7 @@ func TestInstallationSupervisorDo(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		mockStore := &mockInstallationStore{}
 
-		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(mockStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -314,7 +415,7 @@ func TestInstallationSupervisorDo(t *testing.T) {
 		mockStore.Installation = mockStore.UnlockedInstallationsPendingWork[0]
 		mockStore.UnlockChan = make(chan interface{})
 
-		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(mockStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -370,7 +471,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("unexpected state", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -408,7 +509,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("state has changed since installation was selected to be worked on", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -441,7 +542,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations not yet created, no clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		owner := model.NewID()
 		groupID := model.NewID()
@@ -466,7 +567,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations not yet created, cluster doesn't allow scheduling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		cluster.AllowInstallations = false
@@ -496,7 +597,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations not yet created, no empty clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -534,7 +635,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -572,7 +673,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -610,7 +711,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation DNS, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -645,10 +746,150 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
 	})
 
+	t.Run("creation DNS, local cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		awsClient := &mockAWS{}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, awsClient, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := &model.Cluster{
+			Provisioner:              model.ProvisionerLocal,
+			ProvisionerMetadataLocal: &model.LocalMetadata{Name: "local", Engine: model.LocalClusterEngineKind},
+			State:                    model.ClusterStateStable,
+			AllowInstallations:       true,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			State:     model.InstallationStateCreationDNS,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		require.Equal(t, 0, awsClient.PublicCNAMECalls)
+	})
+
+	t.Run("deletion final cleanup, local cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		awsClient := &mockAWS{}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, awsClient, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := &model.Cluster{
+			Provisioner:              model.ProvisionerLocal,
+			ProvisionerMetadataLocal: &model.LocalMetadata{Name: "local", Engine: model.LocalClusterEngineKind},
+			State:                    model.ClusterStateStable,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			State:     model.InstallationStateDeletionFinalCleanup,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeleted,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+		err = sqlStore.DeleteClusterInstallation(clusterInstallation.ID)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeleted)
+		require.Equal(t, 0, awsClient.PublicCNAMECalls)
+	})
+
+	t.Run("deletion final cleanup, restorable backup", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := &model.Cluster{
+			Provisioner:              model.ProvisionerLocal,
+			ProvisionerMetadataLocal: &model.LocalMetadata{Name: "local", Engine: model.LocalClusterEngineKind},
+			State:                    model.ClusterStateStable,
+		}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			State:     model.InstallationStateDeletionFinalCleanup,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeleted,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+		err = sqlStore.DeleteClusterInstallation(clusterInstallation.ID)
+		require.NoError(t, err)
+
+		backup := &model.InstallationBackup{
+			InstallationID: installation.ID,
+			State:          model.InstallationBackupStateBackupSucceeded,
+		}
+		err = sqlStore.CreateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeleted)
+
+		backup, err = sqlStore.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationBackupStateDeleted, backup.State)
+	})
+
 	t.Run("creation requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -686,7 +927,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("pre provisioning requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -724,7 +965,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations failed", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -762,7 +1003,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -800,7 +1041,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -838,7 +1079,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations failed", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -876,7 +1117,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation final tasks, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -914,7 +1155,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, no clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		owner := model.NewID()
 		groupID := model.NewID()
@@ -939,7 +1180,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, no available clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -977,7 +1218,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, available cluster", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1006,7 +1247,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1041,25 +1282,22 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
 	})
 
-	t.Run("update in progress, cluster installations reconciling", func(t *testing.T) {
+	t.Run("update requested, backup in progress", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
+			OwnerID:  model.NewID(),
 			Version:  "version",
 			DNS:      "dns.example.com",
 			Size:     mmv1alpha1.Size100String,
 			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateUpdateInProgress,
+			State:    model.InstallationStateUpdateRequested,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1069,20 +1307,27 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateReconciling,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
+		err = sqlStore.CreateInstallationBackup(&model.InstallationBackup{
+			InstallationID:        installation.ID,
+			ClusterInstallationID: clusterInstallation.ID,
+			State:                 model.InstallationBackupStateBackupInProgress,
+		})
+		require.NoError(t, err)
+
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateRequested)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("update in progress, cluster installations stable", func(t *testing.T) {
+	t.Run("update in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1107,20 +1352,20 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateStable,
+			State:          model.ClusterInstallationStateReconciling,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
 	})
 
-	t.Run("hibernation requested, cluster installations stable", func(t *testing.T) {
+	t.Run("update in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1135,7 +1380,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			Size:     mmv1alpha1.Size100String,
 			Affinity: model.InstallationAffinityIsolated,
 			GroupID:  &groupID,
-			State:    model.InstallationStateHibernationRequested,
+			State:    model.InstallationStateUpdateInProgress,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1151,14 +1396,14 @@ func TestInstallationSupervisor(t *testing.T) {
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("hibernation in progress, cluster installations reconciling", func(t *testing.T) {
+	t.Run("hibernation requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1173,7 +1418,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			Size:     mmv1alpha1.Size100String,
 			Affinity: model.InstallationAffinityIsolated,
 			GroupID:  &groupID,
-			State:    model.InstallationStateHibernationInProgress,
+			State:    model.InstallationStateHibernationRequested,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1183,7 +1428,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateReconciling,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
@@ -1193,10 +1438,10 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
 	})
 
-	t.Run("hibernation in progress, cluster installations stable", func(t *testing.T) {
+	t.Run("hibernation in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1221,20 +1466,20 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateStable,
+			State:          model.ClusterInstallationStateReconciling,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernating)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
 	})
 
-	t.Run("deletion requested, cluster installations stable", func(t *testing.T) {
+	t.Run("hibernation in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1249,7 +1494,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			Size:     mmv1alpha1.Size100String,
 			Affinity: model.InstallationAffinityIsolated,
 			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			State:    model.InstallationStateHibernationInProgress,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1265,29 +1510,31 @@ func TestInstallationSupervisor(t *testing.T) {
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernating)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("deletion requested, cluster installations deleting", func(t *testing.T) {
+	t.Run("migration requested, target cluster available", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
+		targetCluster := standardStableTestCluster()
+		err = sqlStore.CreateCluster(targetCluster)
+		require.NoError(t, err)
+
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			OwnerID:                  model.NewID(),
+			Version:                  "version",
+			DNS:                      "dns.example.com",
+			Size:                     mmv1alpha1.Size100String,
+			Affinity:                 model.InstallationAffinityIsolated,
+			State:                    model.InstallationStateMigrationRequested,
+			MigrationTargetClusterID: targetCluster.ID,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1297,35 +1544,39 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeletionRequested,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+		expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 1)
 	})
 
-	t.Run("deletion in progress, cluster installations failed", func(t *testing.T) {
+	t.Run("migration requested without target, other cluster available", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
+		cluster.AllowInstallations = false
+		cluster.Draining = true
 		err := sqlStore.CreateCluster(cluster)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
+		otherCluster := standardStableTestCluster()
+		err = sqlStore.CreateCluster(otherCluster)
+		require.NoError(t, err)
+
 		installation := &model.Installation{
-			OwnerID:  owner,
+			OwnerID:  model.NewID(),
 			Version:  "version",
 			DNS:      "dns.example.com",
 			Size:     mmv1alpha1.Size100String,
 			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionInProgress,
+			State:    model.InstallationStateMigrationRequested,
 		}
 
 		err = sqlStore.CreateInstallation(installation)
@@ -1335,39 +1586,679 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeletionFailed,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionFailed)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionFailed)
-	})
-
-	t.Run("deletion requested, cluster installations failed, so retry", func(t *testing.T) {
-		logger := testlib.MakeLogger(t)
-		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+		expectClusterInstallationsOnCluster(t, sqlStore, otherCluster, 1)
 
-		cluster := standardStableTestCluster()
-		err := sqlStore.CreateCluster(cluster)
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
 		require.NoError(t, err)
+		require.Equal(t, otherCluster.ID, installation.MigrationTargetClusterID)
+	})
 
-		owner := model.NewID()
-		groupID := model.NewID()
-		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
-		}
+	t.Run("migration requested, aws-rds database", func(t *testing.T) {
+		// The VPC of source clusters created before it was kept in their
+		// metadata is only known from the VPC claimed by them.
+		setup := func(t *testing.T, targetVPC string, withTarget, sourceVPCRecorded bool) (*store.SQLStore, *supervisor.InstallationSupervisor, *model.Installation, *model.Cluster, *model.Cluster) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			mockAWS := &mockAWS{ClaimedVPCs: make(map[string]string)}
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, mockAWS, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, utils.NewResourceUtil("instanceID", nil, nil), logger)
 
-		err = sqlStore.CreateInstallation(installation)
-		require.NoError(t, err)
+			cluster := standardStableTestCluster()
+			if sourceVPCRecorded {
+				cluster.ProvisionerMetadataKops.VPC = "vpc1"
+			}
+			cluster.AllowInstallations = false
+			cluster.Draining = !withTarget
+			err := sqlStore.CreateCluster(cluster)
+			require.NoError(t, err)
+			mockAWS.ClaimedVPCs[cluster.ID] = "vpc1"
+
+			targetCluster := standardStableTestCluster()
+			targetCluster.ProvisionerMetadataKops.VPC = targetVPC
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := &model.Installation{
+				OwnerID:   model.NewID(),
+				Version:   "version",
+				DNS:       "dns.example.com",
+				Size:      mmv1alpha1.Size100String,
+				Affinity:  model.InstallationAffinityIsolated,
+				Database:  model.InstallationDatabaseSingleTenantRDSMySQL,
+				Filestore: model.InstallationFilestoreAwsS3,
+				State:     model.InstallationStateMigrationRequested,
+			}
+			if withTarget {
+				installation.MigrationTargetClusterID = targetCluster.ID
+			}
+
+			err = sqlStore.CreateInstallation(installation)
+			require.NoError(t, err)
+
+			clusterInstallation := &model.ClusterInstallation{
+				ClusterID:      cluster.ID,
+				InstallationID: installation.ID,
+				Namespace:      "namespace",
+				State:          model.ClusterInstallationStateStable,
+			}
+			err = sqlStore.CreateClusterInstallation(clusterInstallation)
+			require.NoError(t, err)
+
+			return sqlStore, supervisor, installation, cluster, targetCluster
+		}
+
+		t.Run("target cluster in the same VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc1", true, true)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 1)
+		})
+
+		t.Run("source cluster without recorded VPC, target cluster in the same VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc1", true, false)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 1)
+		})
+
+		t.Run("source cluster without recorded VPC, target cluster in another VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc2", true, false)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 0)
+		})
+
+		t.Run("target cluster in another VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc2", true, true)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 0)
+		})
+
+		t.Run("without target, only cluster in another VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc2", false, true)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationRequested)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 0)
+		})
+
+		t.Run("without target, cluster in the same VPC", func(t *testing.T) {
+			sqlStore, supervisor, installation, cluster, targetCluster := setup(t, "vpc1", false, true)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 1)
+		})
+	})
+
+	t.Run("migration requested, target cluster deleted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		targetCluster := standardStableTestCluster()
+		err = sqlStore.CreateCluster(targetCluster)
+		require.NoError(t, err)
+		err = sqlStore.DeleteCluster(targetCluster.ID)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                  model.NewID(),
+			Version:                  "version",
+			DNS:                      "dns.example.com",
+			Size:                     mmv1alpha1.Size100String,
+			Affinity:                 model.InstallationAffinityIsolated,
+			State:                    model.InstallationStateMigrationRequested,
+			MigrationTargetClusterID: targetCluster.ID,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+	})
+
+	t.Run("migration in progress, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		targetCluster := standardStableTestCluster()
+		err = sqlStore.CreateCluster(targetCluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                  model.NewID(),
+			Version:                  "version",
+			DNS:                      "dns.example.com",
+			Size:                     mmv1alpha1.Size100String,
+			Affinity:                 model.InstallationAffinityIsolated,
+			State:                    model.InstallationStateMigrationInProgress,
+			MigrationTargetClusterID: targetCluster.ID,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		sourceClusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(sourceClusterInstallation)
+		require.NoError(t, err)
+
+		targetClusterInstallation := &model.ClusterInstallation{
+			ClusterID:      targetCluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(targetClusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationCleanup)
+
+		sourceClusterInstallation, err = sqlStore.GetClusterInstallation(sourceClusterInstallation.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterInstallationStateDeletionRequested, sourceClusterInstallation.State)
+
+		targetClusterInstallation, err = sqlStore.GetClusterInstallation(targetClusterInstallation.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterInstallationStateStable, targetClusterInstallation.State)
+	})
+
+	t.Run("migration cleanup, source cluster installation deleted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		targetCluster := standardStableTestCluster()
+		err = sqlStore.CreateCluster(targetCluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                  model.NewID(),
+			Version:                  "version",
+			DNS:                      "dns.example.com",
+			Size:                     mmv1alpha1.Size100String,
+			Affinity:                 model.InstallationAffinityIsolated,
+			State:                    model.InstallationStateMigrationCleanup,
+			MigrationTargetClusterID: targetCluster.ID,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		sourceClusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeleted,
+		}
+		err = sqlStore.CreateClusterInstallation(sourceClusterInstallation)
+		require.NoError(t, err)
+		err = sqlStore.DeleteClusterInstallation(sourceClusterInstallation.ID)
+		require.NoError(t, err)
+
+		targetClusterInstallation := &model.ClusterInstallation{
+			ClusterID:      targetCluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(targetClusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Empty(t, installation.MigrationTargetClusterID)
+	})
+
+	t.Run("database migration requested, database type not supported", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                   model.NewID(),
+			Version:                   "version",
+			DNS:                       "dns.example.com",
+			Database:                  model.InstallationDatabaseMysqlOperator,
+			Size:                      mmv1alpha1.Size100String,
+			Affinity:                  model.InstallationAffinityIsolated,
+			State:                     model.InstallationStateDBMigrationRequested,
+			MigrationTargetDatabaseID: model.NewID(),
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+	})
+
+	t.Run("database migration in progress, cluster installations reconciling", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                   model.NewID(),
+			Version:                   "version",
+			DNS:                       "dns.example.com",
+			Database:                  model.InstallationDatabaseMultiTenantRDSMySQL,
+			Size:                      mmv1alpha1.Size100String,
+			Affinity:                  model.InstallationAffinityIsolated,
+			State:                     model.InstallationStateDBMigrationInProgress,
+			MigrationTargetDatabaseID: model.NewID(),
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateReconciling,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+	})
+
+	t.Run("database migration in progress, job running", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{DatabaseMigrationJobState: model.BackupJobStateRunning}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                   model.NewID(),
+			Version:                   "version",
+			DNS:                       "dns.example.com",
+			Database:                  model.InstallationDatabaseMultiTenantRDSMySQL,
+			Size:                      mmv1alpha1.Size100String,
+			Affinity:                  model.InstallationAffinityIsolated,
+			State:                     model.InstallationStateDBMigrationInProgress,
+			MigrationTargetDatabaseID: model.NewID(),
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+	})
+
+	t.Run("database migration in progress, job succeeded, installation already switched", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{DatabaseMigrationJobState: model.BackupJobStateSucceeded}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Database: model.InstallationDatabaseMultiTenantRDSMySQL,
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			State:    model.InstallationStateDBMigrationInProgress,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		targetDatabase := &model.MultitenantDatabase{
+			ID:            "rds-cluster-multitenant-target",
+			VpcID:         "vpc-1",
+			DatabaseType:  model.DatabaseEngineTypeMySQL,
+			State:         model.MultitenantDatabaseStateStable,
+			Installations: model.MultitenantDatabaseInstallations{installation.ID},
+		}
+		err = sqlStore.CreateMultitenantDatabase(targetDatabase)
+		require.NoError(t, err)
+
+		installation.MigrationTargetDatabaseID = targetDatabase.ID
+		err = sqlStore.UpdateInstallationMigrationTarget(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationCleanup)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, targetDatabase.ID, installation.MigrationTargetDatabaseID)
+
+		clusterInstallation.State = model.ClusterInstallationStateStable
+		err = sqlStore.UpdateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Empty(t, installation.MigrationTargetDatabaseID)
+	})
+
+	t.Run("database migration cleanup, cluster installations reconciling", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                   model.NewID(),
+			Version:                   "version",
+			DNS:                       "dns.example.com",
+			Database:                  model.InstallationDatabaseMultiTenantRDSMySQL,
+			Size:                      mmv1alpha1.Size100String,
+			Affinity:                  model.InstallationAffinityIsolated,
+			State:                     model.InstallationStateDBMigrationCleanup,
+			MigrationTargetDatabaseID: model.NewID(),
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateReconciling,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationCleanup)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.NotEmpty(t, installation.MigrationTargetDatabaseID)
+	})
+
+	t.Run("database migration in progress, job failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{DatabaseMigrationJobState: model.BackupJobStateFailed}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, provisioner, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:                   model.NewID(),
+			Version:                   "version",
+			DNS:                       "dns.example.com",
+			Database:                  model.InstallationDatabaseMysqlOperator,
+			Size:                      mmv1alpha1.Size100String,
+			Affinity:                  model.InstallationAffinityIsolated,
+			State:                     model.InstallationStateDBMigrationInProgress,
+			MigrationTargetDatabaseID: model.NewID(),
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDBMigrationFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Empty(t, installation.MigrationTargetDatabaseID)
+	})
+
+	t.Run("deletion requested, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+	})
+
+	t.Run("deletion requested, cluster installations deleting", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeletionRequested,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+	})
+
+	t.Run("deletion in progress, cluster installations failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionInProgress,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeletionFailed,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionFailed)
+	})
+
+	t.Run("deletion requested, cluster installations failed, so retry", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
 
 		clusterInstallation := &model.ClusterInstallation{
 			ClusterID:      cluster.ID,
@@ -1386,7 +2277,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations deleted", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1425,7 +2316,7 @@ func TestInstallationSupervisor(t *testing.T) {
 		t.Run("creation requested, cluster installations not yet created, available cluster", func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster)
@@ -1455,7 +2346,7 @@ func TestInstallationSupervisor(t *testing.T) {
 		t.Run("creation requested, cluster installations not yet created, 3 installations, available cluster", func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster)
@@ -1489,7 +2380,7 @@ func TestInstallationSupervisor(t *testing.T) {
 		t.Run("creation requested, cluster installations not yet created, 1 isolated and 1 multitenant, available cluster", func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster)
@@ -1548,7 +2439,7 @@ func TestInstallationSupervisor(t *testing.T) {
 					MilliUsedMemory:  100,
 				},
 			}
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, mockInstallationProvisioner, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster)
@@ -1588,7 +2479,7 @@ func TestInstallationSupervisor(t *testing.T) {
 				MilliUsedMemory:  100,
 			},
 		}
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, mockInstallationProvisioner, placement.FirstFitPolicy{}, 80, 2), false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster)
@@ -1614,4 +2505,47 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
 		expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
 	})
+
+	t.Run("creation requested, cluster installations not yet created, placement constraints", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", placement.NewScheduler(sqlStore, &mockInstallationProvisioner{}, placement.FirstFitPolicy{}, 80, 0), false, false, &utils.ResourceUtil{}, logger)
+
+		cluster1 := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster1)
+		require.NoError(t, err)
+
+		cluster2 := standardStableTestCluster()
+		cluster2.Labels = map[string]string{"zone": "us-east-1b"}
+		err = sqlStore.CreateCluster(cluster2)
+		require.NoError(t, err)
+
+		cluster3 := standardStableTestCluster()
+		cluster3.Labels = map[string]string{"zone": "us-east-1b"}
+		err = sqlStore.CreateCluster(cluster3)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityMultiTenant,
+			State:    model.InstallationStateCreationRequested,
+			PlacementConstraints: &model.PlacementConstraints{
+				RequiredLabels:  map[string]string{"zone": "us-east-1b"},
+				AvoidClusterIDs: []string{cluster2.ID},
+			},
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster1, 0)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster2, 0)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster3, 1)
+	})
 }
@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+type multitenantDatabaseLockStore interface {
+	LockMultitenantDatabase(multitenantDatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantDatabaseID, lockerID string, force bool) (bool, error)
+}
+
+type multitenantDatabaseLock struct {
+	multitenantDatabaseID string
+	lockerID              string
+	store                 multitenantDatabaseLockStore
+	logger                log.FieldLogger
+}
+
+func newMultitenantDatabaseLock(multitenantDatabaseID, lockerID string, store multitenantDatabaseLockStore, logger log.FieldLogger) *multitenantDatabaseLock {
+	return &multitenantDatabaseLock{
+		multitenantDatabaseID: multitenantDatabaseID,
+		lockerID:              lockerID,
+		store:                 store,
+		logger:                logger,
+	}
+}
+
+func (l *multitenantDatabaseLock) TryLock() bool {
+	locked, err := l.store.LockMultitenantDatabase(l.multitenantDatabaseID, l.lockerID)
+	if err != nil {
+		l.logger.WithError(err).Error("failed to lock multitenant database")
+		return false
+	}
+
+	return locked
+}
+
+func (l *multitenantDatabaseLock) Unlock() {
+	unlocked, err := l.store.UnlockMultitenantDatabase(l.multitenantDatabaseID, l.lockerID, false)
+	if err != nil {
+		l.logger.WithError(err).Error("failed to unlock multitenant database")
+	} else if unlocked != true {
+		l.logger.Error("failed to release lock for multitenant database")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// multitenantDatabaseRetirementStore abstracts the database operations
+// required by the multitenant database retirement supervisor.
+type multitenantDatabaseRetirementStore interface {
+	GetMultitenantDatabases(filter *model.MultitenantDatabaseFilter) ([]*model.MultitenantDatabase, error)
+	GetMultitenantDatabase(multitenantDatabaseID string) (*model.MultitenantDatabase, error)
+	UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	DeleteMultitenantDatabase(multitenantDatabaseID string) error
+	LockMultitenantDatabase(multitenantDatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantDatabaseID, lockerID string, force bool) (bool, error)
+}
+
+// multitenantDatabaseRetirer abstracts the provisioning operations required
+// by the multitenant database retirement supervisor.
+type multitenantDatabaseRetirer interface {
+	RetireMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase, logger log.FieldLogger) error
+}
+
+// MultitenantDatabaseRetirementSupervisor retires the multitenant databases
+// created by the provisioner once they have held no installations for the
+// grace period, which keeps a cluster around for installations created
+// shortly after the last one was deleted.
+type MultitenantDatabaseRetirementSupervisor struct {
+	store       multitenantDatabaseRetirementStore
+	retirer     multitenantDatabaseRetirer
+	gracePeriod time.Duration
+	instanceID  string
+	logger      log.FieldLogger
+}
+
+// NewMultitenantDatabaseRetirementSupervisor creates a new
+// MultitenantDatabaseRetirementSupervisor.
+func NewMultitenantDatabaseRetirementSupervisor(store multitenantDatabaseRetirementStore, retirer multitenantDatabaseRetirer, gracePeriod time.Duration, instanceID string, logger log.FieldLogger) *MultitenantDatabaseRetirementSupervisor {
+	return &MultitenantDatabaseRetirementSupervisor{
+		store:       store,
+		retirer:     retirer,
+		gracePeriod: gracePeriod,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the multitenant database
+// retirement supervisor.
+func (s *MultitenantDatabaseRetirementSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down multitenant database retirement supervisor")
+}
+
+// Do looks for managed multitenant databases and retires the ones that have
+// been empty for the grace period.
+func (s *MultitenantDatabaseRetirementSupervisor) Do() error {
+	multitenantDatabases, err := s.store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for multitenant databases")
+		return nil
+	}
+
+	for _, multitenantDatabase := range multitenantDatabases {
+		if !multitenantDatabase.Managed || multitenantDatabase.LockAcquiredAt != 0 {
+			continue
+		}
+		if multitenantDatabase.Installations.Count() > 0 && multitenantDatabase.EmptySince == 0 {
+			continue
+		}
+		s.Supervise(multitenantDatabase)
+	}
+
+	return nil
+}
+
+// Supervise records when the given multitenant database became empty and
+// retires it once it has stayed empty for the grace period.
+func (s *MultitenantDatabaseRetirementSupervisor) Supervise(multitenantDatabase *model.MultitenantDatabase) {
+	logger := s.logger.WithFields(log.Fields{
+		"multitenant-database": multitenantDatabase.ID,
+	})
+
+	lock := newMultitenantDatabaseLock(multitenantDatabase.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	// Installations are assigned under the same lock, so the database can't
+	// gain one while it is being retired.
+	multitenantDatabase, err := s.store.GetMultitenantDatabase(multitenantDatabase.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed multitenant database")
+		return
+	}
+	if multitenantDatabase == nil ||
+		!multitenantDatabase.Managed ||
+		multitenantDatabase.State == model.MultitenantDatabaseStateDeleted {
+		return
+	}
+
+	if multitenantDatabase.Installations.Count() > 0 {
+		if multitenantDatabase.EmptySince != 0 {
+			multitenantDatabase.EmptySince = 0
+			s.updateMultitenantDatabase(multitenantDatabase, logger)
+		}
+		return
+	}
+
+	now := store.GetMillis()
+	if multitenantDatabase.EmptySince == 0 {
+		multitenantDatabase.EmptySince = now
+		s.updateMultitenantDatabase(multitenantDatabase, logger)
+		return
+	}
+	if now-multitenantDatabase.EmptySince < s.gracePeriod.Milliseconds() {
+		return
+	}
+
+	logger.Info("Retiring multitenant database that has been empty for the grace period")
+
+	err = s.retirer.RetireMultitenantDatabase(multitenantDatabase, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to retire multitenant database")
+		return
+	}
+
+	err = s.store.DeleteMultitenantDatabase(multitenantDatabase.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to mark multitenant database as deleted")
+		return
+	}
+
+	logger.Info("Multitenant database retired")
+}
+
+func (s *MultitenantDatabaseRetirementSupervisor) updateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase, logger log.FieldLogger) {
+	err := s.store.UpdateMultitenantDatabase(multitenantDatabase)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update multitenant database")
+	}
+}
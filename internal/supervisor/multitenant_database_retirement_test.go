@@ -0,0 +1,145 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMultitenantDatabaseRetirer struct {
+	Retired []string
+}
+
+func (r *mockMultitenantDatabaseRetirer) RetireMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase, logger log.FieldLogger) error {
+	r.Retired = append(r.Retired, multitenantDatabase.ID)
+	return nil
+}
+
+func TestMultitenantDatabaseRetirementSupervisor(t *testing.T) {
+	setup := func(t *testing.T, managed bool, installations ...string) (*store.SQLStore, *model.MultitenantDatabase) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		multitenantDatabase := &model.MultitenantDatabase{
+			ID:                 model.NewID(),
+			VpcID:              "vpc-1",
+			DatabaseType:       model.DatabaseEngineTypePostgres,
+			Managed:            managed,
+			AllowInstallations: true,
+			Installations:      installations,
+		}
+		err := sqlStore.CreateMultitenantDatabase(multitenantDatabase)
+		require.NoError(t, err)
+
+		return sqlStore, multitenantDatabase
+	}
+
+	getMultitenantDatabase := func(t *testing.T, sqlStore *store.SQLStore, multitenantDatabase *model.MultitenantDatabase) *model.MultitenantDatabase {
+		t.Helper()
+		multitenantDatabase, err := sqlStore.GetMultitenantDatabase(multitenantDatabase.ID)
+		require.NoError(t, err)
+		return multitenantDatabase
+	}
+
+	t.Run("empty, first check", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, multitenantDatabase := setup(t, true)
+		retirer := &mockMultitenantDatabaseRetirer{}
+
+		retirementSupervisor := supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, retirer, time.Hour, model.NewID(), logger)
+		err := retirementSupervisor.Do()
+		require.NoError(t, err)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		require.NotZero(t, multitenantDatabase.EmptySince)
+		require.Zero(t, multitenantDatabase.DeleteAt)
+		require.Empty(t, retirer.Retired)
+	})
+
+	t.Run("empty, grace period elapsed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, multitenantDatabase := setup(t, true)
+		retirer := &mockMultitenantDatabaseRetirer{}
+
+		retirementSupervisor := supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, retirer, 0, model.NewID(), logger)
+		err := retirementSupervisor.Do()
+		require.NoError(t, err)
+		require.Empty(t, retirer.Retired)
+
+		err = retirementSupervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, []string{multitenantDatabase.ID}, retirer.Retired)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		require.Equal(t, model.MultitenantDatabaseStateDeleted, multitenantDatabase.State)
+		require.NotZero(t, multitenantDatabase.DeleteAt)
+		require.Zero(t, multitenantDatabase.LockAcquiredAt)
+	})
+
+	t.Run("installation assigned during the grace period", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, multitenantDatabase := setup(t, true)
+		retirer := &mockMultitenantDatabaseRetirer{}
+
+		retirementSupervisor := supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, retirer, 0, model.NewID(), logger)
+		err := retirementSupervisor.Do()
+		require.NoError(t, err)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		multitenantDatabase.Installations.Add(model.NewID())
+		err = sqlStore.UpdateMultitenantDatabase(multitenantDatabase)
+		require.NoError(t, err)
+
+		err = retirementSupervisor.Do()
+		require.NoError(t, err)
+		require.Empty(t, retirer.Retired)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		require.Zero(t, multitenantDatabase.EmptySince)
+		require.Zero(t, multitenantDatabase.DeleteAt)
+	})
+
+	t.Run("not managed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, multitenantDatabase := setup(t, false)
+		retirer := &mockMultitenantDatabaseRetirer{}
+
+		retirementSupervisor := supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, retirer, 0, model.NewID(), logger)
+		err := retirementSupervisor.Do()
+		require.NoError(t, err)
+		err = retirementSupervisor.Do()
+		require.NoError(t, err)
+		require.Empty(t, retirer.Retired)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		require.Zero(t, multitenantDatabase.EmptySince)
+		require.Zero(t, multitenantDatabase.DeleteAt)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, multitenantDatabase := setup(t, true)
+		retirer := &mockMultitenantDatabaseRetirer{}
+
+		locked, err := sqlStore.LockMultitenantDatabase(multitenantDatabase.ID, model.NewID())
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		retirementSupervisor := supervisor.NewMultitenantDatabaseRetirementSupervisor(sqlStore, retirer, 0, model.NewID(), logger)
+		retirementSupervisor.Supervise(multitenantDatabase)
+
+		multitenantDatabase = getMultitenantDatabase(t, sqlStore, multitenantDatabase)
+		require.Zero(t, multitenantDatabase.EmptySince)
+		require.Empty(t, retirer.Retired)
+	})
+}
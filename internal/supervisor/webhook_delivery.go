@@ -0,0 +1,185 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookDeliveryInitialBackoff is the delay before the first retry of a
+	// failed webhook delivery. Each subsequent retry doubles the delay.
+	webhookDeliveryInitialBackoff = 10 * time.Second
+	// webhookDeliveryMaxBackoff caps the delay between webhook delivery
+	// attempts.
+	webhookDeliveryMaxBackoff = 30 * time.Minute
+	// webhookDeliveryCleanupInterval is the minimum delay between two removals
+	// of the webhook deliveries past their retention period.
+	webhookDeliveryCleanupInterval = time.Hour
+)
+
+// webhookDeliveryStore abstracts the database operations required by the
+// webhook delivery supervisor.
+type webhookDeliveryStore interface {
+	GetWebhook(webhookID string) (*model.Webhook, error)
+	GetWebhookDelivery(deliveryID string) (*model.WebhookDelivery, error)
+	GetUnlockedWebhookDeliveriesPendingWork() ([]*model.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
+	LockWebhookDelivery(deliveryID, lockerID string) (bool, error)
+	UnlockWebhookDelivery(deliveryID, lockerID string, force bool) (bool, error)
+	DeleteFinishedWebhookDeliveries(before int64) (int64, error)
+}
+
+// WebhookDeliverySupervisor finds queued webhook deliveries and attempts to
+// deliver them, retrying failed deliveries with an exponential backoff until
+// the maximum number of attempts is reached. Delivered and dead letter
+// deliveries are removed once they are older than the retention period.
+type WebhookDeliverySupervisor struct {
+	store       webhookDeliveryStore
+	maxAttempts int
+	retention   time.Duration
+	lastCleanup time.Time
+	instanceID  string
+	logger      log.FieldLogger
+}
+
+// NewWebhookDeliverySupervisor creates a new WebhookDeliverySupervisor. A zero
+// retention keeps finished deliveries forever.
+func NewWebhookDeliverySupervisor(store webhookDeliveryStore, maxAttempts int, retention time.Duration, instanceID string, logger log.FieldLogger) *WebhookDeliverySupervisor {
+	return &WebhookDeliverySupervisor{
+		store:       store,
+		maxAttempts: maxAttempts,
+		retention:   retention,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the webhook delivery supervisor.
+func (s *WebhookDeliverySupervisor) Shutdown() {
+	s.logger.Debug("Shutting down webhook delivery supervisor")
+}
+
+// Do looks for webhook deliveries that are due and attempts to deliver them.
+func (s *WebhookDeliverySupervisor) Do() error {
+	deliveries, err := s.store.GetUnlockedWebhookDeliveriesPendingWork()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for webhook deliveries pending work")
+		return nil
+	}
+
+	for _, delivery := range deliveries {
+		s.Supervise(delivery)
+	}
+
+	s.cleanup()
+
+	return nil
+}
+
+// cleanup removes the finished webhook deliveries that are past the retention
+// period, at most once per cleanup interval.
+func (s *WebhookDeliverySupervisor) cleanup() {
+	if s.retention <= 0 || time.Since(s.lastCleanup) < webhookDeliveryCleanupInterval {
+		return
+	}
+	s.lastCleanup = time.Now()
+
+	before := store.GetMillis() - int64(s.retention/time.Millisecond)
+	count, err := s.store.DeleteFinishedWebhookDeliveries(before)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to delete finished webhook deliveries")
+		return
+	}
+	if count > 0 {
+		s.logger.Debugf("Deleted %d finished webhook deliveries", count)
+	}
+}
+
+// Supervise attempts to deliver the given webhook delivery.
+func (s *WebhookDeliverySupervisor) Supervise(delivery *model.WebhookDelivery) {
+	logger := s.logger.WithFields(log.Fields{
+		"webhookDelivery": delivery.ID,
+		"webhook":         delivery.WebhookID,
+	})
+
+	lock := newWebhookDeliveryLock(delivery.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	// Ensure that the delivery was not completed by another provisioning
+	// server in the meantime.
+	delivery, err := s.store.GetWebhookDelivery(delivery.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed webhook delivery")
+		return
+	}
+	if delivery == nil || delivery.State != model.WebhookDeliveryStatePending {
+		return
+	}
+
+	hook, err := s.store.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get webhook")
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = store.GetMillis()
+
+	if hook == nil || hook.IsDeleted() {
+		logger.Warn("Webhook no longer exists; moving delivery to dead letter")
+		delivery.State = model.WebhookDeliveryStateDeadLetter
+		delivery.LastError = "webhook was deleted"
+		metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryDeadLetter).Inc()
+	} else {
+		err = webhook.Deliver(hook, delivery, logger)
+		if err == nil {
+			delivery.State = model.WebhookDeliveryStateDelivered
+			delivery.LastError = ""
+			metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryDelivered).Inc()
+			logger.Debugf("Webhook delivered after %d attempt(s)", delivery.Attempts)
+		} else {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= s.maxAttempts {
+				logger.WithError(err).Warnf("Webhook delivery failed after %d attempts; moving to dead letter", delivery.Attempts)
+				delivery.State = model.WebhookDeliveryStateDeadLetter
+				metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryDeadLetter).Inc()
+			} else {
+				backoff := webhookDeliveryBackoff(delivery.Attempts)
+				delivery.NextAttemptAt = delivery.LastAttemptAt + int64(backoff/time.Millisecond)
+				logger.WithError(err).Debugf("Webhook delivery attempt %d failed; retrying in %s", delivery.Attempts, backoff)
+				metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryFailed).Inc()
+			}
+		}
+	}
+
+	err = s.store.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record webhook delivery attempt")
+	}
+}
+
+// webhookDeliveryBackoff returns the delay to wait before the next delivery
+// attempt after the given number of failed attempts.
+func webhookDeliveryBackoff(attempts int) time.Duration {
+	backoff := webhookDeliveryInitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookDeliveryMaxBackoff {
+			return webhookDeliveryMaxBackoff
+		}
+	}
+
+	return backoff
+}
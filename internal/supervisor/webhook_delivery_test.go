@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliverySupervisor(t *testing.T) {
+	setup := func(t *testing.T, statusCode int) (*store.SQLStore, *model.Webhook, *model.WebhookDelivery, func()) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCode)
+		}))
+
+		hook := &model.Webhook{
+			OwnerID: model.NewID(),
+			URL:     ts.URL,
+		}
+		err := sqlStore.CreateWebhook(hook)
+		require.NoError(t, err)
+
+		delivery := &model.WebhookDelivery{
+			WebhookID: hook.ID,
+			Payload: &model.WebhookPayload{
+				ID:       model.NewID(),
+				Type:     model.TypeInstallation,
+				NewState: model.InstallationStateStable,
+				OldState: model.InstallationStateCreationRequested,
+			},
+		}
+		err = sqlStore.CreateWebhookDelivery(delivery)
+		require.NoError(t, err)
+
+		return sqlStore, hook, delivery, ts.Close
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, delivery, cleanup := setup(t, http.StatusOK)
+		defer cleanup()
+
+		delivered := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryDelivered))
+
+		webhookDeliverySupervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, 3, 0, model.NewID(), logger)
+		webhookDeliverySupervisor.Supervise(delivery)
+
+		delivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStateDelivered, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Empty(t, delivery.LastError)
+		require.Equal(t, delivered+1, testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDeliveryDelivered)))
+	})
+
+	t.Run("non-2xx response is retried with backoff", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, delivery, cleanup := setup(t, http.StatusInternalServerError)
+		defer cleanup()
+
+		webhookDeliverySupervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, 3, 0, model.NewID(), logger)
+		webhookDeliverySupervisor.Supervise(delivery)
+
+		delivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Contains(t, delivery.LastError, "status code 500")
+		require.True(t, delivery.NextAttemptAt > delivery.LastAttemptAt)
+
+		deliveries, err := sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	})
+
+	t.Run("dead letter after max attempts", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, delivery, cleanup := setup(t, http.StatusBadGateway)
+		defer cleanup()
+
+		webhookDeliverySupervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, 1, 0, model.NewID(), logger)
+		webhookDeliverySupervisor.Supervise(delivery)
+
+		delivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStateDeadLetter, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+	})
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, hook, delivery, cleanup := setup(t, http.StatusOK)
+		defer cleanup()
+
+		err := sqlStore.DeleteWebhook(hook.ID)
+		require.NoError(t, err)
+
+		webhookDeliverySupervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, 3, 0, model.NewID(), logger)
+		webhookDeliverySupervisor.Supervise(delivery)
+
+		delivery, err = sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStateDeadLetter, delivery.State)
+	})
+
+	t.Run("finished deliveries are deleted after the retention period", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore, _, delivery, cleanup := setup(t, http.StatusOK)
+		defer cleanup()
+
+		webhookDeliverySupervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, 3, time.Millisecond, model.NewID(), logger)
+		webhookDeliverySupervisor.Supervise(delivery)
+
+		time.Sleep(5 * time.Millisecond)
+
+		err := webhookDeliverySupervisor.Do()
+		require.NoError(t, err)
+
+		delivery, err = sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+		require.Nil(t, delivery)
+	})
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/kms"
@@ -45,6 +47,7 @@ type AWS interface {
 	GetCloudEnvironmentName() (string, error)
 
 	GetAndClaimVpcResources(clusterID, owner string, logger log.FieldLogger) (ClusterResources, error)
+	GetClaimedVpcID(clusterID string, logger log.FieldLogger) (string, error)
 	ReleaseVpc(clusterID string, logger log.FieldLogger) error
 	AttachPolicyToRole(roleName, policyName string, logger log.FieldLogger) error
 	DetachPolicyFromRole(roleName, policyName string, logger log.FieldLogger) error
@@ -62,6 +65,13 @@ type AWS interface {
 	TagResource(resourceID, key, value string, logger log.FieldLogger) error
 	UntagResource(resourceID, key, value string, logger log.FieldLogger) error
 	IsValidAMI(AMIImage string, logger log.FieldLogger) (bool, error)
+	GetSpotInterruptionWarnings(kopsClusterName string, logger log.FieldLogger) ([]string, error)
+
+	EnsureEKSCluster(cluster *model.Cluster, resources ClusterResources, logger log.FieldLogger) (*eks.Cluster, error)
+	EnsureEKSNodeGroup(cluster *model.Cluster, nodeGroup *model.EKSNodeGroup, subnetIDs []string, logger log.FieldLogger) (*eks.Nodegroup, error)
+	GetEKSCluster(clusterName string) (*eks.Cluster, error)
+	GetEKSNodeGroups(clusterName string) ([]*eks.Nodegroup, error)
+	EnsureEKSClusterDeleted(clusterName string, logger log.FieldLogger) error
 
 	DynamoDBEnsureTableDeleted(tableName string, logger log.FieldLogger) error
 	S3EnsureBucketDeleted(bucketName string, logger log.FieldLogger) error
@@ -89,6 +99,7 @@ type Service struct {
 	kms                   kmsiface.KMSAPI
 	dynamodb              dynamodbiface.DynamoDBAPI
 	sts                   stsiface.STSAPI
+	eks                   eksiface.EKSAPI
 }
 
 // NewService creates a new instance of Service.
@@ -105,16 +116,21 @@ func NewService(sess *session.Session) *Service {
 		kms:                   kms.New(sess),
 		dynamodb:              dynamodb.New(sess),
 		sts:                   sts.New(sess),
+		eks:                   eks.New(sess),
 	}
 }
 
 // Client is a client for interacting with AWS resources.
 type Client struct {
-	store   model.InstallationDatabaseStoreInterface
-	logger  log.FieldLogger
-	service *Service
-	config  *aws.Config
-	mux     *sync.Mutex
+	store                     model.InstallationDatabaseStoreInterface
+	logger                    log.FieldLogger
+	service                   *Service
+	config                    *aws.Config
+	mux                       *sync.Mutex
+	multitenantDatabaseConfig model.MultitenantDatabaseConfig
+	// externalDatabaseSecretPrefix is the required name prefix of the
+	// secrets holding the connection string of external databases.
+	externalDatabaseSecretPrefix string
 }
 
 // Service contructs an AWS session if not yet successfully done and returns AWS clients.
@@ -142,6 +158,18 @@ func (c *Client) AddSQLStore(store model.InstallationDatabaseStoreInterface) {
 	}
 }
 
+// SetMultitenantDatabaseConfig sets the settings of the multitenant RDS
+// clusters the client creates when no multitenant database has capacity left.
+func (c *Client) SetMultitenantDatabaseConfig(config model.MultitenantDatabaseConfig) {
+	c.multitenantDatabaseConfig = config
+}
+
+// SetExternalDatabaseSecretPrefix sets the name prefix the secrets of external
+// databases must have to be read by the client.
+func (c *Client) SetExternalDatabaseSecretPrefix(prefix string) {
+	c.externalDatabaseSecretPrefix = prefix
+}
+
 // HasSQLStore returns whether the AWS client has a SQL store or not.
 func (c *Client) HasSQLStore() bool {
 	return c.store != nil
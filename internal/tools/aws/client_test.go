@@ -182,6 +182,7 @@ func (a *AWSTestSuite) SetupTest() {
 				resourceGroupsTagging: api.ResourceGroupsTagging,
 				kms:                   api.KMS,
 				sts:                   api.STS,
+				eks:                   api.EKS,
 			},
 			config: &aws.Config{},
 			mux:    &sync.Mutex{},
@@ -210,6 +211,7 @@ func (a *AWSTestSuite) TestNewClient() {
 	a.Assert().NotNil(client.Service().resourceGroupsTagging)
 	a.Assert().NotNil(client.Service().ec2)
 	a.Assert().NotNil(client.Service().sts)
+	a.Assert().NotNil(client.Service().eks)
 
 	_, err := client.Service().acm.ListCertificates(&acm.ListCertificatesInput{})
 	a.Assert().Error(err)
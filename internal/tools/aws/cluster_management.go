@@ -202,6 +202,33 @@ func (a *Client) GetAndClaimVpcResources(clusterID, owner string, logger log.Fie
 	return ClusterResources{}, fmt.Errorf("%d VPCs were returned as currently available; none of them were configured correctly", len(vpcs))
 }
 
+// GetClaimedVpcID returns the ID of the VPC claimed by the given cluster, or an
+// empty string if it didn't claim one.
+func (a *Client) GetClaimedVpcID(clusterID string, logger log.FieldLogger) (string, error) {
+	vpcs, err := a.GetVpcsWithFilters([]*ec2.Filter{
+		{
+			Name:   aws.String(VpcAvailableTagKey),
+			Values: []*string{aws.String(VpcAvailableTagValueFalse)},
+		},
+		{
+			Name:   aws.String(VpcClusterIDTagKey),
+			Values: []*string{aws.String(clusterID)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(vpcs) > 1 {
+		return "", fmt.Errorf("multiple VPCs (%d) have been claimed by cluster %s", len(vpcs), clusterID)
+	}
+	if len(vpcs) == 0 {
+		logger.Debugf("No VPC is claimed by cluster %s", clusterID)
+		return "", nil
+	}
+
+	return *vpcs[0].VpcId, nil
+}
+
 // ReleaseVpc changes the tags on a VPC to mark it as "available" again.
 func (a *Client) ReleaseVpc(clusterID string, logger log.FieldLogger) error {
 	return a.releaseVpc(clusterID, logger)
@@ -303,4 +303,12 @@ const (
 	// Warning:
 	// changing this value will break the connection to AWS resources for existing installations.
 	DefaultAWSTerraformProvisionedValueTrue = "true"
+
+	// DefaultAWSTerraformProvisionedValueFalse indicates that the AWS
+	// resource has been provisioned by the provisioner itself.
+	DefaultAWSTerraformProvisionedValueFalse = "false"
+
+	// KopsClusterTagKey is the tag key set by kops to the cluster name on the
+	// EC2 instances of the cluster.
+	KopsClusterTagKey = "tag:KubernetesCluster"
 )
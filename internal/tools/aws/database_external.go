@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+)
+
+// ExternalDatabase is a database managed outside of the provisioner. Its
+// connection string is either stored encrypted with the installation or read
+// from an AWS Secrets Manager secret.
+type ExternalDatabase struct {
+	installationID      string
+	secretName          string
+	encryptedDataSource []byte
+	encryptionKey       []byte
+	client              *Client
+}
+
+// NewExternalDatabase returns a new ExternalDatabase interface.
+func NewExternalDatabase(installationID, secretName string, encryptedDataSource, encryptionKey []byte, client *Client) *ExternalDatabase {
+	return &ExternalDatabase{
+		installationID:      installationID,
+		secretName:          secretName,
+		encryptedDataSource: encryptedDataSource,
+		encryptionKey:       encryptionKey,
+		client:              client,
+	}
+}
+
+// Provision checks that the external database can be reached with the
+// connection string of the installation. The check connects from the
+// provisioner host, not from the cluster the installation runs on.
+func (d *ExternalDatabase) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	dataSource, err := d.dataSource()
+	if err != nil {
+		return errors.Wrap(err, "failed to get external database data source")
+	}
+
+	err = checkExternalDatabaseConnection(dataSource)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to external database")
+	}
+
+	logger.Info("External database is reachable")
+
+	return nil
+}
+
+// Teardown leaves the external database untouched, as its data is not owned
+// by the provisioner.
+func (d *ExternalDatabase) Teardown(store model.InstallationDatabaseStoreInterface, keepData bool, logger log.FieldLogger) error {
+	logger.Info("External database requires no teardown; skipping...")
+	if !keepData {
+		logger.Warn("Database deletion was requested, but external database data is never deleted")
+	}
+
+	return nil
+}
+
+// Snapshot is not supported for external databases.
+func (d *ExternalDatabase) Snapshot(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	logger.Error("Snapshotting is not supported for external databases.")
+
+	return errors.New("not implemented")
+}
+
+// GenerateDatabaseSpecAndSecret creates the k8s database spec and secret for
+// accessing the external database.
+func (d *ExternalDatabase) GenerateDatabaseSpecAndSecret(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Database, *corev1.Secret, error) {
+	dataSource, err := d.dataSource()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get external database data source")
+	}
+
+	secretName := ExternalDatabaseSecretName(d.installationID)
+
+	databaseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretName,
+		},
+		StringData: map[string]string{
+			"DB_CONNECTION_STRING": dataSource,
+		},
+	}
+
+	databaseSpec := &mmv1alpha1.Database{
+		Secret: secretName,
+	}
+
+	logger.Debug("External database configuration generated for cluster installation")
+
+	return databaseSpec, databaseSecret, nil
+}
+
+// dataSource returns the connection string of the external database,
+// decrypting it or reading it from AWS Secrets Manager.
+func (d *ExternalDatabase) dataSource() (string, error) {
+	if len(d.encryptedDataSource) != 0 {
+		if len(d.encryptionKey) == 0 {
+			return "", errors.New("no encryption key configured to decrypt the data source")
+		}
+
+		dataSource, err := model.DecryptSecret(d.encryptionKey, d.encryptedDataSource, nil)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to decrypt data source")
+		}
+
+		return string(dataSource), nil
+	}
+
+	if d.secretName == "" {
+		return "", errors.New("no data source or secret name configured")
+	}
+	err := model.CheckExternalDatabaseSecretName(d.secretName, d.client.externalDatabaseSecretPrefix)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid external database secret name")
+	}
+
+	result, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: &d.secretName,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret value of %s", d.secretName)
+	}
+	if result.SecretString == nil {
+		return "", errors.Errorf("secret %s has no string value", d.secretName)
+	}
+
+	dataSource := strings.TrimSpace(*result.SecretString)
+	_, err = model.ExternalDatabaseEngineType(dataSource)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid data source in secret %s", d.secretName)
+	}
+
+	return dataSource, nil
+}
+
+// checkExternalDatabaseConnection opens a connection to the database of the
+// given Mattermost connection string and pings it.
+func checkExternalDatabaseConnection(dataSource string) error {
+	engineType, err := model.ExternalDatabaseEngineType(dataSource)
+	if err != nil {
+		return err
+	}
+
+	var db *sql.DB
+	switch engineType {
+	case model.DatabaseEngineTypeMySQL:
+		// The MySQL driver expects the connection string without its scheme.
+		db, err = sql.Open("mysql", strings.TrimPrefix(dataSource, "mysql://"))
+	case model.DatabaseEngineTypePostgres:
+		db, err = sql.Open("postgres", dataSource)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open database connection")
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
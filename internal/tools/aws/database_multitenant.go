@@ -41,20 +41,24 @@ type SQLDatabaseManager interface {
 
 // RDSMultitenantDatabase is a database backed by RDS that supports multi-tenancy.
 type RDSMultitenantDatabase struct {
-	databaseType   string
-	installationID string
-	instanceID     string
-	db             SQLDatabaseManager
-	client         *Client
+	databaseType              string
+	installationID            string
+	instanceID                string
+	migrationTargetDatabaseID string
+	db                        SQLDatabaseManager
+	client                    *Client
 }
 
 // NewRDSMultitenantDatabase returns a new instance of RDSMultitenantDatabase that implements database interface.
-func NewRDSMultitenantDatabase(databaseType, instanceID, installationID string, client *Client) *RDSMultitenantDatabase {
+// The migration target database ID is only set while the installation
+// database is migrated to another multitenant database.
+func NewRDSMultitenantDatabase(databaseType, instanceID, installationID, migrationTargetDatabaseID string, client *Client) *RDSMultitenantDatabase {
 	return &RDSMultitenantDatabase{
-		databaseType:   databaseType,
-		instanceID:     instanceID,
-		installationID: installationID,
-		client:         client,
+		databaseType:              databaseType,
+		instanceID:                instanceID,
+		installationID:            installationID,
+		migrationTargetDatabaseID: migrationTargetDatabaseID,
+		client:                    client,
 	}
 }
 
@@ -129,6 +133,15 @@ func (d *RDSMultitenantDatabase) Provision(store model.InstallationDatabaseStore
 	defer unlockFn()
 	logger = logger.WithField("assigned-database", database.ID)
 
+	if database.State == model.MultitenantDatabaseStateCreating && database.Managed {
+		// The database is recorded before its RDS cluster is created, so a
+		// creation interrupted by an error is resumed here.
+		err = d.ensureMultitenantDatabaseCreated(database, logger)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create multitenant RDS cluster ID %s", database.ID)
+		}
+	}
+
 	rdsCluster, err := d.describeRDSCluster(database.ID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to describe the multitenant RDS cluster ID %s", database.ID)
@@ -137,6 +150,23 @@ func (d *RDSMultitenantDatabase) Provision(store model.InstallationDatabaseStore
 		return errors.Errorf("multitenant RDS cluster ID %s is not available (status: %s)", database.ID, *rdsCluster.Status)
 	}
 
+	if database.State == model.MultitenantDatabaseStateCreating {
+		ready, err := d.isRDSClusterEndpointsReady(database.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check endpoints of multitenant RDS cluster ID %s", database.ID)
+		}
+		if !ready {
+			return errors.Errorf("multitenant RDS cluster ID %s endpoints are not available yet", database.ID)
+		}
+
+		database.State = model.MultitenantDatabaseStateStable
+		err = store.UpdateMultitenantDatabase(database)
+		if err != nil {
+			return errors.Wrapf(err, "failed to mark multitenant database %s as stable", database.ID)
+		}
+		logger.Infof("Multitenant database %s is now stable", database.ID)
+	}
+
 	rdsID := *rdsCluster.DBClusterIdentifier
 	logger = logger.WithField("rds-cluster-id", rdsID)
 
@@ -176,7 +206,7 @@ func (d *RDSMultitenantDatabase) GenerateDatabaseSpecAndSecret(store model.Insta
 		"database-type":            d.databaseType,
 	})
 
-	multitenantDatabase, err := store.GetMultitenantDatabaseForInstallationID(d.installationID)
+	multitenantDatabase, err := d.getMultitenantDatabaseForSpec(store)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to query for the multitenant database")
 	}
@@ -245,6 +275,24 @@ func (d *RDSMultitenantDatabase) GenerateDatabaseSpecAndSecret(store model.Insta
 	return databaseSpec, databaseSecret, nil
 }
 
+// getMultitenantDatabaseForSpec returns the multitenant database the
+// installation should connect to. While a database migration is finalized the
+// installation is assigned to both the source and the target database; once it
+// was switched it must connect to the target.
+func (d *RDSMultitenantDatabase) getMultitenantDatabaseForSpec(store model.InstallationDatabaseStoreInterface) (*model.MultitenantDatabase, error) {
+	if len(d.migrationTargetDatabaseID) != 0 {
+		target, err := store.GetMultitenantDatabase(d.migrationTargetDatabaseID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query for the migration target multitenant database")
+		}
+		if target != nil && target.Installations.Contains(d.installationID) {
+			return target, nil
+		}
+	}
+
+	return store.GetMultitenantDatabaseForInstallationID(d.installationID)
+}
+
 // Teardown removes all AWS resources related to a RDS multitenant database.
 func (d *RDSMultitenantDatabase) Teardown(store model.InstallationDatabaseStoreInterface, keepData bool, logger log.FieldLogger) error {
 	logger = logger.WithField("rds-multitenant-database", MattermostRDSDatabaseName(d.installationID))
@@ -322,10 +370,12 @@ func (d *RDSMultitenantDatabase) getAndLockAssignedMultitenantDatabase(store mod
 //	1. fetch a multitenant database by installation ID.
 //	2. fetch all multitenant databases in the store which are under the max number of installations limit.
 //	3. fetch all multitenant databases in the RDS cluster that are under the max number of installations limit.
+//	4. record a new multitenant database if the VPC is configured for it.
 func (d *RDSMultitenantDatabase) assignInstallationToMultitenantDatabaseAndLock(vpcID string, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.MultitenantDatabase, func(), error) {
 	multitenantDatabases, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
 		DatabaseType:          d.databaseType,
 		MaxInstallationsLimit: d.MaxSupportedDatabases(),
+		AllowInstallations:    true,
 		VpcID:                 vpcID,
 		PerPage:               model.AllPerPage,
 	})
@@ -343,7 +393,17 @@ func (d *RDSMultitenantDatabase) assignInstallationToMultitenantDatabaseAndLock(
 	}
 
 	if len(multitenantDatabases) == 0 {
-		return nil, nil, errors.New("no multitenant databases are currently available for new installations")
+		settings := d.client.multitenantDatabaseConfig.SettingsForVPC(vpcID)
+		if settings == nil || settings.EngineSettings(d.databaseType) == nil {
+			return nil, nil, errors.New("no multitenant databases are currently available for new installations")
+		}
+
+		logger.Info("No multitenant databases are available for new installations; creating a new one")
+
+		multitenantDatabases, err = d.createMultitenantDatabase(vpcID, store, logger)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create a new multitenant database")
+		}
 	}
 
 	// We want to be smart about how we assign the installation to a database.
@@ -369,6 +429,14 @@ func (d *RDSMultitenantDatabase) assignInstallationToMultitenantDatabaseAndLock(
 		unlockFn()
 		return nil, nil, errors.Wrap(err, "failed to refresh multitenant database after lock")
 	}
+	if selectedDatabase.State == model.MultitenantDatabaseStateDeleted {
+		unlockFn()
+		return nil, nil, errors.Errorf("selected multitenant database %s was retired", selectedDatabase.ID)
+	}
+	if !selectedDatabase.AllowInstallations {
+		unlockFn()
+		return nil, nil, errors.Errorf("selected multitenant database %s no longer allows installations", selectedDatabase.ID)
+	}
 
 	// Finish assigning the installation.
 	selectedDatabase.Installations.Add(d.installationID)
@@ -442,9 +510,11 @@ func (d *RDSMultitenantDatabase) getMultitenantDatabasesFromResourceTags(vpcID s
 
 		if rdsClusterID != nil {
 			multitenantDatabase := model.MultitenantDatabase{
-				ID:           *rdsClusterID,
-				VpcID:        vpcID,
-				DatabaseType: d.databaseType,
+				ID:                 *rdsClusterID,
+				VpcID:              vpcID,
+				DatabaseType:       d.databaseType,
+				State:              model.MultitenantDatabaseStateStable,
+				AllowInstallations: true,
 			}
 
 			ready, err := d.isRDSClusterEndpointsReady(*rdsClusterID)
@@ -471,6 +541,205 @@ func (d *RDSMultitenantDatabase) getMultitenantDatabasesFromResourceTags(vpcID s
 	return multitenantDatabases, nil
 }
 
+// createMultitenantDatabase records a new multitenant database in the given
+// VPC. Creations are serialized per VPC, so the databases another provisioner
+// made available in the meantime are returned instead when there are any.
+//
+// The database is recorded before its RDS cluster is created, under an ID
+// derived from the number of databases of the VPC, so that the creation is
+// resumed with the same ID after a failure instead of leaking a cluster.
+func (d *RDSMultitenantDatabase) createMultitenantDatabase(vpcID string, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) ([]*model.MultitenantDatabase, error) {
+	unlockFn, err := d.lockMultitenantDatabaseVPC(vpcID, store, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock VPC for creating a multitenant database")
+	}
+	defer unlockFn()
+
+	multitenantDatabases, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		DatabaseType:          d.databaseType,
+		MaxInstallationsLimit: d.MaxSupportedDatabases(),
+		AllowInstallations:    true,
+		VpcID:                 vpcID,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get available multitenant databases")
+	}
+	if len(multitenantDatabases) != 0 {
+		logger.Info("A multitenant database was made available while waiting for the VPC lock")
+		return multitenantDatabases, nil
+	}
+
+	vpcDatabases, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		VpcID:                 vpcID,
+		IncludeDeleted:        true,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multitenant databases of the VPC")
+	}
+
+	// Retired databases are kept in the datastore, so the IDs are never
+	// reused.
+	var rdsClusterID string
+	for sequence := len(vpcDatabases) + 1; ; sequence++ {
+		rdsClusterID = RDSMultitenantClusterID(vpcID, sequence)
+		existing, err := store.GetMultitenantDatabase(rdsClusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check for an existing multitenant database")
+		}
+		if existing == nil {
+			break
+		}
+	}
+
+	multitenantDatabase := model.MultitenantDatabase{
+		ID:                 rdsClusterID,
+		VpcID:              vpcID,
+		DatabaseType:       d.databaseType,
+		State:              model.MultitenantDatabaseStateCreating,
+		Managed:            true,
+		AllowInstallations: true,
+	}
+	err = store.CreateMultitenantDatabase(&multitenantDatabase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to record multitenant RDS cluster ID %s in the datastore", rdsClusterID)
+	}
+
+	logger.Infof("Recorded new multitenant database %s", multitenantDatabase.ID)
+
+	return []*model.MultitenantDatabase{&multitenantDatabase}, nil
+}
+
+// ensureMultitenantDatabaseCreated creates the master secret and the RDS
+// cluster of a multitenant database recorded by the provisioner, skipping the
+// resources that already exist. The cluster is created asynchronously by AWS.
+func (d *RDSMultitenantDatabase) ensureMultitenantDatabaseCreated(database *model.MultitenantDatabase, logger log.FieldLogger) error {
+	settings := d.client.multitenantDatabaseConfig.SettingsForVPC(database.VpcID)
+	if settings == nil || settings.EngineSettings(database.DatabaseType) == nil {
+		return errors.Errorf("VPC %s is no longer configured for creating %s multitenant databases", database.VpcID, database.DatabaseType)
+	}
+
+	rdsClusterID := database.ID
+	logger = logger.WithField("rds-cluster-id", rdsClusterID)
+
+	// The master password is stored in a secret named after the cluster,
+	// which is where the provisioning SQL commands look it up.
+	password, err := d.ensureMultitenantDatabaseMasterSecret(rdsClusterID, database.VpcID)
+	if err != nil {
+		return errors.Wrap(err, "failed to ensure master secret")
+	}
+
+	tags := []*rds.Tag{
+		{
+			Key:   aws.String(trimTagPrefix(RDSMultitenantPurposeTagKey)),
+			Value: aws.String(RDSMultitenantPurposeTagValueProvisioning),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(RDSMultitenantOwnerTagKey)),
+			Value: aws.String(RDSMultitenantOwnerTagValueCloudTeam),
+		},
+		{
+			Key:   aws.String(DefaultAWSTerraformProvisionedKey),
+			Value: aws.String(DefaultAWSTerraformProvisionedValueFalse),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(DefaultRDSMultitenantDatabaseTypeTagKey)),
+			Value: aws.String(DefaultRDSMultitenantDatabaseTypeTagValue),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(VpcIDTagKey)),
+			Value: aws.String(database.VpcID),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(CloudInstallationDatabaseTagKey)),
+			Value: aws.String(d.DatabaseTypeTagValue()),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(RDSMultitenantInstallationCounterTagKey)),
+			Value: aws.String("0"),
+		},
+		{
+			Key:   aws.String(trimTagPrefix(DefaultRDSMultitenantDatabaseIDTagKey)),
+			Value: aws.String(rdsClusterID),
+		},
+	}
+
+	err = d.client.rdsEnsureMultitenantDBClusterCreated(rdsClusterID, database.VpcID, password, settings.KMSKeyID, database.DatabaseType, settings.EngineSettings(database.DatabaseType), tags, logger)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureMultitenantDatabaseMasterSecret creates the master secret of a
+// multitenant RDS cluster and returns its password, or returns the password
+// of the existing secret.
+func (d *RDSMultitenantDatabase) ensureMultitenantDatabaseMasterSecret(rdsClusterID, vpcID string) (string, error) {
+	password := newRandomPassword(40)
+	_, err := d.client.Service().secretsManager.CreateSecret(&secretsmanager.CreateSecretInput{
+		Name:         aws.String(rdsClusterID),
+		Description:  aws.String(RDSMultitenantClusterMasterSecretDescription(rdsClusterID)),
+		SecretString: aws.String(password),
+		Tags: []*secretsmanager.Tag{
+			{
+				Key:   aws.String(trimTagPrefix(DefaultRDSMultitenantDatabaseIDTagKey)),
+				Value: aws.String(rdsClusterID),
+			},
+			{
+				Key:   aws.String(trimTagPrefix(VpcIDTagKey)),
+				Value: aws.String(vpcID),
+			},
+		},
+	})
+	if err == nil {
+		return password, nil
+	}
+	if !IsErrorCode(err, secretsmanager.ErrCodeResourceExistsException) {
+		return "", errors.Wrap(err, "failed to create master secret")
+	}
+
+	result, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(rdsClusterID),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get existing master secret")
+	}
+
+	return *result.SecretString, nil
+}
+
+// RetireMultitenantDatabase deletes the RDS cluster and the master secret of
+// an empty multitenant database created by the provisioner. The database is
+// expected to be locked by the caller, which then marks it as deleted.
+func (a *Client) RetireMultitenantDatabase(database *model.MultitenantDatabase, logger log.FieldLogger) error {
+	if !database.Managed {
+		return errors.Errorf("multitenant database %s was not created by the provisioner", database.ID)
+	}
+	if database.Installations.Count() != 0 {
+		return errors.Errorf("multitenant database %s still holds %d installations", database.ID, database.Installations.Count())
+	}
+
+	logger = logger.WithField("rds-cluster-id", database.ID)
+
+	err := a.rdsEnsureDBClusterDeleted(database.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete multitenant RDS cluster")
+	}
+
+	_, err = a.Service().secretsManager.DeleteSecret(&secretsmanager.DeleteSecretInput{
+		SecretId:                   aws.String(database.ID),
+		ForceDeleteWithoutRecovery: aws.Bool(true),
+	})
+	if err != nil && !IsErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+		return errors.Wrap(err, "failed to delete master secret")
+	}
+
+	return nil
+}
+
 func (d *RDSMultitenantDatabase) getRDSClusterIDFromResourceTags(resourceTags []*gt.Tag) (*string, error) {
 	var rdsClusterID *string
 	var installationCounter *string
@@ -586,6 +855,28 @@ func (d *RDSMultitenantDatabase) lockMultitenantDatabase(multitenantDatabaseID s
 	return unlockFN, nil
 }
 
+func (d *RDSMultitenantDatabase) lockMultitenantDatabaseVPC(vpcID string, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (func(), error) {
+	locked, err := store.LockMultitenantDatabaseVPC(vpcID, d.instanceID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to lock VPC %s", vpcID)
+	}
+	if !locked {
+		return nil, errors.Errorf("failed to acquire lock for VPC %s", vpcID)
+	}
+
+	unlockFN := func() {
+		unlocked, err := store.UnlockMultitenantDatabaseVPC(vpcID, d.instanceID, true)
+		if err != nil {
+			logger.WithError(err).Error("failed to unlock VPC")
+		}
+		if !unlocked {
+			logger.Warn("failed to release lock for VPC")
+		}
+	}
+
+	return unlockFN, nil
+}
+
 func (d *RDSMultitenantDatabase) validateMultitenantDatabaseInstallations(multitenantDatabaseID string, installations model.MultitenantDatabaseInstallations, store model.InstallationDatabaseStoreInterface) error {
 	multitenantDatabase, err := store.GetMultitenantDatabase(multitenantDatabaseID)
 	if err != nil {
@@ -650,6 +941,24 @@ func (d *RDSMultitenantDatabase) removeInstallationFromMultitenantDatabase(datab
 }
 
 func (d *RDSMultitenantDatabase) dropDatabaseAndDeleteSecret(rdsClusterID, rdsClusterendpoint string, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := d.dropInstallationDatabase(rdsClusterID, rdsClusterendpoint, logger)
+	if err != nil {
+		return err
+	}
+
+	multitenantDatabaseSecretName := RDSMultitenantSecretName(d.installationID)
+
+	_, err = d.client.Service().secretsManager.DeleteSecret(&secretsmanager.DeleteSecretInput{
+		SecretId: aws.String(multitenantDatabaseSecretName),
+	})
+	if err != nil && !IsErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+		return errors.Wrapf(err, "failed to delete multitenant database secret name %s", multitenantDatabaseSecretName)
+	}
+
+	return nil
+}
+
+func (d *RDSMultitenantDatabase) dropInstallationDatabase(rdsClusterID, rdsClusterendpoint string, logger log.FieldLogger) error {
 	databaseName := MattermostRDSDatabaseName(d.installationID)
 
 	masterSecretValue, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
@@ -673,15 +982,6 @@ func (d *RDSMultitenantDatabase) dropDatabaseAndDeleteSecret(rdsClusterID, rdsCl
 		return errors.Wrapf(err, "failed to drop multitenant RDS database name %s", databaseName)
 	}
 
-	multitenantDatabaseSecretName := RDSMultitenantSecretName(d.installationID)
-
-	_, err = d.client.Service().secretsManager.DeleteSecret(&secretsmanager.DeleteSecretInput{
-		SecretId: aws.String(multitenantDatabaseSecretName),
-	})
-	if err != nil && !IsErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
-		return errors.Wrapf(err, "failed to delete multitenant database secret name %s", multitenantDatabaseSecretName)
-	}
-
 	return nil
 }
 
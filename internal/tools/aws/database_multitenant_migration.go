@@ -0,0 +1,425 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// RDSMultitenantDatabaseMigration moves the logical database of an
+// installation from one multitenant RDS cluster to another one in the same VPC.
+type RDSMultitenantDatabaseMigration struct {
+	databaseType     string
+	installationID   string
+	instanceID       string
+	targetDatabaseID string
+	client           *Client
+}
+
+// NewRDSMultitenantDatabaseMigration returns a new RDSMultitenantDatabaseMigration.
+func NewRDSMultitenantDatabaseMigration(databaseType, instanceID, installationID, targetDatabaseID string, client *Client) *RDSMultitenantDatabaseMigration {
+	return &RDSMultitenantDatabaseMigration{
+		databaseType:     databaseType,
+		instanceID:       instanceID,
+		installationID:   installationID,
+		targetDatabaseID: targetDatabaseID,
+		client:           client,
+	}
+}
+
+// newDatabase returns a RDSMultitenantDatabase for the installation. A new one
+// is needed for every RDS cluster connection as the connection is cached.
+func (m *RDSMultitenantDatabaseMigration) newDatabase() *RDSMultitenantDatabase {
+	return NewRDSMultitenantDatabase(m.databaseType, m.instanceID, m.installationID, m.targetDatabaseID, m.client)
+}
+
+// IsValid returns if the given RDSMultitenantDatabaseMigration configuration is valid.
+func (m *RDSMultitenantDatabaseMigration) IsValid() error {
+	err := m.newDatabase().IsValid()
+	if err != nil {
+		return err
+	}
+	if len(m.targetDatabaseID) == 0 {
+		return errors.New("target database ID is empty")
+	}
+
+	return nil
+}
+
+// Setup validates the target multitenant database and creates the installation
+// database and user in it.
+func (m *RDSMultitenantDatabaseMigration) Setup(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := m.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database migration configuration is invalid")
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"multitenant-rds-database": MattermostRDSDatabaseName(m.installationID),
+		"target-database":          m.targetDatabaseID,
+	})
+
+	source, err := store.GetMultitenantDatabaseForInstallationID(m.installationID)
+	if err != nil {
+		return errors.Wrap(err, "failed to query for the source multitenant database")
+	}
+	if source.ID == m.targetDatabaseID {
+		return errors.Errorf("installation is already assigned to multitenant database %s", m.targetDatabaseID)
+	}
+
+	database := m.newDatabase()
+
+	unlock, err := database.lockMultitenantDatabase(m.targetDatabaseID, store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock target multitenant database")
+	}
+	defer unlock()
+
+	target, err := m.getTargetDatabase(store)
+	if err != nil {
+		return err
+	}
+	if target.State != model.MultitenantDatabaseStateStable {
+		return errors.Errorf("target multitenant database is in state %s", target.State)
+	}
+	if !target.AllowInstallations {
+		return errors.Errorf("target multitenant database %s does not allow installations", target.ID)
+	}
+	if target.VpcID != source.VpcID {
+		return errors.Errorf("target multitenant database is in VPC %s, but the source is in VPC %s", target.VpcID, source.VpcID)
+	}
+	if target.DatabaseType != source.DatabaseType {
+		return errors.Errorf("target multitenant database type %s does not match the source type %s", target.DatabaseType, source.DatabaseType)
+	}
+	limit := target.InstallationLimit(database.MaxSupportedDatabases())
+	if limit != model.NoInstallationsLimit && target.Installations.Count() >= limit {
+		return errors.Errorf("target multitenant database %s is full", target.ID)
+	}
+
+	rdsCluster, err := database.describeRDSCluster(target.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to describe target RDS cluster")
+	}
+	if *rdsCluster.Status != DefaultRDSStatusAvailable {
+		return errors.Errorf("target RDS cluster %s is not available (status: %s)", target.ID, *rdsCluster.Status)
+	}
+
+	err = database.runProvisionSQLCommands(MattermostRDSDatabaseName(m.installationID), target.VpcID, rdsCluster, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare target database")
+	}
+
+	logger.Info("Multitenant database migration setup completed")
+
+	return nil
+}
+
+// GenerateMigrationSecret returns the secret used by the migration job to copy
+// the installation database from the source to the target RDS cluster.
+func (m *RDSMultitenantDatabaseMigration) GenerateMigrationSecret(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*corev1.Secret, error) {
+	err := m.IsValid()
+	if err != nil {
+		return nil, errors.Wrap(err, "multitenant database migration configuration is invalid")
+	}
+
+	source, err := store.GetMultitenantDatabaseForInstallationID(m.installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for the source multitenant database")
+	}
+
+	database := m.newDatabase()
+
+	sourceCluster, err := database.describeRDSCluster(source.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe source RDS cluster")
+	}
+	targetCluster, err := database.describeRDSCluster(m.targetDatabaseID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe target RDS cluster")
+	}
+
+	installationSecretName := RDSMultitenantSecretName(m.installationID)
+
+	result, err := m.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(installationSecretName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get secret value for database")
+	}
+
+	installationSecret, err := unmarshalSecretPayload(*result.SecretString)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal secret payload")
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: RDSMultitenantMigrationSecretName(m.installationID),
+		},
+		StringData: map[string]string{
+			"SOURCE_HOST":       *sourceCluster.Endpoint,
+			"TARGET_HOST":       *targetCluster.Endpoint,
+			"DATABASE_NAME":     MattermostRDSDatabaseName(m.installationID),
+			"DATABASE_USERNAME": installationSecret.MasterUsername,
+			"DATABASE_PASSWORD": installationSecret.MasterPassword,
+		},
+	}, nil
+}
+
+// Switch assigns the installation to the target multitenant database and
+// points the installation secret to it. The source database is left untouched
+// until Cleanup is called, so Mattermost can keep running on it until it was
+// restarted with the updated secret. It is safe to call it again after a
+// partial failure.
+func (m *RDSMultitenantDatabaseMigration) Switch(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := m.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database migration configuration is invalid")
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"multitenant-rds-database": MattermostRDSDatabaseName(m.installationID),
+		"target-database":          m.targetDatabaseID,
+	})
+
+	assigned, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		InstallationID:        m.installationID,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query for the assigned multitenant databases")
+	}
+	if len(assigned) > 2 {
+		return errors.Errorf("expected no more than two multitenant databases, but found %d", len(assigned))
+	}
+	// The source database is only cleaned up once the switch was completed.
+	if len(assigned) == 1 && assigned[0].ID == m.targetDatabaseID {
+		logger.Debug("Installation is already switched to the target multitenant database")
+		return nil
+	}
+
+	database := m.newDatabase()
+
+	unlock, err := database.lockMultitenantDatabase(m.targetDatabaseID, store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock target multitenant database")
+	}
+	defer unlock()
+
+	target, err := m.getTargetDatabase(store)
+	if err != nil {
+		return err
+	}
+
+	if !target.Installations.Contains(m.installationID) {
+		target.Installations.Add(m.installationID)
+		err = store.UpdateMultitenantDatabase(target)
+		if err != nil {
+			return errors.Wrap(err, "failed to add installation to the target multitenant database")
+		}
+	}
+
+	rdsCluster, err := database.describeRDSCluster(target.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to describe target RDS cluster")
+	}
+
+	err = database.updateCounterTag(rdsCluster.DBClusterArn, target.Installations.Count())
+	if err != nil {
+		return errors.Wrap(err, "failed to update target counter tag")
+	}
+
+	err = m.updateInstallationSecret(rdsCluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation secret")
+	}
+
+	logger.Info("Installation switched to the target multitenant database")
+
+	return nil
+}
+
+// Cleanup removes the installation database from the source RDS cluster and
+// the installation from the source multitenant database. It must only be
+// called once Mattermost was restarted on the target database.
+func (m *RDSMultitenantDatabaseMigration) Cleanup(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := m.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database migration configuration is invalid")
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"multitenant-rds-database": MattermostRDSDatabaseName(m.installationID),
+		"target-database":          m.targetDatabaseID,
+	})
+
+	assigned, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		InstallationID:        m.installationID,
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query for the assigned multitenant databases")
+	}
+
+	var source *model.MultitenantDatabase
+	var switched bool
+	for _, multitenantDatabase := range assigned {
+		if multitenantDatabase.ID == m.targetDatabaseID {
+			switched = true
+			continue
+		}
+		if source != nil {
+			return errors.Errorf("expected no more than one source multitenant database, but found %d", len(assigned)-1)
+		}
+		source = multitenantDatabase
+	}
+	if !switched {
+		return errors.New("installation was not switched to the target multitenant database yet")
+	}
+	if source == nil {
+		logger.Debug("Source multitenant database was already cleaned up")
+		return nil
+	}
+
+	database := m.newDatabase()
+
+	unlock, err := database.lockMultitenantDatabase(source.ID, store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock source multitenant database")
+	}
+	defer unlock()
+
+	// The source database may have changed before the lock was acquired.
+	source, err = store.GetMultitenantDatabase(source.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to query for the source multitenant database")
+	}
+	if source == nil {
+		return errors.New("failed to find the source multitenant database")
+	}
+
+	rdsCluster, err := database.describeRDSCluster(source.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to describe source RDS cluster")
+	}
+
+	err = database.dropInstallationDatabase(*rdsCluster.DBClusterIdentifier, *rdsCluster.Endpoint, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to drop source database")
+	}
+
+	source.Installations.Remove(m.installationID)
+	err = store.UpdateMultitenantDatabase(source)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove installation from the source multitenant database")
+	}
+
+	err = database.updateCounterTag(rdsCluster.DBClusterArn, source.Installations.Count())
+	if err != nil {
+		return errors.Wrap(err, "failed to update source counter tag")
+	}
+
+	logger.Info("Source multitenant database cleaned up")
+
+	return nil
+}
+
+// Rollback removes the installation database from the target RDS cluster if
+// the installation was not switched to it yet.
+func (m *RDSMultitenantDatabaseMigration) Rollback(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := m.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database migration configuration is invalid")
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"multitenant-rds-database": MattermostRDSDatabaseName(m.installationID),
+		"target-database":          m.targetDatabaseID,
+	})
+
+	database := m.newDatabase()
+
+	unlock, err := database.lockMultitenantDatabase(m.targetDatabaseID, store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock target multitenant database")
+	}
+	defer unlock()
+
+	target, err := m.getTargetDatabase(store)
+	if err != nil {
+		return err
+	}
+	if target.Installations.Contains(m.installationID) {
+		return errors.New("installation was already switched to the target multitenant database")
+	}
+
+	rdsCluster, err := database.describeRDSCluster(target.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to describe target RDS cluster")
+	}
+
+	err = database.dropInstallationDatabase(*rdsCluster.DBClusterIdentifier, *rdsCluster.Endpoint, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to drop target database")
+	}
+
+	logger.Info("Multitenant database migration rolled back")
+
+	return nil
+}
+
+func (m *RDSMultitenantDatabaseMigration) getTargetDatabase(store model.InstallationDatabaseStoreInterface) (*model.MultitenantDatabase, error) {
+	target, err := store.GetMultitenantDatabase(m.targetDatabaseID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for the target multitenant database")
+	}
+	if target == nil {
+		return nil, errors.Errorf("failed to find target multitenant database %s", m.targetDatabaseID)
+	}
+	if target.State == model.MultitenantDatabaseStateDeleted {
+		return nil, errors.Errorf("target multitenant database %s is deleted", m.targetDatabaseID)
+	}
+
+	return target, nil
+}
+
+// updateInstallationSecret points the installation secret tags and description
+// to the target RDS cluster.
+func (m *RDSMultitenantDatabaseMigration) updateInstallationSecret(rdsCluster *rds.DBCluster) error {
+	installationSecretName := RDSMultitenantSecretName(m.installationID)
+
+	_, err := m.client.Service().secretsManager.TagResource(&secretsmanager.TagResourceInput{
+		SecretId: aws.String(installationSecretName),
+		Tags: []*secretsmanager.Tag{
+			{
+				Key:   aws.String(trimTagPrefix(DefaultRDSMultitenantDatabaseIDTagKey)),
+				Value: rdsCluster.DBClusterIdentifier,
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to tag secret %s", installationSecretName)
+	}
+
+	_, err = m.client.Service().secretsManager.UpdateSecret(&secretsmanager.UpdateSecretInput{
+		SecretId:    aws.String(installationSecretName),
+		Description: aws.String(RDSMultitenantClusterSecretDescription(m.installationID, *rdsCluster.DBClusterIdentifier)),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update secret %s", installationSecretName)
+	}
+
+	return nil
+}
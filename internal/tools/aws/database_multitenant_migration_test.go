@@ -0,0 +1,289 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationSetupDifferentVPC() {
+	targetID := "rds-cluster-multitenant-target"
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	gomock.InOrder(
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabaseForInstallationID(a.InstallationA.ID).
+			Return(&model.MultitenantDatabase{
+				ID:           a.RDSClusterID,
+				VpcID:        a.VPCa,
+				DatabaseType: model.DatabaseEngineTypeMySQL,
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(targetID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(targetID).
+			Return(&model.MultitenantDatabase{
+				ID:                 targetID,
+				VpcID:              "vpc-other",
+				DatabaseType:       model.DatabaseEngineTypeMySQL,
+				State:              model.MultitenantDatabaseStateStable,
+				AllowInstallations: true,
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(targetID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := migration.Setup(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+	a.Assert().Contains(err.Error(), "VPC")
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationSwitchAlreadySwitched() {
+	targetID := "rds-cluster-multitenant-target"
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+		GetMultitenantDatabases(gomock.Any()).
+		Return([]*model.MultitenantDatabase{{
+			ID:            targetID,
+			Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+		}}, nil).
+		Times(1)
+
+	err := migration.Switch(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationSwitchAssignsTarget() {
+	targetID := "rds-cluster-multitenant-target"
+	targetARN := "arn:aws:rds:us-east-1:0:cluster:" + targetID
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	gomock.InOrder(
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return([]*model.MultitenantDatabase{{
+				ID:            a.RDSClusterID,
+				Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+			}}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(targetID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(targetID).
+			Return(&model.MultitenantDatabase{
+				ID:            targetID,
+				VpcID:         a.VPCa,
+				State:         model.MultitenantDatabaseStateStable,
+				Installations: model.MultitenantDatabaseInstallations{"installation1"},
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UpdateMultitenantDatabase(gomock.Any()).
+			Do(func(database *model.MultitenantDatabase) {
+				a.Assert().Equal(targetID, database.ID)
+				a.Assert().Equal(model.MultitenantDatabaseInstallations{"installation1", a.InstallationA.ID}, database.Installations)
+			}).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					{
+						DBClusterIdentifier: aws.String(targetID),
+						DBClusterArn:        aws.String(targetARN),
+						Endpoint:            aws.String("aws.rds.com/mattermost"),
+					},
+				},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			AddTagsToResource(gomock.Any()).
+			Do(func(input *rds.AddTagsToResourceInput) {
+				a.Assert().Equal(targetARN, *input.ResourceName)
+				a.Assert().Equal("2", *input.Tags[0].Value)
+			}).
+			Return(&rds.AddTagsToResourceOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.SecretsManager.EXPECT().
+			TagResource(gomock.Any()).
+			Do(func(input *secretsmanager.TagResourceInput) {
+				a.Assert().Equal(RDSMultitenantSecretName(a.InstallationA.ID), *input.SecretId)
+				a.Assert().Equal(targetID, *input.Tags[0].Value)
+			}).
+			Return(&secretsmanager.TagResourceOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.SecretsManager.EXPECT().
+			UpdateSecret(gomock.Any()).
+			Return(&secretsmanager.UpdateSecretOutput{}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(targetID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := migration.Switch(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationSwitchKeepsSourceOnFailure() {
+	targetID := "rds-cluster-multitenant-target"
+	targetARN := "arn:aws:rds:us-east-1:0:cluster:" + targetID
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	// The source database must be neither dropped nor updated when the switch
+	// fails after the installation was assigned to the target.
+	gomock.InOrder(
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return([]*model.MultitenantDatabase{{
+				ID:            a.RDSClusterID,
+				Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+			}}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(targetID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(targetID).
+			Return(&model.MultitenantDatabase{
+				ID:    targetID,
+				VpcID: a.VPCa,
+				State: model.MultitenantDatabaseStateStable,
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UpdateMultitenantDatabase(gomock.Any()).
+			Do(func(database *model.MultitenantDatabase) {
+				a.Assert().Equal(targetID, database.ID)
+			}).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					{
+						DBClusterIdentifier: aws.String(targetID),
+						DBClusterArn:        aws.String(targetARN),
+						Endpoint:            aws.String("aws.rds.com/mattermost"),
+					},
+				},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			AddTagsToResource(gomock.Any()).
+			Return(&rds.AddTagsToResourceOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.SecretsManager.EXPECT().
+			TagResource(gomock.Any()).
+			Return(nil, awserr.New(secretsmanager.ErrCodeInternalServiceError, "internal error", nil)).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(targetID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := migration.Switch(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationCleanupNotSwitched() {
+	targetID := "rds-cluster-multitenant-target"
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+		GetMultitenantDatabases(gomock.Any()).
+		Return([]*model.MultitenantDatabase{{
+			ID:            a.RDSClusterID,
+			Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+		}}, nil).
+		Times(1)
+
+	err := migration.Cleanup(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+	a.Assert().Contains(err.Error(), "not switched")
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationCleanupAlreadyCleanedUp() {
+	targetID := "rds-cluster-multitenant-target"
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+		GetMultitenantDatabases(gomock.Any()).
+		Return([]*model.MultitenantDatabase{{
+			ID:            targetID,
+			Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+		}}, nil).
+		Times(1)
+
+	err := migration.Cleanup(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestMultitenantDatabaseMigrationRollbackAfterSwitch() {
+	targetID := "rds-cluster-multitenant-target"
+	migration := NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, a.InstanceID, a.InstallationA.ID, targetID, a.Mocks.AWS)
+
+	gomock.InOrder(
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(targetID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(targetID).
+			Return(&model.MultitenantDatabase{
+				ID:            targetID,
+				State:         model.MultitenantDatabaseStateStable,
+				Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(targetID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := migration.Rollback(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+}
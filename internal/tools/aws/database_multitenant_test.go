@@ -6,6 +6,7 @@ package aws
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/rds"
 	gt "github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
@@ -183,13 +184,15 @@ func (a *AWSTestSuite) TestProvisioningMultitenantDatabase() {
 		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
 			GetMultitenantDatabase(a.RDSClusterID).
 			Return(&model.MultitenantDatabase{
-				ID: a.RDSClusterID,
+				ID:                 a.RDSClusterID,
+				AllowInstallations: true,
 			}, nil).
 			Times(1),
 
 		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
 			UpdateMultitenantDatabase(&model.MultitenantDatabase{
-				ID: a.RDSClusterID,
+				ID:                 a.RDSClusterID,
+				AllowInstallations: true,
 				Installations: model.MultitenantDatabaseInstallations{
 					database.installationID,
 				}}).
@@ -238,3 +241,427 @@ func (a *AWSTestSuite) TestProvisioningMultitenantDatabase() {
 		"rds-cluster-multitenant-09d44077df9934f96-97670d43: failed to run create database SQL command: dial tcp: "+
 		"lookup aws.rds.com/mattermost: no such host", err.Error())
 }
+
+func (a *AWSTestSuite) TestProvisioningMultitenantDatabaseCreatesRDSCluster() {
+	database := RDSMultitenantDatabase{
+		databaseType:   model.DatabaseEngineTypeMySQL,
+		installationID: a.InstallationA.ID,
+		instanceID:     a.InstanceID,
+		client:         a.Mocks.AWS,
+	}
+	a.Mocks.AWS.SetMultitenantDatabaseConfig(model.MultitenantDatabaseConfig{
+		a.VPCa: &model.MultitenantDatabaseVPCSettings{
+			MySQL: &model.MultitenantDatabaseEngineSettings{
+				EngineVersion: "5.7.mysql_aurora.2.09.1",
+				InstanceClass: "db.r5.large",
+			},
+			KMSKeyID: a.RDSEncryptionKeyID,
+		},
+	})
+
+	// One database was already retired in the VPC, so the new one is the
+	// second.
+	rdsClusterID := RDSMultitenantClusterID(a.VPCa, 2)
+
+	gomock.InOrder(
+		a.Mocks.Log.Logger.EXPECT().
+			WithFields(gomock.Any()).
+			Return(testlib.NewLoggerEntry()).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetClusterInstallations(gomock.Any()).
+			Return([]*model.ClusterInstallation{{ID: a.ClusterA.ID}}, nil).
+			Times(1),
+
+		a.Mocks.API.EC2.EXPECT().DescribeVpcs(gomock.Any()).
+			Return(&ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{{VpcId: &a.VPCa}}}, nil).
+			Times(1),
+
+		// No database is assigned to the installation yet.
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return(make([]*model.MultitenantDatabase, 0), nil),
+
+		// No database has capacity left in the datastore or in AWS.
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return(make([]*model.MultitenantDatabase, 0), nil),
+
+		a.Mocks.API.ResourceGroupsTagging.EXPECT().
+			GetResources(gomock.Any()).
+			Return(&gt.GetResourcesOutput{}, nil),
+
+		// Record the new database under the VPC lock.
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabaseVPC(a.VPCa, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return(make([]*model.MultitenantDatabase, 0), nil),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Do(func(filter *model.MultitenantDatabaseFilter) {
+				a.Assert().Equal(a.VPCa, filter.VpcID)
+				a.Assert().True(filter.IncludeDeleted)
+			}).
+			Return([]*model.MultitenantDatabase{{ID: RDSMultitenantClusterID(a.VPCa, 1), State: model.MultitenantDatabaseStateDeleted}}, nil),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(rdsClusterID).
+			Return(nil, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			CreateMultitenantDatabase(gomock.Any()).
+			Do(func(input *model.MultitenantDatabase) {
+				a.Assert().Equal(rdsClusterID, input.ID)
+				a.Assert().Equal(a.VPCa, input.VpcID)
+				a.Assert().Equal(model.MultitenantDatabaseStateCreating, input.State)
+				a.Assert().True(input.Managed)
+				a.Assert().True(input.AllowInstallations)
+			}).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabaseVPC(a.VPCa, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(rdsClusterID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabase(rdsClusterID).
+			Return(&model.MultitenantDatabase{
+				ID:                 rdsClusterID,
+				VpcID:              a.VPCa,
+				DatabaseType:       model.DatabaseEngineTypeMySQL,
+				State:              model.MultitenantDatabaseStateCreating,
+				Managed:            true,
+				AllowInstallations: true,
+			}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UpdateMultitenantDatabase(gomock.Any()).
+			Do(func(input *model.MultitenantDatabase) {
+				a.Assert().Equal(model.MultitenantDatabaseInstallations{a.InstallationA.ID}, input.Installations)
+			}).
+			Times(1),
+
+		// Create the master secret and the RDS cluster once the database is
+		// recorded.
+		a.Mocks.API.SecretsManager.EXPECT().
+			CreateSecret(gomock.Any()).
+			Do(func(input *secretsmanager.CreateSecretInput) {
+				a.Assert().Equal(rdsClusterID, *input.Name)
+				a.Assert().Len(*input.SecretString, 40)
+			}).
+			Return(&secretsmanager.CreateSecretOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(&rds.DescribeDBClustersInput{DBClusterIdentifier: &rdsClusterID}).
+			Return(nil, awserr.New(rds.ErrCodeDBClusterNotFoundFault, "not found", nil)).
+			Times(1),
+
+		a.Mocks.API.EC2.EXPECT().
+			DescribeSecurityGroups(gomock.Any()).
+			Return(&ec2.DescribeSecurityGroupsOutput{
+				SecurityGroups: []*ec2.SecurityGroup{{GroupId: &a.GroupID}},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBSubnetGroups(gomock.Any()).
+			Return(&rds.DescribeDBSubnetGroupsOutput{
+				DBSubnetGroups: []*rds.DBSubnetGroup{{DBSubnetGroupName: aws.String(DBSubnetGroupName(a.VPCa))}},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			CreateDBCluster(gomock.Any()).
+			Do(func(input *rds.CreateDBClusterInput) {
+				a.Assert().Equal(rdsClusterID, *input.DBClusterIdentifier)
+				a.Assert().Equal("aurora-mysql", *input.Engine)
+				a.Assert().Equal("5.7.mysql_aurora.2.09.1", *input.EngineVersion)
+				a.Assert().Equal(a.RDSEncryptionKeyID, *input.KmsKeyId)
+				a.Assert().Equal(DefaultMattermostDatabaseUsername, *input.MasterUsername)
+				a.Assert().Contains(input.Tags, &rds.Tag{
+					Key:   aws.String("MultitenantDatabaseID"),
+					Value: aws.String(rdsClusterID),
+				})
+				a.Assert().Contains(input.Tags, &rds.Tag{
+					Key:   aws.String("Counter"),
+					Value: aws.String("0"),
+				})
+			}).
+			Return(&rds.CreateDBClusterOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBInstances(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "not found", nil)).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			CreateDBInstance(gomock.Any()).
+			Do(func(input *rds.CreateDBInstanceInput) {
+				a.Assert().Equal(rdsClusterID, *input.DBClusterIdentifier)
+				a.Assert().Equal("db.r5.large", *input.DBInstanceClass)
+			}).
+			Return(&rds.CreateDBInstanceOutput{}, nil).
+			Times(1),
+
+		// The new RDS cluster is not available yet.
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			DoAndReturn(func(input *rds.DescribeDBClustersInput) (*rds.DescribeDBClustersOutput, error) {
+				return &rds.DescribeDBClustersOutput{
+					DBClusters: []*rds.DBCluster{
+						{
+							DBClusterIdentifier: input.Filters[0].Values[0],
+							Status:              aws.String("creating"),
+						},
+					},
+				}, nil
+			}).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(rdsClusterID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := database.Provision(a.Mocks.Model.DatabaseInstallationStore, a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+	a.Assert().Equal("multitenant RDS cluster ID "+rdsClusterID+" is not available (status: creating)", err.Error())
+}
+
+func (a *AWSTestSuite) TestProvisioningMultitenantDatabaseResumesRDSClusterCreation() {
+	database := RDSMultitenantDatabase{
+		databaseType:   model.DatabaseEngineTypeMySQL,
+		installationID: a.InstallationA.ID,
+		instanceID:     a.InstanceID,
+		client:         a.Mocks.AWS,
+	}
+	a.Mocks.AWS.SetMultitenantDatabaseConfig(model.MultitenantDatabaseConfig{
+		a.VPCa: &model.MultitenantDatabaseVPCSettings{
+			MySQL: &model.MultitenantDatabaseEngineSettings{},
+		},
+	})
+
+	// The previous attempt created the master secret and the RDS cluster,
+	// but failed to create its instance.
+	rdsClusterID := RDSMultitenantClusterID(a.VPCa, 1)
+	multitenantDatabase := &model.MultitenantDatabase{
+		ID:                 rdsClusterID,
+		VpcID:              a.VPCa,
+		DatabaseType:       model.DatabaseEngineTypeMySQL,
+		State:              model.MultitenantDatabaseStateCreating,
+		Managed:            true,
+		AllowInstallations: true,
+		Installations:      model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+	}
+
+	gomock.InOrder(
+		a.Mocks.Log.Logger.EXPECT().
+			WithFields(gomock.Any()).
+			Return(testlib.NewLoggerEntry()).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetClusterInstallations(gomock.Any()).
+			Return([]*model.ClusterInstallation{{ID: a.ClusterA.ID}}, nil).
+			Times(1),
+
+		a.Mocks.API.EC2.EXPECT().DescribeVpcs(gomock.Any()).
+			Return(&ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{{VpcId: &a.VPCa}}}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return([]*model.MultitenantDatabase{multitenantDatabase}, nil),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			LockMultitenantDatabase(rdsClusterID, a.InstanceID).
+			Return(true, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabaseForInstallationID(a.InstallationA.ID).
+			Return(multitenantDatabase, nil).
+			Times(1),
+
+		// The existing master password is reused.
+		a.Mocks.API.SecretsManager.EXPECT().
+			CreateSecret(gomock.Any()).
+			Return(nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "already exists", nil)).
+			Times(1),
+
+		a.Mocks.API.SecretsManager.EXPECT().
+			GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: &rdsClusterID}).
+			Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("password")}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(&rds.DescribeDBClustersInput{DBClusterIdentifier: &rdsClusterID}).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{{DBClusterIdentifier: &rdsClusterID}},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBInstances(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "not found", nil)).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			CreateDBInstance(gomock.Any()).
+			Do(func(input *rds.CreateDBInstanceInput) {
+				a.Assert().Equal(rdsClusterID, *input.DBClusterIdentifier)
+			}).
+			Return(&rds.CreateDBInstanceOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			DoAndReturn(func(input *rds.DescribeDBClustersInput) (*rds.DescribeDBClustersOutput, error) {
+				return &rds.DescribeDBClustersOutput{
+					DBClusters: []*rds.DBCluster{
+						{
+							DBClusterIdentifier: input.Filters[0].Values[0],
+							Status:              aws.String("creating"),
+						},
+					},
+				}, nil
+			}).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			UnlockMultitenantDatabase(rdsClusterID, a.InstanceID, true).
+			Return(true, nil).
+			Times(1),
+	)
+
+	err := database.Provision(a.Mocks.Model.DatabaseInstallationStore, a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+	a.Assert().Equal("multitenant RDS cluster ID "+rdsClusterID+" is not available (status: creating)", err.Error())
+}
+
+func (a *AWSTestSuite) TestProvisioningMultitenantDatabaseNoCapacityNotConfigured() {
+	database := RDSMultitenantDatabase{
+		databaseType:   model.DatabaseEngineTypePostgres,
+		installationID: a.InstallationA.ID,
+		instanceID:     a.InstanceID,
+		client:         a.Mocks.AWS,
+	}
+	// Only MySQL clusters are created in this VPC.
+	a.Mocks.AWS.SetMultitenantDatabaseConfig(model.MultitenantDatabaseConfig{
+		a.VPCa: &model.MultitenantDatabaseVPCSettings{
+			MySQL: &model.MultitenantDatabaseEngineSettings{},
+		},
+	})
+
+	gomock.InOrder(
+		a.Mocks.Log.Logger.EXPECT().
+			WithFields(gomock.Any()).
+			Return(testlib.NewLoggerEntry()).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetClusterInstallations(gomock.Any()).
+			Return([]*model.ClusterInstallation{{ID: a.ClusterA.ID}}, nil).
+			Times(1),
+
+		a.Mocks.API.EC2.EXPECT().DescribeVpcs(gomock.Any()).
+			Return(&ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{{VpcId: &a.VPCa}}}, nil).
+			Times(1),
+
+		a.Mocks.Model.DatabaseInstallationStore.EXPECT().
+			GetMultitenantDatabases(gomock.Any()).
+			Return(make([]*model.MultitenantDatabase, 0), nil).
+			Times(2),
+
+		a.Mocks.API.ResourceGroupsTagging.EXPECT().
+			GetResources(gomock.Any()).
+			Return(&gt.GetResourcesOutput{}, nil),
+	)
+
+	err := database.Provision(a.Mocks.Model.DatabaseInstallationStore, a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+	a.Assert().Equal("failed to assign installation to a multitenant database: no multitenant databases are currently available for new installations", err.Error())
+}
+
+func (a *AWSTestSuite) TestRetireMultitenantDatabase() {
+	multitenantDatabase := &model.MultitenantDatabase{
+		ID:      a.RDSClusterID,
+		VpcID:   a.VPCa,
+		State:   model.MultitenantDatabaseStateStable,
+		Managed: true,
+	}
+	instanceID := RDSMultitenantClusterInstanceID(a.RDSClusterID)
+
+	gomock.InOrder(
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(&rds.DescribeDBClustersInput{DBClusterIdentifier: &a.RDSClusterID}).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					{
+						DBClusterIdentifier: &a.RDSClusterID,
+						DBClusterMembers:    []*rds.DBClusterMember{{DBInstanceIdentifier: &instanceID}},
+					},
+				},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBInstance(gomock.Any()).
+			Do(func(input *rds.DeleteDBInstanceInput) {
+				a.Assert().Equal(instanceID, *input.DBInstanceIdentifier)
+			}).
+			Return(&rds.DeleteDBInstanceOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBCluster(gomock.Any()).
+			Do(func(input *rds.DeleteDBClusterInput) {
+				a.Assert().Equal(a.RDSClusterID, *input.DBClusterIdentifier)
+			}).
+			Return(&rds.DeleteDBClusterOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.SecretsManager.EXPECT().
+			DeleteSecret(gomock.Any()).
+			Do(func(input *secretsmanager.DeleteSecretInput) {
+				a.Assert().Equal(a.RDSClusterID, *input.SecretId)
+			}).
+			Return(&secretsmanager.DeleteSecretOutput{}, nil).
+			Times(1),
+	)
+
+	err := a.Mocks.AWS.RetireMultitenantDatabase(multitenantDatabase, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestRetireMultitenantDatabaseWithInstallations() {
+	multitenantDatabase := &model.MultitenantDatabase{
+		ID:            a.RDSClusterID,
+		VpcID:         a.VPCa,
+		State:         model.MultitenantDatabaseStateStable,
+		Managed:       true,
+		Installations: model.MultitenantDatabaseInstallations{a.InstallationA.ID},
+	}
+
+	err := a.Mocks.AWS.RetireMultitenantDatabase(multitenantDatabase, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+	a.Assert().Equal("multitenant database "+a.RDSClusterID+" still holds 1 installations", err.Error())
+}
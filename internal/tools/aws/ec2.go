@@ -6,6 +6,7 @@ package aws
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -97,6 +98,72 @@ func (a *Client) IsValidAMI(AMIImage string, logger log.FieldLogger) (bool, erro
 	return true, nil
 }
 
+// spotInterruptionCodes are the spot instance request status codes set when
+// AWS interrupts a spot instance, or is about to.
+var spotInterruptionCodes = map[string]bool{
+	"marked-for-stop":                             true,
+	"marked-for-termination":                      true,
+	"instance-stopped-by-price":                   true,
+	"instance-stopped-no-capacity":                true,
+	"instance-terminated-by-price":                true,
+	"instance-terminated-capacity-oversubscribed": true,
+	"instance-terminated-no-capacity":             true,
+}
+
+// GetSpotInterruptionWarnings returns a warning for each spot instance of the
+// given kops cluster which AWS interrupted or is about to interrupt.
+func (a *Client) GetSpotInterruptionWarnings(kopsClusterName string, logger log.FieldLogger) ([]string, error) {
+	instanceOutput, err := a.Service().ec2.DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String(KopsClusterTagKey),
+				Values: []*string{aws.String(kopsClusterName)},
+			},
+			{
+				Name:   aws.String("instance-lifecycle"),
+				Values: []*string{aws.String("spot")},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe spot instances")
+	}
+
+	var spotRequestIDs []*string
+	for _, reservation := range instanceOutput.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance.SpotInstanceRequestId != nil {
+				spotRequestIDs = append(spotRequestIDs, instance.SpotInstanceRequestId)
+			}
+		}
+	}
+	if len(spotRequestIDs) == 0 {
+		return nil, nil
+	}
+
+	spotRequestOutput, err := a.Service().ec2.DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: spotRequestIDs,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe spot instance requests")
+	}
+
+	var warnings []string
+	for _, spotRequest := range spotRequestOutput.SpotInstanceRequests {
+		if spotRequest.Status == nil || !spotInterruptionCodes[aws.StringValue(spotRequest.Status.Code)] {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("Spot instance %s was interrupted (%s): %s",
+			aws.StringValue(spotRequest.InstanceId),
+			aws.StringValue(spotRequest.Status.Code),
+			aws.StringValue(spotRequest.Status.Message),
+		))
+	}
+	logger.Debugf("Found %d interrupted spot instances out of %d", len(warnings), len(spotRequestIDs))
+
+	return warnings, nil
+}
+
 // GetVpcsWithFilters returns VPCs matching a given filter.
 func (a *Client) GetVpcsWithFilters(filters []*ec2.Filter) ([]*ec2.Vpc, error) {
 	vpcOutput, err := a.Service().ec2.DescribeVpcs(&ec2.DescribeVpcsInput{
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/golang/mock/gomock"
 	testlib "github.com/mattermost/mattermost-cloud/internal/testlib"
@@ -160,3 +161,58 @@ func TestVPCReal(t *testing.T) {
 	err = client.releaseVpc(clusterID, logger)
 	require.NoError(t, err)
 }
+
+func (a *AWSTestSuite) TestGetSpotInterruptionWarnings() {
+	gomock.InOrder(
+		a.Mocks.API.EC2.EXPECT().
+			DescribeInstances(gomock.Any()).
+			Return(&ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{{
+					Instances: []*ec2.Instance{
+						{InstanceId: aws.String("i-1"), SpotInstanceRequestId: aws.String("sir-1")},
+						{InstanceId: aws.String("i-2"), SpotInstanceRequestId: aws.String("sir-2")},
+					},
+				}},
+			}, nil),
+		a.Mocks.API.EC2.EXPECT().
+			DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+				SpotInstanceRequestIds: []*string{aws.String("sir-1"), aws.String("sir-2")},
+			}).
+			Return(&ec2.DescribeSpotInstanceRequestsOutput{
+				SpotInstanceRequests: []*ec2.SpotInstanceRequest{
+					{
+						InstanceId: aws.String("i-1"),
+						Status:     &ec2.SpotInstanceStatus{Code: aws.String("fulfilled"), Message: aws.String("Your spot request is fulfilled.")},
+					},
+					{
+						InstanceId: aws.String("i-2"),
+						Status:     &ec2.SpotInstanceStatus{Code: aws.String("marked-for-termination"), Message: aws.String("Your spot instance is marked for termination.")},
+					},
+				},
+			}, nil),
+	)
+
+	warnings, err := a.Mocks.AWS.GetSpotInterruptionWarnings("cluster-kops.k8s.local", log.New())
+	a.Assert().NoError(err)
+	a.Assert().Equal([]string{"Spot instance i-2 was interrupted (marked-for-termination): Your spot instance is marked for termination."}, warnings)
+}
+
+func (a *AWSTestSuite) TestGetSpotInterruptionWarningsNoSpotInstances() {
+	a.Mocks.API.EC2.EXPECT().
+		DescribeInstances(gomock.Any()).
+		Return(&ec2.DescribeInstancesOutput{}, nil)
+
+	warnings, err := a.Mocks.AWS.GetSpotInterruptionWarnings("cluster-kops.k8s.local", log.New())
+	a.Assert().NoError(err)
+	a.Assert().Empty(warnings)
+}
+
+func (a *AWSTestSuite) TestGetSpotInterruptionWarningsError() {
+	a.Mocks.API.EC2.EXPECT().
+		DescribeInstances(gomock.Any()).
+		Return(nil, errors.New("access denied"))
+
+	_, err := a.Mocks.AWS.GetSpotInterruptionWarnings("cluster-kops.k8s.local", log.New())
+	a.Assert().Error(err)
+	a.Assert().Equal("failed to describe spot instances: access denied", err.Error())
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// EnsureEKSCluster creates the EKS control plane of the given cluster in the
+// claimed VPC unless it already exists, and waits for it to become active.
+func (a *Client) EnsureEKSCluster(cluster *model.Cluster, resources ClusterResources, logger log.FieldLogger) (*eks.Cluster, error) {
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	logger = logger.WithField("eks-cluster", eksMetadata.Name)
+
+	eksCluster, err := a.GetEKSCluster(eksMetadata.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if eksCluster == nil {
+		subnetIDs := append([]string{}, resources.PrivateSubnetIDs...)
+		subnetIDs = append(subnetIDs, resources.PublicSubnetsIDs...)
+
+		var version string
+		if eksMetadata.ChangeRequest != nil {
+			version = eksMetadata.ChangeRequest.Version
+		}
+
+		_, err = a.Service().eks.CreateCluster(&eks.CreateClusterInput{
+			Name:    aws.String(eksMetadata.Name),
+			RoleArn: aws.String(eksMetadata.ClusterRoleARN),
+			Version: eksVersion(version),
+			ResourcesVpcConfig: &eks.VpcConfigRequest{
+				SubnetIds:             aws.StringSlice(subnetIDs),
+				SecurityGroupIds:      aws.StringSlice(resources.MasterSecurityGroupIDs),
+				EndpointPrivateAccess: aws.Bool(true),
+				EndpointPublicAccess:  aws.Bool(true),
+			},
+			Tags: map[string]*string{
+				trimTagPrefix(VpcClusterIDTagKey): aws.String(cluster.ID),
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create EKS cluster")
+		}
+		logger.Info("EKS cluster created")
+	}
+
+	logger.Info("Waiting for EKS cluster to become active")
+	err = a.Service().eks.WaitUntilClusterActive(&eks.DescribeClusterInput{
+		Name: aws.String(eksMetadata.Name),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "EKS cluster did not become active")
+	}
+
+	return a.GetEKSCluster(eksMetadata.Name)
+}
+
+// EnsureEKSNodeGroup creates the given managed node group of the EKS cluster
+// unless it already exists, and waits for it to become active.
+func (a *Client) EnsureEKSNodeGroup(cluster *model.Cluster, nodeGroup *model.EKSNodeGroup, subnetIDs []string, logger log.FieldLogger) (*eks.Nodegroup, error) {
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	logger = logger.WithFields(log.Fields{
+		"eks-cluster":    eksMetadata.Name,
+		"eks-node-group": nodeGroup.Name,
+	})
+
+	_, err := a.Service().eks.DescribeNodegroup(&eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(eksMetadata.Name),
+		NodegroupName: aws.String(nodeGroup.Name),
+	})
+	if isEKSNotFound(err) {
+		_, err = a.Service().eks.CreateNodegroup(&eks.CreateNodegroupInput{
+			ClusterName:   aws.String(eksMetadata.Name),
+			NodegroupName: aws.String(nodeGroup.Name),
+			NodeRole:      aws.String(eksMetadata.NodeRoleARN),
+			Subnets:       aws.StringSlice(subnetIDs),
+			InstanceTypes: aws.StringSlice([]string{nodeGroup.InstanceType}),
+			ScalingConfig: &eks.NodegroupScalingConfig{
+				MinSize:     aws.Int64(nodeGroup.MinCount),
+				MaxSize:     aws.Int64(nodeGroup.MaxCount),
+				DesiredSize: aws.Int64(nodeGroup.MinCount),
+			},
+			Tags: map[string]*string{
+				trimTagPrefix(VpcClusterIDTagKey): aws.String(cluster.ID),
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create EKS node group")
+		}
+		logger.Info("EKS node group created")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to describe EKS node group")
+	}
+
+	logger.Info("Waiting for EKS node group to become active")
+	err = a.Service().eks.WaitUntilNodegroupActive(&eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(eksMetadata.Name),
+		NodegroupName: aws.String(nodeGroup.Name),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "EKS node group did not become active")
+	}
+
+	output, err := a.Service().eks.DescribeNodegroup(&eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(eksMetadata.Name),
+		NodegroupName: aws.String(nodeGroup.Name),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe EKS node group")
+	}
+
+	return output.Nodegroup, nil
+}
+
+// GetEKSCluster returns the EKS cluster with the given name, or nil if it
+// doesn't exist.
+func (a *Client) GetEKSCluster(clusterName string) (*eks.Cluster, error) {
+	output, err := a.Service().eks.DescribeCluster(&eks.DescribeClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if isEKSNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe EKS cluster")
+	}
+
+	return output.Cluster, nil
+}
+
+// GetEKSNodeGroups returns all the managed node groups of the EKS cluster with
+// the given name.
+func (a *Client) GetEKSNodeGroups(clusterName string) ([]*eks.Nodegroup, error) {
+	nodeGroupNames, err := a.listEKSNodeGroups(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeGroups []*eks.Nodegroup
+	for _, nodeGroupName := range nodeGroupNames {
+		output, err := a.Service().eks.DescribeNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   aws.String(clusterName),
+			NodegroupName: nodeGroupName,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to describe EKS node group %s", *nodeGroupName)
+		}
+		nodeGroups = append(nodeGroups, output.Nodegroup)
+	}
+
+	return nodeGroups, nil
+}
+
+// EnsureEKSClusterDeleted deletes the managed node groups and then the control
+// plane of the EKS cluster with the given name, waiting for each deletion to
+// complete.
+func (a *Client) EnsureEKSClusterDeleted(clusterName string, logger log.FieldLogger) error {
+	logger = logger.WithField("eks-cluster", clusterName)
+
+	eksCluster, err := a.GetEKSCluster(clusterName)
+	if err != nil {
+		return err
+	}
+	if eksCluster == nil {
+		logger.Info("EKS cluster not found; assuming it was already deleted")
+		return nil
+	}
+
+	nodeGroupNames, err := a.listEKSNodeGroups(clusterName)
+	if err != nil {
+		return err
+	}
+
+	// Node groups must be gone before the control plane can be deleted.
+	for _, nodeGroupName := range nodeGroupNames {
+		_, err = a.Service().eks.DeleteNodegroup(&eks.DeleteNodegroupInput{
+			ClusterName:   aws.String(clusterName),
+			NodegroupName: nodeGroupName,
+		})
+		if err != nil && !isEKSNotFound(err) {
+			return errors.Wrapf(err, "failed to delete EKS node group %s", *nodeGroupName)
+		}
+	}
+	for _, nodeGroupName := range nodeGroupNames {
+		logger.Infof("Waiting for EKS node group %s to be deleted", *nodeGroupName)
+		err = a.Service().eks.WaitUntilNodegroupDeleted(&eks.DescribeNodegroupInput{
+			ClusterName:   aws.String(clusterName),
+			NodegroupName: nodeGroupName,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "EKS node group %s was not deleted", *nodeGroupName)
+		}
+	}
+
+	_, err = a.Service().eks.DeleteCluster(&eks.DeleteClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if err != nil && !isEKSNotFound(err) {
+		return errors.Wrap(err, "failed to delete EKS cluster")
+	}
+
+	logger.Info("Waiting for EKS cluster to be deleted")
+	err = a.Service().eks.WaitUntilClusterDeleted(&eks.DescribeClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if err != nil {
+		return errors.Wrap(err, "EKS cluster was not deleted")
+	}
+
+	logger.Info("EKS cluster deleted")
+
+	return nil
+}
+
+func (a *Client) listEKSNodeGroups(clusterName string) ([]*string, error) {
+	var nodeGroupNames []*string
+	input := &eks.ListNodegroupsInput{
+		ClusterName: aws.String(clusterName),
+	}
+	for {
+		output, err := a.Service().eks.ListNodegroups(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list EKS node groups")
+		}
+		nodeGroupNames = append(nodeGroupNames, output.Nodegroups...)
+
+		if output.NextToken == nil {
+			return nodeGroupNames, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// EKSNodeGroupIssues returns a description of each health issue reported for
+// the given node group.
+func EKSNodeGroupIssues(nodeGroup *eks.Nodegroup) []string {
+	if nodeGroup.Health == nil {
+		return nil
+	}
+
+	var issues []string
+	for _, issue := range nodeGroup.Health.Issues {
+		issues = append(issues, fmt.Sprintf("EKS node group %s has issue %s: %s",
+			aws.StringValue(nodeGroup.NodegroupName), aws.StringValue(issue.Code), aws.StringValue(issue.Message)))
+	}
+
+	return issues
+}
+
+// eksVersion converts a cluster version to the major.minor version expected
+// by EKS. A nil version makes EKS pick its latest version.
+func eksVersion(version string) *string {
+	if version == "" || version == "latest" {
+		return nil
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return aws.String(strings.Join(parts, "."))
+}
+
+func isEKSNotFound(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == eks.ErrCodeResourceNotFoundException
+	}
+
+	return false
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func (a *AWSTestSuite) eksCluster() *model.Cluster {
+	return &model.Cluster{
+		ID:          a.ClusterA.ID,
+		Provisioner: model.ProvisionerEKS,
+		ProvisionerMetadataEKS: &model.EKSMetadata{
+			Name:           "eks-cluster",
+			ClusterRoleARN: "arn:aws:iam::0123456789:role/eks-cluster",
+			NodeRoleARN:    "arn:aws:iam::0123456789:role/eks-node",
+			ChangeRequest: &model.EKSMetadataRequestedState{
+				Version: "1.17.9",
+			},
+		},
+	}
+}
+
+func eksNotFoundError() error {
+	return awserr.New(eks.ErrCodeResourceNotFoundException, "not found", nil)
+}
+
+func (a *AWSTestSuite) TestEnsureEKSClusterCreate() {
+	cluster := a.eksCluster()
+	resources := ClusterResources{
+		VpcID:                  a.VPCa,
+		PrivateSubnetIDs:       []string{"subnet-private"},
+		PublicSubnetsIDs:       []string{"subnet-public"},
+		MasterSecurityGroupIDs: []string{"sg-master"},
+	}
+
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(&eks.DescribeClusterInput{Name: aws.String("eks-cluster")}).
+			Return(nil, eksNotFoundError()).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			CreateCluster(gomock.Any()).
+			Do(func(input *eks.CreateClusterInput) {
+				a.Assert().Equal("eks-cluster", *input.Name)
+				a.Assert().Equal("arn:aws:iam::0123456789:role/eks-cluster", *input.RoleArn)
+				a.Assert().Equal("1.17", *input.Version)
+				a.Assert().Equal([]string{"subnet-private", "subnet-public"}, aws.StringValueSlice(input.ResourcesVpcConfig.SubnetIds))
+				a.Assert().Equal([]string{"sg-master"}, aws.StringValueSlice(input.ResourcesVpcConfig.SecurityGroupIds))
+				a.Assert().Equal(a.ClusterA.ID, *input.Tags["CloudClusterID"])
+			}).
+			Return(&eks.CreateClusterOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			WaitUntilClusterActive(&eks.DescribeClusterInput{Name: aws.String("eks-cluster")}).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(&eks.DescribeClusterInput{Name: aws.String("eks-cluster")}).
+			Return(&eks.DescribeClusterOutput{Cluster: &eks.Cluster{Name: aws.String("eks-cluster")}}, nil).
+			Times(1),
+	)
+
+	eksCluster, err := a.Mocks.AWS.EnsureEKSCluster(cluster, resources, log.New())
+	a.Assert().NoError(err)
+	a.Assert().Equal("eks-cluster", *eksCluster.Name)
+}
+
+func (a *AWSTestSuite) TestEnsureEKSClusterExists() {
+	cluster := a.eksCluster()
+
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(gomock.Any()).
+			Return(&eks.DescribeClusterOutput{Cluster: &eks.Cluster{Name: aws.String("eks-cluster")}}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			WaitUntilClusterActive(gomock.Any()).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(gomock.Any()).
+			Return(&eks.DescribeClusterOutput{Cluster: &eks.Cluster{Name: aws.String("eks-cluster")}}, nil).
+			Times(1),
+	)
+	a.Mocks.API.EKS.EXPECT().CreateCluster(gomock.Any()).Times(0)
+
+	eksCluster, err := a.Mocks.AWS.EnsureEKSCluster(cluster, ClusterResources{}, log.New())
+	a.Assert().NoError(err)
+	a.Assert().Equal("eks-cluster", *eksCluster.Name)
+}
+
+func (a *AWSTestSuite) TestEnsureEKSClusterCreateError() {
+	cluster := a.eksCluster()
+
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(gomock.Any()).
+			Return(nil, eksNotFoundError()).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			CreateCluster(gomock.Any()).
+			Return(nil, errors.New("quota exceeded")).
+			Times(1),
+	)
+
+	_, err := a.Mocks.AWS.EnsureEKSCluster(cluster, ClusterResources{}, log.New())
+	a.Assert().Error(err)
+	a.Assert().Contains(err.Error(), "failed to create EKS cluster")
+}
+
+func (a *AWSTestSuite) TestEnsureEKSNodeGroupCreate() {
+	cluster := a.eksCluster()
+	nodeGroup := &model.EKSNodeGroup{
+		Name:         model.EKSNodeGroupNodes,
+		InstanceType: "m5.large",
+		MinCount:     2,
+		MaxCount:     4,
+	}
+	describeInput := &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String("eks-cluster"),
+		NodegroupName: aws.String(model.EKSNodeGroupNodes),
+	}
+
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			DescribeNodegroup(describeInput).
+			Return(nil, eksNotFoundError()).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			CreateNodegroup(gomock.Any()).
+			Do(func(input *eks.CreateNodegroupInput) {
+				a.Assert().Equal("eks-cluster", *input.ClusterName)
+				a.Assert().Equal(model.EKSNodeGroupNodes, *input.NodegroupName)
+				a.Assert().Equal("arn:aws:iam::0123456789:role/eks-node", *input.NodeRole)
+				a.Assert().Equal([]string{"subnet-private"}, aws.StringValueSlice(input.Subnets))
+				a.Assert().Equal([]string{"m5.large"}, aws.StringValueSlice(input.InstanceTypes))
+				a.Assert().Equal(int64(2), *input.ScalingConfig.MinSize)
+				a.Assert().Equal(int64(4), *input.ScalingConfig.MaxSize)
+				a.Assert().Equal(int64(2), *input.ScalingConfig.DesiredSize)
+			}).
+			Return(&eks.CreateNodegroupOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			WaitUntilNodegroupActive(describeInput).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DescribeNodegroup(describeInput).
+			Return(&eks.DescribeNodegroupOutput{Nodegroup: &eks.Nodegroup{NodegroupName: aws.String(model.EKSNodeGroupNodes)}}, nil).
+			Times(1),
+	)
+
+	eksNodeGroup, err := a.Mocks.AWS.EnsureEKSNodeGroup(cluster, nodeGroup, []string{"subnet-private"}, log.New())
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.EKSNodeGroupNodes, *eksNodeGroup.NodegroupName)
+}
+
+func (a *AWSTestSuite) TestGetEKSNodeGroups() {
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("eks-cluster")}).
+			Return(&eks.ListNodegroupsOutput{
+				Nodegroups: []*string{aws.String("nodes")},
+				NextToken:  aws.String("next"),
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("eks-cluster"), NextToken: aws.String("next")}).
+			Return(&eks.ListNodegroupsOutput{
+				Nodegroups: []*string{aws.String("extra")},
+			}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DescribeNodegroup(gomock.Any()).
+			Return(&eks.DescribeNodegroupOutput{Nodegroup: &eks.Nodegroup{NodegroupName: aws.String("nodes")}}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DescribeNodegroup(gomock.Any()).
+			Return(&eks.DescribeNodegroupOutput{Nodegroup: &eks.Nodegroup{NodegroupName: aws.String("extra")}}, nil).
+			Times(1),
+	)
+
+	nodeGroups, err := a.Mocks.AWS.GetEKSNodeGroups("eks-cluster")
+	a.Assert().NoError(err)
+	a.Assert().Len(nodeGroups, 2)
+}
+
+func (a *AWSTestSuite) TestEnsureEKSClusterDeleted() {
+	gomock.InOrder(
+		a.Mocks.API.EKS.EXPECT().
+			DescribeCluster(gomock.Any()).
+			Return(&eks.DescribeClusterOutput{Cluster: &eks.Cluster{Name: aws.String("eks-cluster")}}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			ListNodegroups(gomock.Any()).
+			Return(&eks.ListNodegroupsOutput{Nodegroups: []*string{aws.String("nodes")}}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DeleteNodegroup(&eks.DeleteNodegroupInput{ClusterName: aws.String("eks-cluster"), NodegroupName: aws.String("nodes")}).
+			Return(&eks.DeleteNodegroupOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			WaitUntilNodegroupDeleted(&eks.DescribeNodegroupInput{ClusterName: aws.String("eks-cluster"), NodegroupName: aws.String("nodes")}).
+			Return(nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			DeleteCluster(&eks.DeleteClusterInput{Name: aws.String("eks-cluster")}).
+			Return(&eks.DeleteClusterOutput{}, nil).
+			Times(1),
+
+		a.Mocks.API.EKS.EXPECT().
+			WaitUntilClusterDeleted(&eks.DescribeClusterInput{Name: aws.String("eks-cluster")}).
+			Return(nil).
+			Times(1),
+	)
+
+	err := a.Mocks.AWS.EnsureEKSClusterDeleted("eks-cluster", log.New())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestEnsureEKSClusterDeletedNotFound() {
+	a.Mocks.API.EKS.EXPECT().
+		DescribeCluster(gomock.Any()).
+		Return(nil, eksNotFoundError()).
+		Times(1)
+	a.Mocks.API.EKS.EXPECT().DeleteCluster(gomock.Any()).Times(0)
+
+	err := a.Mocks.AWS.EnsureEKSClusterDeleted("eks-cluster", log.New())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestEKSNodeGroupIssues() {
+	a.Assert().Empty(EKSNodeGroupIssues(&eks.Nodegroup{}))
+
+	issues := EKSNodeGroupIssues(&eks.Nodegroup{
+		NodegroupName: aws.String("nodes"),
+		Health: &eks.NodegroupHealth{
+			Issues: []*eks.Issue{
+				{Code: aws.String("AccessDenied"), Message: aws.String("denied")},
+			},
+		},
+	})
+	a.Assert().Equal([]string{"EKS node group nodes has issue AccessDenied: denied"}, issues)
+}
+
+func (a *AWSTestSuite) TestEKSVersion() {
+	var testCases = []struct {
+		version  string
+		expected *string
+	}{
+		{"", nil},
+		{"latest", nil},
+		{"1.17", aws.String("1.17")},
+		{"1.17.9", aws.String("1.17")},
+	}
+
+	for _, tc := range testCases {
+		a.Assert().Equal(tc.expected, eksVersion(tc.version), tc.version)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExternalS3Filestore is a filestore backed by an S3-compatible bucket managed
+// outside of the provisioner.
+type ExternalS3Filestore struct {
+	installationID  string
+	config          *model.ExternalFilestoreConfig
+	encryptedSecret []byte
+	encryptionKey   []byte
+	s3              s3iface.S3API
+}
+
+// NewExternalS3Filestore returns a new ExternalS3Filestore interface.
+func NewExternalS3Filestore(installationID string, config *model.ExternalFilestoreConfig, encryptedSecret, encryptionKey []byte) *ExternalS3Filestore {
+	return &ExternalS3Filestore{
+		installationID:  installationID,
+		config:          config,
+		encryptedSecret: encryptedSecret,
+		encryptionKey:   encryptionKey,
+	}
+}
+
+// Provision checks that the external bucket can be reached with the
+// credentials of the installation.
+func (f *ExternalS3Filestore) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	s3Client, err := f.s3Client(logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = s3Client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(f.config.Bucket),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to access bucket %s at %s", f.config.Bucket, f.config.Endpoint)
+	}
+
+	logger.WithField("s3-bucket-name", f.config.Bucket).Info("External S3 filestore is reachable")
+
+	return nil
+}
+
+// Teardown leaves the external bucket untouched, unless the installation
+// allows its files to be deleted and the server doesn't keep filestore data.
+func (f *ExternalS3Filestore) Teardown(keepData bool, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	if f.config == nil {
+		return errors.New("external filestore configuration is missing")
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"s3-bucket-name": f.config.Bucket,
+		"s3-prefix":      f.config.Prefix,
+	})
+
+	if !f.config.DeleteDataOnTeardown {
+		logger.Info("External S3 filestore was left intact as the installation doesn't allow deleting its data")
+		return nil
+	}
+	if keepData {
+		logger.Info("External S3 filestore was left intact due to the keep-data setting of this server")
+		return nil
+	}
+	if f.config.Prefix == "" {
+		return errors.New("refusing to delete the files of an external filestore without a prefix")
+	}
+
+	s3Client, err := f.s3Client(logger)
+	if err != nil {
+		return err
+	}
+
+	iter := s3manager.NewDeleteListIterator(s3Client, &s3.ListObjectsInput{
+		Bucket: aws.String(f.config.Bucket),
+		Prefix: aws.String(f.config.Prefix + "/"),
+	})
+
+	err = s3manager.NewBatchDeleteWithClient(s3Client).Delete(aws.BackgroundContext(), iter)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete external filestore files")
+	}
+
+	logger.Info("External S3 filestore files were deleted")
+
+	return nil
+}
+
+// GenerateFilestoreSpecAndSecret creates the k8s filestore spec and secret for
+// accessing the external bucket.
+func (f *ExternalS3Filestore) GenerateFilestoreSpecAndSecret(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Minio, *corev1.Secret, error) {
+	secretAccessKey, err := f.secretAccessKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filestoreSecretName := fmt.Sprintf("%s-external-s3", f.installationID)
+	filestoreSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: filestoreSecretName,
+		},
+		StringData: map[string]string{
+			"accesskey": f.config.AccessKeyID,
+			"secretkey": secretAccessKey,
+		},
+	}
+
+	filestoreSpec := &mmv1alpha1.Minio{
+		ExternalURL:    f.config.Endpoint,
+		ExternalBucket: f.config.Bucket,
+		Secret:         filestoreSecretName,
+	}
+
+	logger.Debug("Cluster installation configured to use an external S3 filestore")
+
+	return filestoreSpec, filestoreSecret, nil
+}
+
+// secretAccessKey decrypts the secret access key of the external bucket.
+func (f *ExternalS3Filestore) secretAccessKey() (string, error) {
+	if f.config == nil {
+		return "", errors.New("external filestore configuration is missing")
+	}
+	if len(f.encryptionKey) == 0 {
+		return "", errors.New("no encryption key configured to decrypt the secret access key")
+	}
+
+	secretAccessKey, err := model.DecryptSecret(f.encryptionKey, f.encryptedSecret, []byte(f.installationID))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt secret access key")
+	}
+
+	return string(secretAccessKey), nil
+}
+
+// s3Client returns a client of the S3 API of the external endpoint.
+func (f *ExternalS3Filestore) s3Client(logger log.FieldLogger) (s3iface.S3API, error) {
+	if f.s3 != nil {
+		return f.s3, nil
+	}
+
+	secretAccessKey, err := f.secretAccessKey()
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "https"
+	if f.config.Insecure {
+		scheme = "http"
+	}
+
+	sess, err := NewAWSSessionWithLogger(&aws.Config{
+		Endpoint:         aws.String(fmt.Sprintf("%s://%s", scheme, f.config.Endpoint)),
+		Region:           aws.String(DefaultAWSRegion),
+		Credentials:      credentials.NewStaticCredentials(f.config.AccessKeyID, secretAccessKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	}, logger.WithField("tools-aws", "external-s3"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create external S3 session")
+	}
+	f.s3 = s3.New(sess)
+
+	return f.s3, nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+func (a *AWSTestSuite) newExternalS3Filestore(config *model.ExternalFilestoreConfig) *ExternalS3Filestore {
+	encryptionKey := bytes.Repeat([]byte{1}, model.EncryptionKeySize)
+	encryptedSecret, err := model.EncryptSecret(encryptionKey, []byte("secret"), []byte(a.InstallationA.ID))
+	a.Require().NoError(err)
+
+	filestore := NewExternalS3Filestore(a.InstallationA.ID, config, encryptedSecret, encryptionKey)
+	filestore.s3 = a.Mocks.API.S3
+
+	return filestore
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreProvision() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		AccessKeyID: "access",
+	})
+
+	a.Mocks.API.S3.EXPECT().
+		HeadBucket(gomock.Any()).
+		Do(func(input *s3.HeadBucketInput) {
+			a.Assert().Equal("mattermost-files", *input.Bucket)
+		}).
+		Return(&s3.HeadBucketOutput{}, nil).
+		Times(1)
+
+	err := filestore.Provision(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreProvisionUnreachable() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		AccessKeyID: "access",
+	})
+
+	a.Mocks.API.S3.EXPECT().
+		HeadBucket(gomock.Any()).
+		Return(nil, errors.New("forbidden")).
+		Times(1)
+
+	err := filestore.Provision(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+	a.Assert().Contains(err.Error(), "mattermost-files")
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreSecretOfOtherInstallation() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		AccessKeyID: "access",
+	})
+	secretAccessKey, err := filestore.secretAccessKey()
+	a.Require().NoError(err)
+	a.Assert().Equal("secret", secretAccessKey)
+
+	// The secret access key is bound to the installation it was stored for.
+	filestore.installationID = model.NewID()
+	secretAccessKey, err = filestore.secretAccessKey()
+	a.Assert().Error(err)
+	a.Assert().Empty(secretAccessKey)
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreTeardownKeepsData() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		Prefix:      "customer1",
+		AccessKeyID: "access",
+	})
+
+	err := filestore.Teardown(false, a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+
+	filestore.config.DeleteDataOnTeardown = true
+	err = filestore.Teardown(true, a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreTeardownWithoutPrefix() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:             "minio.example.com:9000",
+		Bucket:               "mattermost-files",
+		AccessKeyID:          "access",
+		DeleteDataOnTeardown: true,
+	})
+
+	err := filestore.Teardown(false, a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Assert().Error(err)
+	a.Assert().Contains(err.Error(), "without a prefix")
+}
+
+func (a *AWSTestSuite) TestExternalS3FilestoreGenerateFilestoreSpecAndSecret() {
+	filestore := a.newExternalS3Filestore(&model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		AccessKeyID: "access",
+	})
+
+	filestoreSpec, filestoreSecret, err := filestore.GenerateFilestoreSpecAndSecret(a.Mocks.Model.DatabaseInstallationStore, testlib.NewLoggerEntry())
+	a.Require().NoError(err)
+	a.Assert().Equal("minio.example.com:9000", filestoreSpec.ExternalURL)
+	a.Assert().Equal("mattermost-files", filestoreSpec.ExternalBucket)
+	a.Assert().Equal(filestoreSecret.Name, filestoreSpec.Secret)
+	a.Assert().Equal("access", filestoreSecret.StringData["accesskey"])
+	a.Assert().Equal("secret", filestoreSecret.StringData["secretkey"])
+}
@@ -76,11 +76,6 @@ func RDSMasterInstanceID(installationID string) string {
 	return fmt.Sprintf("%s-master", CloudID(installationID))
 }
 
-// RDSMigrationInstanceID formats the name used for migrated RDS database instances.
-func RDSMigrationInstanceID(installationID string) string {
-	return fmt.Sprintf("%s-migration", CloudID(installationID))
-}
-
 // IsErrorCode asserts that an AWS error has a certain code.
 func IsErrorCode(err error, code string) bool {
 	if err != nil {
@@ -97,6 +92,36 @@ func RDSMultitenantSecretName(id string) string {
 	return fmt.Sprintf("rds-multitenant-%s", id)
 }
 
+// RDSMultitenantMigrationSecretName formats the name of the secret used by the
+// job migrating an installation database between multitenant RDS clusters.
+func RDSMultitenantMigrationSecretName(installationID string) string {
+	return fmt.Sprintf("%s-migration", RDSMultitenantSecretName(installationID))
+}
+
+// ExternalDatabaseSecretName formats the name of the secret holding the
+// connection string of an external database in the installation namespace.
+func ExternalDatabaseSecretName(installationID string) string {
+	return fmt.Sprintf("external-database-%s", installationID)
+}
+
+// RDSMultitenantClusterID formats the ID of the multitenant RDS cluster with
+// the given sequence number created by the provisioner in the given VPC.
+func RDSMultitenantClusterID(vpcID string, sequence int) string {
+	return fmt.Sprintf("%s-%s-%d", RDSMultitenantDBClusterResourceNamePrefix, strings.TrimPrefix(vpcID, "vpc-"), sequence)
+}
+
+// RDSMultitenantClusterInstanceID formats the ID of the instance of a
+// multitenant RDS cluster created by the provisioner.
+func RDSMultitenantClusterInstanceID(rdsClusterID string) string {
+	return fmt.Sprintf("%s-master", rdsClusterID)
+}
+
+// RDSMultitenantClusterMasterSecretDescription formats the text used for
+// describing the master secret of a multitenant RDS cluster.
+func RDSMultitenantClusterMasterSecretDescription(rdsClusterID string) string {
+	return fmt.Sprintf("Master password of the multitenant RDS cluster ID: %s", rdsClusterID)
+}
+
 // MattermostMultitenantS3Name formats the name of a Mattermost S3 multitenant
 // filestore bucket name.
 func MattermostMultitenantS3Name(environmentName, vpcID string) string {
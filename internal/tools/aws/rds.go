@@ -193,6 +193,113 @@ func (a *Client) rdsEnsureDBClusterInstanceCreated(awsID, instanceName, database
 	return nil
 }
 
+// rdsEnsureMultitenantDBClusterCreated creates a multitenant RDS cluster and
+// its instance with the given engine settings, skipping the ones that already
+// exist.
+func (a *Client) rdsEnsureMultitenantDBClusterCreated(awsID, vpcID, password, kmsKeyID, databaseType string, settings *model.MultitenantDatabaseEngineSettings, tags []*rds.Tag, logger log.FieldLogger) error {
+	var engine, engineVersion, instanceClass, sgTagValue string
+	var port int64
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		engine = "aurora-mysql"
+		engineVersion = DefaultDatabaseMySQLVersion
+		instanceClass = "db.t3.small"
+		port = 3306
+		sgTagValue = DefaultDBSecurityGroupTagMySQLValue
+	case model.DatabaseEngineTypePostgres:
+		engine = "aurora-postgresql"
+		engineVersion = DefaultDatabasePostgresVersion
+		instanceClass = "db.r5.large"
+		port = 5432
+		sgTagValue = DefaultDBSecurityGroupTagPostgresValue
+	default:
+		return errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+	if len(settings.EngineVersion) != 0 {
+		engineVersion = settings.EngineVersion
+	}
+	if len(settings.InstanceClass) != 0 {
+		instanceClass = settings.InstanceClass
+	}
+
+	_, err := a.Service().rds.DescribeDBClusters(&rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(awsID),
+	})
+	if err == nil {
+		logger.WithField("db-cluster-name", awsID).Debug("AWS multitenant DB cluster already created")
+	} else {
+		err = a.rdsCreateMultitenantDBCluster(awsID, vpcID, password, kmsKeyID, engine, engineVersion, port, sgTagValue, tags, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	instanceName := RDSMultitenantClusterInstanceID(awsID)
+	_, err = a.Service().rds.DescribeDBInstances(&rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(instanceName),
+	})
+	if err == nil {
+		logger.WithField("db-instance-name", instanceName).Debug("AWS multitenant DB instance already created")
+
+		return nil
+	}
+
+	_, err = a.Service().rds.CreateDBInstance(&rds.CreateDBInstanceInput{
+		DBClusterIdentifier:  aws.String(awsID),
+		DBInstanceIdentifier: aws.String(instanceName),
+		DBInstanceClass:      aws.String(instanceClass),
+		Engine:               aws.String(engine),
+		PubliclyAccessible:   aws.Bool(false),
+		Tags:                 tags,
+	})
+	if err != nil && !IsErrorCode(err, rds.ErrCodeDBInstanceAlreadyExistsFault) {
+		return errors.Wrap(err, "failed to create multitenant DB instance")
+	}
+
+	logger.WithField("db-instance-name", instanceName).Debug("AWS multitenant DB instance created")
+
+	return nil
+}
+
+func (a *Client) rdsCreateMultitenantDBCluster(awsID, vpcID, password, kmsKeyID, engine, engineVersion string, port int64, sgTagValue string, tags []*rds.Tag, logger log.FieldLogger) error {
+	dbSecurityGroupIDs, err := a.rdsGetDBSecurityGroupIDs(vpcID, sgTagValue, logger)
+	if err != nil {
+		return err
+	}
+
+	dbSubnetGroupName, err := a.rdsGetDBSubnetGroupName(vpcID, logger)
+	if err != nil {
+		return err
+	}
+
+	input := &rds.CreateDBClusterInput{
+		BackupRetentionPeriod: aws.Int64(7),
+		DBClusterIdentifier:   aws.String(awsID),
+		EngineMode:            aws.String("provisioned"),
+		Engine:                aws.String(engine),
+		EngineVersion:         aws.String(engineVersion),
+		MasterUserPassword:    aws.String(password),
+		MasterUsername:        aws.String(DefaultMattermostDatabaseUsername),
+		Port:                  aws.Int64(port),
+		StorageEncrypted:      aws.Bool(true),
+		DBSubnetGroupName:     aws.String(dbSubnetGroupName),
+		VpcSecurityGroupIds:   aws.StringSlice(dbSecurityGroupIDs),
+		Tags:                  tags,
+	}
+	if len(kmsKeyID) != 0 {
+		input.KmsKeyId = aws.String(kmsKeyID)
+	}
+
+	_, err = a.Service().rds.CreateDBCluster(input)
+	if err != nil && !IsErrorCode(err, rds.ErrCodeDBClusterAlreadyExistsFault) {
+		return errors.Wrap(err, "failed to create multitenant DB cluster")
+	}
+
+	logger.WithField("db-cluster-name", awsID).Debug("AWS multitenant DB cluster created")
+
+	return nil
+}
+
 func (a *Client) rdsEnsureDBClusterDeleted(awsID string, logger log.FieldLogger) error {
 	result, err := a.Service().rds.DescribeDBClusters(&rds.DescribeDBClustersInput{
 		DBClusterIdentifier: aws.String(awsID),
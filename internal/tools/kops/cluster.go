@@ -210,6 +210,23 @@ func (c *Cmd) Replace(name string) (string, error) {
 	return trimmed, nil
 }
 
+// Create invokes kops create, using the context of the created Cmd, and
+// returns the stdout. The filename passed in is expected to be in the root temp
+// dir of this kops command.
+func (c *Cmd) Create(name string) (string, error) {
+	stdout, _, err := c.run(
+		"create",
+		arg("filename", path.Join(c.GetTempDir(), name)),
+		arg("state", "s3://", c.s3StateStore),
+	)
+	trimmed := strings.TrimSuffix(string(stdout), "\n")
+	if err != nil {
+		return trimmed, errors.Wrap(err, "failed to invoke kops create")
+	}
+
+	return trimmed, nil
+}
+
 // Version invokes kops version, using the context of the created Cmd, and
 // returns the stdout.
 func (c *Cmd) Version() (string, error) {
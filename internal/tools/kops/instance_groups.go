@@ -31,13 +31,33 @@ type InstanceGroupSpec struct {
 	MachineType string `json:"machineType"`
 	MinSize     int64  `json:"minSize"`
 	MaxSize     int64  `json:"maxSize"`
+
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	MaxPrice             string                `json:"maxPrice,omitempty"`
+	MixedInstancesPolicy *MixedInstancesPolicy `json:"mixedInstancesPolicy,omitempty"`
+}
+
+// MixedInstancesPolicy is the mixed instances policy of a kops instance group.
+type MixedInstancesPolicy struct {
+	Instances []string `json:"instances,omitempty"`
+}
+
+// mixedInstanceTypes returns the instance types of the mixed instances policy
+// of the instance group, if any.
+func (spec *InstanceGroupSpec) mixedInstanceTypes() []string {
+	if spec.MixedInstancesPolicy == nil {
+		return nil
+	}
+
+	return spec.MixedInstancesPolicy.Instances
 }
 
 // UpdateMetadata updates KopsMetadata with the current values from kops state
 // store. This can be a bit tricky. We are attempting to correlate multiple kops
 // instance groups into a simplified set of metadata information. To do so, we
 // assume and check the following:
-// - There is one worker node instance group.
+// - There is one default worker node instance group, plus optional pools.
 // - There is one or more master instance groups.
 // - All of the cluster hosts are running the same AMI.
 // - All of the master nodes are running the same instance type.
@@ -46,14 +66,16 @@ type InstanceGroupSpec struct {
 // scope of updating the metadata. Instead, warnings for each violation are
 // returned and stored.
 func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
-	instanceGroups, err := c.GetInstanceGroupsJSON(metadata.Name)
+	kopsInstanceGroups, err := c.GetInstanceGroupsJSON(metadata.Name)
 	if err != nil {
 		return err
 	}
 
 	var masterIGCount, NodeIGCount, nodeMinCount, nodeMaxCount int64
-	var masterMachineType, nodeInstanceType, AMI string
-	for _, ig := range instanceGroups {
+	var masterMachineType, nodeInstanceType, nodeSpotMaxPrice, AMI string
+	var nodeMixedInstanceTypes []string
+	var instanceGroups []*model.KopsInstanceGroup
+	for _, ig := range kopsInstanceGroups {
 		switch ig.Spec.Role {
 		case "Master":
 			if AMI == "" {
@@ -82,10 +104,25 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 				c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 			}
 
+			instanceGroups = append(instanceGroups, &model.KopsInstanceGroup{
+				Name:               ig.Metadata.Name,
+				InstanceType:       ig.Spec.MachineType,
+				MinCount:           ig.Spec.MinSize,
+				MaxCount:           ig.Spec.MaxSize,
+				NodeLabels:         customNodeLabels(ig.Spec.NodeLabels),
+				SpotMaxPrice:       ig.Spec.MaxPrice,
+				MixedInstanceTypes: ig.Spec.mixedInstanceTypes(),
+			})
+
+			if ig.Metadata.Name != model.KopsInstanceGroupNodes {
+				continue
+			}
 			NodeIGCount++
 			nodeInstanceType = ig.Spec.MachineType
 			nodeMinCount = ig.Spec.MinSize
 			nodeMaxCount = ig.Spec.MaxSize
+			nodeSpotMaxPrice = ig.Spec.MaxPrice
+			nodeMixedInstanceTypes = ig.Spec.mixedInstanceTypes()
 		default:
 			warning := fmt.Sprintf("Instance group %s has unknown role %s", ig.Metadata.Name, ig.Spec.Role)
 			metadata.AddWarning(warning)
@@ -99,7 +136,7 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 		c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 	}
 	if NodeIGCount != 1 {
-		warning := fmt.Sprintf("expected exactly 1 node instance group, but found %d", NodeIGCount)
+		warning := fmt.Sprintf("expected exactly 1 %s instance group, but found %d", model.KopsInstanceGroupNodes, NodeIGCount)
 		metadata.AddWarning(warning)
 		c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 	}
@@ -110,6 +147,44 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 	metadata.NodeInstanceType = nodeInstanceType
 	metadata.NodeMinCount = nodeMinCount
 	metadata.NodeMaxCount = nodeMaxCount
+	metadata.InstanceGroups = instanceGroups
+	metadata.NodeSpotMaxPrice = nodeSpotMaxPrice
+	metadata.NodeMixedInstanceTypes = nodeMixedInstanceTypes
+
+	return nil
+}
+
+// customNodeLabels returns the given node labels without the instance group
+// label set by kops.
+func customNodeLabels(nodeLabels map[string]string) map[string]string {
+	var labels map[string]string
+	for key, value := range nodeLabels {
+		if key == model.KopsInstanceGroupLabel {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+	}
+
+	return labels
+}
+
+// DeleteInstanceGroup invokes kops delete instancegroup, using the context of
+// the created Cmd.
+func (c *Cmd) DeleteInstanceGroup(clusterName, igName string) error {
+	_, _, err := c.run(
+		"delete",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		igName,
+		"--yes",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops delete instancegroup")
+	}
 
 	return nil
 }
@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package localcluster wraps kind and k3d, which run kubernetes clusters
+// inside of docker containers on the local machine.
+package localcluster
+
+import (
+	"os/exec"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Cmd is the kind or k3d command to execute.
+type Cmd struct {
+	engine     string
+	enginePath string
+	logger     log.FieldLogger
+}
+
+// New creates a new instance of Cmd through which to execute the given
+// engine, either kind or k3d.
+func New(engine string, logger log.FieldLogger) (*Cmd, error) {
+	if !model.IsSupportedLocalClusterEngine(engine) {
+		return nil, errors.Errorf("unsupported local cluster engine %s", engine)
+	}
+
+	enginePath, err := exec.LookPath(engine)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find %s installed on your PATH", engine)
+	}
+
+	return &Cmd{
+		engine:     engine,
+		enginePath: enginePath,
+		logger:     logger,
+	}, nil
+}
+
+// Close is a no-op.
+func (c *Cmd) Close() error {
+	return nil
+}
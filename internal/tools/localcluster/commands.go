@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package localcluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// CreateCluster creates a local cluster with the given name. The cluster runs
+// the given kubernetes version, or the default version of the engine when the
+// version is "latest".
+func (c *Cmd) CreateCluster(name, version string) error {
+	_, _, err := c.run(createClusterArgs(c.engine, name, version)...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to invoke %s create cluster", c.engine)
+	}
+
+	return nil
+}
+
+// DeleteCluster deletes the local cluster with the given name.
+func (c *Cmd) DeleteCluster(name string) error {
+	_, _, err := c.run(deleteClusterArgs(c.engine, name)...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to invoke %s delete cluster", c.engine)
+	}
+
+	return nil
+}
+
+// ClusterExists returns true if a local cluster with the given name exists.
+func (c *Cmd) ClusterExists(name string) (bool, error) {
+	stdout, _, err := c.run(listClustersArgs(c.engine)...)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to invoke %s list clusters", c.engine)
+	}
+
+	for _, clusterName := range parseClusterNames(string(stdout)) {
+		if clusterName == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetKubeconfig returns the kubeconfig of the local cluster with the given
+// name.
+func (c *Cmd) GetKubeconfig(name string) ([]byte, error) {
+	stdout, _, err := c.runSilent(getKubeconfigArgs(c.engine, name)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to invoke %s get kubeconfig", c.engine)
+	}
+
+	return stdout, nil
+}
+
+func createClusterArgs(engine, name, version string) []string {
+	if engine == model.LocalClusterEngineK3d {
+		args := []string{"cluster", "create", name, "--wait", "--timeout", "5m"}
+		if version != "latest" {
+			args = append(args, "--image", fmt.Sprintf("rancher/k3s:v%s-k3s1", version))
+		}
+		return args
+	}
+
+	args := []string{"create", "cluster", "--name", name, "--wait", "5m"}
+	if version != "latest" {
+		args = append(args, "--image", fmt.Sprintf("kindest/node:v%s", version))
+	}
+	return args
+}
+
+func deleteClusterArgs(engine, name string) []string {
+	if engine == model.LocalClusterEngineK3d {
+		return []string{"cluster", "delete", name}
+	}
+
+	return []string{"delete", "cluster", "--name", name}
+}
+
+func listClustersArgs(engine string) []string {
+	if engine == model.LocalClusterEngineK3d {
+		return []string{"cluster", "list", "--no-headers"}
+	}
+
+	return []string{"get", "clusters"}
+}
+
+func getKubeconfigArgs(engine, name string) []string {
+	if engine == model.LocalClusterEngineK3d {
+		return []string{"kubeconfig", "get", name}
+	}
+
+	return []string{"get", "kubeconfig", "--name", name}
+}
+
+// parseClusterNames returns the cluster names listed in the output of the
+// list clusters command, which starts every line with a cluster name.
+func parseClusterNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		names = append(names, fields[0])
+	}
+
+	return names
+}
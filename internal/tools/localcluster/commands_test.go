@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package localcluster
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateClusterArgs(t *testing.T) {
+	var argsTests = []struct {
+		name     string
+		engine   string
+		version  string
+		expected []string
+	}{
+		{
+			"kind latest",
+			model.LocalClusterEngineKind,
+			"latest",
+			[]string{"create", "cluster", "--name", "cluster1", "--wait", "5m"},
+		}, {
+			"kind version",
+			model.LocalClusterEngineKind,
+			"1.18.2",
+			[]string{"create", "cluster", "--name", "cluster1", "--wait", "5m", "--image", "kindest/node:v1.18.2"},
+		}, {
+			"k3d latest",
+			model.LocalClusterEngineK3d,
+			"latest",
+			[]string{"cluster", "create", "cluster1", "--wait", "--timeout", "5m"},
+		}, {
+			"k3d version",
+			model.LocalClusterEngineK3d,
+			"1.18.2",
+			[]string{"cluster", "create", "cluster1", "--wait", "--timeout", "5m", "--image", "rancher/k3s:v1.18.2-k3s1"},
+		},
+	}
+
+	for _, tt := range argsTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, createClusterArgs(tt.engine, "cluster1", tt.version))
+		})
+	}
+}
+
+func TestEngineArgs(t *testing.T) {
+	assert.Equal(t, []string{"delete", "cluster", "--name", "cluster1"}, deleteClusterArgs(model.LocalClusterEngineKind, "cluster1"))
+	assert.Equal(t, []string{"cluster", "delete", "cluster1"}, deleteClusterArgs(model.LocalClusterEngineK3d, "cluster1"))
+	assert.Equal(t, []string{"get", "clusters"}, listClustersArgs(model.LocalClusterEngineKind))
+	assert.Equal(t, []string{"cluster", "list", "--no-headers"}, listClustersArgs(model.LocalClusterEngineK3d))
+	assert.Equal(t, []string{"get", "kubeconfig", "--name", "cluster1"}, getKubeconfigArgs(model.LocalClusterEngineKind, "cluster1"))
+	assert.Equal(t, []string{"kubeconfig", "get", "cluster1"}, getKubeconfigArgs(model.LocalClusterEngineK3d, "cluster1"))
+}
+
+func TestParseClusterNames(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, parseClusterNames(""))
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		assert.Equal(t, []string{"cluster1", "cluster2"}, parseClusterNames("cluster1\ncluster2\n"))
+	})
+
+	t.Run("k3d", func(t *testing.T) {
+		assert.Equal(t, []string{"cluster1", "cluster2"}, parseClusterNames("cluster1   1/1   0/0   true\ncluster2   1/1   0/0   true\n"))
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("minikube", nil)
+	assert.Error(t, err)
+}
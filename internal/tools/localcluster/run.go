@@ -0,0 +1,44 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package localcluster
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/exechelper"
+	log "github.com/sirupsen/logrus"
+)
+
+func (c *Cmd) outputLogger(line string, logger log.FieldLogger) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	logger.Infof("[%s] %s", c.engine, line)
+}
+
+func (c *Cmd) run(arg ...string) ([]byte, []byte, error) {
+	cmd := exec.Command(c.enginePath, arg...)
+
+	return exechelper.Run(cmd, c.logger, c.outputLogger)
+}
+
+// runSilent runs the command without logging its output, which may contain
+// credentials.
+func (c *Cmd) runSilent(arg ...string) ([]byte, []byte, error) {
+	cmd := exec.Command(c.enginePath, arg...)
+
+	return exechelper.Run(cmd, silentLogger(), func(string, log.FieldLogger) {})
+}
+
+func silentLogger() log.FieldLogger {
+	silentLogger := log.New()
+	silentLogger.Out = ioutil.Discard
+
+	return silentLogger
+}
@@ -83,15 +83,18 @@ func copyFile(source string, dest string) error {
 
 // ResourceUtil is used for calling any filestore type.
 type ResourceUtil struct {
-	awsClient  *aws.Client
-	instanceID string
+	awsClient     *aws.Client
+	instanceID    string
+	encryptionKey []byte
 }
 
-// NewResourceUtil returns a new instance of ResourceUtil.
-func NewResourceUtil(instanceID string, awsClient *aws.Client) *ResourceUtil {
+// NewResourceUtil returns a new instance of ResourceUtil. The encryption key
+// decrypts the credentials of external databases and filestores.
+func NewResourceUtil(instanceID string, awsClient *aws.Client, encryptionKey []byte) *ResourceUtil {
 	return &ResourceUtil{
-		awsClient:  awsClient,
-		instanceID: instanceID,
+		awsClient:     awsClient,
+		instanceID:    instanceID,
+		encryptionKey: encryptionKey,
 	}
 }
 
@@ -104,6 +107,8 @@ func (r *ResourceUtil) GetFilestore(installation *model.Installation) model.File
 		return aws.NewS3Filestore(installation.ID, r.awsClient)
 	case model.InstallationFilestoreMultiTenantAwsS3:
 		return aws.NewS3MultitenantFilestore(installation.ID, r.awsClient)
+	case model.InstallationFilestoreExternalS3:
+		return aws.NewExternalS3Filestore(installation.ID, installation.ExternalFilestoreConfig, installation.EncryptedExternalFilestoreSecret, r.encryptionKey)
 	}
 
 	// Warning: we should never get here as it would mean that we didn't match
@@ -121,9 +126,11 @@ func (r *ResourceUtil) GetDatabase(installation *model.Installation) model.Datab
 	case model.InstallationDatabaseSingleTenantRDSPostgres:
 		return aws.NewRDSDatabase(model.DatabaseEngineTypePostgres, installation.ID, r.awsClient)
 	case model.InstallationDatabaseMultiTenantRDSMySQL:
-		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypeMySQL, r.instanceID, installation.ID, r.awsClient)
+		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypeMySQL, r.instanceID, installation.ID, installation.MigrationTargetDatabaseID, r.awsClient)
 	case model.InstallationDatabaseMultiTenantRDSPostgres:
-		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypePostgres, r.instanceID, installation.ID, r.awsClient)
+		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypePostgres, r.instanceID, installation.ID, installation.MigrationTargetDatabaseID, r.awsClient)
+	case model.InstallationDatabaseExternal:
+		return aws.NewExternalDatabase(installation.ID, installation.ExternalDatabaseSecretName, installation.EncryptedExternalDatabase, r.encryptionKey, r.awsClient)
 	}
 
 	// Warning: we should never get here as it would mean that we didn't match
@@ -131,6 +138,20 @@ func (r *ResourceUtil) GetDatabase(installation *model.Installation) model.Datab
 	return model.NewMysqlOperatorDatabase()
 }
 
+// GetInstallationDatabaseMigration returns the InstallationDatabaseMigration
+// interface moving the database of the installation to its migration target
+// database, or nil if the database type of the installation can't be moved.
+func (r *ResourceUtil) GetInstallationDatabaseMigration(installation *model.Installation) model.InstallationDatabaseMigration {
+	switch installation.Database {
+	case model.InstallationDatabaseMultiTenantRDSMySQL:
+		return aws.NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypeMySQL, r.instanceID, installation.ID, installation.MigrationTargetDatabaseID, r.awsClient)
+	case model.InstallationDatabaseMultiTenantRDSPostgres:
+		return aws.NewRDSMultitenantDatabaseMigration(model.DatabaseEngineTypePostgres, r.instanceID, installation.ID, installation.MigrationTargetDatabaseID, r.awsClient)
+	}
+
+	return nil
+}
+
 // Retry is retrying a function for a maximum number of attempts and time
 func Retry(attempts int, sleep time.Duration, f func() error) error {
 	if err := f(); err != nil {
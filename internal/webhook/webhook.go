@@ -7,8 +7,10 @@ package webhook
 import (
 	"bytes"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -16,10 +18,23 @@ import (
 
 type webhookStore interface {
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetLatestResourceEvent(resourceID string) (*model.Event, error)
+	CreateEvent(event *model.Event) error
 }
 
-// SendToAllWebhooks sends a given payload to all webhooks.
-func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger *log.Entry) error {
+// SendToAllWebhooks records the given event in the event log and queues its
+// payload for delivery to all webhooks whose event filter matches the payload.
+//
+// Payloads are written to the webhook delivery outbox and are sent, and
+// retried if necessary, by the webhook delivery supervisor.
+func SendToAllWebhooks(store webhookStore, event *model.Event, logger *log.Entry) error {
+	var payload *model.WebhookPayload
+	if event != nil && event.Payload != nil {
+		payload = event.Payload
+		recordEvent(store, event, logger)
+	}
+
 	hooks, err := store.GetWebhooks(&model.WebhookFilter{
 		PerPage:        model.AllPerPage,
 		IncludeDeleted: false,
@@ -28,26 +43,65 @@ func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger
 		return errors.Wrap(err, "Failed to find webhooks")
 	}
 
-	sendWebhooks(hooks, payload, logger)
+	var matchingHooks []*model.Webhook
+	for _, hook := range hooks {
+		if hook.ShouldReceive(payload) {
+			matchingHooks = append(matchingHooks, hook)
+		}
+	}
 
-	return nil
+	return queueWebhooks(store, matchingHooks, payload, logger)
 }
 
-// sendWebhooks sends webhooks via fire-and-forget goroutines. The send-webhook
-// failures are logged, but not handled.
-func sendWebhooks(hooks []*model.Webhook, payload *model.WebhookPayload, logger *log.Entry) {
-	if len(hooks) == 0 {
+// recordEvent records the given event in the event log and observes how long
+// the resource spent in the state it is transitioning out of.
+func recordEvent(store webhookStore, event *model.Event, logger *log.Entry) {
+	previous, err := store.GetLatestResourceEvent(event.Payload.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get previous event")
+	}
+
+	err = store.CreateEvent(event)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record event")
 		return
 	}
 
-	logger.Debugf("Sending %d webhook(s)", len(hooks))
+	if previous != nil && previous.Payload.NewState == event.Payload.OldState {
+		metrics.StateDuration.
+			WithLabelValues(event.Payload.Type, event.Payload.OldState).
+			Observe(float64(event.CreateAt-previous.CreateAt) / 1000)
+	}
+}
+
+// queueWebhooks records a webhook delivery for each of the given webhooks.
+func queueWebhooks(store webhookStore, hooks []*model.Webhook, payload *model.WebhookPayload, logger *log.Entry) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	logger.Debugf("Queueing %d webhook(s)", len(hooks))
 
 	for _, hook := range hooks {
-		go sendWebhook(hook, payload, logger)
+		err := store.CreateWebhookDelivery(&model.WebhookDelivery{
+			WebhookID: hook.ID,
+			Payload:   payload,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to queue webhook delivery for webhook %s", hook.ID)
+		}
 	}
+
+	return nil
+}
+
+// Deliver performs a single delivery attempt of the given webhook delivery.
+// Both transport failures and non-2xx responses are returned as errors.
+func Deliver(hook *model.Webhook, delivery *model.WebhookDelivery, logger log.FieldLogger) error {
+	return sendWebhook(hook, delivery.ID, delivery.Payload, logger)
 }
 
-func sendWebhook(hook *model.Webhook, payload *model.WebhookPayload, logger *log.Entry) error {
+func sendWebhook(hook *model.Webhook, deliveryID string, payload *model.WebhookPayload, logger log.FieldLogger) error {
 	payloadStr, err := payload.ToJSON()
 	if err != nil {
 		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to create payload string to send to webhook")
@@ -55,14 +109,30 @@ func sendWebhook(hook *model.Webhook, payload *model.WebhookPayload, logger *log
 	}
 
 	req, err := http.NewRequest("POST", hook.URL, bytes.NewBuffer([]byte(payloadStr)))
+	if err != nil {
+		return errors.Wrap(err, "unable to create webhook request")
+	}
 	req.Header.Set("Content-Type", "application/json")
 
+	timestamp := time.Now().Unix()
+	req.Header.Set(model.WebhookHeaderDeliveryID, deliveryID)
+	req.Header.Set(model.WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if hook.IsSigned() {
+		req.Header.Set(model.WebhookHeaderSignature, model.SignWebhookPayload(hook.Secret, timestamp, []byte(payloadStr)))
+	}
+
 	client := &http.Client{Timeout: 5 * time.Second}
-	_, err = client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to send webhook")
+		logger.WithField("webhookURL", hook.URL).WithError(err).Warn("Unable to send webhook")
 		return errors.Wrap(err, "unable to send webhook")
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.WithField("webhookURL", hook.URL).Warnf("Webhook receiver responded with status code %d", resp.StatusCode)
+		return errors.Errorf("unable to send webhook: receiver responded with status code %d", resp.StatusCode)
+	}
 
 	return nil
 }
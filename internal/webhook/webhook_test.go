@@ -5,6 +5,9 @@
 package webhook
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -15,13 +18,35 @@ import (
 )
 
 type mockWebhookStore struct {
-	Webhooks []*model.Webhook
+	Webhooks   []*model.Webhook
+	Deliveries []*model.WebhookDelivery
+	Events     []*model.Event
 }
 
 func (s *mockWebhookStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error) {
 	return s.Webhooks, nil
 }
 
+func (s *mockWebhookStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	s.Deliveries = append(s.Deliveries, delivery)
+	return nil
+}
+
+func (s *mockWebhookStore) GetLatestResourceEvent(resourceID string) (*model.Event, error) {
+	for i := len(s.Events) - 1; i >= 0; i-- {
+		if s.Events[i].Payload.ID == resourceID {
+			return s.Events[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *mockWebhookStore) CreateEvent(event *model.Event) error {
+	s.Events = append(s.Events, event)
+	return nil
+}
+
 func TestGetAndSendWebhooks(t *testing.T) {
 	mockStore := &mockWebhookStore{}
 	logger := testlib.MakeLogger(t).WithFields(log.Fields{
@@ -31,6 +56,7 @@ func TestGetAndSendWebhooks(t *testing.T) {
 	t.Run("no webhooks", func(t *testing.T) {
 		err := SendToAllWebhooks(mockStore, nil, logger)
 		require.NoError(t, err)
+		require.Empty(t, mockStore.Deliveries)
 	})
 
 	mockStore.Webhooks = append(mockStore.Webhooks, &model.Webhook{
@@ -42,8 +68,10 @@ func TestGetAndSendWebhooks(t *testing.T) {
 	})
 
 	t.Run("1 webhook", func(t *testing.T) {
+		mockStore.Deliveries = nil
 		err := SendToAllWebhooks(mockStore, nil, logger)
 		require.NoError(t, err)
+		require.Len(t, mockStore.Deliveries, 1)
 	})
 
 	mockStore.Webhooks = append(mockStore.Webhooks, &model.Webhook{
@@ -55,12 +83,55 @@ func TestGetAndSendWebhooks(t *testing.T) {
 	})
 
 	t.Run("2 webhooks", func(t *testing.T) {
+		mockStore.Deliveries = nil
 		err := SendToAllWebhooks(mockStore, nil, logger)
 		require.NoError(t, err)
+		require.Len(t, mockStore.Deliveries, 2)
+		require.Equal(t, mockStore.Webhooks[0].ID, mockStore.Deliveries[0].WebhookID)
+		require.Equal(t, mockStore.Webhooks[1].ID, mockStore.Deliveries[1].WebhookID)
+	})
+
+	mockStore.Webhooks = append(mockStore.Webhooks, &model.Webhook{
+		ID:       model.NewID(),
+		OwnerID:  model.NewID(),
+		URL:      "https://test3.com",
+		CreateAt: 10,
+		DeleteAt: 0,
+		EventFilter: &model.WebhookEventFilter{
+			Types:     []string{model.TypeInstallation},
+			NewStates: []string{model.InstallationStateStable},
+		},
+	})
+
+	t.Run("3 webhooks, filtered event", func(t *testing.T) {
+		mockStore.Deliveries = nil
+		err := SendToAllWebhooks(mockStore, &model.Event{
+			Payload: &model.WebhookPayload{
+				Type:     model.TypeCluster,
+				NewState: model.ClusterStateStable,
+			},
+		}, logger)
+		require.NoError(t, err)
+		require.Len(t, mockStore.Deliveries, 2)
+	})
+
+	t.Run("3 webhooks, matching event", func(t *testing.T) {
+		mockStore.Deliveries = nil
+		mockStore.Events = nil
+		payload := &model.WebhookPayload{
+			Type:     model.TypeInstallation,
+			NewState: model.InstallationStateStable,
+		}
+		err := SendToAllWebhooks(mockStore, &model.Event{Payload: payload, InstanceID: "instance"}, logger)
+		require.NoError(t, err)
+		require.Len(t, mockStore.Events, 1)
+		require.Equal(t, payload, mockStore.Events[0].Payload)
+		require.Equal(t, "instance", mockStore.Events[0].InstanceID)
+		require.Len(t, mockStore.Deliveries, 3)
+		require.Equal(t, mockStore.Webhooks[2].ID, mockStore.Deliveries[2].WebhookID)
 	})
 }
 
-// TODO: add happy-path test.
 func TestSendWebhooks(t *testing.T) {
 	logger := testlib.MakeLogger(t).WithFields(log.Fields{
 		"webhooks-tests": true,
@@ -81,6 +152,56 @@ func TestSendWebhooks(t *testing.T) {
 		ExtraData: map[string]string{"ClusterID": model.NewID()},
 	}
 
-	err := sendWebhook(hook, payload, logger)
-	require.Contains(t, err.Error(), "unable to send webhook")
+	t.Run("unreachable host", func(t *testing.T) {
+		err := sendWebhook(hook, model.NewID(), payload, logger)
+		require.Contains(t, err.Error(), "unable to send webhook")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var received *model.WebhookPayload
+		var header http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			received, _ = model.WebhookPayloadFromReader(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		delivery := &model.WebhookDelivery{ID: model.NewID(), Payload: payload}
+		err := Deliver(&model.Webhook{URL: ts.URL}, delivery, logger)
+		require.NoError(t, err)
+		require.Equal(t, payload, received)
+		require.Equal(t, delivery.ID, header.Get(model.WebhookHeaderDeliveryID))
+		require.NotEmpty(t, header.Get(model.WebhookHeaderTimestamp))
+		require.Empty(t, header.Get(model.WebhookHeaderSignature))
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		var body []byte
+		var header http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		delivery := &model.WebhookDelivery{ID: model.NewID(), Payload: payload}
+		err := Deliver(&model.Webhook{URL: ts.URL, Secret: "secret"}, delivery, logger)
+		require.NoError(t, err)
+		require.NotEmpty(t, header.Get(model.WebhookHeaderSignature))
+		require.NoError(t, model.VerifyWebhookSignature("secret", header, body, time.Minute))
+		require.Error(t, model.VerifyWebhookSignature("other", header, body, time.Minute))
+	})
+
+	t.Run("non-2xx response", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		err := Deliver(&model.Webhook{URL: ts.URL}, &model.WebhookDelivery{Payload: payload}, logger)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status code 503")
+	})
 }
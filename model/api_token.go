@@ -0,0 +1,112 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// APITokenScopeReadOnly allows read access to the API.
+	APITokenScopeReadOnly = "read-only"
+	// APITokenScopeInstallationOperator allows read access to the API as well
+	// as managing installations, cluster installations and webhooks.
+	APITokenScopeInstallationOperator = "installation-operator"
+	// APITokenScopeClusterAdmin allows full access to the API.
+	APITokenScopeClusterAdmin = "cluster-admin"
+)
+
+// AllAPITokenScopes is a list of all API token scopes, ordered from the least
+// to the most privileged.
+// Warning:
+// When creating a new API token scope, it must be added to this list.
+var AllAPITokenScopes = []string{
+	APITokenScopeReadOnly,
+	APITokenScopeInstallationOperator,
+	APITokenScopeClusterAdmin,
+}
+
+// APIToken is a static token used to authenticate requests to the API.
+type APIToken struct {
+	ID      string
+	Name    string
+	OwnerID string
+	Scope   string
+	// TokenHash is the hash of the token secret. The secret itself is never
+	// stored.
+	TokenHash string `json:"-"`
+	// Token is the token secret. It is only returned once, when the token is
+	// created.
+	Token    string `json:",omitempty"`
+	CreateAt int64
+	DeleteAt int64
+}
+
+// APITokenFilter describes the parameters used to constrain a set of API
+// tokens.
+type APITokenFilter struct {
+	OwnerID        string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// IsDeleted returns true if the API token is marked as deleted.
+func (t *APIToken) IsDeleted() bool {
+	return t.DeleteAt != 0
+}
+
+// IsValidAPITokenScope returns true if the given scope is a valid API token
+// scope.
+func IsValidAPITokenScope(scope string) bool {
+	return apiTokenScopeRank(scope) >= 0
+}
+
+// APITokenScopeAllows returns true if the granted scope includes everything
+// allowed by the required scope.
+func APITokenScopeAllows(granted, required string) bool {
+	grantedRank := apiTokenScopeRank(granted)
+	if grantedRank < 0 {
+		return false
+	}
+
+	return grantedRank >= apiTokenScopeRank(required)
+}
+
+func apiTokenScopeRank(scope string) int {
+	for i, validScope := range AllAPITokenScopes {
+		if scope == validScope {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// APITokenFromReader decodes a json-encoded API token from the given io.Reader.
+func APITokenFromReader(reader io.Reader) (*APIToken, error) {
+	token := APIToken{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&token)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// APITokensFromReader decodes a json-encoded list of API tokens from the given io.Reader.
+func APITokensFromReader(reader io.Reader) ([]*APIToken, error) {
+	tokens := []*APIToken{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&tokens)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return tokens, nil
+}
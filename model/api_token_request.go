@@ -0,0 +1,81 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CreateAPITokenRequest specifies the parameters for a new API token.
+type CreateAPITokenRequest struct {
+	Name string
+	// OwnerID, if set, restricts the token to resources with the same owner.
+	OwnerID string
+	Scope   string
+}
+
+// SetDefaults sets the default values for an API token create request.
+func (request *CreateAPITokenRequest) SetDefaults() {
+	if request.Scope == "" {
+		request.Scope = APITokenScopeReadOnly
+	}
+}
+
+// Validate validates the values of an API token create request.
+func (request *CreateAPITokenRequest) Validate() error {
+	if request.Name == "" {
+		return errors.New("must specify name")
+	}
+	if !IsValidAPITokenScope(request.Scope) {
+		return errors.Errorf("unsupported scope %s", request.Scope)
+	}
+	if request.OwnerID != "" && request.Scope == APITokenScopeClusterAdmin {
+		return errors.Errorf("tokens restricted to an owner cannot have the %s scope", APITokenScopeClusterAdmin)
+	}
+
+	return nil
+}
+
+// NewCreateAPITokenRequestFromReader will create a CreateAPITokenRequest from an io.Reader with JSON data.
+func NewCreateAPITokenRequestFromReader(reader io.Reader) (*CreateAPITokenRequest, error) {
+	var createAPITokenRequest CreateAPITokenRequest
+	err := json.NewDecoder(reader).Decode(&createAPITokenRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create API token request")
+	}
+
+	createAPITokenRequest.SetDefaults()
+	err = createAPITokenRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "create API token request failed validation")
+	}
+
+	return &createAPITokenRequest, nil
+}
+
+// GetAPITokensRequest describes the parameters to request a list of API tokens.
+type GetAPITokensRequest struct {
+	OwnerID        string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetAPITokensRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("owner", request.OwnerID)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	if request.IncludeDeleted {
+		q.Add("include_deleted", "true")
+	}
+	u.RawQuery = q.Encode()
+}
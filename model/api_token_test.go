@@ -0,0 +1,57 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenScopeAllows(t *testing.T) {
+	testCases := []struct {
+		Granted  string
+		Required string
+		Expected bool
+	}{
+		{APITokenScopeReadOnly, APITokenScopeReadOnly, true},
+		{APITokenScopeReadOnly, APITokenScopeInstallationOperator, false},
+		{APITokenScopeInstallationOperator, APITokenScopeReadOnly, true},
+		{APITokenScopeInstallationOperator, APITokenScopeClusterAdmin, false},
+		{APITokenScopeClusterAdmin, APITokenScopeInstallationOperator, true},
+		{"", APITokenScopeReadOnly, false},
+		{"unknown", APITokenScopeReadOnly, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Granted+" "+tc.Required, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, APITokenScopeAllows(tc.Granted, tc.Required))
+		})
+	}
+}
+
+func TestCreateAPITokenRequestValidate(t *testing.T) {
+	testCases := []struct {
+		Description string
+		Request     *CreateAPITokenRequest
+		ExpectError bool
+	}{
+		{"valid", &CreateAPITokenRequest{Name: "token", Scope: APITokenScopeReadOnly}, false},
+		{"valid owner restricted", &CreateAPITokenRequest{Name: "token", OwnerID: "owner", Scope: APITokenScopeInstallationOperator}, false},
+		{"missing name", &CreateAPITokenRequest{Scope: APITokenScopeReadOnly}, true},
+		{"invalid scope", &CreateAPITokenRequest{Name: "token", Scope: "root"}, true},
+		{"owner restricted cluster admin", &CreateAPITokenRequest{Name: "token", OwnerID: "owner", Scope: APITokenScopeClusterAdmin}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			if tc.ExpectError {
+				assert.Error(t, tc.Request.Validate())
+			} else {
+				assert.NoError(t, tc.Request.Validate())
+			}
+		})
+	}
+}
@@ -129,6 +129,23 @@ func (c *Client) CreateCluster(request *CreateClusterRequest) (*Cluster, error)
 	}
 }
 
+// ImportCluster imports a kubernetes cluster built outside of the configured provisioning server.
+func (c *Client) ImportCluster(request *ImportClusterRequest) (*Cluster, error) {
+	resp, err := c.doPost(c.buildURL("/api/clusters/import"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // RetryCreateCluster retries the creation of a cluster from the configured provisioning server.
 func (c *Client) RetryCreateCluster(clusterID string) error {
 	resp, err := c.doPost(c.buildURL("/api/cluster/%s", clusterID), nil)
@@ -276,6 +293,44 @@ func (c *Client) ResizeCluster(clusterID string, request *PatchClusterSizeReques
 	}
 }
 
+// DrainCluster stops scheduling installations on a cluster and moves all of
+// its installations to other clusters.
+func (c *Client) DrainCluster(clusterID string, request *DrainClusterRequest) (*ClusterDrainStatus, error) {
+	resp, err := c.doPost(c.buildURL("/api/cluster/%s/drain", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDrainStatusFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetClusterDrainStatus fetches the progress of draining the given cluster.
+func (c *Client) GetClusterDrainStatus(clusterID string) (*ClusterDrainStatus, error) {
+	resp, err := c.doGet(c.buildURL("/api/cluster/%s/drain", clusterID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterDrainStatusFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteCluster deletes the given cluster and all resources contained therein.
 func (c *Client) DeleteCluster(clusterID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/cluster/%s", clusterID))
@@ -310,6 +365,24 @@ func (c *Client) CreateInstallation(request *CreateInstallationRequest) (*Instal
 	}
 }
 
+// ScheduleInstallation reports which cluster the requested installation would
+// be placed on by the configured provisioning server, without creating it.
+func (c *Client) ScheduleInstallation(request *CreateInstallationRequest) (*InstallationSchedulingResult, error) {
+	resp, err := c.doPost(c.buildURL("/api/installations/schedule"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationSchedulingResultFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // RetryCreateInstallation retries the creation of a installation from the configured provisioning server.
 func (c *Client) RetryCreateInstallation(installationID string) error {
 	resp, err := c.doPost(c.buildURL("/api/installation/%s", installationID), nil)
@@ -481,6 +554,119 @@ func (c *Client) WakeupInstallation(installationID string) (*Installation, error
 	}
 }
 
+// MigrateInstallation requests that the given installation be moved to another cluster.
+func (c *Client) MigrateInstallation(installationID string, request *MigrateInstallationRequest) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/migrate", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// MigrateInstallationDatabase requests that the database of the given
+// installation be moved to another multitenant database.
+func (c *Client) MigrateInstallationDatabase(installationID string, request *MigrateInstallationDatabaseRequest) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/database/migrate", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// CreateInstallationBackup requests a backup of the given installation.
+func (c *Client) CreateInstallationBackup(installationID string) (*InstallationBackup, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/backup", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationBackupFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationBackup fetches the installation backup from the configured provisioning server.
+func (c *Client) GetInstallationBackup(backupID string) (*InstallationBackup, error) {
+	resp, err := c.doGet(c.buildURL("/api/installation_backup/%s", backupID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationBackupFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationBackups fetches the list of installation backups from the configured provisioning server.
+func (c *Client) GetInstallationBackups(request *GetInstallationBackupsRequest) ([]*InstallationBackup, error) {
+	u, err := url.Parse(c.buildURL("/api/installation_backups"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationBackupsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// RestoreInstallationBackup requests the installation backup to be restored.
+func (c *Client) RestoreInstallationBackup(backupID string, request *RestoreInstallationBackupRequest) (*InstallationBackup, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation_backup/%s/restore", backupID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationBackupFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteInstallation deletes the given installation and all resources contained therein.
 func (c *Client) DeleteInstallation(installationID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/installation/%s", installationID))
@@ -779,6 +965,89 @@ func (c *Client) GetMultitenantDatabases(request *GetDatabasesRequest) ([]*Multi
 	}
 }
 
+// GetMultitenantDatabaseRebalancePlan fetches the installation database moves
+// that would even out the load between the matching multitenant databases.
+// Paging parameters of the request are ignored.
+func (c *Client) GetMultitenantDatabaseRebalancePlan(request *GetDatabasesRequest) ([]*MultitenantDatabaseMove, error) {
+	u, err := url.Parse(c.buildURL("/api/databases/rebalance"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return MultitenantDatabaseMovesFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetMultitenantDatabase fetches the multitenant database from the configured
+// provisioning server.
+func (c *Client) GetMultitenantDatabase(databaseID string) (*MultitenantDatabase, error) {
+	resp, err := c.doGet(c.buildURL("/api/database/%s", databaseID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return MultitenantDatabaseFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// UpdateMultitenantDatabase updates the installation settings of a
+// multitenant database.
+func (c *Client) UpdateMultitenantDatabase(databaseID string, request *UpdateMultitenantDatabaseRequest) (*MultitenantDatabase, error) {
+	resp, err := c.doPut(c.buildURL("/api/database/%s", databaseID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return MultitenantDatabaseFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// UnlockMultitenantDatabase forcefully releases the lock held on a
+// multitenant database.
+func (c *Client) UnlockMultitenantDatabase(databaseID string) error {
+	resp, err := c.doPost(c.buildURL("/api/database/%s/unlock", databaseID), nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // CreateWebhook requests the creation of a webhook from the configured provisioning server.
 func (c *Client) CreateWebhook(request *CreateWebhookRequest) (*Webhook, error) {
 	resp, err := c.doPost(c.buildURL("/api/webhooks"), request)
@@ -840,6 +1109,23 @@ func (c *Client) GetWebhooks(request *GetWebhooksRequest) ([]*Webhook, error) {
 	}
 }
 
+// UpdateWebhook updates the event filter of the given webhook.
+func (c *Client) UpdateWebhook(webhookID string, request *UpdateWebhookRequest) (*Webhook, error) {
+	resp, err := c.doPut(c.buildURL("/api/webhook/%s", webhookID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteWebhook deletes the given webhook.
 func (c *Client) DeleteWebhook(webhookID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/webhook/%s", webhookID))
@@ -857,6 +1143,157 @@ func (c *Client) DeleteWebhook(webhookID string) error {
 	}
 }
 
+// GetWebhookDelivery fetches the webhook delivery from the configured provisioning server.
+func (c *Client) GetWebhookDelivery(deliveryID string) (*WebhookDelivery, error) {
+	resp, err := c.doGet(c.buildURL("/api/webhook_delivery/%s", deliveryID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookDeliveryFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetWebhookDeliveries fetches the list of webhook deliveries from the configured provisioning server.
+func (c *Client) GetWebhookDeliveries(request *GetWebhookDeliveriesRequest) ([]*WebhookDelivery, error) {
+	u, err := url.Parse(c.buildURL("/api/webhook_deliveries"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookDeliveriesFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// RedeliverWebhookDelivery queues a failed webhook delivery for delivery again.
+func (c *Client) RedeliverWebhookDelivery(deliveryID string) (*WebhookDelivery, error) {
+	resp, err := c.doPost(c.buildURL("/api/webhook_delivery/%s/redeliver", deliveryID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return WebhookDeliveryFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// StreamEvents connects to the event stream of the configured provisioning
+// server and calls handle for every received event. It returns when the
+// server closes the stream or when handle returns an error.
+//
+// The LastEventID of the request is updated while streaming, so calling
+// StreamEvents again with the same request resumes the stream without missing
+// any events.
+func (c *Client) StreamEvents(request *StreamEventsRequest, handle func(event *Event) error) error {
+	u, err := url.Parse(c.buildURL("/api/events/stream"))
+	if err != nil {
+		return err
+	}
+
+	request.ApplyToURL(u)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create http request")
+	}
+	for k, v := range c.headers {
+		req.Header.Add(k, v)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if request.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", request.LastEventID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	// The body is closed without draining it, as the stream may still be
+	// open if handle returned an error.
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return readEventStream(resp.Body, &request.LastEventID, handle)
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationEvents fetches the state change history of the given
+// installation.
+func (c *Client) GetInstallationEvents(installationID string, request *GetEventsRequest) ([]*Event, error) {
+	return c.getEvents(c.buildURL("/api/installation/%s/events", installationID), request)
+}
+
+// GetClusterEvents fetches the state change history of the given cluster.
+func (c *Client) GetClusterEvents(clusterID string, request *GetEventsRequest) ([]*Event, error) {
+	return c.getEvents(c.buildURL("/api/cluster/%s/events", clusterID), request)
+}
+
+// GetClusterInstallationEvents fetches the state change history of the given
+// cluster installation.
+func (c *Client) GetClusterInstallationEvents(clusterInstallationID string, request *GetEventsRequest) ([]*Event, error) {
+	return c.getEvents(c.buildURL("/api/cluster_installation/%s/events", clusterInstallationID), request)
+}
+
+// GetGroupEvents fetches the state change history of the installations
+// belonging to the given group.
+func (c *Client) GetGroupEvents(groupID string, request *GetEventsRequest) ([]*Event, error) {
+	return c.getEvents(c.buildURL("/api/group/%s/events", groupID), request)
+}
+
+func (c *Client) getEvents(eventsURL string, request *GetEventsRequest) ([]*Event, error) {
+	u, err := url.Parse(eventsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return EventsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // LockAPIForCluster locks API changes for a given cluster.
 func (c *Client) LockAPIForCluster(clusterID string) error {
 	return c.makeSecurityCall("cluster", clusterID, "api", "lock")
@@ -897,6 +1334,18 @@ func (c *Client) UnlockAPIForGroup(groupID string) error {
 	return c.makeSecurityCall("group", groupID, "api", "unlock")
 }
 
+// LockAPIForMultitenantDatabase locks API changes for a given multitenant
+// database.
+func (c *Client) LockAPIForMultitenantDatabase(databaseID string) error {
+	return c.makeSecurityCall("database", databaseID, "api", "lock")
+}
+
+// UnlockAPIForMultitenantDatabase unlocks API changes for a given multitenant
+// database.
+func (c *Client) UnlockAPIForMultitenantDatabase(databaseID string) error {
+	return c.makeSecurityCall("database", databaseID, "api", "unlock")
+}
+
 func (c *Client) makeSecurityCall(resourceType, id, securityType, action string) error {
 	resp, err := c.doPost(c.buildURL("/api/security/%s/%s/%s/%s", resourceType, id, securityType, action), nil)
 	if err != nil {
@@ -913,3 +1362,82 @@ func (c *Client) makeSecurityCall(resourceType, id, securityType, action string)
 	}
 
 }
+
+// CreateAPIToken requests the creation of an API token from the configured
+// provisioning server. The token secret is only returned by this call.
+func (c *Client) CreateAPIToken(request *CreateAPITokenRequest) (*APIToken, error) {
+	resp, err := c.doPost(c.buildURL("/api/tokens"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return APITokenFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetAPIToken fetches the API token from the configured provisioning server.
+func (c *Client) GetAPIToken(tokenID string) (*APIToken, error) {
+	resp, err := c.doGet(c.buildURL("/api/token/%s", tokenID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return APITokenFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetAPITokens fetches the list of API tokens from the configured provisioning server.
+func (c *Client) GetAPITokens(request *GetAPITokensRequest) ([]*APIToken, error) {
+	u, err := url.Parse(c.buildURL("/api/tokens"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return APITokensFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// DeleteAPIToken revokes the given API token.
+func (c *Client) DeleteAPIToken(tokenID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/token/%s", tokenID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"encoding/json"
 	"io"
 	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 // Cluster represents a Kubernetes cluster.
@@ -18,13 +21,39 @@ type Cluster struct {
 	ProviderMetadataAWS     *AWSMetadata
 	Provisioner             string
 	ProvisionerMetadataKops *KopsMetadata
+	ProvisionerMetadataEKS  *EKSMetadata `json:"ProvisionerMetadataEKS,omitempty"`
 	UtilityMetadata         *UtilityMetadata
 	AllowInstallations      bool
+	Labels                  map[string]string `json:"Labels,omitempty"`
+	Draining                bool
+	DrainMaxRolling         int64
 	CreateAt                int64
 	DeleteAt                int64
 	APISecurityLock         bool
 	LockAcquiredBy          *string
 	LockAcquiredAt          int64
+
+	// ResourceThreshold and ResourceThresholdScaleValue override the server
+	// defaults used when placing installations on the cluster.
+	ResourceThreshold           *int   `json:"ResourceThreshold,omitempty"`
+	ResourceThresholdScaleValue *int   `json:"ResourceThresholdScaleValue,omitempty"`
+	ScaleDownPolicy             string `json:"ScaleDownPolicy,omitempty"`
+
+	// LowUtilizationSince is the time since which the utilization of the
+	// cluster has stayed below the scale down floor, and CapacityCheckAt the
+	// time the utilization was last checked.
+	LowUtilizationSince int64
+	CapacityCheckAt     int64
+
+	// ProvisionerMetadataExternal and EncryptedKubeconfig are only set on
+	// imported clusters. The kubeconfig is encrypted at rest and never
+	// serialized to JSON.
+	ProvisionerMetadataExternal *ExternalMetadata `json:"ProvisionerMetadataExternal,omitempty"`
+	EncryptedKubeconfig         []byte            `json:"-"`
+
+	// ProvisionerMetadataLocal is only set on clusters running on the
+	// machine of the provisioner.
+	ProvisionerMetadataLocal *LocalMetadata `json:"ProvisionerMetadataLocal,omitempty"`
 }
 
 // Clone returns a deep copy the cluster.
@@ -32,10 +61,29 @@ func (c *Cluster) Clone() *Cluster {
 	var clone Cluster
 	data, _ := json.Marshal(c)
 	json.Unmarshal(data, &clone)
+	clone.EncryptedKubeconfig = c.EncryptedKubeconfig
 
 	return &clone
 }
 
+// VPC returns the ID of the AWS VPC the cluster runs in, or an empty string if
+// it is unknown.
+func (c *Cluster) VPC() string {
+	if c.ProvisionerMetadataEKS != nil {
+		return c.ProvisionerMetadataEKS.VPC
+	}
+	if c.ProvisionerMetadataKops != nil {
+		return c.ProvisionerMetadataKops.VPC
+	}
+
+	return ""
+}
+
+// InSameVPC returns true if both clusters are known to run in the same VPC.
+func (c *Cluster) InSameVPC(other *Cluster) bool {
+	return c.VPC() != "" && c.VPC() == other.VPC()
+}
+
 // ClusterFromReader decodes a json-encoded cluster from the given io.Reader.
 func ClusterFromReader(reader io.Reader) (*Cluster, error) {
 	cluster := Cluster{}
@@ -68,6 +116,59 @@ type ClusterFilter struct {
 	IncludeDeleted bool
 }
 
+const (
+	// ClusterScaleDownPolicyNone never scales the cluster worker nodes down.
+	ClusterScaleDownPolicyNone = "none"
+	// ClusterScaleDownPolicyUtilization scales the cluster worker nodes down
+	// when resource utilization stays low.
+	ClusterScaleDownPolicyUtilization = "utilization"
+)
+
+// IsSupportedClusterScaleDownPolicy returns true if the given scale down
+// policy is supported.
+func IsSupportedClusterScaleDownPolicy(policy string) bool {
+	return policy == ClusterScaleDownPolicyNone || policy == ClusterScaleDownPolicyUtilization
+}
+
+// ValidateClusterResourceThresholds validates the resource threshold
+// percentage and scale value of a cluster. Nil values are not validated.
+func ValidateClusterResourceThresholds(threshold, scaleValue *int) error {
+	if threshold != nil && (*threshold < 10 || *threshold > 100) {
+		return errors.Errorf("resource threshold (%d) must be set between 10 and 100", *threshold)
+	}
+	if scaleValue != nil && (*scaleValue < 0 || *scaleValue > 10) {
+		return errors.Errorf("resource threshold scale value (%d) must be set between 0 and 10", *scaleValue)
+	}
+
+	return nil
+}
+
+var spotMaxPriceMatcher = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ValidateSpotInstances validates the spot max price and mixed instance types
+// of worker nodes. An empty max price means on-demand nodes, which don't take
+// mixed instance types.
+func ValidateSpotInstances(maxPrice string, mixedInstanceTypes []string) error {
+	if len(maxPrice) == 0 {
+		if len(mixedInstanceTypes) != 0 {
+			return errors.New("mixed instance types require a spot max price")
+		}
+		return nil
+	}
+
+	price, err := strconv.ParseFloat(maxPrice, 64)
+	if !spotMaxPriceMatcher.MatchString(maxPrice) || err != nil || price <= 0 {
+		return errors.Errorf("spot max price (%s) must be a positive hourly price", maxPrice)
+	}
+	for _, instanceType := range mixedInstanceTypes {
+		if len(instanceType) == 0 {
+			return errors.New("mixed instance types cannot contain a blank value")
+		}
+	}
+
+	return nil
+}
+
 var clusterVersionMatcher = regexp.MustCompile(`^(([0-9]{1,3}.[0-9]{1,3}.[0-9]{1,3})|(latest))$`)
 
 // ValidClusterVersion returns true if the provided version is either "latest"
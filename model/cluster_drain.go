@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ClusterDrainStatus reports the progress of moving all installations off a
+// cluster.
+type ClusterDrainStatus struct {
+	ClusterID  string
+	Draining   bool
+	MaxRolling int64
+	// InstallationsRemaining is the number of installations that still have a
+	// cluster installation on the cluster.
+	InstallationsRemaining int64
+	InstallationsMigrating int64
+	// InstallationsUpdating is the number of installations being updated or
+	// woken up, which are moved off the cluster once stable.
+	InstallationsUpdating int64
+	// InstallationsHibernating is the number of hibernating installations
+	// waiting to be woken up so that they can be moved off the cluster.
+	InstallationsHibernating int64
+	InstallationsFailed      int64
+	// InstallationsNotMigratable is the number of installations that keep
+	// their data inside the cluster and have to be moved or deleted by hand.
+	InstallationsNotMigratable int64
+	// BlockedInstallationIDs are the installations that are not migratable or
+	// whose migration failed, which keep the drain from finishing until they
+	// are dealt with by hand.
+	BlockedInstallationIDs []string
+}
+
+// ClusterDrainStatusFromReader decodes a json-encoded cluster drain status from
+// the given io.Reader.
+func ClusterDrainStatusFromReader(reader io.Reader) (*ClusterDrainStatus, error) {
+	status := ClusterDrainStatus{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&status)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &status, nil
+}
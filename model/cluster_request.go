@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"regexp"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -27,6 +28,24 @@ type CreateClusterRequest struct {
 	AllowInstallations     bool              `json:"allow-installations,omitempty"`
 	APISecurityLock        bool              `json:"api-security-lock,omitempty"`
 	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+
+	// ResourceThreshold and ResourceThresholdScaleValue override the server
+	// defaults when set.
+	ResourceThreshold           *int   `json:"resource-threshold,omitempty"`
+	ResourceThresholdScaleValue *int   `json:"resource-threshold-scale-value,omitempty"`
+	ScaleDownPolicy             string `json:"scale-down-policy,omitempty"`
+
+	// NodeSpotMaxPrice runs the worker nodes on spot instances bid at up to
+	// the given hourly price. NodeMixedInstanceTypes are the instance types
+	// the spot nodes may run on.
+	NodeSpotMaxPrice       string   `json:"node-spot-max-price,omitempty"`
+	NodeMixedInstanceTypes []string `json:"node-mixed-instance-types,omitempty"`
+
+	// Provisioner is the provisioner creating the cluster, either kops, eks
+	// or local. The kops specific parameters are ignored by eks, and local
+	// only honors the version.
+	Provisioner string `json:"provisioner,omitempty"`
 }
 
 // SetDefaults sets the default values for a cluster create request.
@@ -34,6 +53,9 @@ func (request *CreateClusterRequest) SetDefaults() {
 	if len(request.Provider) == 0 {
 		request.Provider = ProviderAWS
 	}
+	if len(request.Provisioner) == 0 {
+		request.Provisioner = ProvisionerKops
+	}
 	if len(request.Version) == 0 {
 		request.Version = "latest"
 	}
@@ -55,21 +77,32 @@ func (request *CreateClusterRequest) SetDefaults() {
 	if request.NodeMaxCount == 0 {
 		request.NodeMaxCount = request.NodeMinCount
 	}
-	if request.DesiredUtilityVersions == nil {
-		request.DesiredUtilityVersions = make(map[string]string)
+	if len(request.ScaleDownPolicy) == 0 {
+		request.ScaleDownPolicy = ClusterScaleDownPolicyNone
 	}
-	if _, ok := request.DesiredUtilityVersions[PrometheusCanonicalName]; !ok {
-		request.DesiredUtilityVersions[PrometheusCanonicalName] = PrometheusDefaultVersion
+	request.DesiredUtilityVersions = setDefaultUtilityVersions(request.DesiredUtilityVersions)
+}
+
+// setDefaultUtilityVersions fills in the default version of the utilities
+// missing from the given desired versions.
+func setDefaultUtilityVersions(desiredVersions map[string]string) map[string]string {
+	if desiredVersions == nil {
+		desiredVersions = make(map[string]string)
 	}
-	if _, ok := request.DesiredUtilityVersions[NginxCanonicalName]; !ok {
-		request.DesiredUtilityVersions[NginxCanonicalName] = NginxDefaultVersion
+	if _, ok := desiredVersions[PrometheusCanonicalName]; !ok {
+		desiredVersions[PrometheusCanonicalName] = PrometheusDefaultVersion
 	}
-	if _, ok := request.DesiredUtilityVersions[FluentbitCanonicalName]; !ok {
-		request.DesiredUtilityVersions[FluentbitCanonicalName] = FluentbitDefaultVersion
+	if _, ok := desiredVersions[NginxCanonicalName]; !ok {
+		desiredVersions[NginxCanonicalName] = NginxDefaultVersion
 	}
-	if _, ok := request.DesiredUtilityVersions[TeleportCanonicalName]; !ok {
-		request.DesiredUtilityVersions[TeleportCanonicalName] = TeleportDefaultVersion
+	if _, ok := desiredVersions[FluentbitCanonicalName]; !ok {
+		desiredVersions[FluentbitCanonicalName] = FluentbitDefaultVersion
 	}
+	if _, ok := desiredVersions[TeleportCanonicalName]; !ok {
+		desiredVersions[TeleportCanonicalName] = TeleportDefaultVersion
+	}
+
+	return desiredVersions
 }
 
 // Validate validates the values of a cluster create request.
@@ -77,6 +110,11 @@ func (request *CreateClusterRequest) Validate() error {
 	if request.Provider != ProviderAWS {
 		return errors.Errorf("unsupported provider %s", request.Provider)
 	}
+	switch request.Provisioner {
+	case ProvisionerKops, ProvisionerEKS, ProvisionerLocal:
+	default:
+		return errors.Errorf("unsupported provisioner %s", request.Provisioner)
+	}
 	if !ValidClusterVersion(request.Version) {
 		return errors.Errorf("unsupported cluster version %s", request.Version)
 	}
@@ -89,6 +127,24 @@ func (request *CreateClusterRequest) Validate() error {
 	if request.NodeMaxCount != request.NodeMinCount {
 		return errors.Errorf("node min (%d) and max (%d) counts must match", request.NodeMinCount, request.NodeMaxCount)
 	}
+	err := ValidateLabels(request.Labels)
+	if err != nil {
+		return errors.Wrap(err, "invalid cluster labels")
+	}
+	err = ValidateClusterResourceThresholds(request.ResourceThreshold, request.ResourceThresholdScaleValue)
+	if err != nil {
+		return err
+	}
+	if !IsSupportedClusterScaleDownPolicy(request.ScaleDownPolicy) {
+		return errors.Errorf("unsupported scale down policy %s", request.ScaleDownPolicy)
+	}
+	err = ValidateSpotInstances(request.NodeSpotMaxPrice, request.NodeMixedInstanceTypes)
+	if err != nil {
+		return err
+	}
+	if request.Provisioner != ProvisionerKops && len(request.NodeSpotMaxPrice) != 0 {
+		return errors.Errorf("spot instances are not supported by the %s provisioner", request.Provisioner)
+	}
 	// TODO: check zones and instance types?
 
 	return nil
@@ -112,6 +168,63 @@ func NewCreateClusterRequestFromReader(reader io.Reader) (*CreateClusterRequest,
 	return &createClusterRequest, nil
 }
 
+// ImportClusterRequest specifies the parameters for importing a kubernetes
+// cluster built outside of the provisioner.
+type ImportClusterRequest struct {
+	// Kubeconfig is the content of the kubeconfig used to access the cluster.
+	Kubeconfig             string            `json:"kubeconfig,omitempty"`
+	Name                   string            `json:"name,omitempty"`
+	AllowInstallations     bool              `json:"allow-installations,omitempty"`
+	APISecurityLock        bool              `json:"api-security-lock,omitempty"`
+	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+	ResourceThreshold      *int              `json:"resource-threshold,omitempty"`
+}
+
+// SetDefaults sets the default values for a cluster import request.
+func (request *ImportClusterRequest) SetDefaults() {
+	request.DesiredUtilityVersions = setDefaultUtilityVersions(request.DesiredUtilityVersions)
+}
+
+// Validate validates the values of a cluster import request.
+func (request *ImportClusterRequest) Validate() error {
+	if len(request.Kubeconfig) == 0 {
+		return errors.New("kubeconfig must not be empty")
+	}
+	err := ValidateKubeconfig([]byte(request.Kubeconfig))
+	if err != nil {
+		return errors.Wrap(err, "invalid kubeconfig")
+	}
+	err = ValidateLabels(request.Labels)
+	if err != nil {
+		return errors.Wrap(err, "invalid cluster labels")
+	}
+	err = ValidateClusterResourceThresholds(request.ResourceThreshold, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewImportClusterRequestFromReader will create an ImportClusterRequest from
+// an io.Reader with JSON data.
+func NewImportClusterRequestFromReader(reader io.Reader) (*ImportClusterRequest, error) {
+	var importClusterRequest ImportClusterRequest
+	err := json.NewDecoder(reader).Decode(&importClusterRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode import cluster request")
+	}
+
+	importClusterRequest.SetDefaults()
+	err = importClusterRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "import cluster request failed validation")
+	}
+
+	return &importClusterRequest, nil
+}
+
 // GetClustersRequest describes the parameters to request a list of clusters.
 type GetClustersRequest struct {
 	Page           int
@@ -133,6 +246,32 @@ func (request *GetClustersRequest) ApplyToURL(u *url.URL) {
 // UpdateClusterRequest specifies the parameters available for updating a cluster.
 type UpdateClusterRequest struct {
 	AllowInstallations bool
+	// Labels replace the labels of the cluster when set. An empty map removes
+	// all labels.
+	Labels map[string]string
+
+	// ResourceThreshold, ResourceThresholdScaleValue and ScaleDownPolicy
+	// are left unchanged when nil.
+	ResourceThreshold           *int    `json:"ResourceThreshold,omitempty"`
+	ResourceThresholdScaleValue *int    `json:"ResourceThresholdScaleValue,omitempty"`
+	ScaleDownPolicy             *string `json:"ScaleDownPolicy,omitempty"`
+}
+
+// Validate validates the values of a cluster update request.
+func (request *UpdateClusterRequest) Validate() error {
+	err := ValidateLabels(request.Labels)
+	if err != nil {
+		return errors.Wrap(err, "invalid cluster labels")
+	}
+	err = ValidateClusterResourceThresholds(request.ResourceThreshold, request.ResourceThresholdScaleValue)
+	if err != nil {
+		return err
+	}
+	if request.ScaleDownPolicy != nil && !IsSupportedClusterScaleDownPolicy(*request.ScaleDownPolicy) {
+		return errors.Errorf("unsupported scale down policy %s", *request.ScaleDownPolicy)
+	}
+
+	return nil
 }
 
 // NewUpdateClusterRequestFromReader will create an UpdateClusterRequest from an io.Reader with JSON data.
@@ -142,9 +281,54 @@ func NewUpdateClusterRequestFromReader(reader io.Reader) (*UpdateClusterRequest,
 	if err != nil && err != io.EOF {
 		return nil, errors.Wrap(err, "failed to decode update cluster request")
 	}
+
+	err = updateClusterRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "update cluster request failed validation")
+	}
+
 	return &updateClusterRequest, nil
 }
 
+// DrainClusterRequest specifies the parameters for moving all installations
+// off a cluster.
+type DrainClusterRequest struct {
+	MaxRolling int64
+}
+
+// SetDefaults sets the default values for a drain cluster request.
+func (request *DrainClusterRequest) SetDefaults() {
+	if request.MaxRolling == 0 {
+		request.MaxRolling = 1
+	}
+}
+
+// Validate validates the values of a drain cluster request.
+func (request *DrainClusterRequest) Validate() error {
+	if request.MaxRolling < 1 {
+		return errors.New("max rolling must be 1 or greater")
+	}
+
+	return nil
+}
+
+// NewDrainClusterRequestFromReader will create a DrainClusterRequest from an io.Reader with JSON data.
+func NewDrainClusterRequestFromReader(reader io.Reader) (*DrainClusterRequest, error) {
+	var drainClusterRequest DrainClusterRequest
+	err := json.NewDecoder(reader).Decode(&drainClusterRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode drain cluster request")
+	}
+
+	drainClusterRequest.SetDefaults()
+	err = drainClusterRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid drain cluster request")
+	}
+
+	return &drainClusterRequest, nil
+}
+
 // PatchUpgradeClusterRequest specifies the parameters for upgrading a cluster.
 type PatchUpgradeClusterRequest struct {
 	Version *string `json:"version,omitempty"`
@@ -202,10 +386,44 @@ type PatchClusterSizeRequest struct {
 	NodeInstanceType *string `json:"node-instance-type,omitempty"`
 	NodeMinCount     *int64  `json:"node-min-count,omitempty"`
 	NodeMaxCount     *int64  `json:"node-max-count,omitempty"`
+
+	// InstanceGroup is the worker node pool to resize, or to create if the
+	// cluster has no such pool. The default pool is resized when omitted.
+	// NodeLabels are set on the nodes of pools being created, and Delete
+	// removes the pool instead.
+	InstanceGroup *string           `json:"instance-group,omitempty"`
+	NodeLabels    map[string]string `json:"node-labels,omitempty"`
+	Delete        bool              `json:"delete,omitempty"`
+
+	// NodeSpotMaxPrice switches the nodes to spot instances bid at up to the
+	// given hourly price, or back to on-demand instances when empty.
+	// NodeMixedInstanceTypes are the instance types the spot nodes may run on.
+	NodeSpotMaxPrice       *string  `json:"node-spot-max-price,omitempty"`
+	NodeMixedInstanceTypes []string `json:"node-mixed-instance-types,omitempty"`
 }
 
+var instanceGroupNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
 // Validate validates the values of a PatchClusterSizeRequest.
 func (p *PatchClusterSizeRequest) Validate() error {
+	if p.InstanceGroup != nil && !instanceGroupNameRegex.MatchString(*p.InstanceGroup) {
+		return errors.Errorf("invalid instance group name %q", *p.InstanceGroup)
+	}
+	if p.Delete {
+		if p.InstanceGroup == nil || *p.InstanceGroup == KopsInstanceGroupNodes {
+			return errors.New("only instance groups other than the default one can be deleted")
+		}
+		if p.NodeInstanceType != nil || p.NodeMinCount != nil || p.NodeMaxCount != nil || p.NodeLabels != nil || p.NodeSpotMaxPrice != nil {
+			return errors.New("node values cannot be set when deleting an instance group")
+		}
+	}
+	err := ValidateLabels(p.NodeLabels)
+	if err != nil {
+		return errors.Wrap(err, "invalid node labels")
+	}
+	if _, ok := p.NodeLabels[KopsInstanceGroupLabel]; ok {
+		return errors.Errorf("node label %s is set by kops", KopsInstanceGroupLabel)
+	}
 	if p.NodeInstanceType != nil && len(*p.NodeInstanceType) == 0 {
 		return errors.New("node instance type cannot be a blank value")
 	}
@@ -216,12 +434,66 @@ func (p *PatchClusterSizeRequest) Validate() error {
 		*p.NodeMaxCount < *p.NodeMinCount {
 		return errors.Errorf("node max count (%d) can't be less than min count (%d)", *p.NodeMaxCount, *p.NodeMinCount)
 	}
+	if p.NodeSpotMaxPrice != nil {
+		err = ValidateSpotInstances(*p.NodeSpotMaxPrice, p.NodeMixedInstanceTypes)
+		if err != nil {
+			return err
+		}
+	} else if p.NodeMixedInstanceTypes != nil {
+		return errors.New("mixed instance types require a spot max price")
+	}
+
+	return nil
+}
+
+// IsDefaultInstanceGroup returns true if the patch applies to the default
+// worker node pool.
+func (p *PatchClusterSizeRequest) IsDefaultInstanceGroup() bool {
+	return p.InstanceGroup == nil || *p.InstanceGroup == KopsInstanceGroupNodes
+}
+
+// ValidateForMetadata validates the patch against the worker node pools of the
+// given cluster's kops metadata.
+func (p *PatchClusterSizeRequest) ValidateForMetadata(metadata *KopsMetadata) error {
+	if p.IsDefaultInstanceGroup() {
+		if p.NodeLabels != nil {
+			return errors.New("node labels can only be set on instance groups being created")
+		}
+		if p.NodeMinCount == nil && p.NodeMaxCount != nil && *p.NodeMaxCount < metadata.NodeMinCount {
+			return errors.New("resize patch would set max node count lower than min node count")
+		}
+
+		return nil
+	}
+
+	ig := metadata.GetInstanceGroup(*p.InstanceGroup)
+	if ig == nil {
+		if p.Delete {
+			return errors.Errorf("instance group %s not found", *p.InstanceGroup)
+		}
+		if p.NodeInstanceType == nil || p.NodeMinCount == nil {
+			return errors.New("node instance type and min count are required to create an instance group")
+		}
+
+		return nil
+	}
+
+	if p.NodeLabels != nil {
+		return errors.New("node labels can only be set on instance groups being created")
+	}
+	if p.NodeMinCount == nil && p.NodeMaxCount != nil && *p.NodeMaxCount < ig.MinCount {
+		return errors.New("resize patch would set max node count lower than min node count")
+	}
 
 	return nil
 }
 
 // Apply applies the patch to the given cluster's kops metadata.
 func (p *PatchClusterSizeRequest) Apply(metadata *KopsMetadata) bool {
+	if !p.IsDefaultInstanceGroup() {
+		return p.applyToInstanceGroup(metadata)
+	}
+
 	changes := &KopsMetadataRequestedState{}
 
 	var applied bool
@@ -237,6 +509,75 @@ func (p *PatchClusterSizeRequest) Apply(metadata *KopsMetadata) bool {
 		applied = true
 		changes.NodeMaxCount = *p.NodeMaxCount
 	}
+	if p.spotChanged(metadata.NodeSpotMaxPrice, metadata.NodeMixedInstanceTypes) {
+		applied = true
+		changes.NodeSpotMaxPrice = p.NodeSpotMaxPrice
+		changes.NodeMixedInstanceTypes = p.NodeMixedInstanceTypes
+	}
+
+	if applied {
+		metadata.ChangeRequest = changes
+	}
+
+	return applied
+}
+
+func (p *PatchClusterSizeRequest) applyToInstanceGroup(metadata *KopsMetadata) bool {
+	ig := metadata.GetInstanceGroup(*p.InstanceGroup)
+	if ig == nil {
+		if p.Delete || p.NodeInstanceType == nil || p.NodeMinCount == nil {
+			return false
+		}
+
+		// New instance groups have as many max as min nodes unless told
+		// otherwise.
+		changes := &KopsMetadataRequestedState{
+			InstanceGroup:    *p.InstanceGroup,
+			NodeInstanceType: *p.NodeInstanceType,
+			NodeMinCount:     *p.NodeMinCount,
+			NodeMaxCount:     *p.NodeMinCount,
+			NodeLabels:       p.NodeLabels,
+
+			NodeSpotMaxPrice:       p.NodeSpotMaxPrice,
+			NodeMixedInstanceTypes: p.NodeMixedInstanceTypes,
+		}
+		if p.NodeMaxCount != nil {
+			changes.NodeMaxCount = *p.NodeMaxCount
+		}
+		metadata.ChangeRequest = changes
+
+		return true
+	}
+
+	if p.Delete {
+		metadata.ChangeRequest = &KopsMetadataRequestedState{
+			InstanceGroup:       ig.Name,
+			DeleteInstanceGroup: true,
+		}
+
+		return true
+	}
+
+	changes := &KopsMetadataRequestedState{InstanceGroup: ig.Name}
+
+	var applied bool
+	if p.NodeInstanceType != nil && *p.NodeInstanceType != ig.InstanceType {
+		applied = true
+		changes.NodeInstanceType = *p.NodeInstanceType
+	}
+	if p.NodeMinCount != nil && *p.NodeMinCount != ig.MinCount {
+		applied = true
+		changes.NodeMinCount = *p.NodeMinCount
+	}
+	if p.NodeMaxCount != nil && *p.NodeMaxCount != ig.MaxCount {
+		applied = true
+		changes.NodeMaxCount = *p.NodeMaxCount
+	}
+	if p.spotChanged(ig.SpotMaxPrice, ig.MixedInstanceTypes) {
+		applied = true
+		changes.NodeSpotMaxPrice = p.NodeSpotMaxPrice
+		changes.NodeMixedInstanceTypes = p.NodeMixedInstanceTypes
+	}
 
 	if applied {
 		metadata.ChangeRequest = changes
@@ -245,6 +586,23 @@ func (p *PatchClusterSizeRequest) Apply(metadata *KopsMetadata) bool {
 	return applied
 }
 
+// spotChanged returns true if the patch changes the given spot settings.
+func (p *PatchClusterSizeRequest) spotChanged(maxPrice string, mixedInstanceTypes []string) bool {
+	if p.NodeSpotMaxPrice == nil {
+		return false
+	}
+	if *p.NodeSpotMaxPrice != maxPrice || len(p.NodeMixedInstanceTypes) != len(mixedInstanceTypes) {
+		return true
+	}
+	for i, instanceType := range p.NodeMixedInstanceTypes {
+		if instanceType != mixedInstanceTypes[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
 // NewResizeClusterRequestFromReader will create an PatchClusterSizeRequest from an io.Reader with JSON data.
 func NewResizeClusterRequestFromReader(reader io.Reader) (*PatchClusterSizeRequest, error) {
 	var patchClusterSizeRequest PatchClusterSizeRequest
@@ -5,10 +5,13 @@
 package model_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateClusterRequestValid(t *testing.T) {
@@ -23,6 +26,24 @@ func TestCreateClusterRequestValid(t *testing.T) {
 		{"negative node counts", &model.CreateClusterRequest{NodeMinCount: -1, NodeMaxCount: -1}, true},
 		{"negative master count", &model.CreateClusterRequest{MasterCount: -1}, true},
 		{"mismatched node count", &model.CreateClusterRequest{NodeMinCount: 2, NodeMaxCount: 3}, true},
+		{"labels", &model.CreateClusterRequest{Labels: map[string]string{"zone": "us-east-1b", "mattermost.com/tier": "enterprise"}}, false},
+		{"invalid label key", &model.CreateClusterRequest{Labels: map[string]string{"-zone": "us-east-1b"}}, true},
+		{"invalid label value", &model.CreateClusterRequest{Labels: map[string]string{"zone": "us east"}}, true},
+		{"resource thresholds", &model.CreateClusterRequest{ResourceThreshold: iToP(95), ResourceThresholdScaleValue: iToP(0)}, false},
+		{"resource threshold too low", &model.CreateClusterRequest{ResourceThreshold: iToP(5)}, true},
+		{"resource threshold scale value too high", &model.CreateClusterRequest{ResourceThresholdScaleValue: iToP(11)}, true},
+		{"scale down policy", &model.CreateClusterRequest{ScaleDownPolicy: model.ClusterScaleDownPolicyUtilization}, false},
+		{"invalid scale down policy", &model.CreateClusterRequest{ScaleDownPolicy: "sometimes"}, true},
+		{"spot instances", &model.CreateClusterRequest{NodeSpotMaxPrice: "0.05", NodeMixedInstanceTypes: []string{"m5.large", "m5a.large"}}, false},
+		{"invalid spot max price", &model.CreateClusterRequest{NodeSpotMaxPrice: "cheap"}, true},
+		{"zero spot max price", &model.CreateClusterRequest{NodeSpotMaxPrice: "0"}, true},
+		{"mixed instance types without spot max price", &model.CreateClusterRequest{NodeMixedInstanceTypes: []string{"m5.large"}}, true},
+		{"eks provisioner", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS}, false},
+		{"invalid provisioner", &model.CreateClusterRequest{Provisioner: "gke"}, true},
+		{"external provisioner", &model.CreateClusterRequest{Provisioner: model.ProvisionerExternal}, true},
+		{"eks provisioner with spot instances", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, NodeSpotMaxPrice: "0.05"}, true},
+		{"local provisioner", &model.CreateClusterRequest{Provisioner: model.ProvisionerLocal}, false},
+		{"local provisioner with spot instances", &model.CreateClusterRequest{Provisioner: model.ProvisionerLocal, NodeSpotMaxPrice: "0.05"}, true},
 	}
 
 	for _, tc := range testCases {
@@ -38,6 +59,57 @@ func TestCreateClusterRequestValid(t *testing.T) {
 	}
 }
 
+func TestImportClusterRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.ImportClusterRequest
+		requireError bool
+	}{
+		{"kubeconfig", &model.ImportClusterRequest{Kubeconfig: testKubeconfig}, false},
+		{"missing kubeconfig", &model.ImportClusterRequest{}, true},
+		{"invalid kubeconfig", &model.ImportClusterRequest{Kubeconfig: "apiVersion: v1"}, true},
+		{"labels", &model.ImportClusterRequest{Kubeconfig: testKubeconfig, Labels: map[string]string{"zone": "us-east-1b"}}, false},
+		{"invalid label value", &model.ImportClusterRequest{Kubeconfig: testKubeconfig, Labels: map[string]string{"zone": "us east"}}, true},
+		{"resource threshold", &model.ImportClusterRequest{Kubeconfig: testKubeconfig, ResourceThreshold: iToP(70)}, false},
+		{"resource threshold too low", &model.ImportClusterRequest{Kubeconfig: testKubeconfig, ResourceThreshold: iToP(5)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.request.SetDefaults()
+
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestNewImportClusterRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewImportClusterRequestFromReader(bytes.NewReader([]byte("")))
+		require.Error(t, err)
+		require.Nil(t, request)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		body, err := json.Marshal(map[string]interface{}{
+			"kubeconfig":          testKubeconfig,
+			"name":                "platform",
+			"allow-installations": true,
+		})
+		require.NoError(t, err)
+		request, err := model.NewImportClusterRequestFromReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		require.Equal(t, testKubeconfig, request.Kubeconfig)
+		require.Equal(t, "platform", request.Name)
+		require.True(t, request.AllowInstallations)
+		require.Equal(t, model.PrometheusDefaultVersion, request.DesiredUtilityVersions[model.PrometheusCanonicalName])
+	})
+}
+
 func TestUpgradeClusterRequestValid(t *testing.T) {
 	var testCases = []struct {
 		testName     string
@@ -151,6 +223,205 @@ func TestResizeClusterRequestValid(t *testing.T) {
 		{"blank node type", &model.PatchClusterSizeRequest{NodeInstanceType: sToP("")}, true},
 		{"zero nodes", &model.PatchClusterSizeRequest{NodeMinCount: i64oP(0), NodeMaxCount: i64oP(0)}, true},
 		{"max lower than min", &model.PatchClusterSizeRequest{NodeMinCount: i64oP(5), NodeMaxCount: i64oP(2)}, true},
+		{"instance group", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeMinCount: i64oP(2), NodeLabels: map[string]string{"pool": "memory"}}, false},
+		{"invalid instance group name", &model.PatchClusterSizeRequest{InstanceGroup: sToP("Memory_Pool")}, true},
+		{"invalid node label", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeLabels: map[string]string{"pool": "memory pool"}}, true},
+		{"kops node label", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeLabels: map[string]string{model.KopsInstanceGroupLabel: "memory"}}, true},
+		{"delete instance group", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), Delete: true}, false},
+		{"delete default instance group", &model.PatchClusterSizeRequest{Delete: true}, true},
+		{"delete instance group with node values", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeMinCount: i64oP(2), Delete: true}, true},
+		{"spot instances", &model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("0.05"), NodeMixedInstanceTypes: []string{"m5.large", "m5a.large"}}, false},
+		{"on-demand instances", &model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("")}, false},
+		{"invalid spot max price", &model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("-1")}, true},
+		{"blank mixed instance type", &model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("0.05"), NodeMixedInstanceTypes: []string{""}}, true},
+		{"mixed instance types without spot max price", &model.PatchClusterSizeRequest{NodeMixedInstanceTypes: []string{"m5.large"}}, true},
+		{"delete instance group with spot values", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeSpotMaxPrice: sToP("0.05"), Delete: true}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestResizeClusterRequestApply(t *testing.T) {
+	newMetadata := func() *model.KopsMetadata {
+		return &model.KopsMetadata{
+			NodeInstanceType: "m5.large",
+			NodeMinCount:     2,
+			NodeMaxCount:     2,
+			InstanceGroups: []*model.KopsInstanceGroup{
+				{Name: model.KopsInstanceGroupNodes, InstanceType: "m5.large", MinCount: 2, MaxCount: 2},
+				{Name: "memory", InstanceType: "r5.xlarge", MinCount: 1, MaxCount: 3},
+				{Name: "batch", InstanceType: "c5.large", MinCount: 1, MaxCount: 1, SpotMaxPrice: "0.1", MixedInstanceTypes: []string{"c5.large"}},
+			},
+		}
+	}
+
+	var testCases = []struct {
+		testName              string
+		request               *model.PatchClusterSizeRequest
+		expectApply           bool
+		expectedChangeRequest *model.KopsMetadataRequestedState
+	}{
+		{
+			"default instance group",
+			&model.PatchClusterSizeRequest{NodeMinCount: i64oP(3), NodeMaxCount: i64oP(3)},
+			true,
+			&model.KopsMetadataRequestedState{NodeMinCount: 3, NodeMaxCount: 3},
+		},
+		{
+			"default instance group, no change",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP(model.KopsInstanceGroupNodes), NodeMinCount: i64oP(2)},
+			false,
+			nil,
+		},
+		{
+			"instance group",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeMinCount: i64oP(2), NodeMaxCount: i64oP(3)},
+			true,
+			&model.KopsMetadataRequestedState{InstanceGroup: "memory", NodeMinCount: 2},
+		},
+		{
+			"instance group, no change",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeInstanceType: sToP("r5.xlarge")},
+			false,
+			nil,
+		},
+		{
+			"new instance group",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("spot"), NodeInstanceType: sToP("m5.large"), NodeMinCount: i64oP(1), NodeLabels: map[string]string{"pool": "spot"}},
+			true,
+			&model.KopsMetadataRequestedState{InstanceGroup: "spot", NodeInstanceType: "m5.large", NodeMinCount: 1, NodeMaxCount: 1, NodeLabels: map[string]string{"pool": "spot"}},
+		},
+		{
+			"delete instance group",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), Delete: true},
+			true,
+			&model.KopsMetadataRequestedState{InstanceGroup: "memory", DeleteInstanceGroup: true},
+		},
+		{
+			"default instance group, spot",
+			&model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("0.05"), NodeMixedInstanceTypes: []string{"m5.large", "m5a.large"}},
+			true,
+			&model.KopsMetadataRequestedState{NodeSpotMaxPrice: sToP("0.05"), NodeMixedInstanceTypes: []string{"m5.large", "m5a.large"}},
+		},
+		{
+			"default instance group, already on-demand",
+			&model.PatchClusterSizeRequest{NodeSpotMaxPrice: sToP("")},
+			false,
+			nil,
+		},
+		{
+			"instance group, back to on-demand",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("batch"), NodeSpotMaxPrice: sToP("")},
+			true,
+			&model.KopsMetadataRequestedState{InstanceGroup: "batch", NodeSpotMaxPrice: sToP("")},
+		},
+		{
+			"instance group, same spot settings",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("batch"), NodeSpotMaxPrice: sToP("0.1"), NodeMixedInstanceTypes: []string{"c5.large"}},
+			false,
+			nil,
+		},
+		{
+			"instance group, new mixed instance types",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("batch"), NodeSpotMaxPrice: sToP("0.1"), NodeMixedInstanceTypes: []string{"c5.large", "c5a.large"}},
+			true,
+			&model.KopsMetadataRequestedState{InstanceGroup: "batch", NodeSpotMaxPrice: sToP("0.1"), NodeMixedInstanceTypes: []string{"c5.large", "c5a.large"}},
+		},
+		{
+			"delete unknown instance group",
+			&model.PatchClusterSizeRequest{InstanceGroup: sToP("spot"), Delete: true},
+			false,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			metadata := newMetadata()
+			assert.Equal(t, tc.expectApply, tc.request.Apply(metadata))
+			assert.Equal(t, tc.expectedChangeRequest, metadata.ChangeRequest)
+		})
+	}
+}
+
+func TestResizeClusterRequestValidateForMetadata(t *testing.T) {
+	metadata := &model.KopsMetadata{
+		NodeMinCount: 2,
+		NodeMaxCount: 2,
+		InstanceGroups: []*model.KopsInstanceGroup{
+			{Name: model.KopsInstanceGroupNodes, MinCount: 2, MaxCount: 2},
+			{Name: "memory", MinCount: 3, MaxCount: 3},
+		},
+	}
+
+	var testCases = []struct {
+		testName     string
+		request      *model.PatchClusterSizeRequest
+		requireError bool
+	}{
+		{"default instance group", &model.PatchClusterSizeRequest{NodeMaxCount: i64oP(2)}, false},
+		{"default instance group, max lower than min", &model.PatchClusterSizeRequest{NodeMaxCount: i64oP(1)}, true},
+		{"default instance group, node labels", &model.PatchClusterSizeRequest{NodeLabels: map[string]string{"pool": "default"}}, true},
+		{"instance group, max lower than min", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeMaxCount: i64oP(2)}, true},
+		{"instance group, node labels", &model.PatchClusterSizeRequest{InstanceGroup: sToP("memory"), NodeLabels: map[string]string{"pool": "memory"}}, true},
+		{"new instance group", &model.PatchClusterSizeRequest{InstanceGroup: sToP("spot"), NodeInstanceType: sToP("m5.large"), NodeMinCount: i64oP(1)}, false},
+		{"new instance group without min count", &model.PatchClusterSizeRequest{InstanceGroup: sToP("spot"), NodeInstanceType: sToP("m5.large")}, true},
+		{"delete unknown instance group", &model.PatchClusterSizeRequest{InstanceGroup: sToP("spot"), Delete: true}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.ValidateForMetadata(metadata))
+			} else {
+				assert.NoError(t, tc.request.ValidateForMetadata(metadata))
+			}
+		})
+	}
+}
+
+func TestNewDrainClusterRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewDrainClusterRequestFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &model.DrainClusterRequest{MaxRolling: 1}, request)
+	})
+
+	t.Run("invalid max rolling", func(t *testing.T) {
+		request, err := model.NewDrainClusterRequestFromReader(bytes.NewReader([]byte(`{"MaxRolling": -1}`)))
+		require.Error(t, err)
+		require.Nil(t, request)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		request, err := model.NewDrainClusterRequestFromReader(bytes.NewReader([]byte(`{"MaxRolling": 5}`)))
+		require.NoError(t, err)
+		require.Equal(t, &model.DrainClusterRequest{MaxRolling: 5}, request)
+	})
+}
+
+func TestUpdateClusterRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.UpdateClusterRequest
+		requireError bool
+	}{
+		{"empty", &model.UpdateClusterRequest{}, false},
+		{"labels", &model.UpdateClusterRequest{Labels: map[string]string{"tier": "enterprise"}}, false},
+		{"invalid labels", &model.UpdateClusterRequest{Labels: map[string]string{"tier": "big enterprise"}}, true},
+		{"resource thresholds", &model.UpdateClusterRequest{ResourceThreshold: iToP(70), ResourceThresholdScaleValue: iToP(2)}, false},
+		{"resource threshold too high", &model.UpdateClusterRequest{ResourceThreshold: iToP(101)}, true},
+		{"negative resource threshold scale value", &model.UpdateClusterRequest{ResourceThresholdScaleValue: iToP(-1)}, true},
+		{"scale down policy", &model.UpdateClusterRequest{ScaleDownPolicy: sToP(model.ClusterScaleDownPolicyNone)}, false},
+		{"invalid scale down policy", &model.UpdateClusterRequest{ScaleDownPolicy: sToP("")}, true},
 	}
 
 	for _, tc := range testCases {
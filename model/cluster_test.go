@@ -28,6 +28,17 @@ func TestClusterClone(t *testing.T) {
 	clone.ProviderMetadataAWS = &AWSMetadata{Zones: []string{"zone2"}}
 	clone.ProvisionerMetadataKops = &KopsMetadata{Version: "version2"}
 	require.NotEqual(t, cluster, clone)
+
+	t.Run("external cluster", func(t *testing.T) {
+		cluster := &Cluster{
+			Provisioner:                 "external",
+			ProvisionerMetadataExternal: &ExternalMetadata{Name: "name"},
+			EncryptedKubeconfig:         []byte("encrypted"),
+		}
+
+		clone := cluster.Clone()
+		require.Equal(t, cluster, clone)
+	})
 }
 
 func TestClusterFromReader(t *testing.T) {
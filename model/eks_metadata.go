@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	// EKSNodeGroupNodes is the name of the default managed node group created
+	// with every EKS cluster.
+	EKSNodeGroupNodes = "nodes"
+)
+
+// EKSMetadata is the provisioner metadata stored in a model.Cluster created
+// with EKS.
+type EKSMetadata struct {
+	Name           string
+	Version        string
+	ClusterRoleARN string
+	NodeRoleARN    string
+	VPC            string
+	// Endpoint and CertificateAuthority are used to build the kubeconfig
+	// of the cluster; the certificate authority is base64 encoded.
+	Endpoint             string
+	CertificateAuthority string
+	NodeGroups           []*EKSNodeGroup            `json:"NodeGroups,omitempty"`
+	ChangeRequest        *EKSMetadataRequestedState `json:"ChangeRequest,omitempty"`
+	Warnings             []string                   `json:"Warnings,omitempty"`
+}
+
+// EKSNodeGroup is a managed node group of an EKS cluster.
+type EKSNodeGroup struct {
+	Name         string
+	InstanceType string
+	MinCount     int64
+	MaxCount     int64
+}
+
+// EKSMetadataRequestedState is the requested state for EKS metadata.
+type EKSMetadataRequestedState struct {
+	Version    string          `json:"Version,omitempty"`
+	NodeGroups []*EKSNodeGroup `json:"NodeGroups,omitempty"`
+}
+
+// GetNodeGroup returns the node group with the given name, or nil if the
+// cluster has none.
+func (em *EKSMetadata) GetNodeGroup(name string) *EKSNodeGroup {
+	for _, nodeGroup := range em.NodeGroups {
+		if nodeGroup.Name == name {
+			return nodeGroup
+		}
+	}
+
+	return nil
+}
+
+// ClearChangeRequest clears the EKS metadata change request.
+func (em *EKSMetadata) ClearChangeRequest() {
+	em.ChangeRequest = nil
+}
+
+// ClearWarnings clears the EKS metadata warnings.
+func (em *EKSMetadata) ClearWarnings() {
+	em.Warnings = []string{}
+}
+
+// AddWarning adds a warning the EKS metadata warning list.
+func (em *EKSMetadata) AddWarning(warning string) {
+	em.Warnings = append(em.Warnings, warning)
+}
+
+// NewEKSMetadata creates an instance of EKSMetadata given the raw provisioner metadata.
+func NewEKSMetadata(metadataBytes []byte) (*EKSMetadata, error) {
+	if len(metadataBytes) == 0 || string(metadataBytes) == "null" {
+		return nil, nil
+	}
+
+	eksMetadata := EKSMetadata{}
+	err := json.Unmarshal(metadataBytes, &eksMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eksMetadata, nil
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEKSMetadata(t *testing.T) {
+	t.Run("nil payload", func(t *testing.T) {
+		eksMetadata, err := model.NewEKSMetadata(nil)
+		require.NoError(t, err)
+		require.Nil(t, eksMetadata)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := model.NewEKSMetadata([]byte(`{`))
+		require.Error(t, err)
+	})
+
+	t.Run("valid payload", func(t *testing.T) {
+		eksMetadata, err := model.NewEKSMetadata([]byte(`{"Name": "name", "NodeGroups": [{"Name": "nodes", "MinCount": 2}]}`))
+		require.NoError(t, err)
+		require.Equal(t, "name", eksMetadata.Name)
+		require.Equal(t, int64(2), eksMetadata.GetNodeGroup(model.EKSNodeGroupNodes).MinCount)
+		require.Nil(t, eksMetadata.GetNodeGroup("memory"))
+	})
+}
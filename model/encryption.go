@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// EncryptionKeySize is the size in bytes of the keys encrypting secrets, such
+// as the kubeconfig of imported clusters, before they are stored.
+const EncryptionKeySize = 32
+
+// NewEncryptionKey decodes the given base64-encoded encryption key, returning
+// an error if it doesn't have the expected size.
+func NewEncryptionKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode encryption key")
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes long, but is %d bytes", EncryptionKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// EncryptSecret encrypts the given secret with AES-256-GCM. The random nonce
+// is prepended to the returned ciphertext. The additional data, such as the ID
+// of the resource owning the secret, is authenticated but not encrypted, and
+// must be given again to decrypt the secret, which prevents the ciphertext
+// from being used with another resource.
+func EncryptSecret(key, secret, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	return gcm.Seal(nonce, nonce, secret, additionalData), nil
+}
+
+// DecryptSecret decrypts a secret previously encrypted by EncryptSecret with
+// the same key and additional data.
+func DecryptSecret(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	secret, err := gcm.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt secret")
+	}
+
+	return secret, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes long, but is %d bytes", EncryptionKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEncryptionKey(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		encodedKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, EncryptionKeySize))
+
+		key, err := NewEncryptionKey(encodedKey)
+		require.NoError(t, err)
+		require.Len(t, key, EncryptionKeySize)
+	})
+
+	t.Run("not base64", func(t *testing.T) {
+		_, err := NewEncryptionKey("not base64!")
+		require.Error(t, err)
+	})
+
+	t.Run("wrong size", func(t *testing.T) {
+		_, err := NewEncryptionKey(base64.StdEncoding.EncodeToString([]byte("short")))
+		require.Error(t, err)
+	})
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, EncryptionKeySize)
+	secret := []byte("apiVersion: v1")
+	additionalData := []byte("clusterID")
+
+	ciphertext, err := EncryptSecret(key, secret, additionalData)
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), string(secret))
+
+	t.Run("decrypt", func(t *testing.T) {
+		decrypted, err := DecryptSecret(key, ciphertext, additionalData)
+		require.NoError(t, err)
+		require.Equal(t, secret, decrypted)
+	})
+
+	t.Run("random nonce", func(t *testing.T) {
+		otherCiphertext, err := EncryptSecret(key, secret, additionalData)
+		require.NoError(t, err)
+		require.NotEqual(t, ciphertext, otherCiphertext)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := DecryptSecret(bytes.Repeat([]byte{2}, EncryptionKeySize), ciphertext, additionalData)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong additional data", func(t *testing.T) {
+		_, err := DecryptSecret(key, ciphertext, []byte("otherClusterID"))
+		require.Error(t, err)
+
+		_, err = DecryptSecret(key, ciphertext, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated ciphertext", func(t *testing.T) {
+		_, err := DecryptSecret(key, ciphertext[:4], additionalData)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := EncryptSecret([]byte("short"), secret, additionalData)
+		require.Error(t, err)
+	})
+}
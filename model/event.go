@@ -0,0 +1,145 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Event is a state change of a resource managed by the provisioning server.
+// Events are recorded in a persistent event log in the order in which they
+// occurred.
+type Event struct {
+	// Sequence is a monotonically increasing number identifying the position
+	// of the event in the event log.
+	Sequence int64
+	Payload  *WebhookPayload
+	// InstanceID is the ID of the provisioning server instance that caused
+	// the event.
+	InstanceID string
+	// RequestID is the ID of the API request that caused the event, if any.
+	RequestID string
+	CreateAt  int64
+}
+
+// EventFilter describes the parameters used to constrain a set of events.
+type EventFilter struct {
+	// AfterSequence only includes events that were recorded after the event
+	// with the given sequence number.
+	AfterSequence int64
+	// MaxSequence only includes events with a sequence number lower than or
+	// equal to the given one when it is not 0.
+	MaxSequence int64
+	ResourceID  string
+	Type        string
+	OwnerID     string
+	GroupID     string
+	Page        int
+	PerPage     int
+}
+
+// GetEventsRequest describes the parameters to request the event history of a
+// resource.
+type GetEventsRequest struct {
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetEventsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// StreamEventsRequest describes the parameters to request a stream of events.
+type StreamEventsRequest struct {
+	Type    string
+	OwnerID string
+	GroupID string
+	// LastEventID, if set, resumes the stream after the event with the given
+	// ID. Otherwise, only events that occur after connecting are streamed.
+	LastEventID string
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *StreamEventsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("type", request.Type)
+	q.Add("owner", request.OwnerID)
+	q.Add("group", request.GroupID)
+	u.RawQuery = q.Encode()
+}
+
+// EventsFromReader decodes a json-encoded list of events from the given io.Reader.
+func EventsFromReader(reader io.Reader) ([]*Event, error) {
+	events := []*Event{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&events)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// readEventStream reads server-sent events from the given io.Reader, calling
+// handle for every complete event until the reader is exhausted or handle
+// returns an error. The ID of the last received event is kept in lastEventID.
+func readEventStream(reader io.Reader, lastEventID *string, handle func(event *Event) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var id, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			// A blank line dispatches the event. Events without data only
+			// update the last event ID.
+			if id != "" {
+				*lastEventID = id
+			}
+			if data == "" {
+				id = ""
+				continue
+			}
+
+			event := &Event{}
+			err := json.Unmarshal([]byte(data), &event.Payload)
+			if err != nil {
+				return errors.Wrap(err, "failed to decode event payload")
+			}
+			event.Sequence, err = strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse event ID %s", id)
+			}
+
+			err = handle(event)
+			if err != nil {
+				return err
+			}
+			id, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// Comments are used as keep-alives.
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+
+	return scanner.Err()
+}
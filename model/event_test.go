@@ -0,0 +1,81 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEventStream(t *testing.T) {
+	collect := func(stream string, lastEventID *string) ([]*Event, error) {
+		var events []*Event
+		err := readEventStream(strings.NewReader(stream), lastEventID, func(event *Event) error {
+			events = append(events, event)
+			return nil
+		})
+
+		return events, err
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		var lastEventID string
+		events, err := collect("", &lastEventID)
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Empty(t, lastEventID)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		stream := "id: 3\n\n" +
+			": keep-alive\n\n" +
+			"id: 4\ndata: {\"ID\":\"id1\",\"Type\":\"cluster\",\"NewState\":\"stable\"}\n\n" +
+			"id: 7\ndata: {\"ID\":\"id2\",\"Type\":\"installation\",\"NewState\":\"deleted\"}\n\n"
+
+		var lastEventID string
+		events, err := collect(stream, &lastEventID)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, int64(4), events[0].Sequence)
+		require.Equal(t, "id1", events[0].Payload.ID)
+		require.Equal(t, TypeCluster, events[0].Payload.Type)
+		require.Equal(t, int64(7), events[1].Sequence)
+		require.Equal(t, "id2", events[1].Payload.ID)
+		require.Equal(t, TypeInstallation, events[1].Payload.Type)
+		require.Equal(t, "7", lastEventID)
+	})
+
+	t.Run("id only", func(t *testing.T) {
+		var lastEventID string
+		events, err := collect("id: 12\n\n", &lastEventID)
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Equal(t, "12", lastEventID)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		var lastEventID string
+		_, err := collect("id: 1\ndata: {invalid\n\n", &lastEventID)
+		require.Error(t, err)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		handlerErr := errors.New("stop")
+		stream := "id: 1\ndata: {\"ID\":\"id1\"}\n\nid: 2\ndata: {\"ID\":\"id2\"}\n\n"
+
+		var lastEventID string
+		var calls int
+		err := readEventStream(strings.NewReader(stream), &lastEventID, func(event *Event) error {
+			calls++
+			return handlerErr
+		})
+		require.Equal(t, handlerErr, err)
+		require.Equal(t, 1, calls)
+		require.Equal(t, "1", lastEventID)
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var externalFilestoreEndpointRegex = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9.]*[A-Za-z0-9])?(:[0-9]{1,5})?$`)
+var externalFilestoreBucketRegex = regexp.MustCompile(`^[a-z0-9][-a-z0-9.]{1,61}[a-z0-9]$`)
+
+// ExternalFilestoreConfig is the S3-compatible bucket of a filestore managed
+// outside of the provisioner, such as an on-premise MinIO, Wasabi or Ceph RGW.
+type ExternalFilestoreConfig struct {
+	// Endpoint is the host, and optionally the port, of the S3 API, such as
+	// s3.wasabisys.com or minio.example.com:9000.
+	Endpoint string
+	Bucket   string
+	// Prefix is the directory of the bucket holding the installation files.
+	Prefix          string `json:"Prefix,omitempty"`
+	AccessKeyID     string
+	SecretAccessKey string `json:"SecretAccessKey,omitempty"`
+	// Insecure connects to the endpoint over plain HTTP.
+	Insecure bool `json:"Insecure,omitempty"`
+	// DeleteDataOnTeardown allows the files under the prefix to be deleted
+	// along with the installation when the server doesn't keep filestore data.
+	DeleteDataOnTeardown bool `json:"DeleteDataOnTeardown,omitempty"`
+}
+
+// Validate validates the external filestore configuration.
+func (c *ExternalFilestoreConfig) Validate() error {
+	if !externalFilestoreEndpointRegex.MatchString(c.Endpoint) {
+		return errors.Errorf("invalid endpoint %q; must be a host with an optional port", c.Endpoint)
+	}
+	if !externalFilestoreBucketRegex.MatchString(c.Bucket) {
+		return errors.Errorf("invalid bucket name %q", c.Bucket)
+	}
+	if strings.HasPrefix(c.Prefix, "/") || strings.HasSuffix(c.Prefix, "/") || strings.ContainsAny(c.Prefix, " ") {
+		return errors.Errorf("invalid prefix %q; must not contain spaces or start or end with a slash", c.Prefix)
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return errors.New("must specify an access key ID and a secret access key")
+	}
+	if c.DeleteDataOnTeardown && c.Prefix == "" {
+		return errors.New("must specify a prefix to delete data on teardown")
+	}
+
+	return nil
+}
+
+// ExternalFilestoreConfigFromJSON creates an ExternalFilestoreConfig from the
+// JSON representation stored in the database.
+func ExternalFilestoreConfigFromJSON(raw []byte) (*ExternalFilestoreConfig, error) {
+	config := &ExternalFilestoreConfig{}
+	err := json.Unmarshal(raw, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal external filestore configuration")
+	}
+
+	return config, nil
+}
+
+// ToJSON returns the JSON representation of the configuration for storage in
+// the database. Nil configurations are stored as NULL.
+func (c *ExternalFilestoreConfig) ToJSON() ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(c)
+}
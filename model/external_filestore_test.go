@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalFilestoreConfigValidate(t *testing.T) {
+	validConfig := func() *model.ExternalFilestoreConfig {
+		return &model.ExternalFilestoreConfig{
+			Endpoint:        "minio.example.com:9000",
+			Bucket:          "mattermost-files",
+			Prefix:          "customers/customer1",
+			AccessKeyID:     "access",
+			SecretAccessKey: "secret",
+		}
+	}
+
+	var testCases = []struct {
+		testName     string
+		requireError bool
+		modify       func(config *model.ExternalFilestoreConfig)
+	}{
+		{"valid", false, func(config *model.ExternalFilestoreConfig) {}},
+		{"endpoint without port", false, func(config *model.ExternalFilestoreConfig) { config.Endpoint = "s3.wasabisys.com" }},
+		{"no prefix", false, func(config *model.ExternalFilestoreConfig) { config.Prefix = "" }},
+		{"delete data with prefix", false, func(config *model.ExternalFilestoreConfig) { config.DeleteDataOnTeardown = true }},
+		{"no endpoint", true, func(config *model.ExternalFilestoreConfig) { config.Endpoint = "" }},
+		{"endpoint with scheme", true, func(config *model.ExternalFilestoreConfig) { config.Endpoint = "https://minio.example.com" }},
+		{"endpoint with path", true, func(config *model.ExternalFilestoreConfig) { config.Endpoint = "minio.example.com/bucket" }},
+		{"no bucket", true, func(config *model.ExternalFilestoreConfig) { config.Bucket = "" }},
+		{"invalid bucket", true, func(config *model.ExternalFilestoreConfig) { config.Bucket = "Mattermost_Files" }},
+		{"prefix with leading slash", true, func(config *model.ExternalFilestoreConfig) { config.Prefix = "/customer1" }},
+		{"prefix with trailing slash", true, func(config *model.ExternalFilestoreConfig) { config.Prefix = "customer1/" }},
+		{"no access key ID", true, func(config *model.ExternalFilestoreConfig) { config.AccessKeyID = "" }},
+		{"no secret access key", true, func(config *model.ExternalFilestoreConfig) { config.SecretAccessKey = "" }},
+		{"delete data without prefix", true, func(config *model.ExternalFilestoreConfig) {
+			config.Prefix = ""
+			config.DeleteDataOnTeardown = true
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			config := validConfig()
+			tc.modify(config)
+
+			if tc.requireError {
+				assert.Error(t, config.Validate())
+			} else {
+				assert.NoError(t, config.Validate())
+			}
+		})
+	}
+}
+
+func TestExternalFilestoreConfigJSON(t *testing.T) {
+	var nilConfig *model.ExternalFilestoreConfig
+	data, err := nilConfig.ToJSON()
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	config := &model.ExternalFilestoreConfig{
+		Endpoint:    "minio.example.com:9000",
+		Bucket:      "mattermost-files",
+		Prefix:      "customer1",
+		AccessKeyID: "access",
+		Insecure:    true,
+	}
+	data, err = config.ToJSON()
+	require.NoError(t, err)
+
+	decoded, err := model.ExternalFilestoreConfigFromJSON(data)
+	require.NoError(t, err)
+	require.Equal(t, config, decoded)
+
+	_, err = model.ExternalFilestoreConfigFromJSON([]byte("{test"))
+	require.Error(t, err)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// ExternalMetadata is the provisioner metadata stored in a model.Cluster
+// imported from outside of the provisioner.
+type ExternalMetadata struct {
+	Name string
+	// Version is the kubernetes version last reported by the cluster.
+	Version string
+}
+
+// NewExternalMetadata creates an instance of ExternalMetadata given the raw provisioner metadata.
+func NewExternalMetadata(metadataBytes []byte) (*ExternalMetadata, error) {
+	if len(metadataBytes) == 0 || string(metadataBytes) == "null" {
+		return nil, nil
+	}
+
+	externalMetadata := ExternalMetadata{}
+	err := json.Unmarshal(metadataBytes, &externalMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalMetadata, nil
+}
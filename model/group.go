@@ -24,6 +24,16 @@ type Group struct {
 	APISecurityLock bool
 	LockAcquiredBy  *string
 	LockAcquiredAt  int64
+
+	HibernationSchedule *HibernationSchedule `json:"HibernationSchedule,omitempty"`
+
+	// IdleHibernationOptOut exempts the installations of the group from
+	// being hibernated when idle.
+	IdleHibernationOptOut bool
+	// IdleHibernationGracePeriodHours overrides the time after an
+	// installation of the group becomes stable during which it is not
+	// hibernated for being idle.
+	IdleHibernationGracePeriodHours *int64 `json:"IdleHibernationGracePeriodHours,omitempty"`
 }
 
 // GroupFilter describes the parameters used to constrain a set of groups.
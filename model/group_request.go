@@ -22,6 +22,11 @@ type CreateGroupRequest struct {
 	MaxRolling      int64
 	APISecurityLock bool
 	MattermostEnv   EnvVarMap
+
+	HibernationSchedule *HibernationSchedule `json:"HibernationSchedule,omitempty"`
+
+	IdleHibernationOptOut           bool
+	IdleHibernationGracePeriodHours *int64 `json:"IdleHibernationGracePeriodHours,omitempty"`
 }
 
 // SetDefaults sets the default values for a group create request.
@@ -43,6 +48,15 @@ func (request *CreateGroupRequest) Validate() error {
 	if err != nil {
 		return errors.Wrapf(err, "bad environment variable map in create group request")
 	}
+	if request.HibernationSchedule != nil {
+		err = request.HibernationSchedule.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid hibernation schedule")
+		}
+	}
+	if request.IdleHibernationGracePeriodHours != nil && *request.IdleHibernationGracePeriodHours < 0 {
+		return errors.New("idle hibernation grace period must not be negative")
+	}
 
 	return nil
 }
@@ -73,6 +87,13 @@ type PatchGroupRequest struct {
 	Version       *string
 	Image         *string
 	MattermostEnv EnvVarMap
+
+	// HibernationSchedule replaces the hibernation schedule of the group.
+	// An empty schedule removes it.
+	HibernationSchedule *HibernationSchedule
+
+	IdleHibernationOptOut           *bool
+	IdleHibernationGracePeriodHours *int64
 }
 
 // Apply applies the patch to the given group.
@@ -104,6 +125,21 @@ func (p *PatchGroupRequest) Apply(group *Group) bool {
 			applied = true
 		}
 	}
+	if p.HibernationSchedule != nil {
+		if patchHibernationSchedule(&group.HibernationSchedule, p.HibernationSchedule) {
+			applied = true
+		}
+	}
+	if p.IdleHibernationOptOut != nil && *p.IdleHibernationOptOut != group.IdleHibernationOptOut {
+		applied = true
+		group.IdleHibernationOptOut = *p.IdleHibernationOptOut
+	}
+	if p.IdleHibernationGracePeriodHours != nil &&
+		(group.IdleHibernationGracePeriodHours == nil || *p.IdleHibernationGracePeriodHours != *group.IdleHibernationGracePeriodHours) {
+		applied = true
+		gracePeriod := *p.IdleHibernationGracePeriodHours
+		group.IdleHibernationGracePeriodHours = &gracePeriod
+	}
 
 	return applied
 }
@@ -116,6 +152,15 @@ func (p *PatchGroupRequest) Validate() error {
 	if p.MaxRolling != nil && *p.MaxRolling < 1 {
 		return errors.New("max rolling must be 1 or greater")
 	}
+	if p.HibernationSchedule != nil && !p.HibernationSchedule.IsEmpty() {
+		err := p.HibernationSchedule.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid hibernation schedule")
+		}
+	}
+	if p.IdleHibernationGracePeriodHours != nil && *p.IdleHibernationGracePeriodHours < 0 {
+		return errors.New("idle hibernation grace period must not be negative")
+	}
 	// EnvVarMap validation is skipped as all configurations of this now imply
 	// a specific patch action should be taken.
 
@@ -105,6 +105,20 @@ func TestPatchGroupRequestValid(t *testing.T) {
 				MaxRolling: i64oP(-1),
 			},
 		},
+		{
+			"idle hibernation grace period only",
+			false,
+			&model.PatchGroupRequest{
+				IdleHibernationGracePeriodHours: i64oP(0),
+			},
+		},
+		{
+			"invalid idle hibernation grace period only",
+			true,
+			&model.PatchGroupRequest{
+				IdleHibernationGracePeriodHours: i64oP(-1),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -167,6 +181,43 @@ func TestPatchGroupRequestApply(t *testing.T) {
 				Version: "version1",
 			},
 		},
+		{
+			"idle hibernation opt-out only",
+			true,
+			&model.PatchGroupRequest{
+				IdleHibernationOptOut: bToP(true),
+			},
+			&model.Group{},
+			&model.Group{
+				IdleHibernationOptOut: true,
+			},
+		},
+		{
+			"idle hibernation grace period only",
+			true,
+			&model.PatchGroupRequest{
+				IdleHibernationGracePeriodHours: i64oP(48),
+			},
+			&model.Group{
+				IdleHibernationGracePeriodHours: i64oP(24),
+			},
+			&model.Group{
+				IdleHibernationGracePeriodHours: i64oP(48),
+			},
+		},
+		{
+			"idle hibernation grace period unchanged",
+			false,
+			&model.PatchGroupRequest{
+				IdleHibernationGracePeriodHours: i64oP(24),
+			},
+			&model.Group{
+				IdleHibernationGracePeriodHours: i64oP(24),
+			},
+			&model.Group{
+				IdleHibernationGracePeriodHours: i64oP(24),
+			},
+		},
 		{
 			"image only",
 			true,
@@ -304,3 +355,7 @@ func TestPatchGroupRequestApply(t *testing.T) {
 func i64oP(i int64) *int64 {
 	return &i
 }
+
+func bToP(b bool) *bool {
+	return &b
+}
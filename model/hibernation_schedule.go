@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// HibernationScheduleActionHibernate is the action of putting an
+	// installation into hibernation.
+	HibernationScheduleActionHibernate = "hibernate"
+	// HibernationScheduleActionWakeUp is the action of waking an installation
+	// up from hibernation.
+	HibernationScheduleActionWakeUp = "wake-up"
+)
+
+// HibernationSchedule describes when an installation should be put into
+// hibernation and woken up again. Both times are standard five-field cron
+// expressions evaluated in the given time zone.
+type HibernationSchedule struct {
+	HibernateCron string
+	WakeUpCron    string
+	TimeZone      string `json:"TimeZone,omitempty"`
+}
+
+// IsEmpty returns true if the schedule has no hibernation or wake-up times.
+// An empty schedule in a patch request clears the existing schedule.
+func (s *HibernationSchedule) IsEmpty() bool {
+	return s.HibernateCron == "" && s.WakeUpCron == ""
+}
+
+// Validate validates the cron expressions and time zone of the schedule.
+func (s *HibernationSchedule) Validate() error {
+	if s.IsEmpty() {
+		return errors.New("must specify a hibernation or wake-up time")
+	}
+	if s.HibernateCron != "" {
+		_, err := cron.ParseStandard(s.HibernateCron)
+		if err != nil {
+			return errors.Wrap(err, "invalid hibernation cron expression")
+		}
+	}
+	if s.WakeUpCron != "" {
+		_, err := cron.ParseStandard(s.WakeUpCron)
+		if err != nil {
+			return errors.Wrap(err, "invalid wake-up cron expression")
+		}
+	}
+	_, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return errors.Wrap(err, "invalid time zone")
+	}
+
+	return nil
+}
+
+// DueAction returns the action whose scheduled time is the most recent one in
+// the time range (from, to], or an empty string if neither the hibernation
+// nor the wake-up time occurs in that range.
+func (s *HibernationSchedule) DueAction(from, to time.Time) (string, error) {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid time zone")
+	}
+	from = from.In(location)
+
+	var action string
+	var actionAt time.Time
+	for _, entry := range []struct {
+		action string
+		spec   string
+	}{
+		{HibernationScheduleActionHibernate, s.HibernateCron},
+		{HibernationScheduleActionWakeUp, s.WakeUpCron},
+	} {
+		if entry.spec == "" {
+			continue
+		}
+		schedule, err := cron.ParseStandard(entry.spec)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid %s cron expression", entry.action)
+		}
+
+		var last time.Time
+		for next := schedule.Next(from); !next.IsZero() && !next.After(to); next = schedule.Next(next) {
+			last = next
+		}
+		if !last.IsZero() && last.After(actionAt) {
+			action = entry.action
+			actionAt = last
+		}
+	}
+
+	return action, nil
+}
+
+// patchHibernationSchedule replaces the given schedule with the patch value,
+// removing it if the patch value is empty. Returns true if the schedule was
+// changed.
+func patchHibernationSchedule(schedule **HibernationSchedule, patch *HibernationSchedule) bool {
+	if patch.IsEmpty() {
+		if *schedule == nil {
+			return false
+		}
+		*schedule = nil
+		return true
+	}
+	if *schedule != nil && **schedule == *patch {
+		return false
+	}
+
+	newSchedule := *patch
+	*schedule = &newSchedule
+	return true
+}
+
+// HibernationScheduleFromJSON creates a HibernationSchedule from the JSON
+// representation stored in the database.
+func HibernationScheduleFromJSON(raw []byte) (*HibernationSchedule, error) {
+	schedule := &HibernationSchedule{}
+	err := json.Unmarshal(raw, schedule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal hibernation schedule")
+	}
+
+	return schedule, nil
+}
+
+// ToJSON returns the JSON representation of the schedule for storage in the
+// database. A nil schedule is stored as NULL.
+func (s *HibernationSchedule) ToJSON() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(s)
+}
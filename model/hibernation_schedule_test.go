@@ -0,0 +1,143 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHibernationScheduleValidate(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		requireError bool
+		schedule     *model.HibernationSchedule
+	}{
+		{
+			"empty",
+			true,
+			&model.HibernationSchedule{},
+		},
+		{
+			"hibernate only",
+			false,
+			&model.HibernationSchedule{HibernateCron: "0 20 * * 1-5"},
+		},
+		{
+			"wake up only",
+			false,
+			&model.HibernationSchedule{WakeUpCron: "@daily"},
+		},
+		{
+			"with time zone",
+			false,
+			&model.HibernationSchedule{HibernateCron: "0 20 * * *", WakeUpCron: "0 7 * * *", TimeZone: "Europe/Berlin"},
+		},
+		{
+			"invalid hibernate cron",
+			true,
+			&model.HibernationSchedule{HibernateCron: "0 25 * * *"},
+		},
+		{
+			"invalid wake up cron",
+			true,
+			&model.HibernationSchedule{HibernateCron: "0 20 * * *", WakeUpCron: "tomorrow"},
+		},
+		{
+			"invalid time zone",
+			true,
+			&model.HibernationSchedule{HibernateCron: "0 20 * * *", TimeZone: "Nowhere/Special"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.schedule.Validate())
+			} else {
+				assert.NoError(t, tc.schedule.Validate())
+			}
+		})
+	}
+}
+
+func TestHibernationScheduleDueAction(t *testing.T) {
+	schedule := &model.HibernationSchedule{
+		HibernateCron: "0 20 * * *",
+		WakeUpCron:    "0 7 * * *",
+		TimeZone:      "UTC",
+	}
+	date := func(hour, min int) time.Time {
+		return time.Date(2020, time.October, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	var testCases = []struct {
+		testName       string
+		from           time.Time
+		to             time.Time
+		expectedAction string
+	}{
+		{"nothing due", date(8, 0), date(19, 59), ""},
+		{"hibernation due", date(19, 59), date(20, 0), model.HibernationScheduleActionHibernate},
+		{"wake up due", date(6, 59), date(7, 1), model.HibernationScheduleActionWakeUp},
+		{"start of range is excluded", date(20, 0), date(20, 1), ""},
+		{"latest action wins", date(6, 0), date(21, 0), model.HibernationScheduleActionHibernate},
+		{"latest action wins across days", date(6, 0), date(21, 0).Add(12 * time.Hour), model.HibernationScheduleActionWakeUp},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			action, err := schedule.DueAction(tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAction, action)
+		})
+	}
+
+	t.Run("time zone", func(t *testing.T) {
+		berlinSchedule := &model.HibernationSchedule{
+			HibernateCron: "0 20 * * *",
+			TimeZone:      "Europe/Berlin",
+		}
+
+		// 20:00 in Berlin is 18:00 UTC during summer time.
+		action, err := berlinSchedule.DueAction(date(17, 59), date(18, 0))
+		require.NoError(t, err)
+		assert.Equal(t, model.HibernationScheduleActionHibernate, action)
+
+		action, err = berlinSchedule.DueAction(date(19, 59), date(20, 0))
+		require.NoError(t, err)
+		assert.Empty(t, action)
+	})
+}
+
+func TestInstallationEffectiveHibernationSchedule(t *testing.T) {
+	installationSchedule := &model.HibernationSchedule{HibernateCron: "0 20 * * *"}
+	groupSchedule := &model.HibernationSchedule{HibernateCron: "0 22 * * *"}
+
+	t.Run("no schedule", func(t *testing.T) {
+		installation := &model.Installation{}
+		assert.Nil(t, installation.EffectiveHibernationSchedule(nil))
+		assert.Nil(t, installation.EffectiveHibernationSchedule(&model.Group{}))
+	})
+
+	t.Run("installation schedule", func(t *testing.T) {
+		installation := &model.Installation{HibernationSchedule: installationSchedule}
+		assert.Equal(t, installationSchedule, installation.EffectiveHibernationSchedule(&model.Group{HibernationSchedule: groupSchedule}))
+	})
+
+	t.Run("group schedule", func(t *testing.T) {
+		installation := &model.Installation{}
+		assert.Equal(t, groupSchedule, installation.EffectiveHibernationSchedule(&model.Group{HibernationSchedule: groupSchedule}))
+	})
+
+	t.Run("deleted group schedule", func(t *testing.T) {
+		installation := &model.Installation{}
+		assert.Nil(t, installation.EffectiveHibernationSchedule(&model.Group{HibernationSchedule: groupSchedule, DeleteAt: 1}))
+	})
+}
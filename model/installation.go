@@ -33,6 +33,38 @@ type Installation struct {
 	LockAcquiredAt  int64
 	GroupOverrides  map[string]string `json:"GroupOverrides,omitempty"`
 
+	HibernationSchedule  *HibernationSchedule  `json:"HibernationSchedule,omitempty"`
+	PlacementConstraints *PlacementConstraints `json:"PlacementConstraints,omitempty"`
+
+	// LastActivityAt is the time of the most recent user activity recorded
+	// for the installation, and LastActivityCheckAt the time it was last
+	// checked.
+	LastActivityAt      int64
+	LastActivityCheckAt int64
+
+	// HibernationScheduleEvaluatedAt is the time up to which the hibernation
+	// schedule of the installation was acted upon.
+	HibernationScheduleEvaluatedAt int64
+
+	// MigrationTargetClusterID is the cluster the installation is being
+	// moved to, if any. Installations drained off a cluster have no target
+	// until a cluster able to host them is found.
+	MigrationTargetClusterID string `json:"MigrationTargetClusterID,omitempty"`
+	// MigrationTargetDatabaseID is the multitenant database the installation
+	// database is being moved to, if any.
+	MigrationTargetDatabaseID string `json:"MigrationTargetDatabaseID,omitempty"`
+
+	// ExternalDatabaseSecretName is the AWS Secrets Manager secret holding
+	// the connection string of an external database. EncryptedExternalDatabase
+	// is the encrypted connection string supplied directly instead.
+	ExternalDatabaseSecretName string `json:"ExternalDatabaseSecretName,omitempty"`
+	EncryptedExternalDatabase  []byte `json:"-"`
+
+	// ExternalFilestoreConfig is the bucket of an external S3 filestore, whose
+	// secret access key is stored separately in EncryptedExternalFilestoreSecret.
+	ExternalFilestoreConfig          *ExternalFilestoreConfig `json:"ExternalFilestoreConfig,omitempty"`
+	EncryptedExternalFilestoreSecret []byte                   `json:"-"`
+
 	// configconfigMergedWithGroup is set when the installation configuration
 	// has been overridden with group configuration. This value can then be
 	// checked later to determine whether the installation is safe to save or
@@ -57,6 +89,7 @@ type InstallationFilter struct {
 	PerPage        int
 	IncludeDeleted bool
 	DNS            string
+	State          string
 }
 
 // Clone returns a deep copy the installation.
@@ -64,6 +97,8 @@ func (i *Installation) Clone() *Installation {
 	var clone Installation
 	data, _ := json.Marshal(i)
 	json.Unmarshal(data, &clone)
+	clone.EncryptedExternalDatabase = i.EncryptedExternalDatabase
+	clone.EncryptedExternalFilestoreSecret = i.EncryptedExternalFilestoreSecret
 
 	return &clone
 }
@@ -73,6 +108,54 @@ func (i *Installation) IsInGroup() bool {
 	return i.GroupID != nil
 }
 
+// EffectiveHibernationSchedule returns the hibernation schedule of the
+// installation, falling back to the schedule of the given group when the
+// installation has no schedule of its own.
+func (i *Installation) EffectiveHibernationSchedule(group *Group) *HibernationSchedule {
+	if i.HibernationSchedule != nil {
+		return i.HibernationSchedule
+	}
+	if group != nil && !group.IsDeleted() {
+		return group.HibernationSchedule
+	}
+
+	return nil
+}
+
+// SupportsMigration returns true if the installation can be moved to another
+// cluster, which is not the case when its data is kept inside the cluster.
+func (i *Installation) SupportsMigration() bool {
+	return !i.InternalDatabase() && !i.InternalFilestore()
+}
+
+// IsMigrating returns true if the installation is being moved to another
+// cluster.
+func (i *Installation) IsMigrating() bool {
+	switch i.State {
+	case InstallationStateMigrationRequested,
+		InstallationStateMigrationInProgress,
+		InstallationStateMigrationDNS,
+		InstallationStateMigrationCleanup:
+		return true
+	}
+
+	return false
+}
+
+// IsMigratingDatabase returns true if the installation database is being moved
+// to another multitenant database.
+func (i *Installation) IsMigratingDatabase() bool {
+	switch i.State {
+	case InstallationStateDBMigrationRequested,
+		InstallationStateDBMigrationInProgress,
+		InstallationStateDBMigrationFinalizing,
+		InstallationStateDBMigrationCleanup:
+		return true
+	}
+
+	return false
+}
+
 // ConfigMergedWithGroup returns if the installation currently has inherited
 // group configuration values.
 func (i *Installation) ConfigMergedWithGroup() bool {
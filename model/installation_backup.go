@@ -0,0 +1,147 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// InstallationBackupStateBackupRequested is a backup waiting to be started.
+	InstallationBackupStateBackupRequested = "backup-requested"
+	// InstallationBackupStateBackupInProgress is a backup being taken.
+	InstallationBackupStateBackupInProgress = "backup-in-progress"
+	// InstallationBackupStateBackupSucceeded is a backup that was taken
+	// successfully and can be restored.
+	InstallationBackupStateBackupSucceeded = "backup-succeeded"
+	// InstallationBackupStateBackupFailed is a backup that failed.
+	InstallationBackupStateBackupFailed = "backup-failed"
+	// InstallationBackupStateRestoreRequested is a backup waiting to be
+	// restored to an installation.
+	InstallationBackupStateRestoreRequested = "restore-requested"
+	// InstallationBackupStateRestoreInProgress is a backup being restored to an
+	// installation.
+	InstallationBackupStateRestoreInProgress = "restore-in-progress"
+	// InstallationBackupStateDeleted is a backup whose stored data was removed
+	// along with the installation it was taken from.
+	InstallationBackupStateDeleted = "deleted"
+)
+
+// AllInstallationBackupStates is a list of all states an installation backup
+// can be in.
+// Warning:
+// When creating a new installation backup state, it must be added to this list.
+var AllInstallationBackupStates = []string{
+	InstallationBackupStateBackupRequested,
+	InstallationBackupStateBackupInProgress,
+	InstallationBackupStateBackupSucceeded,
+	InstallationBackupStateBackupFailed,
+	InstallationBackupStateRestoreRequested,
+	InstallationBackupStateRestoreInProgress,
+	InstallationBackupStateDeleted,
+}
+
+// AllInstallationBackupStatesPendingWork is a list of all installation backup
+// states that the supervisor will attempt to transition on the next "tick".
+// Warning:
+// When creating a new installation backup state, it must be added to this list
+// if the installation backup supervisor should perform some action on its next
+// work cycle.
+var AllInstallationBackupStatesPendingWork = []string{
+	InstallationBackupStateBackupRequested,
+	InstallationBackupStateBackupInProgress,
+	InstallationBackupStateRestoreRequested,
+	InstallationBackupStateRestoreInProgress,
+}
+
+const (
+	// BackupJobStateRunning is a backup or restore job that has not finished yet.
+	BackupJobStateRunning = "running"
+	// BackupJobStateSucceeded is a backup or restore job that completed
+	// successfully.
+	BackupJobStateSucceeded = "succeeded"
+	// BackupJobStateFailed is a backup or restore job that failed.
+	BackupJobStateFailed = "failed"
+)
+
+// InstallationBackup is a backup of the database and filestore contents of an
+// installation.
+//
+// The database is captured with a logical dump, stored alongside a copy of the
+// filestore contents in the filestore of the installation. Installations using
+// a single tenant RDS database additionally get an RDS snapshot.
+type InstallationBackup struct {
+	ID                    string
+	InstallationID        string
+	ClusterInstallationID string
+	// RestoreInstallationID is the installation the backup is being, or was
+	// last, restored to.
+	RestoreInstallationID string
+	State                 string
+	RequestAt             int64
+	CompleteAt            int64
+	LockAcquiredBy        *string
+	LockAcquiredAt        int64
+}
+
+// InstallationBackupFilter describes the parameters used to constrain a set of
+// installation backups.
+type InstallationBackupFilter struct {
+	InstallationID string
+	State          string
+	Page           int
+	PerPage        int
+}
+
+// StorageKey returns the key under which the backup is stored in the
+// filestore of the installation.
+func (b *InstallationBackup) StorageKey() string {
+	return fmt.Sprintf("backups/%s", b.ID)
+}
+
+// CanRestore returns true if the backup was taken successfully and is not
+// currently being restored.
+func (b *InstallationBackup) CanRestore() bool {
+	return b.State == InstallationBackupStateBackupSucceeded
+}
+
+// IsValidInstallationBackupState returns true if the given state is a valid
+// installation backup state.
+func IsValidInstallationBackupState(state string) bool {
+	for _, validState := range AllInstallationBackupStates {
+		if state == validState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InstallationBackupFromReader decodes a json-encoded installation backup from the given io.Reader.
+func InstallationBackupFromReader(reader io.Reader) (*InstallationBackup, error) {
+	backup := InstallationBackup{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&backup)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+// InstallationBackupsFromReader decodes a json-encoded list of installation backups from the given io.Reader.
+func InstallationBackupsFromReader(reader io.Reader) ([]*InstallationBackup, error) {
+	backups := []*InstallationBackup{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&backups)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return backups, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// RestoreInstallationBackupRequest specifies the parameters for restoring an
+// installation backup.
+type RestoreInstallationBackupRequest struct {
+	// InstallationID is the installation to restore the backup to. It defaults
+	// to the installation the backup was taken from.
+	InstallationID string
+	// CreateInstallation, when set, creates a new installation and restores
+	// the backup to it once it is stable. It cannot be combined with
+	// InstallationID.
+	CreateInstallation *CreateInstallationRequest
+}
+
+// NewRestoreInstallationBackupRequestFromReader will create a RestoreInstallationBackupRequest from an io.Reader with JSON data.
+func NewRestoreInstallationBackupRequestFromReader(reader io.Reader) (*RestoreInstallationBackupRequest, error) {
+	var restoreRequest RestoreInstallationBackupRequest
+	err := json.NewDecoder(reader).Decode(&restoreRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode restore installation backup request")
+	}
+
+	if restoreRequest.CreateInstallation != nil {
+		if len(restoreRequest.InstallationID) != 0 {
+			return nil, errors.New("restore installation backup request cannot both target an installation and create one")
+		}
+		restoreRequest.CreateInstallation.SetDefaults()
+		err = restoreRequest.CreateInstallation.Validate()
+		if err != nil {
+			return nil, errors.Wrap(err, "restore installation backup request failed validation")
+		}
+	}
+
+	return &restoreRequest, nil
+}
+
+// GetInstallationBackupsRequest describes the parameters to request a list of
+// installation backups.
+type GetInstallationBackupsRequest struct {
+	InstallationID string
+	State          string
+	Page           int
+	PerPage        int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetInstallationBackupsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("installation", request.InstallationID)
+	q.Add("state", request.State)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
@@ -28,6 +28,9 @@ const (
 	// InstallationDatabaseMultiTenantRDSPostgres is a PostgreSQL multitenant
 	// database hosted via Amazon RDS.
 	InstallationDatabaseMultiTenantRDSPostgres = "aws-multitenant-rds-postgres"
+	// InstallationDatabaseExternal is a MySQL or PostgreSQL database managed
+	// outside of the provisioner.
+	InstallationDatabaseExternal = "external"
 
 	// DatabaseEngineTypeMySQL is a MySQL database.
 	DatabaseEngineTypeMySQL = "mysql"
@@ -43,6 +46,16 @@ type Database interface {
 	GenerateDatabaseSpecAndSecret(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Database, *corev1.Secret, error)
 }
 
+// InstallationDatabaseMigration is the interface for moving the database of an
+// installation to another multitenant database.
+type InstallationDatabaseMigration interface {
+	Setup(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	GenerateMigrationSecret(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*corev1.Secret, error)
+	Switch(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	Cleanup(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	Rollback(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+}
+
 // InstallationDatabaseStoreInterface is the interface necessary for SQLStore
 // functionality to correlate an installation to a cluster for database creation.
 // TODO(gsagula): Consider renaming this interface to InstallationDatabaseInterface. For reference,
@@ -54,8 +67,11 @@ type InstallationDatabaseStoreInterface interface {
 	GetMultitenantDatabaseForInstallationID(installationID string) (*MultitenantDatabase, error)
 	CreateMultitenantDatabase(multitenantDatabase *MultitenantDatabase) error
 	UpdateMultitenantDatabase(multitenantDatabase *MultitenantDatabase) error
+	DeleteMultitenantDatabase(multitenantDatabaseID string) error
 	LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error)
 	UnlockMultitenantDatabase(multitenantdatabaseID, lockerID string, force bool) (bool, error)
+	LockMultitenantDatabaseVPC(vpcID, lockerID string) (bool, error)
+	UnlockMultitenantDatabaseVPC(vpcID, lockerID string, force bool) (bool, error)
 }
 
 // MysqlOperatorDatabase is a database backed by the MySQL operator.
@@ -102,6 +118,20 @@ func (i *Installation) InternalDatabase() bool {
 	return i.Database == InstallationDatabaseMysqlOperator
 }
 
+// VPCDatabase returns true if the database of the installation is hosted in
+// the VPC of its cluster, and so is only reachable from clusters in that VPC.
+func (i *Installation) VPCDatabase() bool {
+	switch i.Database {
+	case InstallationDatabaseSingleTenantRDSMySQL,
+		InstallationDatabaseSingleTenantRDSPostgres,
+		InstallationDatabaseMultiTenantRDSMySQL,
+		InstallationDatabaseMultiTenantRDSPostgres:
+		return true
+	}
+
+	return false
+}
+
 // IsSupportedDatabase returns true if the given database string is supported.
 func IsSupportedDatabase(database string) bool {
 	switch database {
@@ -110,6 +140,7 @@ func IsSupportedDatabase(database string) bool {
 	case InstallationDatabaseMultiTenantRDSMySQL:
 	case InstallationDatabaseMultiTenantRDSPostgres:
 	case InstallationDatabaseMysqlOperator:
+	case InstallationDatabaseExternal:
 	default:
 		return false
 	}
@@ -21,6 +21,7 @@ func TestInternalDatabase(t *testing.T) {
 		{model.InstallationDatabaseMysqlOperator, true},
 		{model.InstallationDatabaseSingleTenantRDSMySQL, false},
 		{model.InstallationDatabaseSingleTenantRDSPostgres, false},
+		{model.InstallationDatabaseExternal, false},
 	}
 
 	for _, tc := range testCases {
@@ -44,6 +45,7 @@ func TestIsSupportedDatabase(t *testing.T) {
 		{model.InstallationDatabaseMysqlOperator, true},
 		{model.InstallationDatabaseSingleTenantRDSMySQL, true},
 		{model.InstallationDatabaseSingleTenantRDSPostgres, true},
+		{model.InstallationDatabaseExternal, true},
 	}
 
 	for _, tc := range testCases {
@@ -19,6 +19,9 @@ const (
 	// InstallationFilestoreMultiTenantAwsS3 is a filestore hosted via a shared
 	// Amazon S3 bucket.
 	InstallationFilestoreMultiTenantAwsS3 = "aws-multitenant-s3"
+	// InstallationFilestoreExternalS3 is a filestore hosted via an
+	// S3-compatible bucket managed outside of the provisioner.
+	InstallationFilestoreExternalS3 = "external-s3"
 )
 
 // Filestore is the interface for managing Mattermost filestores.
@@ -69,5 +72,6 @@ func (i *Installation) InternalFilestore() bool {
 func IsSupportedFilestore(filestore string) bool {
 	return filestore == InstallationFilestoreMinioOperator ||
 		filestore == InstallationFilestoreAwsS3 ||
-		filestore == InstallationFilestoreMultiTenantAwsS3
+		filestore == InstallationFilestoreMultiTenantAwsS3 ||
+		filestore == InstallationFilestoreExternalS3
 }
@@ -20,6 +20,7 @@ func TestInternalFilestore(t *testing.T) {
 		{"unknown", false},
 		{model.InstallationFilestoreMinioOperator, true},
 		{model.InstallationFilestoreAwsS3, false},
+		{model.InstallationFilestoreExternalS3, false},
 	}
 
 	for _, tc := range testCases {
@@ -42,6 +43,7 @@ func TestIsSupportedFilestore(t *testing.T) {
 		{"unknown", false},
 		{model.InstallationFilestoreMinioOperator, true},
 		{model.InstallationFilestoreAwsS3, true},
+		{model.InstallationFilestoreExternalS3, true},
 	}
 
 	for _, tc := range testCases {
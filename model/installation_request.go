@@ -30,6 +30,15 @@ type CreateInstallationRequest struct {
 	Filestore       string
 	APISecurityLock bool
 	MattermostEnv   EnvVarMap
+
+	HibernationSchedule  *HibernationSchedule  `json:"HibernationSchedule,omitempty"`
+	PlacementConstraints *PlacementConstraints `json:"PlacementConstraints,omitempty"`
+	// ExternalDatabaseConfig is required for, and only accepted with,
+	// external databases.
+	ExternalDatabaseConfig *ExternalDatabaseConfig `json:"ExternalDatabaseConfig,omitempty"`
+	// ExternalFilestoreConfig is required for, and only accepted with,
+	// external S3 filestores.
+	ExternalFilestoreConfig *ExternalFilestoreConfig `json:"ExternalFilestoreConfig,omitempty"`
 }
 
 // SetDefaults sets the default values for an installation create request.
@@ -82,10 +91,44 @@ func (request *CreateInstallationRequest) Validate() error {
 	if !IsSupportedFilestore(request.Filestore) {
 		return errors.Errorf("unsupported filestore %s", request.Filestore)
 	}
+	if request.Database == InstallationDatabaseExternal {
+		if request.ExternalDatabaseConfig == nil {
+			return errors.New("must specify external database configuration")
+		}
+		err = request.ExternalDatabaseConfig.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid external database configuration")
+		}
+	} else if request.ExternalDatabaseConfig != nil {
+		return errors.Errorf("external database configuration is only supported with the %s database", InstallationDatabaseExternal)
+	}
+	if request.Filestore == InstallationFilestoreExternalS3 {
+		if request.ExternalFilestoreConfig == nil {
+			return errors.New("must specify external filestore configuration")
+		}
+		err = request.ExternalFilestoreConfig.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid external filestore configuration")
+		}
+	} else if request.ExternalFilestoreConfig != nil {
+		return errors.Errorf("external filestore configuration is only supported with the %s filestore", InstallationFilestoreExternalS3)
+	}
 	err = request.MattermostEnv.Validate()
 	if err != nil {
 		return errors.Wrap(err, "invalid env var settings")
 	}
+	if request.HibernationSchedule != nil {
+		err = request.HibernationSchedule.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid hibernation schedule")
+		}
+	}
+	if request.PlacementConstraints != nil {
+		err = request.PlacementConstraints.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid placement constraints")
+		}
+	}
 
 	return checkSpaces(request)
 }
@@ -193,6 +236,10 @@ type PatchInstallationRequest struct {
 	Size          *string
 	License       *string
 	MattermostEnv EnvVarMap
+
+	// HibernationSchedule replaces the hibernation schedule of the
+	// installation. An empty schedule removes it.
+	HibernationSchedule *HibernationSchedule
 }
 
 // Validate validates the values of a installation patch request.
@@ -209,6 +256,12 @@ func (p *PatchInstallationRequest) Validate() error {
 			return errors.Wrap(err, "invalid size")
 		}
 	}
+	if p.HibernationSchedule != nil && !p.HibernationSchedule.IsEmpty() {
+		err := p.HibernationSchedule.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid hibernation schedule")
+		}
+	}
 	// EnvVarMap validation is skipped as all configurations of this now imply
 	// a specific patch action should be taken.
 
@@ -244,6 +297,17 @@ func (p *PatchInstallationRequest) Apply(installation *Installation) bool {
 	return applied
 }
 
+// ApplyHibernationSchedule applies the hibernation schedule of the patch to
+// the given installation. A schedule change does not require the installation
+// to be updated, so it is applied separately from the rest of the patch.
+func (p *PatchInstallationRequest) ApplyHibernationSchedule(installation *Installation) bool {
+	if p.HibernationSchedule == nil {
+		return false
+	}
+
+	return patchHibernationSchedule(&installation.HibernationSchedule, p.HibernationSchedule)
+}
+
 // NewPatchInstallationRequestFromReader will create a PatchInstallationRequest from an io.Reader with JSON data.
 func NewPatchInstallationRequestFromReader(reader io.Reader) (*PatchInstallationRequest, error) {
 	var patchInstallationRequest PatchInstallationRequest
@@ -259,3 +323,66 @@ func NewPatchInstallationRequestFromReader(reader io.Reader) (*PatchInstallation
 
 	return &patchInstallationRequest, nil
 }
+
+// MigrateInstallationRequest specifies the parameters for moving an
+// installation to another cluster.
+type MigrateInstallationRequest struct {
+	TargetClusterID string
+}
+
+// Validate validates the values of a migrate installation request.
+func (request *MigrateInstallationRequest) Validate() error {
+	if len(request.TargetClusterID) == 0 {
+		return errors.New("must specify a target cluster")
+	}
+
+	return nil
+}
+
+// NewMigrateInstallationRequestFromReader will create a MigrateInstallationRequest from an io.Reader with JSON data.
+func NewMigrateInstallationRequestFromReader(reader io.Reader) (*MigrateInstallationRequest, error) {
+	var migrateInstallationRequest MigrateInstallationRequest
+	err := json.NewDecoder(reader).Decode(&migrateInstallationRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode migrate installation request")
+	}
+
+	err = migrateInstallationRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid migrate installation request")
+	}
+
+	return &migrateInstallationRequest, nil
+}
+
+// MigrateInstallationDatabaseRequest specifies the parameters for moving the
+// database of an installation to another multitenant database.
+type MigrateInstallationDatabaseRequest struct {
+	TargetDatabaseID string
+}
+
+// Validate validates the values of a migrate installation database request.
+func (request *MigrateInstallationDatabaseRequest) Validate() error {
+	if len(request.TargetDatabaseID) == 0 {
+		return errors.New("must specify a target database")
+	}
+
+	return nil
+}
+
+// NewMigrateInstallationDatabaseRequestFromReader will create a
+// MigrateInstallationDatabaseRequest from an io.Reader with JSON data.
+func NewMigrateInstallationDatabaseRequestFromReader(reader io.Reader) (*MigrateInstallationDatabaseRequest, error) {
+	var migrateRequest MigrateInstallationDatabaseRequest
+	err := json.NewDecoder(reader).Decode(&migrateRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode migrate installation database request")
+	}
+
+	err = migrateRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid migrate installation database request")
+	}
+
+	return &migrateRequest, nil
+}
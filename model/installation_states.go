@@ -34,6 +34,42 @@ const (
 	InstallationStateHibernationInProgress = "hibernation-in-progress"
 	// InstallationStateHibernating is an installation that is hibernating.
 	InstallationStateHibernating = "hibernating"
+	// InstallationStateRestorationInProgress is an installation that is having
+	// a backup restored to it.
+	InstallationStateRestorationInProgress = "restoration-in-progress"
+	// InstallationStateRestorationFailed is an installation that failed to
+	// have a backup restored to it.
+	InstallationStateRestorationFailed = "restoration-failed"
+	// InstallationStateMigrationRequested is an installation that is about to
+	// be moved to another cluster.
+	InstallationStateMigrationRequested = "migration-requested"
+	// InstallationStateMigrationInProgress is an installation waiting for its
+	// cluster installation on the migration target cluster to become stable.
+	InstallationStateMigrationInProgress = "migration-in-progress"
+	// InstallationStateMigrationDNS is an installation having its DNS switched
+	// to the migration target cluster.
+	InstallationStateMigrationDNS = "migration-configuring-dns"
+	// InstallationStateMigrationCleanup is an installation having its cluster
+	// installations on the migration source clusters deleted.
+	InstallationStateMigrationCleanup = "migration-cleanup"
+	// InstallationStateMigrationFailed is an installation that failed to be
+	// moved to another cluster.
+	InstallationStateMigrationFailed = "migration-failed"
+	// InstallationStateDBMigrationRequested is an installation that is about
+	// to have its database moved to another multitenant database.
+	InstallationStateDBMigrationRequested = "db-migration-requested"
+	// InstallationStateDBMigrationInProgress is an installation having its
+	// database copied to the migration target multitenant database.
+	InstallationStateDBMigrationInProgress = "db-migration-in-progress"
+	// InstallationStateDBMigrationFinalizing is an installation being switched
+	// over to the migration target multitenant database.
+	InstallationStateDBMigrationFinalizing = "db-migration-finalizing"
+	// InstallationStateDBMigrationCleanup is an installation having its
+	// database removed from the source multitenant database.
+	InstallationStateDBMigrationCleanup = "db-migration-cleanup"
+	// InstallationStateDBMigrationFailed is an installation that failed to
+	// have its database moved to another multitenant database.
+	InstallationStateDBMigrationFailed = "db-migration-failed"
 	// InstallationStateUpdateRequested is an installation that is about to undergo an update.
 	InstallationStateUpdateRequested = "update-requested"
 	// InstallationStateUpdateInProgress is an installation that is being updated.
@@ -72,6 +108,18 @@ var AllInstallationStates = []string{
 	InstallationStateHibernationRequested,
 	InstallationStateHibernationInProgress,
 	InstallationStateHibernating,
+	InstallationStateRestorationInProgress,
+	InstallationStateRestorationFailed,
+	InstallationStateMigrationRequested,
+	InstallationStateMigrationInProgress,
+	InstallationStateMigrationDNS,
+	InstallationStateMigrationCleanup,
+	InstallationStateMigrationFailed,
+	InstallationStateDBMigrationRequested,
+	InstallationStateDBMigrationInProgress,
+	InstallationStateDBMigrationFinalizing,
+	InstallationStateDBMigrationCleanup,
+	InstallationStateDBMigrationFailed,
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
 	InstallationStateUpdateFailed,
@@ -96,6 +144,14 @@ var AllInstallationStatesPendingWork = []string{
 	InstallationStateCreationDNS,
 	InstallationStateHibernationRequested,
 	InstallationStateHibernationInProgress,
+	InstallationStateMigrationRequested,
+	InstallationStateMigrationInProgress,
+	InstallationStateMigrationDNS,
+	InstallationStateMigrationCleanup,
+	InstallationStateDBMigrationRequested,
+	InstallationStateDBMigrationInProgress,
+	InstallationStateDBMigrationFinalizing,
+	InstallationStateDBMigrationCleanup,
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
 	InstallationStateDeletionRequested,
@@ -111,6 +167,8 @@ var AllInstallationStatesPendingWork = []string{
 var AllInstallationRequestStates = []string{
 	InstallationStateCreationRequested,
 	InstallationStateHibernationRequested,
+	InstallationStateMigrationRequested,
+	InstallationStateDBMigrationRequested,
 	InstallationStateUpdateRequested,
 	InstallationStateDeletionRequested,
 }
@@ -123,6 +181,10 @@ func (i *Installation) ValidTransitionState(newState string) bool {
 		return validTransitionToInstallationStateCreationRequested(i.State)
 	case InstallationStateHibernationRequested:
 		return validTransitionToInstallationStateHibernationRequested(i.State)
+	case InstallationStateMigrationRequested:
+		return validTransitionToInstallationStateMigrationRequested(i.State)
+	case InstallationStateDBMigrationRequested:
+		return validTransitionToInstallationStateDBMigrationRequested(i.State)
 	case InstallationStateUpdateRequested:
 		return validTransitionToInstallationStateUpgradeRequested(i.State)
 	case InstallationStateDeletionRequested:
@@ -151,12 +213,34 @@ func validTransitionToInstallationStateHibernationRequested(currentState string)
 	return false
 }
 
+func validTransitionToInstallationStateMigrationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateMigrationFailed:
+		return true
+	}
+
+	return false
+}
+
+func validTransitionToInstallationStateDBMigrationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateDBMigrationFailed:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToInstallationStateUpgradeRequested(currentState string) bool {
 	switch currentState {
 	case InstallationStateStable,
 		InstallationStateHibernating,
 		InstallationStateUpdateRequested,
-		InstallationStateUpdateFailed:
+		InstallationStateUpdateFailed,
+		InstallationStateRestorationFailed,
+		InstallationStateDBMigrationFailed:
 		return true
 	}
 
@@ -179,7 +263,10 @@ func validTransitionToInstallationStateDeletionRequested(currentState string) bo
 		InstallationStateDeletionRequested,
 		InstallationStateDeletionInProgress,
 		InstallationStateDeletionFinalCleanup,
-		InstallationStateDeletionFailed:
+		InstallationStateDeletionFailed,
+		InstallationStateRestorationFailed,
+		InstallationStateMigrationFailed,
+		InstallationStateDBMigrationFailed:
 		return true
 	}
 
@@ -30,6 +30,31 @@ func TestInstallationClone(t *testing.T) {
 	// Verify changing pointers in the clone doesn't affect the original.
 	clone.GroupID = sToP("new_group_id")
 	require.NotEqual(t, installation, clone)
+
+	t.Run("external database", func(t *testing.T) {
+		installation := &Installation{
+			Database:                  InstallationDatabaseExternal,
+			EncryptedExternalDatabase: []byte("encrypted"),
+		}
+
+		clone := installation.Clone()
+		require.Equal(t, installation, clone)
+	})
+
+	t.Run("external filestore", func(t *testing.T) {
+		installation := &Installation{
+			Filestore: InstallationFilestoreExternalS3,
+			ExternalFilestoreConfig: &ExternalFilestoreConfig{
+				Endpoint:    "minio.example.com:9000",
+				Bucket:      "mattermost",
+				AccessKeyID: "access",
+			},
+			EncryptedExternalFilestoreSecret: []byte("encrypted"),
+		}
+
+		clone := installation.Clone()
+		require.Equal(t, installation, clone)
+	})
 }
 
 func TestInstallationFromReader(t *testing.T) {
@@ -8,6 +8,15 @@ import (
 	"encoding/json"
 )
 
+const (
+	// KopsInstanceGroupNodes is the name of the default worker node pool
+	// created with every cluster.
+	KopsInstanceGroupNodes = "nodes"
+	// KopsInstanceGroupLabel is the node label set by kops to the name of the
+	// instance group of each node.
+	KopsInstanceGroupLabel = "kops.k8s.io/instancegroup"
+)
+
 // KopsMetadata is the provisioner metadata stored in a model.Cluster.
 type KopsMetadata struct {
 	Name               string
@@ -20,6 +29,46 @@ type KopsMetadata struct {
 	NodeMaxCount       int64
 	ChangeRequest      *KopsMetadataRequestedState `json:"ChangeRequest,omitempty"`
 	Warnings           []string                    `json:"Warnings,omitempty"`
+
+	// InstanceGroups lists the worker node pools of the cluster, including
+	// the default pool also described by the Node fields above.
+	InstanceGroups []*KopsInstanceGroup `json:"InstanceGroups,omitempty"`
+
+	// NodeSpotMaxPrice and NodeMixedInstanceTypes describe the spot instances
+	// of the default pool, if it runs on any.
+	NodeSpotMaxPrice       string   `json:"NodeSpotMaxPrice,omitempty"`
+	NodeMixedInstanceTypes []string `json:"NodeMixedInstanceTypes,omitempty"`
+
+	// VPC is the ID of the VPC claimed for the cluster, if it was created in
+	// one of the VPCs available to the provisioner.
+	VPC string `json:"VPC,omitempty"`
+}
+
+// KopsInstanceGroup is a pool of worker nodes of a cluster.
+type KopsInstanceGroup struct {
+	Name               string
+	InstanceType       string
+	MinCount           int64
+	MaxCount           int64
+	NodeLabels         map[string]string `json:"NodeLabels,omitempty"`
+	SpotMaxPrice       string            `json:"SpotMaxPrice,omitempty"`
+	MixedInstanceTypes []string          `json:"MixedInstanceTypes,omitempty"`
+}
+
+// IsSpot returns true if the nodes of the instance group are spot instances.
+func (ig *KopsInstanceGroup) IsSpot() bool {
+	return len(ig.SpotMaxPrice) != 0
+}
+
+// Labels returns the labels of the nodes of the instance group, including the
+// label set by kops to the instance group name.
+func (ig *KopsInstanceGroup) Labels() map[string]string {
+	labels := map[string]string{KopsInstanceGroupLabel: ig.Name}
+	for key, value := range ig.NodeLabels {
+		labels[key] = value
+	}
+
+	return labels
 }
 
 // KopsMetadataRequestedState is the requested state for kops metadata.
@@ -31,6 +80,73 @@ type KopsMetadataRequestedState struct {
 	NodeInstanceType   string `json:"NodeInstanceType,omitempty"`
 	NodeMinCount       int64  `json:"NodeMinCount,omitempty"`
 	NodeMaxCount       int64  `json:"NodeMaxCount,omitempty"`
+
+	// InstanceGroup is the worker node pool the node changes apply to. The
+	// default pool is changed when empty. NodeLabels are only set on pools
+	// being created.
+	InstanceGroup       string            `json:"InstanceGroup,omitempty"`
+	NodeLabels          map[string]string `json:"NodeLabels,omitempty"`
+	DeleteInstanceGroup bool              `json:"DeleteInstanceGroup,omitempty"`
+
+	// NodeSpotMaxPrice switches the nodes to spot instances bid at up to the
+	// given hourly price, or back to on-demand instances when set to an empty
+	// string. The spot settings are left unchanged when nil.
+	NodeSpotMaxPrice       *string  `json:"NodeSpotMaxPrice,omitempty"`
+	NodeMixedInstanceTypes []string `json:"NodeMixedInstanceTypes,omitempty"`
+}
+
+// GetInstanceGroup returns the worker node pool with the given name, or nil
+// if the cluster has no such pool.
+func (km *KopsMetadata) GetInstanceGroup(name string) *KopsInstanceGroup {
+	for _, ig := range km.InstanceGroups {
+		if ig.Name == name {
+			return ig
+		}
+	}
+
+	return nil
+}
+
+// MatchesNodeSelector returns true if the nodes of at least one worker node
+// pool of the cluster have all the labels of the given node selector.
+func (km *KopsMetadata) MatchesNodeSelector(selector map[string]string) bool {
+	instanceGroups := km.InstanceGroups
+	if len(instanceGroups) == 0 {
+		// Metadata recorded before node pools were tracked only describes the
+		// default pool.
+		instanceGroups = []*KopsInstanceGroup{{Name: KopsInstanceGroupNodes}}
+	}
+
+	for _, ig := range instanceGroups {
+		labels := ig.Labels()
+		matches := true
+		for key, value := range selector {
+			if labels[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UsesSpotInstances returns true if any worker node pool of the cluster runs
+// on spot instances.
+func (km *KopsMetadata) UsesSpotInstances() bool {
+	if len(km.NodeSpotMaxPrice) != 0 {
+		return true
+	}
+	for _, ig := range km.InstanceGroups {
+		if ig.IsSpot() {
+			return true
+		}
+	}
+
+	return false
 }
 
 // ClearChangeRequest clears the kops metadata change request.
@@ -29,3 +29,41 @@ func TestNewKopsMetadata(t *testing.T) {
 		require.Equal(t, "name", kopsMetadata.Name)
 	})
 }
+
+func TestKopsMetadataMatchesNodeSelector(t *testing.T) {
+	t.Run("no instance groups", func(t *testing.T) {
+		kopsMetadata := &model.KopsMetadata{}
+		require.True(t, kopsMetadata.MatchesNodeSelector(nil))
+		require.True(t, kopsMetadata.MatchesNodeSelector(map[string]string{model.KopsInstanceGroupLabel: model.KopsInstanceGroupNodes}))
+		require.False(t, kopsMetadata.MatchesNodeSelector(map[string]string{model.KopsInstanceGroupLabel: "memory"}))
+	})
+
+	t.Run("instance groups", func(t *testing.T) {
+		kopsMetadata := &model.KopsMetadata{
+			InstanceGroups: []*model.KopsInstanceGroup{
+				{Name: model.KopsInstanceGroupNodes},
+				{Name: "memory", NodeLabels: map[string]string{"pool": "memory", "tier": "enterprise"}},
+			},
+		}
+		require.True(t, kopsMetadata.MatchesNodeSelector(map[string]string{model.KopsInstanceGroupLabel: "memory"}))
+		require.True(t, kopsMetadata.MatchesNodeSelector(map[string]string{"pool": "memory", "tier": "enterprise"}))
+		require.False(t, kopsMetadata.MatchesNodeSelector(map[string]string{"pool": "memory", "tier": "free"}))
+		require.False(t, kopsMetadata.MatchesNodeSelector(map[string]string{"pool": "spot"}))
+	})
+}
+
+func TestKopsMetadataUsesSpotInstances(t *testing.T) {
+	kopsMetadata := &model.KopsMetadata{
+		InstanceGroups: []*model.KopsInstanceGroup{
+			{Name: model.KopsInstanceGroupNodes},
+			{Name: "memory"},
+		},
+	}
+	require.False(t, kopsMetadata.UsesSpotInstances())
+
+	kopsMetadata.InstanceGroups[1].SpotMaxPrice = "0.1"
+	require.True(t, kopsMetadata.UsesSpotInstances())
+
+	kopsMetadata = &model.KopsMetadata{NodeSpotMaxPrice: "0.05"}
+	require.True(t, kopsMetadata.UsesSpotInstances())
+}
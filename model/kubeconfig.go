@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ValidateKubeconfig validates the kubeconfig of an imported cluster.
+//
+// The kubeconfig is used from the provisioner host, so it must carry all of its
+// credentials inline: exec plugins, auth providers and references to files,
+// which would run commands or read files on the provisioner host, are
+// rejected.
+func ValidateKubeconfig(kubeconfig []byte) error {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse kubeconfig")
+	}
+
+	if len(config.CurrentContext) == 0 {
+		return errors.New("kubeconfig has no current context")
+	}
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return errors.Errorf("current context %s not found", config.CurrentContext)
+	}
+	if _, ok = config.Clusters[context.Cluster]; !ok {
+		return errors.Errorf("cluster %s of the current context not found", context.Cluster)
+	}
+	if _, ok = config.AuthInfos[context.AuthInfo]; !ok {
+		return errors.Errorf("user %s of the current context not found", context.AuthInfo)
+	}
+
+	for name, cluster := range config.Clusters {
+		if len(cluster.Server) == 0 {
+			return errors.Errorf("cluster %s has no server", name)
+		}
+		if len(cluster.CertificateAuthority) != 0 {
+			return errors.Errorf("cluster %s must use certificate-authority-data instead of certificate-authority", name)
+		}
+	}
+
+	for name, authInfo := range config.AuthInfos {
+		if authInfo.Exec != nil {
+			return errors.Errorf("user %s must not use an exec plugin", name)
+		}
+		if authInfo.AuthProvider != nil {
+			return errors.Errorf("user %s must not use an auth provider", name)
+		}
+		if len(authInfo.ClientCertificate) != 0 || len(authInfo.ClientKey) != 0 {
+			return errors.Errorf("user %s must use client-certificate-data and client-key-data instead of client-certificate and client-key", name)
+		}
+		if len(authInfo.TokenFile) != 0 {
+			return errors.Errorf("user %s must use token instead of tokenFile", name)
+		}
+
+		hasClientCertificate := len(authInfo.ClientCertificateData) != 0 && len(authInfo.ClientKeyData) != 0
+		if !hasClientCertificate && len(authInfo.Token) == 0 {
+			return errors.Errorf("user %s must have either client-certificate-data and client-key-data or a token", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: platform
+clusters:
+- name: platform
+  cluster:
+    server: https://platform.example.com
+    certificate-authority-data: Y2VydGlmaWNhdGU=
+contexts:
+- name: platform
+  context:
+    cluster: platform
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret-token
+`
+
+func TestValidateKubeconfig(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		kubeconfig   string
+		requireError bool
+	}{
+		{"token", testKubeconfig, false},
+		{
+			"client certificate data",
+			strings.Replace(testKubeconfig, "token: secret-token", "client-certificate-data: Y2VydA==\n    client-key-data: a2V5", 1),
+			false,
+		},
+		{"not a kubeconfig", "apiVersion: [v1", true},
+		{"no current context", strings.Replace(testKubeconfig, "current-context: platform", "", 1), true},
+		{"unknown current context", strings.Replace(testKubeconfig, "current-context: platform", "current-context: other", 1), true},
+		{"no server", strings.Replace(testKubeconfig, "server: https://platform.example.com", "", 1), true},
+		{
+			"certificate authority file",
+			strings.Replace(testKubeconfig, "certificate-authority-data: Y2VydGlmaWNhdGU=", "certificate-authority: /etc/ca.crt", 1),
+			true,
+		},
+		{
+			"exec plugin",
+			strings.Replace(testKubeconfig, "token: secret-token", "token: secret-token\n    exec:\n      apiVersion: client.authentication.k8s.io/v1alpha1\n      command: /bin/sh", 1),
+			true,
+		},
+		{
+			"auth provider",
+			strings.Replace(testKubeconfig, "token: secret-token", "token: secret-token\n    auth-provider:\n      name: gcp", 1),
+			true,
+		},
+		{
+			"client certificate file",
+			strings.Replace(testKubeconfig, "token: secret-token", "client-certificate: /etc/client.crt\n    client-key-data: a2V5", 1),
+			true,
+		},
+		{
+			"client key file",
+			strings.Replace(testKubeconfig, "token: secret-token", "client-certificate-data: Y2VydA==\n    client-key: /etc/client.key", 1),
+			true,
+		},
+		{
+			"token file",
+			strings.Replace(testKubeconfig, "token: secret-token", "tokenFile: /var/run/secrets/token", 1),
+			true,
+		},
+		{"no credentials", strings.Replace(testKubeconfig, "token: secret-token", "username: admin", 1), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			err := model.ValidateKubeconfig([]byte(tc.kubeconfig))
+			if tc.requireError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
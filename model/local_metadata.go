@@ -0,0 +1,63 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	// LocalClusterEngineKind runs local clusters with kind.
+	LocalClusterEngineKind = "kind"
+	// LocalClusterEngineK3d runs local clusters with k3d.
+	LocalClusterEngineK3d = "k3d"
+)
+
+// LocalMetadata is the provisioner metadata stored in a model.Cluster
+// running on the machine of the provisioner.
+type LocalMetadata struct {
+	Name string
+	// Engine is the tool running the cluster, either kind or k3d.
+	Engine string
+	// Version is the kubernetes version last reported by the cluster.
+	Version       string
+	ChangeRequest *LocalMetadataRequestedState `json:"ChangeRequest,omitempty"`
+}
+
+// LocalMetadataRequestedState is the requested state for local metadata.
+type LocalMetadataRequestedState struct {
+	Version string `json:"Version,omitempty"`
+}
+
+// ClearChangeRequest clears the local metadata change request.
+func (lm *LocalMetadata) ClearChangeRequest() {
+	lm.ChangeRequest = nil
+}
+
+// IsSupportedLocalClusterEngine returns true if the given engine can run
+// local clusters.
+func IsSupportedLocalClusterEngine(engine string) bool {
+	switch engine {
+	case LocalClusterEngineKind, LocalClusterEngineK3d:
+		return true
+	}
+
+	return false
+}
+
+// NewLocalMetadata creates an instance of LocalMetadata given the raw provisioner metadata.
+func NewLocalMetadata(metadataBytes []byte) (*LocalMetadata, error) {
+	if len(metadataBytes) == 0 || string(metadataBytes) == "null" {
+		return nil, nil
+	}
+
+	localMetadata := LocalMetadata{}
+	err := json.Unmarshal(metadataBytes, &localMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localMetadata, nil
+}
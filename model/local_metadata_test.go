@@ -0,0 +1,44 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLocalMetadata(t *testing.T) {
+	t.Run("nil payload", func(t *testing.T) {
+		localMetadata, err := model.NewLocalMetadata(nil)
+		require.NoError(t, err)
+		require.Nil(t, localMetadata)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := model.NewLocalMetadata([]byte(`{`))
+		require.Error(t, err)
+	})
+
+	t.Run("valid payload", func(t *testing.T) {
+		localMetadata, err := model.NewLocalMetadata([]byte(`{"Name": "name", "Engine": "kind", "Version": "1.18.2", "ChangeRequest": {"Version": "1.19.1"}}`))
+		require.NoError(t, err)
+		require.Equal(t, "name", localMetadata.Name)
+		require.Equal(t, model.LocalClusterEngineKind, localMetadata.Engine)
+		require.Equal(t, "1.18.2", localMetadata.Version)
+		require.Equal(t, "1.19.1", localMetadata.ChangeRequest.Version)
+
+		localMetadata.ClearChangeRequest()
+		require.Nil(t, localMetadata.ChangeRequest)
+	})
+}
+
+func TestIsSupportedLocalClusterEngine(t *testing.T) {
+	require.True(t, model.IsSupportedLocalClusterEngine(model.LocalClusterEngineKind))
+	require.True(t, model.IsSupportedLocalClusterEngine(model.LocalClusterEngineK3d))
+	require.False(t, model.IsSupportedLocalClusterEngine("minikube"))
+	require.False(t, model.IsSupportedLocalClusterEngine(""))
+}
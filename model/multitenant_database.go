@@ -7,19 +7,72 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MultitenantDatabaseStateCreating is a multitenant database whose RDS
+	// cluster is being created by the provisioner.
+	MultitenantDatabaseStateCreating = "creating"
+	// MultitenantDatabaseStateStable is a multitenant database that is ready
+	// to host installation databases.
+	MultitenantDatabaseStateStable = "stable"
+	// MultitenantDatabaseStateDeleted is a multitenant database whose RDS
+	// cluster was retired by the provisioner.
+	MultitenantDatabaseStateDeleted = "deleted"
 )
 
 // MultitenantDatabase represents database infrastructure that contains multiple
 // installation databases.
 type MultitenantDatabase struct {
-	ID             string
-	VpcID          string
-	DatabaseType   string
-	Installations  MultitenantDatabaseInstallations
-	CreateAt       int64
-	DeleteAt       int64
-	LockAcquiredBy *string
-	LockAcquiredAt int64
+	ID            string
+	VpcID         string
+	DatabaseType  string
+	State         string
+	Installations MultitenantDatabaseInstallations
+	// Managed is true when the RDS cluster was created by the provisioner,
+	// which then also retires it once it held no installations for a while.
+	Managed bool
+	// EmptySince is the time a managed database was first found empty by the
+	// retirement supervisor, or 0 when it holds installations.
+	EmptySince int64
+	// AllowInstallations is false when no new installations may be assigned
+	// to the database. Draining databases never allow installations.
+	AllowInstallations bool
+	Draining           bool
+	// MaxInstallations overrides the default maximum number of installations
+	// of the database type when greater than 0.
+	MaxInstallations int
+	CreateAt         int64
+	DeleteAt         int64
+	APISecurityLock  bool
+	LockAcquiredBy   *string
+	LockAcquiredAt   int64
+}
+
+// InstallationLimit returns the maximum number of installations the database
+// can hold, falling back to the given default for its type.
+func (d *MultitenantDatabase) InstallationLimit(defaultLimit int) int {
+	if d.MaxInstallations > 0 {
+		return d.MaxInstallations
+	}
+
+	return defaultLimit
+}
+
+// MultitenantDatabaseFromReader decodes a json-encoded multitenant database
+// from the given io.Reader.
+func MultitenantDatabaseFromReader(reader io.Reader) (*MultitenantDatabase, error) {
+	database := MultitenantDatabase{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&database)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &database, nil
 }
 
 // MultitenantDatabaseInstallations is the list of installation IDs that belong
@@ -57,15 +110,89 @@ func (i *MultitenantDatabaseInstallations) Remove(installationID string) {
 }
 
 // MultitenantDatabaseFilter filters results based on a specific installation ID, Vpc ID and a number of
-// installation's limit.
+// installation's limit. Databases with their own installation limit are
+// filtered with it instead of MaxInstallationsLimit.
 type MultitenantDatabaseFilter struct {
 	LockerID              string
 	InstallationID        string
 	VpcID                 string
 	DatabaseType          string
 	MaxInstallationsLimit int
-	Page                  int
-	PerPage               int
+	// AllowInstallations only returns the databases new installations may be
+	// assigned to.
+	AllowInstallations bool
+	Page               int
+	PerPage            int
+	IncludeDeleted     bool
+}
+
+// MultitenantDatabaseEngineSettings are the settings of the RDS clusters
+// created for one database engine.
+type MultitenantDatabaseEngineSettings struct {
+	EngineVersion string `json:"engineVersion,omitempty"`
+	InstanceClass string `json:"instanceClass,omitempty"`
+}
+
+// MultitenantDatabaseVPCSettings are the settings of the multitenant RDS
+// clusters created by the provisioner in a VPC. Clusters are only created for
+// the database engines that are configured.
+type MultitenantDatabaseVPCSettings struct {
+	MySQL    *MultitenantDatabaseEngineSettings `json:"mysql,omitempty"`
+	Postgres *MultitenantDatabaseEngineSettings `json:"postgres,omitempty"`
+	// KMSKeyID is the key encrypting the RDS clusters. The default RDS key of
+	// the account is used when empty.
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// EngineSettings returns the settings configured for the given database type
+// or nil if RDS clusters must not be created for it.
+func (s *MultitenantDatabaseVPCSettings) EngineSettings(databaseType string) *MultitenantDatabaseEngineSettings {
+	switch databaseType {
+	case DatabaseEngineTypeMySQL:
+		return s.MySQL
+	case DatabaseEngineTypePostgres:
+		return s.Postgres
+	}
+
+	return nil
+}
+
+// MultitenantDatabaseConfig maps VPC IDs to the settings of the multitenant
+// RDS clusters the provisioner creates in them.
+type MultitenantDatabaseConfig map[string]*MultitenantDatabaseVPCSettings
+
+// SettingsForVPC returns the multitenant database settings of a VPC or nil if
+// the provisioner must not create RDS clusters in it.
+func (c MultitenantDatabaseConfig) SettingsForVPC(vpcID string) *MultitenantDatabaseVPCSettings {
+	if c == nil {
+		return nil
+	}
+
+	return c[vpcID]
+}
+
+// MultitenantDatabaseConfigFromFile reads a json-encoded multitenant database
+// configuration from the given file.
+func MultitenantDatabaseConfigFromFile(path string) (MultitenantDatabaseConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open multitenant database config")
+	}
+	defer file.Close()
+
+	config := MultitenantDatabaseConfig{}
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode multitenant database config")
+	}
+
+	for vpcID, settings := range config {
+		if settings == nil || (settings.MySQL == nil && settings.Postgres == nil) {
+			return nil, errors.Errorf("no database engine configured for VPC %s", vpcID)
+		}
+	}
+
+	return config, nil
 }
 
 // MultitenantDatabasesFromReader decodes a json-encoded list of multitenant databases from the given io.Reader.
@@ -0,0 +1,150 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// MultitenantDatabaseMove is a proposed move of an installation database from
+// one multitenant database to another.
+type MultitenantDatabaseMove struct {
+	InstallationID   string
+	SourceDatabaseID string
+	TargetDatabaseID string
+}
+
+// MultitenantDatabaseMovesFromReader decodes a json-encoded list of multitenant
+// database moves from the given io.Reader.
+func MultitenantDatabaseMovesFromReader(reader io.Reader) ([]*MultitenantDatabaseMove, error) {
+	moves := []*MultitenantDatabaseMove{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&moves)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return moves, nil
+}
+
+// PlanMultitenantDatabaseRebalance proposes the installation database moves
+// evening out the number of installations between the multitenant databases
+// of the same type in the same VPC.
+//
+// Draining databases have all their installations moved first. Databases that
+// don't otherwise allow installations keep theirs, and databases are never
+// filled over their own installation limit. Only stable databases are
+// considered.
+func PlanMultitenantDatabaseRebalance(databases []*MultitenantDatabase) []*MultitenantDatabaseMove {
+	var groupKeys []string
+	groups := make(map[string][]*MultitenantDatabase)
+	for _, database := range databases {
+		if database.State != MultitenantDatabaseStateStable {
+			continue
+		}
+
+		key := database.VpcID + "/" + database.DatabaseType
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], database)
+	}
+
+	var moves []*MultitenantDatabaseMove
+	for _, key := range groupKeys {
+		moves = append(moves, planMultitenantDatabaseGroupRebalance(groups[key])...)
+	}
+
+	return moves
+}
+
+type rebalanceCandidate struct {
+	database      *MultitenantDatabase
+	installations MultitenantDatabaseInstallations
+}
+
+func (c *rebalanceCandidate) hasCapacity() bool {
+	limit := c.database.InstallationLimit(NoInstallationsLimit)
+
+	return limit < 0 || c.installations.Count() < limit
+}
+
+func planMultitenantDatabaseGroupRebalance(databases []*MultitenantDatabase) []*MultitenantDatabaseMove {
+	var sources, targets []*rebalanceCandidate
+	for _, database := range databases {
+		candidate := &rebalanceCandidate{
+			database:      database,
+			installations: append(MultitenantDatabaseInstallations{}, database.Installations...),
+		}
+
+		switch {
+		case database.Draining:
+			sources = append(sources, candidate)
+		case database.AllowInstallations:
+			targets = append(targets, candidate)
+		}
+	}
+
+	var moves []*MultitenantDatabaseMove
+	move := func(source, target *rebalanceCandidate) {
+		last := source.installations.Count() - 1
+		installationID := source.installations[last]
+		source.installations = source.installations[:last]
+		target.installations.Add(installationID)
+
+		moves = append(moves, &MultitenantDatabaseMove{
+			InstallationID:   installationID,
+			SourceDatabaseID: source.database.ID,
+			TargetDatabaseID: target.database.ID,
+		})
+	}
+
+	for _, source := range sources {
+		for source.installations.Count() > 0 {
+			target := leastLoadedRebalanceCandidate(targets, nil)
+			if target == nil {
+				return moves
+			}
+			move(source, target)
+		}
+	}
+
+	for {
+		source := mostLoadedRebalanceCandidate(targets)
+		target := leastLoadedRebalanceCandidate(targets, source)
+		if source == nil || target == nil ||
+			source.installations.Count()-target.installations.Count() <= 1 {
+			return moves
+		}
+		move(source, target)
+	}
+}
+
+func mostLoadedRebalanceCandidate(candidates []*rebalanceCandidate) *rebalanceCandidate {
+	var selected *rebalanceCandidate
+	for _, candidate := range candidates {
+		if selected == nil || candidate.installations.Count() > selected.installations.Count() {
+			selected = candidate
+		}
+	}
+
+	return selected
+}
+
+func leastLoadedRebalanceCandidate(candidates []*rebalanceCandidate, exclude *rebalanceCandidate) *rebalanceCandidate {
+	var selected *rebalanceCandidate
+	for _, candidate := range candidates {
+		if candidate == exclude || !candidate.hasCapacity() {
+			continue
+		}
+		if selected == nil || candidate.installations.Count() < selected.installations.Count() {
+			selected = candidate
+		}
+	}
+
+	return selected
+}
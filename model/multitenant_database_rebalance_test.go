@@ -0,0 +1,147 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanMultitenantDatabaseRebalance(t *testing.T) {
+	makeDatabase := func(id, vpcID string, installations ...string) *model.MultitenantDatabase {
+		return &model.MultitenantDatabase{
+			ID:                 id,
+			VpcID:              vpcID,
+			DatabaseType:       model.DatabaseEngineTypeMySQL,
+			State:              model.MultitenantDatabaseStateStable,
+			AllowInstallations: true,
+			Installations:      installations,
+		}
+	}
+
+	t.Run("no databases", func(t *testing.T) {
+		assert.Empty(t, model.PlanMultitenantDatabaseRebalance(nil))
+	})
+
+	t.Run("balanced", func(t *testing.T) {
+		databases := []*model.MultitenantDatabase{
+			makeDatabase("db1", "vpc1", "i1", "i2"),
+			makeDatabase("db2", "vpc1", "i3"),
+		}
+		assert.Empty(t, model.PlanMultitenantDatabaseRebalance(databases))
+	})
+
+	t.Run("even out", func(t *testing.T) {
+		databases := []*model.MultitenantDatabase{
+			makeDatabase("db1", "vpc1", "i1", "i2", "i3", "i4", "i5"),
+			makeDatabase("db2", "vpc1"),
+			makeDatabase("db3", "vpc1", "i6"),
+		}
+		moves := model.PlanMultitenantDatabaseRebalance(databases)
+		assert.Equal(t, []*model.MultitenantDatabaseMove{
+			{InstallationID: "i5", SourceDatabaseID: "db1", TargetDatabaseID: "db2"},
+			{InstallationID: "i4", SourceDatabaseID: "db1", TargetDatabaseID: "db2"},
+			{InstallationID: "i3", SourceDatabaseID: "db1", TargetDatabaseID: "db3"},
+		}, moves)
+
+		// The planner works on a copy of the installations.
+		assert.Len(t, databases[0].Installations, 5)
+	})
+
+	t.Run("only within the same vpc and type", func(t *testing.T) {
+		postgres := makeDatabase("db3", "vpc1")
+		postgres.DatabaseType = model.DatabaseEngineTypePostgres
+		databases := []*model.MultitenantDatabase{
+			makeDatabase("db1", "vpc1", "i1", "i2", "i3"),
+			makeDatabase("db2", "vpc2"),
+			postgres,
+		}
+		assert.Empty(t, model.PlanMultitenantDatabaseRebalance(databases))
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		draining := makeDatabase("db1", "vpc1", "i1", "i2", "i3")
+		draining.Draining = true
+		draining.AllowInstallations = false
+		databases := []*model.MultitenantDatabase{
+			draining,
+			makeDatabase("db2", "vpc1", "i4"),
+			makeDatabase("db3", "vpc1"),
+		}
+		moves := model.PlanMultitenantDatabaseRebalance(databases)
+		assert.Equal(t, []*model.MultitenantDatabaseMove{
+			{InstallationID: "i3", SourceDatabaseID: "db1", TargetDatabaseID: "db3"},
+			{InstallationID: "i2", SourceDatabaseID: "db1", TargetDatabaseID: "db2"},
+			{InstallationID: "i1", SourceDatabaseID: "db1", TargetDatabaseID: "db3"},
+		}, moves)
+	})
+
+	t.Run("databases not allowing installations are left alone", func(t *testing.T) {
+		closed := makeDatabase("db1", "vpc1", "i1", "i2", "i3")
+		closed.AllowInstallations = false
+		databases := []*model.MultitenantDatabase{
+			closed,
+			makeDatabase("db2", "vpc1"),
+		}
+		assert.Empty(t, model.PlanMultitenantDatabaseRebalance(databases))
+	})
+
+	t.Run("installation limits", func(t *testing.T) {
+		draining := makeDatabase("db1", "vpc1", "i1", "i2", "i3")
+		draining.Draining = true
+		limited := makeDatabase("db2", "vpc1")
+		limited.MaxInstallations = 1
+		databases := []*model.MultitenantDatabase{
+			draining,
+			limited,
+		}
+		moves := model.PlanMultitenantDatabaseRebalance(databases)
+		assert.Equal(t, []*model.MultitenantDatabaseMove{
+			{InstallationID: "i3", SourceDatabaseID: "db1", TargetDatabaseID: "db2"},
+		}, moves)
+	})
+
+	t.Run("unstable databases are ignored", func(t *testing.T) {
+		creating := makeDatabase("db2", "vpc1")
+		creating.State = model.MultitenantDatabaseStateCreating
+		databases := []*model.MultitenantDatabase{
+			makeDatabase("db1", "vpc1", "i1", "i2", "i3"),
+			creating,
+		}
+		assert.Empty(t, model.PlanMultitenantDatabaseRebalance(databases))
+	})
+}
+
+func TestMultitenantDatabaseMovesFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		moves, err := model.MultitenantDatabaseMovesFromReader(bytes.NewReader([]byte(
+			``,
+		)))
+		require.NoError(t, err)
+		require.Equal(t, []*model.MultitenantDatabaseMove{}, moves)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		moves, err := model.MultitenantDatabaseMovesFromReader(bytes.NewReader([]byte(
+			`{test`,
+		)))
+		require.Error(t, err)
+		require.Nil(t, moves)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		moves, err := model.MultitenantDatabaseMovesFromReader(bytes.NewReader([]byte(
+			`[{"InstallationID":"i1","SourceDatabaseID":"db1","TargetDatabaseID":"db2"}]`,
+		)))
+		require.NoError(t, err)
+		require.Equal(t, []*model.MultitenantDatabaseMove{
+			{InstallationID: "i1", SourceDatabaseID: "db1", TargetDatabaseID: "db2"},
+		}, moves)
+	})
+}
@@ -5,8 +5,12 @@
 package model
 
 import (
+	"encoding/json"
+	"io"
 	"net/url"
 	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 // GetDatabasesRequest describes the parameters to request a list of multitenant databases.
@@ -27,3 +31,59 @@ func (request *GetDatabasesRequest) ApplyToURL(u *url.URL) {
 
 	u.RawQuery = q.Encode()
 }
+
+// UpdateMultitenantDatabaseRequest specifies the parameters for updating a
+// multitenant database. Nil values are left unchanged.
+type UpdateMultitenantDatabaseRequest struct {
+	AllowInstallations *bool `json:"AllowInstallations,omitempty"`
+	Draining           *bool `json:"Draining,omitempty"`
+	// MaxInstallations set to 0 restores the default limit of the database
+	// type.
+	MaxInstallations *int `json:"MaxInstallations,omitempty"`
+}
+
+// Validate validates the values of a multitenant database update request.
+func (request *UpdateMultitenantDatabaseRequest) Validate() error {
+	if request.MaxInstallations != nil && *request.MaxInstallations < 0 {
+		return errors.New("max installations must be 0 or greater")
+	}
+	if request.Draining != nil && *request.Draining &&
+		request.AllowInstallations != nil && *request.AllowInstallations {
+		return errors.New("draining databases can't allow installations")
+	}
+
+	return nil
+}
+
+// Apply applies the request to the given multitenant database.
+func (request *UpdateMultitenantDatabaseRequest) Apply(database *MultitenantDatabase) {
+	if request.AllowInstallations != nil {
+		database.AllowInstallations = *request.AllowInstallations
+	}
+	if request.Draining != nil {
+		database.Draining = *request.Draining
+	}
+	if request.MaxInstallations != nil {
+		database.MaxInstallations = *request.MaxInstallations
+	}
+	if database.Draining {
+		database.AllowInstallations = false
+	}
+}
+
+// NewUpdateMultitenantDatabaseRequestFromReader will create an
+// UpdateMultitenantDatabaseRequest from an io.Reader with JSON data.
+func NewUpdateMultitenantDatabaseRequestFromReader(reader io.Reader) (*UpdateMultitenantDatabaseRequest, error) {
+	var updateRequest UpdateMultitenantDatabaseRequest
+	err := json.NewDecoder(reader).Decode(&updateRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode update multitenant database request")
+	}
+
+	err = updateRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "update multitenant database request failed validation")
+	}
+
+	return &updateRequest, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateMultitenantDatabaseRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.UpdateMultitenantDatabaseRequest
+		requireError bool
+	}{
+		{"empty", &model.UpdateMultitenantDatabaseRequest{}, false},
+		{"max installations", &model.UpdateMultitenantDatabaseRequest{MaxInstallations: iToP(20)}, false},
+		{"default max installations", &model.UpdateMultitenantDatabaseRequest{MaxInstallations: iToP(0)}, false},
+		{"negative max installations", &model.UpdateMultitenantDatabaseRequest{MaxInstallations: iToP(-1)}, true},
+		{"draining", &model.UpdateMultitenantDatabaseRequest{Draining: bToP(true), AllowInstallations: bToP(false)}, false},
+		{"draining and allowing installations", &model.UpdateMultitenantDatabaseRequest{Draining: bToP(true), AllowInstallations: bToP(true)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestUpdateMultitenantDatabaseRequestApply(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		database := &model.MultitenantDatabase{AllowInstallations: true, MaxInstallations: 5}
+		request := &model.UpdateMultitenantDatabaseRequest{}
+		request.Apply(database)
+		assert.Equal(t, &model.MultitenantDatabase{AllowInstallations: true, MaxInstallations: 5}, database)
+	})
+
+	t.Run("no new installations", func(t *testing.T) {
+		database := &model.MultitenantDatabase{AllowInstallations: true}
+		request := &model.UpdateMultitenantDatabaseRequest{AllowInstallations: bToP(false), MaxInstallations: iToP(0)}
+		request.Apply(database)
+		assert.Equal(t, &model.MultitenantDatabase{}, database)
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		database := &model.MultitenantDatabase{AllowInstallations: true}
+		request := &model.UpdateMultitenantDatabaseRequest{Draining: bToP(true)}
+		request.Apply(database)
+		assert.Equal(t, &model.MultitenantDatabase{Draining: true}, database)
+	})
+}
+
+func TestNewUpdateMultitenantDatabaseRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewUpdateMultitenantDatabaseRequestFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &model.UpdateMultitenantDatabaseRequest{}, request)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		request, err := model.NewUpdateMultitenantDatabaseRequestFromReader(bytes.NewReader([]byte(`{"MaxInstallations": -1}`)))
+		require.Error(t, err)
+		require.Nil(t, request)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		request, err := model.NewUpdateMultitenantDatabaseRequestFromReader(bytes.NewReader([]byte(`{"Draining": true, "MaxInstallations": 20}`)))
+		require.NoError(t, err)
+		require.Equal(t, &model.UpdateMultitenantDatabaseRequest{Draining: bToP(true), MaxInstallations: iToP(20)}, request)
+	})
+}
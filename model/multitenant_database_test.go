@@ -6,9 +6,13 @@ package model
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMultitenantDatabaseInstallationsCountContainsAndAdd(t *testing.T) {
@@ -85,3 +89,79 @@ func TestMultitenantDatabaseInstallationsRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestMultitenantDatabaseConfig(t *testing.T) {
+	config := MultitenantDatabaseConfig{
+		"vpc1": &MultitenantDatabaseVPCSettings{
+			MySQL: &MultitenantDatabaseEngineSettings{InstanceClass: "db.r5.large"},
+		},
+	}
+
+	t.Run("configured engine", func(t *testing.T) {
+		settings := config.SettingsForVPC("vpc1")
+		require.NotNil(t, settings)
+		assert.Equal(t, "db.r5.large", settings.EngineSettings(DatabaseEngineTypeMySQL).InstanceClass)
+	})
+
+	t.Run("unconfigured engine", func(t *testing.T) {
+		assert.Nil(t, config.SettingsForVPC("vpc1").EngineSettings(DatabaseEngineTypePostgres))
+	})
+
+	t.Run("unconfigured vpc", func(t *testing.T) {
+		assert.Nil(t, config.SettingsForVPC("vpc2"))
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		var config MultitenantDatabaseConfig
+		assert.Nil(t, config.SettingsForVPC("vpc1"))
+	})
+}
+
+func TestMultitenantDatabaseConfigFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multitenant-database-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeConfig := func(t *testing.T, content string) string {
+		path := filepath.Join(dir, "config.json")
+		err := ioutil.WriteFile(path, []byte(content), 0600)
+		require.NoError(t, err)
+
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		path := writeConfig(t, `{"vpc1": {"mysql": {"engineVersion": "5.7", "instanceClass": "db.r5.large"}, "kmsKeyID": "key1"}}`)
+
+		config, err := MultitenantDatabaseConfigFromFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, MultitenantDatabaseConfig{
+			"vpc1": &MultitenantDatabaseVPCSettings{
+				MySQL: &MultitenantDatabaseEngineSettings{
+					EngineVersion: "5.7",
+					InstanceClass: "db.r5.large",
+				},
+				KMSKeyID: "key1",
+			},
+		}, config)
+	})
+
+	t.Run("no engine", func(t *testing.T) {
+		path := writeConfig(t, `{"vpc1": {"kmsKeyID": "key1"}}`)
+
+		_, err := MultitenantDatabaseConfigFromFile(path)
+		assert.EqualError(t, err, "no database engine configured for VPC vpc1")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := writeConfig(t, `{"vpc1"`)
+
+		_, err := MultitenantDatabaseConfigFromFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := MultitenantDatabaseConfigFromFile(filepath.Join(dir, "missing.json"))
+		assert.Error(t, err)
+	})
+}
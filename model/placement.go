@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SchedulingPolicyFirstFit places installations on the oldest cluster able
+	// to host them.
+	SchedulingPolicyFirstFit = "first-fit"
+	// SchedulingPolicyBinPack places installations on the most loaded cluster
+	// able to host them, keeping other clusters free.
+	SchedulingPolicyBinPack = "bin-pack"
+	// SchedulingPolicySpread places installations on the least loaded cluster
+	// able to host them.
+	SchedulingPolicySpread = "spread"
+	// SchedulingPolicyLabel places installations on the cluster matching most
+	// of their preferred labels.
+	SchedulingPolicyLabel = "label"
+)
+
+// IsSupportedSchedulingPolicy returns true if the given scheduling policy is
+// supported.
+func IsSupportedSchedulingPolicy(policy string) bool {
+	switch policy {
+	case SchedulingPolicyFirstFit:
+	case SchedulingPolicyBinPack:
+	case SchedulingPolicySpread:
+	case SchedulingPolicyLabel:
+	default:
+		return false
+	}
+
+	return true
+}
+
+var labelKeyRegex = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]{0,61}[A-Za-z0-9])?$`)
+var labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+
+// ValidateLabels validates the keys and values of the given labels, which
+// follow the format of Kubernetes labels.
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !labelKeyRegex.MatchString(key) {
+			return errors.Errorf("invalid label key %q", key)
+		}
+		if !labelValueRegex.MatchString(value) {
+			return errors.Errorf("invalid value %q for label %s", value, key)
+		}
+	}
+
+	return nil
+}
+
+// PlacementConstraints restricts the clusters an installation can be placed
+// on.
+type PlacementConstraints struct {
+	// RequiredLabels must all be set on the cluster with the same values.
+	RequiredLabels map[string]string `json:"RequiredLabels,omitempty"`
+	// PreferredLabels rank the clusters when using the label scheduling
+	// policy.
+	PreferredLabels map[string]string `json:"PreferredLabels,omitempty"`
+	// AvoidClusterIDs are never picked.
+	AvoidClusterIDs []string `json:"AvoidClusterIDs,omitempty"`
+	// NodeSelector restricts the installation pods to the nodes with all of
+	// the given labels, such as the nodes of a given instance group. Only
+	// clusters with such nodes are picked.
+	NodeSelector map[string]string `json:"NodeSelector,omitempty"`
+}
+
+// Validate validates the placement constraints.
+func (c *PlacementConstraints) Validate() error {
+	err := ValidateLabels(c.RequiredLabels)
+	if err != nil {
+		return errors.Wrap(err, "invalid required labels")
+	}
+	err = ValidateLabels(c.PreferredLabels)
+	if err != nil {
+		return errors.Wrap(err, "invalid preferred labels")
+	}
+	for _, clusterID := range c.AvoidClusterIDs {
+		if len(clusterID) == 0 {
+			return errors.New("cluster IDs to avoid cannot be blank")
+		}
+	}
+	err = ValidateLabels(c.NodeSelector)
+	if err != nil {
+		return errors.Wrap(err, "invalid node selector")
+	}
+
+	return nil
+}
+
+// PlacementConstraintsFromJSON creates PlacementConstraints from the JSON
+// representation stored in the database.
+func PlacementConstraintsFromJSON(raw []byte) (*PlacementConstraints, error) {
+	constraints := &PlacementConstraints{}
+	err := json.Unmarshal(raw, constraints)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal placement constraints")
+	}
+
+	return constraints, nil
+}
+
+// ToJSON returns the JSON representation of the constraints for storage in the
+// database. Nil constraints are stored as NULL.
+func (c *PlacementConstraints) ToJSON() ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(c)
+}
+
+// GetNodeSelector returns the node selector of the constraints, which is nil
+// for nil constraints.
+func (c *PlacementConstraints) GetNodeSelector() map[string]string {
+	if c == nil {
+		return nil
+	}
+
+	return c.NodeSelector
+}
+
+// ClusterPlacement describes whether an installation can be placed on a
+// cluster, and the expected load of the cluster if it is.
+type ClusterPlacement struct {
+	ClusterID   string
+	Schedulable bool
+	// Reason explains why the installation cannot be placed on the cluster.
+	Reason string `json:"Reason,omitempty"`
+	// CPUPercent and MemoryPercent are the expected load of the cluster once
+	// the installation is placed on it.
+	CPUPercent    int
+	MemoryPercent int
+	// RequiresScaleUp is set when the cluster has to be scaled up to stay
+	// under the resource threshold.
+	RequiresScaleUp         bool
+	MatchingPreferredLabels int
+}
+
+// InstallationSchedulingResult is the outcome of scheduling an installation
+// without creating it.
+type InstallationSchedulingResult struct {
+	Policy string
+	// ClusterID is the cluster the installation would be placed on, if any.
+	ClusterID string
+	// Placements lists every cluster in order of preference.
+	Placements []*ClusterPlacement
+}
+
+// InstallationSchedulingResultFromReader decodes a json-encoded scheduling
+// result from the given io.Reader.
+func InstallationSchedulingResultFromReader(reader io.Reader) (*InstallationSchedulingResult, error) {
+	result := InstallationSchedulingResult{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&result)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ProvisionerKops is the provisioner creating clusters with kops and
+	// terraform.
+	ProvisionerKops = "kops"
+	// ProvisionerEKS is the provisioner creating AWS managed EKS clusters.
+	ProvisionerEKS = "eks"
+	// ProvisionerExternal is the provisioner of clusters built outside of the
+	// provisioner and imported with their kubeconfig. Their infrastructure is
+	// never created, upgraded or resized.
+	ProvisionerExternal = "external"
+	// ProvisionerLocal is the provisioner running clusters on its own machine
+	// with kind or k3d, for development and end-to-end tests.
+	ProvisionerLocal = "local"
+)
+
+// CheckProvisioner normalizes the given provisioner, returning an error if invalid.
+func CheckProvisioner(provisioner string) (string, error) {
+	provisioner = strings.ToLower(provisioner)
+	switch provisioner {
+	case ProvisionerKops, ProvisionerEKS, ProvisionerExternal, ProvisionerLocal:
+		return provisioner, nil
+	}
+
+	return provisioner, fmt.Errorf("unsupported provisioner %s", provisioner)
+}
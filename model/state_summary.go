@@ -0,0 +1,15 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// ResourceStateSummary summarizes the resources of a single type that are in
+// a given state.
+type ResourceStateSummary struct {
+	State string
+	Count int64
+	// OldestStateChangeAt is the time at which the resource that has been in
+	// the state for the longest time entered it.
+	OldestStateChangeAt int64
+}
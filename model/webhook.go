@@ -7,6 +7,8 @@ package model
 import (
 	"encoding/json"
 	"io"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -17,15 +19,32 @@ const (
 	// TypeClusterInstallation is the string value that represents a cluster
 	// installation.
 	TypeClusterInstallation = "cluster_installaton"
+	// TypeInstallationBackup is the string value that represents an
+	// installation backup.
+	TypeInstallationBackup = "installation_backup"
 )
 
 // Webhook is
 type Webhook struct {
-	ID       string
-	OwnerID  string
-	URL      string
-	CreateAt int64
-	DeleteAt int64
+	ID          string
+	OwnerID     string
+	URL         string
+	Secret      string              `json:"-"`
+	EventFilter *WebhookEventFilter `json:",omitempty"`
+	CreateAt    int64
+	DeleteAt    int64
+}
+
+// WebhookEventFilter restricts the events sent to a webhook. Each field that
+// is set must match for an event to be sent; empty fields match all events.
+//
+// The owner and group filters only match events that carry an owner or group,
+// such as installation events.
+type WebhookEventFilter struct {
+	Types     []string `json:",omitempty"`
+	NewStates []string `json:",omitempty"`
+	OwnerID   string   `json:",omitempty"`
+	GroupID   string   `json:",omitempty"`
 }
 
 // WebhookFilter describes the parameters used to constrain a set of webhooks.
@@ -43,6 +62,8 @@ type WebhookPayload struct {
 	Type      string            `json:"type"`
 	NewState  string            `json:"new_state"`
 	OldState  string            `json:"old_state"`
+	OwnerID   string            `json:"owner_id,omitempty"`
+	GroupID   *string           `json:"group_id,omitempty"`
 	ExtraData map[string]string `json:"extra_data,omitempty"`
 }
 
@@ -51,6 +72,73 @@ func (w *Webhook) IsDeleted() bool {
 	return w.DeleteAt != 0
 }
 
+// IsSigned returns whether payloads sent to the webhook are signed or not.
+func (w *Webhook) IsSigned() bool {
+	return w.Secret != ""
+}
+
+// ShouldReceive returns whether the given payload passes the webhook's event
+// filter or not.
+func (w *Webhook) ShouldReceive(payload *WebhookPayload) bool {
+	return w.EventFilter.Matches(payload)
+}
+
+// Matches returns whether the given payload passes the filter or not. A nil
+// filter matches every payload.
+func (f *WebhookEventFilter) Matches(payload *WebhookPayload) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Types) != 0 && !containsString(f.Types, payload.Type) {
+		return false
+	}
+	if len(f.NewStates) != 0 && !containsString(f.NewStates, payload.NewState) {
+		return false
+	}
+	if f.OwnerID != "" && f.OwnerID != payload.OwnerID {
+		return false
+	}
+	if f.GroupID != "" && (payload.GroupID == nil || f.GroupID != *payload.GroupID) {
+		return false
+	}
+
+	return true
+}
+
+// IsEmpty returns true if the filter does not restrict any events.
+func (f *WebhookEventFilter) IsEmpty() bool {
+	return f == nil ||
+		len(f.Types) == 0 && len(f.NewStates) == 0 && f.OwnerID == "" && f.GroupID == ""
+}
+
+// Validate validates the values of a webhook event filter.
+func (f *WebhookEventFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	for _, eventType := range f.Types {
+		switch eventType {
+		case TypeCluster, TypeInstallation, TypeClusterInstallation, TypeInstallationBackup:
+		default:
+			return errors.Errorf("unsupported event type %s", eventType)
+		}
+	}
+
+	var validStates []string
+	validStates = append(validStates, AllClusterStates...)
+	validStates = append(validStates, AllInstallationStates...)
+	validStates = append(validStates, AllClusterInstallationStates...)
+	validStates = append(validStates, AllInstallationBackupStates...)
+	for _, state := range f.NewStates {
+		if !containsString(validStates, state) {
+			return errors.Errorf("unsupported event state %s", state)
+		}
+	}
+
+	return nil
+}
+
 // ToJSON returns a JSON string representation of the webhook payload.
 func (p *WebhookPayload) ToJSON() (string, error) {
 	b, err := json.Marshal(p)
@@ -97,3 +185,13 @@ func WebhookPayloadFromReader(reader io.Reader) (*WebhookPayload, error) {
 
 	return &payload, nil
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
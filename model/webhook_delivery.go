@@ -0,0 +1,109 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// WebhookDeliveryStatePending is a webhook delivery waiting for its next
+	// delivery attempt.
+	WebhookDeliveryStatePending = "pending"
+	// WebhookDeliveryStateDelivered is a webhook delivery that was accepted by
+	// the receiver.
+	WebhookDeliveryStateDelivered = "delivered"
+	// WebhookDeliveryStateDeadLetter is a webhook delivery that exhausted all
+	// delivery attempts and will not be retried unless redelivered.
+	WebhookDeliveryStateDeadLetter = "dead-letter"
+)
+
+// AllWebhookDeliveryStates is a list of all states a webhook delivery can be
+// in.
+// Warning:
+// When creating a new webhook delivery state, it must be added to this list.
+var AllWebhookDeliveryStates = []string{
+	WebhookDeliveryStatePending,
+	WebhookDeliveryStateDelivered,
+	WebhookDeliveryStateDeadLetter,
+}
+
+// WebhookDelivery is a single webhook payload queued for delivery to a single
+// webhook.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	Payload        *WebhookPayload
+	State          string
+	Attempts       int
+	LastAttemptAt  int64
+	NextAttemptAt  int64
+	LastError      string
+	CreateAt       int64
+	LockAcquiredBy *string
+	LockAcquiredAt int64
+}
+
+// WebhookDeliveryFilter describes the parameters used to constrain a set of
+// webhook deliveries.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	State     string
+	Page      int
+	PerPage   int
+}
+
+// IsValidWebhookDeliveryState returns true if the given state is a valid
+// webhook delivery state.
+func IsValidWebhookDeliveryState(state string) bool {
+	for _, validState := range AllWebhookDeliveryStates {
+		if state == validState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanRedeliver returns true if the webhook delivery is no longer pending and
+// can be queued for delivery again.
+func (d *WebhookDelivery) CanRedeliver() bool {
+	return d.State != WebhookDeliveryStatePending
+}
+
+// Redeliver resets the webhook delivery so that a new series of delivery
+// attempts is started on the next delivery supervisor cycle.
+func (d *WebhookDelivery) Redeliver() {
+	d.State = WebhookDeliveryStatePending
+	d.Attempts = 0
+	d.NextAttemptAt = 0
+	d.LastError = ""
+}
+
+// WebhookDeliveryFromReader decodes a json-encoded webhook delivery from the given io.Reader.
+func WebhookDeliveryFromReader(reader io.Reader) (*WebhookDelivery, error) {
+	delivery := WebhookDelivery{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&delivery)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// WebhookDeliveriesFromReader decodes a json-encoded list of webhook deliveries from the given io.Reader.
+func WebhookDeliveriesFromReader(reader io.Reader) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&deliveries)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -18,6 +18,10 @@ import (
 type CreateWebhookRequest struct {
 	OwnerID string
 	URL     string
+	// Secret, if set, is used to sign every payload sent to the webhook.
+	Secret string
+	// EventFilter, if set, restricts the events sent to the webhook.
+	EventFilter *WebhookEventFilter `json:",omitempty"`
 }
 
 // NewCreateWebhookRequestFromReader will create a CreateWebhookRequest from an io.Reader with JSON data.
@@ -46,10 +50,38 @@ func NewCreateWebhookRequestFromReader(reader io.Reader) (*CreateWebhookRequest,
 	if uri.Host == "" {
 		return nil, errors.New("must specify host")
 	}
+	err = createWebhookRequest.EventFilter.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event filter")
+	}
 
 	return &createWebhookRequest, nil
 }
 
+// UpdateWebhookRequest specifies the parameters available for updating a
+// webhook.
+type UpdateWebhookRequest struct {
+	// EventFilter replaces the existing event filter of the webhook. A nil
+	// filter removes any filtering.
+	EventFilter *WebhookEventFilter `json:",omitempty"`
+}
+
+// NewUpdateWebhookRequestFromReader will create an UpdateWebhookRequest from an io.Reader with JSON data.
+func NewUpdateWebhookRequestFromReader(reader io.Reader) (*UpdateWebhookRequest, error) {
+	var updateWebhookRequest UpdateWebhookRequest
+	err := json.NewDecoder(reader).Decode(&updateWebhookRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode update webhook request")
+	}
+
+	err = updateWebhookRequest.EventFilter.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event filter")
+	}
+
+	return &updateWebhookRequest, nil
+}
+
 // GetWebhooksRequest describes the parameters to request a list of webhooks.
 type GetWebhooksRequest struct {
 	OwnerID        string
@@ -69,3 +101,22 @@ func (request *GetWebhooksRequest) ApplyToURL(u *url.URL) {
 	}
 	u.RawQuery = q.Encode()
 }
+
+// GetWebhookDeliveriesRequest describes the parameters to request a list of
+// webhook deliveries.
+type GetWebhookDeliveriesRequest struct {
+	WebhookID string
+	State     string
+	Page      int
+	PerPage   int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetWebhookDeliveriesRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("webhook", request.WebhookID)
+	q.Add("state", request.State)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// WebhookHeaderDeliveryID is the header containing the ID of the webhook
+	// delivery. It is unchanged across retries of the same delivery.
+	WebhookHeaderDeliveryID = "X-Cloud-Delivery-Id"
+	// WebhookHeaderTimestamp is the header containing the unix time, in
+	// seconds, at which the webhook request was signed.
+	WebhookHeaderTimestamp = "X-Cloud-Timestamp"
+	// WebhookHeaderSignature is the header containing the HMAC-SHA256
+	// signature of the webhook request. It is only sent for webhooks that
+	// were created with a secret.
+	WebhookHeaderSignature = "X-Cloud-Signature"
+
+	webhookSignaturePrefix = "sha256="
+)
+
+// SignWebhookPayload returns the signature of a webhook request body sent at
+// the given unix timestamp, in the format sent in the WebhookHeaderSignature
+// header.
+//
+// The signature is the hex-encoded HMAC-SHA256, keyed with the webhook secret,
+// of the timestamp and body joined by a period.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks that a received webhook request was signed
+// with the given secret. If tolerance is greater than zero, requests signed
+// longer ago than the tolerance are also rejected to limit replay attacks.
+func VerifyWebhookSignature(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	signature := header.Get(WebhookHeaderSignature)
+	if signature == "" {
+		return errors.New("webhook signature header is missing")
+	}
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return errors.New("webhook signature has an unknown format")
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(WebhookHeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse webhook timestamp header")
+	}
+
+	if tolerance > 0 {
+		signedAt := time.Unix(timestamp, 0)
+		if time.Since(signedAt) > tolerance {
+			return errors.Errorf("webhook timestamp %d is older than %s", timestamp, tolerance)
+		}
+	}
+
+	expected := SignWebhookPayload(secret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("webhook signature does not match")
+	}
+
+	return nil
+}
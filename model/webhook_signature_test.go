@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"id":"id"}`)
+
+	signature := SignWebhookPayload("secret", 1600000000, body)
+	require.Equal(t, "sha256=04afa9aa4e4e2c3f3f9144c531a739f3f2c0e0e722032fb2fdf16a892e11b2ef", signature)
+
+	t.Run("different secret", func(t *testing.T) {
+		require.NotEqual(t, signature, SignWebhookPayload("other", 1600000000, body))
+	})
+
+	t.Run("different timestamp", func(t *testing.T) {
+		require.NotEqual(t, signature, SignWebhookPayload("secret", 1600000001, body))
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		require.NotEqual(t, signature, SignWebhookPayload("secret", 1600000000, []byte(`{"id":"id2"}`)))
+	})
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"id"}`)
+	now := time.Now().Unix()
+
+	makeHeader := func(secret string, timestamp int64) http.Header {
+		header := http.Header{}
+		header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		header.Set(WebhookHeaderSignature, SignWebhookPayload(secret, timestamp, body))
+		return header
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		err := VerifyWebhookSignature("secret", makeHeader("secret", now), body, 5*time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		header := makeHeader("secret", now)
+		header.Del(WebhookHeaderSignature)
+		err := VerifyWebhookSignature("secret", header, body, 0)
+		require.EqualError(t, err, "webhook signature header is missing")
+	})
+
+	t.Run("unknown signature format", func(t *testing.T) {
+		header := makeHeader("secret", now)
+		header.Set(WebhookHeaderSignature, "md5=abc")
+		err := VerifyWebhookSignature("secret", header, body, 0)
+		require.EqualError(t, err, "webhook signature has an unknown format")
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		header := makeHeader("secret", now)
+		header.Set(WebhookHeaderTimestamp, "invalid")
+		err := VerifyWebhookSignature("secret", header, body, 0)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		err := VerifyWebhookSignature("secret", makeHeader("other", now), body, 0)
+		require.EqualError(t, err, "webhook signature does not match")
+	})
+
+	t.Run("modified body", func(t *testing.T) {
+		err := VerifyWebhookSignature("secret", makeHeader("secret", now), []byte(`{"id":"id2"}`), 0)
+		require.EqualError(t, err, "webhook signature does not match")
+	})
+
+	t.Run("expired timestamp", func(t *testing.T) {
+		header := makeHeader("secret", now-3600)
+		err := VerifyWebhookSignature("secret", header, body, 5*time.Minute)
+		require.Error(t, err)
+
+		err = VerifyWebhookSignature("secret", header, body, 0)
+		require.NoError(t, err)
+	})
+}
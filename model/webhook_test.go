@@ -27,6 +27,85 @@ func TestWebhookIsDeleted(t *testing.T) {
 	})
 }
 
+func TestWebhookIsSigned(t *testing.T) {
+	webhook := &Webhook{}
+
+	t.Run("no secret", func(t *testing.T) {
+		require.False(t, webhook.IsSigned())
+	})
+
+	webhook.Secret = "secret"
+
+	t.Run("secret", func(t *testing.T) {
+		require.True(t, webhook.IsSigned())
+	})
+}
+
+func TestWebhookEventFilterMatches(t *testing.T) {
+	groupID := "group1"
+	payload := &WebhookPayload{
+		Type:     TypeInstallation,
+		NewState: InstallationStateStable,
+		OwnerID:  "owner1",
+		GroupID:  &groupID,
+	}
+
+	var testCases = []struct {
+		description string
+		filter      *WebhookEventFilter
+		expected    bool
+	}{
+		{"nil filter", nil, true},
+		{"empty filter", &WebhookEventFilter{}, true},
+		{"matching type", &WebhookEventFilter{Types: []string{TypeCluster, TypeInstallation}}, true},
+		{"non-matching type", &WebhookEventFilter{Types: []string{TypeCluster}}, false},
+		{"matching state", &WebhookEventFilter{NewStates: []string{InstallationStateStable}}, true},
+		{"non-matching state", &WebhookEventFilter{NewStates: []string{InstallationStateDeleted}}, false},
+		{"matching owner", &WebhookEventFilter{OwnerID: "owner1"}, true},
+		{"non-matching owner", &WebhookEventFilter{OwnerID: "owner2"}, false},
+		{"matching group", &WebhookEventFilter{GroupID: "group1"}, true},
+		{"non-matching group", &WebhookEventFilter{GroupID: "group2"}, false},
+		{"all matching", &WebhookEventFilter{Types: []string{TypeInstallation}, NewStates: []string{InstallationStateStable}, OwnerID: "owner1", GroupID: "group1"}, true},
+		{"one non-matching", &WebhookEventFilter{Types: []string{TypeInstallation}, NewStates: []string{InstallationStateStable}, OwnerID: "owner2"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.filter.Matches(payload))
+			require.Equal(t, tc.expected, (&Webhook{EventFilter: tc.filter}).ShouldReceive(payload))
+		})
+	}
+
+	t.Run("group filter with no payload group", func(t *testing.T) {
+		filter := &WebhookEventFilter{GroupID: "group1"}
+		require.False(t, filter.Matches(&WebhookPayload{Type: TypeCluster}))
+	})
+}
+
+func TestWebhookEventFilterValidate(t *testing.T) {
+	var testCases = []struct {
+		description string
+		filter      *WebhookEventFilter
+		valid       bool
+	}{
+		{"nil filter", nil, true},
+		{"empty filter", &WebhookEventFilter{}, true},
+		{"valid", &WebhookEventFilter{Types: []string{TypeInstallation}, NewStates: []string{InstallationStateStable, ClusterStateStable}}, true},
+		{"invalid type", &WebhookEventFilter{Types: []string{"unknown"}}, false},
+		{"invalid state", &WebhookEventFilter{NewStates: []string{"unknown"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if tc.valid {
+				require.NoError(t, tc.filter.Validate())
+			} else {
+				require.Error(t, tc.filter.Validate())
+			}
+		})
+	}
+}
+
 func TestWebhookPayloadToJSON(t *testing.T) {
 	payload := &WebhookPayload{
 		Timestamp: 123456789,